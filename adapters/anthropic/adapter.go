@@ -8,9 +8,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/internal/utils"
 	"github.com/ajeet-kumar1087/ai-providers/types"
 )
 
@@ -40,6 +42,10 @@ type AnthropicAdapter struct {
 	config     AdapterConfig
 	baseURL    string
 	apiKey     string
+
+	// headers are sent with every request. They are built once and only read
+	// afterwards, with canonical names so setting them does not allocate.
+	headers map[string]string
 }
 
 // NewAdapter creates a new Anthropic adapter with the given configuration
@@ -67,12 +73,36 @@ func NewAdapter(config AdapterConfig) (*AnthropicAdapter, error) {
 	}
 
 	httpClient := httputil.NewClient(timeout, maxRetries)
+	httpClient.SetMaxRetryWait(config.MaxRetryWait)
+	httpClient.SetRequestCompression(config.RequestCompressionThreshold)
+	if transform := config.RequestTransformer; transform != nil {
+		httpClient.SetRequestTransformer(func(body []byte) ([]byte, error) {
+			return transform(types.ProviderAnthropic, body)
+		})
+	}
+	if transform := config.ResponseTransformer; transform != nil {
+		httpClient.SetResponseTransformer(func(body []byte) ([]byte, error) {
+			return transform(types.ProviderAnthropic, body)
+		})
+	}
+	if config.DebugPayloads {
+		httpClient.SetDebug(config.DebugLogger, config.DebugRedactFields, config.DebugBodyLimit)
+	}
+	if config.RedactReasoning {
+		// Keep thinking blocks out of debug logs and bundles
+		httpClient.SetReasoningFields([]string{"thinking"})
+	}
 
 	return &AnthropicAdapter{
 		httpClient: httpClient,
 		config:     config,
 		baseURL:    baseURL,
 		apiKey:     config.APIKey,
+		headers: map[string]string{
+			"X-Api-Key":         config.APIKey,
+			"Anthropic-Version": APIVersion,
+			"Content-Type":      "application/json",
+		},
 	}, nil
 }
 
@@ -128,42 +158,58 @@ func (a *AnthropicAdapter) Name() string {
 	return "anthropic"
 }
 
-// SupportedFeatures returns a list of features supported by Anthropic
+// SupportedFeatures returns a list of features supported by Anthropic.
+// Only features the adapter implements are listed, as the client rejects
+// requests that need any other feature.
 func (a *AnthropicAdapter) SupportedFeatures() []string {
 	return []string{
-		"completion",
-		"chat_completion",
-		"streaming",
-		"temperature",
-		"max_tokens",
-		"stop_sequences",
-		"system_messages",
+		types.FeatureCompletion,
+		types.FeatureChatCompletion,
+		types.FeatureStreaming,
+		types.FeatureTemperature,
+		types.FeatureMaxTokens,
+		types.FeatureStopSequences,
+		types.FeatureSystemMessages,
+		types.FeatureTokenCounting,
+		types.FeatureBatch,
+		types.FeatureReasoning,
+		types.FeatureStreamingInput,
 	}
 }
 
+// CloseIdleConnections closes idle keep-alive connections; the client calls
+// it when it is closed
+func (a *AnthropicAdapter) CloseIdleConnections() {
+	a.httpClient.CloseIdleConnections()
+}
+
 // ValidateConfig validates the configuration for Anthropic adapter
 func (a *AnthropicAdapter) ValidateConfig(config AdapterConfig) error {
 	return validateConfig(config)
 }
 
-// makeRequest makes an HTTP request to the Anthropic API
-func (a *AnthropicAdapter) makeRequest(ctx context.Context, endpoint string, requestBody interface{}) (*http.Response, error) {
+// makeRequest makes an HTTP request to the Anthropic API, streaming the
+// content of inputs into the body in place of their placeholders
+func (a *AnthropicAdapter) makeRequest(ctx context.Context, endpoint string, requestBody interface{}, inputs []io.Reader) (*http.Response, error) {
 	// Marshal request body to JSON
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	// Prepare headers
-	headers := map[string]string{
-		"x-api-key":         a.apiKey,
-		"anthropic-version": APIVersion,
-		"Content-Type":      "application/json",
+	baseURL, headers, err := a.endpoint(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Make the request
-	url := a.baseURL + endpoint
-	resp, err := a.httpClient.Post(ctx, url, headers, jsonBody)
+	url := baseURL + endpoint
+	var resp *http.Response
+	if len(inputs) > 0 {
+		resp, err = a.httpClient.PostInputs(ctx, url, headers, jsonBody, inputs)
+	} else {
+		resp, err = a.httpClient.Post(ctx, url, headers, jsonBody)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("http request failed: %w", err)
 	}
@@ -171,6 +217,12 @@ func (a *AnthropicAdapter) makeRequest(ctx context.Context, endpoint string, req
 	return resp, nil
 }
 
+// endpoint returns the base URL and headers of a request, those of the
+// endpoint selected with types.WithEndpoint if any
+func (a *AnthropicAdapter) endpoint(ctx context.Context) (string, map[string]string, error) {
+	return httputil.ApplyEndpoint(ctx, a.config, a.baseURL, a.headers)
+}
+
 // parseErrorResponse parses an Anthropic error response
 func (a *AnthropicAdapter) parseErrorResponse(resp *http.Response) error {
 	defer resp.Body.Close()
@@ -187,8 +239,10 @@ func (a *AnthropicAdapter) parseErrorResponse(resp *http.Response) error {
 	}
 
 	if err := json.Unmarshal(body, &anthropicError); err != nil {
-		// If we can't parse the error, return a generic error with the status code
-		return fmt.Errorf("anthropic api error (status %d): %s", resp.StatusCode, string(body))
+		// Proxies answer with HTML or plain text; keep the body as the
+		// message so the error is still classified by its status
+		anthropicError.Type = ""
+		anthropicError.Message = fmt.Sprintf("anthropic api error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	message := anthropicError.Message
@@ -230,6 +284,31 @@ func (a *AnthropicAdapter) parseErrorResponse(resp *http.Response) error {
 	}
 }
 
+// applyValidationMode rejects or reports parameters beyond the provider
+// limits according to the configured validation mode, before the request
+// mapping clamps them. Requests made through the client arrive clamped.
+func (a *AnthropicAdapter) applyValidationMode(outOfRange []types.OutOfRangeParameter) error {
+	if err := utils.ApplyValidationMode(a.config.ValidationMode, a.config.OnOutOfRangeParameter, outOfRange); err != nil {
+		return &Error{
+			Type:     "validation",
+			Message:  err.Error(),
+			Code:     "out_of_range",
+			Provider: "anthropic",
+		}
+	}
+	return nil
+}
+
+// invalidResponseError reports a response body that could not be parsed
+func invalidResponseError(what string, err error) *Error {
+	return &Error{
+		Type:     "provider",
+		Message:  fmt.Sprintf("failed to parse %s: %v", what, err),
+		Code:     "invalid_response",
+		Provider: "anthropic",
+	}
+}
+
 // getRetryAfter extracts retry-after information from response headers
 func getRetryAfter(headers http.Header) *int {
 	if retryAfter := headers.Get("Retry-After"); retryAfter != "" {
@@ -316,26 +395,38 @@ type AnthropicChatCompletionRequest struct {
 	Temperature *float64           `json:"temperature,omitempty"`
 	StopSeq     []string           `json:"stop_sequences,omitempty"`
 	Stream      bool               `json:"stream,omitempty"`
+	Thinking    *AnthropicThinking `json:"thinking,omitempty"`
+}
+
+// AnthropicThinking enables extended thinking with a token budget
+type AnthropicThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
 }
 
 // AnthropicChatCompletionResponse represents an Anthropic chat completion response
 type AnthropicChatCompletionResponse struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Role    string `json:"role"`
-	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	} `json:"content"`
-	Model        string `json:"model"`
-	StopReason   string `json:"stop_reason"`
-	StopSequence string `json:"stop_sequence"`
+	ID           string                  `json:"id"`
+	Type         string                  `json:"type"`
+	Role         string                  `json:"role"`
+	Content      []AnthropicContentBlock `json:"content"`
+	Model        string                  `json:"model"`
+	StopReason   string                  `json:"stop_reason"`
+	StopSequence string                  `json:"stop_sequence"`
 	Usage        struct {
 		InputTokens  int `json:"input_tokens"`
 		OutputTokens int `json:"output_tokens"`
 	} `json:"usage"`
 }
 
+// AnthropicContentBlock is a block of a response's content, either text or
+// thinking
+type AnthropicContentBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Thinking string `json:"thinking,omitempty"`
+}
+
 // AnthropicMessage represents a chat message in Anthropic format
 type AnthropicMessage struct {
 	Role    string `json:"role"`
@@ -344,11 +435,20 @@ type AnthropicMessage struct {
 
 // Complete implements the ProviderAdapter interface for text completions
 func (a *AnthropicAdapter) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
-	// Map generic request to Anthropic format
-	anthropicReq := a.mapCompletionRequest(req)
+	if err := a.applyValidationMode(utils.FindOutOfRangeCompletion(req, types.ProviderAnthropic)); err != nil {
+		return nil, err
+	}
+
+	// Map generic request to a pooled Anthropic payload
+	anthropicReq := payloadPool.Get().(*AnthropicChatCompletionRequest)
+	a.fillCompletionRequest(anthropicReq, req)
+
+	// Collect retry statistics for the response metadata
+	ctx, retryStats := httputil.WithRetryStats(ctx)
 
 	// Make HTTP request to Anthropic API
-	resp, err := a.makeRequest(ctx, "/messages", anthropicReq)
+	resp, err := a.makeRequest(ctx, "/messages", anthropicReq, nil)
+	releasePayload(anthropicReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make completion request: %w", err)
 	}
@@ -360,35 +460,61 @@ func (a *AnthropicAdapter) Complete(ctx context.Context, req CompletionRequest)
 	}
 
 	// Parse successful response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	buf := httputil.GetBuffer()
+	defer httputil.PutBuffer(buf)
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	var anthropicResp AnthropicChatCompletionResponse
-	if err := json.Unmarshal(body, &anthropicResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Anthropic response: %w", err)
+	if err := json.Unmarshal(buf.Bytes(), &anthropicResp); err != nil {
+		return nil, invalidResponseError("Anthropic response", err)
 	}
 
 	// Normalize response to generic format
-	return a.normalizeCompletionResponse(anthropicResp), nil
+	result := a.normalizeCompletionResponse(anthropicResp)
+	result.Metadata.RateLimit = httputil.ParseRateLimitHeaders(resp.Header, time.Now())
+	result.Metadata.Attempts = retryStats.Attempts
+	result.Metadata.RetryWait = retryStats.TotalWait
+	return result, nil
+}
+
+// payloadPool recycles outgoing request payloads, which are only needed
+// until they are encoded, together with their message slices
+var payloadPool = sync.Pool{
+	New: func() interface{} { return new(AnthropicChatCompletionRequest) },
+}
+
+// releasePayload clears an encoded payload and returns it to payloadPool
+func releasePayload(payload *AnthropicChatCompletionRequest) {
+	// Drop references to message contents but keep the capacity
+	messages := payload.Messages
+	for i := range messages {
+		messages[i] = AnthropicMessage{}
+	}
+	*payload = AnthropicChatCompletionRequest{Messages: messages[:0]}
+	payloadPool.Put(payload)
 }
 
 // mapCompletionRequest maps a generic CompletionRequest to Anthropic format
 func (a *AnthropicAdapter) mapCompletionRequest(req CompletionRequest) AnthropicChatCompletionRequest {
+	var anthropicReq AnthropicChatCompletionRequest
+	a.fillCompletionRequest(&anthropicReq, req)
+	return anthropicReq
+}
+
+// fillCompletionRequest maps req into anthropicReq, reusing the capacity of
+// its message slice
+func (a *AnthropicAdapter) fillCompletionRequest(anthropicReq *AnthropicChatCompletionRequest, req CompletionRequest) {
 	// Anthropic uses the messages API for both completion and chat
 	// Convert prompt to a user message
-	messages := []AnthropicMessage{
-		{
+	*anthropicReq = AnthropicChatCompletionRequest{
+		Model: modelOrDefault(req.Model, DefaultModel),
+		Messages: append(anthropicReq.Messages[:0], AnthropicMessage{
 			Role:    "user",
 			Content: req.Prompt,
-		},
-	}
-
-	anthropicReq := AnthropicChatCompletionRequest{
-		Model:    DefaultModel,
-		Messages: messages,
-		Stream:   req.Stream,
+		}),
+		Stream: req.Stream,
 	}
 
 	// Set max tokens (required for Anthropic)
@@ -431,8 +557,6 @@ func (a *AnthropicAdapter) mapCompletionRequest(req CompletionRequest) Anthropic
 	if len(req.Stop) > 0 {
 		anthropicReq.StopSeq = req.Stop
 	}
-
-	return anthropicReq
 }
 
 // normalizeCompletionResponse converts Anthropic response to generic format
@@ -450,17 +574,32 @@ func (a *AnthropicAdapter) normalizeCompletionResponse(resp AnthropicChatComplet
 			CompletionTokens: resp.Usage.OutputTokens,
 			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
 		},
-		FinishReason: resp.StopReason,
+		FinishReason:        resp.StopReason,
+		MatchedStopSequence: matchedStopSequence(resp.StopReason, resp.StopSequence),
+		Metadata: types.ResponseMetadata{
+			Provider:   types.ProviderAnthropic,
+			Model:      resp.Model,
+			ResponseID: resp.ID,
+		},
 	}
 }
 
 // ChatComplete implements the ProviderAdapter interface for chat completions
 func (a *AnthropicAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
-	// Map generic request to Anthropic format
-	anthropicReq := a.mapChatRequest(req)
+	if err := a.applyValidationMode(utils.FindOutOfRangeChat(req, types.ProviderAnthropic)); err != nil {
+		return nil, err
+	}
+
+	// Map generic request to a pooled Anthropic payload
+	anthropicReq := payloadPool.Get().(*AnthropicChatCompletionRequest)
+	a.fillChatRequest(anthropicReq, req)
+
+	// Collect retry statistics for the response metadata
+	ctx, retryStats := httputil.WithRetryStats(ctx)
 
 	// Make HTTP request to Anthropic API
-	resp, err := a.makeRequest(ctx, "/messages", anthropicReq)
+	resp, err := a.makeRequest(ctx, "/messages", anthropicReq, contentReaders(req.Messages))
+	releasePayload(anthropicReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make chat completion request: %w", err)
 	}
@@ -472,24 +611,77 @@ func (a *AnthropicAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*
 	}
 
 	// Parse successful response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	buf := httputil.GetBuffer()
+	defer httputil.PutBuffer(buf)
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	var anthropicResp AnthropicChatCompletionResponse
-	if err := json.Unmarshal(body, &anthropicResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Anthropic response: %w", err)
+	if err := json.Unmarshal(buf.Bytes(), &anthropicResp); err != nil {
+		return nil, invalidResponseError("Anthropic response", err)
 	}
 
 	// Normalize response to generic format
-	return a.normalizeChatResponse(anthropicResp), nil
+	result := a.normalizeChatResponse(anthropicResp)
+	result.Metadata.RateLimit = httputil.ParseRateLimitHeaders(resp.Header, time.Now())
+	result.Metadata.Attempts = retryStats.Attempts
+	result.Metadata.RetryWait = retryStats.TotalWait
+	return result, nil
+}
+
+// AnthropicCountTokensRequest represents an Anthropic token counting request
+type AnthropicCountTokensRequest struct {
+	Model    string             `json:"model"`
+	Messages []AnthropicMessage `json:"messages"`
+	System   string             `json:"system,omitempty"`
+}
+
+// CountTokens returns the exact number of input tokens for a chat request
+// using the /messages/count_tokens endpoint
+func (a *AnthropicAdapter) CountTokens(ctx context.Context, req ChatRequest) (int, error) {
+	chatReq := a.mapChatRequest(req)
+	countReq := AnthropicCountTokensRequest{
+		Model:    chatReq.Model,
+		Messages: chatReq.Messages,
+		System:   chatReq.System,
+	}
+
+	resp, err := a.makeRequest(ctx, "/messages/count_tokens", countReq, contentReaders(req.Messages))
+	if err != nil {
+		return 0, fmt.Errorf("failed to make token counting request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, a.parseErrorResponse(resp)
+	}
+
+	var countResp struct {
+		InputTokens int `json:"input_tokens"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&countResp); err != nil {
+		return 0, invalidResponseError("Anthropic token count", err)
+	}
+	return countResp.InputTokens, nil
 }
 
 // mapChatRequest maps a generic ChatRequest to Anthropic format
 func (a *AnthropicAdapter) mapChatRequest(req ChatRequest) AnthropicChatCompletionRequest {
-	anthropicReq := AnthropicChatCompletionRequest{
-		Model:  DefaultChatModel,
+	var anthropicReq AnthropicChatCompletionRequest
+	a.fillChatRequest(&anthropicReq, req)
+	return anthropicReq
+}
+
+// fillChatRequest maps req into anthropicReq, reusing the capacity of its
+// message slice
+func (a *AnthropicAdapter) fillChatRequest(anthropicReq *AnthropicChatCompletionRequest, req ChatRequest) {
+	messages := anthropicReq.Messages[:0]
+	if cap(messages) < len(req.Messages) {
+		messages = make([]AnthropicMessage, 0, len(req.Messages))
+	}
+	*anthropicReq = AnthropicChatCompletionRequest{
+		Model:  modelOrDefault(req.Model, DefaultChatModel),
 		Stream: req.Stream,
 	}
 
@@ -531,9 +723,14 @@ func (a *AnthropicAdapter) mapChatRequest(req ChatRequest) AnthropicChatCompleti
 
 	// Convert messages and handle system messages
 	var systemMessage string
-	var messages []AnthropicMessage
 
+	inputs := 0
 	for _, msg := range req.Messages {
+		if msg.ContentReader != nil {
+			// The request body streams the reader's content in place of its placeholder
+			msg.Content += httputil.InputPlaceholder(inputs)
+			inputs++
+		}
 		switch msg.Role {
 		case "system":
 			// Anthropic handles system messages separately
@@ -562,27 +759,74 @@ func (a *AnthropicAdapter) mapChatRequest(req ChatRequest) AnthropicChatCompleti
 		anthropicReq.System = systemMessage
 	}
 
-	return anthropicReq
+	// Thinking counts towards max_tokens, which must exceed the budget, and
+	// does not allow a temperature other than the default
+	if req.ReasoningBudget != nil {
+		anthropicReq.Thinking = &AnthropicThinking{Type: "enabled", BudgetTokens: *req.ReasoningBudget}
+		anthropicReq.MaxTokens += *req.ReasoningBudget
+		anthropicReq.Temperature = nil
+	}
+}
+
+// contentReaders returns the readers of messages streaming their content,
+// in the order fillChatRequest numbers their placeholders
+func contentReaders(messages []Message) []io.Reader {
+	var readers []io.Reader
+	for _, msg := range messages {
+		if msg.ContentReader != nil {
+			readers = append(readers, msg.ContentReader)
+		}
+	}
+	return readers
 }
 
 // normalizeChatResponse converts Anthropic response to generic format
 func (a *AnthropicAdapter) normalizeChatResponse(resp AnthropicChatCompletionResponse) *ChatResponse {
-	// Extract text from content array
-	text := ""
-	if len(resp.Content) > 0 && resp.Content[0].Type == "text" {
-		text = resp.Content[0].Text
+	// Extract text and thinking from the content array
+	var text, reasoning strings.Builder
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "thinking":
+			reasoning.WriteString(block.Thinking)
+		}
 	}
 
 	return &ChatResponse{
 		Message: Message{
-			Role:    "assistant",
-			Content: text,
+			Role:      "assistant",
+			Content:   text.String(),
+			Reasoning: reasoning.String(),
 		},
 		Usage: Usage{
 			PromptTokens:     resp.Usage.InputTokens,
 			CompletionTokens: resp.Usage.OutputTokens,
 			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
 		},
-		FinishReason: resp.StopReason,
+		FinishReason:        resp.StopReason,
+		MatchedStopSequence: matchedStopSequence(resp.StopReason, resp.StopSequence),
+		Metadata: types.ResponseMetadata{
+			Provider:   types.ProviderAnthropic,
+			Model:      resp.Model,
+			ResponseID: resp.ID,
+		},
+	}
+}
+
+// matchedStopSequence returns the stop sequence reported for a response that
+// stopped on one, or nil
+func matchedStopSequence(stopReason, stopSequence string) *string {
+	if stopReason != "stop_sequence" || stopSequence == "" {
+		return nil
+	}
+	return &stopSequence
+}
+
+// modelOrDefault returns the requested model, or fallback if none was requested
+func modelOrDefault(model, fallback string) string {
+	if model != "" {
+		return model
 	}
+	return fallback
 }
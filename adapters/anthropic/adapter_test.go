@@ -11,6 +11,7 @@ import (
 	"time"
 
 	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/types"
 )
 
 // MockHTTPClient implements the HTTPClient interface for testing
@@ -220,6 +221,10 @@ func TestAdapterMethods(t *testing.T) {
 		"max_tokens",
 		"stop_sequences",
 		"system_messages",
+		"token_counting",
+		"batch",
+		"reasoning",
+		"streaming_input",
 	}
 
 	if len(features) != len(expectedFeatures) {
@@ -252,6 +257,99 @@ func TestAdapterMethods(t *testing.T) {
 	}
 }
 
+// TestSupportedFeaturesAccuracy verifies that every advertised feature is
+// implemented and that unimplemented features are not advertised
+func TestSupportedFeaturesAccuracy(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{
+				StatusCode: 200,
+				Body:       `{"type": "message", "role": "assistant", "content": [{"type": "text", "text": "Hi"}], "stop_reason": "end_turn", "input_tokens": 5}`,
+			},
+		},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	ctx := context.Background()
+	probes := map[string]func() bool{
+		types.FeatureCompletion: func() bool {
+			_, err := adapter.Complete(ctx, CompletionRequest{Prompt: "Hi"})
+			return err == nil
+		},
+		types.FeatureChatCompletion: func() bool {
+			_, err := adapter.ChatComplete(ctx, ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+			return err == nil
+		},
+		types.FeatureStreaming: func() bool {
+			streamAdapter, _ := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+			streamAdapter.httpClient = httputil.NewClientWithHTTPClient(&MockHTTPClient{
+				responses: []MockResponse{{StatusCode: 200, Body: testStreamBody}},
+			}, 30*time.Second, 0)
+			stream, err := streamAdapter.StreamChat(ctx, ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+			if err != nil {
+				return false
+			}
+			defer stream.Close()
+			for {
+				if _, err := stream.Recv(); err != nil {
+					return err == io.EOF
+				}
+			}
+		},
+		types.FeatureTemperature: func() bool {
+			return adapter.mapCompletionRequest(CompletionRequest{Prompt: "Hi", Temperature: floatPtr(0.5)}).Temperature != nil
+		},
+		types.FeatureMaxTokens: func() bool {
+			return adapter.mapCompletionRequest(CompletionRequest{Prompt: "Hi", MaxTokens: intPtr(10)}).MaxTokens == 10
+		},
+		types.FeatureStopSequences: func() bool {
+			return len(adapter.mapCompletionRequest(CompletionRequest{Prompt: "Hi", Stop: []string{"\n"}}).StopSeq) == 1
+		},
+		types.FeatureSystemMessages: func() bool {
+			return adapter.mapChatRequest(ChatRequest{Messages: []Message{
+				{Role: "system", Content: "Be brief"},
+				{Role: "user", Content: "Hi"},
+			}}).System == "Be brief"
+		},
+		types.FeatureTokenCounting: func() bool {
+			count, err := adapter.CountTokens(ctx, ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+			return err == nil && count == 5
+		},
+		types.FeatureBatch: func() bool {
+			var batcher interface {
+				SubmitBatch(ctx context.Context, items []types.BatchItem) (*types.Batch, error)
+			} = adapter
+			return batcher != nil
+		},
+		types.FeatureReasoning: func() bool {
+			return adapter.mapChatRequest(ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}, ReasoningBudget: intPtr(2048)}).Thinking != nil
+		},
+		types.FeatureStreamingInput: func() bool {
+			_, err := adapter.ChatComplete(ctx, ChatRequest{Messages: []Message{{Role: "user", ContentReader: strings.NewReader("Hi")}}})
+			return err == nil
+		},
+	}
+
+	advertised := make(map[string]bool)
+	for _, feature := range adapter.SupportedFeatures() {
+		advertised[feature] = true
+		if _, ok := probes[feature]; !ok {
+			t.Errorf("Advertised feature %q has no accuracy probe", feature)
+		}
+	}
+
+	for feature, probe := range probes {
+		if implemented := probe(); implemented != advertised[feature] {
+			t.Errorf("Feature %q: advertised %v, implemented %v", feature, advertised[feature], implemented)
+		}
+	}
+}
+
 // Test successful completion request
 func TestComplete_Success(t *testing.T) {
 	mockClient := &MockHTTPClient{
@@ -567,6 +665,15 @@ func TestChatComplete_Success(t *testing.T) {
 		t.Errorf("Expected total tokens 24, got %d", resp.Usage.TotalTokens)
 	}
 
+	// Verify model version metadata
+	if resp.Metadata.Model != "claude-3-haiku-20240307" {
+		t.Errorf("Expected model 'claude-3-haiku-20240307', got %q", resp.Metadata.Model)
+	}
+
+	if resp.Metadata.ResponseID != "msg_test123" {
+		t.Errorf("Expected response ID 'msg_test123', got %q", resp.Metadata.ResponseID)
+	}
+
 	// Verify request mapping
 	lastReq := mockClient.GetLastRequest()
 	if lastReq == nil {
@@ -601,6 +708,149 @@ func TestChatComplete_Success(t *testing.T) {
 	}
 }
 
+func TestChatComplete_Reasoning(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{
+				StatusCode: 200,
+				Body: `{
+					"id": "msg_think",
+					"type": "message",
+					"role": "assistant",
+					"content": [
+						{"type": "thinking", "thinking": "17 * 3 = 51.", "signature": "sig"},
+						{"type": "text", "text": "51"}
+					],
+					"stop_reason": "end_turn",
+					"usage": {"input_tokens": 12, "output_tokens": 40}
+				}`,
+			},
+		},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	resp, err := adapter.ChatComplete(context.Background(), ChatRequest{
+		Messages:        []Message{{Role: "user", Content: "What is 17 * 3?"}},
+		MaxTokens:       intPtr(100),
+		Temperature:     floatPtr(0.7),
+		ReasoningBudget: intPtr(2048),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Message.Content != "51" || resp.Message.Reasoning != "17 * 3 = 51." {
+		t.Errorf("Expected the answer and reasoning to be separated, got %+v", resp.Message)
+	}
+
+	body, _ := io.ReadAll(mockClient.GetLastRequest().Body)
+	var anthropicReq AnthropicChatCompletionRequest
+	if err := json.Unmarshal(body, &anthropicReq); err != nil {
+		t.Fatalf("Failed to parse request body: %v", err)
+	}
+	if anthropicReq.Thinking == nil || anthropicReq.Thinking.BudgetTokens != 2048 {
+		t.Errorf("Expected thinking with a budget of 2048, got %+v", anthropicReq.Thinking)
+	}
+	if anthropicReq.MaxTokens != 2148 || anthropicReq.Temperature != nil {
+		t.Errorf("Expected max tokens to include the budget and no temperature, got %d and %v", anthropicReq.MaxTokens, anthropicReq.Temperature)
+	}
+}
+
+func TestChatComplete_StreamingInput(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{StatusCode: 200, Body: `{"type":"message","role":"assistant","content":[{"type":"text","text":"A report"}],"stop_reason":"end_turn"}`},
+		},
+	}
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	document := strings.Repeat("Revenue grew \"strongly\".\n", 1000)
+	resp, err := adapter.ChatComplete(context.Background(), ChatRequest{
+		Messages: []Message{
+			{Role: "system", ContentReader: strings.NewReader("Be brief.")},
+			{Role: "user", Content: "Summarize:\n\n", ContentReader: strings.NewReader(document)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Message.Content != "A report" {
+		t.Errorf("Expected the response content, got %q", resp.Message.Content)
+	}
+
+	req := mockClient.GetLastRequest()
+	if req.ContentLength != -1 {
+		t.Errorf("Expected a streamed body, got length %d", req.ContentLength)
+	}
+	body, _ := io.ReadAll(req.Body)
+	var anthropicReq AnthropicChatCompletionRequest
+	if err := json.Unmarshal(body, &anthropicReq); err != nil {
+		t.Fatalf("Failed to parse request body: %v", err)
+	}
+	if anthropicReq.System != "Be brief." {
+		t.Errorf("Expected the streamed system message, got %q", anthropicReq.System)
+	}
+	if len(anthropicReq.Messages) != 1 || anthropicReq.Messages[0].Content != "Summarize:\n\n"+document {
+		t.Errorf("Expected the content followed by the streamed document, got %+v", anthropicReq.Messages)
+	}
+}
+
+// Pooled payloads must not carry fields or messages over between requests
+func TestChatComplete_PayloadReuse(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{StatusCode: 200, Body: `{"type":"message","role":"assistant","content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn"}`},
+		},
+	}
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	ctx := context.Background()
+	long := ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: "Hi"},
+			{Role: "user", Content: "How are you?"},
+		},
+		Temperature: floatPtr(0.5),
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := adapter.ChatComplete(ctx, long); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := adapter.Complete(ctx, CompletionRequest{Prompt: "Once"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := adapter.ChatComplete(ctx, ChatRequest{Messages: []Message{{Role: "user", Content: "Twice"}}}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	expected := []string{
+		`{"model":"claude-3-haiku-20240307","max_tokens":1024,"messages":[{"role":"user","content":"Hello"},{"role":"assistant","content":"Hi"},{"role":"user","content":"How are you?"}],"system":"Be brief.","temperature":0.5}`,
+		`{"model":"claude-3-haiku-20240307","max_tokens":1024,"messages":[{"role":"user","content":"Once"}]}`,
+		`{"model":"claude-3-haiku-20240307","max_tokens":1024,"messages":[{"role":"user","content":"Twice"}]}`,
+	}
+	for i, req := range mockClient.requests {
+		body, _ := io.ReadAll(req.Body)
+		if string(body) != expected[i%3] {
+			t.Errorf("Request %d: expected %s, got %s", i, expected[i%3], body)
+		}
+	}
+}
+
 // Test error handling
 func TestComplete_ErrorHandling(t *testing.T) {
 	tests := []struct {
@@ -654,7 +904,7 @@ func TestComplete_ErrorHandling(t *testing.T) {
 			name:            "invalid JSON response",
 			statusCode:      500,
 			responseBody:    `{"invalid": json}`,
-			expectedErrType: "",
+			expectedErrType: "provider",
 			expectedMsg:     "anthropic api error (status 500)",
 		},
 	}
@@ -732,10 +982,7 @@ func TestNormalizeCompletionResponse(t *testing.T) {
 		{
 			name: "normal response",
 			response: AnthropicChatCompletionResponse{
-				Content: []struct {
-					Type string `json:"type"`
-					Text string `json:"text"`
-				}{
+				Content: []AnthropicContentBlock{
 					{
 						Type: "text",
 						Text: "Hello world!",
@@ -763,10 +1010,7 @@ func TestNormalizeCompletionResponse(t *testing.T) {
 		{
 			name: "empty content",
 			response: AnthropicChatCompletionResponse{
-				Content: []struct {
-					Type string `json:"type"`
-					Text string `json:"text"`
-				}{},
+				Content:    []AnthropicContentBlock{},
 				StopReason: "max_tokens",
 				Usage: struct {
 					InputTokens  int `json:"input_tokens"`
@@ -786,6 +1030,19 @@ func TestNormalizeCompletionResponse(t *testing.T) {
 				FinishReason: "max_tokens",
 			},
 		},
+		{
+			name: "stop sequence",
+			response: AnthropicChatCompletionResponse{
+				Content:      []AnthropicContentBlock{{Type: "text", Text: "1. Apples\n2. Pears"}},
+				StopReason:   "stop_sequence",
+				StopSequence: "\n3.",
+			},
+			expected: CompletionResponse{
+				Text:                "1. Apples\n2. Pears",
+				FinishReason:        "stop_sequence",
+				MatchedStopSequence: stringPtr("\n3."),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -803,6 +1060,11 @@ func TestNormalizeCompletionResponse(t *testing.T) {
 			if result.FinishReason != tt.expected.FinishReason {
 				t.Errorf("Expected finish reason %q, got %q", tt.expected.FinishReason, result.FinishReason)
 			}
+
+			if (result.MatchedStopSequence == nil) != (tt.expected.MatchedStopSequence == nil) ||
+				result.MatchedStopSequence != nil && *result.MatchedStopSequence != *tt.expected.MatchedStopSequence {
+				t.Errorf("Expected matched stop sequence %v, got %v", tt.expected.MatchedStopSequence, result.MatchedStopSequence)
+			}
 		})
 	}
 }
@@ -816,6 +1078,115 @@ func intPtr(i int) *int {
 	return &i
 }
 
+func stringPtr(s string) *string {
+	return &s
+}
+
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+// Test token counting via the count_tokens endpoint
+func TestCountTokens(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{StatusCode: 200, Body: `{"input_tokens": 14}`},
+		},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	count, err := adapter.CountTokens(context.Background(), ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "Be brief"},
+			{Role: "user", Content: "Hello"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 14 {
+		t.Errorf("Expected 14 tokens, got %d", count)
+	}
+
+	req := mockClient.requests[0]
+	if !strings.HasSuffix(req.URL.Path, "/messages/count_tokens") {
+		t.Errorf("Expected count_tokens endpoint, got %s", req.URL.Path)
+	}
+	body, _ := io.ReadAll(req.Body)
+	var sent AnthropicCountTokensRequest
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("Failed to decode request body: %v", err)
+	}
+	if sent.System != "Be brief" || len(sent.Messages) != 1 || sent.Model != DefaultChatModel {
+		t.Errorf("Unexpected request body: %s", body)
+	}
+}
+
+// benchHTTPClient returns the same successful response to every request
+type benchHTTPClient struct {
+	body string
+}
+
+func (b *benchHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(strings.NewReader(b.body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func BenchmarkAnthropicAdapter_ChatComplete(b *testing.B) {
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		b.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(&benchHTTPClient{
+		body: `{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"positive"}],"stop_reason":"end_turn","usage":{"input_tokens":24,"output_tokens":1}}`,
+	}, 30*time.Second, 0)
+
+	maxTokens := 5
+	req := ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "Answer with positive, negative or neutral."},
+			{Role: "user", Content: "I love it"},
+		},
+		MaxTokens: &maxTokens,
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := adapter.ChatComplete(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestChatComplete_ValidationModeStrict(t *testing.T) {
+	mockClient := &MockHTTPClient{}
+	adapter, err := NewAdapter(AdapterConfig{
+		APIKey:         "sk-ant-REDACTED",
+		ValidationMode: types.ValidationModeStrict,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	_, err = adapter.ChatComplete(context.Background(), ChatRequest{
+		Messages:    []Message{{Role: "user", Content: "Hello"}},
+		Temperature: floatPtr(1.5),
+	})
+	anthropicErr, ok := err.(*Error)
+	if !ok || anthropicErr.Type != "validation" || anthropicErr.Code != "out_of_range" {
+		t.Fatalf("Expected an out of range validation error, got %v", err)
+	}
+	if mockClient.GetLastRequest() != nil {
+		t.Errorf("Expected no request to be sent")
+	}
+}
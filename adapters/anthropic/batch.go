@@ -0,0 +1,176 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// AnthropicBatchRequest represents a Message Batches API submission
+type AnthropicBatchRequest struct {
+	Requests []AnthropicBatchRequestItem `json:"requests"`
+}
+
+// AnthropicBatchRequestItem represents one request of a batch submission
+type AnthropicBatchRequestItem struct {
+	CustomID string                         `json:"custom_id"`
+	Params   AnthropicChatCompletionRequest `json:"params"`
+}
+
+// AnthropicBatch represents a Message Batches API batch
+type AnthropicBatch struct {
+	ID               string `json:"id"`
+	Type             string `json:"type"`
+	ProcessingStatus string `json:"processing_status"`
+	RequestCounts    struct {
+		Processing int `json:"processing"`
+		Succeeded  int `json:"succeeded"`
+		Errored    int `json:"errored"`
+		Canceled   int `json:"canceled"`
+		Expired    int `json:"expired"`
+	} `json:"request_counts"`
+	CreatedAt  time.Time  `json:"created_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ResultsURL string     `json:"results_url"`
+}
+
+// AnthropicBatchResult represents one line of a batch results file
+type AnthropicBatchResult struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string                          `json:"type"`
+		Message AnthropicChatCompletionResponse `json:"message"`
+		Error   struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"error"`
+	} `json:"result"`
+}
+
+// SubmitBatch submits chat requests to the Message Batches API
+func (a *AnthropicAdapter) SubmitBatch(ctx context.Context, items []types.BatchItem) (*types.Batch, error) {
+	batchReq := AnthropicBatchRequest{Requests: make([]AnthropicBatchRequestItem, len(items))}
+	for i, item := range items {
+		params := a.mapChatRequest(item.Request)
+		params.Stream = false
+		batchReq.Requests[i] = AnthropicBatchRequestItem{CustomID: item.ID, Params: params}
+	}
+
+	resp, err := a.makeRequest(ctx, "/messages/batches", batchReq, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit batch: %w", err)
+	}
+	return a.parseBatch(resp)
+}
+
+// GetBatch returns the status of a Message Batches API batch
+func (a *AnthropicAdapter) GetBatch(ctx context.Context, id string) (*types.Batch, error) {
+	baseURL, headers, err := a.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.httpClient.Get(ctx, baseURL+"/messages/batches/"+url.PathEscape(id), headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+	return a.parseBatch(resp)
+}
+
+// ListBatchResults reads the results file of an ended batch. The file is
+// decoded as it is read, so large batches are not held in memory twice.
+func (a *AnthropicAdapter) ListBatchResults(ctx context.Context, id string) ([]types.BatchResult, error) {
+	baseURL, headers, err := a.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.httpClient.Get(ctx, baseURL+"/messages/batches/"+url.PathEscape(id)+"/results", headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch results: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, a.parseErrorResponse(resp)
+	}
+	defer resp.Body.Close()
+
+	var results []types.BatchResult
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var line AnthropicBatchResult
+		if err := decoder.Decode(&line); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, invalidResponseError("Anthropic batch results", err)
+		}
+		results = append(results, a.normalizeBatchResult(line))
+	}
+	return results, nil
+}
+
+// parseBatch parses a batch response, or the error response
+func (a *AnthropicAdapter) parseBatch(resp *http.Response) (*types.Batch, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, a.parseErrorResponse(resp)
+	}
+	defer resp.Body.Close()
+
+	var batch AnthropicBatch
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return nil, invalidResponseError("Anthropic batch", err)
+	}
+
+	result := &types.Batch{
+		ID:     batch.ID,
+		Status: types.BatchStatus(batch.ProcessingStatus),
+		Counts: types.BatchCounts{
+			Processing: batch.RequestCounts.Processing,
+			Succeeded:  batch.RequestCounts.Succeeded,
+			Errored:    batch.RequestCounts.Errored,
+			Canceled:   batch.RequestCounts.Canceled,
+			Expired:    batch.RequestCounts.Expired,
+		},
+		CreatedAt: batch.CreatedAt,
+		ExpiresAt: batch.ExpiresAt,
+	}
+	if batch.EndedAt != nil {
+		result.EndedAt = *batch.EndedAt
+	}
+	return result, nil
+}
+
+// normalizeBatchResult converts a batch results line to generic format
+func (a *AnthropicAdapter) normalizeBatchResult(line AnthropicBatchResult) types.BatchResult {
+	result := types.BatchResult{ID: line.CustomID}
+	switch line.Result.Type {
+	case "succeeded":
+		result.Response = a.normalizeChatResponse(line.Result.Message)
+	case "errored":
+		errorType := "provider"
+		if line.Result.Error.Error.Type == "invalid_request_error" {
+			errorType = "validation"
+		}
+		result.Err = &Error{
+			Type:     errorType,
+			Message:  line.Result.Error.Error.Message,
+			Code:     line.Result.Error.Error.Type,
+			Provider: "anthropic",
+		}
+	default:
+		// Canceled or expired before the request was processed
+		result.Err = &Error{
+			Type:     "provider",
+			Message:  fmt.Sprintf("batch request %s before it was processed", line.Result.Type),
+			Code:     line.Result.Type,
+			Provider: "anthropic",
+		}
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// testBatchBody is a batch in progress
+const testBatchBody = `{
+	"id": "msgbatch_01",
+	"type": "message_batch",
+	"processing_status": "in_progress",
+	"request_counts": {"processing": 2, "succeeded": 0, "errored": 0, "canceled": 0, "expired": 0},
+	"ended_at": null,
+	"created_at": "2024-09-24T18:37:24.100435Z",
+	"expires_at": "2024-09-25T18:37:24.100435Z",
+	"results_url": null
+}`
+
+func TestSubmitBatch(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{{StatusCode: 200, Body: testBatchBody}},
+	}
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	batch, err := adapter.SubmitBatch(context.Background(), []types.BatchItem{
+		{ID: "a", Request: ChatRequest{Messages: []Message{{Role: "system", Content: "Be brief"}, {Role: "user", Content: "Hi"}}}},
+		{ID: "b", Request: ChatRequest{Messages: []Message{{Role: "user", Content: "Bye"}}, Stream: true}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if batch.ID != "msgbatch_01" || batch.Status != types.BatchInProgress || batch.Counts.Processing != 2 || batch.Done() {
+		t.Errorf("Unexpected batch: %+v", batch)
+	}
+	if !batch.EndedAt.IsZero() || batch.CreatedAt.Year() != 2024 {
+		t.Errorf("Unexpected batch times: %+v", batch)
+	}
+
+	req := mockClient.GetLastRequest()
+	if req.URL.Path != "/v1/messages/batches" {
+		t.Errorf("Expected the batches endpoint, got %s", req.URL.Path)
+	}
+	body, _ := io.ReadAll(req.Body)
+	var sent AnthropicBatchRequest
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("Failed to decode request body: %v", err)
+	}
+	if len(sent.Requests) != 2 || sent.Requests[0].CustomID != "a" || sent.Requests[0].Params.System != "Be brief" {
+		t.Errorf("Unexpected batch request: %s", body)
+	}
+	if sent.Requests[1].Params.Stream || sent.Requests[1].Params.Model != DefaultChatModel {
+		t.Errorf("Expected unstreamed requests with the default model: %s", body)
+	}
+}
+
+func TestGetBatch(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{StatusCode: 200, Body: `{"id": "msgbatch_01", "processing_status": "ended", "request_counts": {"succeeded": 1, "errored": 1}, "ended_at": "2024-09-24T19:00:00Z"}`},
+			{StatusCode: 404, Body: `{"type": "error", "error": {"type": "not_found_error", "message": "batch not found"}}`},
+		},
+	}
+	adapter, _ := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	batch, err := adapter.GetBatch(context.Background(), "msgbatch_01")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !batch.Done() || batch.Counts.Succeeded != 1 || batch.Counts.Errored != 1 || batch.EndedAt.IsZero() {
+		t.Errorf("Unexpected batch: %+v", batch)
+	}
+	if path := mockClient.GetLastRequest().URL.Path; path != "/v1/messages/batches/msgbatch_01" {
+		t.Errorf("Unexpected path %s", path)
+	}
+
+	if _, err := adapter.GetBatch(context.Background(), "missing"); err == nil {
+		t.Error("Expected error for missing batch")
+	}
+}
+
+func TestListBatchResults(t *testing.T) {
+	results := `{"custom_id":"a","result":{"type":"succeeded","message":{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Hello"}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":2}}}}
+{"custom_id":"b","result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens too large"}}}}
+{"custom_id":"c","result":{"type":"expired"}}
+`
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{{StatusCode: 200, Body: results}},
+	}
+	adapter, _ := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	list, err := adapter.ListBatchResults(context.Background(), "msgbatch_01")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path := mockClient.GetLastRequest().URL.Path; path != "/v1/messages/batches/msgbatch_01/results" {
+		t.Errorf("Unexpected path %s", path)
+	}
+	if len(list) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(list))
+	}
+
+	if resp := list[0].Response; list[0].ID != "a" || resp == nil || resp.Message.Content != "Hello" || resp.Usage.TotalTokens != 12 {
+		t.Errorf("Unexpected succeeded result: %+v", list[0])
+	}
+	var apiErr *Error
+	if !errors.As(list[1].Err, &apiErr) || apiErr.Type != "validation" || apiErr.Message != "max_tokens too large" || list[1].Response != nil {
+		t.Errorf("Unexpected errored result: %+v", list[1])
+	}
+	if !errors.As(list[2].Err, &apiErr) || apiErr.Code != "expired" {
+		t.Errorf("Unexpected expired result: %+v", list[2])
+	}
+}
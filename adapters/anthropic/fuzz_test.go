@@ -0,0 +1,69 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/testutil"
+)
+
+// fuzzAdapter returns an adapter whose provider answers with status and body
+func fuzzAdapter(t *testing.T, status int, body string) *AnthropicAdapter {
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(&MockHTTPClient{
+		responses: []MockResponse{{StatusCode: status, Body: body}},
+	}, 30*time.Second, 0)
+	return adapter
+}
+
+func FuzzChatComplete(f *testing.F) {
+	testutil.AddResponseSeeds(f, testutil.AnthropicResponseSeeds)
+	f.Fuzz(func(t *testing.T, status int, body string) {
+		adapter := fuzzAdapter(t, testutil.FuzzStatus(status), body)
+		resp, err := adapter.ChatComplete(context.Background(), ChatRequest{
+			Messages: []Message{{Role: "user", Content: "Hello"}},
+		})
+		var anthropicErr *Error
+		if err != nil && !errors.As(err, &anthropicErr) {
+			t.Errorf("Expected a structured error, got %T: %v", err, err)
+		}
+		if err == nil && resp == nil {
+			t.Errorf("Expected a response or an error")
+		}
+	})
+}
+
+func FuzzStreamChat(f *testing.F) {
+	testutil.AddStreamSeeds(f, testutil.AnthropicStreamSeeds)
+	f.Fuzz(func(t *testing.T, body string) {
+		adapter := fuzzAdapter(t, 200, body)
+		stream, err := adapter.StreamChat(context.Background(), ChatRequest{
+			Messages: []Message{{Role: "user", Content: "Hello"}},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer stream.Close()
+
+		// Every Recv consumes at least one event, so the stream ends
+		for i := 0; i <= len(body); i++ {
+			_, err := stream.Recv()
+			if err == nil {
+				continue
+			}
+			var anthropicErr *Error
+			if err != io.EOF && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.As(err, &anthropicErr) {
+				t.Errorf("Expected a structured error, got %T: %v", err, err)
+			}
+			return
+		}
+		t.Errorf("Expected the stream to end")
+	})
+}
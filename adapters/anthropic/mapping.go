@@ -0,0 +1,83 @@
+package anthropic
+
+import (
+	"fmt"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// MapCompletionRequest returns the payload the adapter would send for req
+// without sending it. Defaults are taken from config; no API key is needed.
+func MapCompletionRequest(config AdapterConfig, req CompletionRequest) (*types.RequestMapping, error) {
+	a := &AnthropicAdapter{config: config}
+	payload := a.mapCompletionRequest(req)
+	mapping := &types.RequestMapping{Provider: types.ProviderAnthropic, Endpoint: "/messages", Payload: payload}
+	mapping.Changes = append(mapping.Changes, types.MappingChange{
+		Parameter: "prompt",
+		Action:    types.MappingConverted,
+		Detail:    "sent as a single user message to the messages API",
+	})
+	mapping.Changes = append(mapping.Changes, commonChanges(req.Model, DefaultModel, req.MaxTokens, payload.MaxTokens, req.Stop)...)
+	return mapping, nil
+}
+
+// MapChatRequest returns the payload the adapter would send for req without
+// sending it. Defaults are taken from config; no API key is needed.
+func MapChatRequest(config AdapterConfig, req ChatRequest) (*types.RequestMapping, error) {
+	a := &AnthropicAdapter{config: config}
+	payload := a.mapChatRequest(req)
+	mapping := &types.RequestMapping{Provider: types.ProviderAnthropic, Endpoint: "/messages", Payload: payload}
+	mapping.Changes = append(mapping.Changes, commonChanges(req.Model, DefaultChatModel, req.MaxTokens, payload.MaxTokens, nil)...)
+	for i, msg := range req.Messages {
+		switch msg.Role {
+		case "system":
+			mapping.Changes = append(mapping.Changes, types.MappingChange{
+				Parameter: fmt.Sprintf("messages[%d]", i),
+				Action:    types.MappingMoved,
+				Detail:    "system message moved to the top-level \"system\" field",
+			})
+		case "user", "assistant":
+		default:
+			mapping.Changes = append(mapping.Changes, types.MappingChange{
+				Parameter: fmt.Sprintf("messages[%d]", i),
+				Action:    types.MappingConverted,
+				Detail:    fmt.Sprintf("unsupported role %q sent as a user message prefixed with the role", msg.Role),
+			})
+		}
+	}
+	return mapping, nil
+}
+
+// commonChanges reports the model, max_tokens and stop sequence mapping shared by both request types
+func commonChanges(model, defaultModel string, maxTokens *int, mappedMaxTokens int, stop []string) []types.MappingChange {
+	var changes []types.MappingChange
+	if model == "" {
+		changes = append(changes, types.MappingChange{
+			Parameter: "model",
+			Action:    types.MappingDefaulted,
+			Detail:    fmt.Sprintf("no model requested, using %s", defaultModel),
+		})
+	}
+	switch {
+	case maxTokens == nil:
+		changes = append(changes, types.MappingChange{
+			Parameter: "max_tokens",
+			Action:    types.MappingDefaulted,
+			Detail:    fmt.Sprintf("required by Anthropic, set to %d", mappedMaxTokens),
+		})
+	case *maxTokens != mappedMaxTokens:
+		changes = append(changes, types.MappingChange{
+			Parameter: "max_tokens",
+			Action:    types.MappingClamped,
+			Detail:    fmt.Sprintf("%d exceeds the Anthropic limit, sent as %d", *maxTokens, mappedMaxTokens),
+		})
+	}
+	if len(stop) > 0 {
+		changes = append(changes, types.MappingChange{
+			Parameter: "stop",
+			Action:    types.MappingRenamed,
+			Detail:    "sent as \"stop_sequences\"",
+		})
+	}
+	return changes
+}
@@ -0,0 +1,171 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/internal/utils"
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// anthropicStreamEvent is the union of the Messages API stream event payloads
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type         string `json:"type"`
+		Text         string `json:"text"`
+		Thinking     string `json:"thinking"`
+		StopReason   string `json:"stop_reason"`
+		StopSequence string `json:"stop_sequence"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// StreamChat streams a chat completion using server-sent events.
+//
+// The returned stream aborts with httputil.ErrStreamStalled when no data,
+// including pings, arrives within the configured StreamIdleTimeout.
+func (a *AnthropicAdapter) StreamChat(ctx context.Context, req ChatRequest) (types.StreamReader, error) {
+	if err := a.applyValidationMode(utils.FindOutOfRangeChat(req, types.ProviderAnthropic)); err != nil {
+		return nil, err
+	}
+
+	anthropicReq := a.mapChatRequest(req)
+	anthropicReq.Stream = true
+
+	jsonBody, err := json.Marshal(anthropicReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	baseURL, headers, err := a.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, retryStats := httputil.WithRetryStats(ctx)
+	var resp *http.Response
+	if inputs := contentReaders(req.Messages); len(inputs) > 0 {
+		resp, err = a.httpClient.PostStreamInputs(ctx, baseURL+"/messages", headers, jsonBody, inputs)
+	} else {
+		resp, err = a.httpClient.PostStream(ctx, baseURL+"/messages", headers, jsonBody)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to make streaming chat request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, a.parseErrorResponse(resp)
+	}
+
+	idleTimeout := a.config.StreamIdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = httputil.DefaultStreamIdleTimeout
+	}
+
+	return &chatStream{
+		reader: a.httpClient.NewSSEReader(resp.Body, idleTimeout),
+		metadata: types.ResponseMetadata{
+			Provider:  types.ProviderAnthropic,
+			RateLimit: httputil.ParseRateLimitHeaders(resp.Header, time.Now()),
+			Attempts:  retryStats.Attempts,
+			RetryWait: retryStats.TotalWait,
+		},
+	}, nil
+}
+
+// chatStream converts Anthropic stream events into generic chunks
+type chatStream struct {
+	reader       *httputil.SSEReader
+	metadata     types.ResponseMetadata
+	sentMetadata bool
+	inputTokens  int
+	done         bool
+}
+
+// Recv returns the next chunk, or io.EOF after the message_stop event
+func (s *chatStream) Recv() (types.StreamChunk, error) {
+	for !s.done {
+		event, err := s.reader.Next()
+		if err == io.EOF {
+			return types.StreamChunk{}, fmt.Errorf("stream ended before message_stop: %w", io.ErrUnexpectedEOF)
+		}
+		if err != nil {
+			return types.StreamChunk{}, err
+		}
+
+		var payload anthropicStreamEvent
+		if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+			return types.StreamChunk{}, invalidResponseError("Anthropic stream event", err)
+		}
+
+		switch payload.Type {
+		case "message_start":
+			s.metadata.Model = payload.Message.Model
+			s.metadata.ResponseID = payload.Message.ID
+			s.inputTokens = payload.Message.Usage.InputTokens
+		case "content_block_delta":
+			if payload.Delta.Type == "text_delta" && payload.Delta.Text != "" {
+				return s.chunk(types.StreamChunk{Delta: payload.Delta.Text}), nil
+			}
+			if payload.Delta.Type == "thinking_delta" && payload.Delta.Thinking != "" {
+				return s.chunk(types.StreamChunk{ReasoningDelta: payload.Delta.Thinking}), nil
+			}
+		case "message_delta":
+			return s.chunk(types.StreamChunk{
+				FinishReason:        payload.Delta.StopReason,
+				MatchedStopSequence: matchedStopSequence(payload.Delta.StopReason, payload.Delta.StopSequence),
+				Usage: &Usage{
+					PromptTokens:     s.inputTokens,
+					CompletionTokens: payload.Usage.OutputTokens,
+					TotalTokens:      s.inputTokens + payload.Usage.OutputTokens,
+				},
+			}), nil
+		case "message_stop":
+			s.done = true
+		case "error":
+			return types.StreamChunk{}, &Error{
+				Type:     "provider",
+				Message:  payload.Error.Message,
+				Code:     payload.Error.Type,
+				Provider: "anthropic",
+			}
+		}
+	}
+
+	return types.StreamChunk{}, io.EOF
+}
+
+// Close closes the response body
+func (s *chatStream) Close() error {
+	return s.reader.Close()
+}
+
+// chunk attaches the response metadata to the first chunk
+func (s *chatStream) chunk(chunk types.StreamChunk) types.StreamChunk {
+	if !s.sentMetadata {
+		metadata := s.metadata
+		chunk.Metadata = &metadata
+		s.sentMetadata = true
+	}
+	return chunk
+}
@@ -0,0 +1,264 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// testStreamBody is a complete Messages API event stream
+const testStreamBody = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_01","model":"claude-3-haiku-20240307","usage":{"input_tokens":12,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+: keep-alive
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" there"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":6}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+// stallingHTTPClient returns a response whose body sends prefix and then hangs
+type stallingHTTPClient struct {
+	prefix string
+}
+
+func (s *stallingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	reader, writer := io.Pipe()
+	go writer.Write([]byte(s.prefix))
+	return &http.Response{
+		StatusCode: 200,
+		Body:       reader,
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestStreamChat(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{{StatusCode: 200, Body: testStreamBody}},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	stream, err := adapter.StreamChat(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var text strings.Builder
+	var finishReason string
+	var usage *Usage
+	first := true
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if first {
+			if chunk.Metadata == nil || chunk.Metadata.ResponseID != "msg_01" || chunk.Metadata.Model != "claude-3-haiku-20240307" {
+				t.Errorf("Expected metadata on first chunk, got %+v", chunk.Metadata)
+			}
+			first = false
+		} else if chunk.Metadata != nil {
+			t.Errorf("Expected metadata only on the first chunk")
+		}
+		text.WriteString(chunk.Delta)
+		if chunk.FinishReason != "" {
+			finishReason = chunk.FinishReason
+			usage = chunk.Usage
+			if chunk.MatchedStopSequence != nil {
+				t.Errorf("Expected no matched stop sequence for end_turn, got %q", *chunk.MatchedStopSequence)
+			}
+		}
+	}
+
+	if text.String() != "Hello there" {
+		t.Errorf("Expected 'Hello there', got %q", text.String())
+	}
+	if finishReason != "end_turn" {
+		t.Errorf("Expected finish reason 'end_turn', got %q", finishReason)
+	}
+	if usage == nil || usage.PromptTokens != 12 || usage.CompletionTokens != 6 || usage.TotalTokens != 18 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	req := mockClient.GetLastRequest()
+	if req.Header.Get("Accept") != "text/event-stream" {
+		t.Errorf("Expected event stream Accept header, got %q", req.Header.Get("Accept"))
+	}
+	body, _ := io.ReadAll(req.Body)
+	var sent AnthropicChatCompletionRequest
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("Failed to decode request body: %v", err)
+	}
+	if !sent.Stream {
+		t.Errorf("Expected stream to be enabled in request body: %s", body)
+	}
+}
+
+func TestStreamChat_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		expect func(error) bool
+	}{
+		{
+			name: "error event",
+			body: "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n",
+			expect: func(err error) bool {
+				var anthropicErr *Error
+				return errors.As(err, &anthropicErr) && anthropicErr.Code == "overloaded_error"
+			},
+		},
+		{
+			name: "truncated stream",
+			body: "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n",
+			expect: func(err error) bool {
+				return errors.Is(err, io.ErrUnexpectedEOF)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, _ := NewAdapter(AdapterConfig{APIKey: "sk-ant-REDACTED"})
+			adapter.httpClient = httputil.NewClientWithHTTPClient(&MockHTTPClient{
+				responses: []MockResponse{{StatusCode: 200, Body: tt.body}},
+			}, 30*time.Second, 0)
+
+			stream, err := adapter.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer stream.Close()
+
+			for {
+				_, err = stream.Recv()
+				if err != nil {
+					break
+				}
+			}
+			if !tt.expect(err) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestStreamChat_Stall(t *testing.T) {
+	adapter, err := NewAdapter(AdapterConfig{
+		APIKey:            "sk-ant-REDACTED",
+		StreamIdleTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(&stallingHTTPClient{
+		prefix: "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n",
+	}, 30*time.Second, 0)
+
+	stream, err := adapter.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	if err != nil || chunk.Delta != "Hi" {
+		t.Fatalf("Expected first chunk before the stall, got %+v, %v", chunk, err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := stream.Recv()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, httputil.ErrStreamStalled) {
+			t.Errorf("Expected ErrStreamStalled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Recv did not return after the idle timeout")
+	}
+}
+
+func TestStreamChat_ResponseTransformer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testStreamBody))
+	}))
+	defer server.Close()
+
+	var provider types.ProviderType
+	adapter, err := NewAdapter(AdapterConfig{
+		APIKey:  "sk-ant-REDACTED",
+		BaseURL: server.URL,
+		ResponseTransformer: func(p types.ProviderType, body []byte) ([]byte, error) {
+			provider = p
+			return []byte(strings.Replace(string(body), `"text":"Hello"`, `"text":"Howdy"`, 1)), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+
+	stream, err := adapter.StreamChat(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var text strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		text.WriteString(chunk.Delta)
+	}
+	if text.String() != "Howdy there" || provider != types.ProviderAnthropic {
+		t.Errorf("Expected transformed events from anthropic, got %q from %q", text.String(), provider)
+	}
+}
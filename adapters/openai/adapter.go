@@ -11,6 +11,7 @@ import (
 	"time"
 
 	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/internal/utils"
 	"github.com/ajeet-kumar1087/ai-providers/types"
 )
 
@@ -26,6 +27,15 @@ const (
 
 	// MaxTokenLimit is the maximum number of tokens supported
 	MaxTokenLimit = 4096
+
+	// DefaultSpeechModel is the default model to use for text-to-speech
+	DefaultSpeechModel = "tts-1"
+
+	// DefaultVoice is the default text-to-speech voice
+	DefaultVoice = "alloy"
+
+	// DefaultTranscriptionModel is the default model to use for speech-to-text
+	DefaultTranscriptionModel = "whisper-1"
 )
 
 // AdapterConfig represents the configuration needed for OpenAI adapter
@@ -37,6 +47,14 @@ type OpenAIAdapter struct {
 	config     AdapterConfig
 	baseURL    string
 	apiKey     string
+
+	// headers are sent with every request. They are built once and only read
+	// afterwards, with canonical names so setting them does not allocate.
+	headers map[string]string
+
+	// projectHeaders are sent instead of headers with requests for a project
+	// of Config.ProjectKeys, by project ID
+	projectHeaders map[string]map[string]string
 }
 
 // NewAdapter creates a new OpenAI adapter with the given configuration
@@ -64,13 +82,65 @@ func NewAdapter(config AdapterConfig) (*OpenAIAdapter, error) {
 	}
 
 	httpClient := httputil.NewClient(timeout, maxRetries)
+	httpClient.SetMaxRetryWait(config.MaxRetryWait)
+	httpClient.SetRequestCompression(config.RequestCompressionThreshold)
+	if transform := config.RequestTransformer; transform != nil {
+		httpClient.SetRequestTransformer(func(body []byte) ([]byte, error) {
+			return transform(types.ProviderOpenAI, body)
+		})
+	}
+	if transform := config.ResponseTransformer; transform != nil {
+		httpClient.SetResponseTransformer(func(body []byte) ([]byte, error) {
+			return transform(types.ProviderOpenAI, body)
+		})
+	}
+	if config.DebugPayloads {
+		httpClient.SetDebug(config.DebugLogger, config.DebugRedactFields, config.DebugBodyLimit)
+	}
+
+	adapter := &OpenAIAdapter{
+		httpClient:     httpClient,
+		config:         config,
+		baseURL:        baseURL,
+		apiKey:         config.APIKey,
+		headers:        authHeaders(config.APIKey, config.Organization, ""),
+		projectHeaders: make(map[string]map[string]string, len(config.ProjectKeys)),
+	}
+	for project, key := range config.ProjectKeys {
+		adapter.projectHeaders[project] = authHeaders(key, config.Organization, project)
+	}
+	return adapter, nil
+}
 
-	return &OpenAIAdapter{
-		httpClient: httpClient,
-		config:     config,
-		baseURL:    baseURL,
-		apiKey:     config.APIKey,
-	}, nil
+// authHeaders returns the headers of requests authenticated with apiKey,
+// made under an organization and project if not empty
+func authHeaders(apiKey, organization, project string) map[string]string {
+	headers := map[string]string{
+		"Authorization": "Bearer " + apiKey,
+		"Content-Type":  "application/json",
+	}
+	if organization != "" {
+		headers["Openai-Organization"] = organization
+	}
+	if project != "" {
+		headers["Openai-Project"] = project
+	}
+	return headers
+}
+
+// endpoint returns the base URL and headers of a request, using the API key
+// of the project selected with types.WithProject and the endpoint selected
+// with types.WithEndpoint, if any
+func (a *OpenAIAdapter) endpoint(ctx context.Context) (string, map[string]string, error) {
+	headers := a.headers
+	if project := types.ProjectFromContext(ctx); project != "" {
+		var ok bool
+		headers, ok = a.projectHeaders[project]
+		if !ok {
+			return "", nil, fmt.Errorf("no API key configured for project %q", project)
+		}
+	}
+	return httputil.ApplyEndpoint(ctx, a.config, a.baseURL, headers)
 }
 
 // validateConfig validates the OpenAI configuration
@@ -88,6 +158,12 @@ func validateConfig(config AdapterConfig) error {
 		return fmt.Errorf("OpenAI API key appears to be too short")
 	}
 
+	for project, key := range config.ProjectKeys {
+		if !strings.HasPrefix(strings.TrimSpace(key), "sk-") {
+			return fmt.Errorf("OpenAI API key of project %q should start with 'sk-'", project)
+		}
+	}
+
 	// Validate timeout
 	if config.Timeout < 0 {
 		return fmt.Errorf("timeout must be non-negative")
@@ -125,20 +201,27 @@ func (a *OpenAIAdapter) Name() string {
 	return "openai"
 }
 
-// SupportedFeatures returns a list of features supported by OpenAI
+// SupportedFeatures returns a list of features supported by OpenAI.
+// Only features the adapter implements are listed, as the client rejects
+// requests that need any other feature.
 func (a *OpenAIAdapter) SupportedFeatures() []string {
 	return []string{
-		"completion",
-		"chat_completion",
-		"streaming",
-		"temperature",
-		"max_tokens",
-		"stop_sequences",
-		"system_messages",
-		"function_calling",
+		types.FeatureCompletion,
+		types.FeatureTemperature,
+		types.FeatureMaxTokens,
+		types.FeatureStopSequences,
+		types.FeatureSpeech,
+		types.FeatureTranscription,
+		types.FeatureRealtime,
 	}
 }
 
+// CloseIdleConnections closes idle keep-alive connections; the client calls
+// it when it is closed
+func (a *OpenAIAdapter) CloseIdleConnections() {
+	a.httpClient.CloseIdleConnections()
+}
+
 // ValidateConfig validates the configuration for OpenAI adapter
 func (a *OpenAIAdapter) ValidateConfig(config AdapterConfig) error {
 	return validateConfig(config)
@@ -152,14 +235,13 @@ func (a *OpenAIAdapter) makeRequest(ctx context.Context, endpoint string, reques
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	// Prepare headers
-	headers := map[string]string{
-		"Authorization": "Bearer " + a.apiKey,
-		"Content-Type":  "application/json",
+	baseURL, headers, err := a.endpoint(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Make the request
-	url := a.baseURL + endpoint
+	url := baseURL + endpoint
 	resp, err := a.httpClient.Post(ctx, url, headers, jsonBody)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
@@ -187,8 +269,10 @@ func (a *OpenAIAdapter) parseErrorResponse(resp *http.Response) error {
 	}
 
 	if err := json.Unmarshal(body, &openaiError); err != nil {
-		// If we can't parse the error, return a generic error with the status code
-		return fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
+		// Proxies answer with HTML or plain text; keep the body as the
+		// message so the error is still classified by its status
+		openaiError.Error.Code = ""
+		openaiError.Error.Message = fmt.Sprintf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	message := openaiError.Error.Message
@@ -230,6 +314,31 @@ func (a *OpenAIAdapter) parseErrorResponse(resp *http.Response) error {
 	}
 }
 
+// applyValidationMode rejects or reports parameters beyond the provider
+// limits according to the configured validation mode, before the request
+// mapping clamps them. Requests made through the client arrive clamped.
+func (a *OpenAIAdapter) applyValidationMode(outOfRange []types.OutOfRangeParameter) error {
+	if err := utils.ApplyValidationMode(a.config.ValidationMode, a.config.OnOutOfRangeParameter, outOfRange); err != nil {
+		return &Error{
+			Type:     "validation",
+			Message:  err.Error(),
+			Code:     "out_of_range",
+			Provider: "openai",
+		}
+	}
+	return nil
+}
+
+// invalidResponseError reports a response body that could not be parsed
+func invalidResponseError(what string, err error) *Error {
+	return &Error{
+		Type:     "provider",
+		Message:  fmt.Sprintf("failed to parse %s: %v", what, err),
+		Code:     "invalid_response",
+		Provider: "openai",
+	}
+}
+
 // getRetryAfter extracts retry-after information from response headers
 func getRetryAfter(headers http.Header) *int {
 	if retryAfter := headers.Get("Retry-After"); retryAfter != "" {
@@ -270,6 +379,10 @@ func (e *Error) Error() string {
 }
 
 // Type aliases for imported types
+type SpeechRequest = types.SpeechRequest
+type BinaryResponse = types.BinaryResponse
+type TranscriptionRequest = types.TranscriptionRequest
+type TranscriptionResponse = types.TranscriptionResponse
 type CompletionRequest = types.CompletionRequest
 type CompletionResponse = types.CompletionResponse
 type ChatRequest = types.ChatRequest
@@ -281,21 +394,23 @@ type Usage = types.Usage
 
 // OpenAICompletionRequest represents an OpenAI completion request
 type OpenAICompletionRequest struct {
-	Model       string   `json:"model"`
-	Prompt      string   `json:"prompt"`
-	MaxTokens   *int     `json:"max_tokens,omitempty"`
-	Temperature *float64 `json:"temperature,omitempty"`
-	Stop        []string `json:"stop,omitempty"`
-	Stream      bool     `json:"stream,omitempty"`
+	Model       string             `json:"model"`
+	Prompt      string             `json:"prompt"`
+	MaxTokens   *int               `json:"max_tokens,omitempty"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	Stop        []string           `json:"stop,omitempty"`
+	LogitBias   map[string]float64 `json:"logit_bias,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
 }
 
 // OpenAICompletionResponse represents an OpenAI completion response
 type OpenAICompletionResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
+	ID                string `json:"id"`
+	Object            string `json:"object"`
+	Created           int64  `json:"created"`
+	Model             string `json:"model"`
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+	Choices           []struct {
 		Text         string `json:"text"`
 		Index        int    `json:"index"`
 		FinishReason string `json:"finish_reason"`
@@ -319,11 +434,12 @@ type OpenAIChatCompletionRequest struct {
 
 // OpenAIChatCompletionResponse represents an OpenAI chat completion response
 type OpenAIChatCompletionResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
+	ID                string `json:"id"`
+	Object            string `json:"object"`
+	Created           int64  `json:"created"`
+	Model             string `json:"model"`
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+	Choices           []struct {
 		Index   int `json:"index"`
 		Message struct {
 			Role    string `json:"role"`
@@ -338,6 +454,28 @@ type OpenAIChatCompletionResponse struct {
 	} `json:"usage"`
 }
 
+// OpenAISpeechRequest represents an OpenAI text-to-speech request
+type OpenAISpeechRequest struct {
+	Model          string   `json:"model"`
+	Input          string   `json:"input"`
+	Voice          string   `json:"voice"`
+	ResponseFormat string   `json:"response_format,omitempty"`
+	Speed          *float64 `json:"speed,omitempty"`
+}
+
+// OpenAITranscriptionResponse represents a verbose_json transcription
+// response, with times in seconds
+type OpenAITranscriptionResponse struct {
+	Text     string  `json:"text"`
+	Language string  `json:"language"`
+	Duration float64 `json:"duration"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+}
+
 // OpenAIMessage represents a chat message in OpenAI format
 type OpenAIMessage struct {
 	Role    string `json:"role"`
@@ -346,9 +484,16 @@ type OpenAIMessage struct {
 
 // Complete implements the ProviderAdapter interface for text completions
 func (a *OpenAIAdapter) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	if err := a.applyValidationMode(utils.FindOutOfRangeCompletion(req, types.ProviderOpenAI)); err != nil {
+		return nil, err
+	}
+
 	// Map generic request to OpenAI format
 	openaiReq := a.mapCompletionRequest(req)
 
+	// Collect retry statistics for the response metadata
+	ctx, retryStats := httputil.WithRetryStats(ctx)
+
 	// Make HTTP request to OpenAI API
 	resp, err := a.makeRequest(ctx, "/completions", openaiReq)
 	if err != nil {
@@ -362,24 +507,29 @@ func (a *OpenAIAdapter) Complete(ctx context.Context, req CompletionRequest) (*C
 	}
 
 	// Parse successful response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	buf := httputil.GetBuffer()
+	defer httputil.PutBuffer(buf)
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	var openaiResp OpenAICompletionResponse
-	if err := json.Unmarshal(body, &openaiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+	if err := json.Unmarshal(buf.Bytes(), &openaiResp); err != nil {
+		return nil, invalidResponseError("OpenAI response", err)
 	}
 
 	// Normalize response to generic format
-	return a.normalizeCompletionResponse(openaiResp), nil
+	result := a.normalizeCompletionResponse(openaiResp)
+	result.Metadata.RateLimit = httputil.ParseRateLimitHeaders(resp.Header, time.Now())
+	result.Metadata.Attempts = retryStats.Attempts
+	result.Metadata.RetryWait = retryStats.TotalWait
+	return result, nil
 }
 
 // mapCompletionRequest maps a generic CompletionRequest to OpenAI format
 func (a *OpenAIAdapter) mapCompletionRequest(req CompletionRequest) OpenAICompletionRequest {
 	openaiReq := OpenAICompletionRequest{
-		Model:  DefaultModel,
+		Model:  modelOrDefault(req.Model, DefaultModel),
 		Prompt: req.Prompt,
 		Stream: req.Stream,
 	}
@@ -426,6 +576,10 @@ func (a *OpenAIAdapter) mapCompletionRequest(req CompletionRequest) OpenAIComple
 		openaiReq.Stop = req.Stop
 	}
 
+	if len(req.LogitBias) > 0 {
+		openaiReq.LogitBias = req.LogitBias
+	}
+
 	return openaiReq
 }
 
@@ -447,6 +601,12 @@ func (a *OpenAIAdapter) normalizeCompletionResponse(resp OpenAICompletionRespons
 			TotalTokens:      resp.Usage.TotalTokens,
 		},
 		FinishReason: finishReason,
+		Metadata: types.ResponseMetadata{
+			Provider:          types.ProviderOpenAI,
+			Model:             resp.Model,
+			SystemFingerprint: resp.SystemFingerprint,
+			ResponseID:        resp.ID,
+		},
 	}
 }
 
@@ -455,3 +615,116 @@ func (a *OpenAIAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*Cha
 	// This will be implemented in task 5.3
 	return nil, fmt.Errorf("ChatComplete method not yet implemented")
 }
+
+// Speech generates audio for req with the /audio/speech endpoint. The audio is
+// returned as a stream that the caller must close; it is not read into memory.
+func (a *OpenAIAdapter) Speech(ctx context.Context, req SpeechRequest) (*BinaryResponse, error) {
+	voice := req.Voice
+	if voice == "" {
+		voice = DefaultVoice
+	}
+
+	jsonBody, err := json.Marshal(OpenAISpeechRequest{
+		Model:          modelOrDefault(req.Model, DefaultSpeechModel),
+		Input:          req.Input,
+		Voice:          voice,
+		ResponseFormat: req.Format,
+		Speed:          req.Speed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	baseURL, headers, err := a.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.httpClient.PostDownload(ctx, baseURL+"/audio/speech", headers, jsonBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make speech request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, a.parseErrorResponse(resp)
+	}
+
+	download := httputil.NewDownload(resp)
+	return &BinaryResponse{
+		Body:          download.Body,
+		ContentType:   download.ContentType,
+		ContentLength: download.ContentLength,
+	}, nil
+}
+
+// Transcribe transcribes a recording with the /audio/transcriptions
+// endpoint. The recording is streamed into the upload rather than read into
+// memory.
+func (a *OpenAIAdapter) Transcribe(ctx context.Context, req TranscriptionRequest) (*TranscriptionResponse, error) {
+	fields := map[string]string{
+		"model":           modelOrDefault(req.Model, DefaultTranscriptionModel),
+		"response_format": "verbose_json",
+	}
+	if req.Language != "" {
+		fields["language"] = req.Language
+	}
+	if req.Prompt != "" {
+		fields["prompt"] = req.Prompt
+	}
+
+	baseURL, headers, err := a.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, retryStats := httputil.WithRetryStats(ctx)
+	resp, err := a.httpClient.PostMultipart(ctx, baseURL+"/audio/transcriptions", headers, fields, []httputil.MultipartFile{
+		{FieldName: "file", FileName: req.FileName, Reader: req.Audio},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to make transcription request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, a.parseErrorResponse(resp)
+	}
+
+	var openaiResp OpenAITranscriptionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&openaiResp); err != nil {
+		return nil, invalidResponseError("OpenAI transcription", err)
+	}
+
+	result := &TranscriptionResponse{
+		Text:     openaiResp.Text,
+		Language: openaiResp.Language,
+		Duration: seconds(openaiResp.Duration),
+		Metadata: types.ResponseMetadata{
+			Provider:  types.ProviderOpenAI,
+			Model:     fields["model"],
+			RateLimit: httputil.ParseRateLimitHeaders(resp.Header, time.Now()),
+			Attempts:  retryStats.Attempts,
+			RetryWait: retryStats.TotalWait,
+		},
+	}
+	for _, segment := range openaiResp.Segments {
+		result.Segments = append(result.Segments, types.TranscriptionSegment{
+			Start: seconds(segment.Start),
+			End:   seconds(segment.End),
+			Text:  strings.TrimSpace(segment.Text),
+		})
+	}
+	return result, nil
+}
+
+// seconds converts a time in seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// modelOrDefault returns the requested model, or fallback if none was requested
+func modelOrDefault(model, fallback string) string {
+	if model != "" {
+		return model
+	}
+	return fallback
+}
@@ -6,11 +6,13 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/types"
 )
 
 // MockHTTPClient implements the HTTPClient interface for testing
@@ -214,13 +216,12 @@ func TestAdapterMethods(t *testing.T) {
 	features := adapter.SupportedFeatures()
 	expectedFeatures := []string{
 		"completion",
-		"chat_completion",
-		"streaming",
 		"temperature",
 		"max_tokens",
 		"stop_sequences",
-		"system_messages",
-		"function_calling",
+		"speech",
+		"transcription",
+		"realtime",
 	}
 
 	if len(features) != len(expectedFeatures) {
@@ -253,6 +254,81 @@ func TestAdapterMethods(t *testing.T) {
 	}
 }
 
+// TestSupportedFeaturesAccuracy verifies that every advertised feature is
+// implemented and that unimplemented features are not advertised
+func TestSupportedFeaturesAccuracy(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{
+				StatusCode: 200,
+				Body:       `{"choices": [{"text": "Hi", "message": {"role": "assistant", "content": "Hi"}, "finish_reason": "stop"}]}`,
+			},
+		},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-1234567890abcdef1234567890abcdef"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	ctx := context.Background()
+	probes := map[string]func() bool{
+		types.FeatureCompletion: func() bool {
+			_, err := adapter.Complete(ctx, CompletionRequest{Prompt: "Hi"})
+			return err == nil
+		},
+		types.FeatureChatCompletion: func() bool {
+			_, err := adapter.ChatComplete(ctx, ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+			return err == nil
+		},
+		types.FeatureTemperature: func() bool {
+			return adapter.mapCompletionRequest(CompletionRequest{Prompt: "Hi", Temperature: floatPtr(0.5)}).Temperature != nil
+		},
+		types.FeatureMaxTokens: func() bool {
+			return adapter.mapCompletionRequest(CompletionRequest{Prompt: "Hi", MaxTokens: intPtr(10)}).MaxTokens != nil
+		},
+		types.FeatureStopSequences: func() bool {
+			return len(adapter.mapCompletionRequest(CompletionRequest{Prompt: "Hi", Stop: []string{"\n"}}).Stop) == 1
+		},
+		types.FeatureSpeech: func() bool {
+			audio, err := adapter.Speech(ctx, SpeechRequest{Input: "Hi"})
+			if err != nil {
+				return false
+			}
+			audio.Close()
+			return true
+		},
+		types.FeatureTranscription: func() bool {
+			_, err := adapter.Transcribe(ctx, TranscriptionRequest{Audio: strings.NewReader("RIFF"), FileName: "hi.wav"})
+			return err == nil
+		},
+		types.FeatureRealtime: func() bool {
+			realtimeAdapter, _, _ := realtimeTestAdapter(t, nil)
+			session, err := realtimeAdapter.Realtime(ctx, RealtimeRequest{})
+			if err != nil {
+				return false
+			}
+			session.Close()
+			return true
+		},
+	}
+
+	advertised := make(map[string]bool)
+	for _, feature := range adapter.SupportedFeatures() {
+		advertised[feature] = true
+		if _, ok := probes[feature]; !ok {
+			t.Errorf("Advertised feature %q has no accuracy probe", feature)
+		}
+	}
+
+	for feature, probe := range probes {
+		if implemented := probe(); implemented != advertised[feature] {
+			t.Errorf("Feature %q: advertised %v, implemented %v", feature, advertised[feature], implemented)
+		}
+	}
+}
+
 // Test successful completion request
 func TestComplete_Success(t *testing.T) {
 	mockClient := &MockHTTPClient{
@@ -612,6 +688,210 @@ func TestComplete_ErrorHandling(t *testing.T) {
 	}
 }
 
+// Test rate limit headers are surfaced in response metadata
+func TestComplete_RateLimitMetadata(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{
+				StatusCode: 200,
+				Body:       `{"choices": [{"text": "Hi", "index": 0, "finish_reason": "stop"}]}`,
+				Headers: map[string]string{
+					"x-ratelimit-limit-requests":     "3500",
+					"x-ratelimit-remaining-requests": "3499",
+					"x-ratelimit-remaining-tokens":   "89000",
+				},
+			},
+		},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-1234567890abcdef1234567890abcdef"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	resp, err := adapter.Complete(context.Background(), CompletionRequest{Prompt: "Hello"})
+	if err != nil {
+		t.Fatalf("Expected successful completion, got error: %v", err)
+	}
+
+	status := resp.Metadata.RateLimit
+	if status == nil {
+		t.Fatalf("Expected rate limit metadata")
+	}
+	if status.RequestsLimit != 3500 || status.RequestsRemaining != 3499 || status.TokensRemaining != 89000 {
+		t.Errorf("Unexpected rate limit status: %+v", status)
+	}
+}
+
+func TestSpeech(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{
+				StatusCode: 200,
+				Body:       "ID3audio",
+				Headers:    map[string]string{"Content-Type": "audio/mpeg"},
+			},
+		},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-1234567890abcdef1234567890abcdef"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	audio, err := adapter.Speech(context.Background(), SpeechRequest{Input: "Hello", Format: "mp3", Speed: floatPtr(1.5)})
+	if err != nil {
+		t.Fatalf("Expected successful speech request, got error: %v", err)
+	}
+	defer audio.Close()
+
+	data, err := io.ReadAll(audio)
+	if err != nil {
+		t.Fatalf("Expected no error reading audio, got %v", err)
+	}
+	if string(data) != "ID3audio" || audio.ContentType != "audio/mpeg" {
+		t.Errorf("Unexpected audio %q of type %q", data, audio.ContentType)
+	}
+
+	req := mockClient.GetLastRequest()
+	if req.URL.Path != "/v1/audio/speech" {
+		t.Errorf("Expected the speech endpoint, got %s", req.URL.Path)
+	}
+	body, _ := io.ReadAll(req.Body)
+	var payload OpenAISpeechRequest
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Failed to parse request body: %v", err)
+	}
+	if payload.Model != DefaultSpeechModel || payload.Voice != DefaultVoice || payload.ResponseFormat != "mp3" || *payload.Speed != 1.5 {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+}
+
+func TestTranscribe(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{
+				StatusCode: 200,
+				Body: `{
+					"task": "transcribe",
+					"language": "english",
+					"duration": 4.5,
+					"text": "Hello there. General Kenobi.",
+					"segments": [
+						{"id": 0, "start": 0.0, "end": 2.25, "text": " Hello there."},
+						{"id": 1, "start": 2.25, "end": 4.5, "text": " General Kenobi."}
+					]
+				}`,
+			},
+		},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-1234567890abcdef1234567890abcdef"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	result, err := adapter.Transcribe(context.Background(), TranscriptionRequest{
+		Audio:    strings.NewReader("RIFF audio"),
+		FileName: "meeting.wav",
+		Language: "en",
+	})
+	if err != nil {
+		t.Fatalf("Expected successful transcription, got error: %v", err)
+	}
+	if result.Text != "Hello there. General Kenobi." || result.Duration != 4500*time.Millisecond || result.Language != "english" {
+		t.Errorf("Unexpected transcript: %+v", result)
+	}
+	if len(result.Segments) != 2 || result.Segments[1].Start != 2250*time.Millisecond || result.Segments[1].Text != "General Kenobi." {
+		t.Errorf("Unexpected segments: %+v", result.Segments)
+	}
+
+	req := mockClient.GetLastRequest()
+	if req.URL.Path != "/v1/audio/transcriptions" {
+		t.Errorf("Expected the transcription endpoint, got %s", req.URL.Path)
+	}
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("Failed to parse request body: %v", err)
+	}
+	if req.FormValue("model") != DefaultTranscriptionModel || req.FormValue("response_format") != "verbose_json" || req.FormValue("language") != "en" {
+		t.Errorf("Unexpected form values: %v", req.MultipartForm.Value)
+	}
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		t.Fatalf("Expected the recording to be uploaded, got %v", err)
+	}
+	audio, _ := io.ReadAll(file)
+	if header.Filename != "meeting.wav" || string(audio) != "RIFF audio" {
+		t.Errorf("Unexpected upload %q: %q", header.Filename, audio)
+	}
+}
+
+func TestSpeech_Error(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{
+				StatusCode: 400,
+				Body:       `{"error": {"message": "Invalid voice", "type": "invalid_request_error"}}`,
+			},
+		},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-1234567890abcdef1234567890abcdef"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	_, err = adapter.Speech(context.Background(), SpeechRequest{Input: "Hello", Voice: "nobody"})
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.Type != "validation" || apiErr.Message != "Invalid voice" {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+}
+
+func TestComplete_ModelVersionMetadata(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{
+				StatusCode: 200,
+				Body: `{"id": "cmpl-123", "model": "gpt-3.5-turbo-instruct-0914", "system_fingerprint": "fp_44709d6fcb",
+					"choices": [{"text": "Hi", "index": 0, "finish_reason": "stop"}]}`,
+			},
+		},
+	}
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-1234567890abcdef1234567890abcdef"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	resp, err := adapter.Complete(context.Background(), CompletionRequest{Prompt: "Hello"})
+	if err != nil {
+		t.Fatalf("Expected successful completion, got error: %v", err)
+	}
+
+	metadata := resp.Metadata
+	if metadata.Provider != types.ProviderOpenAI {
+		t.Errorf("Expected provider openai, got %q", metadata.Provider)
+	}
+	if metadata.Model != "gpt-3.5-turbo-instruct-0914" {
+		t.Errorf("Expected exact model version, got %q", metadata.Model)
+	}
+	if metadata.SystemFingerprint != "fp_44709d6fcb" {
+		t.Errorf("Expected system fingerprint fp_44709d6fcb, got %q", metadata.SystemFingerprint)
+	}
+	if metadata.ResponseID != "cmpl-123" {
+		t.Errorf("Expected response ID cmpl-123, got %q", metadata.ResponseID)
+	}
+	if metadata.Attempts != 1 || metadata.RetryWait != 0 {
+		t.Errorf("Expected a single attempt without retry wait, got %d attempts and %v", metadata.Attempts, metadata.RetryWait)
+	}
+}
+
 // Test response normalization
 func TestNormalizeCompletionResponse(t *testing.T) {
 	adapter := &OpenAIAdapter{}
@@ -716,3 +996,166 @@ func intPtr(i int) *int {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestRequestTransformer(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"choices": [{"text": "Hi", "index": 0, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	var provider types.ProviderType
+	adapter, err := NewAdapter(AdapterConfig{
+		APIKey:  "sk-1234567890abcdef1234567890abcdef",
+		BaseURL: server.URL,
+		RequestTransformer: func(p types.ProviderType, payload []byte) ([]byte, error) {
+			provider = p
+			return bytes.Replace(payload, []byte(`{`), []byte(`{"service_tier":"flex",`), 1), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+
+	if _, err := adapter.Complete(context.Background(), CompletionRequest{Prompt: "Hello"}); err != nil {
+		t.Fatalf("Expected successful completion, got error: %v", err)
+	}
+	if provider != types.ProviderOpenAI {
+		t.Errorf("Expected transformer to receive the openai provider, got %q", provider)
+	}
+	if received["service_tier"] != "flex" || received["prompt"] != "Hello" {
+		t.Errorf("Expected the transformed payload, got %v", received)
+	}
+}
+
+// Test requests for a project use its API key and project header
+func TestComplete_ProjectKeys(t *testing.T) {
+	body := `{"id": "cmpl-1", "object": "text_completion", "created": 1677652288, "model": "gpt-3.5-turbo-instruct",
+		"choices": [{"text": "Hi", "index": 0, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 2}}`
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{{StatusCode: 200, Body: body}, {StatusCode: 200, Body: body}},
+	}
+
+	config := AdapterConfig{
+		APIKey:       "sk-1234567890abcdef1234567890abcdef",
+		Organization: "org-platform",
+		ProjectKeys:  map[string]string{"proj_search": "sk-proj-search1234567890abcdef"},
+	}
+	adapter, err := NewAdapter(config)
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	ctx := types.WithProject(context.Background(), "proj_search")
+	if _, err := adapter.Complete(ctx, CompletionRequest{Prompt: "Hello"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lastReq := mockClient.GetLastRequest()
+	if auth := lastReq.Header.Get("Authorization"); auth != "Bearer sk-proj-search1234567890abcdef" {
+		t.Errorf("Expected the project key, got %q", auth)
+	}
+	if project := lastReq.Header.Get("OpenAI-Project"); project != "proj_search" {
+		t.Errorf("Expected project header proj_search, got %q", project)
+	}
+	if org := lastReq.Header.Get("OpenAI-Organization"); org != "org-platform" {
+		t.Errorf("Expected organization header org-platform, got %q", org)
+	}
+
+	if _, err := adapter.Complete(context.Background(), CompletionRequest{Prompt: "Hello"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lastReq = mockClient.GetLastRequest()
+	if auth := lastReq.Header.Get("Authorization"); auth != "Bearer "+config.APIKey {
+		t.Errorf("Expected the default key without a project, got %q", auth)
+	}
+	if project := lastReq.Header.Get("OpenAI-Project"); project != "" {
+		t.Errorf("Expected no project header, got %q", project)
+	}
+
+	ctx = types.WithProject(context.Background(), "proj_unknown")
+	if _, err := adapter.Complete(ctx, CompletionRequest{Prompt: "Hello"}); err == nil {
+		t.Errorf("Expected an error for an unknown project")
+	}
+}
+
+// Test requests made with an endpoint go to its base URL with its headers
+func TestComplete_Endpoint(t *testing.T) {
+	body := `{"id": "cmpl-1", "object": "text_completion", "created": 1677652288, "model": "gpt-3.5-turbo-instruct",
+		"choices": [{"text": "Hi", "index": 0, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 2}}`
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{{StatusCode: 200, Body: body}, {StatusCode: 200, Body: body}},
+	}
+
+	config := AdapterConfig{
+		APIKey:          "sk-1234567890abcdef1234567890abcdef",
+		AllowedBaseURLs: []string{"https://eu.gateway.example.com/v1/"},
+		AllowedHeaders:  []string{"X-Tenant"},
+	}
+	adapter, err := NewAdapter(config)
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	ctx := types.WithEndpoint(context.Background(), types.Endpoint{
+		BaseURL: "https://eu.gateway.example.com/v1",
+		Headers: map[string]string{"x-tenant": "acme"},
+	})
+	if _, err := adapter.Complete(ctx, CompletionRequest{Prompt: "Hello"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lastReq := mockClient.GetLastRequest()
+	if url := lastReq.URL.String(); url != "https://eu.gateway.example.com/v1/completions" {
+		t.Errorf("Expected the endpoint URL, got %q", url)
+	}
+	if tenant := lastReq.Header.Get("X-Tenant"); tenant != "acme" {
+		t.Errorf("Expected tenant header acme, got %q", tenant)
+	}
+	if auth := lastReq.Header.Get("Authorization"); auth != "Bearer "+config.APIKey {
+		t.Errorf("Expected the default headers to be kept, got %q", auth)
+	}
+
+	if _, err := adapter.Complete(context.Background(), CompletionRequest{Prompt: "Hello"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lastReq = mockClient.GetLastRequest()
+	if url := lastReq.URL.String(); url != DefaultBaseURL+"/completions" {
+		t.Errorf("Expected the default URL without an endpoint, got %q", url)
+	}
+	if tenant := lastReq.Header.Get("X-Tenant"); tenant != "" {
+		t.Errorf("Expected no tenant header without an endpoint, got %q", tenant)
+	}
+
+	ctx = types.WithEndpoint(context.Background(), types.Endpoint{BaseURL: "https://attacker.example.com"})
+	if _, err := adapter.Complete(ctx, CompletionRequest{Prompt: "Hello"}); err == nil {
+		t.Errorf("Expected an error for a base URL not in the allowlist")
+	}
+	ctx = types.WithEndpoint(context.Background(), types.Endpoint{Headers: map[string]string{"Authorization": "Bearer other"}})
+	if _, err := adapter.Complete(ctx, CompletionRequest{Prompt: "Hello"}); err == nil {
+		t.Errorf("Expected an error for a header not in the allowlist")
+	}
+}
+
+// Test logit bias is sent as logit_bias
+func TestMapCompletionRequest_LogitBias(t *testing.T) {
+	adapter := &OpenAIAdapter{}
+	payload, err := json.Marshal(adapter.mapCompletionRequest(CompletionRequest{
+		Prompt:    "Hi",
+		LogitBias: map[string]float64{"50256": -100},
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(payload), `"logit_bias":{"50256":-100}`) {
+		t.Errorf("Expected logit_bias in payload, got %s", payload)
+	}
+
+	payload, _ = json.Marshal(adapter.mapCompletionRequest(CompletionRequest{Prompt: "Hi"}))
+	if strings.Contains(string(payload), "logit_bias") {
+		t.Errorf("Expected no logit_bias without a bias, got %s", payload)
+	}
+}
@@ -0,0 +1,33 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/testutil"
+)
+
+func FuzzComplete(f *testing.F) {
+	testutil.AddResponseSeeds(f, testutil.OpenAIResponseSeeds)
+	f.Fuzz(func(t *testing.T, status int, body string) {
+		adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-1234567890abcdef1234567890abcdef"})
+		if err != nil {
+			t.Fatalf("Failed to create adapter: %v", err)
+		}
+		adapter.httpClient = httputil.NewClientWithHTTPClient(&MockHTTPClient{
+			responses: []MockResponse{{StatusCode: testutil.FuzzStatus(status), Body: body}},
+		}, 30*time.Second, 0)
+
+		resp, err := adapter.Complete(context.Background(), CompletionRequest{Prompt: "Hello"})
+		var openaiErr *Error
+		if err != nil && !errors.As(err, &openaiErr) {
+			t.Errorf("Expected a structured error, got %T: %v", err, err)
+		}
+		if err == nil && resp == nil {
+			t.Errorf("Expected a response or an error")
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package openai
+
+import (
+	"fmt"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// MapCompletionRequest returns the payload the adapter would send for req
+// without sending it. Defaults are taken from config; no API key is needed.
+func MapCompletionRequest(config AdapterConfig, req CompletionRequest) (*types.RequestMapping, error) {
+	a := &OpenAIAdapter{config: config}
+
+	payload := a.mapCompletionRequest(req)
+	mapping := &types.RequestMapping{Provider: types.ProviderOpenAI, Endpoint: "/completions", Payload: payload}
+	if req.Model == "" {
+		mapping.Changes = append(mapping.Changes, types.MappingChange{
+			Parameter: "model",
+			Action:    types.MappingDefaulted,
+			Detail:    fmt.Sprintf("no model requested, using %s", DefaultModel),
+		})
+	}
+	if req.MaxTokens != nil && (payload.MaxTokens == nil || *payload.MaxTokens != *req.MaxTokens) {
+		mapping.Changes = append(mapping.Changes, types.MappingChange{
+			Parameter: "max_tokens",
+			Action:    types.MappingClamped,
+			Detail:    fmt.Sprintf("%d is outside the OpenAI range 1-%d", *req.MaxTokens, MaxTokenLimit),
+		})
+	}
+	return mapping, nil
+}
+
+// MapChatRequest returns an error until chat completions are implemented
+func MapChatRequest(config AdapterConfig, req ChatRequest) (*types.RequestMapping, error) {
+	return nil, fmt.Errorf("chat completions are not yet implemented for OpenAI")
+}
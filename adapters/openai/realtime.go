@@ -0,0 +1,338 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/ajeet-kumar1087/ai-providers/internal/websocket"
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+const (
+	// DefaultRealtimeModel is the default model for realtime sessions
+	DefaultRealtimeModel = "gpt-4o-realtime-preview"
+
+	// realtimeTranscriptionModel transcribes the caller's audio with
+	// RealtimeRequest.TranscribeInput
+	realtimeTranscriptionModel = "whisper-1"
+)
+
+// Type aliases for realtime types
+type RealtimeRequest = types.RealtimeRequest
+type RealtimeEvent = types.RealtimeEvent
+type RealtimeSession = types.RealtimeSession
+
+// OpenAIRealtimeSessionConfig is the session of a realtime session.update event
+type OpenAIRealtimeSessionConfig struct {
+	Instructions            string                       `json:"instructions,omitempty"`
+	Voice                   string                       `json:"voice,omitempty"`
+	InputAudioFormat        string                       `json:"input_audio_format,omitempty"`
+	OutputAudioFormat       string                       `json:"output_audio_format,omitempty"`
+	InputAudioTranscription *OpenAIRealtimeTranscription `json:"input_audio_transcription,omitempty"`
+	TurnDetection           json.RawMessage              `json:"turn_detection,omitempty"`
+	Tools                   []OpenAIRealtimeTool         `json:"tools,omitempty"`
+	Temperature             *float64                     `json:"temperature,omitempty"`
+}
+
+// OpenAIRealtimeTranscription selects the model transcribing input audio
+type OpenAIRealtimeTranscription struct {
+	Model string `json:"model"`
+}
+
+// OpenAIRealtimeTool is a function the realtime model may call
+type OpenAIRealtimeTool struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// OpenAIRealtimeClientEvent is an event sent to a realtime session
+type OpenAIRealtimeClientEvent struct {
+	Type    string                       `json:"type"`
+	Session *OpenAIRealtimeSessionConfig `json:"session,omitempty"`
+	Audio   string                       `json:"audio,omitempty"`
+	Item    *OpenAIRealtimeItem          `json:"item,omitempty"`
+}
+
+// OpenAIRealtimeItem is a conversation item added to a realtime session
+type OpenAIRealtimeItem struct {
+	Type    string                  `json:"type"`
+	Role    string                  `json:"role,omitempty"`
+	Content []OpenAIRealtimeContent `json:"content,omitempty"`
+	CallID  string                  `json:"call_id,omitempty"`
+	Output  string                  `json:"output,omitempty"`
+}
+
+// OpenAIRealtimeContent is a part of a realtime message item
+type OpenAIRealtimeContent struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// OpenAIRealtimeServerEvent is an event received from a realtime session,
+// with the fields of the events the adapter reports
+type OpenAIRealtimeServerEvent struct {
+	Type       string `json:"type"`
+	ResponseID string `json:"response_id,omitempty"`
+	Delta      string `json:"delta,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+	CallID     string `json:"call_id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Arguments  string `json:"arguments,omitempty"`
+	Response   *struct {
+		ID    string `json:"id"`
+		Usage *struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage,omitempty"`
+	} `json:"response,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Realtime opens a realtime session over a WebSocket to the /realtime
+// endpoint and configures it with req. Realtime sessions are experimental.
+func (a *OpenAIAdapter) Realtime(ctx context.Context, req RealtimeRequest) (RealtimeSession, error) {
+	baseURL, headers, err := a.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header, len(headers)+1)
+	for name, value := range headers {
+		if name != "Content-Type" {
+			header.Set(name, value)
+		}
+	}
+	header.Set("OpenAI-Beta", "realtime=v1")
+
+	model := modelOrDefault(req.Model, DefaultRealtimeModel)
+	sessionURL := realtimeURL(baseURL) + "/realtime?model=" + url.QueryEscape(model)
+	conn, resp, err := websocket.Dial(ctx, sessionURL, header)
+	if err != nil {
+		if resp != nil {
+			return nil, a.parseErrorResponse(resp)
+		}
+		return nil, &Error{
+			Type:     "network",
+			Message:  fmt.Sprintf("failed to open realtime session: %v", err),
+			Provider: "openai",
+		}
+	}
+
+	session := &realtimeSession{conn: conn, model: model}
+	if err := session.send(OpenAIRealtimeClientEvent{Type: "session.update", Session: realtimeSessionConfig(req)}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return session, nil
+}
+
+// realtimeURL converts an HTTP base URL to its WebSocket equivalent
+func realtimeURL(baseURL string) string {
+	if rest, ok := strings.CutPrefix(baseURL, "https://"); ok {
+		return "wss://" + rest
+	}
+	if rest, ok := strings.CutPrefix(baseURL, "http://"); ok {
+		return "ws://" + rest
+	}
+	return baseURL
+}
+
+// realtimeSessionConfig maps a realtime request to OpenAI session settings
+func realtimeSessionConfig(req RealtimeRequest) *OpenAIRealtimeSessionConfig {
+	config := &OpenAIRealtimeSessionConfig{
+		Instructions:      req.Instructions,
+		Voice:             req.Voice,
+		InputAudioFormat:  req.InputAudioFormat,
+		OutputAudioFormat: req.OutputAudioFormat,
+		Temperature:       req.Temperature,
+	}
+	if req.TranscribeInput {
+		config.InputAudioTranscription = &OpenAIRealtimeTranscription{Model: realtimeTranscriptionModel}
+	}
+	if req.ManualTurns {
+		config.TurnDetection = json.RawMessage("null")
+	}
+	for _, tool := range req.Tools {
+		config.Tools = append(config.Tools, OpenAIRealtimeTool{
+			Type:        "function",
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters,
+		})
+	}
+	return config
+}
+
+// realtimeSession implements types.RealtimeSession for OpenAI
+type realtimeSession struct {
+	conn  *websocket.Conn
+	model string // The model the session was opened with
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// SendAudio implements types.RealtimeSession
+func (s *realtimeSession) SendAudio(audio []byte) error {
+	return s.send(OpenAIRealtimeClientEvent{Type: "input_audio_buffer.append", Audio: base64.StdEncoding.EncodeToString(audio)})
+}
+
+// CommitAudio implements types.RealtimeSession
+func (s *realtimeSession) CommitAudio() error {
+	return s.send(OpenAIRealtimeClientEvent{Type: "input_audio_buffer.commit"})
+}
+
+// SendText implements types.RealtimeSession
+func (s *realtimeSession) SendText(text string) error {
+	return s.send(OpenAIRealtimeClientEvent{Type: "conversation.item.create", Item: &OpenAIRealtimeItem{
+		Type:    "message",
+		Role:    "user",
+		Content: []OpenAIRealtimeContent{{Type: "input_text", Text: text}},
+	}})
+}
+
+// SendToolResult implements types.RealtimeSession
+func (s *realtimeSession) SendToolResult(callID, output string) error {
+	return s.send(OpenAIRealtimeClientEvent{Type: "conversation.item.create", Item: &OpenAIRealtimeItem{
+		Type:   "function_call_output",
+		CallID: callID,
+		Output: output,
+	}})
+}
+
+// CreateResponse implements types.RealtimeSession
+func (s *realtimeSession) CreateResponse() error {
+	return s.send(OpenAIRealtimeClientEvent{Type: "response.create"})
+}
+
+// Interrupt implements types.RealtimeSession
+func (s *realtimeSession) Interrupt() error {
+	return s.send(OpenAIRealtimeClientEvent{Type: "response.cancel"})
+}
+
+// Recv implements types.RealtimeSession, skipping events it does not report
+func (s *realtimeSession) Recv() (RealtimeEvent, error) {
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if s.isClosed() || errors.Is(err, websocket.ErrClosed) {
+				return RealtimeEvent{}, io.EOF
+			}
+			return RealtimeEvent{}, &Error{
+				Type:     "network",
+				Message:  fmt.Sprintf("realtime session failed: %v", err),
+				Provider: "openai",
+			}
+		}
+
+		var raw OpenAIRealtimeServerEvent
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return RealtimeEvent{}, invalidResponseError("realtime event", err)
+		}
+		if event, ok := normalizeRealtimeEvent(raw); ok {
+			if event.Type == types.RealtimeEventResponseDone {
+				event.Model = s.model
+			}
+			event.Raw = data
+			return event, nil
+		}
+	}
+}
+
+// normalizeRealtimeEvent maps an OpenAI realtime event to the generic
+// format, reporting false for events with no equivalent
+func normalizeRealtimeEvent(raw OpenAIRealtimeServerEvent) (RealtimeEvent, bool) {
+	event := RealtimeEvent{ResponseID: raw.ResponseID}
+	switch raw.Type {
+	case "response.audio.delta":
+		audio, err := base64.StdEncoding.DecodeString(raw.Delta)
+		if err != nil {
+			return RealtimeEvent{Type: types.RealtimeEventError, Text: "invalid audio delta: " + err.Error()}, true
+		}
+		event.Type = types.RealtimeEventAudio
+		event.Audio = audio
+	case "response.audio_transcript.delta":
+		event.Type = types.RealtimeEventTranscript
+		event.Text = raw.Delta
+	case "response.text.delta":
+		event.Type = types.RealtimeEventText
+		event.Text = raw.Delta
+	case "conversation.item.input_audio_transcription.completed":
+		event.Type = types.RealtimeEventInputTranscript
+		event.Text = raw.Transcript
+	case "input_audio_buffer.speech_started":
+		event.Type = types.RealtimeEventSpeechStarted
+	case "response.function_call_arguments.done":
+		event.Type = types.RealtimeEventToolCall
+		event.ToolCall = &types.RealtimeToolCall{ID: raw.CallID, Name: raw.Name, Arguments: raw.Arguments}
+	case "response.done":
+		event.Type = types.RealtimeEventResponseDone
+		if raw.Response != nil {
+			event.ResponseID = raw.Response.ID
+			if usage := raw.Response.Usage; usage != nil {
+				event.Usage = &Usage{
+					PromptTokens:     usage.InputTokens,
+					CompletionTokens: usage.OutputTokens,
+					TotalTokens:      usage.TotalTokens,
+				}
+			}
+		}
+	case "error":
+		event.Type = types.RealtimeEventError
+		if raw.Error != nil {
+			event.Text = raw.Error.Message
+			event.Code = raw.Error.Code
+		}
+	default:
+		return RealtimeEvent{}, false
+	}
+	return event, true
+}
+
+// send writes a client event to the session
+func (s *realtimeSession) send(event OpenAIRealtimeClientEvent) error {
+	if s.isClosed() {
+		return websocket.ErrClosed
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal realtime event: %w", err)
+	}
+	return s.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// isClosed reports whether Close was called
+func (s *realtimeSession) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Close implements types.RealtimeSession
+func (s *realtimeSession) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.conn.WriteClose(websocket.CloseNormal, "")
+	return s.conn.Close()
+}
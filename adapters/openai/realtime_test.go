@@ -0,0 +1,156 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/internal/websocket"
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// realtimeTestAdapter returns an adapter connected to a fake realtime API
+// that records the events it receives and sends the given events after the
+// session is configured
+func realtimeTestAdapter(t *testing.T, events []string) (*OpenAIAdapter, <-chan OpenAIRealtimeClientEvent, <-chan *http.Request) {
+	t.Helper()
+	received := make(chan OpenAIRealtimeClientEvent, 16)
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-1234567890abcdef1234567890abcdef" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"message": "Invalid API key", "type": "invalid_request_error", "code": "invalid_api_key"}}`))
+			return
+		}
+		conn, err := websocket.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		requests <- r
+
+		sent := false
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var event OpenAIRealtimeClientEvent
+			json.Unmarshal(data, &event)
+			received <- event
+			if !sent {
+				sent = true
+				for _, event := range events {
+					conn.WriteMessage(websocket.TextMessage, []byte(event))
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-1234567890abcdef1234567890abcdef", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	return adapter, received, requests
+}
+
+func TestRealtime(t *testing.T) {
+	adapter, received, requests := realtimeTestAdapter(t, []string{
+		`{"type": "session.updated"}`,
+		`{"type": "input_audio_buffer.speech_started"}`,
+		`{"type": "conversation.item.input_audio_transcription.completed", "transcript": "What's the weather?"}`,
+		`{"type": "response.audio_transcript.delta", "response_id": "resp_1", "delta": "Let me check"}`,
+		`{"type": "response.audio.delta", "response_id": "resp_1", "delta": "AAEC"}`,
+		`{"type": "response.function_call_arguments.done", "response_id": "resp_1", "call_id": "call_1", "name": "weather", "arguments": "{\"city\":\"Paris\"}"}`,
+		`{"type": "response.done", "response": {"id": "resp_1", "usage": {"input_tokens": 10, "output_tokens": 5, "total_tokens": 15}}}`,
+		`{"type": "error", "error": {"type": "invalid_request_error", "code": "unknown_call", "message": "Unknown call"}}`,
+	})
+
+	session, err := adapter.Realtime(context.Background(), RealtimeRequest{
+		Voice:           "verse",
+		TranscribeInput: true,
+		ManualTurns:     true,
+		Tools:           []types.RealtimeTool{{Name: "weather", Parameters: json.RawMessage(`{"type": "object"}`)}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer session.Close()
+
+	r := <-requests
+	if r.URL.Path != "/v1/realtime" || r.URL.Query().Get("model") != DefaultRealtimeModel || r.Header.Get("OpenAI-Beta") != "realtime=v1" {
+		t.Errorf("Unexpected session request %s with headers %v", r.URL, r.Header)
+	}
+	update := <-received
+	if update.Type != "session.update" || update.Session.Voice != "verse" || update.Session.InputAudioTranscription == nil ||
+		string(update.Session.TurnDetection) != "null" || len(update.Session.Tools) != 1 || update.Session.Tools[0].Type != "function" {
+		t.Errorf("Unexpected session update %+v", update)
+	}
+
+	want := []string{
+		types.RealtimeEventSpeechStarted,
+		types.RealtimeEventInputTranscript,
+		types.RealtimeEventTranscript,
+		types.RealtimeEventAudio,
+		types.RealtimeEventToolCall,
+		types.RealtimeEventResponseDone,
+		types.RealtimeEventError,
+	}
+	var events []RealtimeEvent
+	for range want {
+		event, err := session.Recv()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		events = append(events, event)
+	}
+	for i, event := range events {
+		if event.Type != want[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, want[i], event.Type)
+		}
+	}
+	if events[1].Text != "What's the weather?" || string(events[3].Audio) != "\x00\x01\x02" {
+		t.Errorf("Unexpected transcript or audio: %+v, %+v", events[1], events[3])
+	}
+	if call := events[4].ToolCall; call == nil || call.ID != "call_1" || call.Name != "weather" || call.Arguments != `{"city":"Paris"}` {
+		t.Errorf("Unexpected tool call %+v", call)
+	}
+	if usage := events[5].Usage; usage == nil || usage.TotalTokens != 15 || events[5].ResponseID != "resp_1" || events[5].Model != DefaultRealtimeModel {
+		t.Errorf("Unexpected response done event %+v", events[5])
+	}
+	if events[6].Code != "unknown_call" || events[6].Text != "Unknown call" {
+		t.Errorf("Unexpected error event %+v", events[6])
+	}
+
+	session.SendToolResult("call_1", `{"temperature": 21}`)
+	session.CreateResponse()
+	session.Interrupt()
+	if event := <-received; event.Type != "conversation.item.create" || event.Item.Type != "function_call_output" || event.Item.CallID != "call_1" {
+		t.Errorf("Unexpected tool result event %+v", event)
+	}
+	for _, want := range []string{"response.create", "response.cancel"} {
+		if event := <-received; event.Type != want {
+			t.Errorf("Expected %s, got %+v", want, event)
+		}
+	}
+
+	session.Close()
+	if _, err := session.Recv(); err != io.EOF {
+		t.Errorf("Expected io.EOF after Close, got %v", err)
+	}
+}
+
+func TestRealtime_Unauthorized(t *testing.T) {
+	adapter, _, _ := realtimeTestAdapter(t, nil)
+	adapter.headers = authHeaders("sk-wrong", "", "")
+
+	_, err := adapter.Realtime(context.Background(), RealtimeRequest{})
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.Type != "authentication" || apiErr.Code != "invalid_api_key" {
+		t.Errorf("Expected an authentication error, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// OpenAIUsagePage is a page of the organization usage and costs APIs
+type OpenAIUsagePage struct {
+	Data []struct {
+		StartTime int64 `json:"start_time"`
+		EndTime   int64 `json:"end_time"`
+		Results   []struct {
+			InputTokens      int64   `json:"input_tokens"`
+			OutputTokens     int64   `json:"output_tokens"`
+			NumModelRequests int64   `json:"num_model_requests"`
+			Model            *string `json:"model"`
+			ProjectID        *string `json:"project_id"`
+			Amount           struct {
+				Value    float64 `json:"value"`
+				Currency string  `json:"currency"`
+			} `json:"amount"`
+		} `json:"results"`
+	} `json:"data"`
+	HasMore  bool   `json:"has_more"`
+	NextPage string `json:"next_page"`
+}
+
+// Usage reads the organization's completions usage by model. It requires
+// an admin API key.
+func (a *OpenAIAdapter) Usage(ctx context.Context, query types.UsageQuery) ([]types.ProviderUsage, error) {
+	params, err := usageParams(query)
+	if err != nil {
+		return nil, err
+	}
+	groupBy := []string{"model"}
+	if query.GroupByProject {
+		groupBy = append(groupBy, "project_id")
+	}
+	for _, field := range groupBy {
+		params.Add("group_by", field)
+	}
+	return a.listUsage(ctx, "/organization/usage/completions", params)
+}
+
+// Costs reads the organization's billed costs in daily buckets. It requires
+// an admin API key.
+func (a *OpenAIAdapter) Costs(ctx context.Context, query types.UsageQuery) ([]types.ProviderUsage, error) {
+	if query.BucketWidth != 0 && query.BucketWidth != 24*time.Hour {
+		return nil, &Error{
+			Type:     "validation",
+			Message:  fmt.Sprintf("costs are only available in daily buckets, got: %v", query.BucketWidth),
+			Provider: "openai",
+		}
+	}
+	params, err := usageParams(query)
+	if err != nil {
+		return nil, err
+	}
+	if query.GroupByProject {
+		params.Set("group_by", "project_id")
+	}
+	return a.listUsage(ctx, "/organization/costs", params)
+}
+
+// usageParams returns the query parameters shared by the usage and costs APIs
+func usageParams(query types.UsageQuery) (url.Values, error) {
+	bucket := "1d"
+	switch query.BucketWidth {
+	case 0, 24 * time.Hour:
+	case time.Hour:
+		bucket = "1h"
+	case time.Minute:
+		bucket = "1m"
+	default:
+		return nil, &Error{
+			Type:     "validation",
+			Message:  fmt.Sprintf("bucket width must be 1m, 1h or 24h, got: %v", query.BucketWidth),
+			Provider: "openai",
+		}
+	}
+
+	params := url.Values{}
+	params.Set("start_time", strconv.FormatInt(query.Start.Unix(), 10))
+	if !query.End.IsZero() {
+		params.Set("end_time", strconv.FormatInt(query.End.Unix(), 10))
+	}
+	params.Set("bucket_width", bucket)
+	for _, project := range query.ProjectIDs {
+		params.Add("project_ids", project)
+	}
+	return params, nil
+}
+
+// listUsage reads every page of a usage or costs API
+func (a *OpenAIAdapter) listUsage(ctx context.Context, endpoint string, params url.Values) ([]types.ProviderUsage, error) {
+	baseURL, headers, err := a.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewPager(func(ctx context.Context, cursor string) ([]types.ProviderUsage, string, error) {
+		if cursor != "" {
+			params.Set("page", cursor)
+		}
+		resp, err := a.httpClient.Get(ctx, baseURL+endpoint+"?"+params.Encode(), headers)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get usage: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, "", a.parseErrorResponse(resp)
+		}
+
+		var page OpenAIUsagePage
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, "", invalidResponseError("OpenAI usage", err)
+		}
+
+		var usage []types.ProviderUsage
+		for _, bucket := range page.Data {
+			for _, result := range bucket.Results {
+				entry := types.ProviderUsage{
+					Start:            time.Unix(bucket.StartTime, 0).UTC(),
+					End:              time.Unix(bucket.EndTime, 0).UTC(),
+					Requests:         result.NumModelRequests,
+					PromptTokens:     result.InputTokens,
+					CompletionTokens: result.OutputTokens,
+					Cost:             result.Amount.Value,
+				}
+				if result.Model != nil {
+					entry.Model = *result.Model
+				}
+				if result.ProjectID != nil {
+					entry.ProjectID = *result.ProjectID
+				}
+				usage = append(usage, entry)
+			}
+		}
+
+		if !page.HasMore {
+			return usage, "", nil
+		}
+		return usage, page.NextPage, nil
+	}).All(ctx)
+}
@@ -0,0 +1,95 @@
+package openai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+func TestUsage(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{
+			{
+				StatusCode: 200,
+				Body: `{"object": "page", "data": [{"start_time": 1730419200, "end_time": 1730505600, "results": [
+					{"input_tokens": 1000, "output_tokens": 500, "num_model_requests": 5, "model": "gpt-4o-mini-2024-07-18", "project_id": "proj_a"}
+				]}], "has_more": true, "next_page": "page_2"}`,
+			},
+			{
+				StatusCode: 200,
+				Body: `{"object": "page", "data": [{"start_time": 1730505600, "end_time": 1730592000, "results": [
+					{"input_tokens": 10, "output_tokens": 2, "num_model_requests": 1, "model": "gpt-4o", "project_id": null}
+				]}], "has_more": false, "next_page": null}`,
+			},
+		},
+	}
+	adapter, err := NewAdapter(AdapterConfig{APIKey: "sk-admin-1234567890abcdef1234567890"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	usage, err := adapter.Usage(context.Background(), types.UsageQuery{
+		Start:          time.Unix(1730419200, 0),
+		ProjectIDs:     []string{"proj_a"},
+		GroupByProject: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("Expected both pages to be read, got %+v", usage)
+	}
+	first := usage[0]
+	if first.Model != "gpt-4o-mini-2024-07-18" || first.ProjectID != "proj_a" || first.PromptTokens != 1000 || first.CompletionTokens != 500 || first.Requests != 5 {
+		t.Errorf("Unexpected usage %+v", first)
+	}
+	if !first.Start.Equal(time.Unix(1730419200, 0)) || usage[1].ProjectID != "" {
+		t.Errorf("Unexpected buckets %+v", usage)
+	}
+
+	query := mockClient.requests[0].URL.Query()
+	if mockClient.requests[0].URL.Path != "/v1/organization/usage/completions" || query.Get("start_time") != "1730419200" || query.Get("bucket_width") != "1d" {
+		t.Errorf("Unexpected request %s", mockClient.requests[0].URL)
+	}
+	if groupBy := query["group_by"]; len(groupBy) != 2 || query.Get("project_ids") != "proj_a" {
+		t.Errorf("Expected grouping by model and project for proj_a, got %v", query)
+	}
+	if page := mockClient.requests[1].URL.Query().Get("page"); page != "page_2" {
+		t.Errorf("Expected the next page to be requested, got %q", page)
+	}
+}
+
+func TestCosts(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		responses: []MockResponse{{
+			StatusCode: 200,
+			Body: `{"object": "page", "data": [{"start_time": 1730419200, "end_time": 1730505600, "results": [
+				{"object": "organization.costs.result", "amount": {"value": 0.06, "currency": "usd"}, "line_item": null, "project_id": null}
+			]}], "has_more": false}`,
+		}},
+	}
+	adapter, _ := NewAdapter(AdapterConfig{APIKey: "sk-admin-1234567890abcdef1234567890"})
+	adapter.httpClient = httputil.NewClientWithHTTPClient(mockClient, 30*time.Second, 0)
+
+	costs, err := adapter.Costs(context.Background(), types.UsageQuery{Start: time.Unix(1730419200, 0)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(costs) != 1 || costs[0].Cost != 0.06 {
+		t.Errorf("Unexpected costs %+v", costs)
+	}
+	if mockClient.requests[0].URL.Path != "/v1/organization/costs" {
+		t.Errorf("Unexpected request %s", mockClient.requests[0].URL)
+	}
+
+	if _, err := adapter.Costs(context.Background(), types.UsageQuery{Start: time.Unix(1730419200, 0), BucketWidth: time.Hour}); err == nil {
+		t.Error("Expected an error for hourly costs")
+	}
+	if _, err := adapter.Usage(context.Background(), types.UsageQuery{Start: time.Unix(1730419200, 0), BucketWidth: 2 * time.Hour}); err == nil {
+		t.Error("Expected an error for an unsupported bucket width")
+	}
+}
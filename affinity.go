@@ -0,0 +1,67 @@
+package aiprovider
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultSessionAffinityTTL is how long an idle session stays pinned to its
+// client when FallbackOptions.SessionAffinityTTL is zero
+const DefaultSessionAffinityTTL = 30 * time.Minute
+
+// sessionPin is the client serving a session and when it last did
+type sessionPin struct {
+	index int
+	used  time.Time
+}
+
+// sessionPins records the client each session of a FallbackClient is pinned
+// to. Pins idle for longer than ttl are forgotten, so memory stays bounded by
+// the number of active sessions.
+type sessionPins struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	pins map[string]sessionPin
+}
+
+// newSessionPins returns an empty pin table
+func newSessionPins(ttl time.Duration) *sessionPins {
+	if ttl <= 0 {
+		ttl = DefaultSessionAffinityTTL
+	}
+	return &sessionPins{ttl: ttl, pins: make(map[string]sessionPin)}
+}
+
+// get returns the client index a session is pinned to. It reports false for
+// unknown or expired sessions, and always if p is nil or session is empty.
+func (p *sessionPins) get(session string) (int, bool) {
+	if p == nil || session == "" {
+		return 0, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pin, ok := p.pins[session]
+	if !ok || time.Since(pin.used) > p.ttl {
+		return 0, false
+	}
+	return pin.index, true
+}
+
+// set pins a session to the client at index, forgetting expired pins when a
+// new session is added. It does nothing if p is nil or session is empty.
+func (p *sessionPins) set(session string, index int) {
+	if p == nil || session == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if _, ok := p.pins[session]; !ok {
+		for id, pin := range p.pins {
+			if now.Sub(pin.used) > p.ttl {
+				delete(p.pins, id)
+			}
+		}
+	}
+	p.pins[session] = sessionPin{index: index, used: now}
+}
@@ -0,0 +1,384 @@
+// Package agent runs tool-using assistants on top of a client.
+//
+// An Agent combines a system prompt, a Registry of tools, a Memory strategy
+// bounding the history sent to the model and a model or request profile.
+// Run sends an input, calls the tools the model asks for and feeds their
+// output back until the model answers. The history is persisted in a Store
+// after every run, so an agent with the same ID picks up where it left off,
+// even in another process.
+//
+// Tools are described in the system prompt and called with a small JSON
+// protocol, so agents work with every provider, including those without
+// native function calling.
+//
+// An Orchestrator coordinates several agents: a planner model splits a task
+// into steps, dispatches them to executor agents and combines their results.
+//
+// Example:
+//
+//	tools, _ := agent.NewRegistry(agent.Tool{
+//		Name:        "weather",
+//		Description: "Returns the current weather for a city",
+//		Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+//		Call: func(ctx context.Context, args json.RawMessage) (string, error) {
+//			var in struct{ City string }
+//			json.Unmarshal(args, &in)
+//			return lookupWeather(ctx, in.City)
+//		},
+//	})
+//	store, _ := agent.NewFileStore("agents")
+//	assistant, err := agent.New(client, agent.Options{
+//		ID:           "support-" + userID,
+//		SystemPrompt: "You are a travel assistant.",
+//		Tools:        tools,
+//		Memory:       agent.WindowMemory{Messages: 20},
+//		Store:        store,
+//	})
+//	result, err := assistant.Run(ctx, "Do I need an umbrella in Oslo today?")
+//	fmt.Println(result.Output)
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// DefaultMaxSteps is the default number of model requests in one run
+const DefaultMaxSteps = 10
+
+// Message roles used in agent history
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// ErrMaxSteps is returned by Run when the model keeps calling tools after
+// Options.MaxSteps requests
+var ErrMaxSteps = errors.New("agent did not answer within the step limit")
+
+// ChatClient is the subset of the client API an Agent needs.
+// It is satisfied by aiprovider.Client.
+type ChatClient interface {
+	ChatComplete(ctx context.Context, req types.ChatRequest) (*types.ChatResponse, error)
+}
+
+// Options configures an Agent.
+type Options struct {
+	// ID identifies the agent's state in the Store (default: a random ID,
+	// so the state cannot be resumed by a new Agent)
+	ID string
+
+	// SystemPrompt describes the agent's role; tool instructions are
+	// appended to it (optional)
+	SystemPrompt string
+
+	// Tools are the tools the model may call (optional)
+	Tools *Registry
+
+	// Memory selects the history sent with each request (default: FullMemory)
+	Memory Memory
+
+	// Model selects the model (optional)
+	Model string
+
+	// Profile selects a RequestProfile registered on the client, e.g. for
+	// the model, temperature and token limit (optional); Model takes
+	// precedence over the profile's model
+	Profile string
+
+	// Temperature is applied to every request (optional)
+	Temperature *float64
+
+	// MaxSteps is the number of model requests one run may make
+	// Default: 10 if not specified
+	MaxSteps int
+
+	// Store persists the agent's state (default: a new MemoryStore)
+	Store Store
+}
+
+// Step is a tool call made during a run.
+type Step struct {
+	// Tool is the name of the called tool
+	Tool string `json:"tool"`
+
+	// Arguments are the model's arguments
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+
+	// Output is the tool's output
+	Output string `json:"output,omitempty"`
+
+	// Error describes why the call failed, if it did
+	Error string `json:"error,omitempty"`
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	// Output is the model's answer
+	Output string `json:"output"`
+
+	// Steps are the tool calls made, in order
+	Steps []Step `json:"steps,omitempty"`
+
+	// Usage is the total token usage of the run's requests
+	Usage types.Usage `json:"usage"`
+
+	// Response is the model's final response
+	Response *types.ChatResponse `json:"response,omitempty"`
+}
+
+// Agent is an assistant that calls tools and remembers its history.
+//
+// Agent is safe for concurrent use; concurrent runs are serialized so their
+// turns are not interleaved.
+type Agent struct {
+	client  ChatClient
+	options Options
+
+	mu sync.Mutex
+}
+
+// New creates an agent that sends requests through client
+func New(client ChatClient, options Options) (*Agent, error) {
+	if client == nil {
+		return nil, fmt.Errorf("agent requires a client")
+	}
+	if options.MaxSteps < 0 {
+		return nil, fmt.Errorf("max steps must be positive, got: %d", options.MaxSteps)
+	}
+	if options.ID == "" {
+		options.ID = newID()
+	}
+	if options.Memory == nil {
+		options.Memory = FullMemory{}
+	}
+	if options.MaxSteps == 0 {
+		options.MaxSteps = DefaultMaxSteps
+	}
+	if options.Store == nil {
+		options.Store = NewMemoryStore()
+	}
+	return &Agent{client: client, options: options}, nil
+}
+
+// newID returns a random agent ID
+func newID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("agent-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
+// ID returns the ID the agent's state is stored under
+func (a *Agent) ID() string {
+	return a.options.ID
+}
+
+// Run sends input to the agent and returns its answer.
+//
+// The model is sent the history selected by the memory strategy followed by
+// input. While it responds with tool calls, the tools are run and their
+// output is sent back as untrusted content, up to MaxSteps requests. The
+// run's messages are then appended to the stored history. If the run fails
+// the stored state is left unchanged, although tools already called are not
+// undone.
+func (a *Agent) Run(ctx context.Context, input string) (*Result, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, fmt.Errorf("agent input cannot be empty")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, err := a.state(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if types.SessionFromContext(ctx) == "" {
+		ctx = types.WithSession(ctx, a.options.ID)
+	}
+
+	history := append(state.Messages, types.Message{Role: RoleUser, Content: input})
+	result := &Result{}
+	for step := 0; step < a.options.MaxSteps; step++ {
+		resp, err := a.complete(ctx, history)
+		if err != nil {
+			return nil, err
+		}
+		result.Usage = addUsage(result.Usage, resp.Usage)
+		history = append(history, resp.Message)
+
+		call, ok := parseToolCall(resp.Message.Content)
+		if !ok || a.options.Tools == nil {
+			result.Output = resp.Message.Content
+			result.Response = resp
+
+			state.Messages = history
+			state.Usage = addUsage(state.Usage, result.Usage)
+			state.Runs++
+			state.UpdatedAt = time.Now()
+			if err := a.options.Store.Save(ctx, state); err != nil {
+				return nil, fmt.Errorf("failed to save agent state: %w", err)
+			}
+			return result, nil
+		}
+
+		output, err := a.options.Tools.call(ctx, call.Tool, call.Arguments)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		record := Step{Tool: call.Tool, Arguments: call.Arguments, Output: output}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		result.Steps = append(result.Steps, record)
+		history = append(history, types.Message{Role: RoleUser, Content: toolResultMessage(record), Untrusted: true})
+	}
+
+	return nil, fmt.Errorf("%w (%d requests)", ErrMaxSteps, a.options.MaxSteps)
+}
+
+// State returns the agent's stored state
+func (a *Agent) State(ctx context.Context) (*State, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.state(ctx)
+}
+
+// Reset deletes the agent's stored state, so the next run starts afresh
+func (a *Agent) Reset(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.options.Store.Delete(ctx, a.options.ID)
+}
+
+// state loads the agent's state, or returns an empty one
+func (a *Agent) state(ctx context.Context) (*State, error) {
+	state, err := a.options.Store.Load(ctx, a.options.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent state: %w", err)
+	}
+	if state == nil {
+		state = &State{ID: a.options.ID}
+	}
+	return state, nil
+}
+
+// complete sends the system prompt and the history selected by memory
+func (a *Agent) complete(ctx context.Context, history []types.Message) (*types.ChatResponse, error) {
+	selected := a.options.Memory.Select(history)
+	messages := make([]types.Message, 0, len(selected)+1)
+	if system := a.systemPrompt(); system != "" {
+		messages = append(messages, types.Message{Role: RoleSystem, Content: system})
+	}
+	messages = append(messages, selected...)
+
+	resp, err := a.client.ChatComplete(ctx, types.ChatRequest{
+		Messages:    messages,
+		Model:       a.options.Model,
+		Profile:     a.options.Profile,
+		Temperature: a.options.Temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("client returned no response")
+	}
+	return resp, nil
+}
+
+// systemPrompt returns the configured system prompt followed by the tool
+// instructions
+func (a *Agent) systemPrompt() string {
+	if a.options.Tools == nil || a.options.Tools.Len() == 0 {
+		return a.options.SystemPrompt
+	}
+
+	var b strings.Builder
+	if a.options.SystemPrompt != "" {
+		b.WriteString(a.options.SystemPrompt + "\n\n")
+	}
+	b.WriteString("You can call these tools:\n")
+	for _, tool := range a.options.Tools.Tools() {
+		b.WriteString("- " + tool.Name)
+		if tool.Description != "" {
+			b.WriteString(": " + tool.Description)
+		}
+		if len(tool.Parameters) > 0 {
+			b.WriteString("\n  Arguments schema: " + compactJSON(tool.Parameters))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nTo call a tool, respond with only a JSON object such as {\"tool\": \"name\", \"arguments\": {...}} and wait for its result. Call one tool at a time. When you can answer, respond with the answer as plain text.")
+	return b.String()
+}
+
+// toolCall is a tool call in the agent's JSON protocol
+type toolCall struct {
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// parseToolCall reports whether a response is a tool call, which is a JSON
+// object with a "tool" field, optionally in a code fence
+func parseToolCall(content string) (toolCall, bool) {
+	text := strings.TrimSpace(content)
+	if rest, ok := strings.CutPrefix(text, "```"); ok {
+		if newline := strings.IndexByte(rest, '\n'); newline >= 0 {
+			rest = rest[newline+1:]
+		}
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(rest), "```"))
+	}
+	if !strings.HasPrefix(text, "{") || !strings.HasSuffix(text, "}") {
+		return toolCall{}, false
+	}
+
+	var call toolCall
+	if err := json.Unmarshal([]byte(text), &call); err != nil || call.Tool == "" {
+		return toolCall{}, false
+	}
+	return call, true
+}
+
+// toolResultMessage reports the outcome of a tool call to the model
+func toolResultMessage(step Step) string {
+	if step.Error != "" {
+		return fmt.Sprintf("Tool %q failed: %s", step.Tool, step.Error)
+	}
+	output := step.Output
+	if output == "" {
+		output = "(no output)"
+	}
+	return fmt.Sprintf("Result of tool %q:\n%s", step.Tool, output)
+}
+
+// compactJSON returns data without insignificant whitespace
+func compactJSON(data json.RawMessage) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, data); err != nil {
+		return string(data)
+	}
+	return b.String()
+}
+
+// addUsage returns the sum of two usage records
+func addUsage(a, b types.Usage) types.Usage {
+	return types.Usage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+	}
+}
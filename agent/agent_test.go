@@ -0,0 +1,234 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// scriptedClient replies with the given contents in order and records requests
+type scriptedClient struct {
+	replies  []string
+	requests []types.ChatRequest
+	sessions []string
+	err      error
+}
+
+func (c *scriptedClient) ChatComplete(ctx context.Context, req types.ChatRequest) (*types.ChatResponse, error) {
+	c.requests = append(c.requests, req)
+	c.sessions = append(c.sessions, types.SessionFromContext(ctx))
+	if c.err != nil {
+		return nil, c.err
+	}
+	if len(c.replies) == 0 {
+		return nil, errors.New("no more replies")
+	}
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	return &types.ChatResponse{
+		Message: types.Message{Role: RoleAssistant, Content: reply},
+		Usage:   types.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+	}, nil
+}
+
+// weatherTools returns a registry with a weather tool recording its calls
+func weatherTools(t *testing.T, calls *[]string) *Registry {
+	t.Helper()
+	tools, err := NewRegistry(Tool{
+		Name:        "weather",
+		Description: "Returns the weather for a city",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+		Call: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			var in struct{ City string }
+			if err := json.Unmarshal(arguments, &in); err != nil {
+				return "", err
+			}
+			*calls = append(*calls, in.City)
+			return "Rain, 8°C", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return tools
+}
+
+func TestRun_ToolCalls(t *testing.T) {
+	var calls []string
+	client := &scriptedClient{replies: []string{
+		`{"tool": "weather", "arguments": {}}`,
+		"```json\n{\"tool\": \"weather\", \"arguments\": {\"city\": \"Oslo\"}}\n```",
+		"Yes, take an umbrella.",
+	}}
+	a, err := New(client, Options{ID: "travel", SystemPrompt: "You are a travel assistant.", Tools: weatherTools(t, &calls), Profile: "assistant"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := a.Run(context.Background(), "Do I need an umbrella in Oslo?")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Output != "Yes, take an umbrella." {
+		t.Errorf("Expected the final answer, got %q", result.Output)
+	}
+	if len(result.Steps) != 2 || result.Steps[0].Error == "" || result.Steps[1].Output != "Rain, 8°C" {
+		t.Errorf("Expected an invalid call then a successful one, got %+v", result.Steps)
+	}
+	if len(calls) != 1 || calls[0] != "Oslo" {
+		t.Errorf("Expected the tool to run once with valid arguments, got %v", calls)
+	}
+	if result.Usage.TotalTokens != 36 {
+		t.Errorf("Expected the usage of 3 requests, got %+v", result.Usage)
+	}
+
+	last := client.requests[2]
+	system := last.Messages[0]
+	if system.Role != RoleSystem || !strings.HasPrefix(system.Content, "You are a travel assistant.") || !strings.Contains(system.Content, "- weather: Returns the weather for a city") {
+		t.Errorf("Expected the system prompt to describe the tools, got %q", system.Content)
+	}
+	toolResult := last.Messages[len(last.Messages)-1]
+	if !toolResult.Untrusted || !strings.Contains(toolResult.Content, "Rain, 8°C") {
+		t.Errorf("Expected the tool output as untrusted content, got %+v", toolResult)
+	}
+	if last.Profile != "assistant" || client.sessions[0] != "travel" {
+		t.Errorf("Expected the profile and the agent's session, got %q and %q", last.Profile, client.sessions[0])
+	}
+}
+
+func TestRun_PersistsState(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client := &scriptedClient{replies: []string{"Hi Ada.", "Your name is Ada."}}
+
+	first, _ := New(client, Options{ID: "user-1", Store: store})
+	if _, err := first.Run(context.Background(), "I'm Ada."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A new agent with the same ID resumes the history
+	second, _ := New(client, Options{ID: "user-1", Store: store})
+	if _, err := second.Run(context.Background(), "What's my name?"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sent := client.requests[1].Messages; len(sent) != 3 || sent[0].Content != "I'm Ada." {
+		t.Errorf("Expected the stored history to be sent, got %+v", sent)
+	}
+
+	state, err := second.State(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state.Runs != 2 || len(state.Messages) != 4 || state.Usage.TotalTokens != 24 {
+		t.Errorf("Expected 2 runs with 4 messages, got %+v", state)
+	}
+
+	if err := second.Reset(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state, _ := second.State(context.Background()); len(state.Messages) != 0 {
+		t.Errorf("Expected an empty state after Reset, got %+v", state)
+	}
+}
+
+func TestRun_FailureLeavesStateUnchanged(t *testing.T) {
+	var calls []string
+	client := &scriptedClient{replies: []string{`{"tool": "weather", "arguments": {"city": "Oslo"}}`, `{"tool": "weather", "arguments": {"city": "Bergen"}}`}}
+	a, _ := New(client, Options{Tools: weatherTools(t, &calls), MaxSteps: 2})
+
+	_, err := a.Run(context.Background(), "Weather?")
+	if !errors.Is(err, ErrMaxSteps) {
+		t.Errorf("Expected ErrMaxSteps, got %v", err)
+	}
+	client.err = errors.New("boom")
+	if _, err := a.Run(context.Background(), "Weather?"); err == nil {
+		t.Error("Expected the client error")
+	}
+	if state, _ := a.State(context.Background()); len(state.Messages) != 0 {
+		t.Errorf("Expected failed runs to leave the state unchanged, got %+v", state.Messages)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	history := []types.Message{
+		{Role: RoleUser, Content: strings.Repeat("word ", 100)},
+		{Role: RoleAssistant, Content: "short"},
+		{Role: RoleUser, Content: "last"},
+	}
+
+	if got := (WindowMemory{Messages: 2}).Select(history); len(got) != 1 || got[0].Content != "last" {
+		t.Errorf("Expected the last 2 messages without the leading assistant message, got %+v", got)
+	}
+	if got := (WindowMemory{Messages: 3}).Select(history); len(got) != 3 {
+		t.Errorf("Expected the whole history, got %d messages", len(got))
+	}
+	if got := (TokenMemory{MaxTokens: 30}).Select(history); len(got) != 1 {
+		t.Errorf("Expected the long message and the assistant message to be dropped, got %d messages", len(got))
+	}
+	if got := (TokenMemory{MaxTokens: 1}).Select(history); len(got) != 1 || got[0].Content != "last" {
+		t.Errorf("Expected the last message to be kept, got %+v", got)
+	}
+}
+
+func TestMemory_UserTurnStart(t *testing.T) {
+	memories := map[string]Memory{
+		"window": WindowMemory{Messages: 2},
+		"tokens": TokenMemory{MaxTokens: 8},
+	}
+	for name, memory := range memories {
+		t.Run(name, func(t *testing.T) {
+			client := &scriptedClient{replies: []string{"Hello there, how can I help?", "Sure.", "Done."}}
+			a, err := New(client, Options{ID: "chat", Memory: memory})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, input := range []string{"Hi", "Book a table", "Thanks"} {
+				if _, err := a.Run(context.Background(), input); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			for i, req := range client.requests {
+				if len(req.Messages) == 0 || req.Messages[0].Role != RoleUser {
+					t.Errorf("Expected request %d to start with a user message, got %+v", i, req.Messages)
+				}
+			}
+		})
+	}
+
+	// A window without user messages widens back to the previous one
+	history := []types.Message{
+		{Role: RoleUser, Content: "question"},
+		{Role: RoleAssistant, Content: "thinking"},
+		{Role: RoleAssistant, Content: "answer"},
+	}
+	if got := (WindowMemory{Messages: 1}).Select(history); len(got) != 3 {
+		t.Errorf("Expected the window widened to the user message, got %+v", got)
+	}
+}
+
+func TestRegistry_Validation(t *testing.T) {
+	call := func(ctx context.Context, arguments json.RawMessage) (string, error) { return "", nil }
+	tests := []struct {
+		name  string
+		tools []Tool
+	}{
+		{"unnamed", []Tool{{Call: call}}},
+		{"no call", []Tool{{Name: "a"}}},
+		{"duplicate", []Tool{{Name: "a", Call: call}, {Name: "a", Call: call}}},
+		{"invalid schema", []Tool{{Name: "a", Call: call, Parameters: json.RawMessage(`{`)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRegistry(tt.tools...); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
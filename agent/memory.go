@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// Memory decides which part of an agent's history is sent with each
+// request. The full history is always persisted; memory only bounds the
+// context the model sees.
+type Memory interface {
+	// Select returns the messages of history to send, oldest first. It
+	// must keep the last message, which is the current input or tool result.
+	Select(history []types.Message) []types.Message
+}
+
+// FullMemory sends the entire history. It is the default memory.
+type FullMemory struct{}
+
+// Select returns history unchanged
+func (FullMemory) Select(history []types.Message) []types.Message {
+	return history
+}
+
+// WindowMemory sends the most recent messages only.
+type WindowMemory struct {
+	// Messages is the number of messages sent (at least 1)
+	Messages int
+}
+
+// Select returns the last Messages messages of history, without leading
+// assistant messages
+func (m WindowMemory) Select(history []types.Message) []types.Message {
+	size := m.Messages
+	if size < 1 {
+		size = 1
+	}
+	if len(history) <= size {
+		return history
+	}
+	return history[userTurnStart(history, len(history)-size):]
+}
+
+// TokenMemory sends as many of the most recent messages as fit in a token
+// budget, estimated with the tokenizer package.
+type TokenMemory struct {
+	// MaxTokens is the budget for the history; the last message is sent
+	// even if it alone exceeds it
+	MaxTokens int
+}
+
+// Select returns the longest suffix of history within MaxTokens, without
+// leading assistant messages
+func (m TokenMemory) Select(history []types.Message) []types.Message {
+	start := len(history)
+	total := 0
+	for start > 0 {
+		total += tokenizer.EstimateMessages(history[start-1 : start])
+		if total > m.MaxTokens && start < len(history) {
+			break
+		}
+		start--
+	}
+	if start == 0 {
+		return history
+	}
+	return history[userTurnStart(history, start):]
+}
+
+// userTurnStart moves the start of a window of history to a user message,
+// since providers reject conversations that open with an assistant or tool
+// message. It drops the leading messages of the window, or widens it back to
+// the previous user message if the window has none.
+func userTurnStart(history []types.Message, start int) int {
+	for i := start; i < len(history); i++ {
+		if history[i].Role == RoleUser {
+			return i
+		}
+	}
+	for i := start - 1; i >= 0; i-- {
+		if history[i].Role == RoleUser {
+			return i
+		}
+	}
+	return start
+}
@@ -0,0 +1,415 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// DefaultMaxTasks is the default number of tasks in an orchestrator's plan
+const DefaultMaxTasks = 8
+
+// Trace event types reported by an Orchestrator
+const (
+	// TracePlan reports the planner's plan
+	TracePlan = "plan"
+
+	// TraceTaskStart reports a task dispatched to its executor
+	TraceTaskStart = "task_start"
+
+	// TraceTaskDone reports a completed task
+	TraceTaskDone = "task_done"
+
+	// TraceTaskFailed reports a failed task
+	TraceTaskFailed = "task_failed"
+
+	// TraceAggregate reports the planner's final answer
+	TraceAggregate = "aggregate"
+)
+
+// Executor is an agent the planner can dispatch tasks to.
+type Executor struct {
+	// Name identifies the executor in plans (required)
+	Name string
+
+	// Description tells the planner what the executor is good at
+	Description string
+
+	// Agent runs the executor's tasks; it keeps its history between tasks
+	// like any agent, so give it a WindowMemory or Reset it for
+	// independent tasks
+	Agent *Agent
+}
+
+// OrchestratorOptions configures an Orchestrator.
+type OrchestratorOptions struct {
+	// Executors are the agents tasks are dispatched to (required)
+	Executors []Executor
+
+	// Instructions add context to the planner's prompt (optional)
+	Instructions string
+
+	// Model selects the planner's model (optional)
+	Model string
+
+	// Profile selects a RequestProfile for the planner's requests (optional)
+	Profile string
+
+	// MaxTasks is the largest plan accepted from the planner
+	// Default: 8 if not specified
+	MaxTasks int
+
+	// ContinueOnError runs the remaining tasks after one fails, passing the
+	// failure to the tasks depending on it and to the final answer; by
+	// default the first failure ends the run
+	ContinueOnError bool
+
+	// OnTrace is called with each trace event as it happens (optional)
+	OnTrace func(TraceEvent)
+}
+
+// Task is a step of a plan.
+type Task struct {
+	// ID identifies the task in DependsOn
+	ID string `json:"id"`
+
+	// Executor is the name of the executor running the task
+	Executor string `json:"executor"`
+
+	// Input is sent to the executor
+	Input string `json:"input"`
+
+	// DependsOn lists earlier tasks whose output is passed to the executor
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// TaskResult is the outcome of a task.
+type TaskResult struct {
+	// Task is the task run
+	Task Task `json:"task"`
+
+	// Output is the executor's answer
+	Output string `json:"output,omitempty"`
+
+	// Steps are the tool calls the executor made
+	Steps []Step `json:"steps,omitempty"`
+
+	// Error describes why the task failed, if it did
+	Error string `json:"error,omitempty"`
+
+	// Duration is how long the task took
+	Duration time.Duration `json:"duration"`
+}
+
+// TraceEvent records a stage of an orchestrated run.
+type TraceEvent struct {
+	// Type is TracePlan, TraceTaskStart, TraceTaskDone, TraceTaskFailed or
+	// TraceAggregate
+	Type string `json:"type"`
+
+	// Time is when the event happened
+	Time time.Time `json:"time"`
+
+	// Task is the task the event belongs to (task events only)
+	Task *Task `json:"task,omitempty"`
+
+	// Plan is the planner's plan (TracePlan only)
+	Plan []Task `json:"plan,omitempty"`
+
+	// Output is the task's output or the final answer
+	Output string `json:"output,omitempty"`
+
+	// Error describes a failed task (TraceTaskFailed only)
+	Error string `json:"error,omitempty"`
+
+	// Duration is how long the stage took (not TraceTaskStart)
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// OrchestrationResult is the outcome of an orchestrated run.
+type OrchestrationResult struct {
+	// Output is the planner's answer, combining the task results
+	Output string `json:"output"`
+
+	// Plan is the plan that was run
+	Plan []Task `json:"plan"`
+
+	// Results are the results of the tasks in plan order
+	Results []TaskResult `json:"results"`
+
+	// Trace lists the run's events in order
+	Trace []TraceEvent `json:"trace"`
+
+	// Usage is the token usage of the planner and every executor
+	Usage types.Usage `json:"usage"`
+}
+
+// Orchestrator splits tasks between agents: a planner model decomposes a
+// task into steps, executor agents run them, and the planner combines their
+// results into an answer. The planner and executors may use different
+// clients, e.g. a strong model to plan and cheaper ones to execute.
+//
+// Example:
+//
+//	orchestrator, err := agent.NewOrchestrator(planner, agent.OrchestratorOptions{
+//		Executors: []agent.Executor{
+//			{Name: "researcher", Description: "Searches the web", Agent: researcher},
+//			{Name: "writer", Description: "Writes polished prose", Agent: writer},
+//		},
+//		MaxTasks: 5,
+//	})
+//	result, err := orchestrator.Run(ctx, "Write a short brief on solid-state batteries")
+type Orchestrator struct {
+	planner   ChatClient
+	options   OrchestratorOptions
+	executors map[string]Executor
+}
+
+// NewOrchestrator creates an orchestrator planning with planner
+func NewOrchestrator(planner ChatClient, options OrchestratorOptions) (*Orchestrator, error) {
+	if planner == nil {
+		return nil, fmt.Errorf("orchestrator requires a planner client")
+	}
+	if len(options.Executors) == 0 {
+		return nil, fmt.Errorf("orchestrator requires at least one executor")
+	}
+	if options.MaxTasks < 0 {
+		return nil, fmt.Errorf("max tasks must be positive, got: %d", options.MaxTasks)
+	}
+	if options.MaxTasks == 0 {
+		options.MaxTasks = DefaultMaxTasks
+	}
+
+	executors := make(map[string]Executor, len(options.Executors))
+	for _, executor := range options.Executors {
+		if strings.TrimSpace(executor.Name) == "" {
+			return nil, fmt.Errorf("executor name cannot be empty")
+		}
+		if executor.Agent == nil {
+			return nil, fmt.Errorf("executor %q has no agent", executor.Name)
+		}
+		if _, exists := executors[executor.Name]; exists {
+			return nil, fmt.Errorf("executor %q is defined twice", executor.Name)
+		}
+		executors[executor.Name] = executor
+	}
+	return &Orchestrator{planner: planner, options: options, executors: executors}, nil
+}
+
+// Run plans task, runs the plan's tasks in order and returns the planner's
+// combined answer.
+//
+// Each task is sent to its executor with the outputs of the tasks it
+// depends on. A plan that is not valid JSON, names an unknown executor or
+// has more than MaxTasks tasks is sent back to the planner once for
+// correction.
+func (o *Orchestrator) Run(ctx context.Context, task string) (*OrchestrationResult, error) {
+	if strings.TrimSpace(task) == "" {
+		return nil, fmt.Errorf("task cannot be empty")
+	}
+	result := &OrchestrationResult{}
+
+	start := time.Now()
+	plan, err := o.plan(ctx, task, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Plan = plan
+	o.trace(result, TraceEvent{Type: TracePlan, Plan: plan, Duration: time.Since(start)})
+
+	outputs := map[string]TaskResult{}
+	for i := range plan {
+		step := &plan[i]
+		taskResult, err := o.runTask(ctx, step, outputs, result)
+		result.Results = append(result.Results, taskResult)
+		outputs[step.ID] = taskResult
+
+		if err != nil && (!o.options.ContinueOnError || ctx.Err() != nil) {
+			return nil, fmt.Errorf("task %s failed: %w", step.ID, err)
+		}
+	}
+
+	start = time.Now()
+	resp, err := o.complete(ctx, aggregatePrompt(task, result.Results))
+	if err != nil {
+		return nil, err
+	}
+	result.Usage = addUsage(result.Usage, resp.Usage)
+	result.Output = resp.Message.Content
+	o.trace(result, TraceEvent{Type: TraceAggregate, Output: result.Output, Duration: time.Since(start)})
+	return result, nil
+}
+
+// runTask sends a task with its dependencies' outputs to its executor
+func (o *Orchestrator) runTask(ctx context.Context, task *Task, outputs map[string]TaskResult, result *OrchestrationResult) (TaskResult, error) {
+	o.trace(result, TraceEvent{Type: TraceTaskStart, Task: task})
+	start := time.Now()
+
+	input := task.Input
+	if len(task.DependsOn) > 0 {
+		var b strings.Builder
+		b.WriteString(task.Input + "\n\nResults of earlier tasks:")
+		for _, id := range task.DependsOn {
+			dependency := outputs[id]
+			if dependency.Error != "" {
+				fmt.Fprintf(&b, "\n\n[%s] failed: %s", id, dependency.Error)
+			} else {
+				fmt.Fprintf(&b, "\n\n[%s]\n%s", id, dependency.Output)
+			}
+		}
+		input = b.String()
+	}
+
+	taskResult := TaskResult{Task: *task}
+	run, err := o.executors[task.Executor].Agent.Run(ctx, input)
+	taskResult.Duration = time.Since(start)
+	if err != nil {
+		taskResult.Error = err.Error()
+		o.trace(result, TraceEvent{Type: TraceTaskFailed, Task: task, Error: taskResult.Error, Duration: taskResult.Duration})
+		return taskResult, err
+	}
+
+	taskResult.Output = run.Output
+	taskResult.Steps = run.Steps
+	result.Usage = addUsage(result.Usage, run.Usage)
+	o.trace(result, TraceEvent{Type: TraceTaskDone, Task: task, Output: run.Output, Duration: taskResult.Duration})
+	return taskResult, nil
+}
+
+// plan asks the planner for a plan, retrying once with the validation error
+func (o *Orchestrator) plan(ctx context.Context, task string, result *OrchestrationResult) ([]Task, error) {
+	prompt := o.planPrompt(task)
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		user := prompt
+		if lastErr != nil {
+			user += fmt.Sprintf("\n\nYour previous plan was invalid (%v). Respond with a corrected plan only.", lastErr)
+		}
+		resp, err := o.complete(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		result.Usage = addUsage(result.Usage, resp.Usage)
+
+		var plan []Task
+		if plan, lastErr = o.parsePlan(resp.Message.Content); lastErr == nil {
+			return plan, nil
+		}
+	}
+	return nil, fmt.Errorf("planner produced no valid plan: %w", lastErr)
+}
+
+// planPrompt asks the planner to decompose task between the executors
+func (o *Orchestrator) planPrompt(task string) string {
+	var b strings.Builder
+	if o.options.Instructions != "" {
+		b.WriteString(o.options.Instructions + "\n\n")
+	}
+	b.WriteString("Break the task below into steps, each handled by one of these executors:\n")
+	names := make([]string, 0, len(o.executors))
+	for name := range o.executors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("- " + name)
+		if description := o.executors[name].Description; description != "" {
+			b.WriteString(": " + description)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "\nUse at most %d steps. Steps run in order; a step receives the results of the earlier steps listed in depends_on.\n", o.options.MaxTasks)
+	b.WriteString(`Respond with JSON only, in the form {"tasks": [{"id": "t1", "executor": "name", "input": "instructions for the executor", "depends_on": []}]}.`)
+	b.WriteString("\n\n<task>\n" + task + "\n</task>")
+	return b.String()
+}
+
+// parsePlan reads and validates the planner's plan
+func (o *Orchestrator) parsePlan(content string) ([]Task, error) {
+	text := strings.TrimSpace(content)
+	if start, end := strings.IndexByte(text, '{'), strings.LastIndexByte(text, '}'); start >= 0 && end > start {
+		text = text[start : end+1]
+	}
+	var plan struct {
+		Tasks []Task `json:"tasks"`
+	}
+	if err := json.Unmarshal([]byte(text), &plan); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(plan.Tasks) == 0 {
+		return nil, errors.New("the plan has no tasks")
+	}
+	if len(plan.Tasks) > o.options.MaxTasks {
+		return nil, fmt.Errorf("the plan has %d tasks, more than the limit of %d", len(plan.Tasks), o.options.MaxTasks)
+	}
+
+	seen := map[string]bool{}
+	for i := range plan.Tasks {
+		task := &plan.Tasks[i]
+		if task.ID == "" {
+			task.ID = fmt.Sprintf("t%d", i+1)
+		}
+		if seen[task.ID] {
+			return nil, fmt.Errorf("task ID %q is used twice", task.ID)
+		}
+		if _, ok := o.executors[task.Executor]; !ok {
+			return nil, fmt.Errorf("task %s uses unknown executor %q", task.ID, task.Executor)
+		}
+		if strings.TrimSpace(task.Input) == "" {
+			return nil, fmt.Errorf("task %s has no input", task.ID)
+		}
+		for _, dependency := range task.DependsOn {
+			if !seen[dependency] {
+				return nil, fmt.Errorf("task %s depends on %q, which is not an earlier task", task.ID, dependency)
+			}
+		}
+		seen[task.ID] = true
+	}
+	return plan.Tasks, nil
+}
+
+// aggregatePrompt asks the planner to answer task from the task results
+func aggregatePrompt(task string, results []TaskResult) string {
+	var b strings.Builder
+	b.WriteString("<task>\n" + task + "\n</task>\n\nThe task was split into steps with these results:")
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(&b, "\n\n[%s] %s\nFailed: %s", r.Task.ID, r.Task.Input, r.Error)
+		} else {
+			fmt.Fprintf(&b, "\n\n[%s] %s\n%s", r.Task.ID, r.Task.Input, r.Output)
+		}
+	}
+	b.WriteString("\n\nCombine the results into a complete answer to the task. Respond with the answer only.")
+	return b.String()
+}
+
+// complete sends a planner request
+func (o *Orchestrator) complete(ctx context.Context, prompt string) (*types.ChatResponse, error) {
+	resp, err := o.planner.ChatComplete(ctx, types.ChatRequest{
+		Messages: []types.Message{{Role: RoleUser, Content: prompt}},
+		Model:    o.options.Model,
+		Profile:  o.options.Profile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("client returned no response")
+	}
+	return resp, nil
+}
+
+// trace records an event and passes it to OnTrace
+func (o *Orchestrator) trace(result *OrchestrationResult, event TraceEvent) {
+	event.Time = time.Now()
+	result.Trace = append(result.Trace, event)
+	if o.options.OnTrace != nil {
+		o.options.OnTrace(event)
+	}
+}
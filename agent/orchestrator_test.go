@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newExecutor returns an executor whose agent answers with replies
+func newExecutor(t *testing.T, name string, replies ...string) (Executor, *scriptedClient) {
+	t.Helper()
+	client := &scriptedClient{replies: replies}
+	a, err := New(client, Options{ID: name})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return Executor{Name: name, Description: "Handles " + name + " tasks", Agent: a}, client
+}
+
+func TestOrchestrator_Run(t *testing.T) {
+	researcher, researchClient := newExecutor(t, "researcher", "Solid-state batteries use solid electrolytes.")
+	writer, writeClient := newExecutor(t, "writer", "A polished brief.")
+	planner := &scriptedClient{replies: []string{
+		`{"tasks": [{"id": "t1", "executor": "editor", "input": "Edit"}]}`,
+		"```json\n" + `{"tasks": [
+			{"id": "research", "executor": "researcher", "input": "Research solid-state batteries"},
+			{"id": "write", "executor": "writer", "input": "Write a brief", "depends_on": ["research"]}
+		]}` + "\n```",
+		"Final brief.",
+	}}
+
+	var traced []string
+	orchestrator, err := NewOrchestrator(planner, OrchestratorOptions{
+		Executors: []Executor{researcher, writer},
+		OnTrace:   func(event TraceEvent) { traced = append(traced, event.Type) },
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := orchestrator.Run(context.Background(), "Write a brief on solid-state batteries")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Output != "Final brief." || len(result.Plan) != 2 || len(result.Results) != 2 {
+		t.Errorf("Expected the planner's answer over a 2-task plan, got %+v", result)
+	}
+	if retry := planner.requests[1].Messages[0].Content; !strings.Contains(retry, `unknown executor "editor"`) {
+		t.Errorf("Expected the invalid plan to be sent back, got %q", retry)
+	}
+	if input := writeClient.requests[0].Messages[0].Content; !strings.Contains(input, "Solid-state batteries use solid electrolytes.") {
+		t.Errorf("Expected the dependency's output in the writer's input, got %q", input)
+	}
+	if len(researchClient.requests) != 1 {
+		t.Errorf("Expected one research request, got %d", len(researchClient.requests))
+	}
+	if aggregate := planner.requests[2].Messages[0].Content; !strings.Contains(aggregate, "A polished brief.") {
+		t.Errorf("Expected the task results in the aggregation prompt, got %q", aggregate)
+	}
+	if result.Usage.TotalTokens != 5*12 {
+		t.Errorf("Expected the usage of every request, got %+v", result.Usage)
+	}
+
+	want := []string{TracePlan, TraceTaskStart, TraceTaskDone, TraceTaskStart, TraceTaskDone, TraceAggregate}
+	if strings.Join(traced, ",") != strings.Join(want, ",") || len(result.Trace) != len(want) {
+		t.Errorf("Expected trace %v, got %v", want, traced)
+	}
+}
+
+func TestOrchestrator_TaskFailure(t *testing.T) {
+	plan := `{"tasks": [{"id": "t1", "executor": "flaky", "input": "Try"}, {"id": "t2", "executor": "steady", "input": "Go", "depends_on": ["t1"]}]}`
+
+	flaky, flakyClient := newExecutor(t, "flaky")
+	flakyClient.err = errors.New("boom")
+	steady, steadyClient := newExecutor(t, "steady", "done")
+	planner := &scriptedClient{replies: []string{plan}}
+	orchestrator, _ := NewOrchestrator(planner, OrchestratorOptions{Executors: []Executor{flaky, steady}})
+	if _, err := orchestrator.Run(context.Background(), "Task"); err == nil || !strings.Contains(err.Error(), "task t1 failed") {
+		t.Errorf("Expected the task failure, got %v", err)
+	}
+	if len(steadyClient.requests) != 0 {
+		t.Error("Expected the run to stop at the failed task")
+	}
+
+	planner = &scriptedClient{replies: []string{plan, "Partial answer."}}
+	orchestrator, _ = NewOrchestrator(planner, OrchestratorOptions{Executors: []Executor{flaky, steady}, ContinueOnError: true})
+	result, err := orchestrator.Run(context.Background(), "Task")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Results[0].Error == "" || result.Output != "Partial answer." {
+		t.Errorf("Expected the failure to be reported and the run to finish, got %+v", result)
+	}
+	if input := steadyClient.requests[0].Messages[0].Content; !strings.Contains(input, "[t1] failed: boom") {
+		t.Errorf("Expected the failure to be passed to the dependent task, got %q", input)
+	}
+}
+
+func TestOrchestrator_PlanLimit(t *testing.T) {
+	executor, _ := newExecutor(t, "worker")
+	plan := `{"tasks": [{"executor": "worker", "input": "a"}, {"executor": "worker", "input": "b"}]}`
+	planner := &scriptedClient{replies: []string{plan, plan}}
+	orchestrator, _ := NewOrchestrator(planner, OrchestratorOptions{Executors: []Executor{executor}, MaxTasks: 1})
+
+	_, err := orchestrator.Run(context.Background(), "Task")
+	if err == nil || !strings.Contains(err.Error(), "more than the limit of 1") {
+		t.Errorf("Expected the plan limit error, got %v", err)
+	}
+	if len(planner.requests) != 2 {
+		t.Errorf("Expected one correction attempt, got %d requests", len(planner.requests))
+	}
+}
@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// State is the persisted state of an agent between runs.
+type State struct {
+	// ID identifies the agent in its Store
+	ID string `json:"id"`
+
+	// Messages is the full history, excluding the system prompt, including
+	// tool calls and results
+	Messages []types.Message `json:"messages"`
+
+	// Usage is the total token usage of every run
+	Usage types.Usage `json:"usage"`
+
+	// Runs is the number of completed runs
+	Runs int `json:"runs"`
+
+	// UpdatedAt is when the state was last saved
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store persists agent state, so an agent resumes its history after a
+// restart or on another instance. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Load returns the state saved under id, or nil if there is none
+	Load(ctx context.Context, id string) (*State, error)
+
+	// Save stores state under state.ID, replacing any earlier state
+	Save(ctx context.Context, state *State) error
+
+	// Delete removes the state saved under id, if any
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore is a Store that keeps state in memory, for agents that live
+// within one process. It is the default store.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string][]byte
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: map[string][]byte{}}
+}
+
+// Load returns a copy of the state saved under id
+func (s *MemoryStore) Load(ctx context.Context, id string) (*State, error) {
+	s.mu.Lock()
+	data, ok := s.states[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Save stores a copy of state
+func (s *MemoryStore) Save(ctx context.Context, state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.ID] = data
+	return nil
+}
+
+// Delete removes the state saved under id
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, id)
+	return nil
+}
+
+// FileStore is a Store that saves each agent's state as a JSON file named
+// after its ID in a directory.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// path returns the file of the state saved under id
+func (s *FileStore) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return "", fmt.Errorf("invalid agent ID %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// Load reads the state file of id
+func (s *FileStore) Load(ctx context.Context, id string) (*State, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid agent state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Save writes the state file of state.ID, replacing it atomically so an
+// interrupted save leaves the previous state intact
+func (s *FileStore) Save(ctx context.Context, state *State) error {
+	path, err := s.path(state.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, state.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save agent state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save agent state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save agent state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save agent state: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the state file of id
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete agent state: %w", err)
+	}
+	return nil
+}
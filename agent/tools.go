@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ajeet-kumar1087/ai-providers/jsonschema"
+)
+
+// Tool is a function the agent's model may call.
+type Tool struct {
+	// Name identifies the tool in calls (required)
+	Name string
+
+	// Description tells the model what the tool does and when to use it
+	Description string
+
+	// Parameters is the JSON schema of the arguments (optional). Calls with
+	// arguments that do not match it are answered with the validation error
+	// instead of reaching Call.
+	Parameters json.RawMessage
+
+	// Call runs the tool with the model's arguments and returns its output
+	// for the model. An error is reported to the model, which may retry or
+	// answer without the tool; it does not stop the run.
+	Call func(ctx context.Context, arguments json.RawMessage) (string, error)
+}
+
+// Registry holds the tools an agent may call, in registration order.
+//
+// Registry is safe for concurrent use, so tools can be added to an agent
+// between runs.
+type Registry struct {
+	mu     sync.RWMutex
+	tools  map[string]Tool
+	order  []string
+	schema map[string]*jsonschema.Schema
+}
+
+// NewRegistry creates a registry holding tools
+func NewRegistry(tools ...Tool) (*Registry, error) {
+	r := &Registry{tools: map[string]Tool{}, schema: map[string]*jsonschema.Schema{}}
+	for _, tool := range tools {
+		if err := r.Register(tool); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a tool, failing if it is invalid or its name is taken
+func (r *Registry) Register(tool Tool) error {
+	if strings.TrimSpace(tool.Name) == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+	if tool.Call == nil {
+		return fmt.Errorf("tool %q has no Call function", tool.Name)
+	}
+	var schema *jsonschema.Schema
+	if len(tool.Parameters) > 0 {
+		parsed, err := jsonschema.Parse(tool.Parameters)
+		if err != nil {
+			return fmt.Errorf("tool %q: %w", tool.Name, err)
+		}
+		schema = parsed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[tool.Name]; exists {
+		return fmt.Errorf("tool %q is already registered", tool.Name)
+	}
+	r.tools[tool.Name] = tool
+	r.order = append(r.order, tool.Name)
+	if schema != nil {
+		r.schema[tool.Name] = schema
+	}
+	return nil
+}
+
+// Get returns the tool with the given name
+func (r *Registry) Get(name string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// Tools returns the registered tools in registration order
+func (r *Registry) Tools() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tools := make([]Tool, len(r.order))
+	for i, name := range r.order {
+		tools[i] = r.tools[name]
+	}
+	return tools
+}
+
+// Len returns the number of registered tools
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.order)
+}
+
+// call validates the arguments of a tool call and runs the tool, returning
+// the output or error reported back to the model
+func (r *Registry) call(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	r.mu.RLock()
+	tool, ok := r.tools[name]
+	schema := r.schema[name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+	if schema != nil {
+		if err := schema.Validate(arguments); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	return tool.Call(ctx, arguments)
+}
@@ -0,0 +1,291 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/rag"
+)
+
+// DefaultAnswerTopK is the default number of chunks retrieved for a question
+const DefaultAnswerTopK = 4
+
+// CitationMode controls how Answer attributes its answer to sources.
+type CitationMode string
+
+const (
+	// CitationsInline instructs the model to cite the sources of each
+	// statement with markers such as [1], which are resolved into
+	// AnswerResult.References
+	CitationsInline CitationMode = "inline"
+
+	// CitationsStrict is CitationsInline, but fails with a provider error
+	// if the answer cites a source that does not exist
+	CitationsStrict CitationMode = "strict"
+
+	// CitationsNone asks for an answer without markers; every source is
+	// then returned as cited
+	CitationsNone CitationMode = "none"
+)
+
+// AnswerOptions configures Answer.
+type AnswerOptions struct {
+	// Embedder embeds documents and the question (required unless Index is set)
+	Embedder rag.Embedder
+
+	// Index searches a prebuilt index instead of indexing the documents
+	// passed to Answer (optional)
+	Index *rag.Index
+
+	// ChunkTokens is the maximum size of indexed chunks (default: rag.DefaultChunkTokens)
+	ChunkTokens int
+
+	// TopK is the number of chunks retrieved as sources (default: 4)
+	TopK int
+
+	// MinScore excludes chunks less similar to the question than this (optional)
+	MinScore float64
+
+	// Instructions adds guidance such as "Answer in one sentence" (optional)
+	Instructions string
+
+	// Model overrides the provider default model (optional)
+	Model string
+
+	// MaxTokens limits the length of the answer (optional)
+	MaxTokens *int
+
+	// Citations controls whether the model cites sources inline and how
+	// strictly its markers are checked (default: CitationsInline)
+	Citations CitationMode
+}
+
+// Citation is a citation marker in an answer, resolved to the source it
+// cites.
+type Citation struct {
+	// Number is the cited source number; Sources[Number-1] is the source
+	Number int `json:"number"`
+
+	// ChunkID is the ID of the cited chunk
+	ChunkID string `json:"chunk_id"`
+
+	// DocumentID is the ID of the document the chunk belongs to
+	DocumentID string `json:"document_id"`
+
+	// Source is the "source" metadata of the document, such as the path set
+	// by rag.LoadFile, if any
+	Source string `json:"source,omitempty"`
+
+	// Start and End are the byte offsets of the chunk in its document
+	Start int `json:"start"`
+	End   int `json:"end"`
+
+	// AnswerStart and AnswerEnd are the byte offsets of the marker in the
+	// answer; a marker such as [1, 2] yields one citation per number
+	AnswerStart int `json:"answer_start"`
+	AnswerEnd   int `json:"answer_end"`
+}
+
+// AnswerResult is the result of Answer.
+type AnswerResult struct {
+	// Answer is the generated answer, with citations such as [1] referring to Sources
+	Answer string `json:"answer"`
+
+	// Sources contains the retrieved chunks in prompt order; citation [n] refers to Sources[n-1]
+	Sources []rag.Result `json:"sources"`
+
+	// Citations contains the sources cited in the answer, in order of first
+	// citation, or all sources with CitationsNone
+	Citations []rag.Result `json:"citations"`
+
+	// References contains the citation markers of the answer in order,
+	// resolved to the chunks and document offsets they cite
+	References []Citation `json:"references,omitempty"`
+
+	// InvalidCitations contains the source numbers cited in the answer
+	// that match no source, such as 7 when there are four
+	InvalidCitations []int `json:"invalid_citations,omitempty"`
+
+	// Usage is the token usage of the generation request
+	Usage Usage `json:"usage"`
+}
+
+// Answer answers a question from a set of documents.
+//
+// The documents are chunked and embedded, the chunks most similar to the
+// question are retrieved, and the model is asked to answer using only those
+// numbered sources and to cite them. The citation markers of the answer are
+// resolved to the chunks they cite, with their document offsets, so
+// applications can link back to the source text; markers citing sources
+// that do not exist are reported, or fail the call with CitationsStrict.
+//
+// Example:
+//
+//	result, err := client.Answer(ctx, "What is the refund window?", []rag.Document{
+//		{ID: "terms", Text: terms},
+//		{ID: "faq", Text: faq},
+//	}, AnswerOptions{Embedder: embedder})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(result.Answer)
+//	for _, c := range result.References {
+//		fmt.Printf("[%d] %s, bytes %d-%d\n", c.Number, c.DocumentID, c.Start, c.End)
+//	}
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - question: The question to answer
+//   - docs: The documents to answer from (may be empty when opts.Index is set)
+//   - opts: Embedder or index, retrieval and model options
+//
+// Returns:
+//   - *AnswerResult: The answer with retrieved and cited sources
+//   - error: A validation error for invalid input, or a retrieval or request error
+func (c *client) Answer(ctx context.Context, question string, docs []rag.Document, opts AnswerOptions) (*AnswerResult, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	message := ""
+	switch {
+	case strings.TrimSpace(question) == "":
+		message = "question cannot be empty"
+	case opts.Index == nil && opts.Embedder == nil:
+		message = "an embedder or index is required"
+	case opts.Index == nil && len(docs) == 0:
+		message = "at least one document is required"
+	case opts.Citations != "" && opts.Citations != CitationsInline && opts.Citations != CitationsStrict && opts.Citations != CitationsNone:
+		message = fmt.Sprintf("unknown citation mode: %q", opts.Citations)
+	}
+	if message != "" {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  message,
+			Provider: string(c.provider),
+		}
+	}
+
+	index := opts.Index
+	if index == nil {
+		index = rag.NewIndex(opts.Embedder, rag.IndexOptions{ChunkTokens: opts.ChunkTokens})
+		if err := index.Add(ctx, docs...); err != nil {
+			return nil, err
+		}
+	}
+
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = DefaultAnswerTopK
+	}
+	results, err := index.Search(ctx, question, topK)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AnswerResult{}
+	for _, r := range results {
+		if r.Score >= opts.MinScore {
+			result.Sources = append(result.Sources, r)
+		}
+	}
+
+	system := "You answer questions using only the provided sources. Cite the sources supporting each statement " +
+		"with their number in square brackets, e.g. [1]. If the sources do not contain the answer, say that you don't know."
+	if opts.Citations == CitationsNone {
+		system = "You answer questions using only the provided sources. If the sources do not contain the answer, say that you don't know."
+	}
+	gen, err := c.generate(ctx, helperRequest{
+		system:    system,
+		user:      answerPrompt(question, result.Sources, opts.Instructions),
+		model:     opts.Model,
+		maxTokens: opts.MaxTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Answer = strings.TrimSpace(gen.text)
+	result.Usage = gen.usage
+	if opts.Citations == CitationsNone {
+		result.Citations = result.Sources
+		return result, nil
+	}
+
+	result.Citations, result.References, result.InvalidCitations = resolveCitations(result.Answer, result.Sources)
+	if opts.Citations == CitationsStrict && len(result.InvalidCitations) > 0 {
+		return nil, &Error{
+			Type:     ErrorTypeProvider,
+			Message:  fmt.Sprintf("answer cites nonexistent sources %v of %d", result.InvalidCitations, len(result.Sources)),
+			Provider: string(c.provider),
+		}
+	}
+	return result, nil
+}
+
+// answerPrompt builds the grounded prompt listing numbered sources
+func answerPrompt(question string, sources []rag.Result, instructions string) string {
+	var b strings.Builder
+	b.WriteString("Sources:\n\n")
+	if len(sources) == 0 {
+		b.WriteString("(no relevant sources found)\n\n")
+	}
+	for i, source := range sources {
+		fmt.Fprintf(&b, "[%d] (%s)\n%s\n\n", i+1, source.Chunk.Ref(), source.Chunk.Text)
+	}
+	b.WriteString("Question: " + question)
+	if instructions != "" {
+		b.WriteString("\n\n" + instructions)
+	}
+	return b.String()
+}
+
+// citationPattern matches citations such as [1] or [2, 3]
+var citationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// resolveCitations resolves the citation markers of answer, returning the
+// cited sources in order of first citation, a citation per cited number and
+// the numbers that match no source
+func resolveCitations(answer string, sources []rag.Result) ([]rag.Result, []Citation, []int) {
+	var cited []rag.Result
+	var citations []Citation
+	var invalid []int
+	seen := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatchIndex(answer, -1) {
+		for _, field := range strings.Split(answer[match[2]:match[3]], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				continue
+			}
+			if n < 1 || n > len(sources) {
+				if !seen[n] {
+					seen[n] = true
+					invalid = append(invalid, n)
+				}
+				continue
+			}
+
+			chunk := sources[n-1].Chunk
+			citations = append(citations, Citation{
+				Number:      n,
+				ChunkID:     chunk.ID,
+				DocumentID:  chunk.DocumentID,
+				Source:      chunk.Metadata["source"],
+				Start:       chunk.Offset,
+				End:         chunk.Offset + len(chunk.Text),
+				AnswerStart: match[0],
+				AnswerEnd:   match[1],
+			})
+			if !seen[n] {
+				seen[n] = true
+				cited = append(cited, sources[n-1])
+			}
+		}
+	}
+	return cited, citations, invalid
+}
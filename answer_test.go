@@ -0,0 +1,161 @@
+package aiprovider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/rag"
+)
+
+// keywordEmbedder embeds text as counts of fixed keywords
+type keywordEmbedder struct {
+	keywords []string
+}
+
+func (e keywordEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, len(e.keywords))
+		for j, keyword := range e.keywords {
+			vectors[i][j] = float64(strings.Count(strings.ToLower(text), keyword))
+		}
+	}
+	return vectors, nil
+}
+
+func TestAnswer(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(string) (string, error) {
+		return "Refunds are accepted within 30 days [1]. Shipping is free [2, 1] [7].", nil
+	}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	docs := []rag.Document{
+		{ID: "terms", Text: "Refunds are accepted within 30 days of purchase."},
+		{ID: "shipping", Text: "Shipping is free for refund returns."},
+		{ID: "careers", Text: "We are hiring engineers."},
+	}
+	result, err := c.Answer(context.Background(), "What is the refund window?", docs, AnswerOptions{
+		Embedder: keywordEmbedder{keywords: []string{"refund", "shipping", "hiring"}},
+		MinScore: 0.1,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Sources) != 2 {
+		t.Fatalf("Expected 2 sources above the minimum score, got %d", len(result.Sources))
+	}
+	if result.Sources[0].Chunk.Ref() != "terms#0" {
+		t.Errorf("Expected terms#0 as the best source, got %s", result.Sources[0].Chunk.Ref())
+	}
+	if len(result.Citations) != 2 || result.Citations[1].Chunk.Ref() != "shipping#0" {
+		t.Errorf("Expected citations terms#0 and shipping#0, got %+v", result.Citations)
+	}
+
+	// Each number of each marker resolves to its chunk and offsets
+	references := result.References
+	if len(references) != 3 {
+		t.Fatalf("Expected 3 references, got %+v", references)
+	}
+	marker := strings.Index(result.Answer, "[2, 1]")
+	if references[1].Number != 2 || references[1].DocumentID != "shipping" || references[1].ChunkID != result.Sources[1].Chunk.ID ||
+		references[1].AnswerStart != marker || references[1].AnswerEnd != marker+len("[2, 1]") {
+		t.Errorf("Expected the [2, 1] marker to cite shipping, got %+v", references[1])
+	}
+	if references[2].Number != 1 || references[2].Start != 0 || references[2].End != len(docs[0].Text) {
+		t.Errorf("Expected the document offsets of terms#0, got %+v", references[2])
+	}
+	if len(result.InvalidCitations) != 1 || result.InvalidCitations[0] != 7 {
+		t.Errorf("Expected [7] to be reported as invalid, got %v", result.InvalidCitations)
+	}
+
+	user := adapter.requests[0].Messages[1].Content
+	for _, want := range []string{"[1] (terms#0)\nRefunds", "[2] (shipping#0)", "Question: What is the refund window?"} {
+		if !strings.Contains(user, want) {
+			t.Errorf("Expected prompt to contain %q, got %q", want, user)
+		}
+	}
+	if strings.Contains(user, "careers") {
+		t.Errorf("Expected low scoring source to be excluded from the prompt")
+	}
+}
+
+func TestAnswer_PrebuiltIndex(t *testing.T) {
+	embedder := keywordEmbedder{keywords: []string{"go"}}
+	index := rag.NewIndex(embedder, rag.IndexOptions{})
+	if err := index.Add(context.Background(), rag.Document{ID: "go", Text: "Go is a programming language."}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	adapter := &scriptedAdapter{reply: func(string) (string, error) { return "A language [1].", nil }}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	result, err := c.Answer(context.Background(), "What is Go?", nil, AnswerOptions{Index: index})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Citations) != 1 || result.Citations[0].Chunk.DocumentID != "go" {
+		t.Errorf("Expected citation of the go document, got %+v", result.Citations)
+	}
+}
+
+func TestAnswer_CitationModes(t *testing.T) {
+	embedder := keywordEmbedder{keywords: []string{"refund"}}
+	docs := []rag.Document{{ID: "terms", Text: "Refunds within 30 days.", Metadata: map[string]string{"source": "terms.md"}}}
+
+	adapter := &scriptedAdapter{reply: func(string) (string, error) { return "30 days [1] [3].", nil }}
+	c := newMockClient(ProviderAnthropic, adapter)
+	_, err := c.Answer(context.Background(), "Refund window?", docs, AnswerOptions{Embedder: embedder, Citations: CitationsStrict})
+	if e, ok := err.(*Error); !ok || e.Type != ErrorTypeProvider || !strings.Contains(e.Message, "[3]") {
+		t.Errorf("Expected an error for the invalid citation, got %v", err)
+	}
+
+	result, err := c.Answer(context.Background(), "Refund window?", docs, AnswerOptions{Embedder: embedder})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.References) != 1 || result.References[0].Source != "terms.md" {
+		t.Errorf("Expected the reference to carry the source metadata, got %+v", result.References)
+	}
+
+	adapter = &scriptedAdapter{reply: func(string) (string, error) { return "30 days.", nil }}
+	c = newMockClient(ProviderAnthropic, adapter)
+	result, err = c.Answer(context.Background(), "Refund window?", docs, AnswerOptions{Embedder: embedder, Citations: CitationsNone})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Citations) != 1 || len(result.References) != 0 {
+		t.Errorf("Expected every source as cited and no references, got %+v", result)
+	}
+	if system := adapter.requests[0].Messages[0].Content; strings.Contains(system, "square brackets") {
+		t.Errorf("Expected no citation instructions, got %q", system)
+	}
+}
+
+func TestAnswer_Validation(t *testing.T) {
+	c := newMockClient(ProviderAnthropic, &scriptedAdapter{})
+	embedder := keywordEmbedder{}
+	docs := []rag.Document{{Text: "text"}}
+
+	tests := []struct {
+		name     string
+		question string
+		docs     []rag.Document
+		opts     AnswerOptions
+	}{
+		{"empty question", " ", docs, AnswerOptions{Embedder: embedder}},
+		{"no embedder", "Why?", docs, AnswerOptions{}},
+		{"no documents", "Why?", nil, AnswerOptions{Embedder: embedder}},
+		{"unknown citation mode", "Why?", docs, AnswerOptions{Embedder: embedder, Citations: "footnotes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Answer(context.Background(), tt.question, tt.docs, tt.opts)
+			if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+				t.Errorf("Expected validation error, got %v", err)
+			}
+		})
+	}
+}
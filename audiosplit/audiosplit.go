@@ -0,0 +1,274 @@
+// Package audiosplit splits long recordings into chunks for transcription.
+//
+// Transcription APIs limit the size of uploads, such as the 25 MB of
+// OpenAI's Whisper, which an hour of uncompressed audio far exceeds. Split
+// cuts a PCM WAV recording into standalone WAV files within a size and
+// duration limit, ending each chunk at the quietest moment shortly before
+// its limit so words are not cut in half. Chunks carry their offset in the
+// recording, so timestamps of their transcripts can be shifted back.
+//
+// Chunks are read from the recording as they are uploaded, so the recording
+// is never held in memory.
+//
+// Example:
+//
+//	file, err := os.Open("meeting.wav")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	info, _ := file.Stat()
+//	chunks, err := audiosplit.Split(file, info.Size(), audiosplit.Options{MaxDuration: 10 * time.Minute})
+//	for _, chunk := range chunks {
+//		fmt.Println(chunk.Offset, chunk.Duration, chunk.Audio.Size())
+//	}
+package audiosplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultMaxBytes is the default chunk size limit, leaving room below the
+// 25 MB Whisper upload limit for the rest of the request
+const DefaultMaxBytes = 24 << 20
+
+// DefaultSearchWindow is how long before its limit a chunk may end by
+// default, at the quietest moment in that window
+const DefaultSearchWindow = 30 * time.Second
+
+// ErrUnsupportedFormat is returned by Split for recordings that are not
+// PCM WAV files
+var ErrUnsupportedFormat = errors.New("unsupported audio format: only PCM WAV recordings can be split")
+
+// Options configures Split.
+type Options struct {
+	// MaxBytes is the size limit of each chunk, header included (default:
+	// DefaultMaxBytes)
+	MaxBytes int64
+
+	// MaxDuration is the duration limit of each chunk; 0 means no limit
+	// beyond MaxBytes
+	MaxDuration time.Duration
+
+	// SearchWindow is how long before its limit a chunk may end, at the
+	// quietest moment in that window; it is at most half of a chunk
+	// (default: DefaultSearchWindow)
+	SearchWindow time.Duration
+}
+
+// Chunk is a piece of a split recording.
+type Chunk struct {
+	// Index is the position of the chunk in the recording, from zero
+	Index int
+
+	// Offset is where the chunk starts in the recording
+	Offset time.Duration
+
+	// Duration is the length of the chunk
+	Duration time.Duration
+
+	// Audio reads the chunk as a standalone WAV file. It reads from the
+	// recording, which must stay open while it is used.
+	Audio *io.SectionReader
+}
+
+// Split splits a PCM WAV recording of size bytes into chunks meeting
+// opts. It returns ErrUnsupportedFormat for other recordings; a recording
+// within the limits is returned as a single chunk.
+func Split(r io.ReaderAt, size int64, opts Options) ([]Chunk, error) {
+	wav, err := parseWAV(r, size)
+	if err != nil {
+		return nil, err
+	}
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	maxFrames := (maxBytes - wavHeaderSize) / int64(wav.blockAlign)
+	if opts.MaxDuration > 0 {
+		if frames := wav.frames(opts.MaxDuration); frames < maxFrames {
+			maxFrames = frames
+		}
+	}
+	if maxFrames < 1 {
+		return nil, fmt.Errorf("chunk limits are too small for a single audio frame")
+	}
+	searchWindow := opts.SearchWindow
+	if searchWindow <= 0 {
+		searchWindow = DefaultSearchWindow
+	}
+
+	total := wav.dataSize / int64(wav.blockAlign)
+	var chunks []Chunk
+	for start := int64(0); start < total; {
+		end := start + maxFrames
+		if end >= total {
+			end = total
+		} else {
+			window := wav.frames(searchWindow)
+			if window > maxFrames/2 {
+				window = maxFrames / 2
+			}
+			if end, err = wav.quietest(end-window, end); err != nil {
+				return nil, err
+			}
+		}
+		chunks = append(chunks, wav.chunk(len(chunks), start, end))
+		start = end
+	}
+	return chunks, nil
+}
+
+// wavHeaderSize is the size of the header of chunk files
+const wavHeaderSize = 44
+
+// Audio format codes of WAV files
+const (
+	formatPCM        = 1
+	formatFloat      = 3
+	formatExtensible = 0xFFFE
+)
+
+// wavFile is a parsed PCM WAV recording
+type wavFile struct {
+	r             io.ReaderAt
+	format        uint16 // formatPCM or formatFloat
+	channels      uint16
+	sampleRate    uint32
+	blockAlign    uint16 // Bytes per frame, all channels
+	bitsPerSample uint16
+	dataOffset    int64
+	dataSize      int64
+}
+
+// parseWAV reads the format and locates the samples of a WAV recording
+func parseWAV(r io.ReaderAt, size int64) (*wavFile, error) {
+	header := make([]byte, 12)
+	if _, err := r.ReadAt(header, 0); err != nil || string(header[:4]) != "RIFF" || string(header[8:]) != "WAVE" {
+		return nil, ErrUnsupportedFormat
+	}
+
+	wav := &wavFile{r: r}
+	chunkHeader := make([]byte, 8)
+	for offset := int64(12); offset+8 <= size; {
+		if _, err := r.ReadAt(chunkHeader, offset); err != nil {
+			return nil, fmt.Errorf("failed to read WAV header: %w", err)
+		}
+		id := string(chunkHeader[:4])
+		length := int64(binary.LittleEndian.Uint32(chunkHeader[4:]))
+		offset += 8
+
+		switch id {
+		case "fmt ":
+			if length < 16 {
+				return nil, ErrUnsupportedFormat
+			}
+			fmtChunk := make([]byte, 26)
+			if _, err := r.ReadAt(fmtChunk[:readLength(length, len(fmtChunk))], offset); err != nil {
+				return nil, fmt.Errorf("failed to read WAV format: %w", err)
+			}
+			wav.format = binary.LittleEndian.Uint16(fmtChunk)
+			if wav.format == formatExtensible && length >= 26 {
+				// The sub-format GUID starts with the format code
+				wav.format = binary.LittleEndian.Uint16(fmtChunk[24:])
+			}
+			wav.channels = binary.LittleEndian.Uint16(fmtChunk[2:])
+			wav.sampleRate = binary.LittleEndian.Uint32(fmtChunk[4:])
+			wav.blockAlign = binary.LittleEndian.Uint16(fmtChunk[12:])
+			wav.bitsPerSample = binary.LittleEndian.Uint16(fmtChunk[14:])
+		case "data":
+			if !wav.supported() {
+				return nil, ErrUnsupportedFormat
+			}
+			// Recorders that could not seek back leave the length unset
+			if length > size-offset {
+				length = size - offset
+			}
+			wav.dataOffset, wav.dataSize = offset, length
+			return wav, nil
+		}
+		offset += length + length%2 // Chunks are padded to an even length
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// readLength returns how much of a chunk of length bytes fits a buffer
+func readLength(length int64, size int) int {
+	if length < int64(size) {
+		return int(length)
+	}
+	return size
+}
+
+// supported reports whether the samples can be read
+func (w *wavFile) supported() bool {
+	if w.channels == 0 || w.sampleRate == 0 || int(w.blockAlign) != int(w.channels)*int(w.bitsPerSample/8) {
+		return false
+	}
+	switch w.format {
+	case formatPCM:
+		return w.bitsPerSample == 8 || w.bitsPerSample == 16 || w.bitsPerSample == 24 || w.bitsPerSample == 32
+	case formatFloat:
+		return w.bitsPerSample == 32 || w.bitsPerSample == 64
+	}
+	return false
+}
+
+// frames returns the number of frames in a duration, rounded down
+func (w *wavFile) frames(d time.Duration) int64 {
+	return int64(d) * int64(w.sampleRate) / int64(time.Second)
+}
+
+// duration returns the duration of a number of frames
+func (w *wavFile) duration(frames int64) time.Duration {
+	return time.Duration(frames) * time.Second / time.Duration(w.sampleRate)
+}
+
+// chunk returns the frames from start to end as a standalone WAV file
+func (w *wavFile) chunk(index int, start, end int64) Chunk {
+	size := (end - start) * int64(w.blockAlign)
+	header := make([]byte, wavHeaderSize)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(wavHeaderSize-8+size))
+	copy(header[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], w.format)
+	binary.LittleEndian.PutUint16(header[22:], w.channels)
+	binary.LittleEndian.PutUint32(header[24:], w.sampleRate)
+	binary.LittleEndian.PutUint32(header[28:], w.sampleRate*uint32(w.blockAlign))
+	binary.LittleEndian.PutUint16(header[32:], w.blockAlign)
+	binary.LittleEndian.PutUint16(header[34:], w.bitsPerSample)
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(size))
+
+	data := io.NewSectionReader(w.r, w.dataOffset+start*int64(w.blockAlign), size)
+	return Chunk{
+		Index:    index,
+		Offset:   w.duration(start),
+		Duration: w.duration(end - start),
+		Audio:    io.NewSectionReader(&chunkFile{header: header, data: data}, 0, wavHeaderSize+size),
+	}
+}
+
+// chunkFile is a header followed by data read from the recording
+type chunkFile struct {
+	header []byte
+	data   *io.SectionReader
+}
+
+// ReadAt implements io.ReaderAt
+func (c *chunkFile) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	if off < int64(len(c.header)) {
+		n = copy(p, c.header[off:])
+		if n == len(p) {
+			return n, nil
+		}
+		off += int64(n)
+	}
+	m, err := c.data.ReadAt(p[n:], off-int64(len(c.header)))
+	return n + m, err
+}
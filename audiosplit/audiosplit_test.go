@@ -0,0 +1,128 @@
+package audiosplit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRate is the sample rate of test recordings
+const testRate = 8000
+
+// recording returns a 16-bit mono WAV file of a tone lasting d, silent
+// over the given ranges
+func recording(d time.Duration, silences ...[2]time.Duration) []byte {
+	frames := int(d.Seconds() * testRate)
+	data := make([]byte, 2*frames)
+	for i := 0; i < frames; i++ {
+		at := time.Duration(i) * time.Second / testRate
+		level := 0.5
+		for _, silence := range silences {
+			if at >= silence[0] && at < silence[1] {
+				level = 0
+			}
+		}
+		sample := int16(level * math.MaxInt16 * math.Sin(2*math.Pi*440*float64(i)/testRate))
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(sample))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(data)))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, []uint32{16})
+	binary.Write(&buf, binary.LittleEndian, []uint16{formatPCM, 1})
+	binary.Write(&buf, binary.LittleEndian, []uint32{testRate, 2 * testRate})
+	binary.Write(&buf, binary.LittleEndian, []uint16{2, 16})
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestSplit(t *testing.T) {
+	data := recording(10*time.Second,
+		[2]time.Duration{3500 * time.Millisecond, 3600 * time.Millisecond},
+		[2]time.Duration{7200 * time.Millisecond, 7300 * time.Millisecond},
+	)
+	chunks, err := Split(bytes.NewReader(data), int64(len(data)), Options{MaxDuration: 4 * time.Second, SearchWindow: time.Second})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d", len(chunks))
+	}
+
+	// Chunks end in the silences, not at the 4s limit
+	expectedEnds := []time.Duration{3550 * time.Millisecond, 7250 * time.Millisecond, 10 * time.Second}
+	var samples []byte
+	var offset time.Duration
+	for i, chunk := range chunks {
+		end := chunk.Offset + chunk.Duration
+		if chunk.Index != i || chunk.Offset != offset || end < expectedEnds[i]-50*time.Millisecond || end > expectedEnds[i]+50*time.Millisecond {
+			t.Errorf("Chunk %d: expected to end near %v, got %+v", i, expectedEnds[i], chunk)
+		}
+		offset = end
+
+		file, err := io.ReadAll(chunk.Audio)
+		if err != nil {
+			t.Fatalf("Chunk %d: unexpected error: %v", i, err)
+		}
+		wav, err := parseWAV(bytes.NewReader(file), int64(len(file)))
+		if err != nil {
+			t.Fatalf("Chunk %d: expected a valid WAV file, got %v", i, err)
+		}
+		if got := wav.duration(wav.dataSize / int64(wav.blockAlign)); got != chunk.Duration {
+			t.Errorf("Chunk %d: expected a file lasting %v, got %v", i, chunk.Duration, got)
+		}
+		samples = append(samples, file[wav.dataOffset:]...)
+	}
+	if !bytes.Equal(samples, data[44:]) {
+		t.Error("Expected the chunks to add up to the recording")
+	}
+}
+
+func TestSplit_MaxBytes(t *testing.T) {
+	data := recording(10 * time.Second)
+	maxBytes := int64(44 + 3*2*testRate)
+	chunks, err := Split(bytes.NewReader(data), int64(len(data)), Options{MaxBytes: maxBytes})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chunks) != 4 {
+		t.Errorf("Expected 4 chunks, got %d", len(chunks))
+	}
+	for _, chunk := range chunks {
+		if chunk.Audio.Size() > maxBytes {
+			t.Errorf("Expected at most %d bytes, got %d", maxBytes, chunk.Audio.Size())
+		}
+	}
+
+	chunks, err = Split(bytes.NewReader(data), int64(len(data)), Options{})
+	if err != nil || len(chunks) != 1 || chunks[0].Audio.Size() != int64(len(data)) {
+		t.Errorf("Expected a recording within the limits as one chunk, got %d chunks and %v", len(chunks), err)
+	}
+}
+
+func TestSplit_UnsupportedFormat(t *testing.T) {
+	tests := map[string][]byte{
+		"mp3": []byte("ID3\x04\x00\x00\x00\x00\x00\x00audio"),
+		"wav ADPCM": func() []byte {
+			data := recording(time.Second)
+			binary.LittleEndian.PutUint16(data[20:], 2)
+			return data
+		}(),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Split(strings.NewReader(string(data)), int64(len(data)), Options{}); !errors.Is(err, ErrUnsupportedFormat) {
+				t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package audiosplit
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// analysisFrame is the length in seconds of the windows loudness is
+// compared over
+const analysisFrame = 0.02
+
+// quietest returns the frame between from and to at the middle of the
+// quietest analysis window, preferring later windows among equally quiet
+// ones so chunks stay long
+func (w *wavFile) quietest(from, to int64) (int64, error) {
+	if to-from < 2 {
+		return to, nil
+	}
+	buf := make([]byte, (to-from)*int64(w.blockAlign))
+	if _, err := w.r.ReadAt(buf, w.dataOffset+from*int64(w.blockAlign)); err != nil {
+		return 0, fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	windowFrames := int64(float64(w.sampleRate) * analysisFrame)
+	if windowFrames < 1 {
+		windowFrames = 1
+	}
+	if windowFrames > to-from {
+		windowFrames = to - from
+	}
+	windowBytes := int(windowFrames) * int(w.blockAlign)
+	sampleBytes := int(w.bitsPerSample / 8)
+
+	best, bestLevel := to, math.Inf(1)
+	for start := 0; start+windowBytes <= len(buf); start += windowBytes {
+		var level float64
+		for i := start; i < start+windowBytes; i += sampleBytes {
+			level += math.Abs(w.sample(buf[i:]))
+		}
+		if level <= bestLevel {
+			bestLevel = level
+			best = from + int64(start/int(w.blockAlign)) + windowFrames/2
+		}
+	}
+	return best, nil
+}
+
+// sample decodes one sample, scaled to the range -1 to 1
+func (w *wavFile) sample(b []byte) float64 {
+	switch {
+	case w.format == formatFloat && w.bitsPerSample == 32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case w.format == formatFloat:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	case w.bitsPerSample == 8: // Unsigned
+		return (float64(b[0]) - 128) / 128
+	case w.bitsPerSample == 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case w.bitsPerSample == 24:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+		return float64(v) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
@@ -0,0 +1,335 @@
+// Package batch runs prompts from CSV or JSONL files through a worker pool.
+//
+// ProcessFile reads one prompt per CSV row or JSONL object, sends them with
+// an aiprovider.WorkerPool and appends each successful response with its
+// token usage and cost to the output file. Jobs marked Deferred are instead
+// submitted as one native provider batch when the client supports it, which
+// is cheaper but may take up to a day. Completed items are recorded in a
+// CheckpointStore, a file next to the output by default, so running the same
+// job again after an interruption only sends the items that have not
+// completed yet. Failed items are left out of the output and retried by the
+// next run.
+//
+// Example:
+//
+//	report, err := batch.ProcessFile(ctx, client, batch.Options{
+//		Input:  "prompts.csv",
+//		Output: "responses.csv",
+//		Request: aiprovider.ChatRequest{
+//			Messages:  []aiprovider.Message{{Role: "system", Content: "Answer in one sentence."}},
+//			MaxTokens: &maxTokens,
+//		},
+//		Pool: aiprovider.PoolOptions{Concurrency: 8},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("%d done, %d skipped, %d failed, $%.2f\n",
+//		report.Succeeded, report.Skipped, report.Failed, report.Cost)
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+const (
+	// DefaultPromptField is the CSV column or JSONL field holding the prompt
+	DefaultPromptField = "prompt"
+
+	// DefaultIDField is the CSV column or JSONL field holding the item ID
+	DefaultIDField = "id"
+
+	// CheckpointSuffix is appended to the output path to name the default checkpoint file
+	CheckpointSuffix = ".checkpoint"
+
+	// DefaultPollInterval is how often a native batch is checked for completion
+	DefaultPollInterval = 30 * time.Second
+
+	// NativeBatchPriceFactor is the share of the regular price providers
+	// charge for natively batched requests, used to estimate their cost
+	NativeBatchPriceFactor = 0.5
+)
+
+// Format is an input and output file format.
+type Format string
+
+const (
+	// FormatCSV is comma-separated values with a header row
+	FormatCSV Format = "csv"
+
+	// FormatJSONL is one JSON object per line
+	FormatJSONL Format = "jsonl"
+)
+
+// Output columns added to every input record
+const (
+	ColumnResponse         = "response"
+	ColumnPromptTokens     = "prompt_tokens"
+	ColumnCompletionTokens = "completion_tokens"
+	ColumnTotalTokens      = "total_tokens"
+	ColumnCost             = "cost_usd"
+)
+
+// outputColumns lists the columns added to every output record, in order
+var outputColumns = []string{ColumnResponse, ColumnPromptTokens, ColumnCompletionTokens, ColumnTotalTokens, ColumnCost}
+
+// Options configures ProcessFile.
+type Options struct {
+	// Input is the path of the CSV or JSONL file to read prompts from
+	Input string
+
+	// Output is the path of the file responses are appended to, in the input format
+	Output string
+
+	// Format is the file format (default: detected from the input extension)
+	Format Format
+
+	// PromptField is the column or field holding the prompt (default: DefaultPromptField)
+	PromptField string
+
+	// IDField is the column or field identifying each item (default: DefaultIDField).
+	// Items without it are identified by their 1-based record number, which
+	// stays stable only as long as the input file is not reordered.
+	IDField string
+
+	// Checkpoint is the path of the checkpoint file (default: Output + CheckpointSuffix)
+	Checkpoint string
+
+	// CheckpointStore persists completed items instead of the checkpoint file
+	// (optional). ProcessFile does not close it.
+	CheckpointStore CheckpointStore
+
+	// Request is the template for every request; the prompt is appended as a user message
+	Request aiprovider.ChatRequest
+
+	// Pool configures the worker pool. Its OnResult callback is called after
+	// the result was written. Only OnResult and OnProgress apply to native
+	// batches.
+	Pool aiprovider.PoolOptions
+
+	// Deferred marks the job as latency-insensitive. If the client supports
+	// aiprovider.FeatureBatch, the pending items are submitted as one native
+	// provider batch and ProcessFile waits for it to end, which can take up
+	// to a day; otherwise items are sent through the worker pool as usual.
+	Deferred bool
+
+	// PollInterval is how often a native batch is checked for completion
+	// (default: DefaultPollInterval)
+	PollInterval time.Duration
+
+	// BatchID resumes waiting for a native batch submitted by an interrupted
+	// run, reported in its Report.BatchID, instead of submitting a new one
+	// (optional, Deferred only)
+	BatchID string
+}
+
+// Report summarizes a ProcessFile run.
+type Report struct {
+	aiprovider.PoolReport
+
+	// Skipped is the number of items already completed by a previous run
+	Skipped int `json:"skipped"`
+
+	// BatchID identifies the native batch the items were submitted in, if any
+	BatchID string `json:"batch_id,omitempty"`
+}
+
+// ProcessFile sends every prompt of opts.Input that is not yet in the
+// checkpoint and appends the responses to opts.Output.
+//
+// The returned report covers the items processed by this run. A read, write
+// or checkpoint error stops submitting new items; items already submitted
+// still finish before ProcessFile returns the error.
+//
+// Parameters:
+//   - ctx: Context for cancellation; cancelled items are retried by the next run
+//   - client: The client used to send requests
+//   - opts: Input, output and request options
+//
+// Returns:
+//   - *Report: Counts, usage and cost of this run
+//   - error: The first error that stopped the run, if any
+func ProcessFile(ctx context.Context, client aiprovider.Client, opts Options) (*Report, error) {
+	if opts.Input == "" || opts.Output == "" {
+		return nil, fmt.Errorf("input and output paths are required")
+	}
+	if opts.PromptField == "" {
+		opts.PromptField = DefaultPromptField
+	}
+	if opts.IDField == "" {
+		opts.IDField = DefaultIDField
+	}
+	if opts.Checkpoint == "" {
+		opts.Checkpoint = opts.Output + CheckpointSuffix
+	}
+	if opts.Format == "" {
+		format, err := DetectFormat(opts.Input)
+		if err != nil {
+			return nil, err
+		}
+		opts.Format = format
+	}
+
+	in, err := os.Open(opts.Input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input: %w", err)
+	}
+	defer in.Close()
+
+	reader, err := newRecordReader(opts.Format, in, opts.PromptField, opts.IDField)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := opts.CheckpointStore
+	if checkpoint == nil {
+		file, err := OpenFileCheckpoint(opts.Checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		checkpoint = file
+	}
+	// Record completed items even while the run is being cancelled
+	markCtx := context.WithoutCancel(ctx)
+
+	out, err := os.OpenFile(opts.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output: %w", err)
+	}
+	defer out.Close()
+
+	writer, err := newRecordWriter(opts.Format, out, reader.header())
+	if err != nil {
+		return nil, err
+	}
+
+	records := &pendingReader{reader: reader, checkpoint: checkpoint, seen: map[string]bool{}}
+	if opts.Deferred && client.SupportsFeature(aiprovider.FeatureBatch) {
+		return processNative(ctx, client, opts, records, func(rec *record, result aiprovider.JobResult) error {
+			if err := writer.write(rec, result); err != nil {
+				return err
+			}
+			return checkpoint.MarkCompleted(markCtx, rec.id)
+		})
+	}
+
+	var (
+		mu       sync.Mutex
+		pending  = map[string]*record{}
+		writeErr error
+	)
+	poolOpts := opts.Pool
+	onResult := poolOpts.OnResult
+	poolOpts.OnResult = func(result aiprovider.JobResult) {
+		mu.Lock()
+		rec := pending[result.Job.ID]
+		delete(pending, result.Job.ID)
+		if result.Err == nil && writeErr == nil {
+			writeErr = writer.write(rec, result)
+			if writeErr == nil {
+				writeErr = checkpoint.MarkCompleted(markCtx, result.Job.ID)
+			}
+		}
+		mu.Unlock()
+
+		if onResult != nil {
+			onResult(result)
+		}
+	}
+
+	pool := aiprovider.NewWorkerPool(client, poolOpts)
+	report := &Report{}
+	var runErr error
+
+	for runErr == nil {
+		rec, err := records.next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			runErr = err
+			break
+		}
+
+		mu.Lock()
+		pending[rec.id] = rec
+		runErr = writeErr
+		mu.Unlock()
+		if runErr != nil {
+			break
+		}
+
+		if err := pool.Submit(ctx, aiprovider.Job{ID: rec.id, Request: itemRequest(opts.Request, rec.prompt)}); err != nil {
+			runErr = err
+		}
+	}
+	report.Skipped = records.skipped
+
+	report.PoolReport = pool.Drain()
+	if runErr == nil {
+		runErr = writeErr
+	}
+	return report, runErr
+}
+
+// pendingReader reads the records not completed by a previous run
+type pendingReader struct {
+	reader     recordReader
+	checkpoint CheckpointStore
+	seen       map[string]bool
+	skipped    int
+}
+
+// next returns the next pending record, or io.EOF at the end of the input
+func (p *pendingReader) next(ctx context.Context) (*record, error) {
+	for {
+		rec, err := p.reader.next()
+		if err != nil {
+			return nil, err
+		}
+		if p.seen[rec.id] {
+			return nil, fmt.Errorf("duplicate item id %q", rec.id)
+		}
+		p.seen[rec.id] = true
+
+		completed, err := p.checkpoint.Completed(ctx, rec.id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+		}
+		if !completed {
+			return rec, nil
+		}
+		p.skipped++
+	}
+}
+
+// DetectFormat returns the format of a file from its extension
+func DetectFormat(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("cannot detect format of %q, expected .csv or .jsonl", path)
+	}
+}
+
+// itemRequest appends the prompt as a user message to a copy of the template request
+func itemRequest(template aiprovider.ChatRequest, prompt string) aiprovider.ChatRequest {
+	req := template
+	req.Messages = make([]aiprovider.Message, 0, len(template.Messages)+1)
+	req.Messages = append(req.Messages, template.Messages...)
+	req.Messages = append(req.Messages, aiprovider.Message{Role: "user", Content: prompt})
+	return req
+}
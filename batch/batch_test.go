@@ -0,0 +1,332 @@
+package batch
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+// echoClient answers with the upper-cased prompt and fails prompts containing "fail"
+type echoClient struct {
+	aiprovider.Client
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (e *echoClient) ChatComplete(ctx context.Context, req aiprovider.ChatRequest) (*aiprovider.ChatResponse, error) {
+	prompt := req.Messages[len(req.Messages)-1].Content
+	e.mu.Lock()
+	e.prompts = append(e.prompts, prompt)
+	e.mu.Unlock()
+
+	if strings.Contains(prompt, "fail") {
+		return nil, aiprovider.NewError(aiprovider.ErrorTypeValidation, "anthropic", "rejected")
+	}
+	return &aiprovider.ChatResponse{
+		Message: aiprovider.Message{Role: "assistant", Content: strings.ToUpper(prompt)},
+		Usage:   aiprovider.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+	}, nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	return rows
+}
+
+func TestProcessFile_CSVResume(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "prompts.csv")
+	output := filepath.Join(dir, "responses.csv")
+	writeFile(t, input, "id,prompt\na,hello\nb,please fail\nc,\"world, again\"\n")
+
+	client := &echoClient{}
+	opts := Options{
+		Input:   input,
+		Output:  output,
+		Request: aiprovider.ChatRequest{Messages: []aiprovider.Message{{Role: "system", Content: "Be brief"}}},
+	}
+	report, err := ProcessFile(context.Background(), client, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Succeeded != 2 || report.Failed != 1 || report.Skipped != 0 || report.Usage.TotalTokens != 10 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	rows := readCSV(t, output)
+	header := strings.Join(rows[0], ",")
+	if header != "id,prompt,response,prompt_tokens,completion_tokens,total_tokens,cost_usd" {
+		t.Errorf("Unexpected header %q", header)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected 2 result rows, got %v", rows[1:])
+	}
+	for _, row := range rows[1:] {
+		if row[2] != strings.ToUpper(row[1]) || row[5] != "5" {
+			t.Errorf("Unexpected row %v", row)
+		}
+	}
+
+	// The second run only retries the failed item
+	client.prompts = nil
+	report, err = ProcessFile(context.Background(), client, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Skipped != 2 || report.Submitted != 1 || len(client.prompts) != 1 || client.prompts[0] != "please fail" {
+		t.Errorf("Expected only the failed item to be resent, got %+v and %v", report, client.prompts)
+	}
+	if rows := readCSV(t, output); len(rows) != 3 {
+		t.Errorf("Expected no duplicate header or rows after resume, got %d rows", len(rows))
+	}
+}
+
+func TestProcessFile_JSONL(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "prompts.jsonl")
+	output := filepath.Join(dir, "responses.jsonl")
+	writeFile(t, input, `{"prompt": "one", "topic": "x"}`+"\n\n"+`{"id": 7, "prompt": "two"}`+"\n")
+
+	report, err := ProcessFile(context.Background(), &echoClient{}, Options{Input: input, Output: output})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Succeeded != 2 {
+		t.Errorf("Expected 2 successes, got %+v", report)
+	}
+
+	data, _ := os.ReadFile(output)
+	results := map[string]map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &object); err != nil {
+			t.Fatalf("Invalid output line %q: %v", line, err)
+		}
+		results[object["prompt"].(string)] = object
+	}
+	if results["one"]["response"] != "ONE" || results["one"]["topic"] != "x" || results["one"]["total_tokens"] != float64(5) {
+		t.Errorf("Unexpected result %v", results["one"])
+	}
+
+	checkpoint, _ := os.ReadFile(output + CheckpointSuffix)
+	if !strings.Contains(string(checkpoint), `"1"`) || !strings.Contains(string(checkpoint), `"7"`) {
+		t.Errorf("Expected record number and numeric IDs in the checkpoint, got %q", checkpoint)
+	}
+}
+
+func TestProcessFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out.csv")
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "unknown extension", file: "prompts.txt", content: "hello"},
+		{name: "missing prompt column", file: "missing.csv", content: "id,text\n1,hello\n"},
+		{name: "duplicate id", file: "dupes.csv", content: "id,prompt\n1,a\n1,b\n"},
+		{name: "invalid JSON", file: "bad.jsonl", content: "{prompt\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := filepath.Join(dir, tt.file)
+			writeFile(t, input, tt.content)
+			if _, err := ProcessFile(context.Background(), &echoClient{}, Options{Input: input, Output: output}); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestOpenFileCheckpoint_PartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.checkpoint")
+	writeFile(t, path, "\"a\"\n\"b\\nc\"\n\"trunc")
+
+	checkpoint, err := OpenFileCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	for id, expected := range map[string]bool{"a": true, "b\nc": true, "trunc": false, "\"trunc": false} {
+		if completed, _ := checkpoint.Completed(ctx, id); completed != expected {
+			t.Errorf("Expected Completed(%q) to be %v", id, expected)
+		}
+	}
+	if err := checkpoint.MarkCompleted(ctx, "d"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkpoint.Close()
+
+	reopened, err := OpenFileCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Expected the repaired checkpoint to load, got %v", err)
+	}
+	defer reopened.Close()
+	if completed, _ := reopened.Completed(ctx, "d"); len(reopened.done) != 3 || !completed {
+		t.Errorf("Unexpected completed IDs after reopening %v", reopened.done)
+	}
+}
+
+func TestProcessFile_CheckpointStore(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "prompts.csv")
+	writeFile(t, input, "id,prompt\n1,one\n2,two\n3,three\n")
+
+	store := NewMemoryCheckpoint()
+	store.MarkCompleted(context.Background(), "2")
+
+	client := &echoClient{}
+	report, err := ProcessFile(context.Background(), client, Options{
+		Input:           input,
+		Output:          filepath.Join(dir, "out.csv"),
+		CheckpointStore: store,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Skipped != 1 || report.Succeeded != 2 || len(client.prompts) != 2 {
+		t.Errorf("Expected the completed item to be skipped, got %+v", report)
+	}
+	if store.Len() != 3 {
+		t.Errorf("Expected all items in the store, got %d", store.Len())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out.csv"+CheckpointSuffix)); !os.IsNotExist(err) {
+		t.Errorf("Expected no checkpoint file with a custom store")
+	}
+}
+
+// nativeClient answers like echoClient through a native batch that ends
+// after one poll
+type nativeClient struct {
+	echoClient
+	submitted []aiprovider.BatchItem
+	polls     int
+}
+
+func (n *nativeClient) SupportsFeature(feature string) bool {
+	return feature == aiprovider.FeatureBatch
+}
+
+func (n *nativeClient) SubmitBatch(ctx context.Context, items []aiprovider.BatchItem) (*aiprovider.Batch, error) {
+	n.submitted = items
+	return &aiprovider.Batch{ID: "batch_1", Status: aiprovider.BatchInProgress}, nil
+}
+
+func (n *nativeClient) GetBatch(ctx context.Context, id string) (*aiprovider.Batch, error) {
+	n.polls++
+	status := aiprovider.BatchInProgress
+	if n.polls > 1 {
+		status = aiprovider.BatchEnded
+	}
+	return &aiprovider.Batch{ID: id, Status: status}, nil
+}
+
+func (n *nativeClient) ListBatchResults(ctx context.Context, id string) ([]aiprovider.BatchResult, error) {
+	var results []aiprovider.BatchResult
+	for _, item := range n.submitted {
+		response, err := n.ChatComplete(ctx, item.Request)
+		results = append(results, aiprovider.BatchResult{ID: item.ID, Response: response, Err: err})
+	}
+	return results, nil
+}
+
+func TestProcessFile_Deferred(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "prompts.csv")
+	output := filepath.Join(dir, "responses.csv")
+	writeFile(t, input, "id,prompt\na,hello\nb/2,please fail\nc,world\n")
+
+	client := &nativeClient{}
+	report, err := ProcessFile(context.Background(), client, Options{
+		Input:        input,
+		Output:       output,
+		Deferred:     true,
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.BatchID != "batch_1" || report.Submitted != 3 || report.Succeeded != 2 || report.Failed != 1 || report.Usage.TotalTokens != 10 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if client.polls != 2 {
+		t.Errorf("Expected 2 polls, got %d", client.polls)
+	}
+	if id := client.submitted[1].ID; id == "b/2" || !batchItemIDPattern.MatchString(id) {
+		t.Errorf("Expected an invalid item ID to be hashed, got %q", id)
+	}
+	if rows := readCSV(t, output); len(rows) != 3 || rows[1][0] != "a" || rows[2][0] != "c" {
+		t.Errorf("Unexpected output: %v", rows)
+	}
+
+	// Resuming an interrupted wait only fetches the results
+	writeFile(t, input, "id,prompt\na,hello\nb/2,retry\nc,world\n")
+	client.polls = 0
+	report, err = ProcessFile(context.Background(), client, Options{
+		Input:        input,
+		Output:       output,
+		Deferred:     true,
+		PollInterval: time.Millisecond,
+		BatchID:      "batch_1",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Skipped != 2 || report.Submitted != 1 || report.Failed != 1 {
+		t.Errorf("Expected the failed item to be looked up in the batch again, got %+v", report)
+	}
+}
+
+func TestProcessFile_DeferredFallback(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "prompts.csv")
+	writeFile(t, input, "id,prompt\na,hello\n")
+
+	client := &echoClient{}
+	report, err := ProcessFile(context.Background(), &unbatchedClient{client}, Options{
+		Input:    input,
+		Output:   filepath.Join(dir, "out.csv"),
+		Deferred: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.BatchID != "" || report.Succeeded != 1 || len(client.prompts) != 1 {
+		t.Errorf("Expected the worker pool to be used, got %+v", report)
+	}
+}
+
+// unbatchedClient is an echoClient without native batch support
+type unbatchedClient struct {
+	*echoClient
+}
+
+func (u *unbatchedClient) SupportsFeature(feature string) bool {
+	return false
+}
@@ -0,0 +1,124 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CheckpointStore persists which items of a batch job have completed.
+//
+// ProcessFile skips items the store reports as completed and marks items
+// completed after their response was written to the output. A store holds
+// the state of one job; use a separate store, file or key prefix per job.
+// Implementations must be safe for concurrent use.
+type CheckpointStore interface {
+	// Completed reports whether the item with id completed in an earlier run
+	Completed(ctx context.Context, id string) (bool, error)
+
+	// MarkCompleted records the item with id as completed
+	MarkCompleted(ctx context.Context, id string) error
+}
+
+// FileCheckpoint is a CheckpointStore that appends completed item IDs to a
+// file, one quoted ID per line. It is the default store of ProcessFile.
+type FileCheckpoint struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[string]bool
+}
+
+// OpenFileCheckpoint loads the completed IDs from path, creating the file if
+// needed. A last line cut short by an interruption is dropped.
+func OpenFileCheckpoint(path string) (*FileCheckpoint, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	done := map[string]bool{}
+	lines := strings.Split(string(data), "\n")
+	// The last line is empty, or was cut short by an interruption and is dropped
+	if partial := lines[len(lines)-1]; partial != "" {
+		if err := file.Truncate(int64(len(data) - len(partial))); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to repair checkpoint: %w", err)
+		}
+	}
+	for _, line := range lines[:len(lines)-1] {
+		id, err := strconv.Unquote(line)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("invalid checkpoint entry %q", line)
+		}
+		done[id] = true
+	}
+	return &FileCheckpoint{file: file, done: done}, nil
+}
+
+// Completed reports whether id was completed
+func (c *FileCheckpoint) Completed(ctx context.Context, id string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[id], nil
+}
+
+// MarkCompleted appends id to the checkpoint file
+func (c *FileCheckpoint) MarkCompleted(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.file.WriteString(strconv.Quote(id) + "\n"); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	c.done[id] = true
+	return nil
+}
+
+// Close closes the checkpoint file
+func (c *FileCheckpoint) Close() error {
+	return c.file.Close()
+}
+
+// MemoryCheckpoint is a CheckpointStore that keeps completed IDs in memory,
+// for jobs that are resumed within the same process.
+type MemoryCheckpoint struct {
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+// NewMemoryCheckpoint creates an empty in-memory checkpoint store
+func NewMemoryCheckpoint() *MemoryCheckpoint {
+	return &MemoryCheckpoint{done: map[string]bool{}}
+}
+
+// Completed reports whether id was completed
+func (c *MemoryCheckpoint) Completed(ctx context.Context, id string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[id], nil
+}
+
+// MarkCompleted records id as completed
+func (c *MemoryCheckpoint) MarkCompleted(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done[id] = true
+	return nil
+}
+
+// Len returns the number of completed items
+func (c *MemoryCheckpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.done)
+}
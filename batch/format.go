@@ -0,0 +1,235 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+// maxJSONLLine is the longest JSONL line accepted
+const maxJSONLLine = 16 * 1024 * 1024
+
+// record is one input item
+type record struct {
+	id     string
+	prompt string
+	fields []string                   // CSV values in header order
+	object map[string]json.RawMessage // JSONL object
+}
+
+// recordReader reads input records
+type recordReader interface {
+	// header returns the CSV header, or nil for JSONL
+	header() []string
+
+	// next returns the next record, or io.EOF at the end of the input
+	next() (*record, error)
+}
+
+// recordWriter appends results to the output
+type recordWriter interface {
+	write(rec *record, result aiprovider.JobResult) error
+}
+
+// newRecordReader creates a reader for format
+func newRecordReader(format Format, r io.Reader, promptField, idField string) (recordReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r, promptField, idField)
+	case FormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxJSONLLine)
+		return &jsonlReader{scanner: scanner, promptField: promptField, idField: idField}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// newRecordWriter creates a writer for format, writing the CSV header if the output is empty
+func newRecordWriter(format Format, out *os.File, header []string) (recordWriter, error) {
+	switch format {
+	case FormatCSV:
+		w := &csvWriter{w: csv.NewWriter(out)}
+		info, err := out.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat output: %w", err)
+		}
+		if info.Size() == 0 {
+			if err := w.writeRow(append(append([]string{}, header...), outputColumns...)); err != nil {
+				return nil, err
+			}
+		}
+		return w, nil
+	case FormatJSONL:
+		return &jsonlWriter{w: out}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// csvReader reads records from CSV with a header row
+type csvReader struct {
+	r         *csv.Reader
+	columns   []string
+	promptCol int
+	idCol     int
+	row       int
+}
+
+func newCSVReader(r io.Reader, promptField, idField string) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	columns, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("input has no header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	c := &csvReader{r: reader, columns: columns, promptCol: -1, idCol: -1}
+	for i, column := range columns {
+		switch column {
+		case promptField:
+			c.promptCol = i
+		case idField:
+			c.idCol = i
+		}
+	}
+	if c.promptCol < 0 {
+		return nil, fmt.Errorf("input has no %q column", promptField)
+	}
+	return c, nil
+}
+
+func (c *csvReader) header() []string {
+	return c.columns
+}
+
+func (c *csvReader) next() (*record, error) {
+	fields, err := c.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read row %d: %w", c.row+1, err)
+	}
+	c.row++
+
+	rec := &record{id: strconv.Itoa(c.row), prompt: fields[c.promptCol], fields: fields}
+	if c.idCol >= 0 && fields[c.idCol] != "" {
+		rec.id = fields[c.idCol]
+	}
+	return rec, nil
+}
+
+// jsonlReader reads records from one JSON object per line, skipping blank lines
+type jsonlReader struct {
+	scanner     *bufio.Scanner
+	promptField string
+	idField     string
+	line        int
+	records     int
+}
+
+func (j *jsonlReader) header() []string {
+	return nil
+}
+
+func (j *jsonlReader) next() (*record, error) {
+	for j.scanner.Scan() {
+		j.line++
+		line := j.scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		j.records++
+
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(line, &object); err != nil {
+			return nil, fmt.Errorf("invalid JSON on line %d: %w", j.line, err)
+		}
+
+		rec := &record{id: strconv.Itoa(j.records), object: object}
+		if err := json.Unmarshal(object[j.promptField], &rec.prompt); err != nil {
+			return nil, fmt.Errorf("line %d has no string %q field", j.line, j.promptField)
+		}
+		if raw, ok := object[j.idField]; ok {
+			var id string
+			if err := json.Unmarshal(raw, &id); err != nil {
+				// Numeric and other non-string IDs are used verbatim
+				id = string(raw)
+			}
+			if id != "" && id != "null" {
+				rec.id = id
+			}
+		}
+		return rec, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read line %d: %w", j.line+1, err)
+	}
+	return nil, io.EOF
+}
+
+// csvWriter appends input rows with the output columns
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) write(rec *record, result aiprovider.JobResult) error {
+	usage := result.Response.Usage
+	row := append(append([]string{}, rec.fields...),
+		result.Response.Message.Content,
+		strconv.Itoa(usage.PromptTokens),
+		strconv.Itoa(usage.CompletionTokens),
+		strconv.Itoa(usage.TotalTokens),
+		strconv.FormatFloat(result.Cost, 'f', -1, 64),
+	)
+	return c.writeRow(row)
+}
+
+// writeRow writes and flushes a row so completed items survive an interruption
+func (c *csvWriter) writeRow(row []string) error {
+	if err := c.w.Write(row); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// jsonlWriter appends input objects with the output fields
+type jsonlWriter struct {
+	w io.Writer
+}
+
+func (j *jsonlWriter) write(rec *record, result aiprovider.JobResult) error {
+	object := make(map[string]interface{}, len(rec.object)+len(outputColumns))
+	for key, value := range rec.object {
+		object[key] = value
+	}
+	usage := result.Response.Usage
+	object[ColumnResponse] = result.Response.Message.Content
+	object[ColumnPromptTokens] = usage.PromptTokens
+	object[ColumnCompletionTokens] = usage.CompletionTokens
+	object[ColumnTotalTokens] = usage.TotalTokens
+	object[ColumnCost] = result.Cost
+
+	line, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	if _, err := j.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package batch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"regexp"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+// errMissingResult fails items the provider returned no result for
+var errMissingResult = errors.New("item missing from native batch results")
+
+// batchItemIDPattern matches item IDs every provider accepts as batch item IDs
+var batchItemIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// nativeItem is a record submitted in a native batch
+type nativeItem struct {
+	rec     *record
+	request aiprovider.ChatRequest
+}
+
+// processNative submits the pending records as one native batch, or resumes
+// waiting for opts.BatchID, and passes each successful result to save once
+// the batch has ended
+func processNative(ctx context.Context, client aiprovider.Client, opts Options, records *pendingReader, save func(*record, aiprovider.JobResult) error) (*Report, error) {
+	start := time.Now()
+	report := &Report{}
+
+	items := map[string]nativeItem{}
+	var batchItems []aiprovider.BatchItem
+	for {
+		rec, err := records.next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report.Skipped = records.skipped
+			return report, err
+		}
+
+		id := batchItemID(rec.id)
+		req := itemRequest(opts.Request, rec.prompt)
+		items[id] = nativeItem{rec: rec, request: req}
+		batchItems = append(batchItems, aiprovider.BatchItem{ID: id, Request: req})
+	}
+	report.Skipped = records.skipped
+	if len(batchItems) == 0 {
+		return report, nil
+	}
+	report.Submitted = len(batchItems)
+
+	report.BatchID = opts.BatchID
+	if report.BatchID == "" {
+		batch, err := client.SubmitBatch(ctx, batchItems)
+		if err != nil {
+			return report, err
+		}
+		report.BatchID = batch.ID
+	}
+
+	results, err := waitForResults(ctx, client, report.BatchID, opts.PollInterval)
+	if err != nil {
+		return report, err
+	}
+
+	finish := func(item nativeItem, response *aiprovider.ChatResponse, itemErr error) error {
+		result := aiprovider.JobResult{
+			Job:      aiprovider.Job{ID: item.rec.id, Request: item.request},
+			Response: response,
+			Err:      itemErr,
+			Attempts: 1,
+			Latency:  time.Since(start),
+		}
+		report.Completed++
+		if itemErr != nil {
+			report.Failed++
+		} else {
+			usage := response.Usage
+			result.Cost = NativeBatchPriceFactor * aiprovider.ResponseCost(client, response.Metadata, usage)
+			report.Succeeded++
+			report.Usage.PromptTokens += usage.PromptTokens
+			report.Usage.CompletionTokens += usage.CompletionTokens
+			report.Usage.TotalTokens += usage.TotalTokens
+			report.Cost += result.Cost
+			if err := save(item.rec, result); err != nil {
+				return err
+			}
+		}
+
+		if opts.Pool.OnResult != nil {
+			opts.Pool.OnResult(result)
+		}
+		if opts.Pool.OnProgress != nil {
+			opts.Pool.OnProgress(report.PoolProgress)
+		}
+		return nil
+	}
+
+	for _, result := range results {
+		item, ok := items[result.ID]
+		if !ok {
+			// Not pending in this run, e.g. completed since the batch was submitted
+			continue
+		}
+		delete(items, result.ID)
+		if err := finish(item, result.Response, result.Err); err != nil {
+			return report, err
+		}
+	}
+	for _, batchItem := range batchItems {
+		if item, ok := items[batchItem.ID]; ok {
+			if err := finish(item, nil, errMissingResult); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// waitForResults polls a native batch until it ends and returns its results
+func waitForResults(ctx context.Context, client aiprovider.Client, id string, interval time.Duration) ([]aiprovider.BatchResult, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	for {
+		batch, err := client.GetBatch(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if batch.Done() {
+			return client.ListBatchResults(ctx, id)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// batchItemID returns the native batch item ID of a record: the record ID if
+// every provider accepts it, otherwise a hash of it
+func batchItemID(id string) string {
+	if batchItemIDPattern.MatchString(id) {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return "item-" + hex.EncodeToString(sum[:16])
+}
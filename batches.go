@@ -0,0 +1,209 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SubmitBatch submits chat requests for asynchronous processing.
+//
+// Providers process batches at a lower price, typically half the usual one,
+// but may take up to a day to do so; use it for work where latency does not
+// matter, such as evaluations or backfills. Every item is validated and
+// normalized like a ChatComplete request before the batch is submitted, so
+// one invalid item rejects the whole batch without a provider call.
+//
+// Poll GetBatch until Batch.Done reports true, then read the responses with
+// ListBatchResults. The batch package does this for CSV and JSONL files.
+//
+// Example:
+//
+//	batch, err := client.SubmitBatch(ctx, []BatchItem{
+//		{ID: "q1", Request: ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}},
+//		{ID: "q2", Request: ChatRequest{Messages: []Message{{Role: "user", Content: "Bye"}}}},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for !batch.Done() {
+//		time.Sleep(time.Minute)
+//		if batch, err = client.GetBatch(ctx, batch.ID); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//	results, err := client.ListBatchResults(ctx, batch.ID)
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - items: The requests, each with an ID unique within the batch
+//
+// Returns:
+//   - *Batch: The submitted batch with its ID and status
+//   - error: A validation error for invalid items or if batches are unsupported, or a provider error
+func (c *client) SubmitBatch(ctx context.Context, items []BatchItem) (*Batch, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	batcher, err := c.batchAdapter()
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "batch must contain at least one item",
+			Provider: string(c.provider),
+		}
+	}
+
+	normalized := make([]BatchItem, len(items))
+	seen := make(map[string]bool, len(items))
+	var texts []string
+	for i, item := range items {
+		if strings.TrimSpace(item.ID) == "" {
+			return nil, &Error{
+				Type:     ErrorTypeValidation,
+				Message:  fmt.Sprintf("batch item %d has no ID", i),
+				Provider: string(c.provider),
+			}
+		}
+		if seen[item.ID] {
+			return nil, &Error{
+				Type:     ErrorTypeValidation,
+				Message:  fmt.Sprintf("duplicate batch item ID %q", item.ID),
+				Provider: string(c.provider),
+			}
+		}
+		seen[item.ID] = true
+
+		// Batches are uploaded whole, so their content cannot be streamed
+		if hasContentReaders(item.Request.Messages) {
+			return nil, &Error{
+				Type:     ErrorTypeValidation,
+				Message:  fmt.Sprintf("batch item %q streams message content, which batches do not support", item.ID),
+				Provider: string(c.provider),
+			}
+		}
+		req, _, err := c.applyChatPrompt(ctx, item.Request)
+		if err != nil {
+			return nil, err
+		}
+		req, warnings, err := c.validateAndNormalizeChatRequest(req)
+		if err != nil {
+			return nil, &Error{
+				Type:     ErrorTypeValidation,
+				Message:  fmt.Sprintf("batch item %q validation failed: %v", item.ID, err),
+				Provider: string(c.provider),
+				Wrapped:  err,
+			}
+		}
+		if err := c.requireFeatures(chatFeatures(req)...); err != nil {
+			return nil, err
+		}
+		c.reportWarnings(warnings)
+		req, _ = c.applyInjectionGuard(req)
+
+		normalized[i] = BatchItem{ID: item.ID, Request: req}
+		texts = append(texts, chatTexts(req)...)
+	}
+
+	if err := c.checkBudget(ctx); err != nil {
+		return nil, err
+	}
+	batch, err := batcher.SubmitBatch(ctx, normalized)
+	if err != nil {
+		return nil, c.sanitizeError(err, texts)
+	}
+	return batch, nil
+}
+
+// GetBatch returns the current status of a batch submitted with SubmitBatch.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - id: The batch ID
+//
+// Returns:
+//   - *Batch: The batch status and request counts
+//   - error: A validation error if the ID is empty or batches are unsupported, or a provider error
+func (c *client) GetBatch(ctx context.Context, id string) (*Batch, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	batcher, err := c.batchAdapter()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.validateBatchID(id); err != nil {
+		return nil, err
+	}
+
+	return batcher.GetBatch(ctx, id)
+}
+
+// ListBatchResults returns the result of every item of an ended batch.
+//
+// Failed, canceled and expired items have a nil Response and an Err. Batch
+// usage is not reported to Config.UsageRecorder, as results can be listed
+// more than once.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - id: The batch ID
+//
+// Returns:
+//   - []BatchResult: One result per item, in no particular order
+//   - error: A validation error if the ID is empty or batches are unsupported, or a provider error
+func (c *client) ListBatchResults(ctx context.Context, id string) ([]BatchResult, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	batcher, err := c.batchAdapter()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.validateBatchID(id); err != nil {
+		return nil, err
+	}
+
+	return batcher.ListBatchResults(ctx, id)
+}
+
+// batchAdapter returns the adapter as a BatchAdapter, or a validation error
+// if it does not support batches
+func (c *client) batchAdapter() (BatchAdapter, error) {
+	if err := c.requireFeatures(FeatureBatch); err != nil {
+		return nil, err
+	}
+	batcher, ok := c.adapter.(BatchAdapter)
+	if !ok {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("feature %q not supported by provider %s", FeatureBatch, c.provider),
+			Provider: string(c.provider),
+		}
+	}
+	return batcher, nil
+}
+
+// validateBatchID rejects an empty batch ID
+func (c *client) validateBatchID(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "batch ID cannot be empty",
+			Provider: string(c.provider),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// batchingAdapter ends every batch immediately, echoing the last message of each item
+type batchingAdapter struct {
+	mockAdapter
+	submitted []BatchItem
+}
+
+func (b *batchingAdapter) SubmitBatch(ctx context.Context, items []BatchItem) (*Batch, error) {
+	b.submitted = items
+	return &Batch{ID: "batch-1", Status: BatchInProgress, Counts: BatchCounts{Processing: len(items)}}, nil
+}
+
+func (b *batchingAdapter) GetBatch(ctx context.Context, id string) (*Batch, error) {
+	return &Batch{ID: id, Status: BatchEnded, Counts: BatchCounts{Succeeded: len(b.submitted)}}, nil
+}
+
+func (b *batchingAdapter) ListBatchResults(ctx context.Context, id string) ([]BatchResult, error) {
+	results := make([]BatchResult, len(b.submitted))
+	for i, item := range b.submitted {
+		messages := item.Request.Messages
+		results[i] = BatchResult{ID: item.ID, Response: &ChatResponse{
+			Message: Message{Role: "assistant", Content: messages[len(messages)-1].Content},
+		}}
+	}
+	return results, nil
+}
+
+func (b *batchingAdapter) SupportedFeatures() []string {
+	return append(b.mockAdapter.SupportedFeatures(), FeatureBatch)
+}
+
+func TestSubmitBatch(t *testing.T) {
+	adapter := &batchingAdapter{}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.Model = "claude-3-haiku-20240307"
+
+	batch, err := c.SubmitBatch(context.Background(), []BatchItem{
+		{ID: "a", Request: ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}},
+		{ID: "b", Request: ChatRequest{Messages: []Message{{Role: "user", Content: "Bye"}}, Model: "claude-3-opus-20240229"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if batch.ID != "batch-1" || batch.Done() {
+		t.Errorf("Unexpected batch: %+v", batch)
+	}
+	if len(adapter.submitted) != 2 || adapter.submitted[0].Request.Model != "claude-3-haiku-20240307" || adapter.submitted[1].Request.Model != "claude-3-opus-20240229" {
+		t.Errorf("Expected normalized items to reach the adapter, got %+v", adapter.submitted)
+	}
+
+	batch, err = c.GetBatch(context.Background(), batch.ID)
+	if err != nil || !batch.Done() {
+		t.Fatalf("Expected an ended batch, got %+v, %v", batch, err)
+	}
+	results, err := c.ListBatchResults(context.Background(), batch.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[1].ID != "b" || results[1].Response.Message.Content != "Bye" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
+
+func TestSubmitBatch_Validation(t *testing.T) {
+	hi := ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}
+	tests := []struct {
+		name    string
+		adapter ProviderAdapter
+		items   []BatchItem
+	}{
+		{"unsupported provider", &mockAdapter{}, []BatchItem{{ID: "a", Request: hi}}},
+		{"no items", &batchingAdapter{}, nil},
+		{"missing ID", &batchingAdapter{}, []BatchItem{{Request: hi}}},
+		{"duplicate ID", &batchingAdapter{}, []BatchItem{{ID: "a", Request: hi}, {ID: "a", Request: hi}}},
+		{"invalid request", &batchingAdapter{}, []BatchItem{{ID: "a", Request: ChatRequest{}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newMockClient(ProviderAnthropic, tt.adapter)
+			_, err := c.SubmitBatch(context.Background(), tt.items)
+			if aiErr, ok := err.(*Error); !ok || aiErr.Type != ErrorTypeValidation {
+				t.Errorf("Expected a validation error, got %v", err)
+			}
+		})
+	}
+
+	c := newMockClient(ProviderAnthropic, &batchingAdapter{})
+	if _, err := c.GetBatch(context.Background(), ""); err == nil {
+		t.Error("Expected an error for an empty batch ID")
+	}
+	if _, err := c.ListBatchResults(context.Background(), " "); err == nil {
+		t.Error("Expected an error for an empty batch ID")
+	}
+}
+
+func TestSubmitBatch_Budget(t *testing.T) {
+	adapter := &batchingAdapter{}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.UsageRecorder = &fixedBudget{err: &BudgetExceededError{Window: "daily", Limit: 100, Spent: 100}}
+
+	_, err := c.SubmitBatch(context.Background(), []BatchItem{
+		{ID: "a", Request: ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}},
+	})
+	var exceeded *BudgetExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("Expected a budget exceeded error, got %v", err)
+	}
+	if adapter.submitted != nil {
+		t.Errorf("Expected the batch not to be submitted, got %+v", adapter.submitted)
+	}
+}
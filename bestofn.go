@@ -0,0 +1,272 @@
+package aiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ajeet-kumar1087/ai-providers/rag"
+)
+
+// DefaultBestOfNTemperature is the sampling temperature of CompleteBestOfN
+// when neither the request nor the options set one
+const DefaultBestOfNTemperature = 0.9
+
+// Scorer rates candidate completions for CompleteBestOfN.
+type Scorer interface {
+	// Score returns one score per candidate, in candidate order; higher
+	// is better
+	Score(ctx context.Context, prompt string, candidates []string) ([]float64, error)
+}
+
+// ScorerFunc adapts a function to the Scorer interface
+type ScorerFunc func(ctx context.Context, prompt string, candidates []string) ([]float64, error)
+
+// Score implements Scorer
+func (f ScorerFunc) Score(ctx context.Context, prompt string, candidates []string) ([]float64, error) {
+	return f(ctx, prompt, candidates)
+}
+
+// HeuristicScorer scores each candidate with score, e.g. by length, by
+// whether generated code compiles or by keyword coverage
+func HeuristicScorer(score func(candidate string) float64) Scorer {
+	return ScorerFunc(func(ctx context.Context, prompt string, candidates []string) ([]float64, error) {
+		scores := make([]float64, len(candidates))
+		for i, candidate := range candidates {
+			scores[i] = score(candidate)
+		}
+		return scores, nil
+	})
+}
+
+// rewardSchema is the JSON schema of a reward model rating
+var rewardSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"score": {"type": "number"}
+	},
+	"required": ["score"]
+}`)
+
+// RewardModelScorer scores candidates by asking a model to rate each one
+// from 0 to 10 against Criteria, normalized to between 0 and 1. Its
+// requests are not included in BestOfNResult.Usage.
+type RewardModelScorer struct {
+	// Client sends the rating requests (required)
+	Client Client
+
+	// Model overrides the client's default model (optional)
+	Model string
+
+	// Criteria describe a good response, e.g. "Persuasive, under 50 words,
+	// mentions the discount" (optional; default: overall quality)
+	Criteria string
+}
+
+// Score implements Scorer, rating the candidates in parallel
+func (s RewardModelScorer) Score(ctx context.Context, prompt string, candidates []string) ([]float64, error) {
+	criteria := s.Criteria
+	if criteria == "" {
+		criteria = "Overall quality: correct, helpful, clear and well written."
+	}
+
+	scores := make([]float64, len(candidates))
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	for i := range candidates {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			text := fmt.Sprintf("Task:\n%s\n\nCriteria:\n%s\n\nResponse:\n%s", prompt, criteria, candidates[i])
+			result, err := s.Client.Extract(ctx, text, rewardSchema, ExtractOptions{
+				Instructions: "Rate the response to the task from 0 (fails the criteria) to 10 (fully meets them).",
+				Model:        s.Model,
+			})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			var rating struct {
+				Score float64 `json:"score"`
+			}
+			if err := result.Decode(&rating); err != nil {
+				errs[i] = err
+				return
+			}
+			scores[i] = clampScore(rating.Score / 10)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to rate candidate %d: %w", i+1, err)
+		}
+	}
+	return scores, nil
+}
+
+// SimilarityScorer scores candidates by the cosine similarity of their
+// embedding to a reference, e.g. an approved example or the expected
+// answer.
+type SimilarityScorer struct {
+	// Embedder embeds the reference and candidates (required)
+	Embedder rag.Embedder
+
+	// Reference is the text candidates are compared to (required)
+	Reference string
+}
+
+// Score implements Scorer
+func (s SimilarityScorer) Score(ctx context.Context, prompt string, candidates []string) ([]float64, error) {
+	vectors, err := s.Embedder.Embed(ctx, append([]string{s.Reference}, candidates...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed candidates: %w", err)
+	}
+	if len(vectors) != len(candidates)+1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(candidates)+1)
+	}
+	scores := make([]float64, len(candidates))
+	for i := range candidates {
+		scores[i] = cosineSimilarity(vectors[0], vectors[i+1])
+	}
+	return scores, nil
+}
+
+// clampScore limits a score to between 0 and 1
+func clampScore(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
+
+// BestOfNOptions configures CompleteBestOfN.
+type BestOfNOptions struct {
+	// Scorer ranks the candidates (required)
+	Scorer Scorer
+
+	// Temperature overrides the sampling temperature (default: the
+	// request's temperature, or 0.9 if it has none)
+	Temperature *float64
+
+	// Concurrency is the number of candidates requested at once (default: n)
+	Concurrency int
+}
+
+// RankedCandidate is a candidate completion with its score.
+type RankedCandidate struct {
+	// Text is the completion
+	Text string `json:"text"`
+
+	// Score is the scorer's rating
+	Score float64 `json:"score"`
+
+	// Index is the candidate's position in request order
+	Index int `json:"index"`
+
+	// FinishReason indicates why the generation stopped
+	FinishReason string `json:"finish_reason"`
+}
+
+// BestOfNResult is the result of CompleteBestOfN.
+type BestOfNResult struct {
+	// Text is the highest scoring completion
+	Text string `json:"text"`
+
+	// Score is the winner's score
+	Score float64 `json:"score"`
+
+	// Candidates are all completions by descending score
+	Candidates []RankedCandidate `json:"candidates"`
+
+	// Usage is the total token usage of the candidates
+	Usage Usage `json:"usage"`
+}
+
+// CompleteBestOfN generates n candidate completions of req and returns the
+// one opts.Scorer rates highest.
+//
+// The candidates are requested in parallel at a raised temperature for
+// variety, then scored together, so scorers can compare them. Ties go to
+// the candidate requested first. Useful where quality varies between
+// samples and can be judged, such as copywriting and code generation.
+//
+// Example:
+//
+//	result, err := client.CompleteBestOfN(ctx, CompletionRequest{Prompt: "Write a tagline for a bakery"}, 4, BestOfNOptions{
+//		Scorer: RewardModelScorer{Client: judge, Criteria: "Memorable, under 8 words"},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("%s (%.2f)\n", result.Text, result.Score)
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - req: The completion request to sample
+//   - n: The number of candidates (at least 1)
+//   - opts: The scorer and sampling options
+//
+// Returns:
+//   - *BestOfNResult: The winning completion and every candidate's score
+//   - error: A validation error for invalid input, the first request error, or the scorer's error
+func (c *client) CompleteBestOfN(ctx context.Context, req CompletionRequest, n int, opts BestOfNOptions) (*BestOfNResult, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	message := ""
+	switch {
+	case n < 1:
+		message = fmt.Sprintf("number of candidates must be at least 1, got: %d", n)
+	case opts.Scorer == nil:
+		message = "a scorer is required"
+	}
+	if message != "" {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  message,
+			Provider: string(c.provider),
+		}
+	}
+
+	req.Temperature = samplingTemperature(opts.Temperature, req.Temperature, DefaultBestOfNTemperature)
+	responses, err := c.sampleCompletions(ctx, req, n, opts.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BestOfNResult{Candidates: make([]RankedCandidate, n)}
+	texts := make([]string, n)
+	for i, resp := range responses {
+		texts[i] = resp.Text
+		result.Candidates[i] = RankedCandidate{Text: resp.Text, Index: i, FinishReason: resp.FinishReason}
+		result.Usage = addUsage(result.Usage, resp.Usage)
+	}
+
+	scores, err := opts.Scorer.Score(ctx, req.Prompt, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to score candidates: %w", err)
+	}
+	if len(scores) != n {
+		return nil, fmt.Errorf("scorer returned %d scores for %d candidates", len(scores), n)
+	}
+	for i, score := range scores {
+		result.Candidates[i].Score = score
+	}
+
+	sort.SliceStable(result.Candidates, func(i, j int) bool {
+		return result.Candidates[i].Score > result.Candidates[j].Score
+	})
+	result.Text = result.Candidates[0].Text
+	result.Score = result.Candidates[0].Score
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompleteBestOfN(t *testing.T) {
+	adapter := &votingAdapter{answers: []string{"Fresh bread.", "Baked fresh every morning, just for you.", "Bread!"}}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	result, err := c.CompleteBestOfN(context.Background(), CompletionRequest{Prompt: "Tagline?"}, 3, BestOfNOptions{
+		Scorer: HeuristicScorer(func(candidate string) float64 { return float64(len(candidate)) }),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Text != "Baked fresh every morning, just for you." || result.Score != 40 {
+		t.Errorf("Expected the longest candidate to win, got %+v", result)
+	}
+	if len(result.Candidates) != 3 || result.Candidates[2].Score > result.Candidates[1].Score {
+		t.Errorf("Expected candidates by descending score, got %+v", result.Candidates)
+	}
+	if result.Usage.TotalTokens != 45 || *adapter.requests[0].Temperature != DefaultBestOfNTemperature {
+		t.Errorf("Expected 3 samples at the default temperature, got %+v", result.Usage)
+	}
+}
+
+func TestRewardModelScorer(t *testing.T) {
+	judge := newMockClient(ProviderAnthropic, &scriptedAdapter{reply: func(user string) (string, error) {
+		if strings.Contains(user, "Response:\nGood") {
+			return `{"score": 9}`, nil
+		}
+		return `{"score": 12}`, nil
+	}})
+
+	scores, err := RewardModelScorer{Client: judge, Criteria: "Be good"}.Score(context.Background(), "Task", []string{"Good", "Bad"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(scores) != 2 || scores[0] != 0.9 || scores[1] != 1 {
+		t.Errorf("Expected normalized and clamped scores, got %v", scores)
+	}
+}
+
+func TestSimilarityScorer(t *testing.T) {
+	scorer := SimilarityScorer{Embedder: keywordEmbedder{keywords: []string{"refund", "shipping"}}, Reference: "Refunds within 30 days"}
+	scores, err := scorer.Score(context.Background(), "", []string{"We ship for free", "Refund policy: refund anytime"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if scores[0] >= scores[1] {
+		t.Errorf("Expected the candidate closer to the reference to score higher, got %v", scores)
+	}
+}
+
+func TestCompleteBestOfN_Errors(t *testing.T) {
+	c := newMockClient(ProviderOpenAI, &votingAdapter{answers: []string{"a", "b"}})
+	if _, err := c.CompleteBestOfN(context.Background(), CompletionRequest{Prompt: "?"}, 2, BestOfNOptions{}); err == nil {
+		t.Error("Expected a validation error without a scorer")
+	}
+
+	failing := ScorerFunc(func(ctx context.Context, prompt string, candidates []string) ([]float64, error) {
+		return nil, errors.New("boom")
+	})
+	if _, err := c.CompleteBestOfN(context.Background(), CompletionRequest{Prompt: "?"}, 2, BestOfNOptions{Scorer: failing}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected the scorer error, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package aiprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is how long responses stay in Config.Cache when
+// Config.CacheTTL is zero
+const DefaultCacheTTL = time.Hour
+
+// cacheLockTTL bounds how long a replica holds the stampede lock of a cache
+// key, and so how long other replicas wait for its response
+const cacheLockTTL = 30 * time.Second
+
+// cachePollInterval is how often replicas waiting for a locked key check
+// whether the response has been cached
+const cachePollInterval = 100 * time.Millisecond
+
+// completionCacheKey returns the cache key of a normalized completion
+// request, or an empty string if the request is not cached
+func (c *client) completionCacheKey(req CompletionRequest) string {
+	if c.config.Cache == nil || req.SkipCache || c.sampled(req.Temperature) {
+		return ""
+	}
+	req.Prompt = normalizeCacheText(req.Prompt)
+	req.Tags, req.UserID = nil, ""
+	return c.cacheKey("completion", req)
+}
+
+// chatCacheKey returns the cache key of a normalized chat request, or an
+// empty string if the request is not cached
+func (c *client) chatCacheKey(req ChatRequest) string {
+	// Streamed content is not part of the key, so it cannot be cached
+	if c.config.Cache == nil || req.SkipCache || c.sampled(req.Temperature) || hasContentReaders(req.Messages) {
+		return ""
+	}
+	messages := make([]Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		msg.Content = normalizeCacheText(msg.Content)
+		messages[i] = msg
+	}
+	req.Messages = messages
+	req.Tags, req.UserID = nil, ""
+	return c.cacheKey("chat", req)
+}
+
+// sampled reports whether a request temperature makes responses random
+// samples that are not cached. Requests are normalized first, so a nil
+// temperature was set by neither the request nor Config.Temperature and
+// the provider samples at its default, 1.0 for OpenAI.
+func (c *client) sampled(temperature *float64) bool {
+	return (temperature == nil || *temperature > 0) && !c.config.CacheSampled
+}
+
+// cachedMetadata returns the metadata of a response served from the cache.
+// It keeps what describes the stored response, such as the model that
+// generated it, and drops what described the request that stored it, such
+// as its attempts, rate limit state and injection findings.
+func cachedMetadata(stored ResponseMetadata) ResponseMetadata {
+	return ResponseMetadata{
+		Provider:          stored.Provider,
+		Model:             stored.Model,
+		SystemFingerprint: stored.SystemFingerprint,
+		ResponseID:        stored.ResponseID,
+		ReasoningRedacted: stored.ReasoningRedacted,
+		Cached:            true,
+	}
+}
+
+// cacheKey hashes a request with the provider and request kind
+func (c *client) cacheKey(kind string, req interface{}) string {
+	data, err := json.Marshal(req)
+	if err != nil {
+		c.reportCacheError(err)
+		return ""
+	}
+	h := sha256.New()
+	h.Write([]byte(c.provider))
+	h.Write([]byte{0})
+	h.Write([]byte(kind))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeCacheText trims text and collapses whitespace runs, so requests
+// differing only in formatting share a cache entry
+func normalizeCacheText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// lookupCache decodes the cached response for key into out and reports a
+// hit. On a miss it claims the key, so concurrent requests for it, in this
+// process and, when the cache is a CacheLocker, in other replicas, wait for
+// this request's response instead of calling the provider too. The returned
+// release func must be called once the response is stored or has failed.
+func (c *client) lookupCache(ctx context.Context, key string, out interface{}) (bool, func(), error) {
+	var done chan struct{}
+	for {
+		if c.cacheHit(ctx, key, out) {
+			return true, nil, nil
+		}
+
+		c.flightsMu.Lock()
+		pending, busy := c.flights[key]
+		if !busy {
+			if c.flights == nil {
+				c.flights = make(map[string]chan struct{})
+			}
+			done = make(chan struct{})
+			c.flights[key] = done
+		}
+		c.flightsMu.Unlock()
+		if !busy {
+			break
+		}
+
+		// Another request of this process is fetching the response
+		select {
+		case <-pending:
+		case <-ctx.Done():
+			return false, nil, ctx.Err()
+		}
+	}
+	release := func() {
+		c.flightsMu.Lock()
+		delete(c.flights, key)
+		c.flightsMu.Unlock()
+		close(done)
+	}
+
+	locker, ok := c.config.Cache.(CacheLocker)
+	if !ok {
+		return false, release, nil
+	}
+	// Another replica may be fetching the response; wait for it to be cached
+	// unless the lock frees up or expires first
+	deadline := time.Now().Add(cacheLockTTL)
+	for {
+		locked, err := locker.Lock(ctx, key, cacheLockTTL)
+		if err != nil {
+			c.reportCacheError(err)
+			return false, release, nil
+		}
+		if locked {
+			return false, func() {
+				if err := locker.Unlock(context.WithoutCancel(ctx), key); err != nil {
+					c.reportCacheError(err)
+				}
+				release()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			return false, release, nil
+		}
+		if err := sleepContext(ctx, cachePollInterval); err != nil {
+			release()
+			return false, nil, err
+		}
+		if c.cacheHit(ctx, key, out) {
+			release()
+			return true, nil, nil
+		}
+	}
+}
+
+// cacheHit decodes the cached response for key into out, reporting whether
+// there was one
+func (c *client) cacheHit(ctx context.Context, key string, out interface{}) bool {
+	value, ok, err := c.config.Cache.Get(ctx, key)
+	if err != nil {
+		c.reportCacheError(err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(value, out); err != nil {
+		c.reportCacheError(err)
+		return false
+	}
+	return true
+}
+
+// storeCache caches a response under key; an empty key is ignored
+func (c *client) storeCache(ctx context.Context, key string, resp interface{}) {
+	if key == "" {
+		return
+	}
+	value, err := json.Marshal(resp)
+	if err != nil {
+		c.reportCacheError(err)
+		return
+	}
+	ttl := c.config.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	if err := c.config.Cache.Set(ctx, key, value, ttl); err != nil {
+		c.reportCacheError(err)
+	}
+}
+
+// reportCacheError passes a cache failure to the OnCacheError hook
+func (c *client) reportCacheError(err error) {
+	if c.config.OnCacheError != nil {
+		c.config.OnCacheError(err)
+	}
+}
@@ -0,0 +1,107 @@
+// Package cache provides response caches for Config.Cache.
+//
+// MemoryCache keeps responses in process. Services running several replicas
+// share one cache with RedisCache or MemcachedCache instead, so a response
+// computed by one replica is served by all of them. Both also implement
+// types.CacheLocker: when a popular entry expires, one replica calls the
+// provider while the others wait for its response, instead of all of them
+// calling the provider at once.
+//
+// Example:
+//
+//	conn, err := ratelimit.DialRedis(ctx, "redis:6379", ratelimit.RedisOptions{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	config := aiprovider.DefaultConfig().WithAPIKey(key)
+//	config.Cache = cache.NewRedisCache(conn)
+//	config.CacheTTL = 24 * time.Hour
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// DefaultPrefix is prepended to the keys RedisCache and MemcachedCache write
+const DefaultPrefix = "aiprovider:cache:"
+
+// lockSuffix is appended to a key to form the key of its stampede lock
+const lockSuffix = ":lock"
+
+// Cache stores serialized responses. It is an alias of types.ResponseCache.
+type Cache = types.ResponseCache
+
+// MemoryCache keeps responses in process memory.
+//
+// Expired entries are dropped when read, and swept as the cache grows.
+// MemoryCache is safe for concurrent use.
+type MemoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	nextSweep int
+	now       func() time.Time
+}
+
+// entry is a cached value and when it expires
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+// minSweep is the cache size at which MemoryCache first sweeps expired entries
+const minSweep = 1024
+
+// NewMemoryCache creates an empty in-process cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]entry), nextSweep: minSweep, now: time.Now}
+}
+
+// Get returns the value stored under key, implementing types.ResponseCache
+func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !m.now().Before(e.expires) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set stores value under key until ttl passes, implementing
+// types.ResponseCache
+func (m *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.entries[key] = entry{value: append([]byte(nil), value...), expires: now.Add(ttl)}
+	if len(m.entries) >= m.nextSweep {
+		for k, e := range m.entries {
+			if !now.Before(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		m.nextSweep = 2 * len(m.entries)
+		if m.nextSweep < minSweep {
+			m.nextSweep = minSweep
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet dropped
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
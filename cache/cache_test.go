@@ -0,0 +1,329 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	m := NewMemoryCache()
+	now := time.Unix(1700000000, 0)
+	m.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, ok, err := m.Get(ctx, "k"); ok || err != nil {
+		t.Fatalf("Expected a miss on an empty cache, got %v, %v", ok, err)
+	}
+	value := []byte("response")
+	m.Set(ctx, "k", value, time.Minute)
+	value[0] = 'X'
+	if got, ok, _ := m.Get(ctx, "k"); !ok || string(got) != "response" {
+		t.Errorf("Expected a copy of the stored value, got %q, %v", got, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := m.Get(ctx, "k"); ok {
+		t.Error("Expected the entry to expire after its TTL")
+	}
+	if m.Len() != 0 {
+		t.Errorf("Expected the expired entry to be dropped, got %d entries", m.Len())
+	}
+
+	// Growing past the sweep threshold drops expired entries
+	for i := 0; i < minSweep-1; i++ {
+		m.Set(ctx, strconv.Itoa(i), value, time.Second)
+	}
+	now = now.Add(time.Hour)
+	m.Set(ctx, "fresh", value, time.Second)
+	if m.Len() != 1 {
+		t.Errorf("Expected expired entries to be swept, got %d entries", m.Len())
+	}
+}
+
+// fakeRedis is an in-memory RedisClient supporting the commands RedisCache
+// sends. Expiry is not simulated.
+type fakeRedis struct {
+	mu       sync.Mutex
+	data     map[string]string
+	commands [][]string
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{data: make(map[string]string)}
+}
+
+func (f *fakeRedis) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	command := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case []byte:
+			command[i] = string(v)
+		default:
+			command[i] = fmt.Sprint(v)
+		}
+	}
+	f.commands = append(f.commands, command)
+
+	switch command[0] {
+	case "GET":
+		if value, ok := f.data[command[1]]; ok {
+			return value, nil
+		}
+		return nil, nil
+	case "SET":
+		if _, exists := f.data[command[1]]; exists && command[3] == "NX" {
+			return nil, nil
+		}
+		f.data[command[1]] = command[2]
+		return "OK", nil
+	case "EVAL":
+		if f.data[command[3]] == command[4] {
+			delete(f.data, command[3])
+			return int64(1), nil
+		}
+		return int64(0), nil
+	}
+	return nil, fmt.Errorf("unknown command %q", command[0])
+}
+
+func TestRedisCache(t *testing.T) {
+	f := newFakeRedis()
+	c := NewRedisCache(f)
+	ctx := context.Background()
+
+	if _, ok, err := c.Get(ctx, "k"); ok || err != nil {
+		t.Fatalf("Expected a miss, got %v, %v", ok, err)
+	}
+	if err := c.Set(ctx, "k", []byte("response"), 1500*time.Millisecond); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if set := f.commands[1]; strings.Join(set, " ") != "SET "+DefaultPrefix+"k response PX 1500" {
+		t.Errorf("Expected SET with a TTL in milliseconds, got %q", set)
+	}
+	if value, ok, _ := c.Get(ctx, "k"); !ok || string(value) != "response" {
+		t.Errorf("Expected the stored value, got %q, %v", value, ok)
+	}
+
+	// Only one replica gets the lock, and only its holder releases it
+	other := NewRedisCache(f)
+	if locked, err := c.Lock(ctx, "k", time.Second); !locked || err != nil {
+		t.Fatalf("Expected the first lock to succeed, got %v, %v", locked, err)
+	}
+	if locked, _ := other.Lock(ctx, "k", time.Second); locked {
+		t.Error("Expected a second replica not to get the lock")
+	}
+	other.Unlock(ctx, "k")
+	if _, held := f.data[DefaultPrefix+"k"+lockSuffix]; !held {
+		t.Error("Expected a replica not holding the lock not to release it")
+	}
+	if err := c.Unlock(ctx, "k"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if locked, _ := other.Lock(ctx, "k", time.Second); !locked {
+		t.Error("Expected the lock to be free once released")
+	}
+}
+
+// fakeMemcached is a loopback server speaking enough of the memcached text
+// protocol for MemcachedCache. Expiry is not simulated.
+type fakeMemcached struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	data    map[string]string
+	exptime map[string]string
+	cas     map[string]int
+	nextCAS int
+}
+
+func newFakeMemcached(t *testing.T) *fakeMemcached {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on loopback: %v", err)
+	}
+	f := &fakeMemcached{listener: listener, data: make(map[string]string), exptime: make(map[string]string), cas: make(map[string]int)}
+	t.Cleanup(func() { listener.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeMemcached) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				fields := strings.Fields(line)
+				if len(fields) == 0 {
+					return
+				}
+				fmt.Fprint(conn, f.handle(fields, r))
+			}
+		}(conn)
+	}
+}
+
+func (f *fakeMemcached) handle(fields []string, r *bufio.Reader) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch fields[0] {
+	case "get":
+		if value, ok := f.data[fields[1]]; ok {
+			return fmt.Sprintf("VALUE %s 0 %d\r\n%s\r\nEND\r\n", fields[1], len(value), value)
+		}
+		return "END\r\n"
+	case "gets":
+		if value, ok := f.data[fields[1]]; ok {
+			return fmt.Sprintf("VALUE %s 0 %d %d\r\n%s\r\nEND\r\n", fields[1], len(value), f.cas[fields[1]], value)
+		}
+		return "END\r\n"
+	case "cas":
+		n, _ := strconv.Atoi(fields[4])
+		value := make([]byte, n+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return "CLIENT_ERROR bad data chunk\r\n"
+		}
+		if _, exists := f.data[fields[1]]; !exists {
+			return "NOT_FOUND\r\n"
+		}
+		if strconv.Itoa(f.cas[fields[1]]) != fields[5] {
+			return "EXISTS\r\n"
+		}
+		if strings.HasPrefix(fields[3], "-") {
+			delete(f.data, fields[1])
+			return "STORED\r\n"
+		}
+		f.put(fields[1], string(value[:n]), fields[3])
+		return "STORED\r\n"
+	case "set", "add":
+		n, _ := strconv.Atoi(fields[4])
+		value := make([]byte, n+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return "CLIENT_ERROR bad data chunk\r\n"
+		}
+		if _, exists := f.data[fields[1]]; exists && fields[0] == "add" {
+			return "NOT_STORED\r\n"
+		}
+		f.put(fields[1], string(value[:n]), fields[3])
+		return "STORED\r\n"
+	case "delete":
+		if _, ok := f.data[fields[1]]; !ok {
+			return "NOT_FOUND\r\n"
+		}
+		delete(f.data, fields[1])
+		return "DELETED\r\n"
+	}
+	return "ERROR\r\n"
+}
+
+// put stores a value with a fresh compare-and-swap token
+func (f *fakeMemcached) put(key, value, exptime string) {
+	f.nextCAS++
+	f.data[key] = value
+	f.exptime[key] = exptime
+	f.cas[key] = f.nextCAS
+}
+
+func TestMemcachedCache(t *testing.T) {
+	f := newFakeMemcached(t)
+	ctx := context.Background()
+	c, err := DialMemcached(ctx, f.listener.Addr().String(), MemcachedOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if _, ok, err := c.Get(ctx, "k"); ok || err != nil {
+		t.Fatalf("Expected a miss, got %v, %v", ok, err)
+	}
+	value := "line one\r\nline two"
+	if err := c.Set(ctx, "k", []byte(value), 1500*time.Millisecond); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, ok, err := c.Get(ctx, "k"); !ok || err != nil || string(got) != value {
+		t.Errorf("Expected the stored value, got %q, %v, %v", got, ok, err)
+	}
+	if exptime := f.exptime[DefaultPrefix+"k"]; exptime != "2" {
+		t.Errorf("Expected the TTL rounded up to whole seconds, got %s", exptime)
+	}
+
+	if locked, err := c.Lock(ctx, "k", time.Second); !locked || err != nil {
+		t.Fatalf("Expected the first lock to succeed, got %v, %v", locked, err)
+	}
+	if locked, _ := c.Lock(ctx, "k", time.Second); locked {
+		t.Error("Expected a held lock not to be granted again")
+	}
+	if err := c.Unlock(ctx, "k"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if locked, _ := c.Lock(ctx, "k", time.Second); !locked {
+		t.Error("Expected the lock to be free once released")
+	}
+
+	// A lock that expired and was taken by another replica is not released
+	// by its former holder
+	other, err := DialMemcached(ctx, f.listener.Addr().String(), MemcachedOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer other.Close()
+	f.mu.Lock()
+	delete(f.data, DefaultPrefix+"k"+lockSuffix)
+	f.mu.Unlock()
+	if locked, _ := other.Lock(ctx, "k", time.Second); !locked {
+		t.Fatal("Expected another replica to get the expired lock")
+	}
+	if err := c.Unlock(ctx, "k"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if locked, _ := c.Lock(ctx, "k", time.Second); locked {
+		t.Error("Expected a stale holder not to release another replica's lock")
+	}
+	if err := other.Unlock(ctx, "k"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if locked, _ := c.Lock(ctx, "k", time.Second); !locked {
+		t.Error("Expected the lock to be free once its holder released it")
+	}
+
+	// TTLs beyond 30 days are sent as absolute Unix times
+	ttl := 40 * 24 * time.Hour
+	if err := c.Set(ctx, "long", []byte("v"), ttl); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	f.mu.Lock()
+	exptime, _ := strconv.ParseInt(f.exptime[DefaultPrefix+"long"], 10, 64)
+	f.mu.Unlock()
+	if want := time.Now().Add(ttl).Unix(); exptime < want-5 || exptime > want+5 {
+		t.Errorf("Expected an absolute expiry near %d, got %d", want, exptime)
+	}
+
+	// Error replies fail the command; the connection is dialled again after
+	if _, err := c.store(ctx, "bogus", "k", nil, time.Second); err == nil {
+		t.Error("Expected an error reply to fail the command")
+	}
+	if _, ok, err := c.Get(ctx, "k"); !ok || err != nil {
+		t.Errorf("Expected the cache to reconnect, got %v, %v", ok, err)
+	}
+}
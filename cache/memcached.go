@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMemcachedDialTimeout is how long DialMemcached waits for a
+// connection when MemcachedOptions.DialTimeout is zero
+const DefaultMemcachedDialTimeout = 5 * time.Second
+
+// maxMemcachedKey is the longest key memcached accepts
+const maxMemcachedKey = 250
+
+// maxMemcachedRelativeTTL is the longest expiry memcached takes as relative
+// seconds; longer ones are read as absolute Unix times
+const maxMemcachedRelativeTTL = 30 * 24 * time.Hour
+
+// MemcachedOptions configures DialMemcached.
+type MemcachedOptions struct {
+	// Prefix is prepended to keys (default: DefaultPrefix)
+	Prefix string
+
+	// DialTimeout limits connecting (default: DefaultMemcachedDialTimeout)
+	DialTimeout time.Duration
+
+	// TLSConfig enables TLS when set (optional)
+	TLSConfig *tls.Config
+}
+
+// MemcachedCache keeps responses in memcached, shared by every client using
+// the same server and prefix.
+//
+// It speaks the memcached text protocol over a single connection, which is
+// dialled again after a network error. MemcachedCache is safe for concurrent
+// use.
+type MemcachedCache struct {
+	addr string
+	opts MemcachedOptions
+
+	mu     sync.Mutex
+	conn   net.Conn // Nil until dialled or after a network error
+	reader *bufio.Reader
+
+	tokensMu sync.Mutex
+	tokens   map[string]string // Token of each lock held by this cache
+}
+
+// DialMemcached connects to a memcached server.
+//
+// Parameters:
+//   - ctx: Context for the connection attempt
+//   - addr: The server address, e.g. "localhost:11211"
+//   - opts: Key prefix and connection settings
+//
+// Returns:
+//   - *MemcachedCache: The cache, connected to the server
+//   - error: An error if connecting fails
+func DialMemcached(ctx context.Context, addr string, opts MemcachedOptions) (*MemcachedCache, error) {
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultPrefix
+	}
+	m := &MemcachedCache{addr: addr, opts: opts, tokens: make(map[string]string)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.dial(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Get returns the value stored under key, implementing types.ResponseCache
+func (m *MemcachedCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, _, found, err := m.get(ctx, "get", m.key(key))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	return value, found, nil
+}
+
+// Set stores value under key until ttl passes, implementing
+// types.ResponseCache
+func (m *MemcachedCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if _, err := m.store(ctx, "set", m.key(key), value, ttl); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Lock claims key until ttl passes, implementing types.CacheLocker. The
+// claim is an entry added only if absent, so exactly one replica gets it,
+// holding a random token so only that replica releases it.
+func (m *MemcachedCache) Lock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	token, err := newToken()
+	if err != nil {
+		return false, err
+	}
+	stored, err := m.store(ctx, "add", m.key(key+lockSuffix), []byte(token), ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock cache entry: %w", err)
+	}
+	if stored {
+		m.tokensMu.Lock()
+		m.tokens[key] = token
+		m.tokensMu.Unlock()
+	}
+	return stored, nil
+}
+
+// Unlock releases a claim made with Lock, implementing types.CacheLocker.
+// The claim is expired with a compare-and-swap only if it still holds this
+// cache's token, so a claim that expired and was taken by another replica
+// is left alone.
+func (m *MemcachedCache) Unlock(ctx context.Context, key string) error {
+	m.tokensMu.Lock()
+	token, ok := m.tokens[key]
+	delete(m.tokens, key)
+	m.tokensMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	lockKey := m.key(key + lockSuffix)
+	value, cas, found, err := m.get(ctx, "gets", lockKey)
+	if err != nil {
+		return fmt.Errorf("failed to unlock cache entry: %w", err)
+	}
+	if !found || string(value) != token {
+		return nil
+	}
+
+	// A negative expiry expires the entry at once
+	request := fmt.Sprintf("cas %s 0 -1 %d %s\r\n%s\r\n", lockKey, len(value), cas, value)
+	err = m.roundTrip(ctx, request, func(r *bufio.Reader) error {
+		line, err := readLine(r)
+		if err != nil {
+			return err
+		}
+		if line != "STORED" && line != "EXISTS" && line != "NOT_FOUND" {
+			return fmt.Errorf("unexpected memcached reply %q", line)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unlock cache entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection
+func (m *MemcachedCache) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conn == nil {
+		return nil
+	}
+	err := m.conn.Close()
+	m.conn = nil
+	return err
+}
+
+// key prefixes a key. A long user-supplied prefix is cut to fit memcached's
+// key limit, keeping the hashed part of the key intact.
+func (m *MemcachedCache) key(key string) string {
+	key = m.opts.Prefix + key
+	if len(key) > maxMemcachedKey {
+		key = key[len(key)-maxMemcachedKey:]
+	}
+	return key
+}
+
+// get sends a get or gets command for one key and returns its value and,
+// for gets, its compare-and-swap token
+func (m *MemcachedCache) get(ctx context.Context, command, key string) (value []byte, cas string, found bool, err error) {
+	err = m.roundTrip(ctx, command+" "+key+"\r\n", func(r *bufio.Reader) error {
+		for {
+			line, err := readLine(r)
+			if err != nil {
+				return err
+			}
+			if line == "END" {
+				return nil
+			}
+			fields := strings.Fields(line)
+			if (len(fields) != 4 && len(fields) != 5) || fields[0] != "VALUE" {
+				return fmt.Errorf("unexpected memcached reply %q", line)
+			}
+			n, err := strconv.Atoi(fields[3])
+			if err != nil || n < 0 {
+				return fmt.Errorf("malformed memcached value length %q", fields[3])
+			}
+			buf := make([]byte, n+2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return fmt.Errorf("failed to read memcached reply: %w", err)
+			}
+			value, found = buf[:n], true
+			if len(fields) == 5 {
+				cas = fields[4]
+			}
+		}
+	})
+	return value, cas, found, err
+}
+
+// exptime converts a TTL into a memcached expiry: whole seconds, rounded up
+// so entries never expire early, or an absolute Unix time for TTLs beyond
+// the 30 days memcached takes as relative
+func exptime(ttl time.Duration) int64 {
+	if ttl > maxMemcachedRelativeTTL {
+		deadline := time.Now().Add(ttl)
+		return (deadline.UnixNano() + int64(time.Second) - 1) / int64(time.Second)
+	}
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// store sends a set or add command and reports whether the value was stored
+func (m *MemcachedCache) store(ctx context.Context, command, key string, value []byte, ttl time.Duration) (bool, error) {
+	exptime := exptime(ttl)
+	request := fmt.Sprintf("%s %s 0 %d %d\r\n%s\r\n", command, key, exptime, len(value), value)
+
+	var stored bool
+	err := m.roundTrip(ctx, request, func(r *bufio.Reader) error {
+		line, err := readLine(r)
+		if err != nil {
+			return err
+		}
+		switch line {
+		case "STORED":
+			stored = true
+		case "NOT_STORED":
+		default:
+			return fmt.Errorf("unexpected memcached reply %q", line)
+		}
+		return nil
+	})
+	return stored, err
+}
+
+// roundTrip writes a request and reads its reply with read
+func (m *MemcachedCache) roundTrip(ctx context.Context, request string, read func(*bufio.Reader) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conn == nil {
+		if err := m.dial(ctx); err != nil {
+			return err
+		}
+	}
+	deadline, _ := ctx.Deadline()
+	err := m.conn.SetDeadline(deadline)
+	if err == nil {
+		if _, err = io.WriteString(m.conn, request); err != nil {
+			err = fmt.Errorf("failed to send memcached command: %w", err)
+		}
+	}
+	if err == nil {
+		err = read(m.reader)
+	}
+	if err != nil {
+		// The connection may hold half a reply, so it cannot be reused
+		m.conn.Close()
+		m.conn = nil
+	}
+	return err
+}
+
+// dial opens the connection; m.mu must be held
+func (m *MemcachedCache) dial(ctx context.Context) error {
+	timeout := m.opts.DialTimeout
+	if timeout == 0 {
+		timeout = DefaultMemcachedDialTimeout
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	var err error
+	if m.opts.TLSConfig != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: m.opts.TLSConfig}).DialContext(ctx, "tcp", m.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", m.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to memcached: %w", err)
+	}
+	m.conn = conn
+	m.reader = bufio.NewReader(conn)
+	return nil
+}
+
+// readLine reads one reply line without its line ending. Error replies are
+// returned as errors.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read memcached reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "ERROR" || strings.HasPrefix(line, "CLIENT_ERROR") || strings.HasPrefix(line, "SERVER_ERROR") {
+		return "", fmt.Errorf("memcached: %s", line)
+	}
+	return line, nil
+}
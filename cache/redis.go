@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// unlockScript deletes a lock only if it still holds the token of the
+// replica releasing it, so a lock that expired and was claimed by another
+// replica is left alone
+const unlockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// RedisClient sends commands to a Redis server. ratelimit.RedisConn
+// implements it; so does a thin wrapper around a go-redis client:
+//
+//	type goRedis struct{ *redis.Client }
+//
+//	func (r goRedis) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+//		return r.Client.Do(ctx, args...).Result()
+//	}
+//
+// A nil reply must be returned as a nil value, not an error.
+type RedisClient interface {
+	// Do sends a command and returns its reply
+	Do(ctx context.Context, args ...interface{}) (interface{}, error)
+}
+
+// RedisCache keeps responses in Redis, shared by every client using the same
+// server and prefix.
+//
+// RedisCache is safe for concurrent use if its RedisClient is.
+type RedisCache struct {
+	// Prefix is prepended to keys (default: DefaultPrefix)
+	Prefix string
+
+	redis RedisClient
+
+	mu     sync.Mutex
+	tokens map[string]string // Token of each lock held by this cache
+}
+
+// NewRedisCache creates a cache keeping its responses in Redis
+func NewRedisCache(redis RedisClient) *RedisCache {
+	return &RedisCache{Prefix: DefaultPrefix, redis: redis, tokens: make(map[string]string)}
+}
+
+// Get returns the value stored under key, implementing types.ResponseCache
+func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.redis.Do(ctx, "GET", r.Prefix+key)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	switch value := reply.(type) {
+	case nil:
+		return nil, false, nil
+	case string:
+		return []byte(value), true, nil
+	case []byte:
+		return value, true, nil
+	default:
+		return nil, false, fmt.Errorf("unexpected reply to GET: %v", reply)
+	}
+}
+
+// Set stores value under key until ttl passes, implementing
+// types.ResponseCache
+func (r *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if _, err := r.redis.Do(ctx, "SET", r.Prefix+key, value, "PX", milliseconds(ttl)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Lock claims key until ttl passes, implementing types.CacheLocker
+func (r *RedisCache) Lock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	token, err := newToken()
+	if err != nil {
+		return false, err
+	}
+	reply, err := r.redis.Do(ctx, "SET", r.Prefix+key+lockSuffix, token, "NX", "PX", milliseconds(ttl))
+	if err != nil {
+		return false, fmt.Errorf("failed to lock cache entry: %w", err)
+	}
+	if reply == nil {
+		return false, nil
+	}
+
+	r.mu.Lock()
+	r.tokens[key] = token
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Unlock releases a claim made with Lock, implementing types.CacheLocker
+func (r *RedisCache) Unlock(ctx context.Context, key string) error {
+	r.mu.Lock()
+	token, ok := r.tokens[key]
+	delete(r.tokens, key)
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if _, err := r.redis.Do(ctx, "EVAL", unlockScript, 1, r.Prefix+key+lockSuffix, token); err != nil {
+		return fmt.Errorf("failed to unlock cache entry: %w", err)
+	}
+	return nil
+}
+
+// milliseconds formats a TTL in whole milliseconds, at least 1
+func milliseconds(ttl time.Duration) string {
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+// newToken returns a random lock token
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
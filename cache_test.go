@@ -0,0 +1,232 @@
+package aiprovider
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/cache"
+)
+
+// lockingCache adds a CacheLocker to a MemoryCache, as the Redis and
+// memcached caches have
+type lockingCache struct {
+	*cache.MemoryCache
+	mu     sync.Mutex
+	locked map[string]bool
+}
+
+func (l *lockingCache) Lock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locked[key] {
+		return false, nil
+	}
+	l.locked[key] = true
+	return true, nil
+}
+
+func (l *lockingCache) Unlock(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.locked, key)
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(user string) (string, error) { return "Paris", nil }}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Cache = cache.NewMemoryCache()
+	c.config.Temperature = floatPtr(0)
+	ctx := context.Background()
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "What is the capital of France?"}}}
+	first, err := c.ChatComplete(ctx, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.Metadata.Cached {
+		t.Error("Expected the first response not to be cached")
+	}
+
+	// Requests differing only in whitespace and tags share the entry
+	req.Messages[0].Content = "  What is the capital\nof France? "
+	req.Tags = map[string]string{"team": "search"}
+	second, err := c.ChatComplete(ctx, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !second.Metadata.Cached || second.Message.Content != "Paris" || second.Usage.TotalTokens != 15 {
+		t.Errorf("Expected the cached response, got %+v", second)
+	}
+	if second.Metadata.Tags["team"] != "search" {
+		t.Errorf("Expected the request's tags on the cached response, got %v", second.Metadata.Tags)
+	}
+	if len(adapter.requests) != 1 {
+		t.Errorf("Expected one provider call, got %d", len(adapter.requests))
+	}
+
+	// Other parameters and SkipCache miss the cache
+	req.Temperature = floatPtr(0.5)
+	if resp, _ := c.ChatComplete(ctx, req); resp.Metadata.Cached {
+		t.Error("Expected a request with other parameters to miss the cache")
+	}
+	req.SkipCache = true
+	if resp, _ := c.ChatComplete(ctx, req); resp.Metadata.Cached {
+		t.Error("Expected SkipCache to bypass the cache")
+	}
+	if len(adapter.requests) != 3 {
+		t.Errorf("Expected three provider calls, got %d", len(adapter.requests))
+	}
+}
+
+func TestCache_Completion(t *testing.T) {
+	adapter := &mockAdapter{completeResp: &CompletionResponse{Text: "4", FinishReason: "stop"}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Cache = cache.NewMemoryCache()
+	c.config.Temperature = floatPtr(0)
+
+	for i := 0; i < 2; i++ {
+		resp, err := c.Complete(context.Background(), CompletionRequest{Prompt: "2 + 2 ="})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Text != "4" || resp.Metadata.Cached != (i == 1) {
+			t.Errorf("Expected response %d cached=%v, got %+v", i, i == 1, resp)
+		}
+	}
+	if len(adapter.completeRequests) != 1 {
+		t.Errorf("Expected one provider call, got %d", len(adapter.completeRequests))
+	}
+}
+
+func TestCache_Sampled(t *testing.T) {
+	adapter := &votingAdapter{answers: []string{"5 cents", "10 cents"}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Cache = cache.NewMemoryCache()
+	ctx := context.Background()
+
+	// Requests at a temperature above 0 are not cached by default
+	req := CompletionRequest{Prompt: "Bat and ball?", Temperature: floatPtr(0.7)}
+	for i := 0; i < 2; i++ {
+		if resp, _ := c.Complete(ctx, req); resp.Metadata.Cached {
+			t.Error("Expected a sampled request to miss the cache")
+		}
+	}
+
+	// Nor are requests left at the provider's default temperature
+	unset := CompletionRequest{Prompt: "Bat and ball?"}
+	for i := 0; i < 2; i++ {
+		if resp, _ := c.Complete(ctx, unset); resp.Metadata.Cached {
+			t.Error("Expected a request without a temperature to miss the cache")
+		}
+	}
+
+	// Unless Config.Temperature makes them deterministic
+	c.config.Temperature = floatPtr(0)
+	c.Complete(ctx, unset)
+	if resp, _ := c.Complete(ctx, unset); !resp.Metadata.Cached {
+		t.Error("Expected a request at the default temperature of 0 to be cached")
+	}
+	c.config.Temperature = nil
+
+	// Voting draws independent samples even when sampled requests are cached
+	c.config.CacheSampled = true
+	c.Complete(ctx, req)
+	adapter.requests = nil
+	result, err := c.CompleteWithVoting(ctx, req, 5, VotingOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(adapter.requests) != 5 || len(result.Groups) != 2 {
+		t.Errorf("Expected 5 provider calls with differing answers, got %d calls and %+v", len(adapter.requests), result.Groups)
+	}
+}
+
+func TestCache_Stampede(t *testing.T) {
+	release := make(chan struct{})
+	adapter := &scriptedAdapter{reply: func(user string) (string, error) {
+		<-release
+		return "Paris", nil
+	}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Cache = cache.NewMemoryCache()
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "What is the capital of France?"}}, Temperature: floatPtr(0)}
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.ChatComplete(context.Background(), req)
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(adapter.requests) != 1 {
+		t.Errorf("Expected concurrent requests to share one provider call, got %d", len(adapter.requests))
+	}
+}
+
+func TestCache_WaitsForOtherReplica(t *testing.T) {
+	shared := &lockingCache{MemoryCache: cache.NewMemoryCache(), locked: make(map[string]bool)}
+	adapter := &scriptedAdapter{reply: func(user string) (string, error) { return "Paris", nil }}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Cache = shared
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "What is the capital of France?"}}, Temperature: floatPtr(0)}
+	key := c.chatCacheKey(req)
+
+	// Another replica holds the lock and caches its response shortly
+	shared.Lock(context.Background(), key, time.Minute)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		shared.Set(context.Background(), key, []byte(`{"message":{"role":"assistant","content":"Lyon"}}`), time.Minute)
+	}()
+
+	resp, err := c.ChatComplete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !resp.Metadata.Cached || resp.Message.Content != "Lyon" {
+		t.Errorf("Expected the other replica's response, got %+v", resp)
+	}
+	if len(adapter.requests) != 0 {
+		t.Errorf("Expected no provider call, got %d", len(adapter.requests))
+	}
+}
+
+func TestCache_ResetsRequestMetadata(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(user string) (string, error) { return "Paris", nil }}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Cache = cache.NewMemoryCache()
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "What is the capital of France?"}}, Temperature: floatPtr(0)}
+	stored := `{"message":{"role":"assistant","content":"Paris"},"metadata":{"model":"gpt-4o-2024-08-06","attempts":3,` +
+		`"rate_limit":{"requests_remaining":1},"injection_findings":[{"message_index":0}],` +
+		`"usage_mismatches":[{"field":"prompt_tokens"}]}}`
+	c.config.Cache.Set(context.Background(), c.chatCacheKey(req), []byte(stored), time.Minute)
+
+	resp, err := c.ChatComplete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	metadata := resp.Metadata
+	if !metadata.Cached || metadata.Model != "gpt-4o-2024-08-06" {
+		t.Errorf("Expected a cache hit keeping the model, got %+v", metadata)
+	}
+	if metadata.Attempts != 0 || metadata.RateLimit != nil || metadata.InjectionFindings != nil || metadata.UsageMismatches != nil {
+		t.Errorf("Expected the storing request's metadata dropped, got %+v", metadata)
+	}
+}
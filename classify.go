@@ -0,0 +1,234 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DefaultClassifySampleTemperature is the temperature used when ClassifyOptions.Samples > 1
+const DefaultClassifySampleTemperature = 0.7
+
+// ClassifyOptions configures Classify.
+type ClassifyOptions struct {
+	// Instructions adds task context such as "Classify the support ticket by team" (optional)
+	Instructions string
+
+	// Descriptions explains individual labels to the model (optional)
+	Descriptions map[string]string
+
+	// Model overrides the provider default model (optional)
+	Model string
+
+	// Samples is the number of classifications to sample and vote over (default: 1)
+	// More samples give a better confidence estimate at proportionally higher cost
+	Samples int
+
+	// Temperature overrides the sampling temperature (default: 0, or 0.7 when Samples > 1)
+	Temperature *float64
+}
+
+// ClassificationResult is the result of Classify.
+type ClassificationResult struct {
+	// Label is the chosen label, or empty if no response matched a label
+	Label string `json:"label"`
+
+	// Confidence is a heuristic score between 0 and 1 combining how closely
+	// the responses matched a label and how consistently it was chosen
+	Confidence float64 `json:"confidence"`
+
+	// Scores contains the confidence of every label that received votes
+	Scores map[string]float64 `json:"scores"`
+
+	// Responses contains the raw model responses, one per sample
+	Responses []string `json:"responses"`
+
+	// Usage is the total token usage across all samples
+	Usage Usage `json:"usage"`
+}
+
+// Classify assigns text to one of the given labels.
+//
+// The model is asked to answer with a label only, and its response is matched
+// against labels case-insensitively. A response equal to a label counts fully
+// towards it; a response that merely mentions a label counts partially. With
+// Samples > 1 the label with the highest combined score wins, so Confidence
+// also reflects how consistently the model chose it.
+//
+// Example:
+//
+//	result, err := client.Classify(ctx, ticket, []string{"billing", "technical", "other"}, ClassifyOptions{
+//		Instructions: "Route the support ticket to a team.",
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if result.Confidence < 0.5 {
+//		// Ask a human
+//	}
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - text: The text to classify
+//   - labels: The candidate labels (at least two, unique)
+//   - opts: Instructions, label descriptions and sampling options
+//
+// Returns:
+//   - *ClassificationResult: The chosen label with confidence and raw responses
+//   - error: A validation error for invalid input, or the first request error
+func (c *client) Classify(ctx context.Context, text string, labels []string, opts ClassifyOptions) (*ClassificationResult, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	if err := c.validateClassifyInput(text, labels); err != nil {
+		return nil, err
+	}
+
+	samples := opts.Samples
+	if samples <= 0 {
+		samples = 1
+	}
+	temperature := opts.Temperature
+	if temperature == nil {
+		value := 0.0
+		if samples > 1 {
+			value = DefaultClassifySampleTemperature
+		}
+		temperature = &value
+	}
+	maxTokens := 0
+	for _, label := range labels {
+		maxTokens += len(label)
+	}
+	maxTokens += 16
+
+	req := helperRequest{
+		system:      "You are a precise text classifier. Answer with exactly one of the allowed labels and nothing else.",
+		user:        classifyPrompt(text, labels, opts),
+		model:       opts.Model,
+		temperature: temperature,
+		maxTokens:   &maxTokens,
+	}
+
+	result := &ClassificationResult{Scores: make(map[string]float64)}
+	for i := 0; i < samples; i++ {
+		gen, err := c.generate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		result.Responses = append(result.Responses, gen.text)
+		result.Usage = addUsage(result.Usage, gen.usage)
+
+		if label, score := matchLabel(gen.text, labels); label != "" {
+			result.Scores[label] += score / float64(samples)
+		}
+	}
+
+	// Pick the highest score, breaking ties by label order
+	for _, label := range labels {
+		if score, ok := result.Scores[label]; ok && score > result.Confidence {
+			result.Label = label
+			result.Confidence = score
+		}
+	}
+
+	return result, nil
+}
+
+// validateClassifyInput rejects empty text and empty or duplicate labels
+func (c *client) validateClassifyInput(text string, labels []string) error {
+	message := ""
+	seen := make(map[string]bool, len(labels))
+	switch {
+	case strings.TrimSpace(text) == "":
+		message = "text to classify cannot be empty"
+	case len(labels) < 2:
+		message = "at least two labels are required"
+	default:
+		for _, label := range labels {
+			key := normalizeLabel(label)
+			if key == "" {
+				message = "labels cannot be empty"
+				break
+			}
+			if seen[key] {
+				message = fmt.Sprintf("duplicate label %q", label)
+				break
+			}
+			seen[key] = true
+		}
+	}
+
+	if message == "" {
+		return nil
+	}
+	return &Error{
+		Type:     ErrorTypeValidation,
+		Message:  message,
+		Provider: string(c.provider),
+	}
+}
+
+// classifyPrompt builds the user prompt listing the allowed labels
+func classifyPrompt(text string, labels []string, opts ClassifyOptions) string {
+	var b strings.Builder
+	if opts.Instructions != "" {
+		b.WriteString(opts.Instructions + "\n\n")
+	}
+	b.WriteString("Allowed labels:\n")
+	for _, label := range labels {
+		if description := opts.Descriptions[label]; description != "" {
+			fmt.Fprintf(&b, "- %s: %s\n", label, description)
+		} else {
+			fmt.Fprintf(&b, "- %s\n", label)
+		}
+	}
+	b.WriteString("\n<text>\n" + text + "\n</text>\n\nRespond with the label only.")
+	return b.String()
+}
+
+// matchLabel maps a model response to a label and a match score: 1 for an
+// exact match, 0.8 if exactly one label is mentioned and 0.5 if several are
+// (the first mentioned wins)
+func matchLabel(response string, labels []string) (string, float64) {
+	normalized := normalizeLabel(response)
+	for _, label := range labels {
+		if normalized == normalizeLabel(label) {
+			return label, 1
+		}
+	}
+
+	best, bestPos, matches := "", -1, 0
+	padded := " " + normalized + " "
+	for _, label := range labels {
+		pos := strings.Index(padded, " "+normalizeLabel(label)+" ")
+		if pos < 0 {
+			continue
+		}
+		matches++
+		if bestPos < 0 || pos < bestPos || (pos == bestPos && len(label) > len(best)) {
+			best, bestPos = label, pos
+		}
+	}
+
+	switch matches {
+	case 0:
+		return "", 0
+	case 1:
+		return best, 0.8
+	default:
+		return best, 0.5
+	}
+}
+
+// normalizeLabel lowercases s and replaces punctuation and whitespace runs with single spaces
+func normalizeLabel(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	})
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,100 @@
+package aiprovider
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	labels := []string{"billing", "technical", "other"}
+
+	tests := []struct {
+		name           string
+		replies        []string
+		samples        int
+		wantLabel      string
+		wantConfidence float64
+	}{
+		{"exact match", []string{"billing"}, 1, "billing", 1},
+		{"case and punctuation", []string{" Technical. "}, 1, "technical", 1},
+		{"mentioned", []string{"The label is technical"}, 1, "technical", 0.8},
+		{"several mentioned", []string{"other, maybe billing"}, 1, "other", 0.5},
+		{"no match", []string{"I don't know"}, 1, "", 0},
+		{"voting", []string{"billing", "technical", "billing", "billing"}, 4, "billing", 0.75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := 0
+			adapter := &scriptedAdapter{reply: func(string) (string, error) {
+				reply := tt.replies[next]
+				next++
+				return reply, nil
+			}}
+			c := newMockClient(ProviderAnthropic, adapter)
+
+			result, err := c.Classify(context.Background(), "I was charged twice", labels, ClassifyOptions{Samples: tt.samples})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Label != tt.wantLabel {
+				t.Errorf("Expected label %q, got %q", tt.wantLabel, result.Label)
+			}
+			if result.Confidence != tt.wantConfidence {
+				t.Errorf("Expected confidence %v, got %v", tt.wantConfidence, result.Confidence)
+			}
+			if len(result.Responses) != tt.samples {
+				t.Errorf("Expected %d responses, got %d", tt.samples, len(result.Responses))
+			}
+		})
+	}
+}
+
+func TestClassify_Prompt(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(string) (string, error) { return "spam", nil }}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	_, err := c.Classify(context.Background(), "Win a prize!", []string{"spam", "ham"}, ClassifyOptions{
+		Instructions: "Filter email.",
+		Descriptions: map[string]string{"spam": "unsolicited bulk email"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	req := adapter.requests[0]
+	user := req.Messages[len(req.Messages)-1].Content
+	for _, want := range []string{"Filter email.", "- spam: unsolicited bulk email", "- ham\n", "Win a prize!"} {
+		if !strings.Contains(user, want) {
+			t.Errorf("Expected prompt to contain %q, got %q", want, user)
+		}
+	}
+	if req.Temperature == nil || *req.Temperature != 0 {
+		t.Errorf("Expected temperature 0 for a single sample")
+	}
+}
+
+func TestClassify_Validation(t *testing.T) {
+	c := newMockClient(ProviderAnthropic, &scriptedAdapter{})
+
+	tests := []struct {
+		name   string
+		text   string
+		labels []string
+	}{
+		{"empty text", " ", []string{"a", "b"}},
+		{"one label", "text", []string{"a"}},
+		{"empty label", "text", []string{"a", " "}},
+		{"duplicate label", "text", []string{"Yes", "yes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Classify(context.Background(), tt.text, tt.labels, ClassifyOptions{})
+			if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+				t.Errorf("Expected validation error, got %v", err)
+			}
+		})
+	}
+}
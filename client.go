@@ -40,10 +40,15 @@ package aiprovider
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/ajeet-kumar1087/ai-providers/adapters/anthropic"
 	"github.com/ajeet-kumar1087/ai-providers/adapters/openai"
 	"github.com/ajeet-kumar1087/ai-providers/internal/utils"
+	"github.com/ajeet-kumar1087/ai-providers/models"
+	"github.com/ajeet-kumar1087/ai-providers/pricing"
+	"github.com/ajeet-kumar1087/ai-providers/ratelimit"
 	"github.com/ajeet-kumar1087/ai-providers/types"
 )
 
@@ -51,9 +56,28 @@ import (
 // It delegates requests to provider-specific adapters while providing
 // unified parameter validation and error handling.
 type client struct {
-	adapter  ProviderAdapter // The provider-specific adapter
-	provider ProviderType    // The provider type for this client
-	config   Config          // The configuration used to create this client
+	adapter  ProviderAdapter   // The provider-specific adapter
+	provider ProviderType      // The provider type for this client
+	config   Config            // The configuration used to create this client
+	pricing  *pricing.Registry // Prices used to compute usage record costs
+	features []string          // Adapter features, cached so checks do not allocate
+
+	mu         sync.RWMutex              // Guards the fields below
+	rateLimit  *RateLimitStatus          // Last rate limit state reported by the provider
+	profiles   map[string]RequestProfile // Named request defaults
+	downgrades map[string]time.Time      // End of the cool-down of each downgraded model
+
+	flightsMu sync.Mutex               // Guards flights
+	flights   map[string]chan struct{} // Cache misses being fetched, closed when done
+
+	groupsMu sync.Mutex                           // Guards groups
+	groups   map[string]map[*groupMember]struct{} // Requests in flight by cancellation group
+
+	lifecycle sync.Mutex     // Guards closed and adding to inflight
+	closed    bool           // Close was called; new requests are rejected
+	inflight  sync.WaitGroup // Requests and streams in progress
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // NewClient creates a new client instance for the specified provider.
@@ -116,10 +140,37 @@ func NewClient(provider ProviderType, config Config) (Client, error) {
 		}
 	}
 
+	// Load pricing overrides into a client-specific copy of the default prices
+	prices := pricing.Default()
+	if config.PricingFile != "" {
+		prices = prices.Clone()
+		if err := prices.LoadFile(config.PricingFile); err != nil {
+			return nil, &Error{
+				Type:     ErrorTypeValidation,
+				Message:  fmt.Sprintf("invalid pricing file: %v", err),
+				Provider: string(provider),
+				Wrapped:  err,
+			}
+		}
+	}
+
+	profiles := make(map[string]RequestProfile, len(config.Profiles))
+	for name, profile := range config.Profiles {
+		profiles[name] = profile
+	}
+
+	// Rate limit in process unless a shared store is configured
+	if config.RequestsPerMinute > 0 && config.RateLimiterStore == nil {
+		config.RateLimiterStore = ratelimit.NewMemoryStore()
+	}
+
 	return &client{
 		adapter:  adapter,
 		provider: provider,
 		config:   config,
+		pricing:  prices,
+		features: adapter.SupportedFeatures(),
+		profiles: profiles,
 	}, nil
 }
 
@@ -150,8 +201,25 @@ func NewClient(provider ProviderType, config Config) (Client, error) {
 //   - *CompletionResponse: The completion response with generated text and usage info
 //   - error: An error if the request fails or parameters are invalid
 func (c *client) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+	ctx, leave := c.joinGroup(ctx)
+	defer leave()
+
+	// Route the user to their experiment variants
+	req, experiments := c.applyCompletionExperiments(ctx, req)
+
+	// Send the selected version of the request's versioned prompt
+	req, promptRef, err := c.applyCompletionPrompt(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate and normalize the request before delegation
-	normalizedReq, err := c.validateAndNormalizeCompletionRequest(req)
+	normalizedReq, warnings, err := c.validateAndNormalizeCompletionRequest(req)
 	if err != nil {
 		return nil, &Error{
 			Type:     ErrorTypeValidation,
@@ -161,8 +229,80 @@ func (c *client) Complete(ctx context.Context, req CompletionRequest) (*Completi
 		}
 	}
 
+	// Reject requests the adapter cannot serve before any provider call
+	if err := c.requireFeatures(FeatureCompletion); err != nil {
+		return nil, err
+	}
+	c.reportWarnings(warnings)
+
+	ctx, err = c.withProject(ctx, normalizedReq.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkEndpoint(ctx); err != nil {
+		return nil, err
+	}
+
+	// Serve repeated requests from the response cache
+	cacheKey := c.completionCacheKey(normalizedReq)
+	if cacheKey != "" {
+		var cached CompletionResponse
+		hit, release, err := c.lookupCache(ctx, cacheKey, &cached)
+		if err != nil {
+			return nil, groupError(ctx, err)
+		}
+		if hit {
+			cached.Metadata = cachedMetadata(cached.Metadata)
+			cached.Metadata.Experiments = experiments
+			cached.Metadata.Prompt = promptRef
+			cached.Metadata.Tags = normalizedReq.Tags
+			cached.Metadata.Warnings = warnings
+			return &cached, nil
+		}
+		defer release()
+	}
+
+	if err := c.checkBudget(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.waitRateLimit(ctx); err != nil {
+		return nil, groupError(ctx, err)
+	}
+
 	// Delegate to the provider adapter
-	return c.adapter.Complete(ctx, normalizedReq)
+	ctx, capture := c.captureExchanges(ctx)
+	start := time.Now()
+	resp, err := c.adapter.Complete(ctx, normalizedReq)
+	if err != nil {
+		bundleReq := normalizedReq
+		bundleReq.Profile = ""
+		c.noteQuotaError(normalizedReq.Model, err)
+		err = c.sanitizeError(err, completionTexts(normalizedReq))
+		return nil, groupError(ctx, c.attachDebugBundle(err, capture, DebugBundle{CompletionRequest: &bundleReq, StartedAt: start}))
+	}
+	resp.Metadata.Experiments = experiments
+	resp.Metadata.Prompt = promptRef
+	resp.Metadata.Tags = normalizedReq.Tags
+	resp.Metadata.Warnings = warnings
+	c.verifyUsage(&resp.Metadata, resp.Usage, normalizedReq.Prompt, nil, resp.Text)
+	if text, trimmed := trimToLength(resp.Text, normalizedReq.MaxWords, normalizedReq.MaxChars); trimmed {
+		resp.Text = text
+		resp.FinishReason = "length"
+		resp.MatchedStopSequence = nil
+	}
+
+	latency := time.Since(start)
+	c.observeResponse(resp.Metadata, resp.Usage, latency)
+	c.saveInteraction(ctx, InteractionRecord{
+		Prompt:       normalizedReq.Prompt,
+		Response:     resp.Text,
+		FinishReason: resp.FinishReason,
+		Usage:        resp.Usage,
+		Latency:      latency,
+		Metadata:     resp.Metadata,
+	})
+	c.storeCache(ctx, cacheKey, resp)
+	return resp, nil
 }
 
 // ChatComplete sends a chat completion request to the configured AI provider.
@@ -192,8 +332,25 @@ func (c *client) Complete(ctx context.Context, req CompletionRequest) (*Completi
 //   - *ChatResponse: The chat response with the assistant's message and usage info
 //   - error: An error if the request fails or conversation structure is invalid
 func (c *client) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+	ctx, leave := c.joinGroup(ctx)
+	defer leave()
+
+	// Route the user to their experiment variants
+	req, experiments := c.applyChatExperiments(ctx, req)
+
+	// Send the selected version of the request's versioned prompt
+	req, promptRef, err := c.applyChatPrompt(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate and normalize the request before delegation
-	normalizedReq, err := c.validateAndNormalizeChatRequest(req)
+	normalizedReq, warnings, err := c.validateAndNormalizeChatRequest(req)
 	if err != nil {
 		return nil, &Error{
 			Type:     ErrorTypeValidation,
@@ -203,15 +360,302 @@ func (c *client) ChatComplete(ctx context.Context, req ChatRequest) (*ChatRespon
 		}
 	}
 
+	// Reject requests the adapter cannot serve before any provider call
+	if err := c.requireFeatures(chatFeatures(normalizedReq)...); err != nil {
+		return nil, err
+	}
+	c.reportWarnings(warnings)
+
+	ctx, err = c.withProject(ctx, normalizedReq.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkEndpoint(ctx); err != nil {
+		return nil, err
+	}
+
+	// Serve repeated requests from the response cache
+	cacheKey := c.chatCacheKey(normalizedReq)
+	if cacheKey != "" {
+		var cached ChatResponse
+		hit, release, err := c.lookupCache(ctx, cacheKey, &cached)
+		if err != nil {
+			return nil, groupError(ctx, err)
+		}
+		if hit {
+			cached.Metadata = cachedMetadata(cached.Metadata)
+			cached.Metadata.Experiments = experiments
+			cached.Metadata.Prompt = promptRef
+			cached.Metadata.Tags = normalizedReq.Tags
+			cached.Metadata.Warnings = warnings
+			return &cached, nil
+		}
+		defer release()
+	}
+
+	if err := c.checkBudget(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.waitRateLimit(ctx); err != nil {
+		return nil, groupError(ctx, err)
+	}
+
+	// Replaying the request applies the injection guard again, so bundles
+	// record it unguarded
+	bundleReq := normalizedReq
+	bundleReq.Profile = ""
+	bundleReq.Messages = c.storedMessages(bundleReq.Messages)
+
+	// Wrap untrusted content and flag suspicious instructions
+	normalizedReq, findings := c.applyInjectionGuard(normalizedReq)
+
 	// Delegate to the provider adapter
-	return c.adapter.ChatComplete(ctx, normalizedReq)
+	ctx, capture := c.captureExchanges(ctx)
+	start := time.Now()
+	resp, err := c.adapter.ChatComplete(ctx, normalizedReq)
+	if err != nil {
+		c.noteQuotaError(normalizedReq.Model, err)
+		err = c.sanitizeError(err, chatTexts(normalizedReq))
+		return nil, groupError(ctx, c.attachDebugBundle(err, capture, DebugBundle{ChatRequest: &bundleReq, StartedAt: start}))
+	}
+	resp.Metadata.InjectionFindings = findings
+	resp.Metadata.Experiments = experiments
+	resp.Metadata.Prompt = promptRef
+	resp.Metadata.Tags = normalizedReq.Tags
+	resp.Metadata.Warnings = warnings
+	c.verifyUsage(&resp.Metadata, resp.Usage, "", normalizedReq.Messages, generatedText(resp.Message.Content, resp.Message.Reasoning))
+	c.redactReasoning(resp)
+	if text, trimmed := trimToLength(resp.Message.Content, normalizedReq.MaxWords, normalizedReq.MaxChars); trimmed {
+		resp.Message.Content = text
+		resp.FinishReason = "length"
+		resp.MatchedStopSequence = nil
+	}
+
+	latency := time.Since(start)
+	c.observeResponse(resp.Metadata, resp.Usage, latency)
+	c.saveInteraction(ctx, InteractionRecord{
+		Messages:     c.storedMessages(normalizedReq.Messages),
+		Response:     resp.Message.Content,
+		FinishReason: resp.FinishReason,
+		Usage:        resp.Usage,
+		Latency:      latency,
+		Metadata:     resp.Metadata,
+	})
+	c.storeCache(ctx, cacheKey, resp)
+	return resp, nil
+}
+
+// RateLimitStatus returns the most recent rate limit state reported by the provider.
+//
+// Example:
+//
+//	if status := client.RateLimitStatus(); status != nil && status.RequestsRemaining == 0 {
+//		time.Sleep(time.Until(status.RequestsReset))
+//	}
+//
+// Returns:
+//   - *RateLimitStatus: A copy of the last observed status, or nil if none has been observed yet
+func (c *client) RateLimitStatus() *RateLimitStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.rateLimit == nil {
+		return nil
+	}
+	status := *c.rateLimit
+	return &status
+}
+
+// SupportsFeature reports whether the provider adapter supports a feature.
+//
+// Example:
+//
+//	if client.SupportsFeature(FeatureChatCompletion) {
+//		resp, err = client.ChatComplete(ctx, chatReq)
+//	} else {
+//		resp, err = client.Complete(ctx, completionReq)
+//	}
+//
+// Parameters:
+//   - feature: A feature identifier such as FeatureChatCompletion
+//
+// Returns:
+//   - bool: true if the adapter lists the feature in SupportedFeatures
+func (c *client) SupportsFeature(feature string) bool {
+	return containsFeature(c.supportedFeatures(), feature)
+}
+
+// supportedFeatures returns the adapter's features, cached by NewClient
+func (c *client) supportedFeatures() []string {
+	if c.features != nil {
+		return c.features
+	}
+	return c.adapter.SupportedFeatures()
+}
+
+// containsFeature reports whether features lists feature
+func containsFeature(features []string, feature string) bool {
+	for _, supported := range features {
+		if supported == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// requireFeatures returns a validation error naming the first feature the adapter does not support
+func (c *client) requireFeatures(features ...string) error {
+	supported := c.supportedFeatures()
+	for _, feature := range features {
+		if !containsFeature(supported, feature) {
+			return &Error{
+				Type:     ErrorTypeValidation,
+				Message:  fmt.Sprintf("feature %q not supported by provider %s", feature, c.provider),
+				Provider: string(c.provider),
+			}
+		}
+	}
+	return nil
+}
+
+// chatFeatures returns the features a chat request cannot be served
+// without; optional parameters are checked by missingParameterFeatures
+func chatFeatures(req ChatRequest) []string {
+	// One spare slot for the streaming feature StreamChat appends
+	features := make([]string, 1, 4)
+	features[0] = FeatureChatCompletion
+	for _, msg := range req.Messages {
+		if msg.Role == "system" {
+			features = append(features, FeatureSystemMessages)
+			break
+		}
+	}
+	if hasContentReaders(req.Messages) {
+		features = append(features, FeatureStreamingInput)
+	}
+	return features
+}
+
+// parameterFeature pairs an optional request parameter with the adapter
+// feature it needs
+type parameterFeature struct {
+	parameter string
+	feature   string
+	set       bool
+}
+
+// missingParameterFeatures returns the set parameters whose feature the
+// adapter does not advertise, to be handled by the unsupported parameter
+// policy; requests mapped without an adapter are not checked
+func (c *client) missingParameterFeatures(params ...parameterFeature) []UnsupportedParameter {
+	if c.adapter == nil {
+		return nil
+	}
+	supported := c.supportedFeatures()
+	var unsupported []UnsupportedParameter
+	for _, param := range params {
+		if param.set && !containsFeature(supported, param.feature) {
+			unsupported = append(unsupported, UnsupportedParameter{
+				Provider:  c.provider,
+				Parameter: param.parameter,
+				Reason:    fmt.Sprintf("feature %q not supported by provider %s", param.feature, c.provider),
+			})
+		}
+	}
+	return unsupported
+}
+
+// hasContentReaders reports whether any message streams its content
+func hasContentReaders(messages []Message) bool {
+	for _, msg := range messages {
+		if msg.ContentReader != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// observeResponse updates client state from a successful response
+func (c *client) observeResponse(metadata ResponseMetadata, usage Usage, latency time.Duration) {
+	c.recordRateLimit(metadata.RateLimit)
+
+	if c.config.UsageRecorder != nil {
+		c.config.UsageRecorder.RecordUsage(UsageRecord{
+			Provider:          c.provider,
+			Model:             metadata.Model,
+			SystemFingerprint: metadata.SystemFingerprint,
+			Usage:             usage,
+			Cost:              c.cost(metadata.Model, usage),
+			Latency:           latency,
+			Attempts:          metadata.Attempts,
+			RetryWait:         metadata.RetryWait,
+			Experiments:       metadata.Experiments,
+			Prompt:            metadata.Prompt,
+			Tags:              metadata.Tags,
+			UsageMismatch:     len(metadata.UsageMismatches) > 0,
+			TimeToFirstToken:  metadata.TimeToFirstToken,
+			TokensPerSecond:   metadata.TokensPerSecond,
+			Timestamp:         time.Now(),
+		})
+	}
+}
+
+// saveInteraction persists a completed request to the configured store.
+// Store failures are reported to OnStoreError and never fail the request.
+func (c *client) saveInteraction(ctx context.Context, record InteractionRecord) {
+	if c.config.Store == nil {
+		return
+	}
+
+	record.Provider = c.provider
+	record.Model = record.Metadata.Model
+	record.Cost = c.cost(record.Model, record.Usage)
+	record.Timestamp = time.Now()
+
+	if err := c.config.Store.SaveInteraction(ctx, record); err != nil && c.config.OnStoreError != nil {
+		c.config.OnStoreError(err)
+	}
 }
 
-// Close cleans up resources and closes the client.
+// cost prices a request's usage with the client's pricing registry
+func (c *client) cost(model string, usage Usage) float64 {
+	if c.pricing == nil {
+		return 0
+	}
+	return c.pricing.Cost(c.provider, model, usage)
+}
+
+// checkBudget fails requests rejected by a UsageRecorder that caps spend
+func (c *client) checkBudget(ctx context.Context) error {
+	if guard, ok := c.config.UsageRecorder.(BudgetGuard); ok {
+		return guard.CheckBudget(ctx)
+	}
+	return nil
+}
+
+// recordRateLimit stores the rate limit state from a response, ignoring responses without one
+func (c *client) recordRateLimit(status *RateLimitStatus) {
+	if status == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Responses may complete out of order; keep the most recent observation
+	if c.rateLimit == nil || !status.ObservedAt.Before(c.rateLimit.ObservedAt) {
+		copied := *status
+		c.rateLimit = &copied
+	}
+}
+
+// Close shuts the client down gracefully.
 //
-// Currently, this method performs no cleanup as the client uses stateless
-// HTTP connections. However, it's provided for future compatibility and
-// should always be called when the client is no longer needed.
+// New requests and streams fail with ErrClientClosed as soon as Close is
+// called. Close then waits up to Config.ShutdownTimeout for requests and
+// streams in flight to finish, flushes a UsageRecorder or Store that
+// implements Flusher, and closes idle connections. Streams are in flight
+// until Recv returns an error or io.EOF, or the stream is closed.
 //
 // Example:
 //
@@ -222,10 +666,13 @@ func (c *client) ChatComplete(ctx context.Context, req ChatRequest) (*ChatRespon
 //	defer client.Close() // Always close the client
 //
 // Returns:
-//   - error: Always returns nil in the current implementation
+//   - error: An error if in-flight requests did not finish in time or a
+//     flush failed; later calls return the same error
 func (c *client) Close() error {
-	// Currently no cleanup needed, but this allows for future resource management
-	return nil
+	c.closeOnce.Do(func() {
+		c.closeErr = c.shutdown()
+	})
+	return c.closeErr
 }
 
 // defaultClientFactory is the default implementation of ClientFactory.
@@ -412,18 +859,35 @@ func createAnthropicAdapter(config Config) (ProviderAdapter, error) {
 
 // Parameter validation and mapping functions
 
-// validateAndNormalizeCompletionRequest validates and normalizes a completion request
-func (c *client) validateAndNormalizeCompletionRequest(req CompletionRequest) (CompletionRequest, error) {
-	// First, perform basic validation using utilities
+// validateAndNormalizeCompletionRequest validates and normalizes a completion
+// request, returning warnings for the parameters it clamped or dropped
+func (c *client) validateAndNormalizeCompletionRequest(req CompletionRequest) (CompletionRequest, []Warning, error) {
+	// Fill unset fields from the selected request profile
+	req, err := c.applyCompletionProfile(req)
+	if err != nil {
+		return req, nil, err
+	}
+
+	// Perform basic validation using utilities
 	if err := utils.ValidateCompletionRequest(req); err != nil {
-		return req, err
+		return req, nil, err
 	}
 
-	// Create a copy to avoid modifying the original request
-	normalized := req
+	// Reject or report values beyond the provider limits before clamping
+	outOfRange := utils.FindOutOfRangeCompletion(req, c.provider)
+	if err := utils.ApplyValidationMode(c.config.ValidationMode, c.config.OnOutOfRangeParameter, outOfRange); err != nil {
+		return req, nil, err
+	}
+
+	// Handle parameters the provider does not support according to the configured policy
+	unsupported := c.unsupportedCompletion(req)
+	if err := c.applyUnsupportedParameterPolicy(unsupported); err != nil {
+		return req, nil, err
+	}
+	req = c.dropUnsupportedCompletion(req)
 
 	// Apply parameter clamping for the target provider
-	clamped := utils.ClampParameters(normalized, c.provider).(CompletionRequest)
+	clamped := utils.ClampCompletion(req, c.provider)
 
 	// Apply default values from config if not specified in request
 	if clamped.Temperature == nil && c.config.Temperature != nil {
@@ -444,26 +908,81 @@ func (c *client) validateAndNormalizeCompletionRequest(req CompletionRequest) (C
 		}
 	}
 
-	return clamped, nil
+	// Apply the default model from config if the request does not select one
+	if clamped.Model == "" {
+		clamped.Model = c.config.Model
+	}
+
+	// Convert word and character limits into an approximate token limit
+	clamped.MaxTokens = lengthTokenLimit(clamped.MaxTokens, clamped.MaxWords, clamped.MaxChars, utils.GetProviderTokenLimit(c.provider))
+
+	model, downgraded := c.downgradeModel(clamped.Model)
+	clamped.Model = model
+
+	// Handle parameters, including profile and config defaults, that need a
+	// feature the adapter lacks according to the same policy
+	missing := c.missingParameterFeatures(
+		parameterFeature{"temperature", FeatureTemperature, clamped.Temperature != nil},
+		parameterFeature{"max_tokens", FeatureMaxTokens, clamped.MaxTokens != nil},
+		parameterFeature{"stop", FeatureStopSequences, len(clamped.Stop) > 0},
+	)
+	if err := c.applyUnsupportedParameterPolicy(missing); err != nil {
+		return req, nil, err
+	}
+	for _, param := range missing {
+		switch param.Parameter {
+		case "temperature":
+			clamped.Temperature = nil
+		case "max_tokens":
+			clamped.MaxTokens = nil
+		case "stop":
+			clamped.Stop = nil
+		}
+	}
+	unsupported = append(unsupported, missing...)
+
+	warnings := append(adjustmentWarnings(outOfRange, unsupported), downgraded...)
+	warnings = append(warnings, c.deprecationWarnings(clamped.Model)...)
+	return clamped, warnings, nil
 }
 
-// validateAndNormalizeChatRequest validates and normalizes a chat request
-func (c *client) validateAndNormalizeChatRequest(req ChatRequest) (ChatRequest, error) {
-	// First, perform basic validation using utilities
+// validateAndNormalizeChatRequest validates and normalizes a chat request,
+// returning warnings for the parameters it clamped or dropped
+func (c *client) validateAndNormalizeChatRequest(req ChatRequest) (ChatRequest, []Warning, error) {
+	// Fill unset fields from the selected request profile
+	req, err := c.applyChatProfile(req)
+	if err != nil {
+		return req, nil, err
+	}
+
+	// Perform basic validation using utilities
 	if err := utils.ValidateChatRequest(req); err != nil {
-		return req, err
+		return req, nil, err
 	}
 
 	// Validate conversation structure (provider-specific logic)
 	if err := c.validateConversationStructure(req.Messages); err != nil {
-		return req, fmt.Errorf("invalid conversation structure: %w", err)
+		return req, nil, fmt.Errorf("invalid conversation structure: %w", err)
+	}
+	if err := utils.ValidateReasoningBudget(req.ReasoningBudget, c.provider); err != nil {
+		return req, nil, err
+	}
+
+	// Reject or report values beyond the provider limits before clamping
+	outOfRange := utils.FindOutOfRangeChat(req, c.provider)
+	if err := utils.ApplyValidationMode(c.config.ValidationMode, c.config.OnOutOfRangeParameter, outOfRange); err != nil {
+		return req, nil, err
 	}
 
-	// Create a copy to avoid modifying the original request
-	normalized := req
+	// Handle parameters the provider does not support according to the configured policy
+	unsupported := c.unsupportedChat(req)
+	if err := c.applyUnsupportedParameterPolicy(unsupported); err != nil {
+		return req, nil, err
+	}
+	req = c.dropUnsupportedChat(req)
 
 	// Apply parameter clamping for the target provider
-	clamped := utils.ClampParameters(normalized, c.provider).(ChatRequest)
+	clamped := utils.ClampChat(req, c.provider)
 
 	// Apply default values from config if not specified in request
 	if clamped.Temperature == nil && c.config.Temperature != nil {
@@ -484,7 +1003,178 @@ func (c *client) validateAndNormalizeChatRequest(req ChatRequest) (ChatRequest,
 		}
 	}
 
-	return clamped, nil
+	// Apply the default model from config if the request does not select one
+	if clamped.Model == "" {
+		clamped.Model = c.config.Model
+	}
+
+	// Convert word and character limits into an approximate token limit
+	clamped.MaxTokens = lengthTokenLimit(clamped.MaxTokens, clamped.MaxWords, clamped.MaxChars, utils.GetProviderTokenLimit(c.provider))
+
+	model, downgraded := c.downgradeModel(clamped.Model)
+	clamped.Model = model
+
+	// Handle parameters, including profile and config defaults, that need a
+	// feature the adapter lacks according to the same policy
+	missing := c.missingParameterFeatures(
+		parameterFeature{"temperature", FeatureTemperature, clamped.Temperature != nil},
+		parameterFeature{"max_tokens", FeatureMaxTokens, clamped.MaxTokens != nil},
+		parameterFeature{"reasoning_budget", FeatureReasoning, clamped.ReasoningBudget != nil},
+	)
+	if err := c.applyUnsupportedParameterPolicy(missing); err != nil {
+		return req, nil, err
+	}
+	for _, param := range missing {
+		switch param.Parameter {
+		case "temperature":
+			clamped.Temperature = nil
+		case "max_tokens":
+			clamped.MaxTokens = nil
+		case "reasoning_budget":
+			clamped.ReasoningBudget = nil
+		}
+	}
+	unsupported = append(unsupported, missing...)
+
+	warnings := append(adjustmentWarnings(outOfRange, unsupported), downgraded...)
+	warnings = append(warnings, c.deprecationWarnings(clamped.Model)...)
+	return clamped, warnings, nil
+}
+
+// applyUnsupportedParameterPolicy reports or rejects the request parameters
+// the provider does not support; unless it returns an error, the caller drops
+// them from the request
+func (c *client) applyUnsupportedParameterPolicy(unsupported []UnsupportedParameter) error {
+	if len(unsupported) == 0 {
+		return nil
+	}
+
+	switch c.config.UnsupportedParameterPolicy {
+	case UnsupportedParameterError:
+		return unsupported[0]
+	case UnsupportedParameterWarn:
+		if c.config.OnUnsupportedParameter != nil {
+			for _, param := range unsupported {
+				c.config.OnUnsupportedParameter(param)
+			}
+		}
+	}
+	return nil
+}
+
+// unsupportedCompletion returns the parameters of a completion request the
+// provider does not support: those known per provider, and grammars unless
+// the adapter supports FeatureGrammar
+func (c *client) unsupportedCompletion(req CompletionRequest) []UnsupportedParameter {
+	return c.appendUnsupportedGrammar(utils.FindUnsupportedCompletion(req, c.provider), req.Grammar)
+}
+
+// unsupportedChat returns the parameters of a chat request the provider does
+// not support, like unsupportedCompletion
+func (c *client) unsupportedChat(req ChatRequest) []UnsupportedParameter {
+	return c.appendUnsupportedGrammar(utils.FindUnsupportedChat(req, c.provider), req.Grammar)
+}
+
+// dropUnsupportedCompletion returns a copy of the request without the
+// parameters reported by unsupportedCompletion
+func (c *client) dropUnsupportedCompletion(req CompletionRequest) CompletionRequest {
+	req = utils.DropUnsupportedCompletion(req, c.provider)
+	if !c.supportsGrammar() {
+		req.Grammar = nil
+	}
+	return req
+}
+
+// dropUnsupportedChat returns a copy of the request without the parameters
+// reported by unsupportedChat
+func (c *client) dropUnsupportedChat(req ChatRequest) ChatRequest {
+	req = utils.DropUnsupportedChat(req, c.provider)
+	if !c.supportsGrammar() {
+		req.Grammar = nil
+	}
+	return req
+}
+
+// appendUnsupportedGrammar adds the grammar to the unsupported parameters if
+// the request has one the adapter cannot enforce
+func (c *client) appendUnsupportedGrammar(unsupported []UnsupportedParameter, grammar *Grammar) []UnsupportedParameter {
+	if grammar == nil || c.supportsGrammar() {
+		return unsupported
+	}
+	return append(unsupported, UnsupportedParameter{
+		Provider:  c.provider,
+		Parameter: "grammar",
+		Reason:    fmt.Sprintf("grammar-constrained generation is not supported by %s", c.provider),
+	})
+}
+
+// supportsGrammar reports whether the adapter enforces grammars; requests
+// mapped without an adapter, as by ExplainChatMapping, have none
+func (c *client) supportsGrammar() bool {
+	return c.adapter != nil && c.SupportsFeature(FeatureGrammar)
+}
+
+// adjustmentWarnings describes the out-of-range parameters clamped and the
+// unsupported parameters dropped from a request. Extra stop sequences are
+// reported once, as truncated.
+func adjustmentWarnings(outOfRange []OutOfRangeParameter, unsupported []UnsupportedParameter) []Warning {
+	var warnings []Warning
+	reported := make(map[string]bool)
+	for _, param := range outOfRange {
+		warning := Warning{
+			Code:      WarningClamped,
+			Parameter: param.Parameter,
+			Message:   fmt.Sprintf("%s %g clamped to the %s maximum of %g", param.Parameter, param.Value, param.Provider, param.Limit),
+		}
+		if param.Parameter == "stop" {
+			warning.Code = WarningTruncated
+			warning.Message = fmt.Sprintf("%g stop sequences truncated to the %s maximum of %g", param.Value, param.Provider, param.Limit)
+		}
+		warnings = append(warnings, warning)
+		reported[param.Parameter] = true
+	}
+	for _, param := range unsupported {
+		if reported[param.Parameter] {
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Code:      WarningDropped,
+			Parameter: param.Parameter,
+			Message:   fmt.Sprintf("%s dropped: %s", param.Parameter, param.Reason),
+		})
+		reported[param.Parameter] = true
+	}
+	return warnings
+}
+
+// deprecationWarnings reports a request for a model with a known deprecation
+// to Config.OnDeprecatedModel and returns a warning for it
+func (c *client) deprecationWarnings(model string) []Warning {
+	if model == "" {
+		return nil
+	}
+	deprecation, ok := models.Default().Deprecation(c.provider, model)
+	if !ok {
+		return nil
+	}
+	if c.config.OnDeprecatedModel != nil {
+		c.config.OnDeprecatedModel(deprecation)
+	}
+	return []Warning{{
+		Code:      WarningDeprecated,
+		Parameter: "model",
+		Message:   deprecation.String(),
+	}}
+}
+
+// reportWarnings passes each warning to Config.OnWarning
+func (c *client) reportWarnings(warnings []Warning) {
+	if c.config.OnWarning == nil {
+		return
+	}
+	for _, warning := range warnings {
+		c.config.OnWarning(warning)
+	}
 }
 
 // validateConversationStructure validates the structure of a conversation
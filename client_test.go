@@ -2,6 +2,10 @@ package aiprovider
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -324,7 +328,7 @@ func TestRequestValidationAndNormalization(t *testing.T) {
 
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				normalized, err := internalClient.validateAndNormalizeCompletionRequest(tt.request)
+				normalized, _, err := internalClient.validateAndNormalizeCompletionRequest(tt.request)
 				if tt.wantErr {
 					if err == nil {
 						t.Errorf("Expected error, got nil")
@@ -418,7 +422,7 @@ func TestRequestValidationAndNormalization(t *testing.T) {
 
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				normalized, err := internalClient.validateAndNormalizeChatRequest(tt.request)
+				normalized, _, err := internalClient.validateAndNormalizeChatRequest(tt.request)
 				if tt.wantErr {
 					if err == nil {
 						t.Errorf("Expected error, got nil")
@@ -499,7 +503,7 @@ func TestParameterClamping(t *testing.T) {
 			}
 
 			internalClient := clientInstance.(*client)
-			normalized, err := internalClient.validateAndNormalizeCompletionRequest(tt.request)
+			normalized, _, err := internalClient.validateAndNormalizeCompletionRequest(tt.request)
 			if err != nil {
 				t.Fatalf("Expected successful normalization, got error: %v", err)
 			}
@@ -717,4 +721,656 @@ func TestConversationStructureValidation(t *testing.T) {
 	}
 }
 
+// Test rate limit status tracking across responses
+func TestRateLimitStatus(t *testing.T) {
+	now := time.Now()
+	adapter := &mockAdapter{
+		completeResp: &CompletionResponse{
+			Text: "ok",
+			Metadata: ResponseMetadata{
+				RateLimit: &RateLimitStatus{
+					RequestsLimit:     100,
+					RequestsRemaining: 42,
+					ObservedAt:        now,
+				},
+			},
+		},
+	}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	if status := c.RateLimitStatus(); status != nil {
+		t.Errorf("Expected nil status before any request, got %+v", status)
+	}
+
+	if _, err := c.Complete(context.Background(), CompletionRequest{Prompt: "Hello"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	status := c.RateLimitStatus()
+	if status == nil {
+		t.Fatalf("Expected rate limit status after request")
+	}
+	if status.RequestsRemaining != 42 {
+		t.Errorf("Expected 42 remaining requests, got %d", status.RequestsRemaining)
+	}
+
+	// Returned status must be a copy
+	status.RequestsRemaining = 0
+	if c.RateLimitStatus().RequestsRemaining != 42 {
+		t.Errorf("Expected RateLimitStatus to return a copy")
+	}
+
+	// Older observations must not overwrite newer ones
+	adapter.completeResp.Metadata.RateLimit = &RateLimitStatus{
+		RequestsRemaining: 7,
+		ObservedAt:        now.Add(-time.Minute),
+	}
+	if _, err := c.Complete(context.Background(), CompletionRequest{Prompt: "Hello"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c.RateLimitStatus().RequestsRemaining != 42 {
+		t.Errorf("Expected stale observation to be ignored")
+	}
+}
+
+// Test usage records are emitted to the configured recorder
+func TestUsageRecorder(t *testing.T) {
+	recorder := &recordingUsageRecorder{}
+	adapter := &mockAdapter{
+		chatResp: &ChatResponse{
+			Message:  Message{Role: "assistant", Content: "Hi"},
+			Usage:    Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+			Metadata: ResponseMetadata{Model: "claude-3-haiku-20240307"},
+		},
+	}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.UsageRecorder = recorder
+
+	_, err := c.ChatComplete(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(recorder.records) != 1 {
+		t.Fatalf("Expected 1 usage record, got %d", len(recorder.records))
+	}
+	record := recorder.records[0]
+	if record.Provider != ProviderAnthropic || record.Model != "claude-3-haiku-20240307" {
+		t.Errorf("Unexpected record identity: %+v", record)
+	}
+	if record.Usage.TotalTokens != 5 {
+		t.Errorf("Expected 5 total tokens, got %d", record.Usage.TotalTokens)
+	}
+
+	// Failed requests are not recorded
+	adapter.err = NewError(ErrorTypeProvider, "anthropic", "overloaded")
+	c.ChatComplete(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+	})
+	if len(recorder.records) != 1 {
+		t.Errorf("Expected failed request not to be recorded")
+	}
+}
+
+// Test request tags are recorded with usage and returned in the metadata
+func TestUsageRecorder_Tags(t *testing.T) {
+	recorder := &recordingUsageRecorder{}
+	adapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Hi"}}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.UsageRecorder = recorder
+
+	tags := map[string]string{"team": "search", "customer": "acme"}
+	resp, err := c.ChatComplete(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+		Tags:     tags,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Metadata.Tags["team"] != "search" {
+		t.Errorf("Expected tags in the response metadata, got %v", resp.Metadata.Tags)
+	}
+	if len(recorder.records) != 1 || recorder.records[0].Tags["customer"] != "acme" {
+		t.Errorf("Expected tags in the usage record, got %+v", recorder.records)
+	}
+
+	_, err = c.ChatComplete(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hello"}},
+		Tags:     map[string]string{" ": "x"},
+	})
+	if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+		t.Errorf("Expected a validation error for an empty tag key, got %v", err)
+	}
+}
+
+// Test usage records are priced with the client's pricing overrides
+func TestUsageRecorderPricing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	content := `{"openai": {"gpt-4o": {"input_per_1k": 1, "output_per_1k": 2}}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write pricing file: %v", err)
+	}
+
+	recorder := &recordingUsageRecorder{}
+	instance, err := NewClient(ProviderOpenAI, Config{
+		APIKey:        "sk-1234567890abcdef1234567890abcdef",
+		UsageRecorder: recorder,
+		PricingFile:   path,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	c := instance.(*client)
+	c.adapter = &mockAdapter{
+		completeResp: &CompletionResponse{
+			Usage:    Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+			Metadata: ResponseMetadata{Model: "gpt-4o-2024-08-06"},
+		},
+	}
+
+	if _, err := c.Complete(context.Background(), CompletionRequest{Prompt: "Hello"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recorder.records) != 1 || recorder.records[0].Cost != 2 {
+		t.Errorf("Expected a record costing 2 USD, got %+v", recorder.records)
+	}
+
+	// Invalid pricing files are rejected at construction
+	_, err = NewClient(ProviderOpenAI, Config{
+		APIKey:      "sk-1234567890abcdef1234567890abcdef",
+		PricingFile: filepath.Join(t.TempDir(), "missing.json"),
+	})
+	if err == nil {
+		t.Errorf("Expected error for missing pricing file")
+	}
+}
+
+// Test successful requests are persisted to the configured store
+func TestInteractionStore(t *testing.T) {
+	store := &recordingStore{}
+	adapter := &mockAdapter{
+		completeResp: &CompletionResponse{
+			Text:         "Paris",
+			Usage:        Usage{PromptTokens: 6, CompletionTokens: 1, TotalTokens: 7},
+			FinishReason: "stop",
+			Metadata:     ResponseMetadata{Model: "gpt-3.5-turbo-instruct"},
+		},
+	}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Store = store
+
+	var storeErrors []error
+	c.config.OnStoreError = func(err error) { storeErrors = append(storeErrors, err) }
+
+	if _, err := c.Complete(context.Background(), CompletionRequest{Prompt: "Capital of France?"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(store.records) != 1 {
+		t.Fatalf("Expected 1 stored interaction, got %d", len(store.records))
+	}
+	record := store.records[0]
+	if record.Provider != ProviderOpenAI || record.Model != "gpt-3.5-turbo-instruct" {
+		t.Errorf("Unexpected record identity: %+v", record)
+	}
+	if record.Prompt != "Capital of France?" || record.Response != "Paris" || record.FinishReason != "stop" {
+		t.Errorf("Unexpected record content: %+v", record)
+	}
+	if record.Timestamp.IsZero() {
+		t.Error("Expected record timestamp to be set")
+	}
+
+	// Store failures are reported but do not fail the request
+	store.err = fmt.Errorf("database locked")
+	if _, err := c.Complete(context.Background(), CompletionRequest{Prompt: "Again"}); err != nil {
+		t.Fatalf("Expected store failure not to fail the request, got %v", err)
+	}
+	if len(storeErrors) != 1 {
+		t.Errorf("Expected 1 store error, got %d", len(storeErrors))
+	}
+}
+
+type recordingStore struct {
+	records []InteractionRecord
+	err     error
+}
+
+func (s *recordingStore) SaveInteraction(ctx context.Context, record InteractionRecord) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, record)
+	return nil
+}
+
+type recordingUsageRecorder struct {
+	records []UsageRecord
+}
+
+func (r *recordingUsageRecorder) RecordUsage(record UsageRecord) {
+	r.records = append(r.records, record)
+}
+
+// mockAdapter is a ProviderAdapter returning canned responses for client tests
+type mockAdapter struct {
+	completeResp *CompletionResponse
+	chatResp     *ChatResponse
+	err          error
+
+	completeRequests []CompletionRequest
+	chatRequests     []ChatRequest
+}
+
+func (m *mockAdapter) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	m.completeRequests = append(m.completeRequests, req)
+	if m.err != nil {
+		return nil, m.err
+	}
+	resp := *m.completeResp
+	return &resp, nil
+}
+
+func (m *mockAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	m.chatRequests = append(m.chatRequests, req)
+	if m.err != nil {
+		return nil, m.err
+	}
+	resp := *m.chatResp
+	return &resp, nil
+}
+
+func (m *mockAdapter) ValidateConfig(config Config) error { return nil }
+
+func (m *mockAdapter) Name() string { return "mock" }
+
+func (m *mockAdapter) SupportedFeatures() []string {
+	return []string{
+		FeatureCompletion,
+		FeatureChatCompletion,
+		FeatureTemperature,
+		FeatureMaxTokens,
+		FeatureStopSequences,
+		FeatureSystemMessages,
+	}
+}
+
+// newMockClient creates a client backed by the given adapter
+func newMockClient(provider ProviderType, adapter ProviderAdapter) *client {
+	return &client{
+		adapter:  adapter,
+		provider: provider,
+		features: adapter.SupportedFeatures(),
+	}
+}
+
 // Helper functions are in test_utils.go
+
+func TestUnsupportedParameterPolicy(t *testing.T) {
+	req := CompletionRequest{Prompt: "Hello", Stop: []string{"a", "b", "c", "d", "e"}}
+
+	tests := []struct {
+		name          string
+		policy        UnsupportedParameterPolicy
+		expectError   bool
+		expectWarning bool
+	}{
+		{name: "default drops", policy: ""},
+		{name: "drop", policy: UnsupportedParameterDrop},
+		{name: "warn", policy: UnsupportedParameterWarn, expectWarning: true},
+		{name: "error", policy: UnsupportedParameterError, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &mockAdapter{completeResp: &CompletionResponse{Text: "Hi"}}
+			c := newMockClient(ProviderOpenAI, adapter)
+
+			var warnings []UnsupportedParameter
+			c.config.UnsupportedParameterPolicy = tt.policy
+			c.config.OnUnsupportedParameter = func(p UnsupportedParameter) {
+				warnings = append(warnings, p)
+			}
+
+			_, err := c.Complete(context.Background(), req)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected validation error")
+				}
+				if aiErr, ok := err.(*Error); !ok || aiErr.Type != ErrorTypeValidation {
+					t.Errorf("Expected validation error, got %v", err)
+				}
+				if !contains(err.Error(), `parameter "stop" is not supported`) {
+					t.Errorf("Expected error to name the parameter, got %v", err)
+				}
+				if len(adapter.completeRequests) != 0 {
+					t.Error("Expected request not to be sent")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if sent := adapter.completeRequests[0].Stop; len(sent) != 4 {
+				t.Errorf("Expected stop sequences truncated to 4, got %v", sent)
+			}
+			if tt.expectWarning != (len(warnings) == 1) {
+				t.Errorf("Expected warning %v, got %v", tt.expectWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestValidationMode(t *testing.T) {
+	req := CompletionRequest{Prompt: "Hello", Temperature: floatPtr(3.0), MaxTokens: intPtr(10000)}
+
+	tests := []struct {
+		name          string
+		mode          ValidationMode
+		expectError   bool
+		expectWarning bool
+	}{
+		{name: "default clamps", mode: ""},
+		{name: "clamp", mode: ValidationModeClamp},
+		{name: "warn", mode: ValidationModeWarn, expectWarning: true},
+		{name: "strict", mode: ValidationModeStrict, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &mockAdapter{completeResp: &CompletionResponse{Text: "Hi"}}
+			c := newMockClient(ProviderOpenAI, adapter)
+
+			var warnings []OutOfRangeParameter
+			c.config.ValidationMode = tt.mode
+			c.config.OnOutOfRangeParameter = func(p OutOfRangeParameter) {
+				warnings = append(warnings, p)
+			}
+
+			_, err := c.Complete(context.Background(), req)
+			if tt.expectError {
+				if aiErr, ok := err.(*Error); !ok || aiErr.Type != ErrorTypeValidation {
+					t.Fatalf("Expected validation error, got %v", err)
+				}
+				if !contains(err.Error(), `parameter "temperature" is out of range`) {
+					t.Errorf("Expected error to name the parameter, got %v", err)
+				}
+				if len(adapter.completeRequests) != 0 {
+					t.Error("Expected request not to be sent")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			sent := adapter.completeRequests[0]
+			if *sent.Temperature != 2.0 || *sent.MaxTokens != 4096 {
+				t.Errorf("Expected clamped values, got temperature %v and max tokens %v", *sent.Temperature, *sent.MaxTokens)
+			}
+			if tt.expectWarning != (len(warnings) == 2) {
+				t.Errorf("Expected warnings %v, got %v", tt.expectWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestResponseWarnings(t *testing.T) {
+	adapter := &mockAdapter{completeResp: &CompletionResponse{Text: "Hi"}}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	var reported []Warning
+	c.config.OnWarning = func(w Warning) {
+		reported = append(reported, w)
+	}
+
+	resp, err := c.Complete(context.Background(), CompletionRequest{
+		Prompt:      "Hello",
+		Temperature: floatPtr(3.0),
+		Stop:        []string{"a", "b", "c", "d", "e"},
+		Stream:      true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []Warning{
+		{Code: WarningClamped, Parameter: "temperature"},
+		{Code: WarningTruncated, Parameter: "stop"},
+		{Code: WarningDropped, Parameter: "stream"},
+	}
+	warnings := resp.Metadata.Warnings
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i, w := range expected {
+		if warnings[i].Code != w.Code || warnings[i].Parameter != w.Parameter || warnings[i].Message == "" {
+			t.Errorf("Warning %d: expected %s %s, got %+v", i, w.Code, w.Parameter, warnings[i])
+		}
+	}
+	if len(reported) != len(warnings) {
+		t.Errorf("Expected OnWarning to be called %d times, got %d", len(warnings), len(reported))
+	}
+
+	// Requests sent unchanged carry no warnings
+	resp, err = c.Complete(context.Background(), CompletionRequest{Prompt: "Hello"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resp.Metadata.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", resp.Metadata.Warnings)
+	}
+}
+
+func TestDeprecatedModelWarning(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Hi"}}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	var deprecations []ModelDeprecation
+	c.config.OnDeprecatedModel = func(d ModelDeprecation) {
+		deprecations = append(deprecations, d)
+	}
+
+	req := ChatRequest{Model: "claude-2.1", Messages: []Message{{Role: "user", Content: "Hello"}}}
+	resp, err := c.ChatComplete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(deprecations) != 1 || deprecations[0].Model != "claude-2.1" || deprecations[0].Replacement == "" {
+		t.Fatalf("Expected one deprecation with a replacement, got %+v", deprecations)
+	}
+	if len(resp.Metadata.Warnings) != 1 || resp.Metadata.Warnings[0].Code != WarningDeprecated {
+		t.Errorf("Expected a deprecation warning, got %+v", resp.Metadata.Warnings)
+	}
+	if len(adapter.chatRequests) != 1 {
+		t.Error("Expected the request to be sent")
+	}
+
+	req.Model = "claude-3-haiku-20240307"
+	resp, _ = c.ChatComplete(context.Background(), req)
+	if len(deprecations) != 1 || len(resp.Metadata.Warnings) != 0 {
+		t.Errorf("Expected no warning for a current model, got %+v", resp.Metadata.Warnings)
+	}
+}
+
+// limitedAdapter advertises only text completion
+type limitedAdapter struct {
+	mockAdapter
+}
+
+func (m *limitedAdapter) SupportedFeatures() []string {
+	return []string{FeatureCompletion, FeatureMaxTokens}
+}
+
+func TestFeatureGuards(t *testing.T) {
+	adapter := &limitedAdapter{mockAdapter{completeResp: &CompletionResponse{Text: "Hi"}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	if !c.SupportsFeature(FeatureCompletion) || c.SupportsFeature(FeatureChatCompletion) {
+		t.Error("Expected SupportsFeature to reflect the adapter's features")
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		feature string
+	}{
+		{
+			name: "supported completion",
+			call: func() error {
+				_, err := c.Complete(context.Background(), CompletionRequest{Prompt: "Hi", MaxTokens: intPtr(10)})
+				return err
+			},
+		},
+		{
+			name: "unsupported chat",
+			call: func() error {
+				_, err := c.ChatComplete(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+				return err
+			},
+			feature: FeatureChatCompletion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if tt.feature == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			aiErr, ok := err.(*Error)
+			if !ok || aiErr.Type != ErrorTypeValidation {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+			expected := fmt.Sprintf("feature %q not supported by provider openai", tt.feature)
+			if aiErr.Message != expected {
+				t.Errorf("Expected message %q, got %q", expected, aiErr.Message)
+			}
+		})
+	}
+
+	if len(adapter.chatRequests) != 0 {
+		t.Error("Expected unsupported requests not to reach the adapter")
+	}
+}
+
+func TestFeatureGuards_Parameters(t *testing.T) {
+	adapter := &limitedAdapter{mockAdapter{completeResp: &CompletionResponse{Text: "Hi"}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Temperature = floatPtr(0.5)
+
+	// Parameters without a feature, including config defaults, are dropped
+	resp, err := c.Complete(context.Background(), CompletionRequest{Prompt: "Hi", Stop: []string{"."}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sent := adapter.completeRequests[0]
+	if sent.Stop != nil || sent.Temperature != nil {
+		t.Errorf("Expected stop and temperature to be dropped, got %+v", sent)
+	}
+	if len(resp.Metadata.Warnings) != 2 {
+		t.Errorf("Expected a warning per dropped parameter, got %+v", resp.Metadata.Warnings)
+	}
+
+	// The error policy rejects them before the adapter is called
+	c.config.UnsupportedParameterPolicy = UnsupportedParameterError
+	_, err = c.Complete(context.Background(), CompletionRequest{Prompt: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), `parameter "temperature" is not supported`) {
+		t.Errorf("Expected the config temperature to be rejected, got %v", err)
+	}
+	if len(adapter.completeRequests) != 1 {
+		t.Error("Expected the rejected request not to reach the adapter")
+	}
+}
+
+func TestClient_DefaultModel(t *testing.T) {
+	adapter := &mockAdapter{
+		completeResp: &CompletionResponse{Text: "ok"},
+		chatResp:     &ChatResponse{Message: Message{Role: "assistant", Content: "ok"}},
+	}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.Model = "claude-3-haiku-20240307"
+
+	ctx := context.Background()
+	if _, err := c.Complete(ctx, CompletionRequest{Prompt: "Hi"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.ChatComplete(ctx, ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.ChatComplete(ctx, ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}, Model: "claude-3-opus-20240229"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := adapter.completeRequests[0].Model; got != "claude-3-haiku-20240307" {
+		t.Errorf("Expected config model for completion, got %q", got)
+	}
+	if got := adapter.chatRequests[0].Model; got != "claude-3-haiku-20240307" {
+		t.Errorf("Expected config model for chat, got %q", got)
+	}
+	if got := adapter.chatRequests[1].Model; got != "claude-3-opus-20240229" {
+		t.Errorf("Expected request model to take precedence, got %q", got)
+	}
+}
+
+// staticAdapter returns fixed responses without recording requests, so
+// benchmarks measure only the client's own allocations
+type staticAdapter struct {
+	mockAdapter
+}
+
+func (s *staticAdapter) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	resp := *s.completeResp
+	return &resp, nil
+}
+
+func (s *staticAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp := *s.chatResp
+	return &resp, nil
+}
+
+func BenchmarkClient_Complete(b *testing.B) {
+	adapter := &staticAdapter{mockAdapter{completeResp: &CompletionResponse{Text: "positive", FinishReason: "stop"}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	temperature := 0.0
+	maxTokens := 5
+	req := CompletionRequest{Prompt: "Classify the sentiment: I love it", Temperature: &temperature, MaxTokens: &maxTokens}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Complete(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkClient_ChatComplete(b *testing.B) {
+	adapter := &staticAdapter{mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "positive"}, FinishReason: "stop"}}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	temperature := 0.0
+	maxTokens := 5
+	req := ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "Answer with positive, negative or neutral."},
+			{Role: "user", Content: "I love it"},
+		},
+		Temperature: &temperature,
+		MaxTokens:   &maxTokens,
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.ChatComplete(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/promptlint"
+)
+
+// lintResult is the lint output for one file in JSON format
+type lintResult struct {
+	File     string               `json:"file"`
+	Warnings []promptlint.Warning `json:"warnings"`
+}
+
+// runLint implements the lint subcommand
+func runLint(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	vars := flags.String("vars", "", "comma-separated template variables the prompts are rendered with; enables the variable checks")
+	contextWindow := flags.Int("context-window", 0, "context window in tokens to check prompt length against (default: no check)")
+	reserved := flags.Int("reserve", 0, "tokens of the context window to leave for the response")
+	disable := flags.String("disable", "", "comma-separated rules to skip")
+	format := flags.String("format", "text", "output format, text or json")
+	strict := flags.Bool("strict", false, "fail on warnings as well as errors")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("no prompt files given")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	opts := promptlint.Options{
+		ContextWindow:  *contextWindow,
+		ReservedTokens: *reserved,
+		Disable:        splitList(*disable),
+	}
+	if *vars != "" {
+		opts.Variables = splitList(*vars)
+	}
+
+	var results []lintResult
+	errors, warnings := 0, 0
+	for _, file := range flags.Args() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		result := lintResult{File: file, Warnings: promptlint.Lint(string(data), opts)}
+		for _, w := range result.Warnings {
+			if w.Severity == promptlint.SeverityError {
+				errors++
+			} else {
+				warnings++
+			}
+			if *format == "text" && w.Line > 0 {
+				fmt.Fprintf(out, "%s:%s\n", file, w)
+			} else if *format == "text" {
+				fmt.Fprintf(out, "%s: %s\n", file, w)
+			}
+		}
+		results = append(results, result)
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	}
+
+	if errors > 0 || (*strict && warnings > 0) {
+		return fmt.Errorf("%d errors, %d warnings", errors, warnings)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
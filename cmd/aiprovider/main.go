@@ -0,0 +1,160 @@
+// Command aiprovider runs ai-providers features from the command line.
+//
+// Usage:
+//
+//	aiprovider batch -provider anthropic -in prompts.csv -out responses.csv [flags]
+//	aiprovider lint [flags] prompt.txt...
+//	aiprovider replay [-mock] bundle.json
+//	aiprovider serve -provider anthropic -addr :8080
+//
+// The lint command needs no credentials; it exits with status 1 if any
+// prompt has errors, or any warnings with -strict, so it can run in CI.
+//
+// The replay command resends the request of a saved DebugBundle, to the
+// provider or, with -mock, to a local server answering with the recorded
+// responses, and exits with status 1 if the request fails again.
+//
+// The serve command runs the HTTP and WebSocket gateway of the gateway
+// package until interrupted; callers authenticate with the bearer tokens
+// listed in AI_GATEWAY_TOKENS.
+//
+// The client is configured from the environment, see LoadConfigFromEnv.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+	"github.com/ajeet-kumar1087/ai-providers/batch"
+)
+
+// usage describes the available subcommands
+const usage = `Usage: aiprovider <command> [flags]
+
+Commands:
+  batch   Send the prompts of a CSV or JSONL file and write the responses
+  lint    Check prompt files for common problems
+  replay  Resend the request of a saved debug bundle
+  serve   Serve chat over HTTP and WebSocket
+
+Run "aiprovider <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "batch":
+		err = runBatch(ctx, os.Args[2:])
+	case "lint":
+		err = runLint(os.Args[2:], os.Stdout)
+	case "replay":
+		err = runReplay(ctx, os.Args[2:], os.Stdout)
+	case "serve":
+		err = runServe(ctx, os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runBatch implements the batch subcommand
+func runBatch(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	provider := flags.String("provider", string(aiprovider.ProviderAnthropic), "provider to send requests to")
+	input := flags.String("in", "", "input CSV or JSONL file (required)")
+	output := flags.String("out", "", "output file, appended to on resume (required)")
+	format := flags.String("format", "", "file format, csv or jsonl (default: from the input extension)")
+	promptField := flags.String("prompt-field", batch.DefaultPromptField, "column or field holding the prompt")
+	idField := flags.String("id-field", batch.DefaultIDField, "column or field identifying each item")
+	checkpoint := flags.String("checkpoint", "", "checkpoint file (default: output file + "+batch.CheckpointSuffix+")")
+	model := flags.String("model", "", "model to use (default: the provider default)")
+	system := flags.String("system", "", "system message sent with every prompt")
+	maxTokens := flags.Int("max-tokens", 0, "maximum tokens per response (default: the provider default)")
+	temperature := flags.Float64("temperature", -1, "sampling temperature (default: the provider default)")
+	concurrency := flags.Int("concurrency", aiprovider.DefaultPoolConcurrency, "number of parallel requests")
+	retries := flags.Int("retries", aiprovider.DefaultPoolMaxRetries, "retries per item for retryable errors")
+	deferred := flags.Bool("deferred", false, "submit as one native provider batch when supported: cheaper, but may take up to a day")
+	batchID := flags.String("batch-id", "", "native batch to resume waiting for, printed by an interrupted -deferred run")
+	flags.Parse(args)
+
+	if *input == "" || *output == "" {
+		flags.Usage()
+		return fmt.Errorf("-in and -out are required")
+	}
+
+	client, err := aiprovider.NewClientWithEnvConfig(aiprovider.ProviderType(*provider))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	request := aiprovider.ChatRequest{Model: *model}
+	if *system != "" {
+		request.Messages = []aiprovider.Message{{Role: "system", Content: *system}}
+	}
+	if *maxTokens > 0 {
+		request.MaxTokens = maxTokens
+	}
+	if *temperature >= 0 {
+		request.Temperature = temperature
+	}
+	if *retries == 0 {
+		*retries = -1
+	}
+
+	report, err := batch.ProcessFile(ctx, client, batch.Options{
+		Input:       *input,
+		Output:      *output,
+		Format:      batch.Format(*format),
+		PromptField: *promptField,
+		IDField:     *idField,
+		Checkpoint:  *checkpoint,
+		Request:     request,
+		Deferred:    *deferred || *batchID != "",
+		BatchID:     *batchID,
+		Pool: aiprovider.PoolOptions{
+			Concurrency: *concurrency,
+			MaxRetries:  *retries,
+			OnProgress: func(p aiprovider.PoolProgress) {
+				fmt.Fprintf(os.Stderr, "\rprocessed %d (%d failed)", p.Completed, p.Failed)
+			},
+			OnResult: func(r aiprovider.JobResult) {
+				if r.Err != nil {
+					fmt.Fprintf(os.Stderr, "\ritem %s failed: %v\n", r.Job.ID, r.Err)
+				}
+			},
+		},
+	})
+	if report != nil {
+		if report.BatchID != "" {
+			fmt.Fprintf(os.Stderr, "\rnative batch %s\n", report.BatchID)
+		}
+		fmt.Fprintf(os.Stderr, "\rsucceeded %d, failed %d, skipped %d, %d tokens, $%.4f in %v\n",
+			report.Succeeded, report.Failed, report.Skipped, report.Usage.TotalTokens, report.Cost, report.Duration)
+		if err == nil && report.Failed > 0 {
+			err = fmt.Errorf("%d items failed, run again to retry them", report.Failed)
+		}
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+// replayKeys are placeholder API keys accepted by config validation, used
+// when replaying against recorded responses
+var replayKeys = map[aiprovider.ProviderType]string{
+	aiprovider.ProviderOpenAI:    "sk-replay-0000000000000000",
+	aiprovider.ProviderAnthropic: "sk-ant-REDACTED",
+	aiprovider.ProviderGoogle:    "replay-00000000000000000000",
+}
+
+// runReplay implements the replay subcommand
+func runReplay(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	mock := flags.Bool("mock", false, "answer with the recorded responses instead of calling the provider")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: aiprovider replay [-mock] bundle.json")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected one debug bundle file")
+	}
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var bundle aiprovider.DebugBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("failed to decode debug bundle: %w", err)
+	}
+
+	var client aiprovider.Client
+	if *mock {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		server := &http.Server{Handler: bundle.ReplayHandler()}
+		go server.Serve(listener)
+		defer server.Close()
+
+		client, err = aiprovider.NewClient(bundle.Provider, aiprovider.DefaultConfig().
+			WithAPIKey(replayKeys[bundle.Provider]).
+			WithBaseURL("http://"+listener.Addr().String()))
+		if err != nil {
+			return err
+		}
+	} else {
+		client, err = aiprovider.NewClientWithEnvConfig(bundle.Provider)
+		if err != nil {
+			return err
+		}
+	}
+	defer client.Close()
+
+	fmt.Fprintf(os.Stderr, "recorded error: %s\n", bundle.Error)
+	text, err := bundle.Replay(ctx, client)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, text)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+	"github.com/ajeet-kumar1087/ai-providers/gateway"
+)
+
+// runServe implements the serve subcommand
+func runServe(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	provider := flags.String("provider", string(aiprovider.ProviderAnthropic), "provider to send requests to")
+	addr := flags.String("addr", ":8080", "address to listen on")
+	pingInterval := flags.Duration("ping-interval", gateway.DefaultPingInterval, "how often WebSocket connections are pinged")
+	heartbeatInterval := flags.Duration("heartbeat-interval", gateway.DefaultHeartbeatInterval, "how long a stream may be silent before a heartbeat is sent (negative disables)")
+	origins := flags.String("allowed-origins", "", "comma-separated origins whose pages may open WebSockets (default: same origin only)")
+	insecure := flags.Bool("no-auth", false, "serve callers without checking bearer tokens")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: aiprovider serve [flags]")
+		fmt.Fprintln(flags.Output(), "\nCallers authenticate with one of the comma-separated bearer tokens in AI_GATEWAY_TOKENS.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var tokens []string
+	for _, token := range strings.Split(os.Getenv("AI_GATEWAY_TOKENS"), ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	var allowedOrigins []string
+	for _, origin := range strings.Split(*origins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowedOrigins = append(allowedOrigins, origin)
+		}
+	}
+	if len(tokens) == 0 && !*insecure {
+		return fmt.Errorf("set AI_GATEWAY_TOKENS, or pass -no-auth to serve unauthenticated callers")
+	}
+
+	client, err := aiprovider.NewClientWithEnvConfig(aiprovider.ProviderType(*provider))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	server := &http.Server{
+		Addr: *addr,
+		Handler: gateway.New(client, gateway.Options{
+			Tokens:               tokens,
+			AllowUnauthenticated: *insecure,
+			PingInterval:         *pingInterval,
+			HeartbeatInterval:    *heartbeatInterval,
+			AllowedOrigins:       allowedOrigins,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), aiprovider.DefaultShutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "serving %s on %s\n", *provider, *addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
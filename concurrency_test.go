@@ -0,0 +1,136 @@
+package aiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/usage"
+)
+
+// anthropicTestServer serves canned Anthropic messages responses, streamed
+// when the request asks for it
+func anthropicTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stream bool `json:"stream"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+
+		w.Header().Set("anthropic-ratelimit-requests-remaining", "99")
+		w.Header().Set("anthropic-ratelimit-requests-limit", "100")
+		if body.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"model\":\"claude-3-haiku-20240307\",\"usage\":{\"input_tokens\":5,\"output_tokens\":1}}}\n\n")
+			fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n")
+			fmt.Fprint(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":2}}\n\n")
+			fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":2}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// lockedStore is an InteractionStore counting saved records
+type lockedStore struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (s *lockedStore) SaveInteraction(ctx context.Context, record InteractionRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	return nil
+}
+
+// Run with -race: a single client must be safe for concurrent use
+func TestClient_ConcurrentUse(t *testing.T) {
+	server := anthropicTestServer(t)
+	tracker := usage.NewTracker()
+	store := &lockedStore{}
+
+	client, err := NewClient(ProviderAnthropic, Config{
+		APIKey:        "sk-ant-test-key-1234567890",
+		BaseURL:       server.URL,
+		UsageRecorder: tracker,
+		Store:         store,
+		Profiles:      map[string]RequestProfile{"terse": {SystemPrompt: "Be terse."}},
+		Experiments: []Experiment{
+			{Name: "tone", Variants: []ExperimentVariant{{Name: "friendly", Percent: 50, SystemPrompt: "Be friendly."}}},
+		},
+		PromptInjectionGuard: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	const workers = 8
+	const iterations = 10
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*iterations*3)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				userID := fmt.Sprintf("user-%d-%d", w, i)
+				messages := []Message{
+					{Role: "user", Content: "Hello", Untrusted: i%2 == 0},
+				}
+
+				if _, err := client.Complete(ctx, CompletionRequest{Prompt: "Hello", UserID: userID}); err != nil {
+					errs <- err
+				}
+				if _, err := client.ChatComplete(ctx, ChatRequest{Messages: messages, Profile: "terse", UserID: userID}); err != nil {
+					errs <- err
+				}
+
+				stream, err := client.StreamChat(ctx, ChatRequest{Messages: messages, UserID: userID})
+				if err != nil {
+					errs <- err
+					continue
+				}
+				if _, err := drain(stream); err != io.EOF {
+					errs <- err
+				}
+				stream.Close()
+
+				// Registration and state reads race with in-flight requests
+				if err := client.RegisterProfile(fmt.Sprintf("profile-%d", w), RequestProfile{Model: "claude-3-haiku-20240307"}); err != nil {
+					errs <- err
+				}
+				client.RateLimitStatus()
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	want := int64(workers * iterations * 3)
+	if total := tracker.Snapshot().Total(); total.Requests != want {
+		t.Errorf("Expected %d recorded requests, got %d", want, total.Requests)
+	}
+	if store.count != int(want) {
+		t.Errorf("Expected %d stored interactions, got %d", want, store.count)
+	}
+	if status := client.RateLimitStatus(); status == nil || status.RequestsRemaining != 99 {
+		t.Errorf("Expected the rate limit status to be recorded, got %+v", status)
+	}
+}
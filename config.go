@@ -15,6 +15,46 @@ var (
 	// LoadConfigFromEnv loads configuration from environment variables.
 	// Equivalent to types.LoadConfigFromEnv().
 	LoadConfigFromEnv = types.LoadConfigFromEnv
+
+	// WithProject returns a context selecting the OpenAI project requests are billed to.
+	// Equivalent to types.WithProject().
+	WithProject = types.WithProject
+
+	// ProjectFromContext returns the project selected with WithProject.
+	// Equivalent to types.ProjectFromContext().
+	ProjectFromContext = types.ProjectFromContext
+
+	// WithSession returns a context marking requests as turns of one session.
+	// Equivalent to types.WithSession().
+	WithSession = types.WithSession
+
+	// SessionFromContext returns the session selected with WithSession.
+	// Equivalent to types.SessionFromContext().
+	SessionFromContext = types.SessionFromContext
+
+	// WithCancelGroup returns a context adding requests to a cancellation group.
+	// Equivalent to types.WithCancelGroup().
+	WithCancelGroup = types.WithCancelGroup
+
+	// CancelGroupFromContext returns the group set with WithCancelGroup.
+	// Equivalent to types.CancelGroupFromContext().
+	CancelGroupFromContext = types.CancelGroupFromContext
+
+	// WithFlagSubject returns a context carrying the subject feature flags are evaluated for.
+	// Equivalent to types.WithFlagSubject().
+	WithFlagSubject = types.WithFlagSubject
+
+	// FlagSubjectFromContext returns the subject set with WithFlagSubject.
+	// Equivalent to types.FlagSubjectFromContext().
+	FlagSubjectFromContext = types.FlagSubjectFromContext
+
+	// WithEndpoint returns a context overriding the base URL and headers of requests.
+	// Equivalent to types.WithEndpoint().
+	WithEndpoint = types.WithEndpoint
+
+	// EndpointFromContext returns the endpoint selected with WithEndpoint.
+	// Equivalent to types.EndpointFromContext().
+	EndpointFromContext = types.EndpointFromContext
 )
 
 // NewClientWithEnvConfig creates a new client using configuration loaded from environment variables.
@@ -27,7 +67,8 @@ var (
 //   - OpenAI: OPENAI_API_KEY, OPENAI_BASE_URL
 //   - Anthropic: ANTHROPIC_API_KEY, ANTHROPIC_BASE_URL
 //   - Google: GOOGLE_API_KEY, GOOGLE_BASE_URL
-//   - Common: AI_TIMEOUT, AI_MAX_RETRIES, AI_TEMPERATURE, AI_MAX_TOKENS
+//   - Common: AI_TIMEOUT, AI_MAX_RETRIES, AI_MAX_RETRY_WAIT, AI_TEMPERATURE, AI_MAX_TOKENS,
+//     AI_PRICING_FILE, AI_UNSUPPORTED_PARAMETER_POLICY
 //
 // Example:
 //
@@ -2,6 +2,7 @@ package aiprovider
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -210,6 +211,55 @@ func TestConfigValidation(t *testing.T) {
 			provider: types.ProviderAnthropic,
 			wantErr:  false,
 		},
+		{
+			name: "valid unsupported parameter policy",
+			config: types.Config{
+				APIKey:                     "sk-1234567890abcdef1234567890abcdef",
+				UnsupportedParameterPolicy: types.UnsupportedParameterError,
+			},
+			provider: types.ProviderOpenAI,
+			wantErr:  false,
+		},
+		{
+			name: "invalid unsupported parameter policy",
+			config: types.Config{
+				APIKey:                     "sk-1234567890abcdef1234567890abcdef",
+				UnsupportedParameterPolicy: "ignore",
+			},
+			provider: types.ProviderOpenAI,
+			wantErr:  true,
+			errMsg:   "unsupported parameter policy must be one of",
+		},
+		{
+			name: "invalid error sanitization",
+			config: types.Config{
+				APIKey:            "sk-1234567890abcdef1234567890abcdef",
+				ErrorSanitization: "mask",
+			},
+			provider: types.ProviderOpenAI,
+			wantErr:  true,
+			errMsg:   "error sanitization must be one of",
+		},
+		{
+			name: "negative stream buffer size",
+			config: types.Config{
+				APIKey:           "sk-1234567890abcdef1234567890abcdef",
+				StreamBufferSize: -1,
+			},
+			provider: types.ProviderOpenAI,
+			wantErr:  true,
+			errMsg:   "stream buffer size must be non-negative",
+		},
+		{
+			name: "negative request compression threshold",
+			config: types.Config{
+				APIKey:                      "sk-1234567890abcdef1234567890abcdef",
+				RequestCompressionThreshold: -1,
+			},
+			provider: types.ProviderOpenAI,
+			wantErr:  true,
+			errMsg:   "request compression threshold must be non-negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -270,6 +320,11 @@ func TestLoadConfigFromEnv(t *testing.T) {
 		"ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL",
 		"GOOGLE_API_KEY", "GOOGLE_BASE_URL",
 		"AI_TIMEOUT", "AI_MAX_RETRIES", "AI_TEMPERATURE", "AI_MAX_TOKENS",
+		"AI_PRICING_FILE", "AI_UNSUPPORTED_PARAMETER_POLICY", "AI_MAX_RETRY_WAIT",
+		"AI_PROMPT_INJECTION_GUARD", "OPENAI_MODEL", "ANTHROPIC_MODEL", "GOOGLE_MODEL", "AI_MODEL",
+		"AI_STREAM_IDLE_TIMEOUT", "AI_STREAM_STALL_RETRIES", "AI_STREAM_BUFFER_SIZE",
+		"AI_REQUEST_COMPRESSION_THRESHOLD",
+		"AI_DEBUG_PAYLOADS", "AI_DEBUG_REDACT_FIELDS", "AI_ERROR_SANITIZATION",
 	}
 
 	for _, key := range envVars {
@@ -300,18 +355,45 @@ func TestLoadConfigFromEnv(t *testing.T) {
 			envVars: map[string]string{
 				"OPENAI_API_KEY":  "sk-test123",
 				"OPENAI_BASE_URL": "https://api.openai.com/v1",
+				"OPENAI_MODEL":    "gpt-4o-mini",
+				"AI_MODEL":        "ignored-fallback",
 				"AI_TIMEOUT":      "45s",
 				"AI_MAX_RETRIES":  "5",
 				"AI_TEMPERATURE":  "0.8",
 				"AI_MAX_TOKENS":   "2000",
+				"AI_PRICING_FILE": "/etc/ai/prices.json",
+
+				"AI_UNSUPPORTED_PARAMETER_POLICY":  "WARN",
+				"AI_MAX_RETRY_WAIT":                "20s",
+				"AI_PROMPT_INJECTION_GUARD":        "true",
+				"AI_STREAM_IDLE_TIMEOUT":           "15s",
+				"AI_STREAM_STALL_RETRIES":          "2",
+				"AI_STREAM_BUFFER_SIZE":            "32",
+				"AI_REQUEST_COMPRESSION_THRESHOLD": "65536",
+				"AI_DEBUG_PAYLOADS":                "true",
+				"AI_DEBUG_REDACT_FIELDS":           "content, prompt",
+				"AI_ERROR_SANITIZATION":            "Hash",
 			},
 			expected: types.Config{
 				APIKey:      "sk-test123",
 				BaseURL:     "https://api.openai.com/v1",
+				Model:       "gpt-4o-mini",
 				Timeout:     45 * time.Second,
 				MaxRetries:  5,
 				Temperature: floatPtr(0.8),
 				MaxTokens:   intPtr(2000),
+				PricingFile: "/etc/ai/prices.json",
+
+				UnsupportedParameterPolicy:  types.UnsupportedParameterWarn,
+				MaxRetryWait:                20 * time.Second,
+				PromptInjectionGuard:        true,
+				StreamIdleTimeout:           15 * time.Second,
+				StreamStallRetries:          2,
+				StreamBufferSize:            32,
+				RequestCompressionThreshold: 65536,
+				DebugPayloads:               true,
+				DebugRedactFields:           []string{"content", "prompt"},
+				ErrorSanitization:           types.ErrorSanitizationHash,
 			},
 		},
 		{
@@ -320,10 +402,12 @@ func TestLoadConfigFromEnv(t *testing.T) {
 			envVars: map[string]string{
 				"ANTHROPIC_API_KEY":  "sk-ant-test123",
 				"ANTHROPIC_BASE_URL": "https://api.anthropic.com",
+				"AI_MODEL":           "claude-3-haiku-20240307",
 			},
 			expected: types.Config{
 				APIKey:     "sk-ant-test123",
 				BaseURL:    "https://api.anthropic.com",
+				Model:      "claude-3-haiku-20240307",
 				Timeout:    30 * time.Second, // Default
 				MaxRetries: 3,                // Default
 			},
@@ -385,6 +469,9 @@ func TestLoadConfigFromEnv(t *testing.T) {
 			if config.BaseURL != tt.expected.BaseURL {
 				t.Errorf("BaseURL = %q, want %q", config.BaseURL, tt.expected.BaseURL)
 			}
+			if config.Model != tt.expected.Model {
+				t.Errorf("Model = %q, want %q", config.Model, tt.expected.Model)
+			}
 			if config.Timeout != tt.expected.Timeout {
 				t.Errorf("Timeout = %v, want %v", config.Timeout, tt.expected.Timeout)
 			}
@@ -397,6 +484,39 @@ func TestLoadConfigFromEnv(t *testing.T) {
 			if !equalIntPtr(config.MaxTokens, tt.expected.MaxTokens) {
 				t.Errorf("MaxTokens = %v, want %v", config.MaxTokens, tt.expected.MaxTokens)
 			}
+			if config.PricingFile != tt.expected.PricingFile {
+				t.Errorf("PricingFile = %q, want %q", config.PricingFile, tt.expected.PricingFile)
+			}
+			if config.MaxRetryWait != tt.expected.MaxRetryWait {
+				t.Errorf("MaxRetryWait = %v, want %v", config.MaxRetryWait, tt.expected.MaxRetryWait)
+			}
+			if config.UnsupportedParameterPolicy != tt.expected.UnsupportedParameterPolicy {
+				t.Errorf("UnsupportedParameterPolicy = %q, want %q", config.UnsupportedParameterPolicy, tt.expected.UnsupportedParameterPolicy)
+			}
+			if config.PromptInjectionGuard != tt.expected.PromptInjectionGuard {
+				t.Errorf("PromptInjectionGuard = %v, want %v", config.PromptInjectionGuard, tt.expected.PromptInjectionGuard)
+			}
+			if config.StreamIdleTimeout != tt.expected.StreamIdleTimeout {
+				t.Errorf("StreamIdleTimeout = %v, want %v", config.StreamIdleTimeout, tt.expected.StreamIdleTimeout)
+			}
+			if config.StreamStallRetries != tt.expected.StreamStallRetries {
+				t.Errorf("StreamStallRetries = %d, want %d", config.StreamStallRetries, tt.expected.StreamStallRetries)
+			}
+			if config.StreamBufferSize != tt.expected.StreamBufferSize {
+				t.Errorf("StreamBufferSize = %d, want %d", config.StreamBufferSize, tt.expected.StreamBufferSize)
+			}
+			if config.RequestCompressionThreshold != tt.expected.RequestCompressionThreshold {
+				t.Errorf("RequestCompressionThreshold = %d, want %d", config.RequestCompressionThreshold, tt.expected.RequestCompressionThreshold)
+			}
+			if config.ErrorSanitization != tt.expected.ErrorSanitization {
+				t.Errorf("ErrorSanitization = %q, want %q", config.ErrorSanitization, tt.expected.ErrorSanitization)
+			}
+			if config.DebugPayloads != tt.expected.DebugPayloads {
+				t.Errorf("DebugPayloads = %v, want %v", config.DebugPayloads, tt.expected.DebugPayloads)
+			}
+			if strings.Join(config.DebugRedactFields, ",") != strings.Join(tt.expected.DebugRedactFields, ",") {
+				t.Errorf("DebugRedactFields = %v, want %v", config.DebugRedactFields, tt.expected.DebugRedactFields)
+			}
 
 			// Clean up environment variables for next test
 			for key := range tt.envVars {
@@ -425,6 +545,11 @@ func TestConfigBuilderMethods(t *testing.T) {
 	}
 
 	// Test WithBaseURL
+	newConfig = baseConfig.WithModel("gpt-4o")
+	if newConfig.Model != "gpt-4o" {
+		t.Errorf("WithModel: Model = %q, want %q", newConfig.Model, "gpt-4o")
+	}
+
 	newConfig = baseConfig.WithBaseURL("https://api.example.com")
 	if newConfig.BaseURL != "https://api.example.com" {
 		t.Errorf("WithBaseURL: BaseURL = %q, want %q", newConfig.BaseURL, "https://api.example.com")
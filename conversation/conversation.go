@@ -0,0 +1,208 @@
+// Package conversation manages multi-turn chat history on top of a client.
+//
+// A Conversation keeps the message history of a chat thread, sends each new
+// user turn together with the history, and records the assistant's replies.
+// Conversations can be forked to explore alternate continuations without
+// mutating the original thread, and exported with Export to OpenAI,
+// Anthropic, ShareGPT or Markdown form for datasets and debugging. Import
+// reads conversations back from exports, API request logs or ShareGPT dumps,
+// and Restore resumes them.
+//
+// Example:
+//
+//	conv := conversation.New(client, conversation.Options{
+//		SystemPrompt: "You are a helpful assistant.",
+//	})
+//	reply, err := conv.Send(ctx, "Suggest a name for a bakery")
+//
+//	// Explore an alternative continuation without touching conv
+//	alt := conv.Fork()
+//	altReply, err := alt.Send(ctx, "Make it more playful")
+package conversation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// Message roles used in conversation history
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// ChatClient is the subset of the client API a Conversation needs.
+// It is satisfied by aiprovider.Client.
+type ChatClient interface {
+	ChatComplete(ctx context.Context, req types.ChatRequest) (*types.ChatResponse, error)
+}
+
+// Options configures a Conversation.
+type Options struct {
+	// SystemPrompt is sent as the first message of every request (optional)
+	SystemPrompt string
+
+	// Temperature is applied to every request (optional)
+	Temperature *float64
+
+	// MaxTokens is applied to every request (optional)
+	MaxTokens *int
+}
+
+// clone returns a deep copy of the options
+func (o Options) clone() Options {
+	clone := o
+	if o.Temperature != nil {
+		temperature := *o.Temperature
+		clone.Temperature = &temperature
+	}
+	if o.MaxTokens != nil {
+		maxTokens := *o.MaxTokens
+		clone.MaxTokens = &maxTokens
+	}
+	return clone
+}
+
+// Conversation is a chat thread with message history.
+//
+// Conversation is safe for concurrent use, although concurrent Sends on the
+// same conversation are serialized so turns are not interleaved. Calls that
+// modify the history, such as Append and Reset, wait for an in-flight turn
+// so its reply is never lost or appended to a cleared history.
+type Conversation struct {
+	client  ChatClient
+	options Options
+	session string
+
+	// sendMu serializes requests and history changes; mu guards the fields
+	// below so reads do not wait for in-flight requests
+	sendMu   sync.Mutex
+	mu       sync.Mutex
+	messages []types.Message
+}
+
+// New creates an empty conversation that sends requests through client
+func New(client ChatClient, options Options) *Conversation {
+	return &Conversation{
+		client:  client,
+		options: options.clone(),
+		session: newSessionID(),
+	}
+}
+
+// newSessionID returns a random session ID
+func newSessionID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// Session returns the ID the conversation's requests carry with
+// types.WithSession, so clients with session affinity keep every turn on the
+// same provider and model
+func (c *Conversation) Session() string {
+	return c.session
+}
+
+// Send appends a user message, requests a reply with the full history and
+// appends the assistant's reply. If the request fails the history is left
+// unchanged.
+func (c *Conversation) Send(ctx context.Context, content string) (*types.ChatResponse, error) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	history := append(c.Messages(), types.Message{Role: RoleUser, Content: content})
+	resp, err := c.complete(ctx, history, c.options)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.messages = append(history, resp.Message)
+	c.mu.Unlock()
+
+	return resp, nil
+}
+
+// Append adds messages to the history without sending a request, e.g. to
+// restore a saved thread
+func (c *Conversation) Append(messages ...types.Message) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, messages...)
+}
+
+// Messages returns a copy of the history, excluding the system prompt
+func (c *Conversation) Messages() []types.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]types.Message(nil), c.messages...)
+}
+
+// Len returns the number of messages in the history
+func (c *Conversation) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.messages)
+}
+
+// Reset clears the history, keeping the options
+func (c *Conversation) Reset() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = nil
+}
+
+// Fork returns an independent copy of the conversation.
+//
+// The fork shares the client but has its own history and options, so turns
+// sent on either conversation do not affect the other. This allows
+// applications to explore alternate continuations of the same thread. The
+// fork keeps the session, so it continues on the same model.
+func (c *Conversation) Fork() *Conversation {
+	return &Conversation{
+		client:   c.client,
+		options:  c.options.clone(),
+		session:  c.session,
+		messages: c.Messages(),
+	}
+}
+
+// complete sends the history, prefixed with the system prompt, to the client,
+// in the conversation's session unless ctx selects another
+func (c *Conversation) complete(ctx context.Context, history []types.Message, options Options) (*types.ChatResponse, error) {
+	if types.SessionFromContext(ctx) == "" && c.session != "" {
+		ctx = types.WithSession(ctx, c.session)
+	}
+
+	messages := make([]types.Message, 0, len(history)+1)
+	if options.SystemPrompt != "" {
+		messages = append(messages, types.Message{Role: RoleSystem, Content: options.SystemPrompt})
+	}
+	messages = append(messages, history...)
+
+	resp, err := c.client.ChatComplete(ctx, types.ChatRequest{
+		Messages:    messages,
+		Temperature: options.Temperature,
+		MaxTokens:   options.MaxTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("client returned no response")
+	}
+	return resp, nil
+}
@@ -0,0 +1,328 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// mockChatClient replies with a numbered assistant message and records requests
+type mockChatClient struct {
+	requests []types.ChatRequest
+	sessions []string
+	err      error
+}
+
+func (m *mockChatClient) ChatComplete(ctx context.Context, req types.ChatRequest) (*types.ChatResponse, error) {
+	m.requests = append(m.requests, req)
+	m.sessions = append(m.sessions, types.SessionFromContext(ctx))
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &types.ChatResponse{
+		Message:      types.Message{Role: RoleAssistant, Content: fmt.Sprintf("reply %d", len(m.requests))},
+		FinishReason: "stop",
+	}, nil
+}
+
+func TestSend(t *testing.T) {
+	client := &mockChatClient{}
+	conv := New(client, Options{SystemPrompt: "Be brief."})
+
+	if _, err := conv.Send(context.Background(), "Hello"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := conv.Send(context.Background(), "Again"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if conv.Len() != 4 {
+		t.Errorf("Expected 4 messages in history, got %d", conv.Len())
+	}
+
+	last := client.requests[1]
+	if len(last.Messages) != 4 {
+		t.Fatalf("Expected 4 messages sent, got %d", len(last.Messages))
+	}
+	if last.Messages[0].Role != RoleSystem || last.Messages[0].Content != "Be brief." {
+		t.Errorf("Expected system prompt first, got %+v", last.Messages[0])
+	}
+	if last.Messages[2].Content != "reply 1" || last.Messages[3].Content != "Again" {
+		t.Errorf("Expected previous reply and new turn, got %+v", last.Messages)
+	}
+}
+
+func TestSendErrorLeavesHistoryUnchanged(t *testing.T) {
+	client := &mockChatClient{err: errors.New("boom")}
+	conv := New(client, Options{})
+
+	if _, err := conv.Send(context.Background(), "Hello"); err == nil {
+		t.Fatal("Expected error")
+	}
+	if conv.Len() != 0 {
+		t.Errorf("Expected empty history after failed send, got %d messages", conv.Len())
+	}
+}
+
+func TestFork(t *testing.T) {
+	client := &mockChatClient{}
+	temperature := 0.5
+	conv := New(client, Options{Temperature: &temperature})
+
+	if _, err := conv.Send(context.Background(), "Start"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	fork := conv.Fork()
+	if _, err := fork.Send(context.Background(), "Branch"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if conv.Len() != 2 {
+		t.Errorf("Expected original history to be unchanged, got %d messages", conv.Len())
+	}
+	if fork.Len() != 4 {
+		t.Errorf("Expected fork to have 4 messages, got %d", fork.Len())
+	}
+
+	// Mutating the fork's history must not affect the original
+	fork.Reset()
+	if conv.Len() != 2 {
+		t.Errorf("Expected original history to survive fork reset, got %d messages", conv.Len())
+	}
+
+	// Options are copied, not shared
+	*fork.options.Temperature = 1.5
+	if *conv.options.Temperature != 0.5 {
+		t.Errorf("Expected original temperature 0.5, got %f", *conv.options.Temperature)
+	}
+}
+
+func TestSession(t *testing.T) {
+	client := &mockChatClient{}
+	conv := New(client, Options{})
+	other := New(client, Options{})
+	if conv.Session() == "" || conv.Session() == other.Session() {
+		t.Fatalf("Expected unique session IDs, got %q and %q", conv.Session(), other.Session())
+	}
+
+	conv.Send(context.Background(), "Hello")
+	conv.Fork().Send(context.Background(), "Fork")
+	other.Send(context.Background(), "Other")
+	conv.Send(types.WithSession(context.Background(), "caller"), "Explicit")
+
+	want := []string{conv.Session(), conv.Session(), other.Session(), "caller"}
+	for i, session := range want {
+		if client.sessions[i] != session {
+			t.Errorf("Request %d: expected session %q, got %q", i, session, client.sessions[i])
+		}
+	}
+}
+
+func TestRegenerate(t *testing.T) {
+	tests := []struct {
+		name                string
+		opts                RegenerateOptions
+		expectedTemperature *float64
+		expectInstruction   bool
+	}{
+		{
+			name:                "temperature",
+			opts:                RegenerateOptions{},
+			expectedTemperature: floatPtr(0.8),
+		},
+		{
+			name:              "instruction",
+			opts:              RegenerateOptions{Strategy: RegenerateInstruction},
+			expectInstruction: true,
+		},
+		{
+			name:                "both with custom step",
+			opts:                RegenerateOptions{Strategy: RegenerateBoth, TemperatureStep: 2},
+			expectedTemperature: floatPtr(2.0),
+			expectInstruction:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockChatClient{}
+			conv := New(client, Options{Temperature: floatPtr(0.5)})
+			if _, err := conv.Send(context.Background(), "Name a color"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			result, err := conv.Regenerate(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if result.Previous.Content != "reply 1" {
+				t.Errorf("Expected previous reply 'reply 1', got %q", result.Previous.Content)
+			}
+			if result.Response.Message.Content != "reply 2" {
+				t.Errorf("Expected new reply 'reply 2', got %q", result.Response.Message.Content)
+			}
+
+			history := conv.Messages()
+			if len(history) != 2 || history[1].Content != "reply 2" {
+				t.Errorf("Expected history to end with the new reply, got %+v", history)
+			}
+
+			req := client.requests[1]
+			if tt.expectedTemperature == nil {
+				if *req.Temperature != 0.5 {
+					t.Errorf("Expected unchanged temperature 0.5, got %f", *req.Temperature)
+				}
+			} else if diff := *req.Temperature - *tt.expectedTemperature; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Expected temperature %f, got %f", *tt.expectedTemperature, *req.Temperature)
+			}
+
+			last := req.Messages[len(req.Messages)-1]
+			if tt.expectInstruction {
+				if last.Content != DefaultRegenerateInstruction || len(req.Messages) != 3 {
+					t.Errorf("Expected previous reply and instruction to be sent, got %+v", req.Messages)
+				}
+			} else if last.Content != "Name a color" || len(req.Messages) != 1 {
+				t.Errorf("Expected only the user turn to be sent, got %+v", req.Messages)
+			}
+		})
+	}
+}
+
+func TestRegenerateRequiresAssistantReply(t *testing.T) {
+	conv := New(&mockChatClient{}, Options{})
+	if _, err := conv.Regenerate(context.Background(), RegenerateOptions{}); err == nil {
+		t.Error("Expected error for empty conversation")
+	}
+
+	conv.Append(types.Message{Role: RoleUser, Content: "Hi"})
+	if _, err := conv.Regenerate(context.Background(), RegenerateOptions{}); err == nil {
+		t.Error("Expected error when conversation ends with a user message")
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestEdit(t *testing.T) {
+	client := &mockChatClient{}
+	conv := New(client, Options{})
+	for _, turn := range []string{"first", "second", "third"} {
+		if _, err := conv.Send(context.Background(), turn); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	var events []ReplayEvent
+	responses, err := conv.Edit(context.Background(), 2, "second (edited)", EditOptions{
+		OnProgress: func(e ReplayEvent) { events = append(events, e) },
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 replayed turns, got %d", len(responses))
+	}
+	if len(events) != 2 || events[0].Turn != 1 || events[1].Total != 2 {
+		t.Errorf("Expected progress events for 2 turns, got %+v", events)
+	}
+
+	history := conv.Messages()
+	expected := []string{"first", "reply 1", "second (edited)", "reply 4", "third", "reply 5"}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d", len(expected), len(history))
+	}
+	for i, content := range expected {
+		if history[i].Content != content {
+			t.Errorf("Expected message %d to be %q, got %q", i, content, history[i].Content)
+		}
+	}
+
+	// The replayed third turn must see the edited history
+	req := client.requests[4]
+	if req.Messages[2].Content != "second (edited)" || req.Messages[3].Content != "reply 4" {
+		t.Errorf("Expected replay to include edited turn, got %+v", req.Messages)
+	}
+}
+
+func TestEditErrors(t *testing.T) {
+	client := &mockChatClient{}
+	conv := New(client, Options{})
+	if _, err := conv.Send(context.Background(), "first"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := conv.Edit(context.Background(), 1, "x", EditOptions{}); err == nil {
+		t.Error("Expected error when editing an assistant message")
+	}
+	if _, err := conv.Edit(context.Background(), 5, "x", EditOptions{}); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+
+	client.err = errors.New("boom")
+	var failed ReplayEvent
+	_, err := conv.Edit(context.Background(), 0, "edited", EditOptions{
+		OnProgress: func(e ReplayEvent) { failed = e },
+	})
+	if err == nil {
+		t.Fatal("Expected replay error")
+	}
+	if failed.Err == nil {
+		t.Error("Expected progress event to carry the error")
+	}
+	if history := conv.Messages(); len(history) != 2 || history[0].Content != "first" {
+		t.Errorf("Expected history unchanged after failed replay, got %+v", history)
+	}
+}
+
+// blockingChatClient replies once release is closed
+type blockingChatClient struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingChatClient) ChatComplete(ctx context.Context, req types.ChatRequest) (*types.ChatResponse, error) {
+	close(b.started)
+	<-b.release
+	return &types.ChatResponse{Message: types.Message{Role: RoleAssistant, Content: "reply"}}, nil
+}
+
+func TestAppendDuringSend(t *testing.T) {
+	client := &blockingChatClient{started: make(chan struct{}), release: make(chan struct{})}
+	conv := New(client, Options{})
+
+	sent := make(chan error)
+	go func() {
+		_, err := conv.Send(context.Background(), "Hello")
+		sent <- err
+	}()
+	<-client.started
+
+	appended := make(chan struct{})
+	go func() {
+		conv.Append(types.Message{Role: RoleUser, Content: "restored"})
+		close(appended)
+	}()
+
+	// Reads do not wait for the in-flight request
+	if n := conv.Len(); n != 0 {
+		t.Errorf("Expected an empty history while the turn is in flight, got %d", n)
+	}
+
+	close(client.release)
+	if err := <-sent; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	<-appended
+
+	history := conv.Messages()
+	if len(history) != 3 || history[1].Content != "reply" || history[2].Content != "restored" {
+		t.Errorf("Expected the reply kept and the appended message after it, got %+v", history)
+	}
+}
@@ -0,0 +1,102 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// ReplayEvent reports the progress of Edit as each turn is regenerated.
+type ReplayEvent struct {
+	// Turn is the 1-based number of the turn being replayed
+	Turn int
+
+	// Total is the number of turns that will be replayed
+	Total int
+
+	// Message is the user message that was sent
+	Message types.Message
+
+	// Response is the regenerated reply (nil if Err is set)
+	Response *types.ChatResponse
+
+	// Err is the error that stopped the replay, if any
+	Err error
+}
+
+// EditOptions configures Edit.
+type EditOptions struct {
+	// OnProgress is called after each replayed turn (optional)
+	OnProgress func(ReplayEvent)
+}
+
+// Edit replaces the user message at index and replays the conversation from
+// that point.
+//
+// The history is truncated before the edited message, the edited message is
+// sent and every later user message is re-sent in order, each receiving a
+// freshly generated reply. Only user turns are replayed; the assistant replies
+// after index are discarded. If any request fails the history is left
+// unchanged and the error is returned.
+//
+// Example:
+//
+//	responses, err := conv.Edit(ctx, 0, "Plan a trip to Lisbon instead", conversation.EditOptions{
+//		OnProgress: func(e conversation.ReplayEvent) {
+//			fmt.Printf("replayed %d/%d\n", e.Turn, e.Total)
+//		},
+//	})
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - index: Index in Messages() of the user message to edit
+//   - content: The new content of the message
+//   - opts: Replay options
+//
+// Returns:
+//   - []*types.ChatResponse: The regenerated replies, one per replayed turn
+//   - error: An error if index is not a user message or a request fails
+func (c *Conversation) Edit(ctx context.Context, index int, content string, opts EditOptions) ([]*types.ChatResponse, error) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	original := c.Messages()
+	if index < 0 || index >= len(original) {
+		return nil, fmt.Errorf("cannot edit message %d: conversation has %d messages", index, len(original))
+	}
+	if original[index].Role != RoleUser {
+		return nil, fmt.Errorf("cannot edit message %d: only user messages can be edited, got role %q", index, original[index].Role)
+	}
+
+	turns := []types.Message{{Role: RoleUser, Content: content}}
+	for _, msg := range original[index+1:] {
+		if msg.Role == RoleUser {
+			turns = append(turns, msg)
+		}
+	}
+
+	history := append([]types.Message(nil), original[:index]...)
+	responses := make([]*types.ChatResponse, 0, len(turns))
+	for i, turn := range turns {
+		history = append(history, turn)
+		resp, err := c.complete(ctx, history, c.options)
+
+		event := ReplayEvent{Turn: i + 1, Total: len(turns), Message: turn, Response: resp, Err: err}
+		if opts.OnProgress != nil {
+			opts.OnProgress(event)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("replay failed at turn %d of %d: %w", i+1, len(turns), err)
+		}
+
+		history = append(history, resp.Message)
+		responses = append(responses, resp)
+	}
+
+	c.mu.Lock()
+	c.messages = history
+	c.mu.Unlock()
+
+	return responses, nil
+}
@@ -0,0 +1,153 @@
+package conversation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// Format is a serialization format for exported conversations.
+type Format string
+
+const (
+	// FormatOpenAI is the OpenAI chat format, {"messages": [{"role", "content"}]},
+	// also used for OpenAI fine-tuning datasets
+	FormatOpenAI Format = "openai"
+
+	// FormatAnthropic is the Anthropic messages format, with system messages
+	// in a top-level "system" field and consecutive messages of the same
+	// role merged so roles alternate
+	FormatAnthropic Format = "anthropic"
+
+	// FormatMarkdown is a readable transcript with a heading per message
+	FormatMarkdown Format = "markdown"
+
+	// FormatShareGPT is the ShareGPT dataset format,
+	// {"conversations": [{"from", "value"}]}
+	FormatShareGPT Format = "sharegpt"
+)
+
+// exportMessage is a message in the OpenAI and Anthropic formats
+type exportMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIExport is a conversation in the OpenAI chat format
+type openAIExport struct {
+	Messages []exportMessage `json:"messages"`
+}
+
+// anthropicExport is a conversation in the Anthropic messages format
+type anthropicExport struct {
+	System   string          `json:"system,omitempty"`
+	Messages []exportMessage `json:"messages"`
+}
+
+// shareGPTTurn is a message in the ShareGPT format
+type shareGPTTurn struct {
+	From  string `json:"from"`
+	Value string `json:"value"`
+}
+
+// shareGPTExport is a conversation in the ShareGPT format
+type shareGPTExport struct {
+	Conversations []shareGPTTurn `json:"conversations"`
+}
+
+// shareGPTRoles maps message roles to ShareGPT speakers
+var shareGPTRoles = map[string]string{
+	RoleSystem:    "system",
+	RoleUser:      "human",
+	RoleAssistant: "gpt",
+}
+
+// Export writes the conversation, including the system prompt, to w in
+// format. The JSON formats are written as a single line ending in a newline,
+// so exporting several conversations to one writer produces a JSONL dataset.
+func (c *Conversation) Export(w io.Writer, format Format) error {
+	var messages []types.Message
+	if c.options.SystemPrompt != "" {
+		messages = append(messages, types.Message{Role: RoleSystem, Content: c.options.SystemPrompt})
+	}
+	return Export(w, format, append(messages, c.Messages()...))
+}
+
+// Export writes messages to w in format, like Conversation.Export
+func Export(w io.Writer, format Format, messages []types.Message) error {
+	switch format {
+	case FormatOpenAI:
+		export := openAIExport{Messages: make([]exportMessage, 0, len(messages))}
+		for _, msg := range messages {
+			export.Messages = append(export.Messages, exportMessage{Role: msg.Role, Content: msg.Content})
+		}
+		return writeJSONLine(w, export)
+	case FormatAnthropic:
+		return writeJSONLine(w, anthropicMessages(messages))
+	case FormatShareGPT:
+		export := shareGPTExport{Conversations: make([]shareGPTTurn, 0, len(messages))}
+		for _, msg := range messages {
+			from, ok := shareGPTRoles[msg.Role]
+			if !ok {
+				from = msg.Role
+			}
+			export.Conversations = append(export.Conversations, shareGPTTurn{From: from, Value: msg.Content})
+		}
+		return writeJSONLine(w, export)
+	case FormatMarkdown:
+		return writeMarkdown(w, messages)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// anthropicMessages moves system messages to the system field and merges
+// consecutive messages of the same role
+func anthropicMessages(messages []types.Message) anthropicExport {
+	var export anthropicExport
+	var system []string
+	export.Messages = make([]exportMessage, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == RoleSystem {
+			system = append(system, msg.Content)
+			continue
+		}
+		if last := len(export.Messages) - 1; last >= 0 && export.Messages[last].Role == msg.Role {
+			export.Messages[last].Content += "\n\n" + msg.Content
+			continue
+		}
+		export.Messages = append(export.Messages, exportMessage{Role: msg.Role, Content: msg.Content})
+	}
+	export.System = strings.Join(system, "\n\n")
+	return export
+}
+
+// writeJSONLine writes v as compact JSON followed by a newline
+func writeJSONLine(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// writeMarkdown writes messages as a transcript with a heading per message
+func writeMarkdown(w io.Writer, messages []types.Message) error {
+	var b strings.Builder
+	for i, msg := range messages {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		role := msg.Role
+		if role != "" {
+			role = strings.ToUpper(role[:1]) + role[1:]
+		}
+		fmt.Fprintf(&b, "### %s\n\n%s\n", role, strings.TrimRight(msg.Content, "\n"))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,59 @@
+package conversation
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+func TestExport(t *testing.T) {
+	conv := New(&mockChatClient{}, Options{SystemPrompt: "Be brief"})
+	if _, err := conv.Send(context.Background(), "Hello"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	conv.Append(types.Message{Role: RoleUser, Content: "More"}, types.Message{Role: RoleUser, Content: "Please"})
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: FormatOpenAI,
+			want: `{"messages":[{"role":"system","content":"Be brief"},{"role":"user","content":"Hello"},` +
+				`{"role":"assistant","content":"reply 1"},{"role":"user","content":"More"},{"role":"user","content":"Please"}]}` + "\n",
+		},
+		{
+			format: FormatAnthropic,
+			want: `{"system":"Be brief","messages":[{"role":"user","content":"Hello"},` +
+				`{"role":"assistant","content":"reply 1"},{"role":"user","content":"More\n\nPlease"}]}` + "\n",
+		},
+		{
+			format: FormatShareGPT,
+			want: `{"conversations":[{"from":"system","value":"Be brief"},{"from":"human","value":"Hello"},` +
+				`{"from":"gpt","value":"reply 1"},{"from":"human","value":"More"},{"from":"human","value":"Please"}]}` + "\n",
+		},
+		{
+			format: FormatMarkdown,
+			want: "### System\n\nBe brief\n\n### User\n\nHello\n\n### Assistant\n\nreply 1\n\n" +
+				"### User\n\nMore\n\n### User\n\nPlease\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := conv.Export(&buf, tt.format); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Unexpected export:\ngot  %q\nwant %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	if err := conv.Export(&bytes.Buffer{}, "csv"); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,247 @@
+package conversation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// shareGPTSpeakers maps ShareGPT speakers, including the variants found in
+// public dumps, to message roles
+var shareGPTSpeakers = map[string]string{
+	"system":    RoleSystem,
+	"human":     RoleUser,
+	"user":      RoleUser,
+	"gpt":       RoleAssistant,
+	"chatgpt":   RoleAssistant,
+	"assistant": RoleAssistant,
+	"bard":      RoleAssistant,
+	"bing":      RoleAssistant,
+}
+
+// importMessage is a message in the OpenAI and Anthropic formats, whose
+// content is a string or an array of content parts
+type importMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// importRecord is a conversation in any of the JSON formats
+type importRecord struct {
+	System        json.RawMessage `json:"system"`
+	Messages      []importMessage `json:"messages"`
+	Conversations []shareGPTTurn  `json:"conversations"`
+}
+
+// Import reads conversations from r in format and returns the messages of
+// each, including any system prompt as a leading system message.
+//
+// r holds one JSON object per conversation, either one per line as written by
+// Export or as a JSON array as found in ShareGPT dumps. For FormatOpenAI and
+// FormatAnthropic each object is an API request body, so request logs can be
+// imported directly; other request fields such as the model are ignored, as
+// are non-text content parts such as images and tool calls. Markdown
+// transcripts cannot be imported.
+//
+// Example:
+//
+//	threads, err := conversation.Import(logFile, conversation.FormatOpenAI)
+//	if err != nil {
+//		return err
+//	}
+//	for _, messages := range threads {
+//		conv := conversation.Restore(client, conversation.Options{}, messages)
+//		reply, err := conv.Send(ctx, "Please continue")
+//		...
+//	}
+func Import(r io.Reader, format Format) ([][]types.Message, error) {
+	var parse func(record importRecord) ([]types.Message, error)
+	switch format {
+	case FormatOpenAI:
+		parse = openAIMessages
+	case FormatAnthropic:
+		parse = importAnthropicMessages
+	case FormatShareGPT:
+		parse = shareGPTMessages
+	case FormatMarkdown:
+		return nil, fmt.Errorf("importing %s transcripts is not supported", format)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+
+	records, err := decodeRecords(r)
+	if err != nil {
+		return nil, err
+	}
+	conversations := make([][]types.Message, 0, len(records))
+	for i, record := range records {
+		messages, err := parse(record)
+		if err != nil {
+			return nil, fmt.Errorf("conversation %d: %w", i+1, err)
+		}
+		conversations = append(conversations, messages)
+	}
+	return conversations, nil
+}
+
+// Restore creates a conversation with imported messages as its history.
+// Leading system messages become the system prompt unless options already
+// sets one.
+func Restore(client ChatClient, options Options, messages []types.Message) *Conversation {
+	var system []string
+	for len(messages) > 0 && messages[0].Role == RoleSystem {
+		system = append(system, messages[0].Content)
+		messages = messages[1:]
+	}
+	if options.SystemPrompt == "" {
+		options.SystemPrompt = strings.Join(system, "\n\n")
+	}
+
+	conv := New(client, options)
+	conv.messages = append([]types.Message(nil), messages...)
+	return conv
+}
+
+// decodeRecords reads a JSON array of records or a stream of JSON records
+func decodeRecords(r io.Reader) ([]importRecord, error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
+
+	first, err := peekNonSpace(reader)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversations: %w", err)
+	}
+	if first == '[' {
+		var records []importRecord
+		if err := decoder.Decode(&records); err != nil {
+			return nil, fmt.Errorf("failed to decode conversations: %w", err)
+		}
+		return records, nil
+	}
+
+	var records []importRecord
+	for {
+		var record importRecord
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode conversation %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+}
+
+// peekNonSpace returns the first non-whitespace byte of r without consuming it
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsAny(b, " \t\r\n") {
+			return b[0], nil
+		}
+		if _, err := r.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// openAIMessages converts an OpenAI chat request body
+func openAIMessages(record importRecord) ([]types.Message, error) {
+	if len(record.Messages) == 0 {
+		return nil, fmt.Errorf("no messages")
+	}
+	messages := make([]types.Message, 0, len(record.Messages))
+	for i, msg := range record.Messages {
+		content, err := textContent(msg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		role := msg.Role
+		if role == "developer" {
+			// Newer OpenAI models take system instructions as developer messages
+			role = RoleSystem
+		}
+		messages = append(messages, types.Message{Role: role, Content: content})
+	}
+	return messages, nil
+}
+
+// importAnthropicMessages converts an Anthropic messages request body
+func importAnthropicMessages(record importRecord) ([]types.Message, error) {
+	if len(record.Messages) == 0 {
+		return nil, fmt.Errorf("no messages")
+	}
+	system, err := textContent(record.System)
+	if err != nil {
+		return nil, fmt.Errorf("system: %w", err)
+	}
+
+	messages := make([]types.Message, 0, len(record.Messages)+1)
+	if system != "" {
+		messages = append(messages, types.Message{Role: RoleSystem, Content: system})
+	}
+	for i, msg := range record.Messages {
+		content, err := textContent(msg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		messages = append(messages, types.Message{Role: msg.Role, Content: content})
+	}
+	return messages, nil
+}
+
+// shareGPTMessages converts a ShareGPT transcript
+func shareGPTMessages(record importRecord) ([]types.Message, error) {
+	if len(record.Conversations) == 0 {
+		return nil, fmt.Errorf("no conversations")
+	}
+	messages := make([]types.Message, 0, len(record.Conversations))
+	for i, turn := range record.Conversations {
+		role, ok := shareGPTSpeakers[strings.ToLower(turn.From)]
+		if !ok {
+			return nil, fmt.Errorf("message %d: unknown speaker %q", i+1, turn.From)
+		}
+		messages = append(messages, types.Message{Role: role, Content: turn.Value})
+	}
+	return messages, nil
+}
+
+// textContent returns message content given as a string, or as an array of
+// content parts whose text parts are joined
+func textContent(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", fmt.Errorf("content must be a string or an array of content parts")
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n"), nil
+}
@@ -0,0 +1,111 @@
+package conversation
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+		want   [][]types.Message
+	}{
+		{
+			name:   "openai request log",
+			format: FormatOpenAI,
+			input: `{"model":"gpt-4o","messages":[{"role":"developer","content":"Be brief"},{"role":"user","content":[{"type":"text","text":"Hi"},{"type":"image_url","image_url":{"url":"x"}}]}]}
+{"messages":[{"role":"user","content":"Bye"},{"role":"assistant","content":"Goodbye"}]}
+`,
+			want: [][]types.Message{
+				{{Role: RoleSystem, Content: "Be brief"}, {Role: RoleUser, Content: "Hi"}},
+				{{Role: RoleUser, Content: "Bye"}, {Role: RoleAssistant, Content: "Goodbye"}},
+			},
+		},
+		{
+			name:   "anthropic request log",
+			format: FormatAnthropic,
+			input:  `{"model":"claude-3-haiku","system":[{"type":"text","text":"Be brief"}],"messages":[{"role":"user","content":"Hi"},{"role":"assistant","content":[{"type":"text","text":"Hello"}]}]}`,
+			want: [][]types.Message{
+				{{Role: RoleSystem, Content: "Be brief"}, {Role: RoleUser, Content: "Hi"}, {Role: RoleAssistant, Content: "Hello"}},
+			},
+		},
+		{
+			name:   "sharegpt dump",
+			format: FormatShareGPT,
+			input:  `[{"id":"a1","conversations":[{"from":"human","value":"Hi"},{"from":"gpt","value":"Hello"}]}]`,
+			want: [][]types.Message{
+				{{Role: RoleUser, Content: "Hi"}, {Role: RoleAssistant, Content: "Hello"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Import(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unexpected messages:\ngot  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImport_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+	}{
+		{"markdown", FormatMarkdown, "### User\n\nHi\n"},
+		{"unknown format", "csv", ""},
+		{"invalid json", FormatOpenAI, `{"messages":`},
+		{"no messages", FormatOpenAI, `{"model":"gpt-4o"}`},
+		{"invalid content", FormatAnthropic, `{"messages":[{"role":"user","content":42}]}`},
+		{"unknown speaker", FormatShareGPT, `{"conversations":[{"from":"robot","value":"Hi"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Import(strings.NewReader(tt.input), tt.format); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestImport_RoundTrip(t *testing.T) {
+	conv := New(&mockChatClient{}, Options{SystemPrompt: "Be brief"})
+	conv.Append(types.Message{Role: RoleUser, Content: "Hi"}, types.Message{Role: RoleAssistant, Content: "Hello"})
+
+	for _, format := range []Format{FormatOpenAI, FormatAnthropic, FormatShareGPT} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := conv.Export(&buf, format); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			threads, err := Import(&buf, format)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(threads) != 1 {
+				t.Fatalf("Expected 1 conversation, got %d", len(threads))
+			}
+
+			restored := Restore(&mockChatClient{}, Options{}, threads[0])
+			if restored.options.SystemPrompt != "Be brief" {
+				t.Errorf("Expected the system prompt to be restored, got %q", restored.options.SystemPrompt)
+			}
+			if !reflect.DeepEqual(restored.Messages(), conv.Messages()) {
+				t.Errorf("Unexpected history: %+v", restored.Messages())
+			}
+		})
+	}
+}
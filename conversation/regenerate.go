@@ -0,0 +1,127 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// RegenerateStrategy controls how Regenerate discourages repeating the previous reply.
+type RegenerateStrategy int
+
+const (
+	// RegenerateTemperature raises the sampling temperature for the retry
+	RegenerateTemperature RegenerateStrategy = iota
+
+	// RegenerateInstruction shows the model its previous reply and asks for a
+	// different answer
+	RegenerateInstruction
+
+	// RegenerateBoth combines a raised temperature with the instruction
+	RegenerateBoth
+)
+
+const (
+	// DefaultTemperatureStep is added to the temperature when regenerating
+	DefaultTemperatureStep = 0.3
+
+	// DefaultRegenerateInstruction asks the model for a different answer
+	DefaultRegenerateInstruction = "Please give a different answer to my previous message. Do not repeat your previous response."
+
+	// defaultTemperature is assumed when the conversation has no temperature
+	// set, matching the OpenAI and Anthropic API defaults
+	defaultTemperature = 1.0
+
+	// maxTemperature caps the raised temperature; the client further clamps
+	// it to the provider's limit
+	maxTemperature = 2.0
+)
+
+// RegenerateOptions configures Regenerate.
+type RegenerateOptions struct {
+	// Strategy selects how repetition is discouraged (default: RegenerateTemperature)
+	Strategy RegenerateStrategy
+
+	// TemperatureStep is added to the conversation temperature (default: 0.3)
+	TemperatureStep float64
+
+	// Instruction replaces DefaultRegenerateInstruction (optional)
+	Instruction string
+}
+
+// Regeneration holds the replaced and the new assistant reply for comparison.
+type Regeneration struct {
+	// Previous is the assistant reply that was replaced
+	Previous types.Message
+
+	// Response is the new reply, which now ends the conversation history
+	Response *types.ChatResponse
+}
+
+// Regenerate re-asks the last user turn and replaces the last assistant reply.
+//
+// The conversation must end with an assistant reply to a user message. The
+// injected instruction, if any, is only sent with the retry and is not kept
+// in the history. If the request fails the history is left unchanged.
+//
+// Example:
+//
+//	result, err := conv.Regenerate(ctx, conversation.RegenerateOptions{
+//		Strategy: conversation.RegenerateBoth,
+//	})
+//	fmt.Println("Before:", result.Previous.Content)
+//	fmt.Println("After:", result.Response.Message.Content)
+func (c *Conversation) Regenerate(ctx context.Context, opts RegenerateOptions) (*Regeneration, error) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	history := c.Messages()
+	n := len(history)
+	if n < 2 || history[n-1].Role != RoleAssistant || history[n-2].Role != RoleUser {
+		return nil, fmt.Errorf("cannot regenerate: conversation does not end with an assistant reply to a user message")
+	}
+	previous := history[n-1]
+	kept := history[:n-1]
+
+	options := c.options.clone()
+	request := kept
+	if opts.Strategy == RegenerateTemperature || opts.Strategy == RegenerateBoth {
+		options.Temperature = raiseTemperature(options.Temperature, opts.TemperatureStep)
+	}
+	if opts.Strategy == RegenerateInstruction || opts.Strategy == RegenerateBoth {
+		instruction := opts.Instruction
+		if instruction == "" {
+			instruction = DefaultRegenerateInstruction
+		}
+		request = append(history[:n:n], types.Message{Role: RoleUser, Content: instruction})
+	}
+
+	resp, err := c.complete(ctx, request, options)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.messages = append(kept[:n-1:n-1], resp.Message)
+	c.mu.Unlock()
+
+	return &Regeneration{Previous: previous, Response: resp}, nil
+}
+
+// raiseTemperature returns the temperature increased by step, capped at maxTemperature
+func raiseTemperature(current *float64, step float64) *float64 {
+	if step <= 0 {
+		step = DefaultTemperatureStep
+	}
+
+	temperature := defaultTemperature
+	if current != nil {
+		temperature = *current
+	}
+	temperature += step
+	if temperature > maxTemperature {
+		temperature = maxTemperature
+	}
+	return &temperature
+}
@@ -0,0 +1,196 @@
+package aiprovider
+
+import (
+	"fmt"
+
+	"github.com/ajeet-kumar1087/ai-providers/adapters/anthropic"
+	"github.com/ajeet-kumar1087/ai-providers/adapters/openai"
+	"github.com/ajeet-kumar1087/ai-providers/internal/utils"
+	"github.com/ajeet-kumar1087/ai-providers/pricing"
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// CostEstimate describes the expected cost of a request before it is sent.
+//
+// Prompt tokens are estimated locally with the tokenizer package, so the
+// estimate may differ slightly from what the provider bills. The completion
+// cost is an upper bound assuming the model generates MaxCompletionTokens.
+type CostEstimate struct {
+	// Provider is the provider the estimate was computed for
+	Provider ProviderType `json:"provider"`
+
+	// Model is the model the estimate was computed for
+	Model string `json:"model"`
+
+	// PromptTokens is the estimated number of prompt tokens
+	PromptTokens int `json:"prompt_tokens"`
+
+	// MaxCompletionTokens is the completion token limit used for the upper bound
+	MaxCompletionTokens int `json:"max_completion_tokens"`
+
+	// PromptCost is the estimated cost of the prompt
+	PromptCost float64 `json:"prompt_cost"`
+
+	// MaxCompletionCost is the cost if the full completion budget is used
+	MaxCompletionCost float64 `json:"max_completion_cost"`
+
+	// MaxTotalCost is PromptCost plus MaxCompletionCost
+	MaxTotalCost float64 `json:"max_total_cost"`
+
+	// Currency is the currency of all cost fields (always "USD")
+	Currency string `json:"currency"`
+}
+
+// EstimateCost estimates the cost of a chat request without sending it.
+//
+// The estimate combines the local tokenizer with the default pricing registry,
+// which is useful for showing users a price before running an expensive
+// generation. If model is empty, req.Model or else the provider's default chat
+// model is used.
+//
+// Example:
+//
+//	estimate, err := EstimateCost(ProviderOpenAI, "gpt-4o", ChatRequest{
+//		Messages:  messages,
+//		MaxTokens: &[]int{500}[0],
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("Up to $%.4f\n", estimate.MaxTotalCost)
+//
+// Parameters:
+//   - provider: The provider the request would be sent to
+//   - model: The model the request would use (empty for the provider default)
+//   - req: The chat request to estimate
+//
+// Returns:
+//   - CostEstimate: Estimated prompt and maximum completion cost
+//   - error: A validation error if the provider is unsupported or the model has no known price
+func EstimateCost(provider ProviderType, model string, req ChatRequest) (CostEstimate, error) {
+	if model == "" {
+		model = req.Model
+	}
+	if model == "" {
+		model = defaultChatModel(provider)
+	}
+	promptTokens := tokenizer.EstimateMessages(req.Messages)
+	return estimateCost(provider, model, promptTokens, req.MaxTokens)
+}
+
+// EstimateCompletionCost estimates the cost of a text completion request without sending it.
+//
+// It behaves like EstimateCost but for CompletionRequest. If model is empty,
+// req.Model or else the provider's default completion model is used.
+//
+// Parameters:
+//   - provider: The provider the request would be sent to
+//   - model: The model the request would use (empty for the provider default)
+//   - req: The completion request to estimate
+//
+// Returns:
+//   - CostEstimate: Estimated prompt and maximum completion cost
+//   - error: A validation error if the provider is unsupported or the model has no known price
+func EstimateCompletionCost(provider ProviderType, model string, req CompletionRequest) (CostEstimate, error) {
+	if model == "" {
+		model = req.Model
+	}
+	if model == "" {
+		model = defaultCompletionModel(provider)
+	}
+	promptTokens := tokenizer.Estimate(req.Prompt)
+	return estimateCost(provider, model, promptTokens, req.MaxTokens)
+}
+
+// estimateCost prices the given token counts for a provider model
+func estimateCost(provider ProviderType, model string, promptTokens int, maxTokens *int) (CostEstimate, error) {
+	if err := ValidateProviderType(provider); err != nil {
+		return CostEstimate{}, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  err.Error(),
+			Provider: string(provider),
+			Wrapped:  err,
+		}
+	}
+
+	price, ok := pricing.Default().Lookup(provider, model)
+	if !ok {
+		return CostEstimate{}, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("no pricing known for model %q", model),
+			Provider: string(provider),
+		}
+	}
+
+	// Use the requested limit, clamped like the client would, or the provider default
+	completionTokens := utils.GetDefaultMaxTokens(provider)
+	if maxTokens != nil && *maxTokens > 0 {
+		completionTokens = *maxTokens
+		if limit := utils.GetProviderTokenLimit(provider); completionTokens > limit {
+			completionTokens = limit
+		}
+	}
+
+	estimate := CostEstimate{
+		Provider:            provider,
+		Model:               model,
+		PromptTokens:        promptTokens,
+		MaxCompletionTokens: completionTokens,
+		PromptCost:          price.Cost(promptTokens, 0),
+		MaxCompletionCost:   price.Cost(0, completionTokens),
+		Currency:            pricing.Currency,
+	}
+	estimate.MaxTotalCost = estimate.PromptCost + estimate.MaxCompletionCost
+
+	return estimate, nil
+}
+
+// defaultChatModel returns the model an adapter uses for chat requests
+func defaultChatModel(provider ProviderType) string {
+	switch provider {
+	case ProviderOpenAI:
+		return openai.DefaultChatModel
+	case ProviderAnthropic:
+		return anthropic.DefaultChatModel
+	default:
+		return ""
+	}
+}
+
+// defaultCompletionModel returns the model an adapter uses for completion requests
+func defaultCompletionModel(provider ProviderType) string {
+	switch provider {
+	case ProviderOpenAI:
+		return openai.DefaultModel
+	case ProviderAnthropic:
+		return anthropic.DefaultModel
+	default:
+		return ""
+	}
+}
+
+// ResponseCost prices the usage of a response sent with c. Clients created
+// by NewClient, and the ReloadableClient and ShadowClient wrapping them, use
+// their own pricing registry, which includes Config.PricingFile overrides;
+// other implementations use the default registry.
+//
+// Parameters:
+//   - c: The client that sent the request
+//   - metadata: The response metadata, for its provider and model
+//   - usage: The response's token usage
+//
+// Returns:
+//   - float64: The cost in US dollars, or 0 if the model has no known price
+func ResponseCost(c Client, metadata ResponseMetadata, usage Usage) float64 {
+	switch impl := c.(type) {
+	case *client:
+		return impl.cost(metadata.Model, usage)
+	case *ReloadableClient:
+		current, release := impl.acquire()
+		defer release()
+		return ResponseCost(current, metadata, usage)
+	case *ShadowClient:
+		return ResponseCost(impl.Client, metadata, usage)
+	}
+	return pricing.Default().Cost(metadata.Provider, metadata.Model, usage)
+}
@@ -0,0 +1,96 @@
+package aiprovider
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/pricing"
+)
+
+// Test cost estimation without sending requests
+func TestEstimateCost(t *testing.T) {
+	req := ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "You are a helpful assistant."},
+			{Role: "user", Content: "Explain quantum computing in simple terms."},
+		},
+		MaxTokens: intPtr(1000),
+	}
+
+	estimate, err := EstimateCost(ProviderOpenAI, "gpt-4o-2024-08-06", req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if estimate.PromptTokens <= 0 {
+		t.Errorf("Expected positive prompt token estimate, got %d", estimate.PromptTokens)
+	}
+	if estimate.MaxCompletionTokens != 1000 {
+		t.Errorf("Expected 1000 max completion tokens, got %d", estimate.MaxCompletionTokens)
+	}
+	// gpt-4o output is $0.01 per 1K tokens
+	if math.Abs(estimate.MaxCompletionCost-0.01) > 1e-9 {
+		t.Errorf("Expected max completion cost 0.01, got %f", estimate.MaxCompletionCost)
+	}
+	if math.Abs(estimate.MaxTotalCost-(estimate.PromptCost+estimate.MaxCompletionCost)) > 1e-12 {
+		t.Errorf("MaxTotalCost should be the sum of prompt and completion cost")
+	}
+	if estimate.Currency != "USD" {
+		t.Errorf("Expected USD currency, got %q", estimate.Currency)
+	}
+}
+
+func TestEstimateCostDefaults(t *testing.T) {
+	estimate, err := EstimateCompletionCost(ProviderAnthropic, "", CompletionRequest{Prompt: "Hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if estimate.Model != "claude-3-haiku-20240307" {
+		t.Errorf("Expected default Anthropic model, got %q", estimate.Model)
+	}
+	if estimate.MaxCompletionTokens != 1024 {
+		t.Errorf("Expected default max tokens 1024, got %d", estimate.MaxCompletionTokens)
+	}
+}
+
+func TestEstimateCostErrors(t *testing.T) {
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}
+
+	_, err := EstimateCost(ProviderOpenAI, "unknown-model", req)
+	if err == nil {
+		t.Fatalf("Expected error for unknown model")
+	}
+	if customErr, ok := err.(*Error); !ok || customErr.Type != ErrorTypeValidation {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+
+	if _, err := EstimateCost(ProviderType("unsupported"), "gpt-4", req); err == nil {
+		t.Errorf("Expected error for unsupported provider")
+	}
+}
+
+// Test response costs use the pricing registry of the client, also when wrapped
+func TestResponseCost(t *testing.T) {
+	c := newMockClient(ProviderOpenAI, &mockAdapter{})
+	c.pricing = pricing.Default().Clone()
+	c.pricing.Set(ProviderOpenAI, "custom-model", pricing.Price{InputPer1K: 1, OutputPer1K: 2})
+
+	metadata := ResponseMetadata{Provider: ProviderOpenAI, Model: "custom-model"}
+	usage := Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500}
+	if cost := ResponseCost(c, metadata, usage); cost != 2 {
+		t.Errorf("Expected the client's price, got %v", cost)
+	}
+
+	shadow, err := NewShadowClient(c, newMockClient(ProviderOpenAI, &mockAdapter{}), ShadowOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cost := ResponseCost(shadow, metadata, usage); cost != 2 {
+		t.Errorf("Expected the primary client's price, got %v", cost)
+	}
+
+	// Other implementations fall back to the default registry
+	if cost := ResponseCost(&blockingClient{}, metadata, usage); cost != 0 {
+		t.Errorf("Expected no price from the default registry, got %v", cost)
+	}
+}
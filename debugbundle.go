@@ -0,0 +1,144 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+)
+
+// DebugBundle is a snapshot of a failed request, captured with
+// Config.DebugBundles and returned by Error.DebugBundle.
+//
+// A bundle holds the request as sent to the provider adapter, after
+// validation, experiments and profiles were applied, together with every
+// HTTP attempt it made. Credentials are redacted, but prompts and response
+// bodies are kept in full so the failure can be reproduced: treat bundles as
+// sensitive. Bundles encode to JSON, and the "aiprovider replay" command
+// replays them against the provider or against the recorded responses.
+type DebugBundle struct {
+	// Provider is the provider the request was sent to
+	Provider ProviderType `json:"provider"`
+
+	// CompletionRequest is the failed completion request, if any
+	CompletionRequest *CompletionRequest `json:"completion_request,omitempty"`
+
+	// ChatRequest is the failed chat request, if any
+	ChatRequest *ChatRequest `json:"chat_request,omitempty"`
+
+	// Exchanges are the HTTP attempts made for the request, in order
+	Exchanges []DebugEntry `json:"exchanges,omitempty"`
+
+	// Error is the message of the returned error
+	Error string `json:"error"`
+
+	// StartedAt is when the request was sent to the adapter
+	StartedAt time.Time `json:"started_at"`
+
+	// Duration is the time until the request failed
+	Duration time.Duration `json:"duration"`
+}
+
+// Replay sends the bundle's request through client again.
+//
+// Example:
+//
+//	var apiErr *aiprovider.Error
+//	if errors.As(err, &apiErr) && apiErr.DebugBundle() != nil {
+//		text, err := apiErr.DebugBundle().Replay(ctx, client)
+//		...
+//	}
+//
+// Returns:
+//   - string: The response text if the request now succeeds
+//   - error: The error of the replayed request
+func (b *DebugBundle) Replay(ctx context.Context, client Client) (string, error) {
+	switch {
+	case b.ChatRequest != nil:
+		resp, err := client.ChatComplete(ctx, *b.ChatRequest)
+		if err != nil {
+			return "", err
+		}
+		return resp.Message.Content, nil
+	case b.CompletionRequest != nil:
+		resp, err := client.Complete(ctx, *b.CompletionRequest)
+		if err != nil {
+			return "", err
+		}
+		return resp.Text, nil
+	default:
+		return "", fmt.Errorf("debug bundle has no request")
+	}
+}
+
+// ReplayHandler returns an HTTP handler that answers requests with the
+// recorded responses of the bundle's exchanges in order, repeating the last
+// one. Serving it and pointing a client's Config.BaseURL at it replays the
+// failure, including retries, without calling the provider. Exchanges that
+// failed without a response abort the connection.
+func (b *DebugBundle) ReplayHandler() http.Handler {
+	var mu sync.Mutex
+	next := 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if len(b.Exchanges) == 0 {
+			mu.Unlock()
+			http.Error(w, "debug bundle has no recorded exchanges", http.StatusBadGateway)
+			return
+		}
+		exchange := b.Exchanges[next]
+		if next < len(b.Exchanges)-1 {
+			next++
+		}
+		mu.Unlock()
+
+		if exchange.Status == 0 {
+			panic(http.ErrAbortHandler)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(exchange.Status)
+		w.Write([]byte(exchange.ResponseBody))
+	})
+}
+
+// DebugBundle returns the snapshot of the failed request captured when
+// Config.DebugBundles is enabled, or nil
+func (e *Error) DebugBundle() *DebugBundle {
+	return e.bundle
+}
+
+// captureExchanges returns a context recording the HTTP exchanges of
+// requests made with it when debug bundles are enabled, and the capture,
+// which is nil otherwise
+func (c *client) captureExchanges(ctx context.Context) (context.Context, *httputil.Capture) {
+	if !c.config.DebugBundles {
+		return ctx, nil
+	}
+	return httputil.WithCapture(ctx)
+}
+
+// attachDebugBundle returns err as an *Error carrying bundle, completed with
+// the captured exchanges, or err unchanged if nothing was captured
+func (c *client) attachDebugBundle(err error, capture *httputil.Capture, bundle DebugBundle) error {
+	if capture == nil {
+		return err
+	}
+	bundle.Provider = c.provider
+	bundle.Exchanges = capture.Entries()
+	bundle.Error = err.Error()
+	bundle.Duration = time.Since(bundle.StartedAt)
+
+	var withBundle *Error
+	if e, ok := err.(*Error); ok {
+		copied := *e
+		withBundle = &copied
+	} else {
+		withBundle = c.toError(err)
+		withBundle.Wrapped = err
+	}
+	withBundle.bundle = &bundle
+	return withBundle
+}
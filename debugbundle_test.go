@@ -0,0 +1,122 @@
+package aiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugBundle(t *testing.T) {
+	errorBody := `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: too large"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errorBody))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ProviderAnthropic, Config{
+		APIKey:       "sk-ant-test-key-1234567890",
+		BaseURL:      server.URL,
+		DebugBundles: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+	_, err = client.ChatComplete(context.Background(), req)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.DebugBundle() == nil {
+		t.Fatalf("Expected an error with a debug bundle, got %v", err)
+	}
+	bundle := apiErr.DebugBundle()
+	if bundle.Provider != ProviderAnthropic || bundle.ChatRequest == nil || bundle.ChatRequest.Messages[0].Content != "Hello" {
+		t.Errorf("Unexpected bundle: %+v", bundle)
+	}
+	if len(bundle.Exchanges) != 1 || bundle.Exchanges[0].Status != 400 || bundle.Exchanges[0].ResponseBody != errorBody {
+		t.Fatalf("Expected the failed exchange to be captured, got %+v", bundle.Exchanges)
+	}
+	if strings.Contains(bundle.Exchanges[0].Headers["X-Api-Key"], "sk-ant") {
+		t.Errorf("Expected the API key redacted, got %v", bundle.Exchanges[0].Headers)
+	}
+	if !strings.Contains(bundle.Exchanges[0].RequestBody, `"Hello"`) {
+		t.Errorf("Expected the provider payload, got %s", bundle.Exchanges[0].RequestBody)
+	}
+
+	// The bundle survives encoding and replays against the recorded responses
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded DebugBundle
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	replayServer := httptest.NewServer(decoded.ReplayHandler())
+	defer replayServer.Close()
+	replayClient, err := NewClient(ProviderAnthropic, Config{APIKey: "sk-ant-REDACTED", BaseURL: replayServer.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer replayClient.Close()
+
+	_, replayErr := decoded.Replay(context.Background(), replayClient)
+	if replayErr == nil || replayErr.Error() != bundle.Error {
+		t.Errorf("Expected the replay to reproduce %q, got %v", bundle.Error, replayErr)
+	}
+}
+
+func TestDebugBundle_Disabled(t *testing.T) {
+	adapter := &mockAdapter{err: NewError(ErrorTypeProvider, "anthropic", "overloaded")}
+	client := newMockClient(ProviderAnthropic, adapter)
+
+	_, err := client.ChatComplete(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.DebugBundle() != nil {
+		t.Errorf("Expected no debug bundle unless enabled, got %v", err)
+	}
+}
+
+func TestDebugBundle_RedactReasoning(t *testing.T) {
+	errorBody := `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errorBody))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ProviderAnthropic, Config{
+		APIKey:          "sk-ant-test-key-1234567890",
+		BaseURL:         server.URL,
+		DebugBundles:    true,
+		RedactReasoning: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	history := []Message{
+		{Role: "user", Content: "What is 17 * 3?"},
+		{Role: "assistant", Content: "51", Reasoning: "17 * 3 = 51."},
+		{Role: "user", Content: "And 17 * 4?"},
+	}
+	_, err = client.ChatComplete(context.Background(), ChatRequest{Messages: history, ReasoningBudget: intPtr(1024)})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.DebugBundle() == nil {
+		t.Fatalf("Expected an error with a debug bundle, got %v", err)
+	}
+	if bundle := apiErr.DebugBundle(); bundle.ChatRequest.Messages[1].Reasoning != "" {
+		t.Errorf("Expected reasoning removed from the bundled request, got %+v", bundle.ChatRequest.Messages)
+	}
+	if history[1].Reasoning == "" {
+		t.Error("Expected the caller's messages to be left unchanged")
+	}
+}
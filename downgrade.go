@@ -0,0 +1,141 @@
+package aiprovider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/adapters/anthropic"
+	"github.com/ajeet-kumar1087/ai-providers/adapters/openai"
+)
+
+// DefaultDowngradeCooldown is how long requests are downgraded after a quota
+// error when Config.DowngradeCooldown is zero
+const DefaultDowngradeCooldown = 15 * time.Minute
+
+// quotaErrorCodes are provider error codes reporting exhausted quota or a
+// billing problem, as opposed to a short-term rate limit
+var quotaErrorCodes = map[string]bool{
+	"insufficient_quota":         true,
+	"billing_hard_limit_reached": true,
+	"billing_not_active":         true,
+}
+
+// quotaErrorMessages are fragments of quota error messages from providers
+// that report them without a dedicated code
+var quotaErrorMessages = []string{
+	"credit balance is too low",
+	"exceeded your current quota",
+}
+
+// IsQuotaError reports whether err is a provider error for exhausted quota
+// or a billing problem. Unlike rate limits these do not clear on retry, so
+// Config.DowngradeModels uses them to switch to a backup model.
+func IsQuotaError(err error) bool {
+	var code, message string
+	var e *Error
+	var openaiErr *openai.Error
+	var anthropicErr *anthropic.Error
+	switch {
+	case errors.As(err, &e):
+		code, message = e.Code, e.Message
+	case errors.As(err, &openaiErr):
+		code, message = openaiErr.Code, openaiErr.Message
+	case errors.As(err, &anthropicErr):
+		code, message = anthropicErr.Code, anthropicErr.Message
+	default:
+		return false
+	}
+
+	if quotaErrorCodes[code] {
+		return true
+	}
+	message = strings.ToLower(message)
+	for _, fragment := range quotaErrorMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// downgradeModel returns the model to send a request for model to: its
+// downgrade model while a cool-down is active, followed through further
+// downgrades, with a warning for the replacement. Cool-downs that have passed
+// are ended and reported to Config.OnDowngrade.
+func (c *client) downgradeModel(model string) (string, []Warning) {
+	if len(c.config.DowngradeModels) == 0 || model == "" {
+		return model, nil
+	}
+
+	var ended []DowngradeEvent
+	requested := model
+	now := time.Now()
+	c.mu.Lock()
+	// Each model is visited at most once, so a cycle of downgrades ends
+	for seen := map[string]bool{}; !seen[model]; {
+		seen[model] = true
+		until, ok := c.downgrades[model]
+		if !ok {
+			break
+		}
+		if !now.Before(until) {
+			delete(c.downgrades, model)
+			ended = append(ended, DowngradeEvent{Model: model, Replacement: c.config.DowngradeModels[model], Until: until})
+			break
+		}
+		model = c.config.DowngradeModels[model]
+	}
+	c.mu.Unlock()
+
+	if c.config.OnDowngrade != nil {
+		for _, event := range ended {
+			c.config.OnDowngrade(event)
+		}
+	}
+	if model == requested {
+		return model, nil
+	}
+	return model, []Warning{{
+		Code:      WarningDowngraded,
+		Parameter: "model",
+		Message:   fmt.Sprintf("model %s is out of quota; sent to %s instead", requested, model),
+	}}
+}
+
+// noteQuotaError starts a cool-down for model if err reports exhausted quota
+// and model has a downgrade model, so later requests are sent there instead.
+// Only the start of a cool-down is reported to Config.OnDowngrade; further
+// quota errors during it extend it.
+func (c *client) noteQuotaError(model string, err error) {
+	replacement, ok := c.config.DowngradeModels[model]
+	if !ok || !IsQuotaError(err) {
+		return
+	}
+
+	cooldown := c.config.DowngradeCooldown
+	if cooldown <= 0 {
+		cooldown = DefaultDowngradeCooldown
+	}
+	until := time.Now().Add(cooldown)
+
+	c.mu.Lock()
+	previous, ok := c.downgrades[model]
+	active := ok && time.Now().Before(previous)
+	if c.downgrades == nil {
+		c.downgrades = make(map[string]time.Time)
+	}
+	c.downgrades[model] = until
+	c.mu.Unlock()
+
+	if !active && c.config.OnDowngrade != nil {
+		c.config.OnDowngrade(DowngradeEvent{
+			Model:       model,
+			Replacement: replacement,
+			Active:      true,
+			Until:       until,
+			Reason:      err.Error(),
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/adapters/anthropic"
+	"github.com/ajeet-kumar1087/ai-providers/adapters/openai"
+)
+
+func TestIsQuotaError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"openai insufficient quota", &openai.Error{Type: "rate_limit", Code: "insufficient_quota", Message: "You exceeded your current quota"}, true},
+		{"openai billing limit", &openai.Error{Type: "validation", Code: "billing_hard_limit_reached"}, true},
+		{"anthropic credit balance", &anthropic.Error{Type: "validation", Code: "invalid_request_error", Message: "Your credit balance is too low to access the Anthropic API"}, true},
+		{"wrapped", fmt.Errorf("request failed: %w", &Error{Type: ErrorTypeRateLimit, Code: "insufficient_quota"}), true},
+		{"rate limit", &openai.Error{Type: "rate_limit", Code: "rate_limit_exceeded"}, false},
+		{"plain error", errors.New("insufficient_quota"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsQuotaError(tt.err); got != tt.want {
+				t.Errorf("IsQuotaError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDowngradeOnQuotaError(t *testing.T) {
+	adapter := &mockAdapter{
+		chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Hi"}},
+		err:      &openai.Error{Type: "rate_limit", Code: "insufficient_quota", Provider: "openai", Message: "You exceeded your current quota"},
+	}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.DowngradeModels = map[string]string{"gpt-4o": "gpt-4o-mini"}
+
+	var events []DowngradeEvent
+	c.config.OnDowngrade = func(event DowngradeEvent) {
+		events = append(events, event)
+	}
+
+	req := ChatRequest{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Hello"}}}
+	if _, err := c.ChatComplete(context.Background(), req); err == nil {
+		t.Fatal("Expected the quota error")
+	}
+	if len(events) != 1 || !events[0].Active || events[0].Replacement != "gpt-4o-mini" || events[0].Reason == "" {
+		t.Fatalf("Expected a downgrade start event, got %+v", events)
+	}
+	if remaining := time.Until(events[0].Until); remaining <= 0 || remaining > DefaultDowngradeCooldown {
+		t.Errorf("Expected the default cool-down, got %v", remaining)
+	}
+
+	adapter.err = nil
+	resp, err := c.ChatComplete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := adapter.chatRequests[1].Model; got != "gpt-4o-mini" {
+		t.Errorf("Expected the request to be downgraded, got model %q", got)
+	}
+	if len(resp.Metadata.Warnings) != 1 || resp.Metadata.Warnings[0].Code != WarningDowngraded {
+		t.Errorf("Expected a downgrade warning, got %+v", resp.Metadata.Warnings)
+	}
+
+	// Once the cool-down has passed, requests go to the original model again
+	c.mu.Lock()
+	c.downgrades["gpt-4o"] = time.Now().Add(-time.Second)
+	c.mu.Unlock()
+	if _, err := c.ChatComplete(context.Background(), req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := adapter.chatRequests[2].Model; got != "gpt-4o" {
+		t.Errorf("Expected the original model after the cool-down, got %q", got)
+	}
+	if len(events) != 2 || events[1].Active {
+		t.Errorf("Expected a downgrade end event, got %+v", events)
+	}
+}
+
+func TestDowngradeIgnoresOtherErrors(t *testing.T) {
+	adapter := &mockAdapter{err: &openai.Error{Type: "rate_limit", Code: "rate_limit_exceeded", Provider: "openai"}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.DowngradeModels = map[string]string{"gpt-4o": "gpt-4o-mini"}
+
+	req := ChatRequest{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Hello"}}}
+	c.ChatComplete(context.Background(), req)
+	c.ChatComplete(context.Background(), req)
+	if got := adapter.chatRequests[1].Model; got != "gpt-4o" {
+		t.Errorf("Expected rate limits not to downgrade, got model %q", got)
+	}
+}
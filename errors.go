@@ -68,6 +68,9 @@ type Error struct {
 
 	// TokenCount contains the token count for token limit errors (optional)
 	TokenCount *int `json:"token_count,omitempty"`
+
+	// bundle is the snapshot of the failed request, see DebugBundle
+	bundle *DebugBundle
 }
 
 // Error implements the standard Go error interface.
@@ -0,0 +1,74 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Assertion checks a response deterministically.
+type Assertion interface {
+	// Check returns an empty string if the response passes, or the reason it fails
+	Check(response string) string
+}
+
+// AssertionFunc adapts a function to the Assertion interface
+type AssertionFunc func(response string) string
+
+// Check calls f
+func (f AssertionFunc) Check(response string) string {
+	return f(response)
+}
+
+// Contains requires the response to contain substr, ignoring case
+func Contains(substr string) Assertion {
+	return AssertionFunc(func(response string) string {
+		if strings.Contains(strings.ToLower(response), strings.ToLower(substr)) {
+			return ""
+		}
+		return fmt.Sprintf("expected response to contain %q", substr)
+	})
+}
+
+// NotContains requires the response not to contain substr, ignoring case
+func NotContains(substr string) Assertion {
+	return AssertionFunc(func(response string) string {
+		if !strings.Contains(strings.ToLower(response), strings.ToLower(substr)) {
+			return ""
+		}
+		return fmt.Sprintf("expected response not to contain %q", substr)
+	})
+}
+
+// Matches requires the response to match a regular expression; it panics if
+// pattern does not compile, like regexp.MustCompile
+func Matches(pattern string) Assertion {
+	re := regexp.MustCompile(pattern)
+	return AssertionFunc(func(response string) string {
+		if re.MatchString(response) {
+			return ""
+		}
+		return fmt.Sprintf("expected response to match %s", pattern)
+	})
+}
+
+// MaxWords requires the response to have at most n words
+func MaxWords(n int) Assertion {
+	return AssertionFunc(func(response string) string {
+		if words := len(strings.Fields(response)); words > n {
+			return fmt.Sprintf("expected at most %d words, got %d", n, words)
+		}
+		return ""
+	})
+}
+
+// ValidJSON requires the response to be valid JSON, allowing surrounding whitespace
+func ValidJSON() Assertion {
+	return AssertionFunc(func(response string) string {
+		if json.Valid([]byte(strings.TrimSpace(response))) {
+			return ""
+		}
+		return "expected response to be valid JSON"
+	})
+}
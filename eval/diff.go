@@ -0,0 +1,316 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+const (
+	// DiffEqual marks text both responses share
+	DiffEqual = "equal"
+
+	// DiffDelete marks text only the first response has
+	DiffDelete = "delete"
+
+	// DiffInsert marks text only the second response has
+	DiffInsert = "insert"
+)
+
+// DiffSegment is a run of words with the same diff operation.
+type DiffSegment struct {
+	// Op is DiffEqual, DiffDelete or DiffInsert
+	Op string `json:"op"`
+
+	// Text is the segment's text, including whitespace
+	Text string `json:"text"`
+}
+
+// DiffSide is one target's response in a ResponseDiff.
+type DiffSide struct {
+	// Target is the target name
+	Target string `json:"target"`
+
+	// Model is the model that served the request, as reported by the provider
+	Model string `json:"model,omitempty"`
+
+	// Response is the response text
+	Response string `json:"response"`
+
+	// Latency is the response time
+	Latency time.Duration `json:"latency"`
+
+	// Usage is the token usage
+	Usage aiprovider.Usage `json:"usage"`
+
+	// Cost is the estimated cost in USD
+	Cost float64 `json:"cost_usd"`
+}
+
+// ResponseDiff compares the responses of two targets to the same case.
+// Deltas are the second target's value minus the first's.
+type ResponseDiff struct {
+	// A is the first target's response
+	A DiffSide `json:"a"`
+
+	// B is the second target's response
+	B DiffSide `json:"b"`
+
+	// Segments is the word-level diff from A's response to B's
+	Segments []DiffSegment `json:"segments"`
+
+	// Similarity is the share of words the responses have in common, from
+	// 0 (nothing) to 1 (identical)
+	Similarity float64 `json:"similarity"`
+
+	// TokenDelta is the difference in token usage
+	TokenDelta aiprovider.Usage `json:"token_delta"`
+
+	// CostDelta is the difference in estimated cost in USD
+	CostDelta float64 `json:"cost_delta_usd"`
+
+	// LatencyDelta is the difference in response time
+	LatencyDelta time.Duration `json:"latency_delta"`
+}
+
+// Identical reports whether both responses have the same text
+func (d *ResponseDiff) Identical() bool {
+	return d.A.Response == d.B.Response
+}
+
+// Diff sends the same case to two targets in parallel and compares their
+// responses, e.g. the current and a candidate model before a migration.
+//
+// Example:
+//
+//	diff, err := eval.Diff(ctx, eval.Case{Prompt: "Summarize our refund policy: ..."},
+//		eval.Target{Client: openaiClient, Model: "gpt-4o"},
+//		eval.Target{Client: anthropicClient, Model: "claude-3-5-sonnet-20241022"},
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	diff.WriteText(os.Stdout)
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - c: The case sent to both targets; assertions and criteria are ignored
+//   - a: The first target, typically the current model
+//   - b: The second target, typically the candidate
+//
+// Returns:
+//   - *ResponseDiff: Both responses, their text diff and the token, cost and latency deltas
+//   - error: A validation error for an invalid case or target, or the first request error
+func Diff(ctx context.Context, c Case, a, b Target) (*ResponseDiff, error) {
+	if strings.TrimSpace(c.Prompt) == "" && len(c.Messages) == 0 {
+		return nil, fmt.Errorf("case %s has no prompt", c.Name)
+	}
+	targets := []Target{a, b}
+	for i, name := range []string{"a", "b"} {
+		if targets[i].Client == nil {
+			return nil, fmt.Errorf("target %s has no client", name)
+		}
+		if targets[i].Name == "" {
+			targets[i].Name = targets[i].Model
+		}
+		if targets[i].Name == "" {
+			targets[i].Name = name
+		}
+	}
+
+	sides := make([]DiffSide, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := time.Now()
+			text, usage, metadata, err := send(ctx, c, targets[i])
+			if err != nil {
+				errs[i] = fmt.Errorf("target %s failed: %w", targets[i].Name, err)
+				return
+			}
+			sides[i] = DiffSide{
+				Target:   targets[i].Name,
+				Model:    metadata.Model,
+				Response: text,
+				Latency:  time.Since(start),
+				Usage:    usage,
+				Cost:     aiprovider.ResponseCost(targets[i].Client, metadata, usage),
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diff := &ResponseDiff{
+		A: sides[0],
+		B: sides[1],
+		TokenDelta: aiprovider.Usage{
+			PromptTokens:     sides[1].Usage.PromptTokens - sides[0].Usage.PromptTokens,
+			CompletionTokens: sides[1].Usage.CompletionTokens - sides[0].Usage.CompletionTokens,
+			TotalTokens:      sides[1].Usage.TotalTokens - sides[0].Usage.TotalTokens,
+		},
+		CostDelta:    sides[1].Cost - sides[0].Cost,
+		LatencyDelta: sides[1].Latency - sides[0].Latency,
+	}
+	diff.Segments, diff.Similarity = diffWords(sides[0].Response, sides[1].Response)
+	return diff, nil
+}
+
+// WriteText writes the metrics of both responses as an aligned table,
+// followed by the text diff with deletions as [-text-] and insertions as
+// {+text+}
+func (d *ResponseDiff) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\t%s\t%s\tDELTA\n", d.A.Target, d.B.Target)
+	fmt.Fprintf(tw, "LATENCY\t%v\t%v\t%s\n", d.A.Latency.Round(time.Millisecond), d.B.Latency.Round(time.Millisecond), signedDuration(d.LatencyDelta.Round(time.Millisecond)))
+	fmt.Fprintf(tw, "PROMPT TOKENS\t%d\t%d\t%+d\n", d.A.Usage.PromptTokens, d.B.Usage.PromptTokens, d.TokenDelta.PromptTokens)
+	fmt.Fprintf(tw, "COMPLETION TOKENS\t%d\t%d\t%+d\n", d.A.Usage.CompletionTokens, d.B.Usage.CompletionTokens, d.TokenDelta.CompletionTokens)
+	fmt.Fprintf(tw, "TOTAL TOKENS\t%d\t%d\t%+d\n", d.A.Usage.TotalTokens, d.B.Usage.TotalTokens, d.TokenDelta.TotalTokens)
+	fmt.Fprintf(tw, "COST (USD)\t%.4f\t%.4f\t%+.4f\n", d.A.Cost, d.B.Cost, d.CostDelta)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nSIMILARITY %.0f%%\n", 100*d.Similarity)
+	for _, segment := range d.Segments {
+		switch segment.Op {
+		case DiffDelete:
+			fmt.Fprintf(&b, "[-%s-]", segment.Text)
+		case DiffInsert:
+			fmt.Fprintf(&b, "{+%s+}", segment.Text)
+		default:
+			b.WriteString(segment.Text)
+		}
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// signedDuration formats a duration with its sign, e.g. "+1.5s"
+func signedDuration(d time.Duration) string {
+	if d < 0 {
+		return d.String()
+	}
+	return "+" + d.String()
+}
+
+// diffWords returns the word-level diff of two texts, as the longest common
+// subsequence of their words, and the share of words they have in common
+func diffWords(a, b string) ([]DiffSegment, float64) {
+	x, y := splitWords(a), splitWords(b)
+	if len(x)+len(y) == 0 {
+		return nil, 1
+	}
+	// Words are compared without their whitespace
+	kx, ky := trimWords(x), trimWords(y)
+	same := func(i, j int) bool {
+		return kx[i] == ky[j]
+	}
+
+	// Common prefix and suffix are equal without the quadratic table
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && same(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && same(len(x)-1-suffix, len(y)-1-suffix) {
+		suffix++
+	}
+	nx, ny := len(x)-prefix-suffix, len(y)-prefix-suffix
+
+	// lcs[i][j] is the length of the longest common subsequence of the
+	// words from prefix+i in x and prefix+j in y, up to the suffix
+	lcs := make([][]int32, nx+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, ny+1)
+	}
+	for i := nx - 1; i >= 0; i-- {
+		for j := ny - 1; j >= 0; j-- {
+			if same(prefix+i, prefix+j) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var segments []DiffSegment
+	equal := prefix + suffix
+	add := func(op, text string) {
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += text
+			return
+		}
+		segments = append(segments, DiffSegment{Op: op, Text: text})
+	}
+	for _, word := range x[:prefix] {
+		add(DiffEqual, word)
+	}
+	i, j := 0, 0
+	for i < nx || j < ny {
+		switch {
+		case i < nx && j < ny && same(prefix+i, prefix+j):
+			add(DiffEqual, x[prefix+i])
+			equal++
+			i++
+			j++
+		case j == ny || (i < nx && lcs[i+1][j] >= lcs[i][j+1]):
+			add(DiffDelete, x[prefix+i])
+			i++
+		default:
+			add(DiffInsert, y[prefix+j])
+			j++
+		}
+	}
+	for _, word := range x[len(x)-suffix:] {
+		add(DiffEqual, word)
+	}
+	return segments, 2 * float64(equal) / float64(len(x)+len(y))
+}
+
+// splitWords splits text into words, each with its trailing whitespace, so
+// joining them restores the text; leading whitespace is a word of its own
+func splitWords(text string) []string {
+	var words []string
+	start := 0
+	inSpace := true
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if !space && inSpace && i > start {
+			words = append(words, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+// trimWords returns words without their whitespace
+func trimWords(words []string) []string {
+	trimmed := make([]string, len(words))
+	for i, word := range words {
+		trimmed[i] = strings.TrimSpace(word)
+	}
+	return trimmed
+}
@@ -0,0 +1,80 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	current := &scriptedClient{chat: true, answers: map[string]string{"Capital of France?": "The capital is Paris."}}
+	candidate := &scriptedClient{answers: map[string]string{"Capital of France?": "The capital of France is Paris."}}
+
+	diff, err := Diff(context.Background(), Case{Prompt: "Capital of France?"}, Target{Name: "current", Client: current}, Target{Client: candidate})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff.A.Target != "current" || diff.B.Target != "b" || diff.Identical() {
+		t.Errorf("Unexpected sides %+v and %+v", diff.A, diff.B)
+	}
+	if diff.TokenDelta.TotalTokens != 10 {
+		t.Errorf("Expected the completion target to use 10 more tokens, got %+v", diff.TokenDelta)
+	}
+
+	want := []DiffSegment{
+		{Op: DiffEqual, Text: "The capital "},
+		{Op: DiffInsert, Text: "of France "},
+		{Op: DiffEqual, Text: "is Paris."},
+	}
+	if len(diff.Segments) != len(want) {
+		t.Fatalf("Expected segments %+v, got %+v", want, diff.Segments)
+	}
+	for i := range want {
+		if diff.Segments[i] != want[i] {
+			t.Errorf("Segment %d: expected %+v, got %+v", i, want[i], diff.Segments[i])
+		}
+	}
+	if diff.Similarity != 8.0/10 {
+		t.Errorf("Expected a similarity of 0.8, got %v", diff.Similarity)
+	}
+
+	var text bytes.Buffer
+	if err := diff.WriteText(&text); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(text.String(), "The capital {+of France +}is Paris.") || !strings.Contains(text.String(), "+10") {
+		t.Errorf("Unexpected text:\n%s", text.String())
+	}
+
+	if _, err := Diff(context.Background(), Case{Prompt: "Unknown"}, Target{Client: current}, Target{Client: candidate}); err == nil {
+		t.Error("Expected the request error")
+	}
+	if _, err := Diff(context.Background(), Case{Prompt: "Hi"}, Target{Client: current}, Target{}); err == nil {
+		t.Error("Expected an error for a target without a client")
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		a, b       string
+		similarity float64
+		rendered   string
+	}{
+		{"", "", 1, ""},
+		{"same text", "same text", 1, "=same text"},
+		{"one two", "three", 0, "-one two+three"},
+		{"a b c d", "a x c d", 0.75, "=a -b +x =c d"},
+	}
+
+	for _, tt := range tests {
+		segments, similarity := diffWords(tt.a, tt.b)
+		var rendered []string
+		for _, segment := range segments {
+			rendered = append(rendered, map[string]string{DiffEqual: "=", DiffDelete: "-", DiffInsert: "+"}[segment.Op]+segment.Text)
+		}
+		if similarity != tt.similarity || strings.Join(rendered, "") != tt.rendered {
+			t.Errorf("diffWords(%q, %q) = %q, %v; expected %q, %v", tt.a, tt.b, strings.Join(rendered, ""), similarity, tt.rendered, tt.similarity)
+		}
+	}
+}
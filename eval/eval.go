@@ -0,0 +1,336 @@
+// Package eval runs prompt test cases across providers and models and compares the results.
+//
+// A Case is a prompt with deterministic assertions and, optionally, criteria
+// for a model-graded (LLM-as-judge) score. Run sends every case to every
+// Target and returns a Report with per-response results and a per-target
+// summary of pass rate, score, latency, tokens and cost.
+//
+// Example:
+//
+//	cases := []eval.Case{
+//		{
+//			Name:       "capital",
+//			Prompt:     "What is the capital of France? Answer in one word.",
+//			Assertions: []eval.Assertion{eval.Contains("Paris"), eval.MaxWords(3)},
+//		},
+//		{
+//			Name:     "apology",
+//			Prompt:   "Write a two-sentence apology for a late delivery.",
+//			Criteria: "Polite, takes responsibility, exactly two sentences.",
+//		},
+//	}
+//	targets := []eval.Target{
+//		{Name: "haiku", Client: anthropicClient, Model: "claude-3-haiku-20240307"},
+//		{Name: "sonnet", Client: anthropicClient, Model: "claude-3-5-sonnet-20241022"},
+//	}
+//
+//	report, err := eval.Run(ctx, cases, targets, eval.Options{
+//		Judge: &eval.Judge{Client: anthropicClient},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	report.WriteTable(os.Stdout)
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+	"github.com/ajeet-kumar1087/ai-providers/prompt"
+)
+
+const (
+	// DefaultConcurrency is the number of cases run in parallel
+	DefaultConcurrency = 4
+
+	// DefaultPassScore is the minimum judge score for a case to pass
+	DefaultPassScore = 0.7
+)
+
+// Case is a prompt test case.
+type Case struct {
+	// Name identifies the case in the report
+	Name string `json:"name"`
+
+	// Prompt is the user prompt (required unless Messages is set)
+	Prompt string `json:"prompt,omitempty"`
+
+	// System is an optional system message sent before Prompt
+	System string `json:"system,omitempty"`
+
+	// Messages is a full conversation, used instead of System and Prompt (optional)
+	Messages []aiprovider.Message `json:"messages,omitempty"`
+
+	// Assertions must all pass for the case to pass (optional)
+	Assertions []Assertion `json:"-"`
+
+	// Criteria describes a good response for the judge; cases without
+	// criteria are not judged (optional)
+	Criteria string `json:"criteria,omitempty"`
+
+	// MaxTokens limits the response length (optional)
+	MaxTokens *int `json:"max_tokens,omitempty"`
+}
+
+// messages returns the conversation sent for the case
+func (c Case) messages() []aiprovider.Message {
+	if len(c.Messages) > 0 {
+		return c.Messages
+	}
+	var messages []aiprovider.Message
+	if c.System != "" {
+		messages = append(messages, aiprovider.Message{Role: "system", Content: c.System})
+	}
+	return append(messages, aiprovider.Message{Role: "user", Content: c.Prompt})
+}
+
+// prompt renders the conversation as text for completion-only targets and the judge
+func (c Case) prompt() string {
+	if len(c.Messages) == 0 && c.System == "" {
+		return c.Prompt
+	}
+	var b strings.Builder
+	for i, msg := range c.messages() {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "%s: %s", msg.Role, msg.Content)
+	}
+	return b.String()
+}
+
+// ExampleCases turns a dataset of examples into cases, e.g. the held-out
+// examples of prompt.SplitExamples. Each case is named by the example ID, or
+// "example-<index>", prompts with the example input and checks the response
+// with the assertion expect returns for the expected output.
+//
+// Parameters:
+//   - examples: The examples, e.g. from prompt.LoadExamples
+//   - expect: Builds the assertion for an expected output (default: Contains)
+//
+// Returns:
+//   - []Case: One case per example, in order
+func ExampleCases(examples []prompt.Example, expect func(output string) Assertion) []Case {
+	if expect == nil {
+		expect = Contains
+	}
+	cases := make([]Case, len(examples))
+	for i, example := range examples {
+		name := example.ID
+		if name == "" {
+			name = fmt.Sprintf("example-%d", i)
+		}
+		cases[i] = Case{
+			Name:       name,
+			Prompt:     example.Input,
+			Assertions: []Assertion{expect(example.Output)},
+		}
+	}
+	return cases
+}
+
+// Target is a client and model to evaluate.
+type Target struct {
+	// Name identifies the target in the report (default: the model, or the target index)
+	Name string `json:"name"`
+
+	// Client sends the requests (required)
+	Client aiprovider.Client `json:"-"`
+
+	// Model overrides the client's default model (optional)
+	Model string `json:"model,omitempty"`
+
+	// Temperature overrides the client's default temperature (optional)
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+// Options configures Run.
+type Options struct {
+	// Judge grades responses of cases with Criteria (optional)
+	Judge *Judge
+
+	// PassScore is the minimum judge score between 0 and 1 for a case to
+	// pass (default: DefaultPassScore)
+	PassScore float64
+
+	// Concurrency is the number of requests run in parallel (default: DefaultConcurrency)
+	Concurrency int
+}
+
+// Result is the outcome of one case on one target.
+type Result struct {
+	// Case is the case name
+	Case string `json:"case"`
+
+	// Target is the target name
+	Target string `json:"target"`
+
+	// Response is the response text
+	Response string `json:"response"`
+
+	// Passed reports whether the request succeeded, every assertion passed
+	// and the judge score reached the pass score
+	Passed bool `json:"passed"`
+
+	// Score is the judge score, or the fraction of passed assertions for
+	// cases without criteria, between 0 and 1
+	Score float64 `json:"score"`
+
+	// Failures lists the failed assertions
+	Failures []string `json:"failures,omitempty"`
+
+	// JudgeReason is the judge's explanation of the score
+	JudgeReason string `json:"judge_reason,omitempty"`
+
+	// Latency is the response time of the target
+	Latency time.Duration `json:"latency"`
+
+	// Usage is the target's token usage, excluding the judge
+	Usage aiprovider.Usage `json:"usage"`
+
+	// Cost is the estimated cost in USD of the target request
+	Cost float64 `json:"cost_usd"`
+
+	// Err is the request or judge error, if any
+	Err error `json:"-"`
+}
+
+// Run sends every case to every target, scores the responses and returns
+// the comparison report.
+//
+// Request and judge failures are recorded as failed results rather than
+// returned, so one flaky target does not abort the evaluation.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - cases: The test cases
+//   - targets: The clients and models to compare
+//   - opts: Judge and concurrency options
+//
+// Returns:
+//   - *Report: Results in case and target order, and a summary per target
+//   - error: A validation error for invalid cases, targets or options
+func Run(ctx context.Context, cases []Case, targets []Target, opts Options) (*Report, error) {
+	if len(cases) == 0 || len(targets) == 0 {
+		return nil, fmt.Errorf("at least one case and one target are required")
+	}
+	for i, c := range cases {
+		if strings.TrimSpace(c.Prompt) == "" && len(c.Messages) == 0 {
+			return nil, fmt.Errorf("case %d (%s) has no prompt", i, c.Name)
+		}
+		if c.Criteria != "" && opts.Judge == nil {
+			return nil, fmt.Errorf("case %d (%s) has criteria but no judge is configured", i, c.Name)
+		}
+	}
+	targets = append([]Target(nil), targets...)
+	for i := range targets {
+		if targets[i].Client == nil {
+			return nil, fmt.Errorf("target %d has no client", i)
+		}
+		if targets[i].Name == "" {
+			targets[i].Name = targets[i].Model
+		}
+		if targets[i].Name == "" {
+			targets[i].Name = fmt.Sprintf("target-%d", i)
+		}
+	}
+	if opts.Judge != nil && opts.Judge.Client == nil {
+		return nil, fmt.Errorf("judge has no client")
+	}
+	if opts.PassScore <= 0 {
+		opts.PassScore = DefaultPassScore
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+
+	results := make([]Result, len(cases)*len(targets))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, c := range cases {
+		for j, target := range targets {
+			wg.Add(1)
+			go func(index int, c Case, target Target) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				results[index] = runCase(ctx, c, target, opts)
+			}(i*len(targets)+j, c, target)
+		}
+	}
+	wg.Wait()
+
+	return newReport(results, targets), nil
+}
+
+// runCase sends a case to a target and scores the response
+func runCase(ctx context.Context, c Case, target Target, opts Options) Result {
+	result := Result{Case: c.Name, Target: target.Name}
+
+	start := time.Now()
+	text, usage, metadata, err := send(ctx, c, target)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Response = text
+	result.Usage = usage
+	result.Cost = aiprovider.ResponseCost(target.Client, metadata, usage)
+
+	for _, assertion := range c.Assertions {
+		if failure := assertion.Check(text); failure != "" {
+			result.Failures = append(result.Failures, failure)
+		}
+	}
+	result.Passed = len(result.Failures) == 0
+	result.Score = 1
+	if len(c.Assertions) > 0 {
+		result.Score = float64(len(c.Assertions)-len(result.Failures)) / float64(len(c.Assertions))
+	}
+
+	if c.Criteria != "" {
+		score, reason, err := opts.Judge.grade(ctx, c, text)
+		if err != nil {
+			result.Err = err
+			result.Passed = false
+			return result
+		}
+		result.Score = score
+		result.JudgeReason = reason
+		result.Passed = result.Passed && score >= opts.PassScore
+	}
+	return result
+}
+
+// send requests a response with chat completion, or text completion for targets without chat support
+func send(ctx context.Context, c Case, target Target) (string, aiprovider.Usage, aiprovider.ResponseMetadata, error) {
+	if target.Client.SupportsFeature(aiprovider.FeatureChatCompletion) {
+		resp, err := target.Client.ChatComplete(ctx, aiprovider.ChatRequest{
+			Messages:    c.messages(),
+			Model:       target.Model,
+			Temperature: target.Temperature,
+			MaxTokens:   c.MaxTokens,
+		})
+		if err != nil {
+			return "", aiprovider.Usage{}, aiprovider.ResponseMetadata{}, err
+		}
+		return resp.Message.Content, resp.Usage, resp.Metadata, nil
+	}
+
+	resp, err := target.Client.Complete(ctx, aiprovider.CompletionRequest{
+		Prompt:      c.prompt(),
+		Model:       target.Model,
+		Temperature: target.Temperature,
+		MaxTokens:   c.MaxTokens,
+	})
+	if err != nil {
+		return "", aiprovider.Usage{}, aiprovider.ResponseMetadata{}, err
+	}
+	return resp.Text, resp.Usage, resp.Metadata, nil
+}
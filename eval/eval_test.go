@@ -0,0 +1,191 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+	"github.com/ajeet-kumar1087/ai-providers/prompt"
+)
+
+// scriptedClient answers prompts from a map and grades with a fixed verdict
+type scriptedClient struct {
+	aiprovider.Client
+	answers map[string]string
+	chat    bool
+	verdict string
+}
+
+func (s *scriptedClient) SupportsFeature(feature string) bool {
+	return feature != aiprovider.FeatureChatCompletion || s.chat
+}
+
+func (s *scriptedClient) ChatComplete(ctx context.Context, req aiprovider.ChatRequest) (*aiprovider.ChatResponse, error) {
+	answer, ok := s.answers[req.Messages[len(req.Messages)-1].Content]
+	if !ok {
+		return nil, errors.New("no answer")
+	}
+	return &aiprovider.ChatResponse{
+		Message: aiprovider.Message{Role: "assistant", Content: answer},
+		Usage:   aiprovider.Usage{TotalTokens: 10},
+	}, nil
+}
+
+func (s *scriptedClient) Complete(ctx context.Context, req aiprovider.CompletionRequest) (*aiprovider.CompletionResponse, error) {
+	for prompt, answer := range s.answers {
+		if strings.HasSuffix(req.Prompt, prompt) {
+			return &aiprovider.CompletionResponse{Text: answer, Usage: aiprovider.Usage{TotalTokens: 20}}, nil
+		}
+	}
+	return nil, errors.New("no answer")
+}
+
+func (s *scriptedClient) Extract(ctx context.Context, text string, schema json.RawMessage, opts aiprovider.ExtractOptions) (*aiprovider.ExtractionResult, error) {
+	return &aiprovider.ExtractionResult{Data: json.RawMessage(s.verdict)}, nil
+}
+
+func TestRun(t *testing.T) {
+	cases := []Case{
+		{
+			Name:       "capital",
+			Prompt:     "Capital of France?",
+			Assertions: []Assertion{Contains("paris"), MaxWords(3)},
+		},
+		{
+			Name:     "apology",
+			System:   "You are a support agent.",
+			Prompt:   "Apologize.",
+			Criteria: "Polite and brief.",
+		},
+	}
+	good := &scriptedClient{chat: true, answers: map[string]string{
+		"Capital of France?": "Paris.",
+		"Apologize.":         "We are sorry.",
+	}}
+	verbose := &scriptedClient{answers: map[string]string{
+		"Capital of France?": "The capital of France is Paris.",
+		"Apologize.":         "Sorry.",
+	}}
+	judge := &Judge{Client: &scriptedClient{verdict: `{"score": 8, "reason": "polite"}`}}
+
+	report, err := Run(context.Background(), cases, []Target{
+		{Name: "good", Client: good},
+		{Client: verbose, Model: "completion-model"},
+	}, Options{Judge: judge})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(report.Results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(report.Results))
+	}
+	capitalVerbose := report.Results[1]
+	if capitalVerbose.Target != "completion-model" || capitalVerbose.Passed || capitalVerbose.Score != 0.5 || len(capitalVerbose.Failures) != 1 {
+		t.Errorf("Expected the word limit to fail, got %+v", capitalVerbose)
+	}
+	apology := report.Results[2]
+	if !apology.Passed || apology.Score != 0.8 || apology.JudgeReason != "polite" {
+		t.Errorf("Expected a judged pass, got %+v", apology)
+	}
+
+	good0, verbose0 := report.Summaries[0], report.Summaries[1]
+	if good0.Passed != 2 || good0.PassRate() != 1 || good0.Usage.TotalTokens != 20 {
+		t.Errorf("Unexpected summary %+v", good0)
+	}
+	if verbose0.Passed != 1 || verbose0.Usage.TotalTokens != 40 {
+		t.Errorf("Expected the completion fallback to be used, got %+v", verbose0)
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0].Case != "capital" {
+		t.Errorf("Unexpected failed results %+v", failed)
+	}
+
+	var table bytes.Buffer
+	if err := report.WriteTable(&table); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(table.String(), "good") || !strings.Contains(table.String(), "1/2") {
+		t.Errorf("Unexpected table:\n%s", table.String())
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	client := &scriptedClient{chat: true, answers: map[string]string{}}
+
+	report, err := Run(context.Background(), []Case{{Name: "missing", Prompt: "Unknown"}}, []Target{{Client: client}}, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result := report.Results[0]; result.Err == nil || result.Passed || report.Summaries[0].Errors != 1 {
+		t.Errorf("Expected a recorded request error, got %+v", result)
+	}
+
+	invalid := []struct {
+		name    string
+		cases   []Case
+		targets []Target
+	}{
+		{name: "no cases", targets: []Target{{Client: client}}},
+		{name: "empty prompt", cases: []Case{{Name: "empty"}}, targets: []Target{{Client: client}}},
+		{name: "criteria without judge", cases: []Case{{Prompt: "Hi", Criteria: "Friendly"}}, targets: []Target{{Client: client}}},
+		{name: "target without client", cases: []Case{{Prompt: "Hi"}}, targets: []Target{{Name: "none"}}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Run(context.Background(), tt.cases, tt.targets, Options{}); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	tests := []struct {
+		assertion Assertion
+		response  string
+		pass      bool
+	}{
+		{Contains("Paris"), "it is paris", true},
+		{Contains("Paris"), "it is Lyon", false},
+		{NotContains("sorry"), "Sorry!", false},
+		{Matches(`^\d+$`), "42", true},
+		{MaxWords(2), "one two three", false},
+		{ValidJSON(), ` {"a": 1} `, true},
+		{ValidJSON(), `{"a": }`, false},
+	}
+	for i, tt := range tests {
+		if pass := tt.assertion.Check(tt.response) == ""; pass != tt.pass {
+			t.Errorf("Assertion %d on %q: expected pass=%v", i, tt.response, tt.pass)
+		}
+	}
+}
+
+func TestExampleCases(t *testing.T) {
+	examples := []prompt.Example{
+		{ID: "capital", Input: "Capital of France?", Output: "Paris"},
+		{Input: "Capital of Italy?", Output: "Rome"},
+	}
+
+	cases := ExampleCases(examples, nil)
+	if len(cases) != 2 || cases[0].Name != "capital" || cases[1].Name != "example-1" {
+		t.Fatalf("Unexpected cases: %+v", cases)
+	}
+	if cases[1].Prompt != "Capital of Italy?" || cases[1].Assertions[0].Check("It is Rome.") != "" {
+		t.Errorf("Expected case to prompt with the input and expect the output, got %+v", cases[1])
+	}
+
+	client := &scriptedClient{chat: true, answers: map[string]string{
+		"Capital of France?": "Paris",
+		"Capital of Italy?":  "Milan",
+	}}
+	report, err := Run(context.Background(), cases, []Target{{Name: "t", Client: client}}, Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0].Case != "example-1" {
+		t.Errorf("Expected only example-1 to fail, got %+v", failed)
+	}
+}
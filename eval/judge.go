@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+// judgeSchema is the JSON schema of a judge verdict
+var judgeSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"score": {"type": "number"},
+		"reason": {"type": "string"}
+	},
+	"required": ["score", "reason"]
+}`)
+
+// judgeInstructions tells the judge model how to grade
+const judgeInstructions = "You are grading an AI assistant's response. Score it from 0 (fails the criteria) to 10 (fully meets them) and briefly explain the score."
+
+// Judge grades responses against a case's criteria with a model (LLM-as-judge).
+type Judge struct {
+	// Client sends the grading requests (required)
+	Client aiprovider.Client
+
+	// Model overrides the judge client's default model (optional)
+	Model string
+
+	// Instructions replaces the default grading instructions (optional)
+	Instructions string
+}
+
+// verdict is the judge's structured answer
+type verdict struct {
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// grade returns the judge score between 0 and 1 and the judge's reason
+func (j *Judge) grade(ctx context.Context, c Case, response string) (float64, string, error) {
+	instructions := j.Instructions
+	if instructions == "" {
+		instructions = judgeInstructions
+	}
+
+	text := fmt.Sprintf("Task given to the assistant:\n%s\n\nCriteria:\n%s\n\nAssistant response:\n%s", c.prompt(), c.Criteria, response)
+	result, err := j.Client.Extract(ctx, text, judgeSchema, aiprovider.ExtractOptions{
+		Instructions: instructions,
+		Model:        j.Model,
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("judge failed: %w", err)
+	}
+
+	var v verdict
+	if err := result.Decode(&v); err != nil {
+		return 0, "", fmt.Errorf("invalid judge verdict: %w", err)
+	}
+	score := v.Score / 10
+	if score < 0 {
+		score = 0
+	}
+	if score > 1 {
+		score = 1
+	}
+	return score, v.Reason, nil
+}
@@ -0,0 +1,110 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+// Report is the outcome of Run.
+type Report struct {
+	// Results holds one result per case and target, in case then target order
+	Results []Result `json:"results"`
+
+	// Summaries holds one summary per target, in target order
+	Summaries []Summary `json:"summaries"`
+}
+
+// Summary aggregates the results of one target.
+type Summary struct {
+	// Target is the target name
+	Target string `json:"target"`
+
+	// Cases is the number of cases run
+	Cases int `json:"cases"`
+
+	// Passed is the number of passed cases
+	Passed int `json:"passed"`
+
+	// Errors is the number of cases whose request or judge failed
+	Errors int `json:"errors"`
+
+	// MeanScore is the average score over all cases
+	MeanScore float64 `json:"mean_score"`
+
+	// MeanLatency is the average response time
+	MeanLatency time.Duration `json:"mean_latency"`
+
+	// Usage is the total token usage
+	Usage aiprovider.Usage `json:"usage"`
+
+	// Cost is the total estimated cost in USD
+	Cost float64 `json:"cost_usd"`
+}
+
+// PassRate returns the fraction of passed cases
+func (s Summary) PassRate() float64 {
+	if s.Cases == 0 {
+		return 0
+	}
+	return float64(s.Passed) / float64(s.Cases)
+}
+
+// newReport summarizes results per target
+func newReport(results []Result, targets []Target) *Report {
+	report := &Report{Results: results, Summaries: make([]Summary, len(targets))}
+	for i, target := range targets {
+		summary := &report.Summaries[i]
+		summary.Target = target.Name
+
+		var latency time.Duration
+		var score float64
+		for j := i; j < len(results); j += len(targets) {
+			result := results[j]
+			summary.Cases++
+			if result.Passed {
+				summary.Passed++
+			}
+			if result.Err != nil {
+				summary.Errors++
+			}
+			score += result.Score
+			latency += result.Latency
+			summary.Usage.PromptTokens += result.Usage.PromptTokens
+			summary.Usage.CompletionTokens += result.Usage.CompletionTokens
+			summary.Usage.TotalTokens += result.Usage.TotalTokens
+			summary.Cost += result.Cost
+		}
+		if summary.Cases > 0 {
+			summary.MeanScore = score / float64(summary.Cases)
+			summary.MeanLatency = latency / time.Duration(summary.Cases)
+		}
+	}
+	return report
+}
+
+// WriteTable writes the per-target summaries as an aligned text table
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tPASSED\tPASS RATE\tMEAN SCORE\tERRORS\tMEAN LATENCY\tTOKENS\tCOST (USD)")
+	for _, s := range r.Summaries {
+		fmt.Fprintf(tw, "%s\t%d/%d\t%.0f%%\t%.2f\t%d\t%v\t%d\t%.4f\n",
+			s.Target, s.Passed, s.Cases, 100*s.PassRate(), s.MeanScore, s.Errors,
+			s.MeanLatency.Round(time.Millisecond), s.Usage.TotalTokens, s.Cost)
+	}
+	return tw.Flush()
+}
+
+// Failed returns the results that did not pass
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, result := range r.Results {
+		if !result.Passed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
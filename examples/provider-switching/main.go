@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	wrapper "github.com/ajeet-kumar1087/ai-providers"
+	"github.com/ajeet-kumar1087/ai-providers/eval"
 )
 
 func main() {
@@ -75,7 +77,7 @@ func basicProviderSwitching() {
 	}
 }
 
-// providerPerformanceComparison compares response times and token usage
+// providerPerformanceComparison compares pass rates, response times and token usage
 func providerPerformanceComparison() {
 	providers := []struct {
 		name     string
@@ -86,17 +88,19 @@ func providerPerformanceComparison() {
 		{"Anthropic", wrapper.ProviderAnthropic, "sk-ant-REDACTED"},
 	}
 
-	testPrompts := []string{
-		"Write a short summary of quantum computing:",
-		"Explain recursion in programming:",
-		"What are the benefits of cloud computing?",
+	maxTokens := 100
+	cases := []eval.Case{
+		{Name: "quantum", Prompt: "Write a short summary of quantum computing:", MaxTokens: &maxTokens,
+			Assertions: []eval.Assertion{eval.Contains("qubit")}},
+		{Name: "recursion", Prompt: "Explain recursion in programming:", MaxTokens: &maxTokens,
+			Assertions: []eval.Assertion{eval.Contains("itself")}},
+		{Name: "cloud", Prompt: "What are the benefits of cloud computing?", MaxTokens: &maxTokens,
+			Assertions: []eval.Assertion{eval.MaxWords(100)}},
 	}
 
-	results := make(map[string][]PerformanceResult)
-
+	temperature := 0.5
+	var targets []eval.Target
 	for _, p := range providers {
-		fmt.Printf("\n--- Performance Testing: %s ---\n", p.name)
-
 		client, err := wrapper.NewClient(p.provider, wrapper.Config{
 			APIKey: p.apiKey,
 		})
@@ -104,74 +108,26 @@ func providerPerformanceComparison() {
 			log.Printf("Failed to create %s client: %v", p.name, err)
 			continue
 		}
+		defer client.Close()
+		targets = append(targets, eval.Target{Name: p.name, Client: client, Temperature: &temperature})
+	}
+	if len(targets) == 0 {
+		return
+	}
 
-		var providerResults []PerformanceResult
-
-		for i, prompt := range testPrompts {
-			fmt.Printf("Test %d/%d: ", i+1, len(testPrompts))
-
-			req := wrapper.CompletionRequest{
-				Prompt:      prompt,
-				Temperature: func() *float64 { t := 0.5; return &t }(),
-				MaxTokens:   func() *int { m := 100; return &m }(),
-			}
-
-			start := time.Now()
-			resp, err := client.Complete(context.Background(), req)
-			duration := time.Since(start)
-
-			if err != nil {
-				fmt.Printf("Failed - %v\n", err)
-				continue
-			}
-
-			result := PerformanceResult{
-				Prompt:           prompt,
-				Duration:         duration,
-				TokensUsed:       resp.Usage.TotalTokens,
-				CompletionTokens: resp.Usage.CompletionTokens,
-				ResponseLength:   len(resp.Text),
-			}
-
-			providerResults = append(providerResults, result)
-			fmt.Printf("Success - %v, %d tokens\n", duration, resp.Usage.TotalTokens)
-		}
-
-		results[p.name] = providerResults
-		client.Close()
+	report, err := eval.Run(context.Background(), cases, targets, eval.Options{})
+	if err != nil {
+		log.Printf("Evaluation failed: %v", err)
+		return
 	}
 
-	// Print comparison summary
 	fmt.Println("\n=== Performance Summary ===")
-	for providerName, providerResults := range results {
-		if len(providerResults) == 0 {
-			continue
-		}
-
-		var totalDuration time.Duration
-		var totalTokens int
-		for _, result := range providerResults {
-			totalDuration += result.Duration
-			totalTokens += result.TokensUsed
-		}
-
-		avgDuration := totalDuration / time.Duration(len(providerResults))
-		avgTokens := totalTokens / len(providerResults)
-
-		fmt.Printf("%s: Avg Duration: %v, Avg Tokens: %d\n",
-			providerName, avgDuration, avgTokens)
+	report.WriteTable(os.Stdout)
+	for _, result := range report.Failed() {
+		fmt.Printf("%s/%s failed: %v %v\n", result.Target, result.Case, result.Err, result.Failures)
 	}
 }
 
-// PerformanceResult holds performance metrics for a single request
-type PerformanceResult struct {
-	Prompt           string
-	Duration         time.Duration
-	TokensUsed       int
-	CompletionTokens int
-	ResponseLength   int
-}
-
 // fallbackProviderStrategy demonstrates implementing a fallback strategy
 func fallbackProviderStrategy() {
 	// Define providers in order of preference
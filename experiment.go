@@ -0,0 +1,103 @@
+package aiprovider
+
+import "context"
+
+// applyCompletionExperiments assigns the request's user to a variant of every
+// configured experiment and applies the variant overrides, returning the
+// served variant per experiment
+func (c *client) applyCompletionExperiments(ctx context.Context, req CompletionRequest) (CompletionRequest, map[string]string) {
+	if len(c.config.Experiments) == 0 {
+		return req, nil
+	}
+
+	served := make(map[string]string, len(c.config.Experiments))
+	for _, experiment := range c.config.Experiments {
+		variant := c.assignVariant(ctx, experiment, req.UserID, req.Tags)
+		if variant == nil {
+			served[experiment.Name] = ExperimentControl
+			continue
+		}
+		served[experiment.Name] = variant.Name
+
+		if variant.Model != "" {
+			req.Model = variant.Model
+		}
+		if variant.Profile != "" {
+			req.Profile = variant.Profile
+		}
+		if variant.PromptVersion != "" {
+			req.PromptVersion = variant.PromptVersion
+		}
+		if variant.SystemPrompt != "" {
+			req.Prompt = variant.SystemPrompt + "\n\n" + req.Prompt
+		}
+	}
+	return req, served
+}
+
+// applyChatExperiments assigns the request's user to a variant of every
+// configured experiment and applies the variant overrides, returning the
+// served variant per experiment
+func (c *client) applyChatExperiments(ctx context.Context, req ChatRequest) (ChatRequest, map[string]string) {
+	if len(c.config.Experiments) == 0 {
+		return req, nil
+	}
+
+	served := make(map[string]string, len(c.config.Experiments))
+	for _, experiment := range c.config.Experiments {
+		variant := c.assignVariant(ctx, experiment, req.UserID, req.Tags)
+		if variant == nil {
+			served[experiment.Name] = ExperimentControl
+			continue
+		}
+		served[experiment.Name] = variant.Name
+
+		if variant.Model != "" {
+			req.Model = variant.Model
+		}
+		if variant.Profile != "" {
+			req.Profile = variant.Profile
+		}
+		if variant.PromptVersion != "" {
+			req.PromptVersion = variant.PromptVersion
+		}
+		if variant.SystemPrompt != "" {
+			req.Messages = withSystemPrompt(req.Messages, variant.SystemPrompt)
+		}
+	}
+	return req, served
+}
+
+// withSystemPrompt returns a copy of messages with the first system message
+// replaced by prompt, or prompt prepended if there is none
+func withSystemPrompt(messages []Message, prompt string) []Message {
+	for i, msg := range messages {
+		if msg.Role == "system" {
+			replaced := append([]Message(nil), messages...)
+			replaced[i].Content = prompt
+			return replaced
+		}
+	}
+	return append([]Message{{Role: "system", Content: prompt}}, messages...)
+}
+
+// assignVariant returns the experiment variant for a request, or nil for the
+// control group, evaluating the experiment's feature flag if it has one
+func (c *client) assignVariant(ctx context.Context, experiment Experiment, userID string, tags map[string]string) *ExperimentVariant {
+	if experiment.Flag == "" {
+		return experiment.Assign(userID)
+	}
+	if c.config.Flags == nil {
+		return nil
+	}
+
+	ctx = WithFlagSubject(ctx, FlagSubject{UserID: userID, Tags: tags})
+	value, err := c.config.Flags.Evaluate(ctx, experiment.Flag)
+	if err != nil {
+		if c.config.OnFlagError != nil {
+			c.config.OnFlagError(experiment.Flag, err)
+		}
+		return nil
+	}
+	return experiment.Variant(value)
+}
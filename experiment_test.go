@@ -0,0 +1,193 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExperimentAssign(t *testing.T) {
+	experiment := Experiment{
+		Name: "tone",
+		Variants: []ExperimentVariant{
+			{Name: "friendly", Percent: 20},
+			{Name: "formal", Percent: 10},
+		},
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		name := assignedVariant(experiment, userID)
+		if again := assignedVariant(experiment, userID); again != name {
+			t.Fatalf("Expected a stable assignment for %s, got %s and %s", userID, name, again)
+		}
+		counts[name]++
+	}
+
+	for name, want := range map[string]int{"friendly": 2000, "formal": 1000, ExperimentControl: 7000} {
+		if got := counts[name]; got < want*9/10 || got > want*11/10 {
+			t.Errorf("Expected about %d users in %s, got %d", want, name, got)
+		}
+	}
+
+	if variant := experiment.Assign(""); variant != nil {
+		t.Errorf("Expected control without a user ID, got %s", variant.Name)
+	}
+}
+
+// assignedVariant returns the name of the variant assigned to a user
+func assignedVariant(experiment Experiment, userID string) string {
+	if variant := experiment.Assign(userID); variant != nil {
+		return variant.Name
+	}
+	return ExperimentControl
+}
+
+func TestExperimentValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		experiment Experiment
+		valid      bool
+	}{
+		{name: "valid", experiment: Experiment{Name: "e", Variants: []ExperimentVariant{{Name: "b", Percent: 50}, {Name: "c", Percent: 50}}}, valid: true},
+		{name: "empty name", experiment: Experiment{Variants: []ExperimentVariant{{Name: "b", Percent: 50}}}},
+		{name: "no variants", experiment: Experiment{Name: "e"}},
+		{name: "reserved variant", experiment: Experiment{Name: "e", Variants: []ExperimentVariant{{Name: ExperimentControl, Percent: 50}}}},
+		{name: "duplicate variant", experiment: Experiment{Name: "e", Variants: []ExperimentVariant{{Name: "b", Percent: 10}, {Name: "b", Percent: 10}}}},
+		{name: "negative percent", experiment: Experiment{Name: "e", Variants: []ExperimentVariant{{Name: "b", Percent: -1}}}},
+		{name: "over 100 percent", experiment: Experiment{Name: "e", Variants: []ExperimentVariant{{Name: "b", Percent: 60}, {Name: "c", Percent: 50}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.experiment.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Expected valid experiment, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestExperiments(t *testing.T) {
+	adapter := &mockAdapter{
+		completeResp: &CompletionResponse{Text: "ok"},
+		chatResp:     &ChatResponse{Message: Message{Role: "assistant", Content: "ok"}},
+	}
+	recorder := &recordingUsageRecorder{}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Model = "gpt-4o-mini"
+	c.config.UsageRecorder = recorder
+	c.config.Experiments = []Experiment{
+		{Name: "model", Variants: []ExperimentVariant{{Name: "large", Percent: 100, Model: "gpt-4o"}}},
+		{Name: "prompt", Variants: []ExperimentVariant{{Name: "terse", Percent: 100, SystemPrompt: "Be terse."}}},
+	}
+
+	ctx := context.Background()
+	messages := []Message{
+		{Role: "system", Content: "Be helpful."},
+		{Role: "user", Content: "Hi"},
+	}
+	resp, err := c.ChatComplete(ctx, ChatRequest{UserID: "user-1", Messages: messages})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	chat := adapter.chatRequests[0]
+	if chat.Model != "gpt-4o" || chat.Messages[0].Content != "Be terse." || len(chat.Messages) != 2 {
+		t.Errorf("Expected variant model and system prompt, got %+v", chat)
+	}
+	if messages[0].Content != "Be helpful." {
+		t.Errorf("Expected the caller's messages to be unchanged, got %+v", messages)
+	}
+	if resp.Metadata.Experiments["model"] != "large" || resp.Metadata.Experiments["prompt"] != "terse" {
+		t.Errorf("Expected variant labels in metadata, got %v", resp.Metadata.Experiments)
+	}
+	if got := recorder.records[0].Experiments; got["model"] != "large" || got["prompt"] != "terse" {
+		t.Errorf("Expected variant labels in usage record, got %v", got)
+	}
+
+	// Requests without a user ID are served control unchanged
+	resp, err = c.ChatComplete(ctx, ChatRequest{Messages: messages})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if chat := adapter.chatRequests[1]; chat.Model != "gpt-4o-mini" || chat.Messages[0].Content != "Be helpful." {
+		t.Errorf("Expected the control request unchanged, got %+v", chat)
+	}
+	if resp.Metadata.Experiments["model"] != ExperimentControl {
+		t.Errorf("Expected control label, got %v", resp.Metadata.Experiments)
+	}
+
+	resp2, err := c.Complete(ctx, CompletionRequest{UserID: "user-1", Prompt: "Hi"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if completion := adapter.completeRequests[0]; completion.Model != "gpt-4o" || completion.Prompt != "Be terse.\n\nHi" {
+		t.Errorf("Expected variant applied to completion, got %+v", completion)
+	}
+	if resp2.Metadata.Experiments["prompt"] != "terse" {
+		t.Errorf("Expected variant labels in completion metadata, got %v", resp2.Metadata.Experiments)
+	}
+}
+
+func TestExperiments_FeatureFlag(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Hello"}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.Experiments = []Experiment{{
+		Name:     "model",
+		Flag:     "support-model",
+		Variants: []ExperimentVariant{{Name: "large", Model: "gpt-4o"}},
+	}}
+	var subjects []FlagSubject
+	c.config.Flags = FlagFunc(func(ctx context.Context, key string) (string, error) {
+		subject := FlagSubjectFromContext(ctx)
+		subjects = append(subjects, subject)
+		switch subject.UserID {
+		case "beta-user":
+			return "large", nil
+		case "broken":
+			return "", errors.New("flag service unavailable")
+		}
+		return "", nil
+	})
+	var flagErrors []string
+	c.config.OnFlagError = func(key string, err error) { flagErrors = append(flagErrors, key) }
+
+	ctx := context.Background()
+	messages := []Message{{Role: "user", Content: "Hi"}}
+	for _, user := range []string{"beta-user", "other-user", "broken"} {
+		if _, err := c.ChatComplete(ctx, ChatRequest{UserID: user, Tags: map[string]string{"team": "support"}, Messages: messages}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if model := adapter.chatRequests[0].Model; model != "gpt-4o" {
+		t.Errorf("Expected the flagged variant's model, got %q", model)
+	}
+	for _, chat := range adapter.chatRequests[1:] {
+		if chat.Model == "gpt-4o" {
+			t.Errorf("Expected control for users the flag does not select, got %+v", chat)
+		}
+	}
+	if len(subjects) != 3 || subjects[0].Tags["team"] != "support" {
+		t.Errorf("Expected the flag evaluated with the request's user and tags, got %+v", subjects)
+	}
+	if len(flagErrors) != 1 || flagErrors[0] != "support-model" {
+		t.Errorf("Expected the evaluation error reported, got %v", flagErrors)
+	}
+
+	config := Config{APIKey: "sk-1234567890abcdef1234567890abcdef", Experiments: c.config.Experiments}
+	if err := config.Validate(ProviderOpenAI); err == nil || !strings.Contains(err.Error(), "flag provider") {
+		t.Errorf("Expected a validation error for a flag experiment without a flag provider, got %v", err)
+	}
+	config.Flags = c.config.Flags
+	if err := config.Validate(ProviderOpenAI); err != nil {
+		t.Errorf("Expected a valid config with a flag provider, got %v", err)
+	}
+}
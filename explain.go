@@ -0,0 +1,171 @@
+package aiprovider
+
+import (
+	"fmt"
+
+	"github.com/ajeet-kumar1087/ai-providers/adapters/anthropic"
+	"github.com/ajeet-kumar1087/ai-providers/adapters/openai"
+	"github.com/ajeet-kumar1087/ai-providers/internal/utils"
+)
+
+// ExplainChatMapping returns the payload a client for provider would send
+// for a generic chat request, without sending it.
+//
+// The request goes through the same validation, clamping and unsupported
+// parameter handling as ChatComplete, then through the provider's adapter.
+// Every parameter that was clamped, dropped, filled in, renamed or moved is
+// listed in the result's Changes, which explains why the same request
+// behaves differently across providers. Client configuration defaults and
+// request profiles are not applied.
+//
+// Example:
+//
+//	temperature := 1.5
+//	req := ChatRequest{
+//		Messages:    []Message{{Role: "system", Content: "Be terse."}, {Role: "user", Content: "Hi"}},
+//		Temperature: &temperature,
+//	}
+//	mapping, err := ExplainChatMapping(ProviderAnthropic, req)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, change := range mapping.Changes {
+//		fmt.Printf("%s %s: %s\n", change.Parameter, change.Action, change.Detail)
+//	}
+//	// temperature clamped: 1.5 is outside the anthropic range 0-1, sent as 1
+//	// model defaulted: no model requested, using claude-3-haiku-20240307
+//	// max_tokens defaulted: required by Anthropic, set to 1024
+//	// messages[0] moved: system message moved to the top-level "system" field
+//
+// Parameters:
+//   - provider: The provider to map the request for
+//   - req: The chat request to map
+//
+// Returns:
+//   - *RequestMapping: The endpoint, provider payload and parameter changes
+//   - error: A validation error if the request is invalid or cannot be mapped
+func ExplainChatMapping(provider ProviderType, req ChatRequest) (*RequestMapping, error) {
+	c, err := mappingClient(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, _, err := c.validateAndNormalizeChatRequest(req)
+	if err != nil {
+		return nil, c.mappingError(err)
+	}
+	changes := parameterChanges(provider, req.Temperature, normalized.Temperature, req.MaxTokens, normalized.MaxTokens)
+	changes = append(changes, droppedChanges(c.unsupportedChat(req))...)
+
+	var mapping *RequestMapping
+	switch provider {
+	case ProviderOpenAI:
+		mapping, err = openai.MapChatRequest(Config{}, normalized)
+	case ProviderAnthropic:
+		mapping, err = anthropic.MapChatRequest(Config{}, normalized)
+	default:
+		err = fmt.Errorf("%s adapter not yet implemented", provider)
+	}
+	if err != nil {
+		return nil, c.mappingError(err)
+	}
+
+	mapping.Changes = append(changes, mapping.Changes...)
+	return mapping, nil
+}
+
+// ExplainCompletionMapping returns the payload a client for provider would
+// send for a generic completion request, without sending it. It is the
+// completion counterpart of ExplainChatMapping.
+func ExplainCompletionMapping(provider ProviderType, req CompletionRequest) (*RequestMapping, error) {
+	c, err := mappingClient(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, _, err := c.validateAndNormalizeCompletionRequest(req)
+	if err != nil {
+		return nil, c.mappingError(err)
+	}
+	changes := parameterChanges(provider, req.Temperature, normalized.Temperature, req.MaxTokens, normalized.MaxTokens)
+	changes = append(changes, droppedChanges(c.unsupportedCompletion(req))...)
+
+	var mapping *RequestMapping
+	switch provider {
+	case ProviderOpenAI:
+		mapping, err = openai.MapCompletionRequest(Config{}, normalized)
+	case ProviderAnthropic:
+		mapping, err = anthropic.MapCompletionRequest(Config{}, normalized)
+	default:
+		err = fmt.Errorf("%s adapter not yet implemented", provider)
+	}
+	if err != nil {
+		return nil, c.mappingError(err)
+	}
+
+	mapping.Changes = append(changes, mapping.Changes...)
+	return mapping, nil
+}
+
+// mappingClient returns an adapterless client that normalizes requests for provider
+func mappingClient(provider ProviderType) (*client, error) {
+	if err := ValidateProviderType(provider); err != nil {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  err.Error(),
+			Provider: string(provider),
+			Wrapped:  err,
+		}
+	}
+	return &client{provider: provider}, nil
+}
+
+// droppedChanges reports the unsupported parameters dropped from a request
+func droppedChanges(unsupported []UnsupportedParameter) []MappingChange {
+	changes := make([]MappingChange, 0, len(unsupported))
+	for _, param := range unsupported {
+		changes = append(changes, MappingChange{Parameter: param.Parameter, Action: MappingDropped, Detail: param.Reason})
+	}
+	return changes
+}
+
+// mappingError wraps a mapping failure as a validation error unless it already is an *Error
+func (c *client) mappingError(err error) error {
+	if aiErr, ok := err.(*Error); ok {
+		return aiErr
+	}
+	return &Error{
+		Type:     ErrorTypeValidation,
+		Message:  fmt.Sprintf("cannot map request: %v", err),
+		Provider: string(c.provider),
+		Wrapped:  err,
+	}
+}
+
+// parameterChanges reports the temperature and max token changes made by request normalization
+func parameterChanges(provider ProviderType, temperature, normalizedTemperature *float64, maxTokens, normalizedMaxTokens *int) []MappingChange {
+	var changes []MappingChange
+	if temperature != nil && normalizedTemperature != nil && *temperature != *normalizedTemperature {
+		changes = append(changes, MappingChange{
+			Parameter: "temperature",
+			Action:    MappingClamped,
+			Detail: fmt.Sprintf("%g is outside the %s range 0-%g, sent as %g",
+				*temperature, provider, utils.GetProviderMaxTemperature(provider), *normalizedTemperature),
+		})
+	}
+	switch {
+	case maxTokens == nil && normalizedMaxTokens != nil:
+		changes = append(changes, MappingChange{
+			Parameter: "max_tokens",
+			Action:    MappingDefaulted,
+			Detail:    fmt.Sprintf("derived from the word or character limit as %d", *normalizedMaxTokens),
+		})
+	case maxTokens != nil && normalizedMaxTokens != nil && *maxTokens != *normalizedMaxTokens:
+		changes = append(changes, MappingChange{
+			Parameter: "max_tokens",
+			Action:    MappingClamped,
+			Detail:    fmt.Sprintf("%d sent as %d to respect the %s limit or the word or character limit", *maxTokens, *normalizedMaxTokens, provider),
+		})
+	}
+	return changes
+}
@@ -0,0 +1,123 @@
+package aiprovider
+
+import (
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/adapters/anthropic"
+	"github.com/ajeet-kumar1087/ai-providers/adapters/openai"
+)
+
+// changeActions indexes mapping changes by parameter
+func changeActions(changes []MappingChange) map[string]string {
+	actions := map[string]string{}
+	for _, change := range changes {
+		actions[change.Parameter] = change.Action
+	}
+	return actions
+}
+
+func TestExplainMapping_Anthropic(t *testing.T) {
+	temperature := 1.5
+	mapping, err := ExplainChatMapping(ProviderAnthropic, ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "Be terse."},
+			{Role: "user", Content: "Hi"},
+		},
+		Temperature: &temperature,
+		Stream:      true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	payload, ok := mapping.Payload.(anthropic.AnthropicChatCompletionRequest)
+	if !ok {
+		t.Fatalf("Expected an Anthropic chat payload, got %T", mapping.Payload)
+	}
+	if mapping.Endpoint != "/messages" || payload.System != "Be terse." || len(payload.Messages) != 1 {
+		t.Errorf("Unexpected payload %+v at %s", payload, mapping.Endpoint)
+	}
+	if *payload.Temperature != 1.0 || payload.MaxTokens != 1024 || payload.Stream {
+		t.Errorf("Expected clamped temperature, default max tokens and no streaming, got %+v", payload)
+	}
+
+	expected := map[string]string{
+		"temperature": MappingClamped,
+		"stream":      MappingDropped,
+		"model":       MappingDefaulted,
+		"max_tokens":  MappingDefaulted,
+		"messages[0]": MappingMoved,
+	}
+	actions := changeActions(mapping.Changes)
+	for parameter, action := range expected {
+		if actions[parameter] != action {
+			t.Errorf("Expected %s to be %s, got %q", parameter, action, actions[parameter])
+		}
+	}
+	if len(actions) != len(expected) {
+		t.Errorf("Unexpected changes %+v", mapping.Changes)
+	}
+}
+
+func TestExplainMapping_Completion(t *testing.T) {
+	temperature := 1.5
+	maxWords := 75
+	req := CompletionRequest{
+		Prompt:      "Write a haiku",
+		Model:       "some-model",
+		Temperature: &temperature,
+		Stop:        []string{"a", "b", "c", "d", "e"},
+		MaxWords:    &maxWords,
+	}
+
+	openaiMapping, err := ExplainCompletionMapping(ProviderOpenAI, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	payload := openaiMapping.Payload.(openai.OpenAICompletionRequest)
+	if *payload.Temperature != 1.5 || len(payload.Stop) != 4 || payload.MaxTokens == nil {
+		t.Errorf("Unexpected OpenAI payload %+v", payload)
+	}
+	actions := changeActions(openaiMapping.Changes)
+	if actions["stop"] != MappingDropped || actions["max_tokens"] != MappingDefaulted || actions["temperature"] != "" {
+		t.Errorf("Unexpected OpenAI changes %+v", openaiMapping.Changes)
+	}
+
+	anthropicMapping, err := ExplainCompletionMapping(ProviderAnthropic, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	actions = changeActions(anthropicMapping.Changes)
+	if actions["temperature"] != MappingClamped || actions["stop"] != MappingRenamed || actions["prompt"] != MappingConverted {
+		t.Errorf("Unexpected Anthropic changes %+v", anthropicMapping.Changes)
+	}
+}
+
+func TestExplainMapping_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		explain func() (*RequestMapping, error)
+	}{
+		{name: "unknown provider", explain: func() (*RequestMapping, error) {
+			return ExplainCompletionMapping("cohere", CompletionRequest{Prompt: "Hi"})
+		}},
+		{name: "invalid request", explain: func() (*RequestMapping, error) {
+			return ExplainCompletionMapping(ProviderAnthropic, CompletionRequest{})
+		}},
+		{name: "invalid chat request", explain: func() (*RequestMapping, error) {
+			return ExplainChatMapping(ProviderAnthropic, ChatRequest{})
+		}},
+		{name: "unimplemented chat", explain: func() (*RequestMapping, error) {
+			return ExplainChatMapping(ProviderOpenAI, ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.explain()
+			if aiErr, ok := err.(*Error); !ok || aiErr.Type != ErrorTypeValidation {
+				t.Errorf("Expected validation error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package aiprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/jsonschema"
+)
+
+// DefaultExtractRetries is the default number of retries after invalid output
+const DefaultExtractRetries = 2
+
+// ExtractOptions configures Extract.
+type ExtractOptions struct {
+	// Instructions adds task context such as "Extract every person mentioned" (optional)
+	Instructions string
+
+	// Model overrides the provider default model (optional)
+	Model string
+
+	// MaxRetries is the number of times invalid output is sent back to the
+	// model for correction (default: 2, negative disables retries)
+	MaxRetries int
+
+	// MaxTokens limits the size of the generated JSON (optional)
+	MaxTokens *int
+}
+
+// ExtractionResult is the result of Extract.
+type ExtractionResult struct {
+	// Data is the extracted JSON, valid against the schema
+	Data json.RawMessage `json:"data"`
+
+	// Attempts is the number of requests made, including retries
+	Attempts int `json:"attempts"`
+
+	// Usage is the total token usage across all attempts
+	Usage Usage `json:"usage"`
+}
+
+// Decode unmarshals the extracted data into v
+func (r *ExtractionResult) Decode(v interface{}) error {
+	return json.Unmarshal(r.Data, v)
+}
+
+// Extract pulls structured data matching a JSON schema out of unstructured text.
+//
+// The model is instructed to answer with JSON only. Its response is stripped of
+// surrounding prose and code fences and validated against schema; invalid
+// output is sent back with the validation error for correction, up to
+// MaxRetries times. The jsonschema package documents the supported keywords
+// and can generate a schema from a Go struct.
+//
+// Example:
+//
+//	type Contact struct {
+//		Name  string `json:"name"`
+//		Email string `json:"email,omitempty"`
+//	}
+//
+//	schema, _ := jsonschema.For([]Contact{})
+//	result, err := client.Extract(ctx, emailThread, schema, ExtractOptions{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	var contacts []Contact
+//	if err := result.Decode(&contacts); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - text: The text to extract from
+//   - schema: JSON schema describing the output
+//   - opts: Instructions, model and retry options
+//
+// Returns:
+//   - *ExtractionResult: The validated JSON and attempt statistics
+//   - error: A validation error for invalid input or if no valid output was
+//     produced within the retry limit, or the first request error
+func (c *client) Extract(ctx context.Context, text string, schema json.RawMessage, opts ExtractOptions) (*ExtractionResult, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	if strings.TrimSpace(text) == "" {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "text to extract from cannot be empty",
+			Provider: string(c.provider),
+		}
+	}
+
+	parsed, err := jsonschema.Parse(schema)
+	if err != nil {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  err.Error(),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	}
+
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultExtractRetries
+	} else if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	var compactSchema bytes.Buffer
+	if err := json.Compact(&compactSchema, schema); err != nil {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("invalid JSON schema: %v", err),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	}
+
+	temperature := 0.0
+	prompt := extractPrompt(text, compactSchema.String(), opts.Instructions)
+	result := &ExtractionResult{}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		user := prompt
+		if lastErr != nil {
+			user += fmt.Sprintf("\n\nYour previous response was invalid (%v). Respond with corrected JSON only.", lastErr)
+		}
+
+		gen, err := c.generate(ctx, helperRequest{
+			system:      "You extract structured data from text. Respond with a single JSON value matching the given schema and nothing else.",
+			user:        user,
+			model:       opts.Model,
+			temperature: &temperature,
+			maxTokens:   opts.MaxTokens,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Attempts++
+		result.Usage = addUsage(result.Usage, gen.usage)
+
+		data := extractJSON(gen.text)
+		if lastErr = parsed.Validate(data); lastErr == nil {
+			result.Data = data
+			return result, nil
+		}
+	}
+
+	return nil, &Error{
+		Type:     ErrorTypeValidation,
+		Message:  fmt.Sprintf("no valid output after %d attempts: %v", result.Attempts, lastErr),
+		Provider: string(c.provider),
+		Wrapped:  lastErr,
+	}
+}
+
+// extractPrompt builds the user prompt containing the schema and text
+func extractPrompt(text, schema, instructions string) string {
+	var b strings.Builder
+	if instructions != "" {
+		b.WriteString(instructions + "\n\n")
+	}
+	b.WriteString("JSON schema:\n" + schema + "\n\n")
+	b.WriteString("<text>\n" + text + "\n</text>\n\n")
+	b.WriteString("Respond with JSON matching the schema only. Use null or omit optional values that are not present in the text.")
+	return b.String()
+}
+
+// extractJSON returns the JSON value embedded in a model response, removing
+// code fences and any prose before or after it
+func extractJSON(response string) json.RawMessage {
+	text := strings.TrimSpace(response)
+
+	if start := strings.Index(text, "```"); start >= 0 {
+		fenced := text[start+3:]
+		if newline := strings.IndexByte(fenced, '\n'); newline >= 0 {
+			fenced = fenced[newline+1:]
+		}
+		if end := strings.Index(fenced, "```"); end >= 0 {
+			text = strings.TrimSpace(fenced[:end])
+		}
+	}
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return json.RawMessage(text)
+	}
+	closing := byte('}')
+	if text[start] == '[' {
+		closing = ']'
+	}
+	end := strings.LastIndexByte(text, closing)
+	if end < start {
+		return json.RawMessage(text[start:])
+	}
+	return json.RawMessage(text[start : end+1])
+}
@@ -0,0 +1,92 @@
+package aiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	schema := json.RawMessage(`{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}`)
+
+	tests := []struct {
+		name         string
+		replies      []string
+		maxRetries   int
+		wantData     string
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"plain JSON", []string{`{"name": "Ada"}`}, 0, `{"name": "Ada"}`, 1, false},
+		{"code fence", []string{"Here you go:\n```json\n{\"name\": \"Ada\"}\n```"}, 0, `{"name": "Ada"}`, 1, false},
+		{"retry on invalid", []string{`{"name": 1}`, `{"name": "Ada"}`}, 0, `{"name": "Ada"}`, 2, false},
+		{"retries exhausted", []string{"no", "still no", "never"}, 0, "", 3, true},
+		{"retries disabled", []string{"no"}, -1, "", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prompts []string
+			adapter := &scriptedAdapter{reply: func(user string) (string, error) {
+				prompts = append(prompts, user)
+				return tt.replies[len(prompts)-1], nil
+			}}
+			c := newMockClient(ProviderAnthropic, adapter)
+
+			result, err := c.Extract(context.Background(), "Ada Lovelace wrote the first program.", schema, ExtractOptions{MaxRetries: tt.maxRetries})
+			if len(prompts) != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, len(prompts))
+			}
+			if tt.wantErr {
+				if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+					t.Errorf("Expected validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(result.Data) != tt.wantData {
+				t.Errorf("Expected data %s, got %s", tt.wantData, result.Data)
+			}
+			if result.Attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts in result, got %d", tt.wantAttempts, result.Attempts)
+			}
+			if tt.wantAttempts > 1 && !strings.Contains(prompts[1], "expected string") {
+				t.Errorf("Expected retry prompt to include the validation error, got %q", prompts[1])
+			}
+		})
+	}
+}
+
+func TestExtract_Decode(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(string) (string, error) { return `[{"name": "Ada"}, {"name": "Alan"}]`, nil }}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	result, err := c.Extract(context.Background(), "Ada and Alan", json.RawMessage(`{"type": "array"}`), ExtractOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var people []struct {
+		Name string `json:"name"`
+	}
+	if err := result.Decode(&people); err != nil {
+		t.Fatalf("Unexpected decode error: %v", err)
+	}
+	if len(people) != 2 || people[1].Name != "Alan" {
+		t.Errorf("Unexpected decoded value: %+v", people)
+	}
+}
+
+func TestExtract_Validation(t *testing.T) {
+	c := newMockClient(ProviderAnthropic, &scriptedAdapter{})
+
+	if _, err := c.Extract(context.Background(), "", json.RawMessage(`{}`), ExtractOptions{}); err == nil {
+		t.Errorf("Expected error for empty text")
+	}
+	if _, err := c.Extract(context.Background(), "text", json.RawMessage(`{`), ExtractOptions{}); err == nil {
+		t.Errorf("Expected error for invalid schema")
+	}
+}
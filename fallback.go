@@ -0,0 +1,623 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FallbackStreamPolicy controls how a stream that fails part way is continued
+// on the next provider.
+type FallbackStreamPolicy string
+
+const (
+	// FallbackResume asks the next provider to continue the partial output.
+	// Text the new provider repeats at the seam is not delivered again.
+	FallbackResume FallbackStreamPolicy = "resume"
+
+	// FallbackRestart regenerates the response on the next provider. If the
+	// new output starts with the text already delivered, only the remainder
+	// is delivered; otherwise the first new chunk has Restart set and the
+	// consumer must discard the text it received so far.
+	FallbackRestart FallbackStreamPolicy = "restart"
+)
+
+// FallbackOptions configures a FallbackClient.
+type FallbackOptions struct {
+	// StreamPolicy controls how interrupted streams continue on the next
+	// provider (default: FallbackResume)
+	StreamPolicy FallbackStreamPolicy
+
+	// ContinuePrompt is the user message asking the next provider to continue
+	// partial output with FallbackResume (default: assistant prefill for
+	// Anthropic, DefaultContinuePrompt otherwise)
+	ContinuePrompt string
+
+	// ShouldFallback reports whether an error should be retried on the next
+	// client (default: every error except context cancellation)
+	ShouldFallback func(err error) bool
+
+	// OnFallback is called when a request moves from one client to the next,
+	// with the client indexes, -1 for a separate HedgeClient, and the error
+	// that caused it (optional)
+	OnFallback func(from, to int, err error)
+
+	// LatencyBudget is how long Complete and ChatComplete wait for the first
+	// client before racing a hedge request against it (optional, 0 disables)
+	LatencyBudget time.Duration
+
+	// HedgeClient serves hedge requests, typically a fast model
+	// (default: the second client)
+	HedgeClient Client
+
+	// HedgeModel overrides the request model for hedge requests (optional)
+	HedgeModel string
+
+	// SessionAffinity keeps the requests of a session, selected with
+	// WithSession, on the client that served its first request, including a
+	// HedgeClient that won it (with HedgeModel), since
+	// switching models mid-conversation degrades quality. A session moves to
+	// another client only when its client fails. Requests of pinned sessions
+	// are not hedged. (optional)
+	SessionAffinity bool
+
+	// SessionAffinityTTL is how long an idle session stays pinned
+	// (default: DefaultSessionAffinityTTL)
+	SessionAffinityTTL time.Duration
+}
+
+// FallbackClient sends requests to a list of clients in order, falling back
+// to the next client when a request fails.
+//
+// FallbackClient is safe for concurrent use if its clients are.
+type FallbackClient struct {
+	clients []Client
+	opts    FallbackOptions
+	pins    *sessionPins // Session pins, nil without SessionAffinity
+}
+
+// NewFallbackClient creates a client that tries clients in order.
+//
+// Streams that fail after delivering part of the response continue on the
+// next client according to opts.StreamPolicy, so content already delivered
+// to the consumer is never duplicated.
+//
+// Example:
+//
+//	primary, _ := NewClient(ProviderAnthropic, anthropicConfig)
+//	secondary, _ := NewClient(ProviderAnthropic, backupConfig)
+//	fallback, err := NewFallbackClient([]Client{primary, secondary}, FallbackOptions{
+//		StreamPolicy: FallbackRestart,
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	resp, err := fallback.ChatComplete(ctx, req)
+//
+// Parameters:
+//   - clients: The clients to try, in order of preference
+//   - opts: Stream policy and fallback hooks
+//
+// Returns:
+//   - *FallbackClient: The fallback client
+//   - error: A validation error if no clients are given or the policy is unknown
+func NewFallbackClient(clients []Client, opts FallbackOptions) (*FallbackClient, error) {
+	if len(clients) == 0 {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "fallback client requires at least one client",
+			Provider: "fallback",
+		}
+	}
+
+	switch opts.StreamPolicy {
+	case "":
+		opts.StreamPolicy = FallbackResume
+	case FallbackResume, FallbackRestart:
+	default:
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("fallback stream policy must be one of: resume, restart, got: %q", opts.StreamPolicy),
+			Provider: "fallback",
+		}
+	}
+
+	if opts.LatencyBudget < 0 {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("latency budget must be non-negative, got: %v", opts.LatencyBudget),
+			Provider: "fallback",
+		}
+	}
+	if opts.LatencyBudget > 0 && opts.HedgeClient == nil && len(clients) < 2 {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "latency budget requires a hedge client or a second client",
+			Provider: "fallback",
+		}
+	}
+
+	f := &FallbackClient{
+		clients: append([]Client(nil), clients...),
+		opts:    opts,
+	}
+	if opts.SessionAffinity {
+		f.pins = newSessionPins(opts.SessionAffinityTTL)
+	}
+	return f, nil
+}
+
+// Complete sends a completion request to each client in turn until one
+// succeeds, returning the last error if all fail. With a latency budget, the
+// first client is raced against the hedge client (see ChatComplete).
+func (f *FallbackClient) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	hedgeReq := req
+	if f.opts.HedgeModel != "" {
+		hedgeReq.Model = f.opts.HedgeModel
+	}
+	send := func(i int) (*CompletionResponse, error) {
+		if i == hedgeIndex {
+			return f.opts.HedgeClient.Complete(ctx, hedgeReq)
+		}
+		return f.clients[i].Complete(ctx, req)
+	}
+	session := SessionFromContext(ctx)
+	if pinned, ok := f.pins.get(session); ok {
+		return tryClients(ctx, f, session, f.pinnedOrder(pinned), nil, send)
+	}
+
+	next := 0
+	var lastErr error
+	if f.opts.LatencyBudget > 0 {
+		hedgeClient, skip := f.hedgeClient()
+		resp, info, err := runHedged(ctx, f.opts.LatencyBudget,
+			func(ctx context.Context) (*CompletionResponse, error) { return f.clients[0].Complete(ctx, req) },
+			func(ctx context.Context) (*CompletionResponse, error) { return hedgeClient.Complete(ctx, hedgeReq) },
+		)
+		if err == nil {
+			resp.Metadata.Hedge = info
+			f.pinHedged(session, info, skip)
+			return resp, nil
+		}
+		lastErr = err
+		next = f.nextAfterHedge(ctx, info, skip, err)
+	}
+
+	return tryClients(ctx, f, session, f.orderFrom(next), lastErr, send)
+}
+
+// ChatComplete sends a chat request to each client in turn until one
+// succeeds, returning the last error if all fail.
+//
+// With a latency budget, if the first client has not answered within the
+// budget, the request is also sent to the hedge client (with HedgeModel if
+// set) and whichever succeeds first is returned while the other is
+// cancelled. Metadata.Hedge reports which path answered and both latencies.
+// If both fail, the remaining clients are tried in order.
+func (f *FallbackClient) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	hedgeReq := req
+	if f.opts.HedgeModel != "" {
+		hedgeReq.Model = f.opts.HedgeModel
+	}
+	send := func(i int) (*ChatResponse, error) {
+		if i == hedgeIndex {
+			return f.opts.HedgeClient.ChatComplete(ctx, hedgeReq)
+		}
+		return f.clients[i].ChatComplete(ctx, req)
+	}
+	session := SessionFromContext(ctx)
+	if pinned, ok := f.pins.get(session); ok {
+		return tryClients(ctx, f, session, f.pinnedOrder(pinned), nil, send)
+	}
+
+	next := 0
+	var lastErr error
+	if f.opts.LatencyBudget > 0 {
+		hedgeClient, skip := f.hedgeClient()
+		resp, info, err := runHedged(ctx, f.opts.LatencyBudget,
+			func(ctx context.Context) (*ChatResponse, error) { return f.clients[0].ChatComplete(ctx, req) },
+			func(ctx context.Context) (*ChatResponse, error) { return hedgeClient.ChatComplete(ctx, hedgeReq) },
+		)
+		if err == nil {
+			resp.Metadata.Hedge = info
+			f.pinHedged(session, info, skip)
+			return resp, nil
+		}
+		lastErr = err
+		next = f.nextAfterHedge(ctx, info, skip, err)
+	}
+
+	return tryClients(ctx, f, session, f.orderFrom(next), lastErr, send)
+}
+
+// tryClients sends a request to the clients at order in turn until one
+// succeeds, pinning the session to it, and returns the last error, starting
+// with lastErr, if all fail
+func tryClients[T any](ctx context.Context, f *FallbackClient, session string, order []int, lastErr error, send func(i int) (T, error)) (T, error) {
+	var zero T
+	for n, i := range order {
+		resp, err := send(i)
+		if err == nil {
+			f.pins.set(session, i)
+			return resp, nil
+		}
+		lastErr = err
+		if !f.shouldFallback(ctx, err) || n == len(order)-1 {
+			break
+		}
+		f.notify(i, order[n+1], err)
+	}
+	return zero, lastErr
+}
+
+// orderFrom returns the indexes of the clients from next on
+func (f *FallbackClient) orderFrom(next int) []int {
+	var order []int
+	for i := next; i < len(f.clients); i++ {
+		order = append(order, i)
+	}
+	return order
+}
+
+// pinnedOrder returns the index of the pinned client followed by the others
+// in order
+func (f *FallbackClient) pinnedOrder(pinned int) []int {
+	order := []int{pinned}
+	for i := range f.clients {
+		if i != pinned {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// pinHedged pins a session to the client that won a hedged request, which
+// is hedgeIndex for a separate HedgeClient
+func (f *FallbackClient) pinHedged(session string, info *HedgeInfo, skip bool) {
+	switch {
+	case info.Winner == "primary":
+		f.pins.set(session, 0)
+	case skip:
+		f.pins.set(session, 1)
+	default:
+		f.pins.set(session, hedgeIndex)
+	}
+}
+
+// hedgeIndex is the client index of a separate HedgeClient, which sessions
+// it answered are pinned to
+const hedgeIndex = -1
+
+// hedgeClient returns the client serving hedge requests and whether it is
+// the second client, which then must not be tried again
+func (f *FallbackClient) hedgeClient() (Client, bool) {
+	if f.opts.HedgeClient != nil {
+		return f.opts.HedgeClient, false
+	}
+	return f.clients[1], true
+}
+
+// nextAfterHedge returns the index of the client to try after a failed
+// hedged request, or len(clients) if no further client should be tried
+func (f *FallbackClient) nextAfterHedge(ctx context.Context, info *HedgeInfo, skip bool, err error) int {
+	next := 1
+	if skip && info.Triggered {
+		next = 2
+	}
+	if next >= len(f.clients) || !f.shouldFallback(ctx, err) {
+		return len(f.clients)
+	}
+	f.notify(0, next, err)
+	return next
+}
+
+// StreamChat opens a stream on the first client that accepts the request,
+// or on the pinned client of its session first with SessionAffinity.
+// If the stream fails part way, it continues on the next client according
+// to the stream policy.
+func (f *FallbackClient) StreamChat(ctx context.Context, req ChatRequest) (*FallbackStream, error) {
+	stream := &FallbackStream{
+		client:  f,
+		ctx:     ctx,
+		req:     req,
+		session: SessionFromContext(ctx),
+		index:   -1,
+		pos:     -1,
+	}
+	if pinned, ok := f.pins.get(stream.session); ok {
+		stream.order = f.pinnedOrder(pinned)
+	} else {
+		stream.order = f.orderFrom(0)
+	}
+	if err := stream.advance(nil); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// shouldFallback reports whether err should be retried on the next client
+func (f *FallbackClient) shouldFallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if f.opts.ShouldFallback != nil {
+		return f.opts.ShouldFallback(err)
+	}
+	return true
+}
+
+// notify reports a fallback to the OnFallback hook
+func (f *FallbackClient) notify(from, to int, err error) {
+	if f.opts.OnFallback != nil {
+		f.opts.OnFallback(from, to, err)
+	}
+}
+
+// FallbackStream is a streamed chat response served by a FallbackClient.
+//
+// It behaves like ChatStream; chunks with Restart set are only produced by
+// the FallbackRestart policy.
+type FallbackStream struct {
+	client  *FallbackClient
+	ctx     context.Context
+	req     ChatRequest
+	session string
+	order   []int // Indexes of the clients to try, in order
+	pos     int   // Position in order of the serving client
+	index   int   // Index of the serving client
+
+	mu     sync.Mutex // Guards stream and closed for Close
+	stream *ChatStream
+	closed bool
+
+	seam         streamSeam
+	content      strings.Builder
+	finishReason string
+	stopSequence *string
+	usage        Usage
+	metadata     ResponseMetadata
+	pendingErr   error
+	err          error
+}
+
+// Recv returns the next chunk, or io.EOF once the stream is complete
+func (s *FallbackStream) Recv() (StreamChunk, error) {
+	for s.err == nil {
+		if s.isClosed() {
+			s.err = errStreamClosed
+			break
+		}
+		if err := s.pendingErr; err != nil {
+			s.pendingErr = nil
+			if !s.client.shouldFallback(s.ctx, err) || s.pos == len(s.order)-1 {
+				s.err = err
+				break
+			}
+			if advanceErr := s.advance(err); advanceErr != nil {
+				s.err = advanceErr
+			}
+			continue
+		}
+
+		chunk, err := s.stream.Recv()
+		if err == nil {
+			var restart bool
+			chunk.Delta, restart = s.seam.push(chunk.Delta)
+			chunk.Restart = chunk.Restart || restart
+			if chunk.Delta == "" && !chunk.Restart && chunk.FinishReason == "" && chunk.Usage == nil && chunk.Metadata == nil {
+				continue
+			}
+			s.accumulate(chunk)
+			return chunk, nil
+		}
+
+		if err == io.EOF {
+			s.err = io.EOF
+		} else {
+			s.pendingErr = err
+		}
+		// Deliver output held back for deduplication before the error
+		if delta, restart, ok := s.seam.flush(); ok {
+			chunk := StreamChunk{Delta: delta, Restart: restart}
+			s.accumulate(chunk)
+			return chunk, nil
+		}
+	}
+	return StreamChunk{}, s.err
+}
+
+// Response returns the response aggregated from the chunks received so far.
+// Usage is the total across all clients that reported it.
+func (s *FallbackStream) Response() *ChatResponse {
+	return &ChatResponse{
+		Message: Message{
+			Role:    "assistant",
+			Content: s.content.String(),
+		},
+		Usage:               s.usage,
+		FinishReason:        s.finishReason,
+		MatchedStopSequence: s.stopSequence,
+		Metadata:            s.metadata,
+	}
+}
+
+// Client returns the index of the client currently serving the stream
+func (s *FallbackStream) Client() int {
+	return s.index
+}
+
+// Close releases the underlying connection and stops the stream from
+// falling back to further clients; Recv then returns an error. It is safe
+// to call more than once and from another goroutine.
+func (s *FallbackStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.stream == nil {
+		return nil
+	}
+	return s.stream.Close()
+}
+
+// isClosed reports whether Close has been called
+func (s *FallbackStream) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// accumulate adds a delivered chunk to the aggregated response
+func (s *FallbackStream) accumulate(chunk StreamChunk) {
+	if chunk.Restart {
+		s.content.Reset()
+	}
+	s.content.WriteString(chunk.Delta)
+	if chunk.FinishReason != "" {
+		s.finishReason = chunk.FinishReason
+		s.stopSequence = chunk.MatchedStopSequence
+	}
+	if chunk.Usage != nil {
+		s.usage = addUsage(s.usage, *chunk.Usage)
+	}
+	if chunk.Metadata != nil {
+		s.metadata = *chunk.Metadata
+	}
+}
+
+// advance opens the stream on the next client that accepts the request
+func (s *FallbackStream) advance(cause error) error {
+	s.mu.Lock()
+	if s.stream != nil {
+		s.stream.Close()
+	}
+	s.mu.Unlock()
+
+	for s.pos+1 < len(s.order) {
+		if s.isClosed() {
+			return errStreamClosed
+		}
+		from := s.index
+		s.pos++
+		s.index = s.order[s.pos]
+		if cause != nil {
+			s.client.notify(from, s.index, cause)
+		}
+
+		next, req := s.client.streamTarget(s.index, s.req)
+		if delivered := s.content.String(); delivered != "" {
+			s.seam.start(s.client.opts.StreamPolicy, delivered)
+			if s.client.opts.StreamPolicy == FallbackResume {
+				req.Messages = continuationMessages(providerOf(next), s.req.Messages, delivered, s.client.opts.ContinuePrompt)
+			}
+		}
+
+		stream, err := next.StreamChat(s.ctx, req)
+		if err == nil {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.closed {
+				// Closed while opening, so nobody will read this stream
+				stream.Close()
+				return errStreamClosed
+			}
+			s.stream = stream
+			s.client.pins.set(s.session, s.index)
+			return nil
+		}
+		if !s.client.shouldFallback(s.ctx, err) {
+			return err
+		}
+		cause = err
+	}
+	return cause
+}
+
+// streamTarget returns the client at index and the request to stream from
+// it, with HedgeModel for a separate HedgeClient
+func (f *FallbackClient) streamTarget(index int, req ChatRequest) (Client, ChatRequest) {
+	if index != hedgeIndex {
+		return f.clients[index], req
+	}
+	if f.opts.HedgeModel != "" {
+		req.Model = f.opts.HedgeModel
+	}
+	return f.opts.HedgeClient, req
+}
+
+// providerOf returns the provider of a client, or "" if unknown
+func providerOf(c Client) ProviderType {
+	if impl, ok := c.(*client); ok {
+		return impl.provider
+	}
+	return ""
+}
+
+// streamSeam holds back the start of a fallback stream until it is known
+// which part of it was already delivered
+type streamSeam struct {
+	policy    FallbackStreamPolicy
+	delivered string
+	pending   strings.Builder
+	active    bool
+}
+
+// start begins deduplicating a new stream against delivered text
+func (s *streamSeam) start(policy FallbackStreamPolicy, delivered string) {
+	s.policy = policy
+	s.delivered = delivered
+	s.pending.Reset()
+	s.active = true
+}
+
+// push adds a delta from the new stream and returns the text to deliver now,
+// and whether delivered text must be discarded first
+func (s *streamSeam) push(delta string) (string, bool) {
+	if !s.active {
+		return delta, false
+	}
+	s.pending.WriteString(delta)
+	pending := s.pending.String()
+
+	if s.policy == FallbackRestart {
+		if strings.HasPrefix(s.delivered, pending) && len(pending) < len(s.delivered) {
+			return "", false
+		}
+		s.active = false
+		if strings.HasPrefix(pending, s.delivered) {
+			return pending[len(s.delivered):], false
+		}
+		return pending, true
+	}
+
+	if len(pending) < maxStitchOverlap {
+		return "", false
+	}
+	s.active = false
+	return stitch(s.delivered, pending)[len(s.delivered):], false
+}
+
+// flush returns the held back text when the new stream ends, reporting
+// false if nothing is held back
+func (s *streamSeam) flush() (string, bool, bool) {
+	if !s.active {
+		return "", false, false
+	}
+	s.active = false
+	pending := s.pending.String()
+
+	if s.policy == FallbackRestart {
+		// The new output ended before reproducing the delivered text
+		return pending, true, true
+	}
+	if pending == "" {
+		return "", false, false
+	}
+	return stitch(s.delivered, pending)[len(s.delivered):], false, true
+}
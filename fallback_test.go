@@ -0,0 +1,272 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// collect reads a fallback stream to the end, applying restarts like a consumer would
+func collect(t *testing.T, stream *FallbackStream) (string, int) {
+	t.Helper()
+	var text string
+	restarts := 0
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return text, restarts
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if chunk.Restart {
+			text = ""
+			restarts++
+		}
+		text += chunk.Delta
+	}
+}
+
+// deltas builds a stream from text deltas, ending with err or a finish chunk
+func deltas(err error, parts ...string) *sliceStream {
+	stream := &sliceStream{err: err}
+	for _, part := range parts {
+		stream.chunks = append(stream.chunks, StreamChunk{Delta: part})
+	}
+	if err == nil {
+		stream.chunks = append(stream.chunks, StreamChunk{FinishReason: "end_turn", Usage: &Usage{TotalTokens: 10}})
+	}
+	return stream
+}
+
+func TestFallbackClient_ChatComplete(t *testing.T) {
+	failing := newMockClient(ProviderAnthropic, &mockAdapter{err: NewError(ErrorTypeProvider, "anthropic", "overloaded")})
+	working := newMockClient(ProviderAnthropic, &mockAdapter{chatResp: &ChatResponse{Message: Message{Content: "ok"}}})
+
+	var fallbacks [][2]int
+	f, err := NewFallbackClient([]Client{failing, working}, FallbackOptions{
+		OnFallback: func(from, to int, err error) { fallbacks = append(fallbacks, [2]int{from, to}) },
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := f.ChatComplete(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Message.Content != "ok" {
+		t.Errorf("Expected response from the second client, got %q", resp.Message.Content)
+	}
+	if len(fallbacks) != 1 || fallbacks[0] != [2]int{0, 1} {
+		t.Errorf("Expected one fallback from 0 to 1, got %v", fallbacks)
+	}
+
+	f, _ = NewFallbackClient([]Client{failing, failing}, FallbackOptions{})
+	if _, err := f.ChatComplete(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}); err == nil {
+		t.Errorf("Expected error when all clients fail")
+	}
+
+	if _, err := NewFallbackClient(nil, FallbackOptions{}); err == nil {
+		t.Errorf("Expected error without clients")
+	}
+	if _, err := NewFallbackClient([]Client{working}, FallbackOptions{StreamPolicy: "merge"}); err == nil {
+		t.Errorf("Expected error for unknown stream policy")
+	}
+}
+
+func TestFallbackClient_StreamResume(t *testing.T) {
+	primary := &streamingAdapter{streams: []*sliceStream{deltas(ErrStreamStalled, "The quick ", "brown ")}}
+	secondary := &streamingAdapter{streams: []*sliceStream{deltas(nil, "quick brown ", "fox jumps.")}}
+
+	f, _ := NewFallbackClient([]Client{
+		newMockClient(ProviderAnthropic, primary),
+		newMockClient(ProviderAnthropic, secondary),
+	}, FallbackOptions{})
+
+	stream, err := f.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Pangram"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	text, restarts := collect(t, stream)
+	if text != "The quick brown fox jumps." || restarts != 0 {
+		t.Errorf("Expected deduplicated text without restarts, got %q (%d restarts)", text, restarts)
+	}
+	if stream.Response().Message.Content != text || stream.Client() != 1 {
+		t.Errorf("Unexpected aggregated response %+v from client %d", stream.Response(), stream.Client())
+	}
+
+	continued := secondary.streamRequests[0].Messages
+	if len(continued) != 2 || continued[1].Role != "assistant" || continued[1].Content != "The quick brown" {
+		t.Errorf("Expected the partial output as assistant prefill, got %+v", continued)
+	}
+}
+
+func TestFallbackClient_StreamRestart(t *testing.T) {
+	tests := []struct {
+		name      string
+		secondary *sliceStream
+		expected  string
+		restarts  int
+	}{
+		{
+			name:      "identical prefix is not repeated",
+			secondary: deltas(nil, "Hello ", "world"),
+			expected:  "Hello world",
+		},
+		{
+			name:      "diverging output restarts",
+			secondary: deltas(nil, "Greetings"),
+			expected:  "Greetings",
+			restarts:  1,
+		},
+		{
+			name:      "shorter output restarts",
+			secondary: deltas(nil, "Hel"),
+			expected:  "Hel",
+			restarts:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &streamingAdapter{streams: []*sliceStream{deltas(io.ErrUnexpectedEOF, "Hello wo")}}
+			secondary := &streamingAdapter{streams: []*sliceStream{tt.secondary}}
+
+			f, _ := NewFallbackClient([]Client{
+				newMockClient(ProviderAnthropic, primary),
+				newMockClient(ProviderAnthropic, secondary),
+			}, FallbackOptions{StreamPolicy: FallbackRestart})
+
+			stream, err := f.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Greet"}}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			text, restarts := collect(t, stream)
+			if text != tt.expected || restarts != tt.restarts {
+				t.Errorf("Expected %q with %d restarts, got %q with %d", tt.expected, tt.restarts, text, restarts)
+			}
+			if stream.Response().Message.Content != tt.expected {
+				t.Errorf("Expected aggregated content %q, got %q", tt.expected, stream.Response().Message.Content)
+			}
+			if len(secondary.streamRequests[0].Messages) != 1 {
+				t.Errorf("Expected the original request to be regenerated")
+			}
+		})
+	}
+}
+
+func TestFallbackClient_StreamShouldFallback(t *testing.T) {
+	authErr := NewError(ErrorTypeAuth, "anthropic", "bad key")
+	primary := &streamingAdapter{streams: []*sliceStream{deltas(authErr, "Hi")}}
+	secondary := &streamingAdapter{streams: []*sliceStream{deltas(nil, "Hello")}}
+
+	f, _ := NewFallbackClient([]Client{
+		newMockClient(ProviderAnthropic, primary),
+		newMockClient(ProviderAnthropic, secondary),
+	}, FallbackOptions{
+		ShouldFallback: func(err error) bool {
+			var aiErr *Error
+			return errors.As(err, &aiErr) && aiErr.IsRetryable()
+		},
+	})
+
+	stream, err := f.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if chunk, err := stream.Recv(); err != nil || chunk.Delta != "Hi" {
+		t.Fatalf("Expected first chunk, got %+v, %v", chunk, err)
+	}
+	if _, err := stream.Recv(); err != authErr {
+		t.Errorf("Expected auth error without fallback, got %v", err)
+	}
+	if len(secondary.streamRequests) != 0 {
+		t.Errorf("Expected no request to the secondary client")
+	}
+}
+
+func TestFallbackClient_StreamClose(t *testing.T) {
+	primary := &streamingAdapter{streams: []*sliceStream{deltas(NewError(ErrorTypeProvider, "anthropic", "overloaded"), "Hi")}}
+	secondary := &streamingAdapter{streams: []*sliceStream{deltas(nil, "Hello")}}
+	f, _ := NewFallbackClient([]Client{
+		newMockClient(ProviderAnthropic, primary),
+		newMockClient(ProviderAnthropic, secondary),
+	}, FallbackOptions{})
+
+	stream, err := f.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if chunk, err := stream.Recv(); err != nil || chunk.Delta != "Hi" {
+		t.Fatalf("Expected first chunk, got %+v, %v", chunk, err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A closed stream must not fall back and open a new stream
+	if _, err := stream.Recv(); err != errStreamClosed {
+		t.Errorf("Expected errStreamClosed after Close, got %v", err)
+	}
+	if len(secondary.streamRequests) != 0 {
+		t.Errorf("Expected no request to the secondary client after Close, got %d", len(secondary.streamRequests))
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("Expected a second Close to succeed, got %v", err)
+	}
+}
+
+func TestFallbackClient_SessionAffinity(t *testing.T) {
+	primary := &mockAdapter{err: NewError(ErrorTypeProvider, "anthropic", "overloaded"), chatResp: &ChatResponse{Message: Message{Content: "primary"}}}
+	secondary := &mockAdapter{chatResp: &ChatResponse{Message: Message{Content: "secondary"}}}
+	f, err := NewFallbackClient([]Client{
+		newMockClient(ProviderAnthropic, primary),
+		newMockClient(ProviderAnthropic, secondary),
+	}, FallbackOptions{SessionAffinity: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}
+	send := func(session string) string {
+		t.Helper()
+		ctx := context.Background()
+		if session != "" {
+			ctx = WithSession(ctx, session)
+		}
+		resp, err := f.ChatComplete(ctx, req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return resp.Message.Content
+	}
+
+	// The first turn falls back and pins the session to the secondary
+	if got := send("conv-1"); got != "secondary" {
+		t.Fatalf("Expected the secondary to serve the first turn, got %q", got)
+	}
+	primary.err = nil
+	if got := send("conv-1"); got != "secondary" {
+		t.Errorf("Expected the session to stay on the secondary, got %q", got)
+	}
+	if got := send("conv-2"); got != "primary" {
+		t.Errorf("Expected a new session to start on the primary, got %q", got)
+	}
+	if got := send(""); got != "primary" {
+		t.Errorf("Expected requests without a session to start on the primary, got %q", got)
+	}
+
+	// A failing pinned client moves the session
+	secondary.err = NewError(ErrorTypeProvider, "anthropic", "overloaded")
+	if got := send("conv-1"); got != "primary" {
+		t.Errorf("Expected the session to move to the primary, got %q", got)
+	}
+	secondary.err = nil
+	if got := send("conv-1"); got != "primary" {
+		t.Errorf("Expected the session to stay on the primary, got %q", got)
+	}
+}
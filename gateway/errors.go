@@ -0,0 +1,154 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+// RequestIDHeader carries the ID of a gateway request. Callers may set it to
+// correlate their logs with the gateway's; otherwise the gateway generates
+// one. It is echoed in every response and in error bodies.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs set by callers
+const maxRequestIDLength = 128
+
+// ErrorBody is the JSON body of gateway errors, sent as {"error": ErrorBody}
+// over HTTP and in "error" events and messages.
+//
+// Errors carry the same normalized type and code Go callers see on
+// aiprovider.Error, and HTTP responses use a status matching the type:
+//
+//   - validation, token_limit: 400 Bad Request
+//   - rate_limit: 429 Too Many Requests, with a Retry-After header when
+//     retry_after is set; requests rejected by a spend budget have the code
+//     "budget_exceeded"
+//   - authentication: 401 Unauthorized for the gateway's own bearer tokens,
+//     502 Bad Gateway when the provider rejected the gateway's credentials
+//   - network: 504 Gateway Timeout for timeouts, 502 Bad Gateway otherwise
+//   - provider: 502 Bad Gateway, or 503 Service Unavailable with the code
+//     "client_closed" while the gateway shuts down
+type ErrorBody struct {
+	// Type is the category of the error, see aiprovider.ErrorType
+	Type string `json:"type,omitempty"`
+
+	// Code is the provider-specific or gateway error code (optional)
+	Code string `json:"code,omitempty"`
+
+	// Message describes the error
+	Message string `json:"message"`
+
+	// RetryAfter is how many seconds to wait before retrying (optional)
+	RetryAfter *int `json:"retry_after,omitempty"`
+
+	// RequestID identifies the gateway request, as in the X-Request-ID
+	// header; errors on a WebSocket carry the ID of its handshake
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// errorBody describes err for callers
+func errorBody(err error, requestID string) *ErrorBody {
+	body := &ErrorBody{Type: string(aiprovider.ErrorTypeProvider), Message: err.Error(), RequestID: requestID}
+
+	var e *aiprovider.Error
+	var budget *aiprovider.BudgetExceededError
+	switch {
+	case errors.As(err, &budget):
+		retryAfter := int(math.Ceil(time.Until(budget.ResetAt).Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		body.Type = string(aiprovider.ErrorTypeRateLimit)
+		body.Code = "budget_exceeded"
+		body.RetryAfter = &retryAfter
+	case errors.Is(err, aiprovider.ErrClientClosed):
+		body.Code = "client_closed"
+	case errors.As(err, &e):
+		body.Type = string(e.Type)
+		body.Code = e.Code
+		body.Message = e.Message
+		body.RetryAfter = e.RetryAfter
+	case errors.Is(err, context.DeadlineExceeded):
+		body.Type = string(aiprovider.ErrorTypeNetwork)
+	}
+	return body
+}
+
+// errorStatus returns the HTTP status of a client error, see ErrorBody
+func errorStatus(err error) int {
+	var e *aiprovider.Error
+	switch {
+	case errors.As(err, new(*aiprovider.BudgetExceededError)):
+		return http.StatusTooManyRequests
+	case errors.Is(err, aiprovider.ErrClientClosed):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case !errors.As(err, &e):
+		return http.StatusBadGateway
+	}
+
+	switch e.Type {
+	case aiprovider.ErrorTypeValidation, aiprovider.ErrorTypeTokenLimit:
+		return http.StatusBadRequest
+	case aiprovider.ErrorTypeRateLimit:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// gatewayError is an error of the gateway itself rather than the client
+func gatewayError(errorType aiprovider.ErrorType, message string) error {
+	return &aiprovider.Error{Type: errorType, Message: message}
+}
+
+// writeClientError responds with an error returned by the client
+func writeClientError(w http.ResponseWriter, err error) {
+	writeError(w, errorStatus(err), err)
+}
+
+// writeError responds with status and err as an error body, and a
+// Retry-After header if the error suggests a delay
+func writeError(w http.ResponseWriter, status int, err error) {
+	body := errorBody(err, w.Header().Get(RequestIDHeader))
+	if body.RetryAfter != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(*body.RetryAfter))
+	}
+	writeJSON(w, status, map[string]*ErrorBody{"error": body})
+}
+
+// requestID returns the caller's request ID, or a new one if it set none or
+// an unusable one
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); id != "" && len(id) <= maxRequestIDLength && printable(id) {
+		return id
+	}
+	return newRequestID()
+}
+
+// newRequestID returns a random request ID
+func newRequestID() string {
+	var b [12]byte
+	rand.Read(b[:])
+	return "req_" + hex.EncodeToString(b[:])
+}
+
+// printable reports whether s is printable ASCII, so it is safe to echo in
+// headers and logs
+func printable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,347 @@
+// Package gateway serves a Client over HTTP, so services in other languages
+// and browsers share one configured client, with its credentials, limits and
+// metrics, instead of calling providers directly.
+//
+// Endpoints:
+//
+//   - POST /v1/chat: a ChatRequest as JSON, answered with a ChatResponse
+//   - POST /v1/chat/stream: a ChatRequest as JSON, answered with server-sent
+//     events carrying StreamChunks, then the final ChatResponse
+//   - GET /v1/chat/ws: a WebSocket carrying any number of streamed chats,
+//     see Message for the protocol
+//
+// During long generations the gateway sends heartbeats, a "heartbeat" event
+// or message, whenever a stream has been silent for HeartbeatInterval, so
+// proxies and browsers do not close the connection as idle.
+//
+// Errors are sent as {"error": ErrorBody}, with the normalized type and code
+// of aiprovider.Error and an HTTP status matching the type, so callers in
+// other languages handle them like Go callers do; see ErrorBody.
+//
+// Callers authenticate with "Authorization: Bearer <token>" and send
+// requests as application/json. Browsers cannot set headers on WebSockets,
+// so the WebSocket endpoint also accepts the token in the access_token query
+// parameter, and only from pages of the AllowedOrigins.
+//
+// Example:
+//
+//	handler := gateway.New(client, gateway.Options{Tokens: []string{os.Getenv("GATEWAY_TOKEN")}})
+//	log.Fatal(http.ListenAndServe(":8080", handler))
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+)
+
+const (
+	// DefaultPingInterval is how often WebSocket connections are pinged when
+	// Options.PingInterval is zero
+	DefaultPingInterval = 30 * time.Second
+
+	// DefaultHeartbeatInterval is how long a stream may be silent before a
+	// heartbeat is sent when Options.HeartbeatInterval is zero
+	DefaultHeartbeatInterval = 15 * time.Second
+
+	// DefaultMaxRequestSize is the largest request body or WebSocket message
+	// accepted when Options.MaxRequestSize is zero
+	DefaultMaxRequestSize = 1 << 20
+
+	// DefaultMaxChatsPerConnection is the number of chats a WebSocket
+	// connection may run at once when Options.MaxChatsPerConnection is zero
+	DefaultMaxChatsPerConnection = 16
+)
+
+// Options configures a gateway Server.
+type Options struct {
+	// Tokens are the bearer tokens callers may authenticate with
+	Tokens []string
+
+	// Authenticate validates a caller's bearer token, e.g. against a token
+	// service; used instead of Tokens when set
+	Authenticate func(r *http.Request, token string) bool
+
+	// AllowUnauthenticated serves callers without checking tokens, e.g.
+	// behind a proxy that authenticates them; without it a Server with
+	// neither Tokens nor Authenticate rejects every request
+	AllowUnauthenticated bool
+
+	// PingInterval is how often idle WebSocket connections are pinged; a
+	// connection that sends nothing, not even a pong, for two intervals is
+	// closed
+	// Default: 30 seconds if not specified
+	PingInterval time.Duration
+
+	// MaxRequestSize is the largest request body or WebSocket message in
+	// bytes
+	// Default: 1 MiB if not specified
+	MaxRequestSize int64
+
+	// HeartbeatInterval is how long a streamed chat may send nothing before
+	// the gateway sends a heartbeat; negative disables heartbeats
+	// Default: 15 seconds if not specified
+	HeartbeatInterval time.Duration
+
+	// AllowedOrigins are the origins, such as "https://app.example.com",
+	// whose pages may open WebSockets; "*" allows any. Requests without an
+	// Origin header, which browsers always send, are allowed
+	// Default: the gateway's own origin only if not specified
+	AllowedOrigins []string
+
+	// MaxChatsPerConnection is the number of chats a WebSocket connection
+	// may run at once; further chat messages are answered with an error
+	// Default: 16 if not specified
+	MaxChatsPerConnection int
+
+	// PrepareRequest sets the server-controlled fields of a caller's chat
+	// request, such as Project, Tags or UserID, e.g. from the authenticated
+	// caller (optional). The gateway clears those fields in every request it
+	// receives first, so callers cannot bill another project's key or spoof
+	// cost attribution
+	PrepareRequest func(r *http.Request, req *aiprovider.ChatRequest)
+}
+
+// Server serves a Client over HTTP and WebSocket; see the package
+// documentation for the endpoints.
+type Server struct {
+	client aiprovider.Client
+	opts   Options
+	mux    *http.ServeMux
+}
+
+// New returns a Server sending requests to client
+func New(client aiprovider.Client, opts Options) *Server {
+	if opts.PingInterval <= 0 {
+		opts.PingInterval = DefaultPingInterval
+	}
+	if opts.MaxRequestSize <= 0 {
+		opts.MaxRequestSize = DefaultMaxRequestSize
+	}
+	if opts.HeartbeatInterval == 0 {
+		opts.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+	if opts.MaxChatsPerConnection <= 0 {
+		opts.MaxChatsPerConnection = DefaultMaxChatsPerConnection
+	}
+
+	s := &Server{client: client, opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/v1/chat", s.handleChat)
+	s.mux.HandleFunc("/v1/chat/stream", s.handleStream)
+	s.mux.HandleFunc("/v1/chat/ws", s.handleWebSocket)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(RequestIDHeader, requestID(r))
+	if !s.authenticate(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="aiprovider"`)
+		writeError(w, http.StatusUnauthorized, gatewayError(aiprovider.ErrorTypeAuth, "missing or invalid bearer token"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// authenticate reports whether the request carries a valid bearer token
+func (s *Server) authenticate(r *http.Request) bool {
+	if s.opts.AllowUnauthenticated {
+		return true
+	}
+
+	token := ""
+	if header := r.Header.Get("Authorization"); len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		token = strings.TrimSpace(header[7:])
+	} else if r.URL.Path == "/v1/chat/ws" {
+		token = r.URL.Query().Get("access_token")
+	}
+	if token == "" {
+		return false
+	}
+
+	if s.opts.Authenticate != nil {
+		return s.opts.Authenticate(r, token)
+	}
+	for _, valid := range s.opts.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(valid)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// handleChat serves POST /v1/chat
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	resp, err := s.client.ChatComplete(r.Context(), req)
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleStream serves POST /v1/chat/stream as server-sent events: a "chunk"
+// event for each StreamChunk, then a "done" event with the ChatResponse or
+// an "error" event, with "heartbeat" events while the stream is silent
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, gatewayError(aiprovider.ErrorTypeProvider, "streaming not supported"))
+		return
+	}
+
+	stream, err := s.client.StreamChat(r.Context(), req)
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+	defer stream.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	s.relay(stream, func(chunk aiprovider.StreamChunk, err error) bool {
+		switch {
+		case err == io.EOF:
+			writeEvent(w, "done", stream.Response())
+		case err != nil:
+			writeEvent(w, "error", errorBody(err, w.Header().Get(RequestIDHeader)))
+		default:
+			writeEvent(w, "chunk", chunk)
+		}
+		flusher.Flush()
+		return err == nil
+	}, func() bool {
+		writeEvent(w, "heartbeat", heartbeat{Time: time.Now().UTC()})
+		flusher.Flush()
+		return r.Context().Err() == nil
+	})
+}
+
+// heartbeat is the payload of heartbeat events
+type heartbeat struct {
+	Time time.Time `json:"time"`
+}
+
+// streamResult is the outcome of one Recv call
+type streamResult struct {
+	chunk aiprovider.StreamChunk
+	err   error
+}
+
+// relay receives a stream's chunks in the background and passes each to
+// handle until it returns false, calling beat whenever the stream has been
+// silent for the heartbeat interval until beat returns false. The caller
+// must close the stream after relay returns, to end a pending Recv.
+func (s *Server) relay(stream *aiprovider.ChatStream, handle func(aiprovider.StreamChunk, error) bool, beat func() bool) {
+	results := make(chan streamResult)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			chunk, err := stream.Recv()
+			select {
+			case results <- streamResult{chunk, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var heartbeats <-chan time.Time
+	var timer *time.Timer
+	if s.opts.HeartbeatInterval > 0 {
+		timer = time.NewTimer(s.opts.HeartbeatInterval)
+		defer timer.Stop()
+		heartbeats = timer.C
+	}
+	for {
+		select {
+		case result := <-results:
+			if !handle(result.chunk, result.err) {
+				return
+			}
+		case <-heartbeats:
+			if !beat() {
+				return
+			}
+		}
+		if timer != nil {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(s.opts.HeartbeatInterval)
+		}
+	}
+}
+
+// decodeRequest reads the ChatRequest of a POST request, responding with an
+// error and reporting false if there is none
+func (s *Server) decodeRequest(w http.ResponseWriter, r *http.Request) (aiprovider.ChatRequest, bool) {
+	var req aiprovider.ChatRequest
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, gatewayError(aiprovider.ErrorTypeValidation, "use POST"))
+		return req, false
+	}
+	// Browsers send cross-site form posts without a preflight, but never
+	// with a JSON content type
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, gatewayError(aiprovider.ErrorTypeValidation, "Content-Type must be application/json"))
+		return req, false
+	}
+	body := http.MaxBytesReader(w, r.Body, s.opts.MaxRequestSize)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, gatewayError(aiprovider.ErrorTypeValidation, fmt.Sprintf("invalid chat request: %v", err)))
+		return req, false
+	}
+	s.prepareRequest(r, &req)
+	return req, true
+}
+
+// prepareRequest clears the fields of a caller's request that select
+// credentials or attribute cost, then lets Options.PrepareRequest set them
+func (s *Server) prepareRequest(r *http.Request, req *aiprovider.ChatRequest) {
+	req.Project = ""
+	req.Tags = nil
+	req.UserID = ""
+	if s.opts.PrepareRequest != nil {
+		s.opts.PrepareRequest(r, req)
+	}
+}
+
+// writeJSON responds with status and v as JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeEvent writes a server-sent event with v as JSON data
+func writeEvent(w io.Writer, event string, v interface{}) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
@@ -0,0 +1,522 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+	"github.com/ajeet-kumar1087/ai-providers/internal/websocket"
+)
+
+// newTestGateway serves a client backed by a fake Anthropic API through a
+// gateway accepting the token "secret"
+func newTestGateway(t *testing.T, opts Options) *httptest.Server {
+	t.Helper()
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stream   bool `json:"stream"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"model\":\"claude-3-haiku-20240307\",\"usage\":{\"input_tokens\":5,\"output_tokens\":1}}}\n\n")
+			fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n")
+			if len(body.Messages) > 0 && body.Messages[0].Content == "Think slowly" {
+				// Pause mid-generation, as long generations do
+				w.(http.Flusher).Flush()
+				time.Sleep(150 * time.Millisecond)
+			}
+			fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\" there\"}}\n\n")
+			fmt.Fprint(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":2}}\n\n")
+			fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Hi there"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":2}}`)
+	}))
+	t.Cleanup(provider.Close)
+
+	client, err := aiprovider.NewClient(aiprovider.ProviderAnthropic, aiprovider.Config{
+		APIKey:  "sk-ant-test-key-1234567890",
+		BaseURL: provider.URL,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	if opts.Tokens == nil {
+		opts.Tokens = []string{"secret"}
+	}
+	server := httptest.NewServer(New(client, opts))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// chatBody is a chat request body for the fake provider
+const chatBody = `{"messages": [{"role": "user", "content": "Hello"}]}`
+
+func TestChat(t *testing.T) {
+	server := newTestGateway(t, Options{})
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat", strings.NewReader(chatBody))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var chat aiprovider.ChatResponse
+	json.NewDecoder(resp.Body).Decode(&chat)
+	if resp.StatusCode != http.StatusOK || chat.Message.Content != "Hi there" {
+		t.Errorf("Expected the response, got status %d and %+v", resp.StatusCode, chat)
+	}
+}
+
+func TestChat_Unauthorized(t *testing.T) {
+	server := newTestGateway(t, Options{})
+
+	for _, header := range []string{"", "Bearer wrong"} {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat", strings.NewReader(chatBody))
+		req.Header.Set("Content-Type", "application/json")
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected status 401, got %d", header, resp.StatusCode)
+		}
+	}
+}
+
+// recordingClient is a Client recording the chat requests it receives
+type recordingClient struct {
+	aiprovider.Client
+	requests []aiprovider.ChatRequest
+}
+
+func (c *recordingClient) ChatComplete(ctx context.Context, req aiprovider.ChatRequest) (*aiprovider.ChatResponse, error) {
+	c.requests = append(c.requests, req)
+	return &aiprovider.ChatResponse{Message: aiprovider.Message{Role: "assistant", Content: "Hi"}}, nil
+}
+
+func TestChat_CallerCannotSelectProject(t *testing.T) {
+	send := func(opts Options) aiprovider.ChatRequest {
+		client := &recordingClient{}
+		opts.Tokens = []string{"secret"}
+		server := httptest.NewServer(New(client, opts))
+		defer server.Close()
+
+		body := `{"project": "other", "tags": {"team": "billing"}, "user_id": "someone-else", "messages": [{"role": "user", "content": "Hello"}]}`
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || len(client.requests) != 1 {
+			t.Fatalf("Expected one forwarded request, got status %d and %d requests", resp.StatusCode, len(client.requests))
+		}
+		return client.requests[0]
+	}
+
+	// The project would select the key of Config.ProjectKeys["other"]
+	if req := send(Options{}); req.Project != "" || req.Tags != nil || req.UserID != "" {
+		t.Errorf("Expected the caller's project, tags and user to be dropped, got %+v", req)
+	}
+
+	// The server may still route requests itself
+	req := send(Options{PrepareRequest: func(r *http.Request, req *aiprovider.ChatRequest) {
+		if req.Project != "" {
+			t.Errorf("Expected the caller's project to be cleared first, got %q", req.Project)
+		}
+		req.Project = "team-" + r.Header.Get("Authorization")[len("Bearer "):]
+	}})
+	if req.Project != "team-secret" || req.Messages[0].Content != "Hello" {
+		t.Errorf("Expected the server's project, got %+v", req)
+	}
+}
+
+// failingClient is a Client whose chats fail with err
+type failingClient struct {
+	aiprovider.Client
+	err error
+}
+
+func (f *failingClient) ChatComplete(ctx context.Context, req aiprovider.ChatRequest) (*aiprovider.ChatResponse, error) {
+	return nil, f.err
+}
+
+func TestChat_Errors(t *testing.T) {
+	retryAfter := 30
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		body       ErrorBody
+		retryAfter string
+	}{
+		{"validation", &aiprovider.Error{Type: aiprovider.ErrorTypeValidation, Message: "messages are required", Provider: "openai"}, 400, ErrorBody{Type: "validation", Message: "messages are required"}, ""},
+		{"token limit", aiprovider.NewTokenLimitError("openai", "too long", 200000), 400, ErrorBody{Type: "token_limit", Message: "too long"}, ""},
+		{"rate limit", &aiprovider.Error{Type: aiprovider.ErrorTypeRateLimit, Code: "rate_limit_exceeded", Message: "slow down", RetryAfter: &retryAfter}, 429, ErrorBody{Type: "rate_limit", Code: "rate_limit_exceeded", Message: "slow down", RetryAfter: &retryAfter}, "30"},
+		{"provider auth", &aiprovider.Error{Type: aiprovider.ErrorTypeAuth, Code: "invalid_api_key", Message: "bad key"}, 502, ErrorBody{Type: "authentication", Code: "invalid_api_key", Message: "bad key"}, ""},
+		{"timeout", &aiprovider.Error{Type: aiprovider.ErrorTypeNetwork, Message: "timed out", Wrapped: context.DeadlineExceeded}, 504, ErrorBody{Type: "network", Message: "timed out"}, ""},
+		{"budget", &aiprovider.BudgetExceededError{Window: "daily", Limit: 100, Spent: 101, ResetAt: time.Now().Add(time.Minute)}, 429, ErrorBody{Type: "rate_limit", Code: "budget_exceeded"}, "60"},
+		{"closed", aiprovider.ErrClientClosed, 503, ErrorBody{Type: "provider", Code: "client_closed", Message: "client is closed"}, ""},
+		{"unknown", errors.New("boom"), 502, ErrorBody{Type: "provider", Message: "boom"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(New(&failingClient{err: tt.err}, Options{AllowUnauthenticated: true}))
+			defer server.Close()
+
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat", strings.NewReader(chatBody))
+			req.Header.Set(RequestIDHeader, "trace-123")
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var body struct {
+				Error ErrorBody `json:"error"`
+			}
+			json.NewDecoder(resp.Body).Decode(&body)
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if body.Error.Type != tt.body.Type || body.Error.Code != tt.body.Code || (tt.body.Message != "" && body.Error.Message != tt.body.Message) {
+				t.Errorf("Expected %+v, got %+v", tt.body, body.Error)
+			}
+			if body.Error.RequestID != "trace-123" || resp.Header.Get(RequestIDHeader) != "trace-123" {
+				t.Errorf("Expected the caller's request ID, got %q and %q", body.Error.RequestID, resp.Header.Get(RequestIDHeader))
+			}
+			if got := resp.Header.Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.retryAfter, got)
+			}
+		})
+	}
+}
+
+func TestChat_InvalidRequest(t *testing.T) {
+	server := newTestGateway(t, Options{})
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat", strings.NewReader("{"))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Error ErrorBody `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusBadRequest || body.Error.Type != "validation" {
+		t.Errorf("Expected a 400 validation error, got %d and %+v", resp.StatusCode, body.Error)
+	}
+	if id := resp.Header.Get(RequestIDHeader); !strings.HasPrefix(id, "req_") || body.Error.RequestID != id {
+		t.Errorf("Expected a generated request ID in the header and body, got %q and %q", id, body.Error.RequestID)
+	}
+}
+
+func TestChat_ContentType(t *testing.T) {
+	server := newTestGateway(t, Options{})
+
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat", strings.NewReader(chatBody))
+		req.Header.Set("Authorization", "Bearer secret")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("Content-Type %q: expected status 415, got %d", contentType, resp.StatusCode)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	server := newTestGateway(t, Options{})
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat/stream", strings.NewReader(chatBody))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if event, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, event)
+		}
+	}
+	if got := strings.Join(events, ","); got != "chunk,chunk,chunk,done" {
+		t.Errorf("Unexpected events %q", got)
+	}
+}
+
+func TestStream_Heartbeat(t *testing.T) {
+	server := newTestGateway(t, Options{HeartbeatInterval: 30 * time.Millisecond})
+
+	body := `{"messages": [{"role": "user", "content": "Think slowly"}]}`
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat/stream", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if event, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, event)
+		}
+	}
+	got := strings.Join(events, ",")
+	if !strings.HasPrefix(got, "chunk,heartbeat,") || !strings.HasSuffix(got, ",chunk,chunk,done") {
+		t.Errorf("Expected heartbeats while the stream paused, got %q", got)
+	}
+}
+
+// dialGateway opens a WebSocket to the gateway with the token in the query
+func dialGateway(t *testing.T, server *httptest.Server, token string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	return dialGatewayFrom(t, server, token, "")
+}
+
+// dialGatewayFrom opens a WebSocket to the gateway from a page of origin
+func dialGatewayFrom(t *testing.T, server *httptest.Server, token, origin string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/chat/ws?access_token=" + token
+	header := http.Header{}
+	if origin != "" {
+		header.Set("Origin", origin)
+	}
+	conn, resp, err := websocket.Dial(context.Background(), url, header)
+	if err == nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+// readMessage reads the next gateway message
+func readMessage(t *testing.T, conn *websocket.Conn) Message {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("invalid message %s: %v", data, err)
+	}
+	return msg
+}
+
+func TestWebSocket(t *testing.T) {
+	server := newTestGateway(t, Options{})
+	conn, _, err := dialGateway(t, server, "secret")
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+
+	// Two chats in a row on the same connection
+	for _, id := range []string{"a", "b"} {
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "chat", "id": "`+id+`", "request": `+chatBody+`}`))
+
+		var text string
+		for {
+			msg := readMessage(t, conn)
+			if msg.ID != id {
+				t.Fatalf("Expected messages for chat %q, got %+v", id, msg)
+			}
+			if msg.Type == MessageChunk {
+				text += msg.Chunk.Delta
+				continue
+			}
+			if msg.Type != MessageDone || msg.Response.Message.Content != "Hi there" {
+				t.Fatalf("Expected the done message, got %+v", msg)
+			}
+			break
+		}
+		if text != "Hi there" {
+			t.Errorf("Expected the streamed text, got %q", text)
+		}
+	}
+}
+
+func TestWebSocket_InvalidMessage(t *testing.T) {
+	server := newTestGateway(t, Options{})
+	conn, _, err := dialGateway(t, server, "secret")
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "chat", "id": "a"}`))
+	if msg := readMessage(t, conn); msg.Type != MessageError || msg.ID != "a" {
+		t.Errorf("Expected an error for a chat without a request, got %+v", msg)
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte(`not json`))
+	if msg := readMessage(t, conn); msg.Type != MessageError {
+		t.Errorf("Expected an error for invalid JSON, got %+v", msg)
+	}
+}
+
+func TestWebSocket_Unauthorized(t *testing.T) {
+	server := newTestGateway(t, Options{})
+
+	_, resp, err := dialGateway(t, server, "wrong")
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the handshake to be rejected with 401, got %v", err)
+	}
+}
+
+func TestWebSocket_Origin(t *testing.T) {
+	server := newTestGateway(t, Options{AllowedOrigins: []string{"https://app.example.com"}})
+
+	for origin, allowed := range map[string]bool{
+		server.URL:                true,
+		"https://app.example.com": true,
+		"https://evil.example":    false,
+	} {
+		_, resp, err := dialGatewayFrom(t, server, "secret", origin)
+		if allowed && err != nil {
+			t.Errorf("Origin %s: expected the handshake to succeed, got %v", origin, err)
+		}
+		if !allowed && (err == nil || resp == nil || resp.StatusCode != http.StatusForbidden) {
+			t.Errorf("Origin %s: expected the handshake to be rejected with 403, got %v", origin, err)
+		}
+	}
+}
+
+func TestWebSocket_MaxChats(t *testing.T) {
+	server := newTestGateway(t, Options{MaxChatsPerConnection: 1})
+	conn, _, err := dialGateway(t, server, "secret")
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "chat", "id": "a", "request": {"messages": [{"role": "user", "content": "Think slowly"}]}}`))
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "chat", "id": "b", "request": `+chatBody+`}`))
+	for {
+		msg := readMessage(t, conn)
+		if msg.ID == "b" {
+			if msg.Type != MessageError || msg.Error.Type != "rate_limit" {
+				t.Errorf("Expected the second chat to be rejected, got %+v", msg)
+			}
+			return
+		}
+		if msg.Type == MessageDone {
+			t.Fatal("Expected the second chat to be rejected while the first runs")
+		}
+	}
+}
+
+func TestWebSocket_KeepAlive(t *testing.T) {
+	server := newTestGateway(t, Options{PingInterval: 20 * time.Millisecond})
+	conn, _, err := dialGateway(t, server, "secret")
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+
+	// Waiting in ReadMessage answers the gateway's pings, which keeps the
+	// connection open past the pong timeout
+	chunks := 0
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Fatal("Expected no messages while idle")
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "chat", "id": "a", "request": `+chatBody+`}`))
+	msg := readMessage(t, conn)
+	for msg.Type == MessageChunk {
+		chunks++
+		msg = readMessage(t, conn)
+	}
+	if msg.Type != MessageDone || chunks == 0 {
+		t.Errorf("Expected the chat to complete after idling, got %+v", msg)
+	}
+}
+
+func TestWebSocket_Heartbeat(t *testing.T) {
+	server := newTestGateway(t, Options{HeartbeatInterval: 30 * time.Millisecond})
+	conn, _, err := dialGateway(t, server, "secret")
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "chat", "id": "a", "request": {"messages": [{"role": "user", "content": "Think slowly"}]}}`))
+	heartbeats := 0
+	msg := readMessage(t, conn)
+	for msg.Type == MessageChunk || msg.Type == MessageHeartbeat {
+		if msg.Type == MessageHeartbeat {
+			if msg.ID != "a" {
+				t.Errorf("Expected the heartbeat to carry the chat ID, got %q", msg.ID)
+			}
+			heartbeats++
+		}
+		msg = readMessage(t, conn)
+	}
+	if msg.Type != MessageDone || heartbeats == 0 {
+		t.Errorf("Expected heartbeats before the chat completed, got %d and %+v", heartbeats, msg)
+	}
+}
+
+func TestWebSocket_IdleClosed(t *testing.T) {
+	server := newTestGateway(t, Options{PingInterval: 20 * time.Millisecond})
+	conn, _, err := dialGateway(t, server, "secret")
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+
+	// A client that never reads never answers pings, so the gateway closes
+	// the connection once the pong timeout passes
+	time.Sleep(100 * time.Millisecond)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			t.Fatal("Expected the gateway to close the connection")
+		}
+		return
+	}
+}
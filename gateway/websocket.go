@@ -0,0 +1,272 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	aiprovider "github.com/ajeet-kumar1087/ai-providers"
+	"github.com/ajeet-kumar1087/ai-providers/internal/websocket"
+)
+
+// Message types of the WebSocket protocol
+const (
+	// MessageChat starts a streamed chat (client to server)
+	MessageChat = "chat"
+
+	// MessageCancel stops the chat with the same ID (client to server)
+	MessageCancel = "cancel"
+
+	// MessageChunk carries a StreamChunk of a chat (server to client)
+	MessageChunk = "chunk"
+
+	// MessageDone carries the final ChatResponse of a chat (server to client)
+	MessageDone = "done"
+
+	// MessageError reports a failed chat or invalid message (server to client)
+	MessageError = "error"
+
+	// MessageHeartbeat reports that a chat is still generating while it has
+	// sent nothing for the heartbeat interval (server to client)
+	MessageHeartbeat = "heartbeat"
+)
+
+// Message is a WebSocket message exchanged with the gateway, sent as a JSON
+// text message.
+//
+// A client sends a "chat" message with a request and an ID of its choosing,
+// and receives "chunk" messages as the response is generated, then a "done"
+// message with the complete response or an "error" message, all carrying
+// the same ID, with "heartbeat" messages while a chat is silent. Chats on
+// one connection run concurrently; a "cancel" message stops one. The server
+// also pings the connection to keep it alive through proxies; browsers
+// answer pings automatically.
+type Message struct {
+	// Type is MessageChat, MessageCancel, MessageChunk, MessageDone or
+	// MessageError
+	Type string `json:"type"`
+
+	// ID identifies the chat the message belongs to
+	ID string `json:"id,omitempty"`
+
+	// Request is the chat request ("chat" only)
+	Request *aiprovider.ChatRequest `json:"request,omitempty"`
+
+	// Chunk is the next part of the response ("chunk" only)
+	Chunk *aiprovider.StreamChunk `json:"chunk,omitempty"`
+
+	// Response is the complete response ("done" only)
+	Response *aiprovider.ChatResponse `json:"response,omitempty"`
+
+	// Error describes the failure ("error" only)
+	Error *ErrorBody `json:"error,omitempty"`
+}
+
+// wsSession is a WebSocket connection and the chats running on it
+type wsSession struct {
+	server    *Server
+	conn      *websocket.Conn
+	ctx       context.Context
+	request   *http.Request // The handshake request, for Options.PrepareRequest
+	requestID string        // ID of the handshake request, for error bodies
+
+	mu      sync.Mutex
+	running map[string]*wsChat
+	chats   sync.WaitGroup
+}
+
+// wsChat is a chat running on a WebSocket connection
+type wsChat struct {
+	cancel context.CancelFunc
+}
+
+// handleWebSocket serves GET /v1/chat/ws
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r, s.checkOrigin)
+	if err != nil {
+		return
+	}
+	conn.SetReadLimit(s.opts.MaxRequestSize)
+	conn.SetWriteTimeout(s.opts.PingInterval)
+
+	// The request context ends when the handler returns, which it does only
+	// once the connection is done
+	ctx, cancel := context.WithCancel(r.Context())
+	session := &wsSession{server: s, conn: conn, ctx: ctx, request: r, requestID: w.Header().Get(RequestIDHeader), running: make(map[string]*wsChat)}
+	defer func() {
+		cancel()
+		session.chats.Wait()
+		conn.Close()
+	}()
+
+	go session.keepAlive()
+	session.readLoop()
+}
+
+// checkOrigin reports whether the WebSocket request comes from an allowed
+// origin
+func (s *Server) checkOrigin(r *http.Request) bool {
+	if websocket.SameOrigin(r) {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	for _, allowed := range s.opts.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// keepAlive pings the connection every PingInterval until it closes
+func (ws *wsSession) keepAlive() {
+	ticker := time.NewTicker(ws.server.opts.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ws.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ws.conn.Ping(time.Now().Add(ws.server.opts.PingInterval)); err != nil {
+				ws.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// readLoop handles client messages until the connection closes or goes
+// quiet for two ping intervals
+func (ws *wsSession) readLoop() {
+	timeout := 2 * ws.server.opts.PingInterval
+	extend := func() { ws.conn.SetReadDeadline(time.Now().Add(timeout)) }
+	ws.conn.SetPongHandler(extend)
+
+	for {
+		extend()
+		messageType, data, err := ws.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if messageType != websocket.TextMessage {
+			ws.send(Message{Type: MessageError, Error: ws.protocolError("messages must be JSON text")})
+			continue
+		}
+
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			ws.send(Message{Type: MessageError, Error: ws.protocolError("invalid message: " + err.Error())})
+			continue
+		}
+		switch msg.Type {
+		case MessageChat:
+			ws.startChat(msg)
+		case MessageCancel:
+			ws.cancel(msg.ID)
+		default:
+			ws.send(Message{Type: MessageError, ID: msg.ID, Error: ws.protocolError("unknown message type " + msg.Type)})
+		}
+	}
+}
+
+// startChat streams the response to a chat message in the background
+func (ws *wsSession) startChat(msg Message) {
+	if msg.Request == nil {
+		ws.send(Message{Type: MessageError, ID: msg.ID, Error: ws.protocolError("chat message has no request")})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ws.ctx)
+	chat := &wsChat{cancel: cancel}
+	ws.mu.Lock()
+	if _, running := ws.running[msg.ID]; running {
+		ws.mu.Unlock()
+		cancel()
+		ws.send(Message{Type: MessageError, ID: msg.ID, Error: ws.protocolError("a chat with this ID is already running")})
+		return
+	}
+	if len(ws.running) >= ws.server.opts.MaxChatsPerConnection {
+		ws.mu.Unlock()
+		cancel()
+		ws.send(Message{Type: MessageError, ID: msg.ID, Error: &ErrorBody{Type: string(aiprovider.ErrorTypeRateLimit), Message: "too many chats running on this connection", RequestID: ws.requestID}})
+		return
+	}
+	ws.running[msg.ID] = chat
+	ws.mu.Unlock()
+
+	req := *msg.Request
+	ws.server.prepareRequest(ws.request, &req)
+	ws.chats.Add(1)
+	go func() {
+		defer ws.chats.Done()
+		defer cancel()
+		ws.streamChat(ctx, msg.ID, req, chat)
+	}()
+}
+
+// streamChat sends the chunks of a chat's response, then its result
+func (ws *wsSession) streamChat(ctx context.Context, id string, req aiprovider.ChatRequest, chat *wsChat) {
+	// Finish the chat before reporting its result, so the client may reuse
+	// the ID as soon as it sees it
+	finish := func(msg Message) {
+		ws.mu.Lock()
+		if ws.running[id] == chat {
+			delete(ws.running, id)
+		}
+		ws.mu.Unlock()
+		ws.send(msg)
+	}
+
+	stream, err := ws.server.client.StreamChat(ctx, req)
+	if err != nil {
+		finish(Message{Type: MessageError, ID: id, Error: errorBody(err, ws.requestID)})
+		return
+	}
+	defer stream.Close()
+
+	ws.server.relay(stream, func(chunk aiprovider.StreamChunk, err error) bool {
+		if err == io.EOF {
+			finish(Message{Type: MessageDone, ID: id, Response: stream.Response()})
+			return false
+		}
+		if err != nil {
+			if ctx.Err() != nil && ws.ctx.Err() == nil {
+				err = errors.New("chat cancelled")
+			}
+			finish(Message{Type: MessageError, ID: id, Error: errorBody(err, ws.requestID)})
+			return false
+		}
+		return ws.send(Message{Type: MessageChunk, ID: id, Chunk: &chunk}) == nil
+	}, func() bool {
+		return ctx.Err() == nil && ws.send(Message{Type: MessageHeartbeat, ID: id}) == nil
+	})
+}
+
+// cancel stops the chat with the given ID, if it is running
+func (ws *wsSession) cancel(id string) {
+	ws.mu.Lock()
+	chat, ok := ws.running[id]
+	ws.mu.Unlock()
+	if ok {
+		chat.cancel()
+	}
+}
+
+// protocolError describes a message the client should not have sent
+func (ws *wsSession) protocolError(message string) *ErrorBody {
+	return &ErrorBody{Type: string(aiprovider.ErrorTypeValidation), Message: message, RequestID: ws.requestID}
+}
+
+// send writes a message to the connection
+func (ws *wsSession) send(msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return ws.conn.WriteMessage(websocket.TextMessage, data)
+}
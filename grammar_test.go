@@ -0,0 +1,80 @@
+package aiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// grammarAdapter is a mock adapter of a backend with constrained decoding
+type grammarAdapter struct {
+	*mockAdapter
+}
+
+func (g *grammarAdapter) SupportedFeatures() []string {
+	return append(g.mockAdapter.SupportedFeatures(), FeatureGrammar)
+}
+
+func TestChatComplete_Grammar(t *testing.T) {
+	adapter := &grammarAdapter{&mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "yes"}}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	grammar := &Grammar{JSONSchema: json.RawMessage(`{"type": "object", "required": ["answer"]}`)}
+	_, err := c.ChatComplete(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Answer in JSON"}},
+		Grammar:  grammar,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := adapter.chatRequests[0].Grammar; got == nil || string(got.JSONSchema) != string(grammar.JSONSchema) {
+		t.Errorf("Expected the grammar to reach the adapter, got %+v", got)
+	}
+
+	invalid := []Grammar{
+		{},
+		{GBNF: `root ::= "a"`, JSONSchema: json.RawMessage(`{}`)},
+		{JSONSchema: json.RawMessage(`[1, 2]`)},
+	}
+	for _, g := range invalid {
+		g := g
+		_, err := c.ChatComplete(context.Background(), ChatRequest{
+			Messages: []Message{{Role: "user", Content: "Hi"}},
+			Grammar:  &g,
+		})
+		if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+			t.Errorf("Expected a validation error for %+v, got %v", g, err)
+		}
+	}
+}
+
+func TestChatComplete_GrammarUnsupported(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "yes"}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	req := ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Yes or no?"}},
+		Grammar:  &Grammar{GBNF: `root ::= "yes" | "no"`},
+	}
+
+	// The default policy drops the grammar with a warning
+	resp, err := c.ChatComplete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if adapter.chatRequests[0].Grammar != nil {
+		t.Error("Expected the grammar to be dropped")
+	}
+	if len(resp.Metadata.Warnings) != 1 || resp.Metadata.Warnings[0].Parameter != "grammar" || resp.Metadata.Warnings[0].Code != WarningDropped {
+		t.Errorf("Expected a dropped grammar warning, got %+v", resp.Metadata.Warnings)
+	}
+
+	c.config.UnsupportedParameterPolicy = UnsupportedParameterError
+	_, err = c.Complete(context.Background(), CompletionRequest{Prompt: "Yes or no?", Grammar: req.Grammar})
+	if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation || !strings.Contains(err.Error(), `parameter "grammar" is not supported`) {
+		t.Errorf("Expected an unsupported grammar error, got %v", err)
+	}
+	if len(adapter.completeRequests) != 0 {
+		t.Errorf("Expected the rejected request not to be sent, got %d requests", len(adapter.completeRequests))
+	}
+}
@@ -0,0 +1,73 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrGroupCancelled is wrapped by the errors of requests and streams ended
+// by Client.CancelGroup
+var ErrGroupCancelled = errors.New("request group cancelled")
+
+// groupMember is a request or stream in flight in a cancellation group
+type groupMember struct {
+	cancel context.CancelCauseFunc
+}
+
+// joinGroup adds a request to the cancellation group of its context, set
+// with WithCancelGroup, returning the context to make it with and a function
+// removing it from the group once it is done. Requests without a group get
+// ctx back unchanged.
+func (c *client) joinGroup(ctx context.Context) (context.Context, func()) {
+	group := CancelGroupFromContext(ctx)
+	if group == "" {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	member := &groupMember{cancel: cancel}
+	c.groupsMu.Lock()
+	if c.groups == nil {
+		c.groups = make(map[string]map[*groupMember]struct{})
+	}
+	if c.groups[group] == nil {
+		c.groups[group] = make(map[*groupMember]struct{})
+	}
+	c.groups[group][member] = struct{}{}
+	c.groupsMu.Unlock()
+
+	return ctx, func() {
+		c.groupsMu.Lock()
+		if members := c.groups[group]; members != nil {
+			delete(members, member)
+			if len(members) == 0 {
+				delete(c.groups, group)
+			}
+		}
+		c.groupsMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// CancelGroup implements Client
+func (c *client) CancelGroup(group string) int {
+	c.groupsMu.Lock()
+	members := c.groups[group]
+	delete(c.groups, group)
+	c.groupsMu.Unlock()
+
+	for member := range members {
+		member.cancel(ErrGroupCancelled)
+	}
+	return len(members)
+}
+
+// groupError marks the error of a request ended by CancelGroup, so callers
+// can tell it from their own cancellations with errors.Is
+func groupError(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, ErrGroupCancelled) || !errors.Is(context.Cause(ctx), ErrGroupCancelled) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrGroupCancelled, err)
+}
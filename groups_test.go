@@ -0,0 +1,176 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/ratelimit"
+)
+
+// groupAdapter blocks chats until their context is done or release is
+// closed, and serves streams that block until closed
+type groupAdapter struct {
+	mockAdapter
+	started chan struct{}
+	release chan struct{}
+}
+
+func (g *groupAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	g.started <- struct{}{}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-g.release:
+		return &ChatResponse{Message: Message{Role: "assistant", Content: "done"}}, nil
+	}
+}
+
+func (g *groupAdapter) StreamChat(ctx context.Context, req ChatRequest) (StreamReader, error) {
+	return &blockingReader{closed: make(chan struct{})}, nil
+}
+
+func (g *groupAdapter) SupportedFeatures() []string {
+	return append(g.mockAdapter.SupportedFeatures(), FeatureStreaming)
+}
+
+// blockingReader is a StreamReader whose Recv blocks until it is closed
+type blockingReader struct {
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (b *blockingReader) Recv() (StreamChunk, error) {
+	<-b.closed
+	return StreamChunk{}, errors.New("read on closed stream")
+}
+
+func (b *blockingReader) Close() error {
+	b.once.Do(func() { close(b.closed) })
+	return nil
+}
+
+func TestCancelGroup(t *testing.T) {
+	adapter := &groupAdapter{started: make(chan struct{}), release: make(chan struct{})}
+	c := newMockClient(ProviderOpenAI, adapter)
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+	userA := WithCancelGroup(context.Background(), "user-a")
+	userB := WithCancelGroup(context.Background(), "user-b")
+
+	errs := make(chan error, 3)
+	for _, ctx := range []context.Context{userA, userA, userB} {
+		go func(ctx context.Context) {
+			_, err := c.ChatComplete(ctx, req)
+			errs <- err
+		}(ctx)
+		<-adapter.started
+	}
+	stream, err := c.StreamChat(userA, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+	streamErr := make(chan error, 1)
+	go func() {
+		_, err := stream.Recv()
+		streamErr <- err
+	}()
+
+	if n := c.CancelGroup("user-a"); n != 3 {
+		t.Errorf("Expected 3 requests cancelled, got %d", n)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; !errors.Is(err, ErrGroupCancelled) || !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected a group cancellation error, got %v", err)
+		}
+	}
+	select {
+	case err := <-streamErr:
+		if !errors.Is(err, ErrGroupCancelled) {
+			t.Errorf("Expected the stream to fail with ErrGroupCancelled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stream to be closed")
+	}
+
+	// Other groups keep running, and the group can be used again
+	if n := c.CancelGroup("user-a"); n != 0 {
+		t.Errorf("Expected no requests left in the group, got %d", n)
+	}
+	close(adapter.release)
+	if err := <-errs; err != nil {
+		t.Errorf("Expected the other group's request to finish, got %v", err)
+	}
+	go func() { <-adapter.started }()
+	if _, err := c.ChatComplete(userA, req); err != nil {
+		t.Errorf("Expected a later request of the group to succeed, got %v", err)
+	}
+	if len(c.groups) != 0 {
+		t.Errorf("Expected finished requests to leave their groups, got %v", c.groups)
+	}
+}
+
+func TestCancelGroup_ReleasesRateLimitToken(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Hi"}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	store := ratelimit.NewMemoryStore()
+	c.config.RequestsPerMinute = 1
+	c.config.RateLimiterStore = store
+
+	ctx := WithCancelGroup(context.Background(), "user-a")
+	key := c.rateLimitKey(ctx)
+	store.Reserve(ctx, key, 1.0/60, 1)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.ChatComplete(ctx, ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}})
+		errs <- err
+	}()
+	for c.CancelGroup("user-a") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := <-errs; !errors.Is(err, ErrGroupCancelled) {
+		t.Fatalf("Expected a group cancellation error, got %v", err)
+	}
+	if len(adapter.chatRequests) != 0 {
+		t.Error("Expected the cancelled request not to be sent")
+	}
+
+	// The next request waits one interval, not two
+	if wait, _ := store.Reserve(ctx, key, 1.0/60, 1); wait > time.Minute {
+		t.Errorf("Expected the cancelled request's token to be returned, got a wait of %v", wait)
+	}
+}
+
+func TestCancelGroup_StreamStopsWatching(t *testing.T) {
+	adapter := &streamingAdapter{streams: []*sliceStream{completeStream(), completeStream()}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+
+	// Streams outside a group do not watch their context
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	plain, err := c.StreamChat(ctx, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if plain.stopClose != nil {
+		t.Error("Expected no context watch for a stream without a group")
+	}
+	drain(plain)
+
+	// Grouped streams stop watching once they end
+	grouped, err := c.StreamChat(WithCancelGroup(ctx, "user-a"), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if grouped.stopClose == nil {
+		t.Fatal("Expected a context watch for a grouped stream")
+	}
+	drain(grouped)
+	if grouped.stopClose() {
+		t.Error("Expected the watch to be stopped when the stream ended")
+	}
+}
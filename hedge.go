@@ -0,0 +1,102 @@
+package aiprovider
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// HedgePrimary is the HedgeInfo.Winner value when the primary request answered
+	HedgePrimary = "primary"
+
+	// HedgeHedge is the HedgeInfo.Winner value when the hedge request answered
+	HedgeHedge = "hedge"
+)
+
+// hedgeOutcome is the result of one side of a hedged request
+type hedgeOutcome[T any] struct {
+	resp  T
+	err   error
+	hedge bool
+}
+
+// runHedged runs primary and, if it has not returned within budget, races
+// hedge against it. The first success wins and the other request is
+// cancelled. If the primary fails before the budget, hedge is not started.
+// When both fail, the primary error is returned.
+func runHedged[T any](ctx context.Context, budget time.Duration, primary, hedge func(context.Context) (T, error)) (T, *HedgeInfo, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	info := &HedgeInfo{}
+	results := make(chan hedgeOutcome[T], 2)
+
+	start := time.Now()
+	var hedgeStart time.Time
+	go func() {
+		resp, err := primary(ctx)
+		results <- hedgeOutcome[T]{resp: resp, err: err}
+	}()
+
+	timer := time.NewTimer(budget)
+	defer timer.Stop()
+
+	running := 1
+	primaryDone, hedgeDone := false, false
+	var primaryErr, hedgeErr error
+	var zero T
+
+	for running > 0 {
+		select {
+		case <-timer.C:
+			if primaryDone {
+				continue
+			}
+			info.Triggered = true
+			hedgeStart = time.Now()
+			running++
+			go func() {
+				resp, err := hedge(ctx)
+				results <- hedgeOutcome[T]{resp: resp, err: err, hedge: true}
+			}()
+
+		case outcome := <-results:
+			running--
+			if outcome.hedge {
+				hedgeDone = true
+				hedgeErr = outcome.err
+				info.HedgeLatency = time.Since(hedgeStart)
+			} else {
+				primaryDone = true
+				primaryErr = outcome.err
+				info.PrimaryLatency = time.Since(start)
+			}
+			if outcome.err != nil {
+				continue
+			}
+
+			info.Winner = HedgePrimary
+			if outcome.hedge {
+				info.Winner = HedgeHedge
+			}
+			// The loser's latency is the time it ran until cancellation
+			if info.Triggered && !primaryDone {
+				info.PrimaryLatency = time.Since(start)
+				info.LoserCancelled = true
+			}
+			if info.Triggered && !hedgeDone {
+				info.HedgeLatency = time.Since(hedgeStart)
+				info.LoserCancelled = true
+			}
+			return outcome.resp, info, nil
+
+		case <-ctx.Done():
+			return zero, info, ctx.Err()
+		}
+	}
+
+	if primaryErr == nil {
+		primaryErr = hedgeErr
+	}
+	return zero, info, primaryErr
+}
@@ -0,0 +1,162 @@
+package aiprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// delayAdapter answers chat requests with content after delay, or fails with err
+type delayAdapter struct {
+	mockAdapter
+	delay   time.Duration
+	content string
+	err     error
+	models  chan string
+}
+
+func (d *delayAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if d.models != nil {
+		d.models <- req.Model
+	}
+	select {
+	case <-time.After(d.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &ChatResponse{Message: Message{Role: "assistant", Content: d.content}}, nil
+}
+
+func TestFallbackClient_LatencyBudget(t *testing.T) {
+	request := ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}
+
+	t.Run("primary within budget", func(t *testing.T) {
+		primary := &delayAdapter{content: "primary"}
+		hedge := &delayAdapter{content: "hedge", models: make(chan string, 1)}
+		f, _ := NewFallbackClient([]Client{
+			newMockClient(ProviderAnthropic, primary),
+			newMockClient(ProviderAnthropic, hedge),
+		}, FallbackOptions{LatencyBudget: 200 * time.Millisecond})
+
+		resp, err := f.ChatComplete(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		info := resp.Metadata.Hedge
+		if resp.Message.Content != "primary" || info == nil || info.Triggered || info.Winner != HedgePrimary {
+			t.Errorf("Expected untriggered primary answer, got %q with %+v", resp.Message.Content, info)
+		}
+		if len(hedge.models) != 0 {
+			t.Errorf("Expected no hedge request")
+		}
+	})
+
+	t.Run("hedge wins after budget", func(t *testing.T) {
+		primary := &delayAdapter{delay: time.Second, content: "primary"}
+		hedge := &delayAdapter{delay: 10 * time.Millisecond, content: "hedge", models: make(chan string, 1)}
+		f, _ := NewFallbackClient([]Client{newMockClient(ProviderAnthropic, primary)}, FallbackOptions{
+			LatencyBudget: 20 * time.Millisecond,
+			HedgeClient:   newMockClient(ProviderAnthropic, hedge),
+			HedgeModel:    "claude-3-haiku-20240307",
+		})
+
+		start := time.Now()
+		resp, err := f.ChatComplete(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Expected the hedge to answer early, took %v", elapsed)
+		}
+
+		info := resp.Metadata.Hedge
+		if resp.Message.Content != "hedge" || !info.Triggered || info.Winner != HedgeHedge || !info.LoserCancelled {
+			t.Errorf("Expected hedge answer, got %q with %+v", resp.Message.Content, info)
+		}
+		if info.PrimaryLatency < 20*time.Millisecond || info.HedgeLatency <= 0 || info.HedgeLatency > info.PrimaryLatency {
+			t.Errorf("Unexpected latencies: %+v", info)
+		}
+		if model := <-hedge.models; model != "claude-3-haiku-20240307" {
+			t.Errorf("Expected hedge model override, got %q", model)
+		}
+	})
+
+	t.Run("primary wins after hedge triggered", func(t *testing.T) {
+		primary := &delayAdapter{delay: 40 * time.Millisecond, content: "primary"}
+		hedge := &delayAdapter{delay: time.Second, content: "hedge"}
+		f, _ := NewFallbackClient([]Client{
+			newMockClient(ProviderAnthropic, primary),
+			newMockClient(ProviderAnthropic, hedge),
+		}, FallbackOptions{LatencyBudget: 10 * time.Millisecond})
+
+		resp, err := f.ChatComplete(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		info := resp.Metadata.Hedge
+		if resp.Message.Content != "primary" || !info.Triggered || info.Winner != HedgePrimary || !info.LoserCancelled {
+			t.Errorf("Expected primary answer after hedge, got %q with %+v", resp.Message.Content, info)
+		}
+	})
+
+	t.Run("both fail falls back to remaining clients", func(t *testing.T) {
+		failure := NewError(ErrorTypeProvider, "anthropic", "overloaded")
+		primary := &delayAdapter{delay: 30 * time.Millisecond, err: failure}
+		hedge := &delayAdapter{err: failure}
+		last := &delayAdapter{content: "last"}
+		f, _ := NewFallbackClient([]Client{
+			newMockClient(ProviderAnthropic, primary),
+			newMockClient(ProviderAnthropic, hedge),
+			newMockClient(ProviderAnthropic, last),
+		}, FallbackOptions{LatencyBudget: 10 * time.Millisecond})
+
+		resp, err := f.ChatComplete(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Message.Content != "last" {
+			t.Errorf("Expected answer from the third client, got %q", resp.Message.Content)
+		}
+	})
+
+	t.Run("requires hedge target", func(t *testing.T) {
+		if _, err := NewFallbackClient([]Client{newMockClient(ProviderAnthropic, &mockAdapter{})}, FallbackOptions{
+			LatencyBudget: time.Second,
+		}); err == nil {
+			t.Errorf("Expected error without a hedge target")
+		}
+	})
+}
+
+func TestFallbackClient_HedgeSessionAffinity(t *testing.T) {
+	primary := &delayAdapter{delay: time.Second, content: "primary", models: make(chan string, 4)}
+	hedge := &delayAdapter{delay: 10 * time.Millisecond, content: "hedge", models: make(chan string, 4)}
+	f, _ := NewFallbackClient([]Client{newMockClient(ProviderAnthropic, primary)}, FallbackOptions{
+		LatencyBudget:   20 * time.Millisecond,
+		HedgeClient:     newMockClient(ProviderAnthropic, hedge),
+		HedgeModel:      "claude-3-haiku-20240307",
+		SessionAffinity: true,
+	})
+	ctx := WithSession(context.Background(), "conv-1")
+	request := ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}
+
+	// The hedge client wins the first turn, so later turns stay on it
+	for turn := 1; turn <= 3; turn++ {
+		resp, err := f.ChatComplete(ctx, request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Message.Content != "hedge" {
+			t.Errorf("Expected turn %d on the hedge client, got %q", turn, resp.Message.Content)
+		}
+		if model := <-hedge.models; model != "claude-3-haiku-20240307" {
+			t.Errorf("Expected the hedge model on turn %d, got %q", turn, model)
+		}
+	}
+	if len(primary.models) != 1 {
+		t.Errorf("Expected only the first turn to reach the primary, got %d requests", len(primary.models))
+	}
+}
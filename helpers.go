@@ -0,0 +1,114 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// helperRequest is a single instruction/input exchange sent by the
+// high-level helpers (Summarize, Classify, ...)
+type helperRequest struct {
+	system      string
+	user        string
+	model       string
+	temperature *float64
+	maxTokens   *int
+}
+
+// generation is the result of a helper request
+type generation struct {
+	text         string
+	usage        Usage
+	finishReason string
+}
+
+// generate sends a helper request, using chat completion when the provider
+// supports it and falling back to text completion otherwise
+func (c *client) generate(ctx context.Context, req helperRequest) (*generation, error) {
+	if c.SupportsFeature(FeatureChatCompletion) {
+		var messages []Message
+		user := req.user
+		if req.system != "" {
+			if c.SupportsFeature(FeatureSystemMessages) {
+				messages = append(messages, Message{Role: "system", Content: req.system})
+			} else {
+				user = req.system + "\n\n" + user
+			}
+		}
+		messages = append(messages, Message{Role: "user", Content: user})
+
+		resp, err := c.ChatComplete(ctx, ChatRequest{
+			Messages:    messages,
+			Model:       req.model,
+			Temperature: req.temperature,
+			MaxTokens:   req.maxTokens,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &generation{text: resp.Message.Content, usage: resp.Usage, finishReason: resp.FinishReason}, nil
+	}
+
+	prompt := req.user
+	if req.system != "" {
+		prompt = req.system + "\n\n" + prompt
+	}
+	resp, err := c.Complete(ctx, CompletionRequest{
+		Prompt:      prompt,
+		Model:       req.model,
+		Temperature: req.temperature,
+		MaxTokens:   req.maxTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &generation{text: resp.Text, usage: resp.Usage, finishReason: resp.FinishReason}, nil
+}
+
+// addUsage returns the sum of two usage values
+func addUsage(a, b Usage) Usage {
+	return Usage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+	}
+}
+
+// generateAll sends requests with at most concurrency in flight, returning
+// results in request order. The first error cancels outstanding requests.
+func (c *client) generateAll(ctx context.Context, reqs []helperRequest, concurrency int) ([]*generation, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*generation, len(reqs))
+	errs := make([]error, len(reqs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := range reqs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = c.generate(ctx, reqs[i])
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("request %d of %d failed: %w", i+1, len(reqs), err)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,311 @@
+// Package imageprep prepares images for vision requests.
+//
+// Providers accept a few image formats up to a size and resolution limit;
+// larger images are rejected, or uploaded in full only to be downscaled by
+// the provider. Prepare converts an image to an accepted format, downscales
+// it to the provider's resolution, recompresses it until it fits the size
+// limit and removes its metadata, such as the location and camera details
+// photos carry in EXIF, applying the EXIF orientation first so the image
+// still displays upright.
+//
+// Images already within the limits keep their encoding, only losing their
+// metadata, so they are not recompressed. JPEG, PNG and GIF images can be
+// read; GIFs are converted to PNG, keeping the first frame of animations.
+//
+// Example:
+//
+//	img, err := imageprep.Prepare(file, imageprep.Options{
+//		Limits: imageprep.LimitsFor(aiprovider.ProviderAnthropic),
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(img.MediaType, img.Width, img.Height)
+//	url := img.DataURL() // data:image/jpeg;base64,...
+package imageprep
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// Media types of images providers accept
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	GIF  = "image/gif"
+	WebP = "image/webp"
+)
+
+// DefaultQuality is the JPEG quality images are encoded with by default
+const DefaultQuality = 85
+
+// DefaultMaxPixels is the largest image, in pixels, Prepare decodes when
+// Options.MaxPixels is zero. Decoding allocates 4 bytes per pixel, so a small
+// file declaring huge dimensions could otherwise exhaust memory.
+const DefaultMaxPixels = 50000000
+
+// minQuality is the lowest JPEG quality tried before an image is shrunk
+// further to fit MaxBytes
+const minQuality = 50
+
+// Limits are the constraints a provider places on input images. The zero
+// value has no limits, so Prepare only removes metadata.
+type Limits struct {
+	// MaxBytes is the largest encoded image accepted; 0 means no limit
+	MaxBytes int
+
+	// MaxDimension is the longest edge in pixels, beyond which images are
+	// downscaled; 0 means no limit
+	MaxDimension int
+
+	// Formats are the accepted media types; nil accepts all of them
+	Formats []string
+}
+
+// LimitsFor returns the image limits of a provider. Unknown providers get
+// the strictest limits, those of Anthropic.
+func LimitsFor(provider types.ProviderType) Limits {
+	formats := []string{JPEG, PNG, GIF, WebP}
+	switch provider {
+	case types.ProviderOpenAI:
+		// High-detail images are scaled to fit 2048x2048
+		return Limits{MaxBytes: 20 << 20, MaxDimension: 2048, Formats: formats}
+	case types.ProviderGoogle:
+		return Limits{MaxBytes: 20 << 20, MaxDimension: 3072, Formats: formats}
+	default:
+		// The 5 MB limit applies to the base64 encoding, and larger edges
+		// are downscaled by the API
+		return Limits{MaxBytes: 5 << 20 / 4 * 3, MaxDimension: 1568, Formats: formats}
+	}
+}
+
+// accepts reports whether images of a media type are accepted
+func (l Limits) accepts(mediaType string) bool {
+	if l.Formats == nil {
+		return true
+	}
+	for _, format := range l.Formats {
+		if format == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// Options configures Prepare.
+type Options struct {
+	// Limits are the constraints the image must meet
+	Limits Limits
+
+	// Quality is the JPEG quality images are encoded with, lowered as far
+	// as 50 to fit Limits.MaxBytes before images are shrunk (default:
+	// DefaultQuality)
+	Quality int
+
+	// MaxPixels is the largest width × height accepted; larger images are
+	// rejected before they are decoded (default: DefaultMaxPixels)
+	MaxPixels int
+}
+
+// Image is a prepared image.
+type Image struct {
+	// Data is the encoded image
+	Data []byte
+
+	// MediaType is the format of Data, JPEG or PNG unless the original
+	// encoding was kept
+	MediaType string
+
+	// Width and Height are the dimensions in pixels
+	Width  int
+	Height int
+}
+
+// Base64 returns the image encoded as standard base64, as sent inline in
+// requests
+func (img *Image) Base64() string {
+	return base64.StdEncoding.EncodeToString(img.Data)
+}
+
+// DataURL returns the image as a data URL
+func (img *Image) DataURL() string {
+	return "data:" + img.MediaType + ";base64," + img.Base64()
+}
+
+// Prepare reads an image and converts it to meet opts.Limits, removing its
+// metadata. It returns an error if the image cannot be decoded, or if no
+// encoding fits the limits.
+func Prepare(r io.Reader, opts Options) (*Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	maxPixels := opts.MaxPixels
+	if maxPixels <= 0 {
+		maxPixels = DefaultMaxPixels
+	}
+	if int64(config.Width)*int64(config.Height) > int64(maxPixels) {
+		return nil, fmt.Errorf("image of %dx%d pixels exceeds the limit of %d pixels", config.Width, config.Height, maxPixels)
+	}
+	mediaType := "image/" + format
+	orientation := 1
+	if mediaType == JPEG {
+		orientation = exifOrientation(data)
+	}
+
+	// Keep the encoding of images within the limits; those with metadata
+	// that cannot be parsed are re-encoded instead
+	limits := opts.Limits
+	if orientation == 1 && mediaType != GIF && limits.accepts(mediaType) &&
+		(limits.MaxDimension <= 0 || (config.Width <= limits.MaxDimension && config.Height <= limits.MaxDimension)) {
+		stripped, err := stripMetadata(data, mediaType)
+		if err == nil && (limits.MaxBytes <= 0 || len(stripped) <= limits.MaxBytes) {
+			return &Image{Data: stripped, MediaType: mediaType, Width: config.Width, Height: config.Height}, nil
+		}
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return encodeWithin(orient(toRGBA(decoded), orientation), mediaType, opts)
+}
+
+// encodeWithin encodes an image to meet the limits, trying lossy steps in
+// order of their cost to quality: converting PNG to JPEG, lowering the JPEG
+// quality, then shrinking the image
+func encodeWithin(img *image.RGBA, source string, opts Options) (*Image, error) {
+	limits := opts.Limits
+	format := outputFormat(source, limits)
+	if format == "" {
+		return nil, errors.New("image limits accept neither JPEG nor PNG")
+	}
+	quality := opts.Quality
+	if quality <= 0 {
+		quality = DefaultQuality
+	}
+
+	width, height := fitDimension(img.Rect.Dx(), img.Rect.Dy(), limits.MaxDimension)
+	for {
+		scaled := img
+		if width != img.Rect.Dx() || height != img.Rect.Dy() {
+			scaled = resize(img, width, height)
+		}
+		data, err := encode(scaled, format, quality)
+		if err != nil {
+			return nil, err
+		}
+		if limits.MaxBytes <= 0 || len(data) <= limits.MaxBytes {
+			return &Image{Data: data, MediaType: format, Width: width, Height: height}, nil
+		}
+
+		switch {
+		case format == PNG && limits.accepts(JPEG):
+			format = JPEG
+		case format == JPEG && quality > minQuality:
+			quality -= 10
+			if quality < minQuality {
+				quality = minQuality
+			}
+		case width > 1 || height > 1:
+			width, height = fitDimension(width, height, longest(width, height)*3/4)
+		default:
+			return nil, fmt.Errorf("image does not fit %d bytes", limits.MaxBytes)
+		}
+	}
+}
+
+// outputFormat returns the format a re-encoded image is written in: that of
+// the source where accepted, with GIFs becoming PNG, otherwise the other of
+// JPEG and PNG. It returns "" if neither is accepted.
+func outputFormat(source string, limits Limits) string {
+	preferred, other := PNG, JPEG
+	if source == JPEG {
+		preferred, other = JPEG, PNG
+	}
+	switch {
+	case limits.accepts(preferred):
+		return preferred
+	case limits.accepts(other):
+		return other
+	}
+	return ""
+}
+
+// fitDimension scales width and height proportionally so the longest edge
+// is at most maxDimension, keeping both at least one pixel
+func fitDimension(width, height, maxDimension int) (int, int) {
+	edge := longest(width, height)
+	if maxDimension <= 0 || edge <= maxDimension {
+		return width, height
+	}
+	scale := float64(maxDimension) / float64(edge)
+	width = int(math.Round(float64(width) * scale))
+	height = int(math.Round(float64(height) * scale))
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	return width, height
+}
+
+// longest returns the longer of two edges
+func longest(width, height int) int {
+	if width > height {
+		return width
+	}
+	return height
+}
+
+// encode writes an image as JPEG or PNG. JPEG has no transparency, so
+// transparent areas become white rather than black.
+func encode(img *image.RGBA, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if format == JPEG {
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality})
+	} else {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// toRGBA converts an image to RGBA with its origin at zero
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Rect, src, bounds.Min, draw.Src)
+	return dst
+}
+
+// flatten composites an image with transparency onto white
+func flatten(img *image.RGBA) *image.RGBA {
+	if img.Opaque() {
+		return img
+	}
+	dst := image.NewRGBA(img.Rect)
+	draw.Draw(dst, dst.Rect, image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Rect, img, img.Rect.Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,262 @@
+package imageprep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// halves returns an image whose left half is red and right half blue
+func halves(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// noise returns an image of random pixels, which compresses poorly
+func noise(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rng := rand.New(rand.NewSource(1))
+	rng.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withEXIF inserts an EXIF segment with an orientation and a camera make,
+// and a comment, after the start marker of a JPEG image
+func withEXIF(data []byte, orientation uint16, cameraMake string) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	entry := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(entry, 1)
+	binary.LittleEndian.PutUint16(entry[2:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[4:], 3)
+	binary.LittleEndian.PutUint32(entry[6:], 1)
+	binary.LittleEndian.PutUint16(entry[10:], orientation)
+	payload := append(append([]byte("Exif\x00\x00"), append(tiff, entry...)...), cameraMake...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	comment := []byte{0xFF, 0xFE, 0, byte(len(cameraMake) + 2)}
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, append(segment, payload...)...)
+	out = append(out, append(comment, cameraMake...)...)
+	return append(out, data[2:]...)
+}
+
+// pngChunk encodes a PNG chunk
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(append(chunk, chunkType...), data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestPrepare_StripsMetadata(t *testing.T) {
+	original := encodeJPEG(t, halves(40, 20))
+	data := withEXIF(original, 1, "Secret Camera")
+
+	img, err := Prepare(bytes.NewReader(data), Options{Limits: LimitsFor(types.ProviderAnthropic)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bytes.Contains(img.Data, []byte("Secret")) || bytes.Contains(img.Data, []byte("Exif")) {
+		t.Error("Expected the EXIF data and comment to be removed")
+	}
+	// Upright images within the limits are not recompressed
+	if !bytes.Equal(img.Data, original) {
+		t.Errorf("Expected the original encoding, got %d bytes instead of %d", len(img.Data), len(original))
+	}
+	if img.MediaType != JPEG || img.Width != 40 || img.Height != 20 {
+		t.Errorf("Expected a 40x20 JPEG, got a %dx%d %s", img.Width, img.Height, img.MediaType)
+	}
+
+	// Data after the end marker, such as an appended MPF preview, is dropped
+	trailer := append(append([]byte{}, data...), encodeJPEG(t, halves(8, 8))...)
+	trailer = append(trailer, "Secret trailer"...)
+	img, err = Prepare(bytes.NewReader(trailer), Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(img.Data, original) {
+		t.Errorf("Expected the data after the end marker to be removed, got %d bytes instead of %d", len(img.Data), len(original))
+	}
+	if !strings.HasPrefix(img.DataURL(), "data:image/jpeg;base64,/9j/") {
+		t.Errorf("Expected a JPEG data URL, got %.30s", img.DataURL())
+	}
+
+	withText := append([]byte{}, encodePNG(t, halves(4, 4))...)
+	text := pngChunk("tEXt", []byte("GPS\x0052.5200"))
+	withText = append(withText[:33:33], append(text, withText[33:]...)...) // After the header chunk
+	img, err = Prepare(bytes.NewReader(withText), Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bytes.Contains(img.Data, []byte("GPS")) {
+		t.Error("Expected the PNG text chunk to be removed")
+	}
+	if _, err := png.Decode(bytes.NewReader(img.Data)); err != nil {
+		t.Errorf("Expected a valid PNG, got %v", err)
+	}
+}
+
+func TestPrepare_MaxPixels(t *testing.T) {
+	data := encodePNG(t, halves(100, 100))
+	if _, err := Prepare(bytes.NewReader(data), Options{MaxPixels: 9999}); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("Expected an image over the pixel limit to be rejected, got %v", err)
+	}
+	if _, err := Prepare(bytes.NewReader(data), Options{MaxPixels: 10000}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// A header declaring huge dimensions is rejected without decoding
+	huge := append([]byte{}, data...)
+	binary.BigEndian.PutUint32(huge[16:], 100000)
+	binary.BigEndian.PutUint32(huge[20:], 100000)
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+	if _, err := Prepare(bytes.NewReader(huge), Options{}); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("Expected a huge image to be rejected, got %v", err)
+	}
+}
+
+func TestPrepare_Orientation(t *testing.T) {
+	// Stored sideways: rotating 90° clockwise brings the red half on top
+	data := withEXIF(encodeJPEG(t, halves(40, 20)), 6, "Camera")
+
+	img, err := Prepare(bytes.NewReader(data), Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if img.Width != 20 || img.Height != 40 || bytes.Contains(img.Data, []byte("Exif")) {
+		t.Fatalf("Expected an upright 20x40 image without EXIF, got %dx%d", img.Width, img.Height)
+	}
+	decoded, err := jpeg.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatalf("Expected a valid JPEG, got %v", err)
+	}
+	top, _, _, _ := decoded.At(10, 5).RGBA()
+	bottom, _, _, _ := decoded.At(10, 35).RGBA()
+	if top < 0xC000 || bottom > 0x4000 {
+		t.Errorf("Expected red on top and blue below, got red levels %#x and %#x", top, bottom)
+	}
+}
+
+func TestPrepare_Limits(t *testing.T) {
+	var animated bytes.Buffer
+	palette := color.Palette{color.White, color.Black}
+	frame := image.NewPaletted(image.Rect(0, 0, 10, 10), palette)
+	gif.EncodeAll(&animated, &gif.GIF{Image: []*image.Paletted{frame, frame}, Delay: []int{10, 10}})
+
+	tests := []struct {
+		name           string
+		data           []byte
+		opts           Options
+		expectedType   string
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{
+			name:           "downscaled to the longest edge",
+			data:           encodePNG(t, halves(3000, 1000)),
+			opts:           Options{Limits: LimitsFor(types.ProviderAnthropic)},
+			expectedType:   PNG,
+			expectedWidth:  1568,
+			expectedHeight: 523,
+		},
+		{
+			name:           "PNG too large is converted to JPEG",
+			data:           encodePNG(t, noise(300, 300)),
+			opts:           Options{Limits: Limits{MaxBytes: 100000}},
+			expectedType:   JPEG,
+			expectedWidth:  300,
+			expectedHeight: 300,
+		},
+		{
+			name:           "shrunk once quality is exhausted",
+			data:           encodeJPEG(t, noise(400, 400)),
+			opts:           Options{Limits: Limits{MaxBytes: 30000}},
+			expectedType:   JPEG,
+			expectedWidth:  225,
+			expectedHeight: 225,
+		},
+		{
+			name:           "unaccepted format is converted",
+			data:           encodeJPEG(t, halves(20, 20)),
+			opts:           Options{Limits: Limits{Formats: []string{PNG}}},
+			expectedType:   PNG,
+			expectedWidth:  20,
+			expectedHeight: 20,
+		},
+		{
+			name:           "GIF keeps its first frame as PNG",
+			data:           animated.Bytes(),
+			opts:           Options{},
+			expectedType:   PNG,
+			expectedWidth:  10,
+			expectedHeight: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Prepare(bytes.NewReader(tt.data), tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if img.MediaType != tt.expectedType || img.Width != tt.expectedWidth || img.Height != tt.expectedHeight {
+				t.Errorf("Expected a %dx%d %s, got a %dx%d %s", tt.expectedWidth, tt.expectedHeight, tt.expectedType, img.Width, img.Height, img.MediaType)
+			}
+			if max := tt.opts.Limits.MaxBytes; max > 0 && len(img.Data) > max {
+				t.Errorf("Expected at most %d bytes, got %d", max, len(img.Data))
+			}
+			decoded, _, err := image.Decode(bytes.NewReader(img.Data))
+			if err != nil {
+				t.Fatalf("Expected a valid image, got %v", err)
+			}
+			if size := decoded.Bounds().Size(); size.X != img.Width || size.Y != img.Height {
+				t.Errorf("Expected the reported dimensions, got %v", size)
+			}
+		})
+	}
+
+	if _, err := Prepare(strings.NewReader("not an image"), Options{}); err == nil {
+		t.Error("Expected an error for data that is not an image")
+	}
+	if _, err := Prepare(bytes.NewReader(encodePNG(t, halves(4, 4))), Options{Limits: Limits{Formats: []string{WebP}}}); err == nil {
+		t.Error("Expected an error when no writable format is accepted")
+	}
+}
@@ -0,0 +1,234 @@
+package imageprep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// stripMetadata removes the metadata of a JPEG or PNG image without
+// re-encoding it
+func stripMetadata(data []byte, mediaType string) ([]byte, error) {
+	switch mediaType {
+	case JPEG:
+		return stripJPEG(data)
+	case PNG:
+		return stripPNG(data)
+	}
+	return nil, fmt.Errorf("cannot strip metadata from %s images", mediaType)
+}
+
+// jpegSegment is a marker segment of a JPEG header
+type jpegSegment struct {
+	marker     byte
+	start, end int // Offsets of the segment in the image, marker included
+	payload    []byte
+}
+
+// jpegSegments returns the marker segments of a JPEG image before its
+// first scan, and the offset the scan starts at
+func jpegSegments(data []byte) ([]jpegSegment, int, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, errors.New("missing JPEG start marker")
+	}
+	var segments []jpegSegment
+	i := 2
+	for {
+		start := i
+		if i >= len(data) || data[i] != 0xFF {
+			return nil, 0, errors.New("malformed JPEG marker")
+		}
+		// Markers may be preceded by fill bytes
+		for i < len(data) && data[i] == 0xFF {
+			i++
+		}
+		if i >= len(data) {
+			return nil, 0, errors.New("truncated JPEG header")
+		}
+		marker := data[i]
+		i++
+
+		switch {
+		case marker == 0xDA || marker == 0xD9: // Start of scan, end of image
+			return segments, start, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // No payload
+			segments = append(segments, jpegSegment{marker: marker, start: start, end: i})
+			continue
+		}
+		if i+2 > len(data) {
+			return nil, 0, errors.New("truncated JPEG header")
+		}
+		length := int(binary.BigEndian.Uint16(data[i:]))
+		if length < 2 || i+length > len(data) {
+			return nil, 0, errors.New("truncated JPEG segment")
+		}
+		segments = append(segments, jpegSegment{marker: marker, start: start, end: i + length, payload: data[i+2 : i+length]})
+		i += length
+	}
+}
+
+// stripJPEG removes the EXIF, XMP, IPTC and comment segments of a JPEG
+// image, keeping those that affect how it is decoded and displayed, and
+// everything after its end marker, such as the preview images of MPF files
+func stripJPEG(data []byte) ([]byte, error) {
+	segments, scan, err := jpegSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	end, err := jpegEnd(data, scan)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, end)
+	out = append(out, data[:2]...)
+	for _, segment := range segments {
+		if keepJPEGSegment(segment) {
+			out = append(out, data[segment.start:segment.end]...)
+		}
+	}
+	return append(out, data[scan:end]...), nil
+}
+
+// jpegEnd returns the offset after the end marker of a JPEG image, walking
+// its scans from offset scan. Marker bytes in entropy-coded data are
+// followed by a zero byte, so only real markers end the walk.
+func jpegEnd(data []byte, scan int) (int, error) {
+	for i := scan; i < len(data); {
+		if data[i] != 0xFF {
+			i++
+			continue
+		}
+		// Markers may be preceded by fill bytes
+		j := i + 1
+		for j < len(data) && data[j] == 0xFF {
+			j++
+		}
+		if j >= len(data) {
+			break
+		}
+		marker := data[j]
+		switch {
+		case marker == 0xD9: // End of image
+			return j + 1, nil
+		case marker == 0x00 || (marker >= 0xD0 && marker <= 0xD7): // Stuffed byte, restart
+			i = j + 1
+			continue
+		}
+		// Segments between scans, and the headers of scans
+		if j+3 > len(data) {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[j+1:]))
+		if length < 2 {
+			return 0, errors.New("malformed JPEG segment")
+		}
+		i = j + 1 + length
+	}
+	return 0, errors.New("missing JPEG end marker")
+}
+
+// keepJPEGSegment reports whether a JPEG segment is kept by stripJPEG
+func keepJPEGSegment(segment jpegSegment) bool {
+	switch {
+	case segment.marker == 0xFE: // Comment
+		return false
+	case segment.marker == 0xE0 || segment.marker == 0xEE: // JFIF, Adobe color transform
+		return true
+	case segment.marker == 0xE2: // ICC color profile; other APP2 data is dropped
+		return bytes.HasPrefix(segment.payload, []byte("ICC_PROFILE\x00"))
+	case segment.marker >= 0xE1 && segment.marker <= 0xEF: // Application data
+		return false
+	}
+	return true
+}
+
+// pngSignature starts every PNG image
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// pngMetadataChunks are the PNG chunks holding text, EXIF and timestamps
+var pngMetadataChunks = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"eXIf": true,
+	"tIME": true,
+}
+
+// stripPNG removes the metadata chunks of a PNG image
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return nil, errors.New("missing PNG signature")
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	for i := len(pngSignature); i < len(data); {
+		// Length, type, data and CRC
+		if i+12 > len(data) {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		length := int64(binary.BigEndian.Uint32(data[i:]))
+		if length > int64(len(data)-i-12) {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		end := i + 12 + int(length)
+		if !pngMetadataChunks[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// exifOrientation returns the EXIF orientation of a JPEG image, from 1
+// (upright) to 8, or 1 if it has none
+func exifOrientation(data []byte) int {
+	segments, _, err := jpegSegments(data)
+	if err != nil {
+		return 1
+	}
+	for _, segment := range segments {
+		if segment.marker == 0xE1 && bytes.HasPrefix(segment.payload, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment.payload[6:])
+		}
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first directory of
+// EXIF data, which is structured like a TIFF file
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > int64(len(tiff)) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := int(ifd) + 2 + 12*i
+		if entry+12 > len(tiff) {
+			break
+		}
+		// Orientation is tag 0x0112, a SHORT (type 3) stored in the entry
+		if order.Uint16(tiff[entry:]) != 0x0112 || order.Uint16(tiff[entry+2:]) != 3 {
+			continue
+		}
+		if orientation := int(order.Uint16(tiff[entry+8:])); orientation >= 1 && orientation <= 8 {
+			return orientation
+		}
+		return 1
+	}
+	return 1
+}
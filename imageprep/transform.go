@@ -0,0 +1,90 @@
+package imageprep
+
+import (
+	"image"
+)
+
+// resize downscales an image to width by height, averaging the source
+// pixels each destination pixel covers. Colors are averaged premultiplied,
+// so transparent pixels do not darken their neighbours.
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// Source columns covered by each destination column
+	columns := make([][2]int, width)
+	for x := range columns {
+		columns[x] = span(x, width, srcWidth)
+	}
+
+	for y := 0; y < height; y++ {
+		rows := span(y, height, srcHeight)
+		for x, cols := range columns {
+			var sum [4]int
+			for sy := rows[0]; sy < rows[1]; sy++ {
+				offset := src.PixOffset(src.Rect.Min.X+cols[0], src.Rect.Min.Y+sy)
+				for sx := cols[0]; sx < cols[1]; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[offset+c])
+					}
+					offset += 4
+				}
+			}
+			count := (rows[1] - rows[0]) * (cols[1] - cols[0])
+			offset := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8((sum[c] + count/2) / count)
+			}
+		}
+	}
+	return dst
+}
+
+// span returns the range of source pixels covered by destination pixel i
+// when n source pixels are scaled to size, covering at least one
+func span(i, size, n int) [2]int {
+	start, end := i*n/size, (i+1)*n/size
+	if end <= start {
+		end = start + 1
+	}
+	return [2]int{start, end}
+}
+
+// orient transforms an image stored with an EXIF orientation so it is
+// upright. Orientations 5 to 8 swap the width and height.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	srcWidth, srcHeight := src.Rect.Dx(), src.Rect.Dy()
+	width, height := srcWidth, srcHeight
+	if orientation >= 5 {
+		width, height = srcHeight, srcWidth
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				sx, sy = srcWidth-1-x, y
+			case 3: // Rotated 180°
+				sx, sy = srcWidth-1-x, srcHeight-1-y
+			case 4: // Mirrored vertically
+				sx, sy = x, srcHeight-1-y
+			case 5: // Transposed
+				sx, sy = y, x
+			case 6: // Needs rotating 90° clockwise
+				sx, sy = y, srcHeight-1-x
+			case 7: // Transversed
+				sx, sy = srcWidth-1-y, srcHeight-1-x
+			case 8: // Needs rotating 90° counter-clockwise
+				sx, sy = srcWidth-1-y, x
+			}
+			from := src.PixOffset(src.Rect.Min.X+sx, src.Rect.Min.Y+sy)
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], src.Pix[from:from+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,69 @@
+package aiprovider
+
+import (
+	"github.com/ajeet-kumar1087/ai-providers/guard"
+)
+
+// injectionExcerptLength is the maximum length of InjectionFinding.Excerpt
+const injectionExcerptLength = 120
+
+// applyInjectionGuard checks untrusted messages for suspicious instructions
+// and, if the guard is enabled, wraps them and adds the guard instruction.
+// The request's messages are copied, never modified in place.
+func (c *client) applyInjectionGuard(req ChatRequest) (ChatRequest, []InjectionFinding) {
+	detector := c.config.InjectionDetector
+	if detector == nil && c.config.PromptInjectionGuard {
+		detector = guard.Detect
+	}
+	if detector == nil {
+		return req, nil
+	}
+
+	var findings []InjectionFinding
+	var messages []Message
+	for i, msg := range req.Messages {
+		// Streamed content is never read into memory to be checked
+		if !msg.Untrusted || msg.ContentReader != nil {
+			continue
+		}
+
+		if reasons := detector(msg.Content); len(reasons) > 0 {
+			finding := InjectionFinding{
+				MessageIndex: i,
+				Reasons:      reasons,
+				Excerpt:      guard.Excerpt(msg.Content, injectionExcerptLength),
+			}
+			findings = append(findings, finding)
+			if c.config.OnInjectionDetected != nil {
+				c.config.OnInjectionDetected(finding)
+			}
+		}
+
+		if c.config.PromptInjectionGuard {
+			if messages == nil {
+				messages = append([]Message(nil), req.Messages...)
+			}
+			messages[i].Content = guard.Wrap(msg.Content)
+		}
+	}
+
+	if messages == nil {
+		return req, findings
+	}
+
+	// Instruct the model before any other message; providers without system
+	// message support get the instruction in the first user message instead
+	if c.SupportsFeature(FeatureSystemMessages) {
+		messages = append([]Message{{Role: "system", Content: guard.Instruction}}, messages...)
+	} else {
+		for i := range messages {
+			if messages[i].Role == "user" {
+				messages[i].Content = guard.Instruction + "\n\n" + messages[i].Content
+				break
+			}
+		}
+	}
+
+	req.Messages = messages
+	return req, findings
+}
@@ -1,6 +1,11 @@
 package aiprovider
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ajeet-kumar1087/ai-providers/rag"
+)
 
 // Client represents the main interface for interacting with AI providers.
 //
@@ -10,6 +15,14 @@ import "context"
 //
 // The interface supports both simple text completions and conversational
 // chat completions with proper context management and parameter normalization.
+//
+// Clients returned by NewClient are safe for concurrent use by multiple
+// goroutines, including RegisterProfile racing with in-flight requests; share
+// one client rather than creating one per request. A ChatStream is not safe
+// for concurrent use, except for Close. Because requests run concurrently,
+// the hooks in Config (UsageRecorder, Store, OnUnsupportedParameter,
+// OnInjectionDetected, OnStoreError, DebugLogger) may be called from several
+// goroutines at once and must be safe for concurrent use.
 type Client interface {
 	// Complete sends a text completion request to the AI provider.
 	//
@@ -41,13 +54,327 @@ type Client interface {
 	//   - error: Provider-specific error wrapped in standardized error type
 	ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error)
 
-	// Close cleans up resources and closes the client connection.
+	// RateLimitStatus returns the most recent rate limit state reported by the provider.
+	//
+	// The status is updated from the rate limit headers of every successful
+	// response, allowing schedulers to pace traffic based on the real remaining
+	// quota instead of guessing. The same information is available per response
+	// via the Metadata.RateLimit field.
+	//
+	// Returns:
+	//   - *RateLimitStatus: A copy of the last observed status, or nil if none has been observed yet
+	RateLimitStatus() *RateLimitStatus
+
+	// Limits returns the known rate limits and quotas of the account.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//
+	// Returns:
+	//   - *Limits: The known limits; zero values are unknown
+	//   - error: A provider error if querying the provider's limits endpoint failed
+	Limits(ctx context.Context) (*Limits, error)
+
+	// Summarize summarizes text of any length.
+	//
+	// Long inputs are split into chunks that are summarized separately and then
+	// combined (map-reduce). The result includes the intermediate chunk summaries.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - text: The text to summarize
+	//   - opts: Chunking, length and model options
+	//
+	// Returns:
+	//   - *SummaryResult: The final summary and per-chunk intermediate results
+	//   - error: A validation error for empty input, or the first request error
+	Summarize(ctx context.Context, text string, opts SummarizeOptions) (*SummaryResult, error)
+
+	// Classify assigns text to one of the given labels.
+	//
+	// The model is constrained to answer with a label, and the result carries a
+	// confidence heuristic based on how closely and consistently it did so.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - text: The text to classify
+	//   - labels: The candidate labels (at least two, unique)
+	//   - opts: Instructions, label descriptions and sampling options
+	//
+	// Returns:
+	//   - *ClassificationResult: The chosen label, confidence and raw responses
+	//   - error: A validation error for invalid input, or the first request error
+	Classify(ctx context.Context, text string, labels []string, opts ClassifyOptions) (*ClassificationResult, error)
+
+	// CompleteWithVoting samples k completions and returns the answer most
+	// of them agree on (self-consistency).
+	//
+	// Answers are grouped by exact match after normalization, or by
+	// embedding similarity when opts has an Embedder.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - req: The completion request to sample
+	//   - k: The number of samples (at least 1)
+	//   - opts: Sampling and grouping options
+	//
+	// Returns:
+	//   - *VotingResult: The consensus answer, every candidate and the answer groups
+	//   - error: A validation error for invalid input, or the first request error
+	CompleteWithVoting(ctx context.Context, req CompletionRequest, k int, opts VotingOptions) (*VotingResult, error)
+
+	// CompleteBestOfN generates n candidate completions and returns the one
+	// opts.Scorer rates highest.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - req: The completion request to sample
+	//   - n: The number of candidates (at least 1)
+	//   - opts: The scorer and sampling options
+	//
+	// Returns:
+	//   - *BestOfNResult: The winning completion and every candidate's score
+	//   - error: A validation error for invalid input, the first request error, or the scorer's error
+	CompleteBestOfN(ctx context.Context, req CompletionRequest, n int, opts BestOfNOptions) (*BestOfNResult, error)
+
+	// Extract pulls structured data matching a JSON schema out of text.
+	//
+	// Output is validated against the schema and invalid responses are sent
+	// back to the model for correction. Use ExtractionResult.Decode to
+	// unmarshal the result into a struct.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - text: The text to extract from
+	//   - schema: JSON schema describing the output (see the jsonschema package)
+	//   - opts: Instructions, model and retry options
+	//
+	// Returns:
+	//   - *ExtractionResult: The validated JSON and attempt statistics
+	//   - error: A validation error if no valid output was produced, or the first request error
+	Extract(ctx context.Context, text string, schema json.RawMessage, opts ExtractOptions) (*ExtractionResult, error)
+
+	// Translate translates text into targetLang.
+	//
+	// The source language is detected when not given, long documents are
+	// translated in chunks, and code blocks and markup are preserved.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - text: The text to translate
+	//   - targetLang: The language to translate into, e.g. "French"
+	//   - opts: Source language, chunking and model options
+	//
+	// Returns:
+	//   - *TranslationResult: The translated text and source language
+	//   - error: A validation error for invalid input, or the first request error
+	Translate(ctx context.Context, text, targetLang string, opts TranslateOptions) (*TranslationResult, error)
+
+	// Answer answers a question from a set of documents.
+	//
+	// Relevant chunks are retrieved with embeddings and the model answers from
+	// them with numbered citations, which are resolved back to source chunks.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - question: The question to answer
+	//   - docs: The documents to answer from (may be empty when opts.Index is set)
+	//   - opts: Embedder or index, retrieval and model options
+	//
+	// Returns:
+	//   - *AnswerResult: The answer with retrieved and cited sources
+	//   - error: A validation error for invalid input, or a retrieval or request error
+	Answer(ctx context.Context, question string, docs []rag.Document, opts AnswerOptions) (*AnswerResult, error)
+
+	// CountTokensRemote counts the input tokens of a chat request.
+	//
+	// Providers with a token counting endpoint return exact counts; otherwise,
+	// or if the endpoint fails, the count is estimated with the local tokenizer.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - req: The chat request to count
+	//
+	// Returns:
+	//   - *TokenCount: The token count and the method used to obtain it
+	//   - error: A validation error for invalid requests, or a context error
+	CountTokensRemote(ctx context.Context, req ChatRequest) (*TokenCount, error)
+
+	// Speech converts text to audio.
+	//
+	// The audio is streamed from the provider rather than read into memory;
+	// the caller must close the returned response.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - req: The text and optional model, voice, format and speed
+	//
+	// Returns:
+	//   - *BinaryResponse: The audio stream with its content type and length
+	//   - error: A validation error if the provider does not support speech, or a provider error
+	Speech(ctx context.Context, req SpeechRequest) (*BinaryResponse, error)
+
+	// Transcribe converts speech to text.
+	//
+	// Recordings over the provider's upload limit are split into chunks
+	// at quiet moments and transcribed in turn, with the transcripts
+	// stitched together.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - req: The recording and optional model, language and prompt
+	//
+	// Returns:
+	//   - *TranscriptionResponse: The transcript with its timed segments
+	//   - error: A validation error if the provider does not support transcription or the recording cannot be split, or a provider error
+	Transcribe(ctx context.Context, req TranscriptionRequest) (*TranscriptionResponse, error)
+
+	// Realtime opens an experimental realtime speech-to-speech session.
+	//
+	// The session stays open, and counts as in flight for Close, until it is
+	// closed; usage of each response is recorded like other requests.
+	//
+	// Parameters:
+	//   - ctx: Context for opening the session
+	//   - req: The session's model, voice, audio formats and tools
+	//
+	// Returns:
+	//   - RealtimeSession: The open session
+	//   - error: A validation error if the provider does not support realtime sessions, or a provider error
+	Realtime(ctx context.Context, req RealtimeRequest) (RealtimeSession, error)
+
+	// SubmitBatch submits chat requests for asynchronous processing at a
+	// lower price. Poll GetBatch until the batch is done, then read the
+	// responses with ListBatchResults.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - items: The requests, each with an ID unique within the batch
+	//
+	// Returns:
+	//   - *Batch: The submitted batch with its ID and status
+	//   - error: A validation error for invalid items or if batches are unsupported, or a provider error
+	SubmitBatch(ctx context.Context, items []BatchItem) (*Batch, error)
+
+	// GetBatch returns the current status of a batch.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - id: The batch ID returned by SubmitBatch
+	//
+	// Returns:
+	//   - *Batch: The batch status and request counts
+	//   - error: A validation error if batches are unsupported, or a provider error
+	GetBatch(ctx context.Context, id string) (*Batch, error)
+
+	// ListBatchResults returns the result of every item of an ended batch.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout control
+	//   - id: The batch ID returned by SubmitBatch
+	//
+	// Returns:
+	//   - []BatchResult: One result per item, in no particular order
+	//   - error: A validation error if batches are unsupported, or a provider error
+	ListBatchResults(ctx context.Context, id string) ([]BatchResult, error)
+
+	// RegisterProfile adds or replaces a named request profile.
+	//
+	// Requests select profiles with their Profile field; request fields take
+	// precedence over profile fields, which take precedence over config defaults.
+	//
+	// Parameters:
+	//   - name: The profile name referenced by requests
+	//   - profile: The request defaults
+	//
+	// Returns:
+	//   - error: A validation error if the name is empty or the profile is invalid
+	RegisterProfile(name string, profile RequestProfile) error
+
+	// StreamChat sends a chat completion request and streams the response.
+	//
+	// Streams that receive no data within Config.StreamIdleTimeout fail with
+	// a retryable network error instead of hanging.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout, covering the whole stream
+	//   - req: The chat request containing messages and optional parameters
+	//
+	// Returns:
+	//   - *ChatStream: The open stream; read it with Recv until io.EOF
+	//   - error: A validation error if streaming is unsupported, or the provider error
+	StreamChat(ctx context.Context, req ChatRequest) (*ChatStream, error)
+
+	// StreamSpeculative streams a draft answer from a small, fast model
+	// while the final answer is generated, then swaps the final answer in.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout, covering both requests
+	//   - req: The chat request for the final answer
+	//   - opts: The draft model or client
+	//
+	// Returns:
+	//   - *SpeculativeStream: The open stream; read it with Recv until io.EOF
+	//   - error: A validation error if no draft model or client is given
+	StreamSpeculative(ctx context.Context, req ChatRequest, opts SpeculativeOptions) (*SpeculativeStream, error)
+
+	// ChatCompleteWithResume sends a chat request and resumes the generation
+	// if the output is cut off.
+	//
+	// Interrupted streams and responses stopped at the token limit are
+	// continued with the partial output appended, and the pieces are stitched
+	// into one response with Metadata.Resumed set.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout
+	//   - req: The chat request containing messages and optional parameters
+	//   - opts: Resume limits and continuation prompt
+	//
+	// Returns:
+	//   - *ChatResponse: The stitched response with total usage
+	//   - error: A non-retryable request error, or the last error once resumes are exhausted
+	ChatCompleteWithResume(ctx context.Context, req ChatRequest, opts ResumeOptions) (*ChatResponse, error)
+
+	// SupportsFeature reports whether the provider supports a feature.
 	//
-	// This method should be called when the client is no longer needed
-	// to ensure proper resource cleanup. It's safe to call multiple times.
+	// Complete and ChatComplete check the features a request needs before
+	// contacting the provider and return an ErrorTypeValidation error for
+	// unsupported ones, so callers can use this method to choose a request
+	// shape up front.
+	//
+	// Parameters:
+	//   - feature: A feature identifier such as FeatureChatCompletion or FeatureSystemMessages
 	//
 	// Returns:
-	//   - error: An error if cleanup fails (currently always returns nil)
+	//   - bool: true if the provider adapter supports the feature
+	SupportsFeature(feature string) bool
+
+	// CancelGroup cancels the requests and streams in flight in a
+	// cancellation group, set with WithCancelGroup.
+	//
+	// Cancelled requests fail with an error wrapping ErrGroupCancelled,
+	// streams are closed and requests waiting for the client-side rate
+	// limiter return their token. Requests of the group started afterwards
+	// are not affected.
+	//
+	// Parameters:
+	//   - group: The group ID
+	//
+	// Returns:
+	//   - int: The number of requests and streams cancelled
+	CancelGroup(group string) int
+
+	// Close shuts the client down gracefully.
+	//
+	// New requests fail with ErrClientClosed, requests and streams in flight
+	// are given Config.ShutdownTimeout to finish, buffered usage and
+	// interaction records are flushed and idle connections are closed.
+	// Helpers such as Summarize or Translate count as one request, so those
+	// in flight can still make their remaining provider calls. It's safe to
+	// call multiple times.
+	//
+	// Returns:
+	//   - error: An error if in-flight requests did not finish in time or a flush failed
 	Close() error
 }
 
@@ -113,14 +440,90 @@ type ProviderAdapter interface {
 	// SupportedFeatures returns a list of features supported by this provider.
 	//
 	// This allows clients to query provider capabilities and adapt behavior
-	// accordingly. Features might include "streaming", "function_calling",
-	// "image_input", etc.
+	// accordingly. Features are identified by the Feature* constants, such as
+	// FeatureChatCompletion or FeatureStopSequences. The client rejects
+	// requests needing an unlisted feature, so the list must be accurate.
 	//
 	// Returns:
 	//   - []string: List of supported feature identifiers
 	SupportedFeatures() []string
 }
 
+// TokenCounter is implemented by adapters whose provider offers exact token counting.
+//
+// Adapters implementing it should also advertise FeatureTokenCounting.
+type TokenCounter interface {
+	// CountTokens returns the number of input tokens the provider would bill for req
+	CountTokens(ctx context.Context, req ChatRequest) (int, error)
+}
+
+// StreamingAdapter is implemented by adapters that can stream chat responses.
+//
+// Adapters implementing it should also advertise FeatureStreaming.
+type StreamingAdapter interface {
+	// StreamChat opens a streamed chat completion for req
+	StreamChat(ctx context.Context, req ChatRequest) (StreamReader, error)
+}
+
+// SpeechAdapter is implemented by adapters that can convert text to speech.
+//
+// Adapters implementing it should also advertise FeatureSpeech.
+type SpeechAdapter interface {
+	// Speech generates audio for req, streamed rather than read into memory
+	Speech(ctx context.Context, req SpeechRequest) (*BinaryResponse, error)
+}
+
+// TranscriptionAdapter is implemented by adapters that can convert speech
+// to text.
+//
+// Adapters implementing it should also advertise FeatureTranscription.
+type TranscriptionAdapter interface {
+	// Transcribe transcribes a recording within the provider's upload limit
+	Transcribe(ctx context.Context, req TranscriptionRequest) (*TranscriptionResponse, error)
+}
+
+// RealtimeAdapter is implemented by adapters that can open realtime
+// speech-to-speech sessions.
+//
+// Adapters implementing it should also advertise FeatureRealtime.
+type RealtimeAdapter interface {
+	// Realtime opens a session configured with req
+	Realtime(ctx context.Context, req RealtimeRequest) (RealtimeSession, error)
+}
+
+// BatchAdapter is implemented by adapters that can process batches of chat
+// requests asynchronously.
+//
+// Adapters implementing it should also advertise FeatureBatch.
+type BatchAdapter interface {
+	// SubmitBatch submits items, which have been validated and normalized
+	SubmitBatch(ctx context.Context, items []BatchItem) (*Batch, error)
+
+	// GetBatch returns the status of a batch
+	GetBatch(ctx context.Context, id string) (*Batch, error)
+
+	// ListBatchResults returns the results of an ended batch
+	ListBatchResults(ctx context.Context, id string) ([]BatchResult, error)
+}
+
+// LimitsAdapter is implemented by adapters that can query the provider for
+// the rate limits and quotas of the account, rather than only learning them
+// from response headers.
+type LimitsAdapter interface {
+	// Limits returns the account limits reported by the provider
+	Limits(ctx context.Context) (*Limits, error)
+}
+
+// UsageAdapter is implemented by adapters whose provider offers an API for
+// the account's usage and billed costs, read by UsageClient.
+type UsageAdapter interface {
+	// Usage returns the token usage and request counts in query's period
+	Usage(ctx context.Context, query UsageQuery) ([]ProviderUsage, error)
+
+	// Costs returns the billed costs in query's period
+	Costs(ctx context.Context, query UsageQuery) ([]ProviderUsage, error)
+}
+
 // ClientFactory represents the interface for creating AI provider clients.
 //
 // This interface provides a factory pattern for client creation, useful in
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize is the capacity above which buffers are not returned to
+// the pool, so one unusually large response does not stay pinned in memory
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool recycles buffers for reading response bodies
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// GetBuffer returns an empty buffer from a shared pool. Return it with
+// PutBuffer once its contents are no longer referenced; decoding JSON from
+// the buffer copies the values it keeps, so the buffer can be released as
+// soon as decoding returns.
+func GetBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// PutBuffer returns a buffer obtained from GetBuffer to the pool.
+func PutBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
@@ -6,63 +6,208 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"time"
 )
 
+const (
+	// baseRetryDelay is the backoff ceiling for the first retry
+	baseRetryDelay = time.Second
+
+	// maxRetryDelay caps the backoff ceiling of a single retry
+	maxRetryDelay = 30 * time.Second
+
+	// DefaultIdempotentRetries is the number of retries for idempotent
+	// requests such as GET, which are independent of the retries configured
+	// for POST requests
+	DefaultIdempotentRetries = 3
+)
+
 // HTTPClient interface for making HTTP requests (allows for mocking in tests)
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// RetryStats records how many attempts a request took and how long was
+// spent waiting between them.
+type RetryStats struct {
+	// Attempts is the number of HTTP attempts made, including the first
+	Attempts int
+
+	// TotalWait is the cumulative backoff time spent between attempts
+	TotalWait time.Duration
+}
+
+// retryStatsKey is the context key for RetryStats
+type retryStatsKey struct{}
+
+// WithRetryStats returns a context that collects retry statistics for
+// requests made with it. The returned stats are filled in as the request
+// progresses and are final once Post or Get returns.
+func WithRetryStats(ctx context.Context) (context.Context, *RetryStats) {
+	stats := &RetryStats{}
+	return context.WithValue(ctx, retryStatsKey{}, stats), stats
+}
+
+// retryStatsFromContext returns the stats collector in ctx, or nil
+func retryStatsFromContext(ctx context.Context) *RetryStats {
+	stats, _ := ctx.Value(retryStatsKey{}).(*RetryStats)
+	return stats
+}
+
 // Client wraps the standard HTTP client with retry logic and timeout handling
 type Client struct {
-	httpClient HTTPClient
-	timeout    time.Duration
-	maxRetries int
+	httpClient   HTTPClient
+	streamClient HTTPClient
+	timeout      time.Duration
+	maxRetries   int
+	maxRetryWait time.Duration
+	debug        *debugLogger
+
+	// reasoningFields are JSON fields holding model reasoning, whose text is
+	// redacted from debug entries and captures
+	reasoningFields map[string]bool
+
+	// idempotentRetries limits retries of requests that are safe to repeat
+	idempotentRetries int
+
+	// compressThreshold is the smallest request body gzip-compressed, or 0
+	compressThreshold int
+
+	// transformRequest rewrites JSON request bodies before they are sent
+	transformRequest func(body []byte) ([]byte, error)
+
+	// transformResponse rewrites response bodies before they are parsed
+	transformResponse func(body []byte) ([]byte, error)
+
+	// jitter picks a backoff in [0, ceiling]; replaced in tests
+	jitter func(ceiling time.Duration) time.Duration
 }
 
 // NewClient creates a new HTTP client with the specified configuration
 func NewClient(timeout time.Duration, maxRetries int) *Client {
+	// Streamed bodies may take far longer than timeout to read, so the stream
+	// client only limits the wait for response headers
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+
 	return &Client{
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
-		timeout:    timeout,
-		maxRetries: maxRetries,
+		streamClient: &http.Client{
+			Transport: transport,
+		},
+		timeout:           timeout,
+		maxRetries:        maxRetries,
+		idempotentRetries: DefaultIdempotentRetries,
+		jitter:            fullJitter,
 	}
 }
 
 // NewClientWithHTTPClient creates a new HTTP client with a custom HTTP client
 func NewClientWithHTTPClient(httpClient HTTPClient, timeout time.Duration, maxRetries int) *Client {
 	return &Client{
-		httpClient: httpClient,
-		timeout:    timeout,
-		maxRetries: maxRetries,
+		httpClient:        httpClient,
+		streamClient:      httpClient,
+		timeout:           timeout,
+		maxRetries:        maxRetries,
+		idempotentRetries: DefaultIdempotentRetries,
+		jitter:            fullJitter,
+	}
+}
+
+// SetMaxRetryWait caps the cumulative backoff time across all retries of a
+// request, independently of the per-attempt timeout. Once the next backoff
+// would exceed the budget, the last response or error is returned. Zero
+// disables the cap.
+func (c *Client) SetMaxRetryWait(maxRetryWait time.Duration) {
+	c.maxRetryWait = maxRetryWait
+}
+
+// SetIdempotentRetries sets the number of retries for idempotent requests
+// such as Get. These are safe to repeat however a previous attempt failed, so
+// their limit is kept separate from the one passed to NewClient, which only
+// applies to POST requests. The default is DefaultIdempotentRetries.
+func (c *Client) SetIdempotentRetries(retries int) {
+	c.idempotentRetries = retries
+}
+
+// CloseIdleConnections closes connections kept alive for reuse that are not
+// serving a request. HTTP clients without idle connections are left alone.
+func (c *Client) CloseIdleConnections() {
+	for _, httpClient := range []HTTPClient{c.httpClient, c.streamClient} {
+		if closer, ok := httpClient.(interface{ CloseIdleConnections() }); ok {
+			closer.CloseIdleConnections()
+		}
 	}
 }
 
 // Post makes a POST request with retry logic
 func (c *Client) Post(ctx context.Context, url string, headers map[string]string, body []byte) (*http.Response, error) {
+	req, body, err := c.newPostRequest(ctx, url, headers, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.transformResponseBody(c.doWithRetry(c.httpClient, req, body))
+}
+
+// newPostRequest creates a POST request with headers and a JSON content type
+// unless another is set, applying the request transformer and compressing
+// the body if it reaches the compression threshold. It returns the body as
+// sent.
+func (c *Client) newPostRequest(ctx context.Context, url string, headers map[string]string, body []byte) (*http.Request, []byte, error) {
+	body, err := c.transformRequestBody(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, compressed := c.compressBody(body)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
+	setHeaders(req.Header, headers)
 
 	// Set default content type if not provided
 	if req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	return req, body, nil
+}
+
+// PostStream makes a POST request for a streamed response with retry logic.
+//
+// Retries only cover the request up to the response headers; once a
+// successful response is returned, reading its body is up to the caller (see
+// SSEReader). Unlike Post, the configured timeout limits only the wait for
+// response headers, not reading the body.
+func (c *Client) PostStream(ctx context.Context, url string, headers map[string]string, body []byte) (*http.Response, error) {
+	req, body, err := c.newPostRequest(ctx, url, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
 
-	return c.doWithRetry(req)
+	streamClient := c.streamClient
+	if streamClient == nil {
+		streamClient = c.httpClient
+	}
+	return c.transformErrorBody(c.doWithRetry(streamClient, req, body))
 }
 
-// Get makes a GET request with retry logic
+// Get makes a GET request with retry logic.
+//
+// GET requests are idempotent, so they are retried on any network error and
+// on 408, 425, 429 and 5xx responses, up to the idempotent retry limit (see
+// SetIdempotentRetries) rather than the limit for POST requests.
 func (c *Client) Get(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -70,55 +215,156 @@ func (c *Client) Get(ctx context.Context, url string, headers map[string]string)
 	}
 
 	// Set headers
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	setHeaders(req.Header, headers)
+
+	return c.transformResponseBody(c.doWithRetry(c.httpClient, req, nil))
+}
+
+// Delete makes a DELETE request with retry logic. Like Get, it is idempotent
+// and retried under the idempotent retry limit.
+func (c *Client) Delete(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	return c.doWithRetry(req)
+	setHeaders(req.Header, headers)
+
+	return c.transformResponseBody(c.doWithRetry(c.httpClient, req, nil))
+}
+
+// setHeaders sets each of headers on header. The values share one backing
+// array instead of allocating a slice per header as http.Header.Set does.
+func setHeaders(header http.Header, headers map[string]string) {
+	values := make([]string, 0, len(headers))
+	for key, value := range headers {
+		values = append(values, value)
+		header[http.CanonicalHeaderKey(key)] = values[len(values)-1 : len(values) : len(values)]
+	}
 }
 
-// doWithRetry executes the request with retry logic
-func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
+// doWithRetry executes the request with httpClient using retry logic. body is
+// the request body, or nil if the request has none; retries resend it from
+// this slice rather than reading req.Body again. A streamed body without
+// bytes is resent through req.GetBody, and is not retried if that is nil.
+func (c *Client) doWithRetry(httpClient HTTPClient, req *http.Request, body []byte) (*http.Response, error) {
+	ctx := req.Context()
+	stats := retryStatsFromContext(ctx)
+	if stats == nil {
+		stats = &RetryStats{}
+	}
+	capture := captureFromContext(ctx)
+
+	maxRetries, retryStatus := c.maxRetries, c.shouldRetryStatus
+	if isIdempotent(req.Method) {
+		maxRetries, retryStatus = c.idempotentRetries, shouldRetryIdempotentStatus
+	}
+	if body == nil && req.Body != nil && req.GetBody == nil {
+		maxRetries = 0
+	}
+
+	// Setting Accept-Encoding stops the transport decoding gzip itself, so
+	// responses are decoded below unless the caller asked for an encoding
+	decode := req.Header.Get("Accept-Encoding") == ""
+	if decode {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
 	var lastErr error
 
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		// Clone the request for retry attempts
-		reqClone := req.Clone(req.Context())
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		stats.Attempts = attempt + 1
 
-		// If there's a body, we need to reset it for retries
-		if req.Body != nil {
-			body, err := io.ReadAll(req.Body)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read request body: %w", err)
+		// The first attempt sends req itself; retries send a clone with a
+		// fresh reader over the same body
+		reqClone := req
+		if attempt > 0 {
+			reqClone = req.Clone(ctx)
+			if body != nil {
+				reqClone.Body = io.NopCloser(bytes.NewReader(body))
+			} else if req.GetBody != nil {
+				replay, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("failed to replay request body: %w", err)
+				}
+				reqClone.Body = replay
 			}
-			req.Body.Close()
-			reqClone.Body = io.NopCloser(bytes.NewReader(body))
-			// Reset original request body for potential future retries
-			req.Body = io.NopCloser(bytes.NewReader(body))
 		}
 
-		resp, err := c.httpClient.Do(reqClone)
+		start := time.Now()
+		resp, err := httpClient.Do(reqClone)
+		if err == nil && decode {
+			decompressResponse(resp)
+		}
+		duration := time.Since(start)
+		if c.debug != nil {
+			c.debug.logExchange(reqClone, body, attempt+1, resp, err, duration, c.reasoningFields)
+		}
+		if capture != nil {
+			capture.add(newDebugEntry(reqClone, body, attempt+1, resp, err, duration, 0, c.reasoningFields))
+		}
 		if err != nil {
 			lastErr = err
-			if attempt < c.maxRetries && c.shouldRetryError(err) {
-				c.waitBeforeRetry(attempt)
-				continue
+			if attempt < maxRetries && c.shouldRetryError(err) {
+				if wait, ok := c.nextBackoff(attempt, stats.TotalWait); ok {
+					if err := sleepContext(ctx, wait); err != nil {
+						return nil, fmt.Errorf("HTTP request cancelled during retry backoff: %w", err)
+					}
+					stats.TotalWait += wait
+					continue
+				}
 			}
 			return nil, fmt.Errorf("HTTP request failed after %d attempts: %w", attempt+1, err)
 		}
 
 		// Check if we should retry based on status code
-		if c.shouldRetryStatus(resp.StatusCode) && attempt < c.maxRetries {
+		if retryStatus(resp.StatusCode) && attempt < maxRetries {
+			wait, ok := c.nextBackoff(attempt, stats.TotalWait)
+			if !ok {
+				// Retry budget exhausted; let the caller handle the error response
+				return resp, nil
+			}
 			resp.Body.Close()
 			lastErr = fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-			c.waitBeforeRetry(attempt)
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, fmt.Errorf("HTTP request cancelled during retry backoff: %w", err)
+			}
+			stats.TotalWait += wait
 			continue
 		}
 
 		return resp, nil
 	}
 
-	return nil, fmt.Errorf("HTTP request failed after %d attempts: %w", c.maxRetries+1, lastErr)
+	return nil, fmt.Errorf("HTTP request failed after %d attempts: %w", maxRetries+1, lastErr)
+}
+
+// isIdempotent reports whether requests with method can be repeated without
+// changing the result, which makes them safe to retry after any failure
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// shouldRetryIdempotentStatus determines if an HTTP status code should
+// trigger a retry of an idempotent request. Unlike POST requests, these are
+// also retried when the server timed out or refused early data, as repeating
+// them cannot duplicate work.
+func shouldRetryIdempotentStatus(statusCode int) bool {
+	switch statusCode {
+	case 408, 425: // Request timeout, too early
+		return true
+	case 429: // Rate limited
+		return true
+	case 500, 502, 503, 504: // Server errors
+		return true
+	default:
+		return false
+	}
 }
 
 // shouldRetryError determines if an error should trigger a retry
@@ -140,15 +386,50 @@ func (c *Client) shouldRetryStatus(statusCode int) bool {
 	}
 }
 
-// waitBeforeRetry implements exponential backoff
-func (c *Client) waitBeforeRetry(attempt int) {
-	// Exponential backoff: 1s, 2s, 4s, 8s, etc.
-	backoff := time.Duration(1<<uint(attempt)) * time.Second
+// nextBackoff returns the wait before the retry following attempt, using
+// exponential backoff with full jitter: a uniformly random duration between
+// zero and min(maxRetryDelay, baseRetryDelay * 2^attempt). It reports false
+// if the wait would exceed the cumulative retry budget.
+func (c *Client) nextBackoff(attempt int, waited time.Duration) (time.Duration, bool) {
+	ceiling := maxRetryDelay
+	if attempt < 5 {
+		// 1s, 2s, 4s, 8s, 16s; later attempts use the cap
+		ceiling = baseRetryDelay << uint(attempt)
+	}
+
+	jitter := c.jitter
+	if jitter == nil {
+		jitter = fullJitter
+	}
+	wait := jitter(ceiling)
+
+	if c.maxRetryWait > 0 && waited+wait > c.maxRetryWait {
+		return 0, false
+	}
+	return wait, true
+}
+
+// fullJitter returns a random duration in [0, ceiling]
+func fullJitter(ceiling time.Duration) time.Duration {
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
 
-	// Cap the backoff at 30 seconds
-	if backoff > 30*time.Second {
-		backoff = 30 * time.Second
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
 	}
 
-	time.Sleep(backoff)
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
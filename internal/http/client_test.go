@@ -0,0 +1,304 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sequenceHTTPClient returns the given status codes in order, repeating the last
+type sequenceHTTPClient struct {
+	statuses []int
+	calls    int
+	bodies   []string
+}
+
+func (s *sequenceHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(body))
+	}
+	i := s.calls
+	if i >= len(s.statuses) {
+		i = len(s.statuses) - 1
+	}
+	s.calls++
+	return &http.Response{
+		StatusCode: s.statuses[i],
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Header:     make(http.Header),
+	}, nil
+}
+
+// fixedJitter makes backoff deterministic in tests
+func fixedJitter(wait time.Duration) func(time.Duration) time.Duration {
+	return func(time.Duration) time.Duration { return wait }
+}
+
+func TestDoWithRetryStats(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		maxRetries       int
+		maxRetryWait     time.Duration
+		expectedStatus   int
+		expectedAttempts int
+		expectedWait     time.Duration
+	}{
+		{
+			name:             "success without retry",
+			statuses:         []int{200},
+			maxRetries:       3,
+			expectedStatus:   200,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retries until success",
+			statuses:         []int{503, 429, 200},
+			maxRetries:       3,
+			expectedStatus:   200,
+			expectedAttempts: 3,
+			expectedWait:     4 * time.Millisecond,
+		},
+		{
+			name:             "retries exhausted returns last response",
+			statuses:         []int{503},
+			maxRetries:       2,
+			expectedStatus:   503,
+			expectedAttempts: 3,
+			expectedWait:     4 * time.Millisecond,
+		},
+		{
+			name:             "retry budget stops retries",
+			statuses:         []int{503},
+			maxRetries:       5,
+			maxRetryWait:     5 * time.Millisecond,
+			expectedStatus:   503,
+			expectedAttempts: 3,
+			expectedWait:     4 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &sequenceHTTPClient{statuses: tt.statuses}
+			client := NewClientWithHTTPClient(httpClient, time.Second, tt.maxRetries)
+			client.jitter = fixedJitter(2 * time.Millisecond)
+			client.SetMaxRetryWait(tt.maxRetryWait)
+
+			ctx, stats := WithRetryStats(context.Background())
+			resp, err := client.Post(ctx, "http://example.com", nil, []byte(`{"prompt":"Hi"}`))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if stats.Attempts != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.expectedAttempts, stats.Attempts)
+			}
+			if stats.TotalWait != tt.expectedWait {
+				t.Errorf("Expected total wait %v, got %v", tt.expectedWait, stats.TotalWait)
+			}
+			for i, body := range httpClient.bodies {
+				if body != `{"prompt":"Hi"}` {
+					t.Errorf("Expected attempt %d to send the full body, got %q", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+// failingHTTPClient always returns a network error
+type failingHTTPClient struct{}
+
+func (failingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestDoWithRetryCancelledDuringBackoff(t *testing.T) {
+	client := NewClientWithHTTPClient(failingHTTPClient{}, time.Second, 3)
+	client.jitter = fixedJitter(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Get(ctx, "http://example.com", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected backoff to stop on cancellation, took %v", elapsed)
+	}
+}
+
+func TestDoWithRetryIdempotent(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		statuses         []int
+		maxRetries       int
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		{
+			name:             "GET retries request timeouts",
+			method:           http.MethodGet,
+			statuses:         []int{408, 200},
+			maxRetries:       3,
+			expectedStatus:   200,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "POST does not retry request timeouts",
+			method:           http.MethodPost,
+			statuses:         []int{408, 200},
+			maxRetries:       3,
+			expectedStatus:   408,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "GET retries when POST retries are disabled",
+			method:           http.MethodGet,
+			statuses:         []int{503, 502, 200},
+			maxRetries:       0,
+			expectedStatus:   200,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "GET stops at the idempotent retry limit",
+			method:           http.MethodGet,
+			statuses:         []int{503},
+			maxRetries:       0,
+			expectedStatus:   503,
+			expectedAttempts: DefaultIdempotentRetries + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &sequenceHTTPClient{statuses: tt.statuses}
+			client := NewClientWithHTTPClient(httpClient, time.Second, tt.maxRetries)
+			client.jitter = fixedJitter(time.Millisecond)
+
+			ctx, stats := WithRetryStats(context.Background())
+			var resp *http.Response
+			var err error
+			if tt.method == http.MethodGet {
+				resp, err = client.Get(ctx, "http://example.com", nil)
+			} else {
+				resp, err = client.Post(ctx, "http://example.com", nil, []byte(`{}`))
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if stats.Attempts != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.expectedAttempts, stats.Attempts)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	httpClient := &sequenceHTTPClient{statuses: []int{502, 204}}
+	client := NewClientWithHTTPClient(httpClient, time.Second, 0)
+	client.jitter = fixedJitter(time.Millisecond)
+
+	resp, err := client.Delete(context.Background(), "http://example.com/files/file-1", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 204 || httpClient.calls != 2 {
+		t.Errorf("Expected status 204 after 2 attempts, got %d after %d", resp.StatusCode, httpClient.calls)
+	}
+}
+
+func TestSetIdempotentRetries(t *testing.T) {
+	httpClient := &sequenceHTTPClient{statuses: []int{500}}
+	client := NewClientWithHTTPClient(httpClient, time.Second, 5)
+	client.jitter = fixedJitter(time.Millisecond)
+	client.SetIdempotentRetries(1)
+
+	resp, err := client.Get(context.Background(), "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if httpClient.calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", httpClient.calls)
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	client := NewClientWithHTTPClient(nil, time.Second, 10)
+	client.jitter = func(ceiling time.Duration) time.Duration { return ceiling }
+
+	expected := []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		16 * time.Second,
+		30 * time.Second,
+		30 * time.Second,
+	}
+	for attempt, want := range expected {
+		got, ok := client.nextBackoff(attempt, 0)
+		if !ok || got != want {
+			t.Errorf("Attempt %d: expected ceiling %v, got %v (ok=%v)", attempt, want, got, ok)
+		}
+	}
+
+	client.SetMaxRetryWait(10 * time.Second)
+	if _, ok := client.nextBackoff(3, 5*time.Second); ok {
+		t.Error("Expected backoff beyond the retry budget to be refused")
+	}
+}
+
+func TestFullJitter(t *testing.T) {
+	ceiling := 100 * time.Millisecond
+	for i := 0; i < 1000; i++ {
+		if wait := fullJitter(ceiling); wait < 0 || wait > ceiling {
+			t.Fatalf("Expected jitter in [0, %v], got %v", ceiling, wait)
+		}
+	}
+	if wait := fullJitter(0); wait != 0 {
+		t.Errorf("Expected zero jitter for zero ceiling, got %v", wait)
+	}
+}
+
+// okHTTPClient returns an empty successful response
+type okHTTPClient struct{}
+
+func (okHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+}
+
+func BenchmarkClient_Post(b *testing.B) {
+	client := NewClientWithHTTPClient(okHTTPClient{}, 0, 3)
+	headers := map[string]string{"Content-Type": "application/json"}
+	body := []byte(`{"model":"claude-3-haiku-20240307","max_tokens":5,"messages":[{"role":"user","content":"I love it"}]}`)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		resp, err := client.Post(ctx, "https://api.example.com/v1/messages", headers, body)
+		if err != nil {
+			b.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding lists the response encodings the client decodes
+const acceptEncoding = "gzip, deflate"
+
+// SetRequestCompression gzip-compresses request bodies of at least threshold
+// bytes, sending them with Content-Encoding: gzip. This cuts transfer time for
+// very large prompts on slow networks, but only works with providers and
+// proxies that accept compressed requests. Zero, the default, disables it.
+//
+// Responses are decompressed regardless of this setting.
+func (c *Client) SetRequestCompression(threshold int) {
+	c.compressThreshold = threshold
+}
+
+// compressBody returns body gzip-compressed if it reaches the compression
+// threshold and compressing makes it smaller, reporting whether it did
+func (c *Client) compressBody(body []byte) ([]byte, bool) {
+	if c.compressThreshold <= 0 || len(body) < c.compressThreshold {
+		return body, false
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(body) / 2)
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return body, false
+	}
+	if err := writer.Close(); err != nil || buf.Len() >= len(body) {
+		return body, false
+	}
+	return buf.Bytes(), true
+}
+
+// decompressResponse replaces the body of a gzip or deflate encoded response
+// with one that decodes it, as the transport only does itself when it chose
+// the Accept-Encoding header
+func decompressResponse(resp *http.Response) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if resp.Body == nil || (encoding != "gzip" && encoding != "deflate") {
+		return
+	}
+
+	resp.Body = &decompressReader{body: resp.Body, encoding: encoding}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decompressReader decodes a response body. The decoder is created on the
+// first Read, so a streamed response is not waited on until it is read.
+type decompressReader struct {
+	body     io.ReadCloser
+	encoding string
+	reader   io.Reader
+	err      error
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if d.reader == nil && d.err == nil {
+		d.reader, d.err = newDecoder(d.body, d.encoding)
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.reader.Read(p)
+}
+
+func (d *decompressReader) Close() error {
+	if closer, ok := d.reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return d.body.Close()
+}
+
+// newDecoder returns a reader decoding r. Deflate bodies should be zlib
+// wrapped, but some servers send raw deflate, so both are accepted.
+func newDecoder(r io.Reader, encoding string) (io.Reader, error) {
+	if encoding == "gzip" {
+		return gzip.NewReader(r)
+	}
+
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// decompressRequestBody returns the decoded body of a gzip encoded request
+// for debug logging, or body itself if it is not encoded
+func decompressRequestBody(req *http.Request, body []byte) []byte {
+	if req.Header.Get("Content-Encoding") != "gzip" {
+		return body
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
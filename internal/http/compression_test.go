@@ -0,0 +1,172 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// encodedHTTPClient returns body encoded with encoding
+type encodedHTTPClient struct {
+	encoding string
+	body     string
+	accepted string
+}
+
+func (e *encodedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	e.accepted = req.Header.Get("Accept-Encoding")
+
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch e.encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&buf)
+	case "zlib":
+		writer = zlib.NewWriter(&buf)
+	default:
+		writer, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	writer.Write([]byte(e.body))
+	writer.Close()
+
+	header := make(http.Header)
+	header.Set("Content-Encoding", e.encoding)
+	if e.encoding == "zlib" {
+		header.Set("Content-Encoding", "deflate")
+	}
+	return &http.Response{
+		StatusCode:    200,
+		Body:          io.NopCloser(&buf),
+		Header:        header,
+		ContentLength: int64(buf.Len()),
+	}, nil
+}
+
+func TestResponseDecompression(t *testing.T) {
+	for _, encoding := range []string{"gzip", "zlib", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			httpClient := &encodedHTTPClient{encoding: encoding, body: `{"text":"Hello"}`}
+			client := NewClientWithHTTPClient(httpClient, time.Second, 0)
+
+			resp, err := client.Post(context.Background(), "http://example.com", nil, []byte(`{}`))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Expected no error decoding, got %v", err)
+			}
+			if string(data) != `{"text":"Hello"}` {
+				t.Errorf("Expected the decoded body, got %q", data)
+			}
+			if httpClient.accepted != "gzip, deflate" {
+				t.Errorf("Expected gzip and deflate to be accepted, got %q", httpClient.accepted)
+			}
+			if resp.Header.Get("Content-Encoding") != "" || resp.ContentLength != -1 {
+				t.Errorf("Expected the encoding headers removed, got %v with length %d", resp.Header, resp.ContentLength)
+			}
+		})
+	}
+}
+
+func TestResponseDecompression_CallerEncoding(t *testing.T) {
+	httpClient := &encodedHTTPClient{encoding: "gzip", body: "raw"}
+	client := NewClientWithHTTPClient(httpClient, time.Second, 0)
+
+	resp, err := client.Get(context.Background(), "http://example.com", map[string]string{"Accept-Encoding": "gzip"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	// A caller choosing the encoding decodes the body itself
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("Expected the body left encoded, got headers %v", resp.Header)
+	}
+}
+
+func TestRequestCompression(t *testing.T) {
+	large := `{"prompt":"` + strings.Repeat("lorem ipsum ", 1000) + `"}`
+	tests := []struct {
+		name       string
+		threshold  int
+		body       string
+		compressed bool
+	}{
+		{"disabled", 0, large, false},
+		{"below threshold", 1 << 20, large, false},
+		{"above threshold", 1024, large, true},
+		{"incompressible", 4, `{"a":1}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var reader io.Reader = r.Body
+				encoded := r.Header.Get("Content-Encoding") == "gzip"
+				if encoded {
+					gz, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Errorf("Expected a gzip body, got %v", err)
+						return
+					}
+					reader = gz
+				}
+				data, _ := io.ReadAll(reader)
+				if encoded != tt.compressed || string(data) != tt.body {
+					t.Errorf("Expected compressed=%v with the full body, got compressed=%v and %d bytes", tt.compressed, encoded, len(data))
+				}
+
+				// The server compresses its response too
+				w.Header().Set("Content-Encoding", "gzip")
+				gz := gzip.NewWriter(w)
+				gz.Write([]byte(`{"ok":true}`))
+				gz.Close()
+			}))
+			defer server.Close()
+
+			client := NewClient(time.Second, 0)
+			client.SetRequestCompression(tt.threshold)
+			resp, err := client.Post(context.Background(), server.URL, nil, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer resp.Body.Close()
+
+			data, _ := io.ReadAll(resp.Body)
+			if string(data) != `{"ok":true}` {
+				t.Errorf("Expected the decoded response, got %q", data)
+			}
+		})
+	}
+}
+
+func TestRequestCompression_DebugLogsDecodedBody(t *testing.T) {
+	var entries []types.DebugEntry
+	client := NewClientWithHTTPClient(&bodyHTTPClient{status: 200, body: "{}"}, 0, 0)
+	client.SetRequestCompression(16)
+	client.SetDebug(func(entry types.DebugEntry) { entries = append(entries, entry) }, nil, 100)
+
+	body := `{"prompt":"` + strings.Repeat("a", 100) + `"}`
+	resp, err := client.Post(context.Background(), "http://example.com", nil, []byte(body))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(entries) != 1 || entries[0].RequestBody != body {
+		t.Errorf("Expected the decoded request body to be logged, got %+v", entries)
+	}
+}
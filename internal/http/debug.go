@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+const (
+	// DefaultDebugBodyLimit is the number of response body bytes logged when
+	// no limit is configured
+	DefaultDebugBodyLimit = 2048
+
+	// redacted replaces credentials and redacted fields in debug entries
+	redacted = "[REDACTED]"
+)
+
+// debugLogger logs redacted HTTP exchanges
+type debugLogger struct {
+	log       func(types.DebugEntry)
+	fields    map[string]bool
+	bodyLimit int
+}
+
+// SetDebug enables logging of every HTTP exchange to logger, or to the
+// standard library logger if logger is nil. Credentials are always
+// redacted; redactFields lists additional JSON fields to redact from request
+// bodies. Response bodies are truncated to bodyLimit bytes
+// (DefaultDebugBodyLimit if zero).
+func (c *Client) SetDebug(logger func(types.DebugEntry), redactFields []string, bodyLimit int) {
+	if logger == nil {
+		logger = logDebugEntry
+	}
+	if bodyLimit <= 0 {
+		bodyLimit = DefaultDebugBodyLimit
+	}
+	fields := make(map[string]bool, len(redactFields))
+	for _, field := range redactFields {
+		fields[strings.ToLower(field)] = true
+	}
+	c.debug = &debugLogger{log: logger, fields: fields, bodyLimit: bodyLimit}
+}
+
+// SetReasoningFields lists JSON fields holding model reasoning. Their text
+// values are redacted from the request and response bodies of debug log
+// entries and captures, so a request keeps its reasoning settings but not
+// the reasoning itself.
+func (c *Client) SetReasoningFields(fields []string) {
+	c.reasoningFields = make(map[string]bool, len(fields))
+	for _, field := range fields {
+		c.reasoningFields[strings.ToLower(field)] = true
+	}
+}
+
+// logDebugEntry writes an entry as JSON to the standard library logger
+func logDebugEntry(entry types.DebugEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("aiprovider debug: %s %s: %v", entry.Method, entry.URL, err)
+		return
+	}
+	log.Printf("aiprovider debug: %s", data)
+}
+
+// logExchange logs one attempt of a request. The response body is read and
+// replaced with a copy, except for successful streamed responses.
+func (d *debugLogger) logExchange(req *http.Request, body []byte, attempt int, resp *http.Response, err error, duration time.Duration, reasoning map[string]bool) {
+	entry := newDebugEntry(req, body, attempt, resp, err, duration, d.bodyLimit, reasoning)
+	entry.RequestBody = d.redactBody([]byte(entry.RequestBody))
+	d.log(entry)
+}
+
+// newDebugEntry records one attempt of a request with credentials and the
+// text of reasoning fields redacted, truncating the response body to
+// bodyLimit bytes unless bodyLimit is 0. The response body is read and
+// replaced with a copy, except for successful streamed responses.
+func newDebugEntry(req *http.Request, body []byte, attempt int, resp *http.Response, err error, duration time.Duration, bodyLimit int, reasoning map[string]bool) types.DebugEntry {
+	entry := types.DebugEntry{
+		Method:      req.Method,
+		URL:         redactURL(req.URL),
+		Headers:     redactHeaders(req.Header),
+		RequestBody: string(redactText(decompressRequestBody(req, body), reasoning)),
+		Attempt:     attempt,
+		Duration:    duration,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if resp != nil {
+		entry.Status = resp.StatusCode
+		streamed := req.Header.Get("Accept") == "text/event-stream"
+		if resp.Body != nil && (!streamed || resp.StatusCode >= 400) {
+			data, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(data))
+			if readErr != nil {
+				entry.Error = readErr.Error()
+			}
+			// Redact before truncating, which leaves the body unparseable
+			data = redactText(data, reasoning)
+			if bodyLimit > 0 && len(data) > bodyLimit {
+				data = data[:bodyLimit]
+				entry.Truncated = true
+			}
+			entry.ResponseBody = string(data)
+		}
+	}
+	return entry
+}
+
+// Capture collects the HTTP exchanges of requests made with a context from
+// WithCapture, with credentials redacted and response bodies in full.
+type Capture struct {
+	mu      sync.Mutex
+	entries []types.DebugEntry
+}
+
+// captureKey is the context key for Capture
+type captureKey struct{}
+
+// WithCapture returns a context that records every attempt of requests made
+// with it, independently of SetDebug
+func WithCapture(ctx context.Context) (context.Context, *Capture) {
+	capture := &Capture{}
+	return context.WithValue(ctx, captureKey{}, capture), capture
+}
+
+// captureFromContext returns the capture in ctx, or nil
+func captureFromContext(ctx context.Context) *Capture {
+	capture, _ := ctx.Value(captureKey{}).(*Capture)
+	return capture
+}
+
+// Entries returns a copy of the exchanges recorded so far
+func (c *Capture) Entries() []types.DebugEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]types.DebugEntry(nil), c.entries...)
+}
+
+// add records one attempt of a request
+func (c *Capture) add(entry types.DebugEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, entry)
+}
+
+// redactBody returns the request body with the configured fields redacted.
+// Bodies are returned unchanged when no fields are configured or the body is
+// not JSON.
+func (d *debugLogger) redactBody(body []byte) string {
+	if len(d.fields) == 0 || len(body) == 0 {
+		return string(body)
+	}
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return string(body)
+	}
+	data, err := json.Marshal(d.redactValue(payload))
+	if err != nil {
+		return string(body)
+	}
+	return string(data)
+}
+
+// redactText returns a JSON body with the text values of fields redacted at
+// any depth. Bodies are returned unchanged when there is nothing to redact or
+// they are not JSON.
+func redactText(body []byte, fields map[string]bool) []byte {
+	if len(fields) == 0 || len(body) == 0 {
+		return body
+	}
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return body
+	}
+	if !redactTextValue(payload, fields) {
+		return body
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+// redactTextValue replaces the text values of fields, reporting whether any were found
+func redactTextValue(value interface{}, fields map[string]bool) bool {
+	found := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if text, ok := field.(string); ok && text != "" && fields[strings.ToLower(key)] {
+				v[key] = redacted
+				found = true
+			} else if redactTextValue(field, fields) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactTextValue(item, fields) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// redactValue replaces the values of configured fields at any depth
+func (d *debugLogger) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if d.fields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = d.redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = d.redactValue(item)
+		}
+	}
+	return value
+}
+
+// isCredential reports whether a header or query parameter name carries credentials
+func isCredential(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range []string{"auth", "key", "token", "secret", "cookie"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders flattens headers, redacting credentials
+func redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		if isCredential(name) {
+			headers[name] = redacted
+		} else {
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+	return headers
+}
+
+// redactURL returns the URL with credential query parameters and user info redacted
+func redactURL(u *url.URL) string {
+	clean := *u
+	if clean.User != nil {
+		clean.User = url.User(redacted)
+	}
+	if clean.RawQuery != "" {
+		query := clean.Query()
+		for name := range query {
+			if isCredential(name) {
+				query.Set(name, redacted)
+			}
+		}
+		clean.RawQuery = query.Encode()
+	}
+	return clean.String()
+}
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// bodyHTTPClient returns a fixed status and body
+type bodyHTTPClient struct {
+	status int
+	body   string
+}
+
+func (b *bodyHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: b.status,
+		Body:       io.NopCloser(strings.NewReader(b.body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestDebugLogging(t *testing.T) {
+	var entries []types.DebugEntry
+	client := NewClientWithHTTPClient(&bodyHTTPClient{status: 400, body: `{"error":{"message":"invalid model"}}`}, 0, 0)
+	client.SetDebug(func(entry types.DebugEntry) { entries = append(entries, entry) }, []string{"content"}, 10)
+
+	body := `{"model":"gpt-x","messages":[{"role":"user","content":"my secret prompt"}]}`
+	resp, err := client.Post(context.Background(), "https://api.example.com/v1/chat?key=abc123&alt=json", map[string]string{
+		"Authorization": "Bearer sk-secret",
+		"x-api-key":     "sk-ant-secret",
+		"X-Request-Id":  "req-1",
+	}, []byte(body))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The caller still reads the full response body
+	data, _ := io.ReadAll(resp.Body)
+	if string(data) != `{"error":{"message":"invalid model"}}` {
+		t.Errorf("Expected the response body to be preserved, got %q", data)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Status != 400 || entry.Attempt != 1 || entry.Method != "POST" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if entry.ResponseBody != `{"error":{` || !entry.Truncated {
+		t.Errorf("Expected the response body truncated to 10 bytes, got %q", entry.ResponseBody)
+	}
+	if entry.Headers["Authorization"] != redacted || entry.Headers["X-Api-Key"] != redacted || entry.Headers["X-Request-Id"] != "req-1" {
+		t.Errorf("Expected credential headers redacted, got %v", entry.Headers)
+	}
+	if strings.Contains(entry.URL, "abc123") || !strings.Contains(entry.URL, "alt=json") {
+		t.Errorf("Expected the key query parameter redacted, got %q", entry.URL)
+	}
+	if strings.Contains(entry.RequestBody, "my secret prompt") || !strings.Contains(entry.RequestBody, `"model":"gpt-x"`) {
+		t.Errorf("Expected only the content field redacted, got %s", entry.RequestBody)
+	}
+}
+
+func TestDebugLogging_StreamBodyNotRead(t *testing.T) {
+	var entries []types.DebugEntry
+	client := NewClientWithHTTPClient(&bodyHTTPClient{status: 200, body: "data: {}\n\n"}, 0, 0)
+	client.SetDebug(func(entry types.DebugEntry) { entries = append(entries, entry) }, nil, 0)
+
+	body := `{"stream":true}`
+	resp, err := client.PostStream(context.Background(), "https://api.example.com/v1/chat", nil, []byte(body))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ResponseBody != "" || entries[0].RequestBody != body {
+		t.Errorf("Expected the exact request and no streamed body, got %+v", entries)
+	}
+	if data, _ := io.ReadAll(resp.Body); string(data) != "data: {}\n\n" {
+		t.Errorf("Expected the stream untouched, got %q", data)
+	}
+}
+
+func TestDebugLogging_ReasoningFields(t *testing.T) {
+	responseBody := `{"content":[{"type":"thinking","thinking":"secret plan"},{"type":"text","text":"Hi"}]}`
+	var entries []types.DebugEntry
+	client := NewClientWithHTTPClient(&bodyHTTPClient{status: 200, body: responseBody}, 0, 0)
+	client.SetDebug(func(entry types.DebugEntry) { entries = append(entries, entry) }, nil, 60)
+	client.SetReasoningFields([]string{"thinking"})
+
+	ctx, capture := WithCapture(context.Background())
+	body := `{"thinking":{"type":"enabled","budget_tokens":1024},"messages":[{"role":"assistant","content":[{"type":"thinking","thinking":"old plan"}]}]}`
+	resp, err := client.Post(ctx, "https://api.example.com/v1/messages", nil, []byte(body))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, _ := io.ReadAll(resp.Body); string(data) != responseBody {
+		t.Errorf("Expected the response body to be preserved, got %q", data)
+	}
+
+	captured := capture.Entries()
+	if len(entries) != 1 || len(captured) != 1 {
+		t.Fatalf("Expected 1 logged and 1 captured entry, got %d and %d", len(entries), len(captured))
+	}
+	for _, entry := range []types.DebugEntry{entries[0], captured[0]} {
+		if strings.Contains(entry.RequestBody, "old plan") || !strings.Contains(entry.RequestBody, `"budget_tokens":1024`) {
+			t.Errorf("Expected only reasoning text redacted from the request, got %s", entry.RequestBody)
+		}
+		if strings.Contains(entry.ResponseBody, "secret") {
+			t.Errorf("Expected reasoning redacted from the response, got %s", entry.ResponseBody)
+		}
+	}
+	if !entries[0].Truncated || !strings.Contains(captured[0].ResponseBody, `"text":"Hi"`) {
+		t.Errorf("Expected the logged body truncated after redaction and the captured one whole, got %+v", captured[0])
+	}
+}
+
+func TestCapture(t *testing.T) {
+	errorBody := `{"error":{"message":"invalid model"}}`
+	client := NewClientWithHTTPClient(&bodyHTTPClient{status: 400, body: errorBody}, 0, 0)
+
+	ctx, capture := WithCapture(context.Background())
+	body := `{"model":"gpt-x"}`
+	resp, err := client.Post(ctx, "https://api.example.com/v1/chat", map[string]string{"Authorization": "Bearer sk-secret"}, []byte(body))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, _ := io.ReadAll(resp.Body); string(data) != errorBody {
+		t.Errorf("Expected the response body to be preserved, got %q", data)
+	}
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.RequestBody != body || entry.ResponseBody != errorBody || entry.Truncated || entry.Status != 400 {
+		t.Errorf("Expected the full exchange to be captured, got %+v", entry)
+	}
+	if entry.Headers["Authorization"] != redacted {
+		t.Errorf("Expected credentials redacted, got %v", entry.Headers)
+	}
+}
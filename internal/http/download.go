@@ -0,0 +1,48 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// Download is a binary response body read as a stream, such as generated
+// audio or an image
+type Download struct {
+	// Body streams the response body; the caller must close it
+	Body io.ReadCloser
+
+	// ContentType is the MIME type of the body, e.g. "audio/mpeg"
+	ContentType string
+
+	// ContentLength is the size of the body in bytes, or -1 if unknown
+	ContentLength int64
+}
+
+// NewDownload returns resp's body as a Download. The body is not read.
+func NewDownload(resp *http.Response) *Download {
+	return &Download{
+		Body:          resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	}
+}
+
+// PostDownload makes a POST request for a binary response with retry logic.
+//
+// Like PostStream, retries only cover the request up to the response headers,
+// and the configured timeout limits only the wait for them, so large bodies
+// can be read at the caller's pace. Pass successful responses to NewDownload;
+// error responses are returned as is for the caller to parse.
+func (c *Client) PostDownload(ctx context.Context, url string, headers map[string]string, body []byte) (*http.Response, error) {
+	req, body, err := c.newPostRequest(ctx, url, headers, body)
+	if err != nil {
+		return nil, err
+	}
+
+	streamClient := c.streamClient
+	if streamClient == nil {
+		streamClient = c.httpClient
+	}
+	return c.transformErrorBody(c.doWithRetry(streamClient, req, body))
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostDownload(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("Content-Length", "8")
+		w.Write([]byte("ID3"))
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	// The body takes longer than the timeout, which only covers the headers
+	client := NewClient(50*time.Millisecond, 0)
+	resp, err := client.PostDownload(context.Background(), server.URL, nil, []byte(`{"input":"Hi"}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	download := NewDownload(resp)
+	defer download.Body.Close()
+
+	if download.ContentType != "audio/mpeg" || download.ContentLength != 8 {
+		t.Errorf("Expected 8 bytes of audio/mpeg, got %d bytes of %q", download.ContentLength, download.ContentType)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	data, err := io.ReadAll(download.Body)
+	if err != nil {
+		t.Fatalf("Expected no error reading the body, got %v", err)
+	}
+	if string(data) != "ID3audio" {
+		t.Errorf("Expected the full body, got %q", data)
+	}
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// ApplyEndpoint returns the base URL and headers of a request made with ctx:
+// those of the endpoint selected with types.WithEndpoint if config allows it,
+// or else baseURL and headers unchanged. The headers map is copied before
+// the endpoint's headers are added, so adapters can share theirs.
+func ApplyEndpoint(ctx context.Context, config types.Config, baseURL string, headers map[string]string) (string, map[string]string, error) {
+	endpoint, ok := types.EndpointFromContext(ctx)
+	if !ok {
+		return baseURL, headers, nil
+	}
+	if err := config.CheckEndpoint(endpoint); err != nil {
+		return "", nil, err
+	}
+
+	if endpoint.BaseURL != "" {
+		baseURL = strings.TrimSuffix(endpoint.BaseURL, "/")
+	}
+	if len(endpoint.Headers) > 0 {
+		merged := make(map[string]string, len(headers)+len(endpoint.Headers))
+		for name, value := range headers {
+			merged[name] = value
+		}
+		for name, value := range endpoint.Headers {
+			merged[http.CanonicalHeaderKey(name)] = value
+		}
+		headers = merged
+	}
+	return baseURL, headers, nil
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// MultipartFile is a file part of a multipart/form-data upload
+type MultipartFile struct {
+	// FieldName is the form field the file is sent as, e.g. "file"
+	FieldName string
+
+	// FileName is the name reported for the file
+	FileName string
+
+	// ContentType is the MIME type of the file; defaults to
+	// application/octet-stream
+	ContentType string
+
+	// Data is the file content
+	Data []byte
+
+	// Reader streams the file content instead of Data, so large files are
+	// not held in memory. If it is also an io.Seeker, it is rewound to its
+	// starting offset when the request is retried.
+	Reader io.Reader
+}
+
+// PostMultipart makes a multipart/form-data POST request with retry logic,
+// as used for file uploads. fields are sent as form values, in key order,
+// before files.
+//
+// When every file uses Data, the body is encoded in memory before the first
+// attempt, so retries resend the same bytes and follow the same retry, timeout
+// and header handling as Post. When any file uses Reader, the body is encoded
+// while it is sent, with chunked transfer encoding; it is only retried if
+// every Reader is an io.Seeker. Any Content-Type in headers is replaced by the
+// multipart one.
+func (c *Client) PostMultipart(ctx context.Context, url string, headers map[string]string, fields map[string]string, files []MultipartFile) (*http.Response, error) {
+	if streamsFiles(files) {
+		return c.postMultipartStream(ctx, url, headers, fields, files)
+	}
+
+	var buf bytes.Buffer
+	contentType, err := encodeMultipart(&buf, "", fields, files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode multipart body: %w", err)
+	}
+	body := buf.Bytes()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	setHeaders(req.Header, headers)
+	req.Header.Set("Content-Type", contentType)
+
+	return c.transformResponseBody(c.doWithRetry(c.httpClient, req, body))
+}
+
+// postMultipartStream sends a multipart body encoded from the files' readers
+// through a pipe as the transport reads it
+func (c *Client) postMultipartStream(ctx context.Context, url string, headers map[string]string, fields map[string]string, files []MultipartFile) (*http.Response, error) {
+	// Every attempt must use the same boundary for the content type to match
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	readers := make([]io.Reader, len(files))
+	for i, file := range files {
+		readers[i] = file.Reader
+	}
+	streamBody(req, readers, func(w io.Writer) error {
+		_, err := encodeMultipart(w, boundary, fields, files)
+		return err
+	})
+
+	setHeaders(req.Header, headers)
+	req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+
+	return c.transformResponseBody(c.doWithRetry(c.httpClient, req, nil))
+}
+
+// streamsFiles reports whether any of files is read from a Reader
+func streamsFiles(files []MultipartFile) bool {
+	for _, file := range files {
+		if file.Reader != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeMultipart writes a multipart/form-data body to w, returning its
+// content type. An empty boundary is chosen at random.
+func encodeMultipart(w io.Writer, boundary string, fields map[string]string, files []MultipartFile) (string, error) {
+	writer := multipart.NewWriter(w)
+	if boundary != "" {
+		if err := writer.SetBoundary(boundary); err != nil {
+			return "", err
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := writer.WriteField(key, fields[key]); err != nil {
+			return "", err
+		}
+	}
+
+	for _, file := range files {
+		part, err := writer.CreatePart(fileHeader(file.FieldName, file.FileName, file.ContentType))
+		if err != nil {
+			return "", err
+		}
+		if file.Reader != nil {
+			_, err = io.Copy(part, file.Reader)
+		} else {
+			_, err = part.Write(file.Data)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return writer.FormDataContentType(), nil
+}
+
+// quoteEscaper escapes quotes and backslashes in Content-Disposition values
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// fileHeader returns the part header of a file, which unlike
+// multipart.Writer.CreateFormFile allows setting its content type
+func fileHeader(fieldName, fileName, contentType string) textproto.MIMEHeader {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(fieldName), quoteEscaper.Replace(fileName)))
+	header.Set("Content-Type", contentType)
+	return header
+}
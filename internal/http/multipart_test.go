@@ -0,0 +1,158 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostMultipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Expected a multipart body, got %v", err)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Expected the authorization header, got %q", got)
+		}
+		if got := r.FormValue("purpose"); got != "fine-tune" {
+			t.Errorf("Expected purpose fine-tune, got %q", got)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Expected a file part, got %v", err)
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		if header.Filename != `train "v1".jsonl` || string(data) != "{}\n" {
+			t.Errorf("Unexpected file %q: %q", header.Filename, data)
+		}
+		if got := header.Header.Get("Content-Type"); got != "application/jsonl" {
+			t.Errorf("Expected content type application/jsonl, got %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, 0)
+	resp, err := client.PostMultipart(context.Background(), server.URL,
+		map[string]string{"Authorization": "Bearer key", "Content-Type": "application/json"},
+		map[string]string{"purpose": "fine-tune"},
+		[]MultipartFile{{FieldName: "file", FileName: `train "v1".jsonl`, ContentType: "application/jsonl", Data: []byte("{}\n")}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestPostMultipart_RetryResendsBody(t *testing.T) {
+	httpClient := &sequenceHTTPClient{statuses: []int{503, 200}}
+	client := NewClientWithHTTPClient(httpClient, time.Second, 1)
+	client.jitter = fixedJitter(time.Millisecond)
+
+	resp, err := client.PostMultipart(context.Background(), "http://example.com", nil,
+		map[string]string{"purpose": "assistants"},
+		[]MultipartFile{{FieldName: "file", FileName: "notes.txt", Data: []byte("hello")}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	if len(httpClient.bodies) != 2 || httpClient.bodies[0] == "" || httpClient.bodies[0] != httpClient.bodies[1] {
+		t.Errorf("Expected the same body on both attempts, got %q", httpClient.bodies)
+	}
+}
+
+// onlyReader hides any io.Seeker implementation of the wrapped reader
+type onlyReader struct {
+	io.Reader
+}
+
+func TestPostMultipart_Stream(t *testing.T) {
+	content := strings.Repeat("audio", 100000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("Expected a chunked body, got length %d", r.ContentLength)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Expected a multipart body, got %v", err)
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Expected a file part, got %v", err)
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		if string(data) != content || r.FormValue("model") != "whisper-1" {
+			t.Errorf("Unexpected upload of %d bytes, model %q", len(data), r.FormValue("model"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, 0)
+	resp, err := client.PostMultipart(context.Background(), server.URL, nil,
+		map[string]string{"model": "whisper-1"},
+		[]MultipartFile{{FieldName: "file", FileName: "speech.mp3", Reader: onlyReader{strings.NewReader(content)}}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestPostMultipart_StreamRetry(t *testing.T) {
+	tests := []struct {
+		name             string
+		reader           io.Reader
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		{
+			name:             "seekable reader is rewound",
+			reader:           strings.NewReader("hello"),
+			expectedStatus:   200,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "non-seekable reader is not retried",
+			reader:           onlyReader{strings.NewReader("hello")},
+			expectedStatus:   503,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &sequenceHTTPClient{statuses: []int{503, 200}}
+			client := NewClientWithHTTPClient(httpClient, time.Second, 1)
+			client.jitter = fixedJitter(time.Millisecond)
+
+			resp, err := client.PostMultipart(context.Background(), "http://example.com", nil, nil,
+				[]MultipartFile{{FieldName: "file", FileName: "notes.txt", Reader: tt.reader}})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus || httpClient.calls != tt.expectedAttempts {
+				t.Errorf("Expected status %d after %d attempts, got %d after %d",
+					tt.expectedStatus, tt.expectedAttempts, resp.StatusCode, httpClient.calls)
+			}
+			for _, body := range httpClient.bodies {
+				if body != httpClient.bodies[0] || !strings.Contains(body, "hello") {
+					t.Errorf("Expected the full body on every attempt, got %q", httpClient.bodies)
+				}
+			}
+		})
+	}
+}
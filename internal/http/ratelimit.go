@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// ParseRateLimitHeaders extracts rate limit state from provider response headers.
+//
+// OpenAI reports limits via x-ratelimit-* headers with relative reset durations
+// (e.g. "6m0s"), while Anthropic uses anthropic-ratelimit-* headers with RFC 3339
+// reset timestamps. Both families are recognised. It returns nil when the
+// response carried no rate limit headers at all.
+func ParseRateLimitHeaders(headers http.Header, observedAt time.Time) *types.RateLimitStatus {
+	status := types.RateLimitStatus{ObservedAt: observedAt}
+	found := false
+
+	// Header names are in canonical form so http.Header.Get does not allocate
+
+	// OpenAI style headers
+	found = parseIntHeader(headers, "X-Ratelimit-Limit-Requests", &status.RequestsLimit) || found
+	found = parseIntHeader(headers, "X-Ratelimit-Remaining-Requests", &status.RequestsRemaining) || found
+	found = parseIntHeader(headers, "X-Ratelimit-Limit-Tokens", &status.TokensLimit) || found
+	found = parseIntHeader(headers, "X-Ratelimit-Remaining-Tokens", &status.TokensRemaining) || found
+	found = parseResetHeader(headers, "X-Ratelimit-Reset-Requests", observedAt, &status.RequestsReset) || found
+	found = parseResetHeader(headers, "X-Ratelimit-Reset-Tokens", observedAt, &status.TokensReset) || found
+
+	// Anthropic style headers
+	found = parseIntHeader(headers, "Anthropic-Ratelimit-Requests-Limit", &status.RequestsLimit) || found
+	found = parseIntHeader(headers, "Anthropic-Ratelimit-Requests-Remaining", &status.RequestsRemaining) || found
+	found = parseIntHeader(headers, "Anthropic-Ratelimit-Tokens-Limit", &status.TokensLimit) || found
+	found = parseIntHeader(headers, "Anthropic-Ratelimit-Tokens-Remaining", &status.TokensRemaining) || found
+	found = parseResetHeader(headers, "Anthropic-Ratelimit-Requests-Reset", observedAt, &status.RequestsReset) || found
+	found = parseResetHeader(headers, "Anthropic-Ratelimit-Tokens-Reset", observedAt, &status.TokensReset) || found
+	found = parseIntHeader(headers, "Anthropic-Ratelimit-Input-Tokens-Limit", &status.InputTokensLimit) || found
+	found = parseIntHeader(headers, "Anthropic-Ratelimit-Input-Tokens-Remaining", &status.InputTokensRemaining) || found
+	found = parseIntHeader(headers, "Anthropic-Ratelimit-Output-Tokens-Limit", &status.OutputTokensLimit) || found
+	found = parseIntHeader(headers, "Anthropic-Ratelimit-Output-Tokens-Remaining", &status.OutputTokensRemaining) || found
+
+	if retryAfter := ParseRetryAfter(headers.Get("Retry-After"), observedAt); retryAfter > 0 {
+		status.RetryAfter = retryAfter
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+	result := status
+	return &result
+}
+
+// ParseRetryAfter parses a Retry-After header value given either as a number
+// of seconds or as an HTTP date. It returns zero if the value is empty or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+// parseIntHeader parses an integer header into dst, reporting whether it was present
+func parseIntHeader(headers http.Header, name string, dst *int) bool {
+	value := strings.TrimSpace(headers.Get(name))
+	if value == "" {
+		return false
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return false
+	}
+
+	*dst = n
+	return true
+}
+
+// parseResetHeader parses a reset header into an absolute time, accepting both
+// Go-style durations ("1m30s", "250ms") and RFC 3339 timestamps
+func parseResetHeader(headers http.Header, name string, now time.Time, dst *time.Time) bool {
+	value := strings.TrimSpace(headers.Get(name))
+	if value == "" {
+		return false
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		*dst = now.Add(d)
+		return true
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		*dst = t
+		return true
+	}
+
+	return false
+}
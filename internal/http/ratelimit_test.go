@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("openai headers", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("x-ratelimit-limit-requests", "500")
+		headers.Set("x-ratelimit-remaining-requests", "499")
+		headers.Set("x-ratelimit-reset-requests", "120ms")
+		headers.Set("x-ratelimit-limit-tokens", "30000")
+		headers.Set("x-ratelimit-remaining-tokens", "29000")
+		headers.Set("x-ratelimit-reset-tokens", "6m0s")
+
+		status := ParseRateLimitHeaders(headers, now)
+		if status == nil {
+			t.Fatalf("Expected status, got nil")
+		}
+		if status.RequestsLimit != 500 || status.RequestsRemaining != 499 {
+			t.Errorf("Unexpected request limits: %+v", status)
+		}
+		if status.TokensLimit != 30000 || status.TokensRemaining != 29000 {
+			t.Errorf("Unexpected token limits: %+v", status)
+		}
+		if !status.RequestsReset.Equal(now.Add(120 * time.Millisecond)) {
+			t.Errorf("Unexpected requests reset: %v", status.RequestsReset)
+		}
+		if !status.TokensReset.Equal(now.Add(6 * time.Minute)) {
+			t.Errorf("Unexpected tokens reset: %v", status.TokensReset)
+		}
+	})
+
+	t.Run("anthropic headers", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("anthropic-ratelimit-requests-limit", "50")
+		headers.Set("anthropic-ratelimit-requests-remaining", "0")
+		headers.Set("anthropic-ratelimit-requests-reset", "2024-01-01T12:00:30Z")
+		headers.Set("anthropic-ratelimit-input-tokens-limit", "40000")
+		headers.Set("anthropic-ratelimit-input-tokens-remaining", "39000")
+		headers.Set("anthropic-ratelimit-output-tokens-limit", "8000")
+		headers.Set("anthropic-ratelimit-output-tokens-remaining", "7900")
+		headers.Set("retry-after", "30")
+
+		status := ParseRateLimitHeaders(headers, now)
+		if status == nil {
+			t.Fatalf("Expected status, got nil")
+		}
+		if status.InputTokensLimit != 40000 || status.InputTokensRemaining != 39000 || status.OutputTokensLimit != 8000 || status.OutputTokensRemaining != 7900 {
+			t.Errorf("Unexpected input and output token limits: %+v", status)
+		}
+		if status.RequestsLimit != 50 || status.RequestsRemaining != 0 {
+			t.Errorf("Unexpected request limits: %+v", status)
+		}
+		if !status.RequestsReset.Equal(now.Add(30 * time.Second)) {
+			t.Errorf("Unexpected requests reset: %v", status.RequestsReset)
+		}
+		if status.RetryAfter != 30*time.Second {
+			t.Errorf("Expected retry after 30s, got %v", status.RetryAfter)
+		}
+	})
+
+	t.Run("no headers", func(t *testing.T) {
+		if status := ParseRateLimitHeaders(http.Header{}, now); status != nil {
+			t.Errorf("Expected nil status, got %+v", status)
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"10", 10 * time.Second},
+		{"1.5", 1500 * time.Millisecond},
+		{"-3", 0},
+		{"Mon, 01 Jan 2024 12:01:00 GMT", time.Minute},
+		{"invalid", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value, now); got != tt.expected {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStreamIdleTimeout is the stream inactivity timeout used when none is configured
+const DefaultStreamIdleTimeout = 60 * time.Second
+
+// ErrStreamStalled is returned by SSEReader.Next when no data arrives within
+// the idle timeout
+var ErrStreamStalled = errors.New("stream stalled: no data received within idle timeout")
+
+// Event is a single server-sent event.
+type Event struct {
+	// Type is the event name from the "event:" field (empty for unnamed events)
+	Type string
+
+	// Data is the event payload, with multiple "data:" lines joined by newlines
+	Data string
+}
+
+// SSEReader reads server-sent events from a response body.
+//
+// Every line received, including keep-alive comments, resets the idle timer.
+// If the timer expires while Next is waiting for data, the body is closed
+// and Next returns ErrStreamStalled instead of blocking forever. The timer
+// only runs while Next is reading, so a slow consumer never causes a stall.
+type SSEReader struct {
+	body        io.ReadCloser
+	reader      *bufio.Reader
+	idleTimeout time.Duration
+	transform   func(data []byte) ([]byte, error)
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stalled bool
+	closed  bool
+}
+
+// NewSSEReader creates a reader for body. An idleTimeout of zero disables
+// stall detection.
+func NewSSEReader(body io.ReadCloser, idleTimeout time.Duration) *SSEReader {
+	return &SSEReader{
+		body:        body,
+		reader:      bufio.NewReader(body),
+		idleTimeout: idleTimeout,
+	}
+}
+
+// Next returns the next event. It returns io.EOF when the stream ends and
+// ErrStreamStalled when the idle timeout expires.
+func (r *SSEReader) Next() (Event, error) {
+	var event Event
+	var data []string
+	hasData := false
+
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			if err == io.EOF && hasData {
+				// Dispatch a final event not terminated by a blank line
+				return r.dispatch(event, data)
+			}
+			return Event{}, err
+		}
+
+		switch {
+		case line == "":
+			if hasData {
+				return r.dispatch(event, data)
+			}
+			event = Event{}
+		case strings.HasPrefix(line, ":"):
+			// Comment, typically a keep-alive
+		default:
+			field, value := line, ""
+			if i := strings.IndexByte(line, ':'); i >= 0 {
+				field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+			}
+			switch field {
+			case "event":
+				event.Type = value
+			case "data":
+				data = append(data, value)
+				hasData = true
+			}
+		}
+	}
+}
+
+// dispatch completes event with its data lines, applying the transformer if any
+func (r *SSEReader) dispatch(event Event, data []string) (Event, error) {
+	event.Data = strings.Join(data, "\n")
+	if r.transform == nil {
+		return event, nil
+	}
+	transformed, err := r.transform([]byte(event.Data))
+	if err != nil {
+		return Event{}, fmt.Errorf("response transformer failed: %w", err)
+	}
+	event.Data = string(transformed)
+	return event, nil
+}
+
+// Close closes the underlying body
+func (r *SSEReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	return r.body.Close()
+}
+
+// readLine reads one line without its line ending, guarded by the idle timer
+func (r *SSEReader) readLine() (string, error) {
+	r.startTimer()
+	line, err := r.reader.ReadString('\n')
+	stalled := r.stopTimer()
+
+	if stalled {
+		return "", ErrStreamStalled
+	}
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// startTimer arms the idle timer before a blocking read
+func (r *SSEReader) startTimer() {
+	if r.idleTimeout <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timer == nil {
+		r.timer = time.AfterFunc(r.idleTimeout, r.stall)
+	} else {
+		r.timer.Reset(r.idleTimeout)
+	}
+}
+
+// stopTimer disarms the idle timer and reports whether it fired
+func (r *SSEReader) stopTimer() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	return r.stalled
+}
+
+// stall closes the body to unblock a pending read
+func (r *SSEReader) stall() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+	r.stalled = true
+	r.closed = true
+	r.body.Close()
+}
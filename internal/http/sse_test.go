@@ -0,0 +1,99 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/testutil"
+)
+
+func TestSSEReader(t *testing.T) {
+	body := "event: greeting\ndata: hello\ndata: world\n\n" +
+		": keep-alive\n\n" +
+		"data: {\"n\": 1}\r\n\r\n" +
+		"data: unterminated"
+
+	reader := NewSSEReader(io.NopCloser(strings.NewReader(body)), time.Second)
+	defer reader.Close()
+
+	expected := []Event{
+		{Type: "greeting", Data: "hello\nworld"},
+		{Data: `{"n": 1}`},
+		{Data: "unterminated"},
+	}
+	for i, want := range expected {
+		event, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Event %d: unexpected error: %v", i, err)
+		}
+		if event != want {
+			t.Errorf("Event %d: expected %+v, got %+v", i, want, event)
+		}
+	}
+
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestSSEReader_Stall(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+	reader := NewSSEReader(pipeReader, 50*time.Millisecond)
+	defer reader.Close()
+
+	go func() {
+		// Keep-alives within the timeout keep the stream open
+		for i := 0; i < 3; i++ {
+			pipeWriter.Write([]byte(": ping\n"))
+			time.Sleep(20 * time.Millisecond)
+		}
+		pipeWriter.Write([]byte("data: first\n\n"))
+	}()
+
+	event, err := reader.Next()
+	if err != nil || event.Data != "first" {
+		t.Fatalf("Expected first event, got %+v, %v", event, err)
+	}
+
+	// A slow consumer does not count as a stall
+	time.Sleep(100 * time.Millisecond)
+	go pipeWriter.Write([]byte("data: second\n\n"))
+	event, err = reader.Next()
+	if err != nil || event.Data != "second" {
+		t.Fatalf("Expected second event, got %+v, %v", event, err)
+	}
+
+	start := time.Now()
+	if _, err := reader.Next(); !errors.Is(err, ErrStreamStalled) {
+		t.Fatalf("Expected ErrStreamStalled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Stall detected after %v, expected about 50ms", elapsed)
+	}
+}
+
+func FuzzSSEReader(f *testing.F) {
+	testutil.AddStreamSeeds(f, testutil.AnthropicStreamSeeds)
+	f.Fuzz(func(t *testing.T, body string) {
+		reader := NewSSEReader(io.NopCloser(strings.NewReader(body)), 0)
+		defer reader.Close()
+
+		// Every event consumes at least one line, so the stream ends
+		for i := 0; i <= len(body); i++ {
+			event, err := reader.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.Contains(body, strings.SplitN(event.Data, "\n", 2)[0]) {
+				t.Errorf("Event data %q not found in the body", event.Data)
+			}
+		}
+		t.Errorf("Expected the stream to end")
+	})
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// InputPlaceholder returns the string a JSON body passed to PostInputs holds
+// in place of the content of its index-th reader. It may appear anywhere in
+// a JSON string, alone or surrounded by other text.
+func InputPlaceholder(index int) string {
+	return "\x00aiprovider-input-" + strconv.Itoa(index) + "\x00"
+}
+
+// PostInputs makes a POST request like Post, with the content of readers
+// streamed into the JSON body in place of their InputPlaceholders.
+//
+// The body is encoded while it is sent, with chunked transfer encoding, so
+// large inputs are never held in memory. The request transformer sees the
+// body with the placeholders still in it and must keep them. The body is not
+// compressed, and it is only retried if every reader is an io.Seeker, which
+// is rewound to its starting offset.
+func (c *Client) PostInputs(ctx context.Context, url string, headers map[string]string, body []byte, readers []io.Reader) (*http.Response, error) {
+	req, err := c.newInputsRequest(ctx, url, headers, body, readers)
+	if err != nil {
+		return nil, err
+	}
+	return c.transformResponseBody(c.doWithRetry(c.httpClient, req, nil))
+}
+
+// PostStreamInputs makes a POST request for a streamed response like
+// PostStream, with its body encoded as by PostInputs
+func (c *Client) PostStreamInputs(ctx context.Context, url string, headers map[string]string, body []byte, readers []io.Reader) (*http.Response, error) {
+	req, err := c.newInputsRequest(ctx, url, headers, body, readers)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	streamClient := c.streamClient
+	if streamClient == nil {
+		streamClient = c.httpClient
+	}
+	return c.transformErrorBody(c.doWithRetry(streamClient, req, nil))
+}
+
+// newInputsRequest creates a POST request whose JSON body, after the request
+// transformer, streams the content of readers in place of their placeholders
+func (c *Client) newInputsRequest(ctx context.Context, url string, headers map[string]string, body []byte, readers []io.Reader) (*http.Request, error) {
+	body, err := c.transformRequestBody(body)
+	if err != nil {
+		return nil, err
+	}
+	segments, order, err := splitInputs(body, len(readers))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	streamBody(req, readers, func(pipe io.Writer) error {
+		// Readers may return little at a time; batch it into larger chunks
+		w := bufio.NewWriterSize(pipe, 32*1024)
+		for i, input := range order {
+			if _, err := w.Write(segments[i]); err != nil {
+				return err
+			}
+			if err := copyJSONString(w, readers[input]); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(segments[len(order)]); err != nil {
+			return err
+		}
+		return w.Flush()
+	})
+
+	setHeaders(req.Header, headers)
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// splitInputs splits a JSON body at the placeholders of n readers, which
+// may appear in any order. It returns the n+1 segments around them and the
+// index of the reader of each placeholder, in body order.
+func splitInputs(body []byte, n int) ([][]byte, []int, error) {
+	offsets := make([]int, n)
+	order := make([]int, n)
+	for i := range offsets {
+		// json.Marshal escapes the placeholder's NUL bytes
+		offsets[i] = bytes.Index(body, jsonPlaceholder(i))
+		if offsets[i] < 0 {
+			return nil, nil, fmt.Errorf("request body has no placeholder for input %d", i)
+		}
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return offsets[order[a]] < offsets[order[b]] })
+
+	segments := make([][]byte, 0, n+1)
+	start := 0
+	for _, input := range order {
+		segments = append(segments, body[start:offsets[input]])
+		start = offsets[input] + len(jsonPlaceholder(input))
+	}
+	return append(segments, body[start:]), order, nil
+}
+
+// jsonPlaceholder is an InputPlaceholder as encoded by json.Marshal
+func jsonPlaceholder(index int) []byte {
+	return []byte(`\u0000aiprovider-input-` + strconv.Itoa(index) + `\u0000`)
+}
+
+// streamBody sets the body of req to the output of encode, written through
+// a pipe as the transport reads it. If every reader is an io.Seeker, retries
+// rewind them and encode the body again; otherwise the request is sent once.
+func streamBody(req *http.Request, readers []io.Reader, encode func(w io.Writer) error) {
+	var reader *io.PipeReader
+	var done chan struct{}
+	open := func() io.ReadCloser {
+		var writer *io.PipeWriter
+		reader, writer = io.Pipe()
+		done = make(chan struct{})
+		go func(done chan struct{}) {
+			defer close(done)
+			writer.CloseWithError(encode(writer))
+		}(done)
+		return reader
+	}
+
+	// Offsets are taken before encoding starts reading
+	offsets, seekable := seekOffsets(readers)
+	req.Body = open()
+	req.ContentLength = -1
+
+	if seekable {
+		req.GetBody = func() (io.ReadCloser, error) {
+			// The transport may still be sending the previous attempt, so
+			// stop it before rewinding the readers it uses
+			reader.Close()
+			<-done
+			for i, r := range readers {
+				if r == nil {
+					continue
+				}
+				if _, err := r.(io.Seeker).Seek(offsets[i], io.SeekStart); err != nil {
+					return nil, err
+				}
+			}
+			return open(), nil
+		}
+	}
+}
+
+// seekOffsets returns the current offset of each reader, reporting false if
+// any cannot seek. Nil readers are skipped.
+func seekOffsets(readers []io.Reader) ([]int64, bool) {
+	offsets := make([]int64, len(readers))
+	for i, r := range readers {
+		if r == nil {
+			continue
+		}
+		seeker, ok := r.(io.Seeker)
+		if !ok {
+			return nil, false
+		}
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, false
+		}
+		offsets[i] = offset
+	}
+	return offsets, true
+}
+
+// hexDigits are the digits of \u escapes
+const hexDigits = "0123456789abcdef"
+
+// copyJSONString writes the content of r to w escaped as the inside of a
+// JSON string, replacing invalid UTF-8 with U+FFFD as json.Marshal does
+func copyJSONString(w io.Writer, r io.Reader) error {
+	buf := make([]byte, 32*1024)
+	out := make([]byte, 0, 2*len(buf))
+	pending := 0 // Bytes of an incomplete rune carried over from the last read
+	for {
+		n, readErr := r.Read(buf[pending:])
+		data := buf[:pending+n]
+		eof := readErr == io.EOF
+
+		out = out[:0]
+		i := 0
+		for i < len(data) {
+			c := data[i]
+			if c < utf8.RuneSelf {
+				switch {
+				case c == '"' || c == '\\':
+					out = append(out, '\\', c)
+				case c == '\n':
+					out = append(out, '\\', 'n')
+				case c == '\r':
+					out = append(out, '\\', 'r')
+				case c == '\t':
+					out = append(out, '\\', 't')
+				case c < 0x20:
+					out = append(out, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+				default:
+					out = append(out, c)
+				}
+				i++
+				continue
+			}
+			if !eof && !utf8.FullRune(data[i:]) {
+				break
+			}
+			char, size := utf8.DecodeRune(data[i:])
+			if char == utf8.RuneError && size == 1 {
+				out = append(out, `\ufffd`...)
+			} else {
+				out = append(out, data[i:i+size]...)
+			}
+			i += size
+		}
+		pending = copy(buf, data[i:])
+
+		if _, err := w.Write(out); err != nil {
+			return err
+		}
+		if eof {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read request input: %w", readErr)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestPostInputs(t *testing.T) {
+	document := strings.Repeat("Quarterly report, \"Q3\"\n\tnet: 5€ ", 2000)
+	var received struct {
+		System   string `json:"system"`
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("Expected a chunked body, got length %d", r.ContentLength)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Expected a JSON content type, got %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Expected a valid JSON body, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	// Placeholders need not appear in reader order
+	body, _ := json.Marshal(map[string]interface{}{
+		"messages": []map[string]string{{"content": "Summarize: " + InputPlaceholder(0)}},
+		"system":   InputPlaceholder(1),
+	})
+	client := NewClient(time.Second, 0)
+	resp, err := client.PostInputs(context.Background(), server.URL, nil, body, []io.Reader{
+		iotest.OneByteReader(strings.NewReader(document)), // Splits runes across reads
+		strings.NewReader("Be brief.\x01\xff"),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	if len(received.Messages) != 1 || received.Messages[0].Content != "Summarize: "+document {
+		t.Errorf("Expected the streamed document, got %d messages", len(received.Messages))
+	}
+	if received.System != "Be brief.\x01�" {
+		t.Errorf("Expected control characters escaped and invalid UTF-8 replaced, got %q", received.System)
+	}
+
+	if _, err := client.PostInputs(context.Background(), server.URL, nil, []byte(`{}`), []io.Reader{strings.NewReader("x")}); err == nil {
+		t.Error("Expected an error for a body without the placeholder")
+	}
+}
+
+func TestPostInputs_Retry(t *testing.T) {
+	tests := []struct {
+		name             string
+		reader           io.Reader
+		expectedAttempts int
+	}{
+		{"seekable reader is rewound", strings.NewReader("hello"), 2},
+		{"non-seekable reader is not retried", onlyReader{strings.NewReader("hello")}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &sequenceHTTPClient{statuses: []int{503, 200}}
+			client := NewClientWithHTTPClient(httpClient, time.Second, 1)
+			client.jitter = fixedJitter(time.Millisecond)
+
+			body, _ := json.Marshal(map[string]string{"content": InputPlaceholder(0)})
+			resp, err := client.PostStreamInputs(context.Background(), "http://example.com", nil, body, []io.Reader{tt.reader})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			resp.Body.Close()
+
+			if httpClient.calls != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.expectedAttempts, httpClient.calls)
+			}
+			for _, sent := range httpClient.bodies {
+				if sent != `{"content":"hello"}` {
+					t.Errorf("Expected the full body on every attempt, got %q", sent)
+				}
+			}
+		})
+	}
+}
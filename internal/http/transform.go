@@ -0,0 +1,78 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SetRequestTransformer sets a function that rewrites every JSON request
+// body before it is compressed and sent, including the bodies of PostInputs
+// before their inputs are streamed in. An error from transform aborts the
+// request. Multipart bodies are not transformed.
+func (c *Client) SetRequestTransformer(transform func(body []byte) ([]byte, error)) {
+	c.transformRequest = transform
+}
+
+// SetResponseTransformer sets a function that rewrites raw response bodies
+// before the caller parses them. It receives the decompressed body of every
+// response, except that successful streamed and downloaded responses are
+// left as is; the data of each event read by an SSEReader from NewSSEReader
+// is transformed instead. An error from transform fails the request.
+func (c *Client) SetResponseTransformer(transform func(body []byte) ([]byte, error)) {
+	c.transformResponse = transform
+}
+
+// NewSSEReader creates an SSEReader for body that applies the response
+// transformer, if any, to the data of each event
+func (c *Client) NewSSEReader(body io.ReadCloser, idleTimeout time.Duration) *SSEReader {
+	reader := NewSSEReader(body, idleTimeout)
+	reader.transform = c.transformResponse
+	return reader
+}
+
+// transformRequestBody applies the request transformer, if any, to body
+func (c *Client) transformRequestBody(body []byte) ([]byte, error) {
+	if c.transformRequest == nil {
+		return body, nil
+	}
+	body, err := c.transformRequest(body)
+	if err != nil {
+		return nil, fmt.Errorf("request transformer failed: %w", err)
+	}
+	return body, nil
+}
+
+// transformResponseBody replaces the body of resp with its transformed
+// version. It takes and returns the results of doWithRetry.
+func (c *Client) transformResponseBody(resp *http.Response, err error) (*http.Response, error) {
+	if err != nil || c.transformResponse == nil || resp.Body == nil {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	body, err = c.transformResponse(body)
+	if err != nil {
+		return nil, fmt.Errorf("response transformer failed: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
+
+// transformErrorBody is transformResponseBody for streamed and downloaded
+// responses, whose body is only transformed if the request failed
+func (c *Client) transformErrorBody(resp *http.Response, err error) (*http.Response, error) {
+	if err != nil || resp.StatusCode < 400 {
+		return resp, err
+	}
+	return c.transformResponseBody(resp, err)
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestTransformer(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, 0)
+	client.SetRequestTransformer(func(body []byte) ([]byte, error) {
+		return bytes.Replace(body, []byte(`}`), []byte(`,"extra":true}`), 1), nil
+	})
+
+	resp, err := client.Post(context.Background(), server.URL, nil, []byte(`{"model":"m"}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if string(received) != `{"model":"m","extra":true}` {
+		t.Errorf("Expected transformed body, got %s", received)
+	}
+}
+
+func TestRequestTransformer_Inputs(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, 0)
+	client.SetRequestTransformer(func(body []byte) ([]byte, error) {
+		return bytes.Replace(body, []byte(`}`), []byte(`,"extra":true}`), 1), nil
+	})
+
+	body := []byte(`{"content":"` + string(jsonPlaceholder(0)) + `"}`)
+	resp, err := client.PostInputs(context.Background(), server.URL, nil, body, []io.Reader{strings.NewReader("streamed")})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if string(received) != `{"content":"streamed","extra":true}` {
+		t.Errorf("Expected transformed body with the input streamed in, got %s", received)
+	}
+}
+
+func TestRequestTransformer_Error(t *testing.T) {
+	httpClient := &sequenceHTTPClient{statuses: []int{200}}
+	client := NewClientWithHTTPClient(httpClient, time.Second, 0)
+	boom := errors.New("boom")
+	client.SetRequestTransformer(func(body []byte) ([]byte, error) {
+		return nil, boom
+	})
+
+	_, err := client.PostStream(context.Background(), "http://example.com", nil, []byte(`{}`))
+	if !errors.Is(err, boom) {
+		t.Fatalf("Expected transformer error, got %v", err)
+	}
+	if httpClient.calls != 0 {
+		t.Errorf("Expected no request to be sent, got %d", httpClient.calls)
+	}
+}
+
+func TestResponseTransformer(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"data":{"text":"hi"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, 0)
+	client.SetResponseTransformer(func(body []byte) ([]byte, error) {
+		return bytes.TrimSuffix(bytes.TrimPrefix(body, []byte(`{"data":`)), []byte(`}`)), nil
+	})
+
+	read := func(resp *http.Response, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if got := read(client.Post(context.Background(), server.URL, nil, []byte(`{}`))); got != `{"text":"hi"}` {
+		t.Errorf("Expected unwrapped Post body, got %s", got)
+	}
+	if got := read(client.Get(context.Background(), server.URL, nil)); got != `{"text":"hi"}` {
+		t.Errorf("Expected unwrapped Get body, got %s", got)
+	}
+
+	// Successful streams are transformed per event, not as a whole
+	if got := read(client.PostStream(context.Background(), server.URL, nil, []byte(`{}`))); got != `{"data":{"text":"hi"}}` {
+		t.Errorf("Expected untouched stream body, got %s", got)
+	}
+	status = http.StatusBadRequest
+	if got := read(client.PostStream(context.Background(), server.URL, nil, []byte(`{}`))); got != `{"text":"hi"}` {
+		t.Errorf("Expected unwrapped stream error body, got %s", got)
+	}
+}
+
+func TestResponseTransformer_Error(t *testing.T) {
+	client := NewClientWithHTTPClient(&sequenceHTTPClient{statuses: []int{200}}, time.Second, 0)
+	boom := errors.New("boom")
+	client.SetResponseTransformer(func(body []byte) ([]byte, error) {
+		return nil, boom
+	})
+
+	if _, err := client.Post(context.Background(), "http://example.com", nil, []byte(`{}`)); !errors.Is(err, boom) {
+		t.Errorf("Expected transformer error, got %v", err)
+	}
+}
+
+func TestSSEReader_Transform(t *testing.T) {
+	client := NewClient(time.Second, 0)
+	client.SetResponseTransformer(func(data []byte) ([]byte, error) {
+		if string(data) == "bad" {
+			return nil, errors.New("boom")
+		}
+		return bytes.ToUpper(data), nil
+	})
+
+	reader := client.NewSSEReader(io.NopCloser(strings.NewReader("data: hello\n\ndata: bad\n\n")), 0)
+	event, err := reader.Next()
+	if err != nil || event.Data != "HELLO" {
+		t.Errorf("Expected transformed event, got %q, %v", event.Data, err)
+	}
+	if _, err := reader.Next(); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected transformer error, got %v", err)
+	}
+
+	// Readers created without the client are not transformed
+	plain := NewSSEReader(io.NopCloser(strings.NewReader("data: hello\n\n")), 0)
+	if event, _ := plain.Next(); event.Data != "hello" {
+		t.Errorf("Expected untransformed event, got %q", event.Data)
+	}
+}
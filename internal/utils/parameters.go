@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// FindUnsupportedCompletion returns the parameters of a completion request
+// that the provider does not support. The request is not modified; use
+// DropUnsupportedCompletion to remove them.
+func FindUnsupportedCompletion(req types.CompletionRequest, provider ProviderType) []types.UnsupportedParameter {
+	var unsupported []types.UnsupportedParameter
+	if maxStop := GetProviderMaxStopSequences(provider); len(req.Stop) > maxStop {
+		unsupported = append(unsupported, types.UnsupportedParameter{
+			Provider:  provider,
+			Parameter: "stop",
+			Reason:    fmt.Sprintf("at most %d stop sequences are supported, got %d", maxStop, len(req.Stop)),
+		})
+	}
+	if len(req.LogitBias) > 0 && !ProviderSupportsLogitBias(provider) {
+		unsupported = append(unsupported, logitBiasUnsupported(provider))
+	}
+	if req.Stream {
+		unsupported = append(unsupported, streamUnsupported(provider))
+	}
+	return unsupported
+}
+
+// FindUnsupportedChat returns the parameters of a chat request that the
+// provider does not support. The request is not modified; use
+// DropUnsupportedChat to remove them.
+func FindUnsupportedChat(req types.ChatRequest, provider ProviderType) []types.UnsupportedParameter {
+	var unsupported []types.UnsupportedParameter
+	if len(req.LogitBias) > 0 && !ProviderSupportsLogitBias(provider) {
+		unsupported = append(unsupported, logitBiasUnsupported(provider))
+	}
+	if req.Stream {
+		unsupported = append(unsupported, streamUnsupported(provider))
+	}
+	return unsupported
+}
+
+// DropUnsupportedCompletion returns a copy of the request with the
+// parameters reported by FindUnsupportedCompletion removed or truncated
+func DropUnsupportedCompletion(req types.CompletionRequest, provider ProviderType) types.CompletionRequest {
+	if maxStop := GetProviderMaxStopSequences(provider); len(req.Stop) > maxStop {
+		req.Stop = req.Stop[:maxStop]
+	}
+	if !ProviderSupportsLogitBias(provider) {
+		req.LogitBias = nil
+	}
+	req.Stream = false
+	return req
+}
+
+// DropUnsupportedChat returns a copy of the request with the parameters
+// reported by FindUnsupportedChat removed
+func DropUnsupportedChat(req types.ChatRequest, provider ProviderType) types.ChatRequest {
+	if !ProviderSupportsLogitBias(provider) {
+		req.LogitBias = nil
+	}
+	req.Stream = false
+	return req
+}
+
+// streamUnsupported reports that Complete and ChatComplete return whole responses
+func streamUnsupported(provider ProviderType) types.UnsupportedParameter {
+	return types.UnsupportedParameter{
+		Provider:  provider,
+		Parameter: "stream",
+		Reason:    "streaming responses are not supported by Complete and ChatComplete",
+	}
+}
+
+// logitBiasUnsupported reports that the provider cannot bias token likelihoods
+func logitBiasUnsupported(provider ProviderType) types.UnsupportedParameter {
+	return types.UnsupportedParameter{
+		Provider:  provider,
+		Parameter: "logit_bias",
+		Reason:    fmt.Sprintf("logit bias is not supported by %s", provider),
+	}
+}
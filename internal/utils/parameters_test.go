@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+func TestFindUnsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      interface{}
+		provider ProviderType
+		expected []string
+	}{
+		{
+			name:     "supported completion request",
+			req:      types.CompletionRequest{Prompt: "Hi", Stop: []string{"a", "b"}},
+			provider: types.ProviderOpenAI,
+		},
+		{
+			name:     "too many stop sequences for OpenAI",
+			req:      types.CompletionRequest{Prompt: "Hi", Stop: []string{"a", "b", "c", "d", "e"}},
+			provider: types.ProviderOpenAI,
+			expected: []string{"stop"},
+		},
+		{
+			name:     "five stop sequences allowed for Anthropic",
+			req:      types.CompletionRequest{Prompt: "Hi", Stop: []string{"a", "b", "c", "d", "e"}},
+			provider: types.ProviderAnthropic,
+		},
+		{
+			name:     "streaming completion",
+			req:      types.CompletionRequest{Prompt: "Hi", Stream: true},
+			provider: types.ProviderAnthropic,
+			expected: []string{"stream"},
+		},
+		{
+			name:     "logit bias for OpenAI",
+			req:      types.CompletionRequest{Prompt: "Hi", LogitBias: map[string]float64{"50256": -100}},
+			provider: types.ProviderOpenAI,
+		},
+		{
+			name:     "logit bias for Anthropic",
+			req:      types.ChatRequest{Messages: []types.Message{{Role: "user", Content: "Hi"}}, LogitBias: map[string]float64{"50256": -100}},
+			provider: types.ProviderAnthropic,
+			expected: []string{"logit_bias"},
+		},
+		{
+			name:     "streaming chat",
+			req:      types.ChatRequest{Messages: []types.Message{{Role: "user", Content: "Hi"}}, Stream: true},
+			provider: types.ProviderOpenAI,
+			expected: []string{"stream"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsupported, remaining := findAndDrop(tt.req, tt.provider)
+			if len(unsupported) != len(tt.expected) {
+				t.Fatalf("Expected %d unsupported parameters, got %v", len(tt.expected), unsupported)
+			}
+			for i, param := range unsupported {
+				if param.Parameter != tt.expected[i] {
+					t.Errorf("Expected parameter %q, got %q", tt.expected[i], param.Parameter)
+				}
+				if param.Provider != tt.provider {
+					t.Errorf("Expected provider %q, got %q", tt.provider, param.Provider)
+				}
+			}
+
+			// Dropping must leave nothing unsupported
+			if len(remaining) != 0 {
+				t.Errorf("Expected no unsupported parameters after drop, got %v", remaining)
+			}
+		})
+	}
+}
+
+// findAndDrop returns the unsupported parameters of a completion or chat
+// request, and those left after dropping them
+func findAndDrop(req interface{}, provider ProviderType) (unsupported, remaining []types.UnsupportedParameter) {
+	switch r := req.(type) {
+	case types.CompletionRequest:
+		return FindUnsupportedCompletion(r, provider), FindUnsupportedCompletion(DropUnsupportedCompletion(r, provider), provider)
+	case types.ChatRequest:
+		return FindUnsupportedChat(r, provider), FindUnsupportedChat(DropUnsupportedChat(r, provider), provider)
+	}
+	return nil, nil
+}
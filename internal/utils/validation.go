@@ -3,6 +3,7 @@ package utils
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/ajeet-kumar1087/ai-providers/types"
@@ -107,6 +108,24 @@ func GetProviderMaxTemperature(provider ProviderType) float64 {
 	}
 }
 
+// GetProviderMinReasoningBudget returns the smallest reasoning budget a provider accepts
+func GetProviderMinReasoningBudget(provider ProviderType) int {
+	switch provider {
+	case types.ProviderAnthropic:
+		return 1024 // Anthropic rejects smaller thinking budgets
+	default:
+		return 1
+	}
+}
+
+// ValidateReasoningBudget checks a reasoning budget against the provider minimum
+func ValidateReasoningBudget(budget *int, provider ProviderType) error {
+	if minBudget := GetProviderMinReasoningBudget(provider); budget != nil && *budget < minBudget {
+		return fmt.Errorf("reasoning_budget must be at least %d for provider %s, got: %d", minBudget, provider, *budget)
+	}
+	return nil
+}
+
 // GetProviderMaxStopSequences returns the maximum number of stop sequences for a provider
 func GetProviderMaxStopSequences(provider ProviderType) int {
 	switch provider {
@@ -121,6 +140,11 @@ func GetProviderMaxStopSequences(provider ProviderType) int {
 	}
 }
 
+// ProviderSupportsLogitBias reports whether a provider accepts logit bias
+func ProviderSupportsLogitBias(provider ProviderType) bool {
+	return provider == types.ProviderOpenAI
+}
+
 // GetDefaultMaxTokens returns a sensible default for max tokens for a provider
 func GetDefaultMaxTokens(provider ProviderType) int {
 	switch provider {
@@ -159,7 +183,21 @@ func ValidateCompletionRequest(req types.CompletionRequest) error {
 		// Don't validate upper bound here - let provider-specific validation handle it
 	}
 
-	return nil
+	if err := validateTags(req.Tags); err != nil {
+		return err
+	}
+
+	if err := validateLogitBias(req.LogitBias); err != nil {
+		return err
+	}
+
+	if req.Grammar != nil {
+		if err := req.Grammar.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return validateLengthLimits(req.MaxWords, req.MaxChars)
 }
 
 // ValidateChatRequest validates a chat request (basic validation only)
@@ -190,6 +228,59 @@ func ValidateChatRequest(req types.ChatRequest) error {
 		// Don't validate upper bound here - let provider-specific validation handle it
 	}
 
+	if err := validateTags(req.Tags); err != nil {
+		return err
+	}
+
+	if err := validateLogitBias(req.LogitBias); err != nil {
+		return err
+	}
+
+	if req.ReasoningBudget != nil && *req.ReasoningBudget <= 0 {
+		return fmt.Errorf("reasoning_budget must be positive, got: %d", *req.ReasoningBudget)
+	}
+
+	if req.Grammar != nil {
+		if err := req.Grammar.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return validateLengthLimits(req.MaxWords, req.MaxChars)
+}
+
+// validateLengthLimits checks that word and character limits are positive
+func validateLengthLimits(maxWords, maxChars *int) error {
+	if maxWords != nil && *maxWords <= 0 {
+		return fmt.Errorf("max_words must be positive, got: %d", *maxWords)
+	}
+	if maxChars != nil && *maxChars <= 0 {
+		return fmt.Errorf("max_chars must be positive, got: %d", *maxChars)
+	}
+	return nil
+}
+
+// validateLogitBias checks that logit bias keys are token IDs and biases are
+// within -100 to 100
+func validateLogitBias(bias map[string]float64) error {
+	for token, value := range bias {
+		if id, err := strconv.Atoi(token); err != nil || id < 0 {
+			return fmt.Errorf("logit_bias keys must be token IDs, got: %q", token)
+		}
+		if value < -100 || value > 100 {
+			return fmt.Errorf("logit_bias for token %s must be between -100 and 100, got: %g", token, value)
+		}
+	}
+	return nil
+}
+
+// validateTags checks that cost attribution tags have non-empty keys
+func validateTags(tags map[string]string) error {
+	for key := range tags {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("tag keys cannot be empty")
+		}
+	}
 	return nil
 }
 
@@ -199,7 +290,7 @@ func ValidateMessage(msg types.Message, index int) error {
 		return fmt.Errorf("message %d: role is required", index)
 	}
 
-	if strings.TrimSpace(msg.Content) == "" {
+	if strings.TrimSpace(msg.Content) == "" && msg.ContentReader == nil {
 		return fmt.Errorf("message %d: content is required", index)
 	}
 
@@ -214,47 +305,93 @@ func ValidateMessage(msg types.Message, index int) error {
 	return nil
 }
 
-// ClampParameters clamps parameters to provider-specific ranges
+// FindOutOfRangeCompletion returns the temperature, max tokens and stop
+// sequences of a completion request that exceed the provider's limits
+func FindOutOfRangeCompletion(req types.CompletionRequest, provider ProviderType) []types.OutOfRangeParameter {
+	outOfRange := findOutOfRange(req.Temperature, req.MaxTokens, provider)
+	if maxStop := GetProviderMaxStopSequences(provider); len(req.Stop) > maxStop {
+		outOfRange = append(outOfRange, types.OutOfRangeParameter{
+			Provider:  provider,
+			Parameter: "stop",
+			Value:     float64(len(req.Stop)),
+			Limit:     float64(maxStop),
+		})
+	}
+	return outOfRange
+}
+
+// FindOutOfRangeChat returns the temperature and max tokens of a chat request
+// that exceed the provider's limits
+func FindOutOfRangeChat(req types.ChatRequest, provider ProviderType) []types.OutOfRangeParameter {
+	return findOutOfRange(req.Temperature, req.MaxTokens, provider)
+}
+
+// findOutOfRange checks the parameters shared by completion and chat requests
+func findOutOfRange(temperature *float64, maxTokens *int, provider ProviderType) []types.OutOfRangeParameter {
+	var outOfRange []types.OutOfRangeParameter
+	if maxTemp := GetProviderMaxTemperature(provider); temperature != nil && *temperature > maxTemp {
+		outOfRange = append(outOfRange, types.OutOfRangeParameter{
+			Provider:  provider,
+			Parameter: "temperature",
+			Value:     *temperature,
+			Limit:     maxTemp,
+		})
+	}
+	if limit := GetProviderTokenLimit(provider); maxTokens != nil && *maxTokens > limit {
+		outOfRange = append(outOfRange, types.OutOfRangeParameter{
+			Provider:  provider,
+			Parameter: "max_tokens",
+			Value:     float64(*maxTokens),
+			Limit:     float64(limit),
+		})
+	}
+	return outOfRange
+}
+
+// ApplyValidationMode handles out-of-range parameters according to mode: the
+// strict mode returns the first one as an error, and the warn mode reports
+// each to onOutOfRange. Clamping is left to the caller.
+func ApplyValidationMode(mode types.ValidationMode, onOutOfRange func(types.OutOfRangeParameter), outOfRange []types.OutOfRangeParameter) error {
+	if len(outOfRange) == 0 {
+		return nil
+	}
+	switch mode {
+	case types.ValidationModeStrict:
+		return outOfRange[0]
+	case types.ValidationModeWarn:
+		if onOutOfRange != nil {
+			for _, param := range outOfRange {
+				onOutOfRange(param)
+			}
+		}
+	}
+	return nil
+}
+
+// ClampParameters clamps parameters to provider-specific ranges. Requests of
+// any other type are returned unchanged.
+//
+// Deprecated: Use ClampCompletion or ClampChat, which take and return typed
+// requests.
 func ClampParameters(req interface{}, provider ProviderType) interface{} {
 	switch r := req.(type) {
 	case types.CompletionRequest:
-		return clampCompletionRequest(r, provider)
+		return ClampCompletion(r, provider)
 	case types.ChatRequest:
-		return clampChatRequest(r, provider)
+		return ClampChat(r, provider)
 	default:
 		return req
 	}
 }
 
-// clampCompletionRequest clamps completion request parameters
-func clampCompletionRequest(req types.CompletionRequest, provider ProviderType) types.CompletionRequest {
+// ClampCompletion returns a copy of req with temperature, max tokens and stop
+// sequences clamped to the provider's ranges. Non-positive max tokens are
+// replaced with the provider default.
+func ClampCompletion(req types.CompletionRequest, provider ProviderType) types.CompletionRequest {
 	clamped := req
 
-	// Clamp temperature
-	if clamped.Temperature != nil {
-		temp := *clamped.Temperature
-		maxTemp := GetProviderMaxTemperature(provider)
-		if temp > maxTemp {
-			temp = maxTemp
-		}
-		if temp < 0.0 {
-			temp = 0.0
-		}
-		clamped.Temperature = &temp
-	}
-
-	// Clamp max tokens
-	if clamped.MaxTokens != nil {
-		tokens := *clamped.MaxTokens
-		maxTokens := GetProviderTokenLimit(provider)
-		if tokens > maxTokens {
-			tokens = maxTokens
-		}
-		if tokens <= 0 {
-			tokens = GetDefaultMaxTokens(provider)
-		}
-		clamped.MaxTokens = &tokens
-	}
+	clamped.Temperature = clampTemperature(clamped.Temperature, provider)
+	clamped.MaxTokens = clampMaxTokens(clamped.MaxTokens, provider)
 
 	// Clamp stop sequences
 	maxStop := GetProviderMaxStopSequences(provider)
@@ -265,37 +402,60 @@ func clampCompletionRequest(req types.CompletionRequest, provider ProviderType)
 	return clamped
 }
 
-// clampChatRequest clamps chat request parameters
-func clampChatRequest(req types.ChatRequest, provider ProviderType) types.ChatRequest {
+// ClampChat returns a copy of req with temperature and max tokens clamped to
+// the provider's ranges. Non-positive max tokens are replaced with the
+// provider default.
+func ClampChat(req types.ChatRequest, provider ProviderType) types.ChatRequest {
 	clamped := req
 
-	// Clamp temperature
-	if clamped.Temperature != nil {
-		temp := *clamped.Temperature
-		maxTemp := GetProviderMaxTemperature(provider)
-		if temp > maxTemp {
-			temp = maxTemp
-		}
-		if temp < 0.0 {
-			temp = 0.0
-		}
-		clamped.Temperature = &temp
-	}
+	clamped.Temperature = clampTemperature(clamped.Temperature, provider)
+	clamped.MaxTokens = clampMaxTokens(clamped.MaxTokens, provider)
 
-	// Clamp max tokens
-	if clamped.MaxTokens != nil {
-		tokens := *clamped.MaxTokens
-		maxTokens := GetProviderTokenLimit(provider)
-		if tokens > maxTokens {
-			tokens = maxTokens
-		}
-		if tokens <= 0 {
-			tokens = GetDefaultMaxTokens(provider)
-		}
-		clamped.MaxTokens = &tokens
+	return clamped
+}
+
+// clampTemperature returns temperature clamped to the provider range. The
+// pointer is reused when the value is already in range, which keeps clamping
+// free of allocations for valid requests.
+func clampTemperature(temperature *float64, provider ProviderType) *float64 {
+	if temperature == nil {
+		return nil
+	}
+	temp := *temperature
+	maxTemp := GetProviderMaxTemperature(provider)
+	if temp > maxTemp {
+		temp = maxTemp
 	}
+	if temp < 0.0 {
+		temp = 0.0
+	}
+	if temp == *temperature {
+		return temperature
+	}
+	clamped := temp
+	return &clamped
+}
 
-	return clamped
+// clampMaxTokens returns maxTokens clamped to the provider limit, replacing
+// non-positive values with the provider default. Like clampTemperature, the
+// pointer is reused when the value is already valid.
+func clampMaxTokens(maxTokens *int, provider ProviderType) *int {
+	if maxTokens == nil {
+		return nil
+	}
+	tokens := *maxTokens
+	limit := GetProviderTokenLimit(provider)
+	if tokens > limit {
+		tokens = limit
+	}
+	if tokens <= 0 {
+		tokens = GetDefaultMaxTokens(provider)
+	}
+	if tokens == *maxTokens {
+		return maxTokens
+	}
+	clamped := tokens
+	return &clamped
 }
 
 // Type alias for convenience
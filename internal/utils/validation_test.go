@@ -320,6 +320,42 @@ func TestValidateCompletionRequest(t *testing.T) {
 			wantErr: true,
 			errMsg:  "prompt is required and cannot be empty",
 		},
+		{
+			name: "logit bias key not a token ID",
+			request: types.CompletionRequest{
+				Prompt:    "Hello",
+				LogitBias: map[string]float64{"hello": 10},
+			},
+			wantErr: true,
+			errMsg:  "logit_bias keys must be token IDs",
+		},
+		{
+			name: "logit bias out of range",
+			request: types.CompletionRequest{
+				Prompt:    "Hello",
+				LogitBias: map[string]float64{"50256": -101},
+			},
+			wantErr: true,
+			errMsg:  "must be between -100 and 100",
+		},
+		{
+			name: "zero max words",
+			request: types.CompletionRequest{
+				Prompt:   "Hello",
+				MaxWords: intPtr(0),
+			},
+			wantErr: true,
+			errMsg:  "max_words must be positive",
+		},
+		{
+			name: "negative max chars",
+			request: types.CompletionRequest{
+				Prompt:   "Hello",
+				MaxChars: intPtr(-5),
+			},
+			wantErr: true,
+			errMsg:  "max_chars must be positive",
+		},
 		{
 			name: "negative temperature",
 			request: types.CompletionRequest{
@@ -562,7 +598,7 @@ func TestValidateMessage(t *testing.T) {
 	}
 }
 
-// Test ClampParameters
+// Test ClampCompletion, ClampChat and the deprecated ClampParameters
 func TestClampParameters(t *testing.T) {
 	// Test CompletionRequest clamping
 	completionReq := types.CompletionRequest{
@@ -572,7 +608,7 @@ func TestClampParameters(t *testing.T) {
 		Stop:        []string{".", "!", "?", ";", ":", ",", "\n"}, // Too many for OpenAI
 	}
 
-	clampedCompletion := ClampParameters(completionReq, types.ProviderOpenAI).(types.CompletionRequest)
+	clampedCompletion := ClampCompletion(completionReq, types.ProviderOpenAI)
 
 	if clampedCompletion.Temperature == nil || *clampedCompletion.Temperature != 2.0 {
 		t.Errorf("Temperature not clamped correctly: got %v, want 2.0", clampedCompletion.Temperature)
@@ -593,7 +629,7 @@ func TestClampParameters(t *testing.T) {
 		MaxTokens:   intPtr(0),      // Invalid
 	}
 
-	clampedChat := ClampParameters(chatReq, types.ProviderAnthropic).(types.ChatRequest)
+	clampedChat := ClampChat(chatReq, types.ProviderAnthropic)
 
 	if clampedChat.Temperature == nil || *clampedChat.Temperature != 0.0 {
 		t.Errorf("Temperature not clamped correctly: got %v, want 0.0", clampedChat.Temperature)
@@ -602,6 +638,11 @@ func TestClampParameters(t *testing.T) {
 		t.Errorf("MaxTokens not set to default correctly: got %v, want 1024", clampedChat.MaxTokens)
 	}
 
+	// The deprecated wrapper dispatches to the typed functions
+	if wrapped := ClampParameters(chatReq, types.ProviderAnthropic).(types.ChatRequest); *wrapped.MaxTokens != *clampedChat.MaxTokens {
+		t.Errorf("ClampParameters should match ClampChat: got %d, want %d", *wrapped.MaxTokens, *clampedChat.MaxTokens)
+	}
+
 	// Test unknown type (should return unchanged)
 	unknownType := "unknown"
 	result := ClampParameters(unknownType, types.ProviderOpenAI)
@@ -610,6 +651,59 @@ func TestClampParameters(t *testing.T) {
 	}
 }
 
+func TestClampChat_InRangeReusesPointers(t *testing.T) {
+	temperature := floatPtr(0.7)
+	maxTokens := intPtr(100)
+	req := types.ChatRequest{
+		Messages:    []types.Message{{Role: "user", Content: "Hello"}},
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
+	}
+
+	clamped := ClampChat(req, types.ProviderAnthropic)
+	if clamped.Temperature != temperature || clamped.MaxTokens != maxTokens {
+		t.Errorf("Expected in-range values to keep their pointers")
+	}
+
+	// Clamped values never write through the caller's pointers
+	req.Temperature = floatPtr(1.5)
+	clamped = ClampChat(req, types.ProviderAnthropic)
+	if *clamped.Temperature != 1.0 || *req.Temperature != 1.5 {
+		t.Errorf("Expected a new pointer for the clamped temperature, got %v (request %v)", *clamped.Temperature, *req.Temperature)
+	}
+}
+
+func TestFindOutOfRange(t *testing.T) {
+	req := types.CompletionRequest{
+		Prompt:      "Hello",
+		Temperature: floatPtr(1.5),
+		MaxTokens:   intPtr(100),
+		Stop:        []string{"a", "b", "c", "d", "e"},
+	}
+
+	outOfRange := FindOutOfRangeCompletion(req, types.ProviderOpenAI)
+	if len(outOfRange) != 1 || outOfRange[0].Parameter != "stop" || outOfRange[0].Value != 5 || outOfRange[0].Limit != 4 {
+		t.Errorf("Expected only the stop sequences out of range for OpenAI, got %+v", outOfRange)
+	}
+
+	chat := types.ChatRequest{Temperature: req.Temperature, MaxTokens: intPtr(200000)}
+	outOfRange = FindOutOfRangeChat(chat, types.ProviderAnthropic)
+	if len(outOfRange) != 2 || outOfRange[0].Parameter != "temperature" || outOfRange[1].Parameter != "max_tokens" {
+		t.Errorf("Expected temperature and max tokens out of range for Anthropic, got %+v", outOfRange)
+	}
+
+	if err := ApplyValidationMode(types.ValidationModeClamp, nil, outOfRange); err != nil {
+		t.Errorf("Expected no error when clamping, got %v", err)
+	}
+	if err := ApplyValidationMode(types.ValidationModeStrict, nil, outOfRange); err != outOfRange[0] {
+		t.Errorf("Expected the first parameter as the error, got %v", err)
+	}
+	var reported int
+	if err := ApplyValidationMode(types.ValidationModeWarn, func(types.OutOfRangeParameter) { reported++ }, outOfRange); err != nil || reported != 2 {
+		t.Errorf("Expected both parameters reported without error, got %d reported and %v", reported, err)
+	}
+}
+
 // Helper functions (keeping local copies since this is in a different package)
 func floatPtr(f float64) *float64 {
 	return &f
@@ -0,0 +1,162 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dialTest opens a client connection to a test server
+func dialTest(t *testing.T, server *httptest.Server) *Conn {
+	t.Helper()
+	conn, resp, err := Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected status 101, got %d", resp.StatusCode)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// echoServer echoes every message back to the client
+func echoServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(messageType, data)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEcho(t *testing.T) {
+	conn := dialTest(t, echoServer(t))
+
+	for _, message := range []string{"hello", strings.Repeat("a", 200), strings.Repeat("b", 70000)} {
+		if err := conn.WriteMessage(TextMessage, []byte(message)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if messageType != TextMessage || string(data) != message {
+			t.Errorf("Expected the %d byte message echoed, got type %d with %d bytes", len(message), messageType, len(data))
+		}
+	}
+}
+
+func TestPingPong(t *testing.T) {
+	conn := dialTest(t, echoServer(t))
+
+	pongs := make(chan struct{}, 1)
+	conn.SetPongHandler(func() { pongs <- struct{}{} })
+	if err := conn.Ping(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+	// The pong is handled while waiting for the next message
+	conn.WriteMessage(TextMessage, []byte("after ping"))
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "after ping" {
+		t.Fatalf("Expected the echoed message, got %q, %v", data, err)
+	}
+	select {
+	case <-pongs:
+	default:
+		t.Error("Expected the pong handler to be called")
+	}
+}
+
+func TestClose(t *testing.T) {
+	conn := dialTest(t, echoServer(t))
+
+	if err := conn.WriteClose(CloseNormal, "bye"); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	_, _, err := conn.ReadMessage()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != CloseNormal || !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected the server to echo the close, got %v", err)
+	}
+}
+
+func TestReadLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.SetReadLimit(10)
+		conn.ReadMessage()
+	}))
+	defer server.Close()
+	conn := dialTest(t, server)
+
+	conn.WriteMessage(TextMessage, []byte("more than ten bytes"))
+	_, _, err := conn.ReadMessage()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != CloseTooLarge {
+		t.Errorf("Expected a too large close, got %v", err)
+	}
+}
+
+func TestAcceptKey(t *testing.T) {
+	// The example handshake from RFC 6455 section 1.3
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Unexpected accept key %q", got)
+	}
+}
+
+func TestDial_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, resp, err := Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the rejection with its response, got %v", err)
+	}
+}
+
+func TestUpgrade_Rejected(t *testing.T) {
+	server := echoServer(t)
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a plain request, got %d", resp.StatusCode)
+	}
+}
+
+func TestUpgrade_Origin(t *testing.T) {
+	server := echoServer(t)
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	_, resp, err := Dial(context.Background(), url, http.Header{"Origin": {"https://evil.example"}})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a cross-origin handshake to be rejected with 403, got %v", err)
+	}
+	conn, _, err := Dial(context.Background(), url, http.Header{"Origin": {server.URL}})
+	if err != nil {
+		t.Fatalf("Expected a same-origin handshake to succeed, got %v", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,368 @@
+// Package jsonschema provides a small subset of JSON Schema for validating
+// structured model output.
+//
+// Validate supports the keywords most useful for describing extraction
+// targets: type, properties, required, additionalProperties (false only),
+// items, enum, minItems and maxItems. Unknown keywords are ignored, so richer
+// schemas can be shared with providers that understand them. For generates a
+// schema from a Go type using its json struct tags.
+//
+// Example:
+//
+//	type Invoice struct {
+//		Number string   `json:"number"`
+//		Total  float64  `json:"total"`
+//		Items  []string `json:"items,omitempty"`
+//	}
+//
+//	schema, err := jsonschema.For(Invoice{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := jsonschema.Validate(schema, output); err != nil {
+//		fmt.Println("invalid output:", err)
+//	}
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schema is the decoded form of the supported JSON Schema subset.
+type Schema struct {
+	// Type is a JSON type name or a list of type names
+	Type interface{} `json:"type,omitempty"`
+
+	// Description documents the value for the model
+	Description string `json:"description,omitempty"`
+
+	// Properties describes object members
+	Properties map[string]*Schema `json:"properties,omitempty"`
+
+	// Required lists object members that must be present
+	Required []string `json:"required,omitempty"`
+
+	// AdditionalProperties disallows unknown object members when false
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+
+	// Items describes array elements
+	Items *Schema `json:"items,omitempty"`
+
+	// Enum restricts the value to a fixed set
+	Enum []interface{} `json:"enum,omitempty"`
+
+	// MinItems is the minimum array length
+	MinItems *int `json:"minItems,omitempty"`
+
+	// MaxItems is the maximum array length
+	MaxItems *int `json:"maxItems,omitempty"`
+}
+
+// ValidationError describes why a value does not match a schema.
+type ValidationError struct {
+	// Path is the location of the invalid value, e.g. "$.items[2].price"
+	Path string
+
+	// Message describes the problem
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Parse decodes a JSON schema document
+func Parse(schema json.RawMessage) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON schema: %w", err)
+	}
+	return &s, nil
+}
+
+// Validate checks that data is valid JSON matching schema
+func Validate(schema, data json.RawMessage) error {
+	s, err := Parse(schema)
+	if err != nil {
+		return err
+	}
+	return s.Validate(data)
+}
+
+// Validate checks that data is valid JSON matching the schema
+func (s *Schema) Validate(data json.RawMessage) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return &ValidationError{Path: "$", Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if decoder.More() {
+		return &ValidationError{Path: "$", Message: "unexpected data after JSON value"}
+	}
+	return s.validate("$", value)
+}
+
+// validate checks a decoded value against the schema
+func (s *Schema) validate(path string, value interface{}) error {
+	if s == nil {
+		return nil
+	}
+
+	if types := s.types(); len(types) > 0 {
+		actual := typeOf(value)
+		ok := false
+		for _, t := range types {
+			if t == actual || (t == "number" && actual == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), actual)}
+		}
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("value %s is not one of the allowed values", compact(value))}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return &ValidationError{Path: path, Message: fmt.Sprintf("missing required property %q", name)}
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return &ValidationError{Path: path, Message: fmt.Sprintf("unexpected property %q", name)}
+				}
+				continue
+			}
+			if err := property.validate(path+"."+name, v[name]); err != nil {
+				return err
+			}
+		}
+
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("expected at least %d items, got %d", *s.MinItems, len(v))}
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("expected at most %d items, got %d", *s.MaxItems, len(v))}
+		}
+		for i, item := range v {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// types returns the allowed type names
+func (s *Schema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+// inEnum reports whether value equals one of the enum values
+func (s *Schema) inEnum(value interface{}) bool {
+	encoded := compact(value)
+	for _, allowed := range s.Enum {
+		if compact(allowed) == encoded {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON type name of a decoded value
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(v.String(), ".eE") {
+			return "integer"
+		}
+		return "number"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// compact returns the canonical JSON encoding of a value for comparison
+func compact(value interface{}) string {
+	if n, ok := value.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			value = f
+		}
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// For generates a schema for the type of v.
+//
+// Struct fields are named by their json tags and are required unless tagged
+// omitempty or declared as pointers. Fields tagged "-" and unexported fields
+// are skipped. Objects do not allow additional properties.
+func For(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot generate a schema for nil")
+	}
+	s, err := schemaFor(reflect.TypeOf(v), nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(s)
+}
+
+// schemaFor builds the schema of t; seen guards against recursive types
+func schemaFor(t reflect.Type, seen map[reflect.Type]bool) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Description: "RFC 3339 timestamp"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := schemaFor(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		return &Schema{Type: "object"}, nil
+	case reflect.Struct:
+		return structSchema(t, seen)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// structSchema builds an object schema from struct fields
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) (*Schema, error) {
+	if seen[t] {
+		return nil, fmt.Errorf("recursive type %s is not supported", t)
+	}
+	if seen == nil {
+		seen = make(map[reflect.Type]bool)
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	closed := false
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: &closed,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		omitempty := false
+		if tag := field.Tag.Get("json"); tag != "" {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, option := range parts[1:] {
+				if option == "omitempty" {
+					omitempty = true
+				}
+			}
+		}
+
+		property, err := schemaFor(field.Type, seen)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			property.Description = description
+		}
+		s.Properties[name] = property
+
+		if !omitempty && field.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,104 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer"},
+			"score": {"type": ["number", "null"]},
+			"status": {"enum": ["active", "inactive"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+		},
+		"required": ["name"],
+		"additionalProperties": false
+	}`)
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"valid", `{"name": "Ada", "age": 36, "score": 9.5, "status": "active", "tags": ["a"]}`, ""},
+		{"null allowed", `{"name": "Ada", "score": null}`, ""},
+		{"missing required", `{"age": 36}`, `missing required property "name"`},
+		{"wrong type", `{"name": 1}`, "$.name: expected string, got integer"},
+		{"integer mismatch", `{"name": "Ada", "age": 36.5}`, "$.age: expected integer, got number"},
+		{"enum", `{"name": "Ada", "status": "gone"}`, "$.status: value"},
+		{"array item", `{"name": "Ada", "tags": ["a", 2]}`, "$.tags[1]: expected string"},
+		{"max items", `{"name": "Ada", "tags": ["a", "b", "c"]}`, "at most 2 items"},
+		{"additional property", `{"name": "Ada", "extra": true}`, `unexpected property "extra"`},
+		{"invalid JSON", `{"name":`, "invalid JSON"},
+		{"trailing data", `{"name": "Ada"} {}`, "unexpected data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(schema, json.RawMessage(tt.data))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	type Address struct {
+		City string `json:"city" description:"City name"`
+	}
+	type Person struct {
+		Name     string    `json:"name"`
+		Age      int       `json:"age,omitempty"`
+		Address  *Address  `json:"address"`
+		Tags     []string  `json:"tags"`
+		Born     time.Time `json:"born"`
+		Internal string    `json:"-"`
+		private  string
+	}
+
+	schema, err := For(Person{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	s, err := Parse(schema)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(s.Properties) != 5 {
+		t.Errorf("Expected 5 properties, got %d", len(s.Properties))
+	}
+	if strings.Join(s.Required, ",") != "name,tags,born" {
+		t.Errorf("Expected required name,tags,born, got %v", s.Required)
+	}
+	if s.Properties["address"].Properties["city"].Description != "City name" {
+		t.Errorf("Expected field description to be carried over")
+	}
+
+	if err := s.Validate(json.RawMessage(`{"name": "Ada", "tags": [], "born": "1815-12-10T00:00:00Z", "address": {"city": "London"}}`)); err != nil {
+		t.Errorf("Expected valid document, got %v", err)
+	}
+	if err := s.Validate(json.RawMessage(`{"name": "Ada", "tags": [], "born": "x", "Internal": "y"}`)); err == nil {
+		t.Errorf("Expected unknown property to be rejected")
+	}
+
+	type Node struct {
+		Children []Node `json:"children"`
+	}
+	if _, err := For(Node{}); err == nil {
+		t.Errorf("Expected error for recursive type")
+	}
+}
@@ -0,0 +1,76 @@
+package aiprovider
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// lengthTokenLimit derives a token limit from word and character limits,
+// capped at ceiling, returning maxTokens if it is already the tighter limit
+func lengthTokenLimit(maxTokens, maxWords, maxChars *int, ceiling int) *int {
+	if maxWords == nil && maxChars == nil {
+		return maxTokens
+	}
+
+	limit := ceiling
+	if maxTokens != nil && *maxTokens < limit {
+		limit = *maxTokens
+	}
+	if maxWords != nil {
+		if tokens := tokenizer.TokensForWords(*maxWords); tokens < limit {
+			limit = tokens
+		}
+	}
+	if maxChars != nil {
+		if tokens := tokenizer.TokensForChars(*maxChars); tokens < limit {
+			limit = tokens
+		}
+	}
+	return &limit
+}
+
+// trimToLength shortens text to at most maxWords words and maxChars
+// characters, cutting at a word boundary where possible. It reports whether
+// the text was shortened.
+func trimToLength(text string, maxWords, maxChars *int) (string, bool) {
+	trimmed := false
+
+	if maxWords != nil {
+		words := 0
+		inWord := false
+		for i, r := range text {
+			if unicode.IsSpace(r) {
+				inWord = false
+				continue
+			}
+			if !inWord {
+				inWord = true
+				words++
+				if words > *maxWords {
+					text = strings.TrimRightFunc(text[:i], unicode.IsSpace)
+					trimmed = true
+					break
+				}
+			}
+		}
+	}
+
+	if maxChars != nil && utf8.RuneCountInString(text) > *maxChars {
+		cut := len(tokenizer.TruncateRunes(text, *maxChars))
+
+		// Prefer ending at the last word boundary within the limit
+		next, _ := utf8.DecodeRuneInString(text[cut:])
+		if !unicode.IsSpace(next) {
+			if space := strings.LastIndexFunc(text[:cut], unicode.IsSpace); space > 0 {
+				cut = space
+			}
+		}
+		text = strings.TrimRightFunc(text[:cut], unicode.IsSpace)
+		trimmed = true
+	}
+
+	return text, trimmed
+}
@@ -0,0 +1,116 @@
+package aiprovider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTrimToLength(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		maxWords    *int
+		maxChars    *int
+		want        string
+		wantTrimmed bool
+	}{
+		{"no limits", "one two three", nil, nil, "one two three", false},
+		{"within word limit", "one two three", intPtr(3), nil, "one two three", false},
+		{"word limit", "one two\n\nthree four", intPtr(2), nil, "one two", true},
+		{"leading space", "  one two", intPtr(1), nil, "  one", true},
+		{"within char limit", "hello", nil, intPtr(5), "hello", false},
+		{"char limit at word boundary", "hello world again", nil, intPtr(13), "hello world", true},
+		{"char limit on boundary", "hello world again", nil, intPtr(11), "hello world", true},
+		{"char limit without spaces", "abcdefghij", nil, intPtr(4), "abcd", true},
+		{"char limit counts runes", "héllo wörld", nil, intPtr(8), "héllo", true},
+		{"both limits", "one two three four", intPtr(3), intPtr(9), "one two", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, trimmed := trimToLength(tt.text, tt.maxWords, tt.maxChars)
+			if got != tt.want || trimmed != tt.wantTrimmed {
+				t.Errorf("Expected %q (trimmed %v), got %q (trimmed %v)", tt.want, tt.wantTrimmed, got, trimmed)
+			}
+		})
+	}
+}
+
+func TestLengthTokenLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxTokens *int
+		maxWords  *int
+		maxChars  *int
+		want      *int
+	}{
+		{"no limits", nil, nil, nil, nil},
+		{"tokens only", intPtr(50), nil, nil, intPtr(50)},
+		{"words", nil, intPtr(100), nil, intPtr(160)},
+		{"chars", nil, nil, intPtr(400), intPtr(120)},
+		{"tighter tokens kept", intPtr(20), intPtr(100), nil, intPtr(20)},
+		{"tightest of words and chars", nil, intPtr(100), intPtr(100), intPtr(30)},
+		{"capped at ceiling", nil, intPtr(1000000), nil, intPtr(4096)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lengthTokenLimit(tt.maxTokens, tt.maxWords, tt.maxChars, 4096)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("Expected %v, got %v", deref(tt.want), deref(got))
+			}
+		})
+	}
+}
+
+func TestChatComplete_MaxWords(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{
+		Message:      Message{Role: "assistant", Content: "The quick brown fox jumps over the lazy dog."},
+		FinishReason: "stop",
+	}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	resp, err := c.ChatComplete(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Describe a fox"}},
+		MaxWords: intPtr(4),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Message.Content != "The quick brown fox" || resp.FinishReason != "length" {
+		t.Errorf("Expected trimmed content with length finish reason, got %q (%s)", resp.Message.Content, resp.FinishReason)
+	}
+	if sent := adapter.chatRequests[0].MaxTokens; sent == nil || *sent != 7 {
+		t.Errorf("Expected derived max tokens 7, got %v", deref(sent))
+	}
+
+	_, err = c.ChatComplete(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+		MaxChars: intPtr(0),
+	})
+	if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+		t.Errorf("Expected validation error for zero max chars, got %v", err)
+	}
+}
+
+func TestComplete_MaxChars(t *testing.T) {
+	adapter := &mockAdapter{completeResp: &CompletionResponse{Text: "Hello there, world", FinishReason: "stop"}}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	resp, err := c.Complete(context.Background(), CompletionRequest{Prompt: "Greet", MaxChars: intPtr(14)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Text != "Hello there," || resp.FinishReason != "length" {
+		t.Errorf("Expected trimmed text, got %q (%s)", resp.Text, resp.FinishReason)
+	}
+}
+
+// deref returns the value of p, or -1 if p is nil
+func deref(p *int) int {
+	if p == nil {
+		return -1
+	}
+	return *p
+}
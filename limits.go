@@ -0,0 +1,60 @@
+package aiprovider
+
+import (
+	"context"
+)
+
+// Limits returns the known rate limits and quotas of the account.
+//
+// Rate limits are learned from the rate limit headers of every response, so
+// they are only known once the client has sent a request. Adapters that can
+// query a provider endpoint for the account limits (see LimitsAdapter) are
+// asked on every call, and their report is used unless a response observed
+// more recently carried fresher state. Zero values in the result mean the
+// limit is unknown, not that there is none.
+//
+// Example:
+//
+//	limits, err := client.Limits(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if limits.Rate != nil {
+//		fmt.Printf("%d requests and %d tokens per window\n",
+//			limits.Rate.RequestsLimit, limits.Rate.TokensLimit)
+//	}
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//
+// Returns:
+//   - *Limits: The known limits of the account
+//   - error: A provider error if querying the provider's limits endpoint failed
+func (c *client) Limits(ctx context.Context) (*Limits, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	limits := &Limits{Provider: c.provider, Rate: c.RateLimitStatus()}
+
+	reporter, ok := c.adapter.(LimitsAdapter)
+	if !ok {
+		return limits, nil
+	}
+	reported, err := reporter.Limits(ctx)
+	if err != nil {
+		return nil, c.sanitizeError(err, nil)
+	}
+	if reported == nil {
+		return limits, nil
+	}
+
+	if reported.Rate != nil {
+		c.recordRateLimit(reported.Rate)
+		limits.Rate = c.RateLimitStatus()
+	}
+	limits.Quotas = append([]Quota(nil), reported.Quotas...)
+	return limits, nil
+}
@@ -0,0 +1,67 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// limitsAdapter is a mock adapter reporting account limits from an endpoint
+type limitsAdapter struct {
+	*mockAdapter
+	limits *Limits
+	err    error
+}
+
+func (l *limitsAdapter) Limits(ctx context.Context) (*Limits, error) {
+	return l.limits, l.err
+}
+
+func TestLimits_FromHeaders(t *testing.T) {
+	client := newMockClient(ProviderOpenAI, &mockAdapter{})
+
+	limits, err := client.Limits(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if limits.Provider != ProviderOpenAI || limits.Rate != nil {
+		t.Errorf("Expected no known limits before a response, got %+v", limits)
+	}
+
+	client.recordRateLimit(&RateLimitStatus{RequestsLimit: 500, TokensLimit: 30000, ObservedAt: time.Now()})
+	limits, err = client.Limits(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if limits.Rate == nil || limits.Rate.RequestsLimit != 500 || limits.Rate.TokensLimit != 30000 {
+		t.Errorf("Expected the observed limits, got %+v", limits.Rate)
+	}
+}
+
+func TestLimits_FromAdapter(t *testing.T) {
+	now := time.Now()
+	adapter := &limitsAdapter{
+		mockAdapter: &mockAdapter{},
+		limits: &Limits{
+			Rate:   &RateLimitStatus{RequestsLimit: 50, ObservedAt: now},
+			Quotas: []Quota{{Name: "monthly_spend", Limit: 100, Used: 12.5, Unit: "usd"}},
+		},
+	}
+	client := newMockClient(ProviderAnthropic, adapter)
+
+	// A fresher observation wins over the reported rate limits
+	client.recordRateLimit(&RateLimitStatus{RequestsLimit: 60, ObservedAt: now.Add(time.Second)})
+	limits, err := client.Limits(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if limits.Rate.RequestsLimit != 60 || len(limits.Quotas) != 1 || limits.Quotas[0].Used != 12.5 {
+		t.Errorf("Unexpected limits: %+v", limits)
+	}
+
+	adapter.err = errors.New("endpoint unavailable")
+	if _, err := client.Limits(context.Background()); err == nil {
+		t.Errorf("Expected the adapter error")
+	}
+}
@@ -0,0 +1,203 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMapConcurrency is the number of prompts sent at once by MapPrompts
+// when MapOptions.Concurrency is zero
+const DefaultMapConcurrency = 4
+
+// MapOptions configures MapPrompts.
+type MapOptions struct {
+	// Concurrency is the number of prompts sent at once
+	// (default: DefaultMapConcurrency)
+	Concurrency int
+
+	// System is a system message sent with every prompt (optional)
+	System string
+
+	// Model overrides the client's default model (optional)
+	Model string
+
+	// Temperature overrides the client's default temperature (optional)
+	Temperature *float64
+
+	// MaxTokens limits each response (optional)
+	MaxTokens *int
+
+	// IsFatal reports whether a prompt's error cancels the prompts not yet
+	// finished and is returned by MapPrompts; other errors are only recorded
+	// in the prompt's result (default: authentication errors, which would
+	// fail every prompt)
+	IsFatal func(err error) bool
+}
+
+// MapResult is the outcome of one prompt of MapPrompts.
+type MapResult struct {
+	// Index is the prompt's position in the input
+	Index int `json:"index"`
+
+	// Prompt is the prompt
+	Prompt string `json:"prompt"`
+
+	// Text is the response text, empty if the prompt failed
+	Text string `json:"text,omitempty"`
+
+	// FinishReason indicates why the generation stopped
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// Usage is the token usage of the prompt
+	Usage Usage `json:"usage"`
+
+	// Latency is the response time
+	Latency time.Duration `json:"latency"`
+
+	// Err is the prompt's error, or the cancellation error if it was not
+	// sent or was cancelled after a fatal error
+	Err error `json:"-"`
+}
+
+// MapPrompts sends prompts to a client with bounded concurrency and returns
+// their results in input order.
+//
+// Each prompt's error is captured in its result, so one failing prompt does
+// not lose the others. An error opts.IsFatal reports as fatal cancels the
+// prompts not yet finished and is returned, like an errgroup; the results
+// are returned either way. Prompts are sent with chat completion when the
+// client supports it and text completion otherwise. For retries, progress
+// reporting and long-running queues, use a WorkerPool.
+//
+// Example:
+//
+//	results, err := MapPrompts(ctx, client, prompts, MapOptions{Concurrency: 8})
+//	if err != nil {
+//		log.Fatal(err) // a fatal error, e.g. an invalid API key
+//	}
+//	for _, result := range results {
+//		if result.Err != nil {
+//			log.Printf("prompt %d failed: %v", result.Index, result.Err)
+//			continue
+//		}
+//		fmt.Println(result.Text)
+//	}
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - client: The client sending the prompts
+//   - prompts: The prompts, each sent as a single user message
+//   - opts: Concurrency, request parameters and the fatal error policy
+//
+// Returns:
+//   - []MapResult: One result per prompt, in input order
+//   - error: The first fatal error, wrapped with the prompt index, or the context's error
+func MapPrompts(ctx context.Context, client Client, prompts []string, opts MapOptions) ([]MapResult, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultMapConcurrency
+	}
+	if concurrency > len(prompts) {
+		concurrency = len(prompts)
+	}
+	isFatal := opts.IsFatal
+	if isFatal == nil {
+		isFatal = isAuthenticationError
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]MapResult, len(prompts))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var fatal error
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result := &results[i]
+				if err := ctx.Err(); err != nil {
+					result.Err = err
+					continue
+				}
+				mapPrompt(ctx, client, result, opts)
+				if result.Err != nil && ctx.Err() == nil && isFatal(result.Err) {
+					once.Do(func() {
+						fatal = fmt.Errorf("prompt %d failed: %w", i, result.Err)
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for i, prompt := range prompts {
+		results[i] = MapResult{Index: i, Prompt: prompt}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if fatal != nil {
+		return results, fatal
+	}
+	// The caller's context was cancelled, since ours only is after a fatal error
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// mapPrompt sends one prompt and fills in its result
+func mapPrompt(ctx context.Context, client Client, result *MapResult, opts MapOptions) {
+	start := time.Now()
+	defer func() { result.Latency = time.Since(start) }()
+
+	if client.SupportsFeature(FeatureChatCompletion) {
+		var messages []Message
+		if opts.System != "" {
+			messages = append(messages, Message{Role: "system", Content: opts.System})
+		}
+		resp, err := client.ChatComplete(ctx, ChatRequest{
+			Messages:    append(messages, Message{Role: "user", Content: result.Prompt}),
+			Model:       opts.Model,
+			Temperature: opts.Temperature,
+			MaxTokens:   opts.MaxTokens,
+		})
+		if err != nil {
+			result.Err = err
+			return
+		}
+		result.Text, result.FinishReason, result.Usage = resp.Message.Content, resp.FinishReason, resp.Usage
+		return
+	}
+
+	prompt := result.Prompt
+	if opts.System != "" {
+		prompt = opts.System + "\n\n" + prompt
+	}
+	resp, err := client.Complete(ctx, CompletionRequest{
+		Prompt:      prompt,
+		Model:       opts.Model,
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+	})
+	if err != nil {
+		result.Err = err
+		return
+	}
+	result.Text, result.FinishReason, result.Usage = resp.Text, resp.FinishReason, resp.Usage
+}
+
+// isAuthenticationError reports whether err is an authentication error
+func isAuthenticationError(err error) bool {
+	var aiErr *Error
+	return errors.As(err, &aiErr) && aiErr.Type == ErrorTypeAuth
+}
@@ -0,0 +1,82 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMapPrompts(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(user string) (string, error) {
+		if user == "fail" {
+			return "", NewError(ErrorTypeProvider, "anthropic", "overloaded")
+		}
+		return strings.ToUpper(user), nil
+	}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	prompts := []string{"a", "b", "fail", "c", "d"}
+	results, err := MapPrompts(context.Background(), c, prompts, MapOptions{Concurrency: 2, System: "Shout."})
+	if err != nil {
+		t.Fatalf("Expected per-item errors not to fail the map, got %v", err)
+	}
+	if len(results) != len(prompts) {
+		t.Fatalf("Expected %d results, got %d", len(prompts), len(results))
+	}
+	for i, result := range results {
+		if result.Index != i || result.Prompt != prompts[i] {
+			t.Errorf("Expected result %d in input order, got %+v", i, result)
+		}
+		if prompts[i] == "fail" {
+			if result.Err == nil {
+				t.Error("Expected the failing prompt's error in its result")
+			}
+			continue
+		}
+		if result.Err != nil || result.Text != strings.ToUpper(prompts[i]) || result.Usage.TotalTokens != 15 {
+			t.Errorf("Expected a response for %q, got %+v", prompts[i], result)
+		}
+	}
+	if req := adapter.requests[0]; req.Messages[0].Role != "system" || req.Messages[0].Content != "Shout." {
+		t.Errorf("Expected the system message to be sent, got %+v", req.Messages)
+	}
+}
+
+func TestMapPrompts_FatalError(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(user string) (string, error) {
+		if user == "b" {
+			return "", NewError(ErrorTypeAuth, "anthropic", "invalid API key")
+		}
+		return user, nil
+	}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	results, err := MapPrompts(context.Background(), c, []string{"a", "b", "c", "d"}, MapOptions{Concurrency: 1})
+	var aiErr *Error
+	if !errors.As(err, &aiErr) || aiErr.Type != ErrorTypeAuth {
+		t.Fatalf("Expected the authentication error, got %v", err)
+	}
+	if results[0].Err != nil || results[0].Text != "a" {
+		t.Errorf("Expected the first prompt to succeed, got %+v", results[0])
+	}
+	for _, result := range results[2:] {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Expected prompt %d to be cancelled, got %v", result.Index, result.Err)
+		}
+	}
+	if len(adapter.requests) != 2 {
+		t.Errorf("Expected no requests after the fatal error, got %d", len(adapter.requests))
+	}
+
+	// A custom policy makes every error fatal
+	adapter.requests = nil
+	adapter.reply = func(user string) (string, error) { return "", errors.New("boom") }
+	_, err = MapPrompts(context.Background(), c, []string{"a", "b"}, MapOptions{
+		Concurrency: 1,
+		IsFatal:     func(error) bool { return true },
+	})
+	if err == nil || len(adapter.requests) != 1 {
+		t.Errorf("Expected the first error to stop the map, got %v after %d requests", err, len(adapter.requests))
+	}
+}
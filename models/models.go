@@ -0,0 +1,214 @@
+// Package models tracks the lifecycle of provider models.
+//
+// Providers deprecate models months before retiring them, after which
+// requests fail. The default registry lists the announced deprecation and
+// retirement dates of common OpenAI and Anthropic models with their
+// suggested replacements. Like the pricing package, model names are matched
+// by longest prefix, so "claude-2.1" resolves to the "claude-2" entry.
+//
+// The client warns about requests for deprecated models through
+// Config.OnDeprecatedModel and ResponseMetadata.Warnings. Add entries as
+// providers announce them with Set or LoadFile.
+//
+// Example:
+//
+//	if lifecycle, ok := models.Default().Lookup(types.ProviderOpenAI, "gpt-4-32k"); ok {
+//		fmt.Printf("retires %s, use %s\n", lifecycle.RetiresOn.Format("2006-01-02"), lifecycle.Replacement)
+//	}
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// Lifecycle describes the deprecation of a model.
+type Lifecycle struct {
+	// DeprecatedOn is when the deprecation was announced or takes effect
+	DeprecatedOn time.Time `json:"deprecated_on"`
+
+	// RetiresOn is when requests for the model start failing (zero if not
+	// announced)
+	RetiresOn time.Time `json:"retires_on,omitempty"`
+
+	// Replacement is the suggested model to migrate to (optional)
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Table maps providers to model prefixes to lifecycles.
+//
+// Its JSON form is used by LoadJSON and LoadFile, with RFC 3339 dates:
+//
+//	{
+//	  "openai": {"gpt-4-32k": {"deprecated_on": "2024-06-06T00:00:00Z", "retires_on": "2025-06-06T00:00:00Z", "replacement": "gpt-4o"}}
+//	}
+type Table map[types.ProviderType]map[string]Lifecycle
+
+// date returns midnight UTC of a day
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// defaultLifecycles contains announced deprecations per provider and model prefix
+var defaultLifecycles = Table{
+	types.ProviderOpenAI: {
+		"gpt-4-32k":            {DeprecatedOn: date(2024, time.June, 6), RetiresOn: date(2025, time.June, 6), Replacement: "gpt-4o"},
+		"gpt-4-0314":           {DeprecatedOn: date(2024, time.June, 6), RetiresOn: date(2025, time.June, 6), Replacement: "gpt-4o"},
+		"gpt-4-vision-preview": {DeprecatedOn: date(2024, time.June, 6), RetiresOn: date(2024, time.December, 6), Replacement: "gpt-4o"},
+		"gpt-3.5-turbo-0613":   {DeprecatedOn: date(2023, time.June, 13), RetiresOn: date(2024, time.September, 13), Replacement: "gpt-3.5-turbo"},
+		"gpt-3.5-turbo-0301":   {DeprecatedOn: date(2023, time.June, 13), RetiresOn: date(2024, time.September, 13), Replacement: "gpt-3.5-turbo"},
+		"text-davinci-003":     {DeprecatedOn: date(2023, time.July, 6), RetiresOn: date(2024, time.January, 4), Replacement: "gpt-3.5-turbo-instruct"},
+	},
+	types.ProviderAnthropic: {
+		"claude-2":                 {DeprecatedOn: date(2025, time.January, 21), RetiresOn: date(2025, time.July, 21), Replacement: "claude-3-5-sonnet-20241022"},
+		"claude-3-sonnet-20240229": {DeprecatedOn: date(2025, time.January, 21), RetiresOn: date(2025, time.July, 21), Replacement: "claude-3-5-sonnet-20241022"},
+		"claude-instant-1":         {DeprecatedOn: date(2024, time.September, 4), RetiresOn: date(2024, time.November, 6), Replacement: "claude-3-haiku-20240307"},
+	},
+}
+
+// Registry maps provider models to lifecycles.
+//
+// Registry is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	lifecycles map[types.ProviderType]map[string]Lifecycle
+}
+
+// NewRegistry creates a registry populated with the default lifecycles
+func NewRegistry() *Registry {
+	r := &Registry{lifecycles: make(map[types.ProviderType]map[string]Lifecycle)}
+	r.Merge(defaultLifecycles)
+	return r
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// Default returns the shared registry used by clients
+func Default() *Registry {
+	defaultRegistryOnce.Do(func() {
+		defaultRegistry = NewRegistry()
+	})
+	return defaultRegistry
+}
+
+// Lookup returns the lifecycle of a deprecated model, matching the longest
+// known model prefix. It reports false if no deprecation is known.
+func (r *Registry) Lookup(provider types.ProviderType, model string) (Lifecycle, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	models := r.lifecycles[provider]
+	if lifecycle, ok := models[model]; ok {
+		return lifecycle, true
+	}
+
+	bestLen := 0
+	var best Lifecycle
+	for prefix, lifecycle := range models {
+		if len(prefix) > bestLen && strings.HasPrefix(model, prefix) {
+			best = lifecycle
+			bestLen = len(prefix)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// Set adds or replaces the lifecycle of a model prefix
+func (r *Registry) Set(provider types.ProviderType, model string, lifecycle Lifecycle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setLocked(provider, model, lifecycle)
+}
+
+// Merge adds or replaces all lifecycles in table, leaving other entries untouched
+func (r *Registry) Merge(table Table) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for provider, models := range table {
+		for model, lifecycle := range models {
+			r.setLocked(provider, model, lifecycle)
+		}
+	}
+}
+
+// LoadJSON merges lifecycles from a JSON encoded Table
+func (r *Registry) LoadJSON(reader io.Reader) error {
+	var table Table
+	if err := json.NewDecoder(reader).Decode(&table); err != nil {
+		return fmt.Errorf("failed to decode model lifecycle table: %w", err)
+	}
+
+	if err := table.validate(); err != nil {
+		return err
+	}
+
+	r.Merge(table)
+	return nil
+}
+
+// LoadFile merges lifecycles from a JSON file containing a Table
+func (r *Registry) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open model lifecycle file: %w", err)
+	}
+	defer file.Close()
+
+	return r.LoadJSON(file)
+}
+
+// Deprecation returns the deprecation of a model for reporting, or false if
+// none is known
+func (r *Registry) Deprecation(provider types.ProviderType, model string) (types.ModelDeprecation, bool) {
+	lifecycle, ok := r.Lookup(provider, model)
+	if !ok {
+		return types.ModelDeprecation{}, false
+	}
+	return types.ModelDeprecation{
+		Provider:     provider,
+		Model:        model,
+		DeprecatedOn: lifecycle.DeprecatedOn,
+		RetiresOn:    lifecycle.RetiresOn,
+		Replacement:  lifecycle.Replacement,
+	}, true
+}
+
+// setLocked stores a lifecycle; r.mu must be held
+func (r *Registry) setLocked(provider types.ProviderType, model string, lifecycle Lifecycle) {
+	models, ok := r.lifecycles[provider]
+	if !ok {
+		models = make(map[string]Lifecycle)
+		r.lifecycles[provider] = models
+	}
+	models[model] = lifecycle
+}
+
+// validate rejects tables with unknown providers, empty model names or
+// retirements before deprecation
+func (t Table) validate() error {
+	for provider, models := range t {
+		if err := types.ValidateProviderType(provider); err != nil {
+			return fmt.Errorf("invalid model lifecycle table: %w", err)
+		}
+		for model, lifecycle := range models {
+			if strings.TrimSpace(model) == "" {
+				return fmt.Errorf("invalid model lifecycle table: empty model name for provider %s", provider)
+			}
+			if !lifecycle.RetiresOn.IsZero() && lifecycle.RetiresOn.Before(lifecycle.DeprecatedOn) {
+				return fmt.Errorf("invalid model lifecycle table: %s/%s retires before it is deprecated", provider, model)
+			}
+		}
+	}
+	return nil
+}
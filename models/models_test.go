@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+func TestLookup(t *testing.T) {
+	r := NewRegistry()
+
+	tests := []struct {
+		provider    types.ProviderType
+		model       string
+		replacement string
+		found       bool
+	}{
+		{types.ProviderOpenAI, "gpt-4-32k-0613", "gpt-4o", true},
+		{types.ProviderOpenAI, "gpt-3.5-turbo-0613", "gpt-3.5-turbo", true},
+		{types.ProviderAnthropic, "claude-2.1", "claude-3-5-sonnet-20241022", true},
+		{types.ProviderOpenAI, "gpt-4o", "", false},
+		{types.ProviderAnthropic, "claude-3-haiku-20240307", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := r.Lookup(tt.provider, tt.model)
+		if ok != tt.found || got.Replacement != tt.replacement {
+			t.Errorf("Lookup(%s, %s) = %+v, %v; want replacement %q, %v", tt.provider, tt.model, got, ok, tt.replacement, tt.found)
+		}
+	}
+}
+
+func TestDeprecation(t *testing.T) {
+	r := NewRegistry()
+	r.Set(types.ProviderOpenAI, "gpt-4o-2024-05-13", Lifecycle{
+		DeprecatedOn: time.Now().Add(-24 * time.Hour),
+		RetiresOn:    time.Now().Add(30 * 24 * time.Hour),
+		Replacement:  "gpt-4o",
+	})
+
+	deprecation, ok := r.Deprecation(types.ProviderOpenAI, "gpt-4o-2024-05-13")
+	if !ok {
+		t.Fatal("Expected a deprecation for the registered model")
+	}
+	if deprecation.Retired(time.Now()) {
+		t.Error("Expected the model not to be retired yet")
+	}
+	if message := deprecation.String(); !strings.Contains(message, "retires on") || !strings.Contains(message, "migrate to gpt-4o") {
+		t.Errorf("Expected the retirement date and replacement in %q", message)
+	}
+
+	deprecation, _ = r.Deprecation(types.ProviderAnthropic, "claude-instant-1.2")
+	if !deprecation.Retired(time.Now()) || !strings.Contains(deprecation.String(), "retired on 2024-11-06") {
+		t.Errorf("Expected a retired model, got %q", deprecation.String())
+	}
+}
+
+func TestLoadJSONValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"invalid json", `{`},
+		{"unknown provider", `{"cohere": {"command": {"deprecated_on": "2024-01-01T00:00:00Z"}}}`},
+		{"empty model", `{"openai": {"": {"deprecated_on": "2024-01-01T00:00:00Z"}}}`},
+		{"retires before deprecation", `{"openai": {"gpt-4": {"deprecated_on": "2024-06-01T00:00:00Z", "retires_on": "2024-01-01T00:00:00Z"}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			if err := r.LoadJSON(strings.NewReader(tt.json)); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+
+	r := NewRegistry()
+	if err := r.LoadJSON(strings.NewReader(`{"google": {"gemini-pro": {"deprecated_on": "2025-02-15T00:00:00Z", "replacement": "gemini-1.5-flash"}}}`)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lifecycle, ok := r.Lookup(types.ProviderGoogle, "gemini-pro-vision"); !ok || lifecycle.Replacement != "gemini-1.5-flash" {
+		t.Errorf("Expected the loaded lifecycle, got %+v, %v", lifecycle, ok)
+	}
+}
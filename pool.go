@@ -0,0 +1,365 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPoolConcurrency is the number of workers used when
+	// PoolOptions.Concurrency is zero
+	DefaultPoolConcurrency = 4
+
+	// DefaultPoolMaxRetries is the number of retries per job used when
+	// PoolOptions.MaxRetries is zero
+	DefaultPoolMaxRetries = 2
+
+	// DefaultPoolRetryBackoff is the wait before the first retry used when
+	// PoolOptions.RetryBackoff is zero; it doubles with every retry
+	DefaultPoolRetryBackoff = time.Second
+)
+
+// ErrPoolDrained is returned by WorkerPool.Submit after Drain was called
+var ErrPoolDrained = errors.New("worker pool has been drained")
+
+// Job is a single request processed by a WorkerPool.
+type Job struct {
+	// ID identifies the job in results, e.g. a row number or record key
+	ID string `json:"id"`
+
+	// Request is the chat request to send
+	Request ChatRequest `json:"request"`
+}
+
+// JobResult is the outcome of a Job.
+type JobResult struct {
+	// Job is the processed job
+	Job Job `json:"job"`
+
+	// Response is the chat response, nil if the job failed
+	Response *ChatResponse `json:"response,omitempty"`
+
+	// Err is the last error if the job failed
+	Err error `json:"-"`
+
+	// Attempts is the number of requests made, including retries
+	Attempts int `json:"attempts"`
+
+	// Latency is the time spent on the job, including retries
+	Latency time.Duration `json:"latency"`
+
+	// Cost is the estimated cost in USD of the response (zero for unknown prices)
+	Cost float64 `json:"cost_usd"`
+}
+
+// PoolProgress is a snapshot of a WorkerPool's progress.
+type PoolProgress struct {
+	// Submitted is the number of jobs accepted by Submit
+	Submitted int `json:"submitted"`
+
+	// Completed is the number of jobs that finished, successfully or not
+	Completed int `json:"completed"`
+
+	// Succeeded is the number of jobs that produced a response
+	Succeeded int `json:"succeeded"`
+
+	// Failed is the number of jobs that failed after all retries
+	Failed int `json:"failed"`
+}
+
+// PoolReport aggregates the results of all jobs processed by a WorkerPool.
+type PoolReport struct {
+	PoolProgress
+
+	// Retries is the total number of retried requests
+	Retries int `json:"retries"`
+
+	// Usage is the total token usage of successful jobs
+	Usage Usage `json:"usage"`
+
+	// Cost is the total estimated cost in USD of successful jobs
+	Cost float64 `json:"cost_usd"`
+
+	// Duration is the time from pool creation until Drain returned
+	Duration time.Duration `json:"duration"`
+}
+
+// PoolOptions configures a WorkerPool.
+type PoolOptions struct {
+	// Concurrency is the number of jobs processed in parallel
+	// (default: DefaultPoolConcurrency)
+	Concurrency int
+
+	// QueueSize is the number of submitted jobs buffered before Submit blocks
+	// (default: twice the concurrency)
+	QueueSize int
+
+	// MaxRetries is the number of retries per job for retryable errors
+	// (default: DefaultPoolMaxRetries, negative disables retries)
+	MaxRetries int
+
+	// RetryBackoff is the wait before the first retry, doubling with every
+	// retry (default: DefaultPoolRetryBackoff). A longer rate limit
+	// RetryAfter takes precedence.
+	RetryBackoff time.Duration
+
+	// ShouldRetry reports whether a failed attempt is retried
+	// (default: errors whose IsRetryable method reports true)
+	ShouldRetry func(err error) bool
+
+	// OnResult is called with each job result (optional)
+	// It is called from worker goroutines and must be safe for concurrent use
+	OnResult func(JobResult)
+
+	// OnProgress is called after each completed job (optional)
+	// Calls are serialized
+	OnProgress func(PoolProgress)
+}
+
+// poolItem is a queued job with the context it was submitted with
+type poolItem struct {
+	ctx context.Context
+	job Job
+}
+
+// WorkerPool processes chat requests with bounded concurrency.
+//
+// Jobs are queued with Submit and processed by a fixed number of workers,
+// retrying retryable failures with exponential backoff. Drain waits for all
+// submitted jobs and returns the aggregated report. WorkerPool is safe for
+// concurrent use.
+type WorkerPool struct {
+	client Client
+	opts   PoolOptions
+	start  time.Time
+	queue  chan poolItem
+	wg     sync.WaitGroup
+
+	queueMu sync.RWMutex // Guards drained and closing the queue
+	drained bool
+
+	mu       sync.Mutex // Guards report
+	report   PoolReport
+	progress sync.Mutex // Serializes OnProgress calls
+}
+
+// NewWorkerPool creates a worker pool sending requests with client and starts its workers.
+//
+// Example:
+//
+//	pool := NewWorkerPool(client, PoolOptions{
+//		Concurrency: 8,
+//		OnResult: func(r JobResult) {
+//			if r.Err != nil {
+//				log.Printf("%s failed: %v", r.Job.ID, r.Err)
+//			}
+//		},
+//	})
+//	for i, prompt := range prompts {
+//		err := pool.Submit(ctx, Job{
+//			ID:      strconv.Itoa(i),
+//			Request: ChatRequest{Messages: []Message{{Role: "user", Content: prompt}}},
+//		})
+//		if err != nil {
+//			break
+//		}
+//	}
+//	report := pool.Drain()
+//	fmt.Printf("%d succeeded, %d failed, $%.2f\n", report.Succeeded, report.Failed, report.Cost)
+//
+// Parameters:
+//   - client: The client used to send requests
+//   - opts: Concurrency, retry and callback options
+//
+// Returns:
+//   - *WorkerPool: The running pool; call Drain when all jobs are submitted
+func NewWorkerPool(client Client, opts PoolOptions) *WorkerPool {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultPoolConcurrency
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 2 * opts.Concurrency
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = DefaultPoolMaxRetries
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultPoolRetryBackoff
+	}
+	if opts.ShouldRetry == nil {
+		opts.ShouldRetry = isRetryableError
+	}
+
+	p := &WorkerPool{
+		client: client,
+		opts:   opts,
+		start:  time.Now(),
+		queue:  make(chan poolItem, opts.QueueSize),
+	}
+
+	p.wg.Add(opts.Concurrency)
+	for i := 0; i < opts.Concurrency; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// Submit queues a job, blocking while the queue is full. The job is
+// processed with ctx. It returns ErrPoolDrained after Drain, or the context
+// error if ctx is done before the job could be queued.
+func (p *WorkerPool) Submit(ctx context.Context, job Job) error {
+	// Hold the read lock while sending so Drain cannot close the queue
+	p.queueMu.RLock()
+	defer p.queueMu.RUnlock()
+
+	if p.drained {
+		return ErrPoolDrained
+	}
+
+	select {
+	case p.queue <- poolItem{ctx: ctx, job: job}:
+		p.mu.Lock()
+		p.report.Submitted++
+		p.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SubmitAll submits every job received from jobs until the channel is
+// closed, returning the first Submit error
+func (p *WorkerPool) SubmitAll(ctx context.Context, jobs <-chan Job) error {
+	for {
+		select {
+		case job, ok := <-jobs:
+			if !ok {
+				return nil
+			}
+			if err := p.Submit(ctx, job); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Progress returns the current progress
+func (p *WorkerPool) Progress() PoolProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.report.PoolProgress
+}
+
+// Drain stops accepting jobs, waits for all submitted jobs to finish and
+// returns the aggregated report. Calling Drain again returns the same report.
+func (p *WorkerPool) Drain() PoolReport {
+	p.queueMu.Lock()
+	if !p.drained {
+		p.drained = true
+		close(p.queue)
+	}
+	p.queueMu.Unlock()
+
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.report.Duration == 0 {
+		p.report.Duration = time.Since(p.start)
+	}
+	return p.report
+}
+
+// worker processes queued jobs until the queue is closed
+func (p *WorkerPool) worker() {
+	defer p.wg.Done()
+	for item := range p.queue {
+		p.record(p.process(item.ctx, item.job))
+	}
+}
+
+// process runs a job with retries
+func (p *WorkerPool) process(ctx context.Context, job Job) JobResult {
+	result := JobResult{Job: job}
+	start := time.Now()
+	backoff := p.opts.RetryBackoff
+
+	for {
+		result.Attempts++
+		resp, err := p.client.ChatComplete(ctx, job.Request)
+		if err == nil {
+			result.Response = resp
+			result.Err = nil
+			result.Cost = ResponseCost(p.client, resp.Metadata, resp.Usage)
+			break
+		}
+		result.Err = err
+
+		if result.Attempts > p.opts.MaxRetries || !p.opts.ShouldRetry(err) || ctx.Err() != nil {
+			break
+		}
+
+		wait := backoff
+		var aiErr *Error
+		if errors.As(err, &aiErr) && aiErr.RetryAfter != nil {
+			if retryAfter := time.Duration(*aiErr.RetryAfter) * time.Second; retryAfter > wait {
+				wait = retryAfter
+			}
+		}
+		if sleepErr := sleepContext(ctx, wait); sleepErr != nil {
+			break
+		}
+		backoff *= 2
+	}
+
+	result.Latency = time.Since(start)
+	return result
+}
+
+// record adds a result to the report and reports it to the callbacks
+func (p *WorkerPool) record(result JobResult) {
+	p.mu.Lock()
+	p.report.Completed++
+	p.report.Retries += result.Attempts - 1
+	if result.Err != nil {
+		p.report.Failed++
+	} else {
+		p.report.Succeeded++
+		p.report.Usage = addUsage(p.report.Usage, result.Response.Usage)
+		p.report.Cost += result.Cost
+	}
+	progress := p.report.PoolProgress
+	p.mu.Unlock()
+
+	if p.opts.OnResult != nil {
+		p.opts.OnResult(result)
+	}
+	if p.opts.OnProgress != nil {
+		p.progress.Lock()
+		p.opts.OnProgress(progress)
+		p.progress.Unlock()
+	}
+}
+
+// isRetryableError reports whether err has an IsRetryable method reporting true
+func isRetryableError(err error) bool {
+	var retryable interface{ IsRetryable() bool }
+	return errors.As(err, &retryable) && retryable.IsRetryable()
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/pricing"
+)
+
+// flakyAdapter fails prompts containing "flaky" until their third attempt and
+// prompts containing "bad" always, tracking the peak number of concurrent requests
+type flakyAdapter struct {
+	mockAdapter
+	mu       sync.Mutex
+	attempts map[string]int
+	active   int32
+	peak     int32
+}
+
+func (f *flakyAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	active := atomic.AddInt32(&f.active, 1)
+	defer atomic.AddInt32(&f.active, -1)
+	for {
+		peak := atomic.LoadInt32(&f.peak)
+		if active <= peak || atomic.CompareAndSwapInt32(&f.peak, peak, active) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	prompt := req.Messages[0].Content
+	f.mu.Lock()
+	f.attempts[prompt]++
+	attempt := f.attempts[prompt]
+	f.mu.Unlock()
+
+	switch {
+	case strings.Contains(prompt, "bad"):
+		return nil, NewError(ErrorTypeValidation, "anthropic", "invalid request")
+	case strings.Contains(prompt, "flaky") && attempt < 3:
+		return nil, NewError(ErrorTypeNetwork, "anthropic", "connection reset")
+	}
+	return &ChatResponse{
+		Message:  Message{Role: "assistant", Content: "ok"},
+		Usage:    Usage{PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000},
+		Metadata: ResponseMetadata{Provider: "anthropic", Model: "claude-3-haiku-20240307"},
+	}, nil
+}
+
+func TestWorkerPool(t *testing.T) {
+	adapter := &flakyAdapter{attempts: map[string]int{}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.pricing = pricing.Default()
+
+	var progress []PoolProgress
+	var failed []string
+	var resultsMu sync.Mutex
+	pool := NewWorkerPool(c, PoolOptions{
+		Concurrency:  3,
+		RetryBackoff: time.Millisecond,
+		OnResult: func(r JobResult) {
+			if r.Err != nil {
+				resultsMu.Lock()
+				failed = append(failed, r.Job.ID)
+				resultsMu.Unlock()
+			}
+		},
+		OnProgress: func(p PoolProgress) { progress = append(progress, p) },
+	})
+
+	prompts := []string{"one", "two", "flaky", "bad", "three", "four", "five", "six"}
+	for _, prompt := range prompts {
+		err := pool.Submit(context.Background(), Job{
+			ID:      prompt,
+			Request: ChatRequest{Messages: []Message{{Role: "user", Content: prompt}}},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected submit error: %v", err)
+		}
+	}
+	report := pool.Drain()
+
+	if report.Submitted != 8 || report.Completed != 8 || report.Succeeded != 7 || report.Failed != 1 {
+		t.Errorf("Unexpected counts: %+v", report.PoolProgress)
+	}
+	if report.Retries != 2 {
+		t.Errorf("Expected 2 retries for the flaky job, got %d", report.Retries)
+	}
+	if report.Usage.TotalTokens != 14000 {
+		t.Errorf("Expected 14000 total tokens, got %d", report.Usage.TotalTokens)
+	}
+	if report.Cost <= 0 {
+		t.Errorf("Expected a positive cost, got %f", report.Cost)
+	}
+	if len(failed) != 1 || failed[0] != "bad" {
+		t.Errorf("Expected only the bad job to fail, got %v", failed)
+	}
+	if adapter.attempts["bad"] != 1 {
+		t.Errorf("Expected no retries for non-retryable errors, got %d attempts", adapter.attempts["bad"])
+	}
+	if peak := atomic.LoadInt32(&adapter.peak); peak > 3 {
+		t.Errorf("Expected at most 3 concurrent requests, got %d", peak)
+	}
+	if len(progress) != 8 || progress[7].Completed != 8 {
+		t.Errorf("Expected a progress callback per job, got %+v", progress)
+	}
+
+	if err := pool.Submit(context.Background(), Job{}); !errors.Is(err, ErrPoolDrained) {
+		t.Errorf("Expected ErrPoolDrained after Drain, got %v", err)
+	}
+	if again := pool.Drain(); again != report {
+		t.Errorf("Expected repeated Drain to return the same report")
+	}
+}
+
+func TestWorkerPool_SubmitAll(t *testing.T) {
+	adapter := &flakyAdapter{attempts: map[string]int{}}
+	pool := NewWorkerPool(newMockClient(ProviderAnthropic, adapter), PoolOptions{MaxRetries: -1})
+
+	jobs := make(chan Job)
+	go func() {
+		defer close(jobs)
+		for _, prompt := range []string{"a", "flaky", "b"} {
+			jobs <- Job{ID: prompt, Request: ChatRequest{Messages: []Message{{Role: "user", Content: prompt}}}}
+		}
+	}()
+	if err := pool.SubmitAll(context.Background(), jobs); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report := pool.Drain()
+	if report.Succeeded != 2 || report.Failed != 1 || report.Retries != 0 {
+		t.Errorf("Expected the flaky job to fail without retries, got %+v", report)
+	}
+}
@@ -0,0 +1,309 @@
+// Package pricing provides per-model token prices for cost tracking.
+//
+// Prices are expressed in USD per 1,000 tokens, separately for input (prompt)
+// and output (completion) tokens. The default registry ships with list prices
+// for common OpenAI, Anthropic and Google models. Model names are matched by
+// longest prefix, so dated snapshots such as "gpt-4o-2024-08-06" resolve to
+// the "gpt-4o" entry.
+//
+// Prices change over time, so the registry supports user overrides (Set,
+// LoadFile) and refreshing from an external source (Refresh, AutoRefresh).
+//
+// Example:
+//
+//	price, ok := pricing.Default().Lookup(types.ProviderOpenAI, "gpt-4o-mini")
+//	cost := pricing.Default().Cost(types.ProviderOpenAI, "gpt-4o-mini", resp.Usage)
+//
+//	// Override with negotiated prices
+//	if err := pricing.Default().LoadFile("prices.json"); err != nil {
+//		log.Fatal(err)
+//	}
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// Currency is the currency all prices are expressed in
+const Currency = "USD"
+
+// Price is the cost of a model's tokens.
+type Price struct {
+	// InputPer1K is the cost in USD per 1,000 prompt tokens
+	InputPer1K float64 `json:"input_per_1k"`
+
+	// OutputPer1K is the cost in USD per 1,000 completion tokens
+	OutputPer1K float64 `json:"output_per_1k"`
+}
+
+// Cost returns the cost in USD of the given token counts
+func (p Price) Cost(promptTokens, completionTokens int) float64 {
+	return float64(promptTokens)/1000*p.InputPer1K + float64(completionTokens)/1000*p.OutputPer1K
+}
+
+// Table maps providers to model prefixes to prices.
+//
+// Its JSON form is used by LoadJSON, LoadFile and HTTPSource:
+//
+//	{
+//	  "openai": {"gpt-4o": {"input_per_1k": 0.0025, "output_per_1k": 0.01}},
+//	  "anthropic": {"claude-3-haiku": {"input_per_1k": 0.00025, "output_per_1k": 0.00125}}
+//	}
+type Table map[types.ProviderType]map[string]Price
+
+// defaultPrices contains list prices per provider and model prefix
+var defaultPrices = Table{
+	types.ProviderOpenAI: {
+		"gpt-4o":                 {InputPer1K: 0.0025, OutputPer1K: 0.01},
+		"gpt-4o-mini":            {InputPer1K: 0.00015, OutputPer1K: 0.0006},
+		"gpt-4-turbo":            {InputPer1K: 0.01, OutputPer1K: 0.03},
+		"gpt-4":                  {InputPer1K: 0.03, OutputPer1K: 0.06},
+		"gpt-4-32k":              {InputPer1K: 0.06, OutputPer1K: 0.12},
+		"gpt-3.5-turbo":          {InputPer1K: 0.0005, OutputPer1K: 0.0015},
+		"gpt-3.5-turbo-instruct": {InputPer1K: 0.0015, OutputPer1K: 0.002},
+	},
+	types.ProviderAnthropic: {
+		"claude-3-5-sonnet":  {InputPer1K: 0.003, OutputPer1K: 0.015},
+		"claude-3-5-haiku":   {InputPer1K: 0.0008, OutputPer1K: 0.004},
+		"claude-3-opus":      {InputPer1K: 0.015, OutputPer1K: 0.075},
+		"claude-3-sonnet":    {InputPer1K: 0.003, OutputPer1K: 0.015},
+		"claude-3-haiku":     {InputPer1K: 0.00025, OutputPer1K: 0.00125},
+		"claude-2":           {InputPer1K: 0.008, OutputPer1K: 0.024},
+		"claude-instant-1.2": {InputPer1K: 0.0008, OutputPer1K: 0.0024},
+	},
+	types.ProviderGoogle: {
+		"gemini-1.5-pro":   {InputPer1K: 0.00125, OutputPer1K: 0.005},
+		"gemini-1.5-flash": {InputPer1K: 0.000075, OutputPer1K: 0.0003},
+		"gemini-pro":       {InputPer1K: 0.0005, OutputPer1K: 0.0015},
+	},
+}
+
+// Registry maps provider models to prices.
+//
+// Registry is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	prices map[types.ProviderType]map[string]Price
+}
+
+// NewRegistry creates a registry populated with the default price table
+func NewRegistry() *Registry {
+	r := &Registry{prices: make(map[types.ProviderType]map[string]Price)}
+	r.Merge(defaultPrices)
+	return r
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// Default returns the shared registry used by package-level helpers
+func Default() *Registry {
+	defaultRegistryOnce.Do(func() {
+		defaultRegistry = NewRegistry()
+	})
+	return defaultRegistry
+}
+
+// Lookup returns the price for a model, matching the longest known model
+// prefix. It reports false if no price is known.
+func (r *Registry) Lookup(provider types.ProviderType, model string) (Price, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	models := r.prices[provider]
+	if price, ok := models[model]; ok {
+		return price, true
+	}
+
+	bestLen := 0
+	var best Price
+	for prefix, price := range models {
+		if len(prefix) > bestLen && strings.HasPrefix(model, prefix) {
+			best = price
+			bestLen = len(prefix)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// Cost returns the cost in USD of a request's usage, or zero if the model's
+// price is unknown. It can be used directly as a usage.CostFunc.
+func (r *Registry) Cost(provider types.ProviderType, model string, usage types.Usage) float64 {
+	price, ok := r.Lookup(provider, model)
+	if !ok {
+		return 0
+	}
+	return price.Cost(usage.PromptTokens, usage.CompletionTokens)
+}
+
+// Set adds or replaces the price for a model prefix
+func (r *Registry) Set(provider types.ProviderType, model string, price Price) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setLocked(provider, model, price)
+}
+
+// Merge adds or replaces all prices in table, leaving other entries untouched
+func (r *Registry) Merge(table Table) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for provider, models := range table {
+		for model, price := range models {
+			r.setLocked(provider, model, price)
+		}
+	}
+}
+
+// Table returns a copy of all known prices
+func (r *Registry) Table() Table {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	table := make(Table, len(r.prices))
+	for provider, models := range r.prices {
+		table[provider] = make(map[string]Price, len(models))
+		for model, price := range models {
+			table[provider][model] = price
+		}
+	}
+	return table
+}
+
+// Clone returns an independent copy of the registry
+func (r *Registry) Clone() *Registry {
+	clone := &Registry{prices: make(map[types.ProviderType]map[string]Price)}
+	clone.Merge(r.Table())
+	return clone
+}
+
+// LoadJSON merges prices from a JSON encoded Table
+func (r *Registry) LoadJSON(reader io.Reader) error {
+	var table Table
+	if err := json.NewDecoder(reader).Decode(&table); err != nil {
+		return fmt.Errorf("failed to decode pricing table: %w", err)
+	}
+
+	if err := table.validate(); err != nil {
+		return err
+	}
+
+	r.Merge(table)
+	return nil
+}
+
+// LoadFile merges prices from a JSON file containing a Table
+func (r *Registry) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open pricing file: %w", err)
+	}
+	defer file.Close()
+
+	return r.LoadJSON(file)
+}
+
+// RefreshFunc fetches up-to-date prices from an external source
+type RefreshFunc func(ctx context.Context) (Table, error)
+
+// Refresh fetches prices with fn and merges them into the registry.
+// On error the registry is left unchanged.
+func (r *Registry) Refresh(ctx context.Context, fn RefreshFunc) error {
+	table, err := fn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh pricing: %w", err)
+	}
+
+	if err := table.validate(); err != nil {
+		return err
+	}
+
+	r.Merge(table)
+	return nil
+}
+
+// AutoRefresh calls Refresh every interval in a background goroutine until
+// ctx is cancelled. Errors are passed to onError if it is not nil.
+func (r *Registry) AutoRefresh(ctx context.Context, interval time.Duration, fn RefreshFunc, onError func(error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.Refresh(ctx, fn); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// HTTPSource returns a RefreshFunc that downloads a JSON encoded Table from url
+func HTTPSource(url string) RefreshFunc {
+	return func(ctx context.Context) (Table, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch pricing table: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("pricing source returned status %d", resp.StatusCode)
+		}
+
+		var table Table
+		if err := json.NewDecoder(resp.Body).Decode(&table); err != nil {
+			return nil, fmt.Errorf("failed to decode pricing table: %w", err)
+		}
+		return table, nil
+	}
+}
+
+// setLocked stores a price; r.mu must be held
+func (r *Registry) setLocked(provider types.ProviderType, model string, price Price) {
+	models, ok := r.prices[provider]
+	if !ok {
+		models = make(map[string]Price)
+		r.prices[provider] = models
+	}
+	models[model] = price
+}
+
+// validate rejects tables with unknown providers or negative prices
+func (t Table) validate() error {
+	for provider, models := range t {
+		if err := types.ValidateProviderType(provider); err != nil {
+			return fmt.Errorf("invalid pricing table: %w", err)
+		}
+		for model, price := range models {
+			if strings.TrimSpace(model) == "" {
+				return fmt.Errorf("invalid pricing table: empty model name for provider %s", provider)
+			}
+			if price.InputPer1K < 0 || price.OutputPer1K < 0 {
+				return fmt.Errorf("invalid pricing table: negative price for %s/%s", provider, model)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+func TestLookup(t *testing.T) {
+	r := NewRegistry()
+
+	tests := []struct {
+		provider types.ProviderType
+		model    string
+		want     Price
+		found    bool
+	}{
+		{types.ProviderOpenAI, "gpt-4o", Price{0.0025, 0.01}, true},
+		{types.ProviderOpenAI, "gpt-4o-mini-2024-07-18", Price{0.00015, 0.0006}, true},
+		{types.ProviderOpenAI, "gpt-4-0613", Price{0.03, 0.06}, true},
+		{types.ProviderAnthropic, "claude-3-haiku-20240307", Price{0.00025, 0.00125}, true},
+		{types.ProviderAnthropic, "gpt-4", Price{}, false},
+		{types.ProviderOpenAI, "unknown", Price{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := r.Lookup(tt.provider, tt.model)
+		if ok != tt.found || got != tt.want {
+			t.Errorf("Lookup(%s, %s) = %+v, %v; want %+v, %v", tt.provider, tt.model, got, ok, tt.want, tt.found)
+		}
+	}
+}
+
+func TestCost(t *testing.T) {
+	r := NewRegistry()
+
+	cost := r.Cost(types.ProviderOpenAI, "gpt-4", types.Usage{PromptTokens: 1000, CompletionTokens: 500})
+	if math.Abs(cost-0.06) > 1e-9 {
+		t.Errorf("Expected cost 0.06, got %f", cost)
+	}
+
+	if cost := r.Cost(types.ProviderOpenAI, "unknown", types.Usage{PromptTokens: 1000}); cost != 0 {
+		t.Errorf("Expected zero cost for unknown model, got %f", cost)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	r := NewRegistry()
+
+	r.Set(types.ProviderOpenAI, "ft:gpt-4o-mini", Price{InputPer1K: 0.0003, OutputPer1K: 0.0012})
+	if price, ok := r.Lookup(types.ProviderOpenAI, "ft:gpt-4o-mini:acme::abc123"); !ok || price.InputPer1K != 0.0003 {
+		t.Errorf("Expected fine-tuned price override, got %+v, %v", price, ok)
+	}
+
+	err := r.LoadJSON(strings.NewReader(`{"anthropic": {"claude-3-haiku": {"input_per_1k": 0.0002, "output_per_1k": 0.001}}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if price, _ := r.Lookup(types.ProviderAnthropic, "claude-3-haiku-20240307"); price.InputPer1K != 0.0002 {
+		t.Errorf("Expected JSON override to apply, got %+v", price)
+	}
+
+	// Overrides on a clone do not affect the original
+	clone := r.Clone()
+	clone.Set(types.ProviderOpenAI, "gpt-4", Price{InputPer1K: 1, OutputPer1K: 1})
+	if price, _ := r.Lookup(types.ProviderOpenAI, "gpt-4"); price.InputPer1K != 0.03 {
+		t.Errorf("Expected original registry to be unchanged, got %+v", price)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	content := `{"google": {"gemini-2.0-flash": {"input_per_1k": 0.0001, "output_per_1k": 0.0004}}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write pricing file: %v", err)
+	}
+
+	r := NewRegistry()
+	if err := r.LoadFile(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := r.Lookup(types.ProviderGoogle, "gemini-2.0-flash-001"); !ok {
+		t.Errorf("Expected model from pricing file to be known")
+	}
+
+	if err := r.LoadFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("Expected error for missing file")
+	}
+}
+
+func TestLoadJSONValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"invalid json", `{`},
+		{"unknown provider", `{"cohere": {"command": {"input_per_1k": 0.001, "output_per_1k": 0.002}}}`},
+		{"negative price", `{"openai": {"gpt-4": {"input_per_1k": -1, "output_per_1k": 0.002}}}`},
+		{"empty model", `{"openai": {"": {"input_per_1k": 0.001, "output_per_1k": 0.002}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			if err := r.LoadJSON(strings.NewReader(tt.json)); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	r := NewRegistry()
+
+	failing := func(ctx context.Context) (Table, error) {
+		return nil, errors.New("source unavailable")
+	}
+	if err := r.Refresh(context.Background(), failing); err == nil {
+		t.Errorf("Expected refresh error")
+	}
+	if price, _ := r.Lookup(types.ProviderOpenAI, "gpt-4"); price.InputPer1K != 0.03 {
+		t.Errorf("Expected registry unchanged after failed refresh, got %+v", price)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"openai": {"gpt-4": {"input_per_1k": 0.02, "output_per_1k": 0.04}}}`))
+	}))
+	defer server.Close()
+
+	if err := r.Refresh(context.Background(), HTTPSource(server.URL)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if price, _ := r.Lookup(types.ProviderOpenAI, "gpt-4"); price.InputPer1K != 0.02 {
+		t.Errorf("Expected refreshed price, got %+v", price)
+	}
+}
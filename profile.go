@@ -0,0 +1,136 @@
+package aiprovider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegisterProfile adds or replaces a named request profile.
+//
+// Requests select the profile with their Profile field. Fields set on the
+// request take precedence over the profile, and the profile takes precedence
+// over client-wide config defaults.
+//
+// Example:
+//
+//	err := client.RegisterProfile("creative-writing", RequestProfile{
+//		Model:        "claude-3-5-sonnet-20241022",
+//		Temperature:  &[]float64{0.9}[0],
+//		SystemPrompt: "You are an imaginative fiction writer.",
+//	})
+//
+//	resp, err := client.ChatComplete(ctx, ChatRequest{
+//		Profile:  "creative-writing",
+//		Messages: []Message{{Role: "user", Content: "Write an opening line."}},
+//	})
+//
+// Parameters:
+//   - name: The profile name referenced by requests
+//   - profile: The request defaults
+//
+// Returns:
+//   - error: A validation error if the name is empty or the profile is invalid
+func (c *client) RegisterProfile(name string, profile RequestProfile) error {
+	if strings.TrimSpace(name) == "" {
+		return &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "request profile name cannot be empty",
+			Provider: string(c.provider),
+		}
+	}
+	if err := profile.Validate(); err != nil {
+		return &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("invalid request profile %q: %v", name, err),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.profiles == nil {
+		c.profiles = make(map[string]RequestProfile)
+	}
+	c.profiles[name] = profile
+	return nil
+}
+
+// profile looks up a registered profile by name
+func (c *client) profile(name string) (RequestProfile, error) {
+	c.mu.RLock()
+	profile, ok := c.profiles[name]
+	c.mu.RUnlock()
+
+	if !ok {
+		return RequestProfile{}, fmt.Errorf("unknown request profile %q", name)
+	}
+	return profile, nil
+}
+
+// applyCompletionProfile fills unset request fields from the selected profile
+func (c *client) applyCompletionProfile(req CompletionRequest) (CompletionRequest, error) {
+	if req.Profile == "" {
+		return req, nil
+	}
+	profile, err := c.profile(req.Profile)
+	if err != nil {
+		return req, err
+	}
+
+	if req.Model == "" {
+		req.Model = profile.Model
+	}
+	if req.Temperature == nil && profile.Temperature != nil {
+		temperature := *profile.Temperature
+		req.Temperature = &temperature
+	}
+	if req.MaxTokens == nil && profile.MaxTokens != nil {
+		maxTokens := *profile.MaxTokens
+		req.MaxTokens = &maxTokens
+	}
+	if len(req.Stop) == 0 && len(profile.Stop) > 0 {
+		req.Stop = append([]string(nil), profile.Stop...)
+	}
+	if profile.SystemPrompt != "" {
+		req.Prompt = profile.SystemPrompt + "\n\n" + req.Prompt
+	}
+	return req, nil
+}
+
+// applyChatProfile fills unset request fields from the selected profile
+func (c *client) applyChatProfile(req ChatRequest) (ChatRequest, error) {
+	if req.Profile == "" {
+		return req, nil
+	}
+	profile, err := c.profile(req.Profile)
+	if err != nil {
+		return req, err
+	}
+
+	if req.Model == "" {
+		req.Model = profile.Model
+	}
+	if req.Temperature == nil && profile.Temperature != nil {
+		temperature := *profile.Temperature
+		req.Temperature = &temperature
+	}
+	if req.MaxTokens == nil && profile.MaxTokens != nil {
+		maxTokens := *profile.MaxTokens
+		req.MaxTokens = &maxTokens
+	}
+	if profile.SystemPrompt != "" && !hasSystemMessage(req.Messages) {
+		req.Messages = append([]Message{{Role: "system", Content: profile.SystemPrompt}}, req.Messages...)
+	}
+	return req, nil
+}
+
+// hasSystemMessage reports whether messages contain a system message
+func hasSystemMessage(messages []Message) bool {
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			return true
+		}
+	}
+	return false
+}
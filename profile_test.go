@@ -0,0 +1,113 @@
+package aiprovider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequestProfiles(t *testing.T) {
+	adapter := &mockAdapter{
+		completeResp: &CompletionResponse{Text: "ok"},
+		chatResp:     &ChatResponse{Message: Message{Role: "assistant", Content: "ok"}},
+	}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.Temperature = floatPtr(0.2)
+
+	err := c.RegisterProfile("creative-writing", RequestProfile{
+		Model:        "claude-3-5-sonnet-20241022",
+		Temperature:  floatPtr(0.9),
+		MaxTokens:    intPtr(500),
+		SystemPrompt: "You are a novelist.",
+		Stop:         []string{"THE END"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	_, err = c.ChatComplete(ctx, ChatRequest{
+		Profile:   "creative-writing",
+		Messages:  []Message{{Role: "user", Content: "Begin."}},
+		MaxTokens: intPtr(100),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	chat := adapter.chatRequests[0]
+	if chat.Model != "claude-3-5-sonnet-20241022" || *chat.Temperature != 0.9 {
+		t.Errorf("Expected profile model and temperature, got %q and %v", chat.Model, *chat.Temperature)
+	}
+	if *chat.MaxTokens != 100 {
+		t.Errorf("Expected request max tokens to take precedence, got %d", *chat.MaxTokens)
+	}
+	if len(chat.Messages) != 2 || chat.Messages[0].Content != "You are a novelist." {
+		t.Errorf("Expected profile system prompt, got %+v", chat.Messages)
+	}
+
+	// An existing system message is kept
+	_, err = c.ChatComplete(ctx, ChatRequest{
+		Profile: "creative-writing",
+		Messages: []Message{
+			{Role: "system", Content: "Write poems."},
+			{Role: "user", Content: "Begin."},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if messages := adapter.chatRequests[1].Messages; len(messages) != 2 || messages[0].Content != "Write poems." {
+		t.Errorf("Expected request system message to take precedence, got %+v", messages)
+	}
+
+	if _, err := c.Complete(ctx, CompletionRequest{Profile: "creative-writing", Prompt: "Once upon a time"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	completion := adapter.completeRequests[0]
+	if completion.Prompt != "You are a novelist.\n\nOnce upon a time" || len(completion.Stop) != 1 || *completion.MaxTokens != 500 {
+		t.Errorf("Expected profile applied to completion, got %+v", completion)
+	}
+
+	// Without a profile, config defaults apply
+	if _, err := c.Complete(ctx, CompletionRequest{Prompt: "Hi"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if temperature := adapter.completeRequests[1].Temperature; temperature == nil || *temperature != 0.2 {
+		t.Errorf("Expected config temperature without a profile, got %v", temperature)
+	}
+
+	_, err = c.Complete(ctx, CompletionRequest{Profile: "missing", Prompt: "Hi"})
+	if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+		t.Errorf("Expected validation error for unknown profile, got %v", err)
+	}
+}
+
+func TestRegisterProfile_Validation(t *testing.T) {
+	c := newMockClient(ProviderAnthropic, &mockAdapter{})
+
+	if err := c.RegisterProfile(" ", RequestProfile{}); err == nil {
+		t.Errorf("Expected error for empty name")
+	}
+	if err := c.RegisterProfile("hot", RequestProfile{Temperature: floatPtr(3)}); err == nil {
+		t.Errorf("Expected error for invalid temperature")
+	}
+}
+
+func TestNewClient_Profiles(t *testing.T) {
+	config := Config{
+		APIKey:   "sk-ant-REDACTED",
+		Profiles: map[string]RequestProfile{"extraction": {Temperature: floatPtr(0)}},
+	}
+	c, err := NewClient(ProviderAnthropic, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.(*client).profile("extraction"); err != nil {
+		t.Errorf("Expected config profile to be registered: %v", err)
+	}
+
+	config.Profiles = map[string]RequestProfile{"broken": {MaxTokens: intPtr(0)}}
+	if _, err := NewClient(ProviderAnthropic, config); err == nil {
+		t.Errorf("Expected error for invalid config profile")
+	}
+}
@@ -0,0 +1,44 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+)
+
+// withProject resolves the OpenAI project of a request, its own or else the
+// one selected with WithProject, and returns a context carrying it for the
+// adapter. Projects without a key in Config.ProjectKeys are rejected.
+func (c *client) withProject(ctx context.Context, project string) (context.Context, error) {
+	if project == "" {
+		project = ProjectFromContext(ctx)
+		if project == "" {
+			return ctx, nil
+		}
+	}
+	if _, ok := c.config.ProjectKeys[project]; !ok {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("no API key configured for project %q", project),
+			Provider: string(c.provider),
+		}
+	}
+	return WithProject(ctx, project), nil
+}
+
+// checkEndpoint rejects the endpoint selected with WithEndpoint, if any, when
+// Config.AllowedBaseURLs or Config.AllowedHeaders do not allow it
+func (c *client) checkEndpoint(ctx context.Context) error {
+	endpoint, ok := EndpointFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if err := c.config.CheckEndpoint(endpoint); err != nil {
+		return &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("endpoint override rejected: %v", err),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package aiprovider
+
+import (
+	"context"
+	"testing"
+)
+
+// projectAdapter is a mock adapter recording the project of each request
+type projectAdapter struct {
+	*mockAdapter
+	projects []string
+}
+
+func (p *projectAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.projects = append(p.projects, ProjectFromContext(ctx))
+	return p.mockAdapter.ChatComplete(ctx, req)
+}
+
+func TestChatComplete_Project(t *testing.T) {
+	adapter := &projectAdapter{mockAdapter: &mockAdapter{chatResp: &ChatResponse{}}}
+	client := newMockClient(ProviderOpenAI, adapter)
+	client.config = DefaultConfig().
+		WithProjectKey("proj_search", "sk-search-key-1234567890").
+		WithProjectKey("proj_support", "sk-support-key-1234567890")
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+	ctx := WithProject(context.Background(), "proj_search")
+	if _, err := client.ChatComplete(ctx, req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	req.Project = "proj_support"
+	if _, err := client.ChatComplete(ctx, req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	req.Project = ""
+	if _, err := client.ChatComplete(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"proj_search", "proj_support", ""}
+	for i, project := range want {
+		if adapter.projects[i] != project {
+			t.Errorf("Request %d: expected project %q, got %q", i, project, adapter.projects[i])
+		}
+	}
+
+	req.Project = "proj_unknown"
+	_, err := client.ChatComplete(context.Background(), req)
+	if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+		t.Errorf("Expected a validation error for an unknown project, got %v", err)
+	}
+	if len(adapter.projects) != 3 {
+		t.Errorf("Expected no provider call for an unknown project")
+	}
+}
+
+func TestConfigValidate_ProjectKeys(t *testing.T) {
+	config := DefaultConfig().WithAPIKey("sk-default-key-1234567890").WithProjectKey("proj_a", "sk-project-key-1234567890")
+	if err := config.Validate(ProviderOpenAI); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := config.WithProjectKey("proj_b", "bad").Validate(ProviderOpenAI); err == nil {
+		t.Errorf("Expected an error for an invalid project key")
+	}
+	anthropic := DefaultConfig().WithAPIKey("sk-ant-REDACTED").WithProjectKey("proj_a", "sk-ant-project-key-123456")
+	if err := anthropic.Validate(ProviderAnthropic); err == nil {
+		t.Errorf("Expected project keys to be rejected for anthropic")
+	}
+}
+
+func TestChatComplete_EndpointRejected(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{}}
+	client := newMockClient(ProviderOpenAI, adapter)
+	client.config.AllowedBaseURLs = []string{"https://eu.gateway.example.com/v1"}
+	client.config.AllowedHeaders = []string{"X-Tenant"}
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+	ctx := WithEndpoint(context.Background(), Endpoint{
+		BaseURL: "https://eu.gateway.example.com/v1/",
+		Headers: map[string]string{"x-tenant": "acme"},
+	})
+	if _, err := client.ChatComplete(ctx, req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, endpoint := range []Endpoint{
+		{BaseURL: "https://us.gateway.example.com/v1"},
+		{Headers: map[string]string{"X-Api-Key": "other"}},
+	} {
+		ctx := WithEndpoint(context.Background(), endpoint)
+		_, err := client.ChatComplete(ctx, req)
+		if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+			t.Errorf("Expected a validation error for %+v, got %v", endpoint, err)
+		}
+	}
+	if len(adapter.chatRequests) != 1 {
+		t.Errorf("Expected no provider call for rejected endpoints, got %d calls", len(adapter.chatRequests))
+	}
+}
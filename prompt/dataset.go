@@ -0,0 +1,158 @@
+package prompt
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// Default field names of JSONL example datasets
+const (
+	DefaultInputField  = "input"
+	DefaultOutputField = "output"
+	DefaultIDField     = "id"
+	DefaultLabelField  = "label"
+)
+
+// DatasetOptions configures how examples are read from a JSONL dataset.
+type DatasetOptions struct {
+	// InputField is the field holding the example input (default: "input")
+	InputField string
+
+	// OutputField is the field holding the expected output (default: "output")
+	OutputField string
+
+	// IDField is the field identifying the example (default: "id")
+	IDField string
+
+	// LabelField is the field holding the example category (default: "label")
+	LabelField string
+}
+
+// LoadExamples reads a JSONL dataset of examples from a file.
+//
+// Each non-blank line is a JSON object with an input and an output field,
+// and optionally an id and a label:
+//
+//	{"input": "I love it", "output": "positive", "label": "positive"}
+//	{"input": "Never again", "output": "negative", "label": "negative"}
+//
+// Field values that are not strings, such as a structured output, are kept
+// as JSON. The examples can be used as a FewShotTemplate pool, sampled with
+// an ExampleSelector, or turned into evaluation cases with eval.ExampleCases.
+//
+// Parameters:
+//   - path: The dataset file
+//   - opts: Field names, or the zero value for the defaults
+//
+// Returns:
+//   - []Example: The examples in file order
+//   - error: A read error, or an error naming the line of an invalid example
+func LoadExamples(path string, opts DatasetOptions) ([]Example, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	examples, err := ReadExamples(file, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return examples, nil
+}
+
+// ReadExamples reads a JSONL dataset of examples from r, like LoadExamples
+func ReadExamples(r io.Reader, opts DatasetOptions) ([]Example, error) {
+	opts = opts.withDefaults()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var examples []Example
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			return nil, fmt.Errorf("line %d: invalid JSON object: %w", line, err)
+		}
+		example := Example{
+			Input:  fieldString(fields[opts.InputField]),
+			Output: fieldString(fields[opts.OutputField]),
+			ID:     fieldString(fields[opts.IDField]),
+			Label:  fieldString(fields[opts.LabelField]),
+		}
+		if example.Input == "" {
+			return nil, fmt.Errorf("line %d: missing %q field", line, opts.InputField)
+		}
+		if _, ok := fields[opts.OutputField]; !ok {
+			return nil, fmt.Errorf("line %d: missing %q field", line, opts.OutputField)
+		}
+		examples = append(examples, example)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return examples, nil
+}
+
+// withDefaults returns the options with default field names filled in
+func (o DatasetOptions) withDefaults() DatasetOptions {
+	if o.InputField == "" {
+		o.InputField = DefaultInputField
+	}
+	if o.OutputField == "" {
+		o.OutputField = DefaultOutputField
+	}
+	if o.IDField == "" {
+		o.IDField = DefaultIDField
+	}
+	if o.LabelField == "" {
+		o.LabelField = DefaultLabelField
+	}
+	return o
+}
+
+// fieldString returns a JSON string value as text, null or missing values as
+// "", and any other value as its JSON encoding
+func fieldString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// SplitExamples shuffles examples and splits them into a pool of few-shot
+// examples and a held-out set for evaluation, so the examples a prompt is
+// evaluated on never appear in it. holdout is the fraction of examples held
+// out, between 0 and 1; a non-zero seed makes the split deterministic.
+func SplitExamples(examples []Example, holdout float64, seed int64) (pool, heldOut []Example) {
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	shuffled := append([]Example(nil), examples...)
+	rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	n := int(float64(len(shuffled))*holdout + 0.5)
+	if n < 0 {
+		n = 0
+	}
+	if n > len(shuffled) {
+		n = len(shuffled)
+	}
+	return shuffled[n:], shuffled[:n]
+}
@@ -0,0 +1,202 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+const (
+	// DefaultInputVariable is the variable holding the input used to select examples
+	DefaultInputVariable = "input"
+
+	// DefaultExampleSeparator separates blocks in completion prompts
+	DefaultExampleSeparator = "\n\n"
+)
+
+// DefaultExampleTemplate formats an example in completion prompts
+var DefaultExampleTemplate = MustTemplate("Input: {{.Input}}\nOutput: {{.Output}}")
+
+// Example is a single input/output demonstration for few-shot prompting.
+type Example struct {
+	// Input is the example input, e.g. a question or text to classify
+	Input string `json:"input"`
+
+	// Output is the expected output for the input
+	Output string `json:"output"`
+
+	// ID identifies the example in a dataset (optional)
+	ID string `json:"id,omitempty"`
+
+	// Label is the example's category, used by StratifiedSelector (optional)
+	Label string `json:"label,omitempty"`
+}
+
+// FewShotTemplate builds prompts from instructions, examples and a query.
+//
+// The completion prompt produced by Format has the form:
+//
+//	<Prefix>
+//
+//	<example 1>
+//
+//	<example 2>
+//
+//	<Suffix>
+//
+// Messages produces the equivalent chat conversation: the prefix as a system
+// message, each example as a user/assistant pair and the suffix as the final
+// user message.
+type FewShotTemplate struct {
+	// Prefix contains instructions placed before the examples (optional)
+	Prefix *Template
+
+	// Examples is the pool of available examples
+	Examples []Example
+
+	// ExampleTemplate formats each example in completion prompts, with the
+	// Example fields as data (default: DefaultExampleTemplate)
+	ExampleTemplate *Template
+
+	// Suffix is the query placed after the examples (required)
+	Suffix *Template
+
+	// Separator separates prefix, examples and suffix (default: "\n\n")
+	Separator string
+
+	// Selector chooses which examples to include for a given input
+	// (default: all examples in order)
+	Selector ExampleSelector
+
+	// K is the number of examples to select (default: all examples)
+	K int
+
+	// InputVariable names the variable used as selection input (default: "input")
+	InputVariable string
+}
+
+// AddExample appends an example to the example pool
+func (f *FewShotTemplate) AddExample(input, output string) {
+	f.Examples = append(f.Examples, Example{Input: input, Output: output})
+}
+
+// SelectExamples returns the examples that would be included for vars
+func (f *FewShotTemplate) SelectExamples(ctx context.Context, vars map[string]interface{}) ([]Example, error) {
+	k := f.K
+	if k <= 0 || k > len(f.Examples) {
+		k = len(f.Examples)
+	}
+	if k == 0 {
+		return nil, nil
+	}
+
+	selector := f.Selector
+	if selector == nil {
+		selector = FirstSelector{}
+	}
+
+	inputVar := f.InputVariable
+	if inputVar == "" {
+		inputVar = DefaultInputVariable
+	}
+	var input string
+	if value, ok := vars[inputVar]; ok && value != nil {
+		input = fmt.Sprint(value)
+	}
+
+	selected, err := selector.Select(ctx, input, f.Examples, k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select examples: %w", err)
+	}
+	return selected, nil
+}
+
+// Format renders a completion prompt containing the selected examples
+func (f *FewShotTemplate) Format(ctx context.Context, vars map[string]interface{}) (string, error) {
+	prefix, suffix, err := f.renderParts(vars)
+	if err != nil {
+		return "", err
+	}
+
+	examples, err := f.SelectExamples(ctx, vars)
+	if err != nil {
+		return "", err
+	}
+
+	exampleTemplate := f.ExampleTemplate
+	if exampleTemplate == nil {
+		exampleTemplate = DefaultExampleTemplate
+	}
+
+	var blocks []string
+	if prefix != "" {
+		blocks = append(blocks, prefix)
+	}
+	for i, example := range examples {
+		var buf strings.Builder
+		if err := exampleTemplate.tmpl.Execute(&buf, example); err != nil {
+			return "", fmt.Errorf("failed to render example %d: %w", i, err)
+		}
+		blocks = append(blocks, buf.String())
+	}
+	blocks = append(blocks, suffix)
+
+	separator := f.Separator
+	if separator == "" {
+		separator = DefaultExampleSeparator
+	}
+	return strings.Join(blocks, separator), nil
+}
+
+// Messages renders a chat conversation containing the selected examples as
+// alternating user/assistant turns
+func (f *FewShotTemplate) Messages(ctx context.Context, vars map[string]interface{}) ([]types.Message, error) {
+	prefix, suffix, err := f.renderParts(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	examples, err := f.SelectExamples(ctx, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]types.Message, 0, 2*len(examples)+2)
+	if prefix != "" {
+		messages = append(messages, types.Message{Role: "system", Content: prefix})
+	}
+	for _, example := range examples {
+		messages = append(messages,
+			types.Message{Role: "user", Content: example.Input},
+			types.Message{Role: "assistant", Content: example.Output},
+		)
+	}
+	messages = append(messages, types.Message{Role: "user", Content: suffix})
+
+	return messages, nil
+}
+
+// renderParts renders the prefix and suffix templates
+func (f *FewShotTemplate) renderParts(vars map[string]interface{}) (string, string, error) {
+	if f.Suffix == nil {
+		return "", "", fmt.Errorf("few-shot template requires a suffix")
+	}
+
+	var prefix string
+	if f.Prefix != nil {
+		rendered, err := f.Prefix.Render(vars)
+		if err != nil {
+			return "", "", err
+		}
+		prefix = rendered
+	}
+
+	suffix, err := f.Suffix.Render(vars)
+	if err != nil {
+		return "", "", err
+	}
+
+	return prefix, suffix, nil
+}
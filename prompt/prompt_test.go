@@ -0,0 +1,403 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateRender(t *testing.T) {
+	tmpl := MustTemplate("Translate to {{.language}}: {{.text}}")
+
+	got, err := tmpl.Render(map[string]interface{}{"language": "French", "text": "Hello"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != "Translate to French: Hello" {
+		t.Errorf("Expected rendered prompt, got %q", got)
+	}
+
+	if _, err := tmpl.Render(map[string]interface{}{"language": "French"}); err == nil {
+		t.Error("Expected error for missing variable")
+	}
+
+	if _, err := NewTemplate("{{.unclosed"); err == nil {
+		t.Error("Expected parse error")
+	}
+}
+
+func newTestFewShot() *FewShotTemplate {
+	f := &FewShotTemplate{
+		Prefix: MustTemplate("Classify the sentiment."),
+		Suffix: MustTemplate("Input: {{.input}}\nOutput:"),
+	}
+	f.AddExample("I love this movie", "positive")
+	f.AddExample("The food was cold", "negative")
+	f.AddExample("What a great movie night", "positive")
+	return f
+}
+
+func TestFewShotFormat(t *testing.T) {
+	f := newTestFewShot()
+	f.K = 2
+
+	got, err := f.Format(context.Background(), map[string]interface{}{"input": "Terrible service"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "Classify the sentiment.\n\n" +
+		"Input: I love this movie\nOutput: positive\n\n" +
+		"Input: The food was cold\nOutput: negative\n\n" +
+		"Input: Terrible service\nOutput:"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestFewShotMessages(t *testing.T) {
+	f := newTestFewShot()
+
+	messages, err := f.Messages(context.Background(), map[string]interface{}{"input": "Meh"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(messages) != 8 {
+		t.Fatalf("Expected 8 messages, got %d", len(messages))
+	}
+	if messages[0].Role != "system" || messages[0].Content != "Classify the sentiment." {
+		t.Errorf("Expected system prefix, got %+v", messages[0])
+	}
+	if messages[1].Role != "user" || messages[2].Role != "assistant" || messages[2].Content != "positive" {
+		t.Errorf("Expected user/assistant example pair, got %+v %+v", messages[1], messages[2])
+	}
+	last := messages[len(messages)-1]
+	if last.Role != "user" || !strings.Contains(last.Content, "Meh") {
+		t.Errorf("Expected final user query, got %+v", last)
+	}
+}
+
+func TestFewShotRequiresSuffix(t *testing.T) {
+	f := &FewShotTemplate{}
+	if _, err := f.Format(context.Background(), nil); err == nil {
+		t.Error("Expected error for missing suffix")
+	}
+}
+
+func TestLexicalSelector(t *testing.T) {
+	f := newTestFewShot()
+	f.Selector = LexicalSelector{}
+	f.K = 2
+
+	examples, err := f.SelectExamples(context.Background(), map[string]interface{}{"input": "a great movie"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(examples) != 2 {
+		t.Fatalf("Expected 2 examples, got %d", len(examples))
+	}
+	// Most similar example is placed last, closest to the query
+	if examples[1].Input != "What a great movie night" {
+		t.Errorf("Expected most similar example last, got %q", examples[1].Input)
+	}
+	if examples[0].Input != "I love this movie" {
+		t.Errorf("Expected second most similar example first, got %q", examples[0].Input)
+	}
+}
+
+func TestRandomSelector(t *testing.T) {
+	pool := newTestFewShot().Examples
+	selector := RandomSelector{Seed: 42}
+
+	first, err := selector.Select(context.Background(), "", pool, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, _ := selector.Select(context.Background(), "", pool, 2)
+
+	if len(first) != 2 {
+		t.Fatalf("Expected 2 examples, got %d", len(first))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Expected deterministic selection with seed, got %v and %v", first, second)
+		}
+	}
+}
+
+type fakeEmbedder struct {
+	calls int
+	err   error
+}
+
+// Embed maps texts mentioning food to one axis and everything else to another
+func (e *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		if strings.Contains(text, "food") || strings.Contains(text, "dinner") {
+			vectors[i] = []float64{1, 0}
+		} else {
+			vectors[i] = []float64{0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+func TestSimilaritySelector(t *testing.T) {
+	embedder := &fakeEmbedder{}
+	selector := &SimilaritySelector{Embedder: embedder}
+	pool := newTestFewShot().Examples
+
+	examples, err := selector.Select(context.Background(), "dinner was awful", pool, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(examples) != 1 || examples[0].Input != "The food was cold" {
+		t.Errorf("Expected food example, got %v", examples)
+	}
+
+	if _, err := selector.Select(context.Background(), "another dinner", pool, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if embedder.calls != 2 {
+		t.Errorf("Expected 2 embed calls, got %d", embedder.calls)
+	}
+
+	failing := &SimilaritySelector{Embedder: &fakeEmbedder{err: errors.New("boom")}}
+	if _, err := failing.Select(context.Background(), "x", pool, 1); err == nil {
+		t.Error("Expected embedder error to be returned")
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []float64
+		expected float64
+	}{
+		{"identical", []float64{1, 2}, []float64{1, 2}, 1},
+		{"orthogonal", []float64{1, 0}, []float64{0, 1}, 0},
+		{"length mismatch", []float64{1}, []float64{1, 2}, 0},
+		{"zero vector", []float64{0, 0}, []float64{1, 2}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CosineSimilarity(tt.a, tt.b)
+			if got < tt.expected-1e-9 || got > tt.expected+1e-9 {
+				t.Errorf("Expected %f, got %f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStratifiedSelector(t *testing.T) {
+	var pool []Example
+	for i := 0; i < 8; i++ {
+		pool = append(pool, Example{Input: fmt.Sprintf("good %d", i), Output: "positive", Label: "positive"})
+	}
+	pool = append(pool,
+		Example{Input: "bad", Output: "negative", Label: "negative"},
+		Example{Input: "fine", Output: "neutral", Label: "neutral"},
+	)
+	selector := StratifiedSelector{Seed: 7}
+
+	examples, err := selector.Select(context.Background(), "", pool, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	counts := make(map[string]int)
+	for _, example := range examples {
+		counts[example.Label]++
+	}
+	if len(examples) != 3 || counts["positive"] != 1 || counts["negative"] != 1 || counts["neutral"] != 1 {
+		t.Errorf("Expected one example per label, got %v", examples)
+	}
+
+	// Once small labels run out, the remaining slots go to larger ones
+	examples, _ = selector.Select(context.Background(), "", pool, 6)
+	if len(examples) != 6 || examples[4].Label != "negative" || examples[5].Label != "neutral" {
+		t.Errorf("Expected 4 positive examples then the others in pool order, got %v", examples)
+	}
+
+	again, _ := selector.Select(context.Background(), "", pool, 6)
+	for i := range examples {
+		if examples[i] != again[i] {
+			t.Errorf("Expected deterministic selection with seed, got %v and %v", examples, again)
+		}
+	}
+}
+
+func TestReadExamples(t *testing.T) {
+	data := `{"id": "a", "input": "I love it", "output": "positive", "label": "positive"}
+
+{"input": "Extract", "output": {"name": "Ada"}}
+`
+	examples, err := ReadExamples(strings.NewReader(data), DatasetOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []Example{
+		{ID: "a", Input: "I love it", Output: "positive", Label: "positive"},
+		{Input: "Extract", Output: `{"name": "Ada"}`},
+	}
+	if len(examples) != len(want) {
+		t.Fatalf("Expected %d examples, got %v", len(want), examples)
+	}
+	for i := range want {
+		if examples[i] != want[i] {
+			t.Errorf("Example %d: expected %+v, got %+v", i, want[i], examples[i])
+		}
+	}
+
+	custom, err := ReadExamples(strings.NewReader(`{"question": "2+2?", "answer": 4, "topic": "math"}`),
+		DatasetOptions{InputField: "question", OutputField: "answer", LabelField: "topic"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if custom[0] != (Example{Input: "2+2?", Output: "4", Label: "math"}) {
+		t.Errorf("Unexpected example from custom fields: %+v", custom[0])
+	}
+}
+
+func TestReadExamples_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"invalid JSON", "{\"input\": \"a\", \"output\": \"b\"}\nnot json", "line 2: invalid JSON"},
+		{"missing input", `{"output": "b"}`, `line 1: missing "input" field`},
+		{"missing output", `{"input": "a"}`, `line 1: missing "output" field`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadExamples(strings.NewReader(tt.data), DatasetOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadExamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "examples.jsonl")
+	if err := os.WriteFile(path, []byte(`{"input": "hi", "output": "hello"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	examples, err := LoadExamples(path, DatasetOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(examples) != 1 || examples[0].Output != "hello" {
+		t.Errorf("Unexpected examples: %v", examples)
+	}
+
+	if _, err := LoadExamples(filepath.Join(t.TempDir(), "missing.jsonl"), DatasetOptions{}); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestSplitExamples(t *testing.T) {
+	var examples []Example
+	for i := 0; i < 10; i++ {
+		examples = append(examples, Example{Input: fmt.Sprint(i)})
+	}
+
+	pool, heldOut := SplitExamples(examples, 0.2, 3)
+	if len(pool) != 8 || len(heldOut) != 2 {
+		t.Fatalf("Expected 8/2 split, got %d/%d", len(pool), len(heldOut))
+	}
+	seen := make(map[string]bool)
+	for _, example := range append(pool, heldOut...) {
+		seen[example.Input] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("Expected every example in exactly one set, got %d distinct", len(seen))
+	}
+
+	pool2, _ := SplitExamples(examples, 0.2, 3)
+	for i := range pool {
+		if pool[i] != pool2[i] {
+			t.Errorf("Expected deterministic split with seed")
+			break
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	for _, v := range []struct{ version, content string }{
+		{"1.10.0", "v1.10 for {{.product}}"},
+		{"1.2.0", "v1.2 for {{.product}}"},
+		{"2.0.0-rc.1", "v2 candidate"},
+	} {
+		if _, err := r.Register("support", v.version, v.content); err != nil {
+			t.Fatalf("Unexpected error registering %s: %v", v.version, err)
+		}
+	}
+
+	var versions []string
+	for _, v := range r.Versions("support") {
+		versions = append(versions, v.Version)
+	}
+	if strings.Join(versions, " ") != "1.2.0 1.10.0 2.0.0-rc.1" {
+		t.Errorf("Expected versions in semver order, got %v", versions)
+	}
+
+	latest, err := r.GetPrompt(context.Background(), "support", "")
+	if err != nil || latest.Version != "1.10.0" {
+		t.Errorf("Expected the latest release to skip the pre-release, got %+v, %v", latest, err)
+	}
+	pinned, err := r.GetPrompt(context.Background(), "support", "1.2.0")
+	if err != nil || pinned.Content != "v1.2 for {{.product}}" || pinned.Hash != Hash(pinned.Content) {
+		t.Errorf("Expected the pinned version with its hash, got %+v, %v", pinned, err)
+	}
+
+	if _, err := r.GetPrompt(context.Background(), "support", "3.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown version, got %v", err)
+	}
+	if _, err := r.GetPrompt(context.Background(), "billing", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown prompt, got %v", err)
+	}
+
+	if _, err := r.Register("support", "1.2.0", "v1.2 for {{.product}}"); err != nil {
+		t.Errorf("Expected re-registering identical content to succeed, got %v", err)
+	}
+	if _, err := r.Register("support", "1.2.0", "changed"); err == nil {
+		t.Error("Expected an error for changing a registered version")
+	}
+	for _, version := range []string{"1.2", "01.2.0", "1.2.0-", "latest"} {
+		if _, err := r.Register("support", version, "text"); err == nil {
+			t.Errorf("Expected an error for version %q", version)
+		}
+	}
+	if _, err := r.Register("support", "3.0.0", "{{.unclosed"); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "v1.0.1+build.5"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := parseSemver(ordered[i-1])
+		b, err := parseSemver(ordered[i])
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", ordered[i], err)
+		}
+		if a.compare(b) != -1 || b.compare(a) != 1 {
+			t.Errorf("Expected %s < %s", ordered[i-1], ordered[i])
+		}
+	}
+}
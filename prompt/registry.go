@@ -0,0 +1,236 @@
+package prompt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// ErrNotFound is returned by Registry.GetPrompt for an unknown prompt name
+// or version
+var ErrNotFound = errors.New("prompt not found")
+
+// Registry is an in-memory store of versioned prompt templates, usable as
+// Config.Prompts.
+//
+// Versions are semantic versions and immutable: registering a version again
+// with different content fails, so a pinned version always sends the same
+// prompt. Registry is safe for concurrent use.
+//
+// Example:
+//
+//	prompts := prompt.NewRegistry()
+//	prompts.Register("support-agent", "1.0.0", "You are a support agent for {{.product}}.")
+//	prompts.Register("support-agent", "1.1.0", "You are a friendly support agent for {{.product}}.")
+//
+//	config.Prompts = prompts
+//	config.PromptVersions = map[string]string{"support-agent": "1.0.0"}
+type Registry struct {
+	mu       sync.RWMutex
+	versions map[string][]registered // Sorted by version, oldest first
+}
+
+// registered is a prompt version with its parsed version number
+type registered struct {
+	prompt types.PromptVersion
+	semver semver
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{versions: make(map[string][]registered)}
+}
+
+// Register adds a version of a named prompt.
+//
+// Registering an existing version with the same content is a no-op.
+//
+// Parameters:
+//   - name: The prompt name
+//   - version: A semantic version such as "1.2.0" or "2.0.0-rc.1"
+//   - content: The prompt text in text/template syntax
+//
+// Returns:
+//   - types.PromptVersion: The registered version with its content hash
+//   - error: An error for an empty name, an invalid version or template, or
+//     an existing version with different content
+func (r *Registry) Register(name, version, content string) (types.PromptVersion, error) {
+	if strings.TrimSpace(name) == "" {
+		return types.PromptVersion{}, fmt.Errorf("prompt name cannot be empty")
+	}
+	parsed, err := parseSemver(version)
+	if err != nil {
+		return types.PromptVersion{}, fmt.Errorf("prompt %q: %w", name, err)
+	}
+	if _, err := NewTemplate(content); err != nil {
+		return types.PromptVersion{}, fmt.Errorf("prompt %q version %s: %w", name, version, err)
+	}
+
+	prompt := types.PromptVersion{Name: name, Version: version, Hash: Hash(content), Content: content}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions := r.versions[name]
+	i := sort.Search(len(versions), func(i int) bool {
+		return versions[i].semver.compare(parsed) >= 0
+	})
+	if i < len(versions) && versions[i].semver.compare(parsed) == 0 {
+		if existing := versions[i].prompt; existing.Hash != prompt.Hash {
+			return types.PromptVersion{}, fmt.Errorf("prompt %q version %s is already registered with different content", name, existing.Version)
+		}
+		return versions[i].prompt, nil
+	}
+	versions = append(versions, registered{})
+	copy(versions[i+1:], versions[i:])
+	versions[i] = registered{prompt: prompt, semver: parsed}
+	r.versions[name] = versions
+	return prompt, nil
+}
+
+// GetPrompt returns a version of the named prompt, implementing
+// types.PromptStore. An empty version selects the latest release, or the
+// latest pre-release if the prompt has no release yet.
+func (r *Registry) GetPrompt(ctx context.Context, name, version string) (types.PromptVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := r.versions[name]
+	if len(versions) == 0 {
+		return types.PromptVersion{}, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	if version == "" {
+		for i := len(versions) - 1; i >= 0; i-- {
+			if len(versions[i].semver.pre) == 0 {
+				return versions[i].prompt, nil
+			}
+		}
+		return versions[len(versions)-1].prompt, nil
+	}
+
+	parsed, err := parseSemver(version)
+	if err != nil {
+		return types.PromptVersion{}, fmt.Errorf("prompt %q: %w", name, err)
+	}
+	for _, v := range versions {
+		if v.semver.compare(parsed) == 0 {
+			return v.prompt, nil
+		}
+	}
+	return types.PromptVersion{}, fmt.Errorf("%w: %q version %s", ErrNotFound, name, version)
+}
+
+// Versions returns the registered versions of a prompt, oldest first
+func (r *Registry) Versions(name string) []types.PromptVersion {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := make([]types.PromptVersion, len(r.versions[name]))
+	for i, v := range r.versions[name] {
+		versions[i] = v.prompt
+	}
+	return versions
+}
+
+// Hash returns the hex-encoded SHA-256 of prompt content
+func Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// semver is a parsed semantic version; build metadata is ignored
+type semver struct {
+	major, minor, patch int
+	pre                 []string
+}
+
+// parseSemver parses a semantic version such as "1.2.0" or "v2.0.0-rc.1"
+func parseSemver(version string) (semver, error) {
+	v := strings.TrimPrefix(version, "v")
+	v, _, _ = strings.Cut(v, "+")
+	core, pre, hasPre := strings.Cut(v, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semver{}, fmt.Errorf("invalid semantic version %q", version)
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return semver{}, fmt.Errorf("invalid semantic version %q", version)
+		}
+		numbers[i] = n
+	}
+
+	parsed := semver{major: numbers[0], minor: numbers[1], patch: numbers[2]}
+	if hasPre {
+		parsed.pre = strings.Split(pre, ".")
+		for _, identifier := range parsed.pre {
+			if identifier == "" {
+				return semver{}, fmt.Errorf("invalid semantic version %q", version)
+			}
+		}
+	}
+	return parsed, nil
+}
+
+// compare returns -1, 0 or 1 as v has lower, equal or higher precedence
+// than other
+func (v semver) compare(other semver) int {
+	for _, pair := range [][2]int{{v.major, other.major}, {v.minor, other.minor}, {v.patch, other.patch}} {
+		if pair[0] != pair[1] {
+			return compareInts(pair[0], pair[1])
+		}
+	}
+
+	// A pre-release has lower precedence than its release
+	switch {
+	case len(v.pre) == 0 && len(other.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(other.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.pre) && i < len(other.pre); i++ {
+		a, b := v.pre[i], other.pre[i]
+		if a == b {
+			continue
+		}
+		// Numeric identifiers compare numerically and below alphanumeric ones
+		na, errA := strconv.Atoi(a)
+		nb, errB := strconv.Atoi(b)
+		switch {
+		case errA == nil && errB == nil:
+			return compareInts(na, nb)
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		case a < b:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return compareInts(len(v.pre), len(other.pre))
+}
+
+// compareInts returns -1, 0 or 1 as a is less than, equal to or greater than b
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
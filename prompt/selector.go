@@ -0,0 +1,248 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ExampleSelector chooses which few-shot examples to include for an input.
+type ExampleSelector interface {
+	// Select returns up to k examples from pool for the given input
+	Select(ctx context.Context, input string, pool []Example, k int) ([]Example, error)
+}
+
+// FirstSelector selects the first k examples in pool order.
+type FirstSelector struct{}
+
+// Select returns the first k examples
+func (FirstSelector) Select(ctx context.Context, input string, pool []Example, k int) ([]Example, error) {
+	if k > len(pool) {
+		k = len(pool)
+	}
+	return append([]Example(nil), pool[:k]...), nil
+}
+
+// RandomSelector selects k examples uniformly at random.
+type RandomSelector struct {
+	// Seed makes the selection deterministic when non-zero
+	Seed int64
+}
+
+// Select returns k randomly chosen examples in pool order
+func (s RandomSelector) Select(ctx context.Context, input string, pool []Example, k int) ([]Example, error) {
+	if k > len(pool) {
+		k = len(pool)
+	}
+
+	seed := s.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	indices := rand.New(rand.NewSource(seed)).Perm(len(pool))[:k]
+	sort.Ints(indices)
+
+	selected := make([]Example, 0, k)
+	for _, i := range indices {
+		selected = append(selected, pool[i])
+	}
+	return selected, nil
+}
+
+// StratifiedSelector selects k examples spread evenly across example labels,
+// so every category is demonstrated even when the pool is unbalanced. Labels
+// take turns in order of first appearance, each contributing a random
+// example it has not yet contributed; examples without a label form their
+// own group.
+type StratifiedSelector struct {
+	// Seed makes the selection deterministic when non-zero
+	Seed int64
+}
+
+// Select returns k examples balanced across labels, in pool order
+func (s StratifiedSelector) Select(ctx context.Context, input string, pool []Example, k int) ([]Example, error) {
+	if k > len(pool) {
+		k = len(pool)
+	}
+
+	seed := s.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	// Group pool indices by label, shuffled within each group
+	var labels []string
+	groups := make(map[string][]int)
+	for i, example := range pool {
+		if _, ok := groups[example.Label]; !ok {
+			labels = append(labels, example.Label)
+		}
+		groups[example.Label] = append(groups[example.Label], i)
+	}
+	for _, label := range labels {
+		group := groups[label]
+		rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+	}
+
+	indices := make([]int, 0, k)
+	for round := 0; len(indices) < k; round++ {
+		for _, label := range labels {
+			if group := groups[label]; round < len(group) && len(indices) < k {
+				indices = append(indices, group[round])
+			}
+		}
+	}
+	sort.Ints(indices)
+
+	selected := make([]Example, 0, k)
+	for _, i := range indices {
+		selected = append(selected, pool[i])
+	}
+	return selected, nil
+}
+
+// LexicalSelector selects the k examples whose inputs share the most words
+// with the input (Jaccard similarity). It needs no embedding model.
+type LexicalSelector struct{}
+
+// Select returns the k most lexically similar examples, most similar last so
+// the closest demonstration sits next to the query
+func (LexicalSelector) Select(ctx context.Context, input string, pool []Example, k int) ([]Example, error) {
+	inputWords := wordSet(input)
+	scores := make([]float64, len(pool))
+	for i, example := range pool {
+		scores[i] = jaccard(inputWords, wordSet(example.Input))
+	}
+	return topK(pool, scores, k), nil
+}
+
+// Embedder computes embedding vectors for texts.
+//
+// It is implemented by embedding model clients; the prompt package has no
+// dependency on any particular provider.
+type Embedder interface {
+	// Embed returns one embedding vector per input text
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// SimilaritySelector selects the k examples whose inputs are most similar to
+// the input by cosine similarity of their embeddings. Example embeddings are
+// cached, so only the input is embedded on subsequent calls.
+type SimilaritySelector struct {
+	// Embedder computes the embeddings (required)
+	Embedder Embedder
+
+	cache sync.Map // example input -> []float64
+}
+
+// Select returns the k most similar examples, most similar last
+func (s *SimilaritySelector) Select(ctx context.Context, input string, pool []Example, k int) ([]Example, error) {
+	if s.Embedder == nil {
+		return nil, fmt.Errorf("similarity selector requires an embedder")
+	}
+
+	// Embed the input together with any examples not seen before
+	texts := []string{input}
+	var missing []string
+	for _, example := range pool {
+		if _, ok := s.cache.Load(example.Input); !ok {
+			missing = append(missing, example.Input)
+		}
+	}
+	texts = append(texts, missing...)
+
+	vectors, err := s.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed examples: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	for i, text := range missing {
+		s.cache.Store(text, vectors[i+1])
+	}
+
+	inputVector := vectors[0]
+	scores := make([]float64, len(pool))
+	for i, example := range pool {
+		cached, _ := s.cache.Load(example.Input)
+		scores[i] = CosineSimilarity(inputVector, cached.([]float64))
+	}
+	return topK(pool, scores, k), nil
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or zero if
+// either is empty or they differ in length
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// topK returns the k highest scoring examples ordered by ascending score.
+// Ties keep pool order so selection is deterministic.
+func topK(pool []Example, scores []float64, k int) []Example {
+	if k > len(pool) {
+		k = len(pool)
+	}
+
+	indices := make([]int, len(pool))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return scores[indices[i]] > scores[indices[j]]
+	})
+	indices = indices[:k]
+
+	selected := make([]Example, k)
+	for i, idx := range indices {
+		selected[k-1-i] = pool[idx]
+	}
+	return selected
+}
+
+// wordSet returns the set of lowercase words in text
+func wordSet(text string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+// jaccard returns the Jaccard similarity of two word sets
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for w := range a {
+		if _, ok := b[w]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
@@ -0,0 +1,65 @@
+// Package prompt provides prompt templates and few-shot prompt construction.
+//
+// Templates use Go's text/template syntax with variables passed as a map,
+// and fail on missing variables instead of silently rendering "<no value>".
+// FewShotTemplate builds prompts from example input/output pairs, optionally
+// selecting the most relevant examples for each input, and can produce either
+// a single completion prompt or a list of chat messages. Registry stores
+// immutable, semantically versioned prompts for clients to pin.
+//
+// Example:
+//
+//	tmpl := prompt.MustTemplate("Translate to {{.language}}: {{.text}}")
+//	text, err := tmpl.Render(map[string]interface{}{
+//		"language": "French",
+//		"text":     "Good morning",
+//	})
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// Template is a parsed prompt template.
+//
+// Template is safe for concurrent use once created.
+type Template struct {
+	source string
+	tmpl   *template.Template
+}
+
+// NewTemplate parses a prompt template using text/template syntax
+func NewTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	return &Template{source: text, tmpl: tmpl}, nil
+}
+
+// MustTemplate is like NewTemplate but panics if the template cannot be parsed.
+// It is intended for package-level template variables.
+func MustTemplate(text string) *Template {
+	tmpl, err := NewTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+	return tmpl
+}
+
+// Render executes the template with the given variables
+func (t *Template) Render(vars map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Source returns the unparsed template text
+func (t *Template) Source() string {
+	return t.source
+}
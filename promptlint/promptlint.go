@@ -0,0 +1,452 @@
+// Package promptlint checks prompts for common problems before they are sent.
+//
+// Lint reports unbalanced brackets, quotes, code fences and XML-style tags,
+// instructions that contradict each other, template variables the caller
+// does not provide, and prompts too long for the model's context window.
+// Findings are returned as structured warnings with a rule name, severity
+// and position, so they can be shown in editors or fail a CI build (see the
+// lint command of cmd/aiprovider).
+//
+// The checks are heuristics: a clean result does not guarantee a good
+// prompt, and some warnings may be intended.
+//
+// Example:
+//
+//	warnings := promptlint.Lint(source, promptlint.Options{
+//		Variables:     []string{"language", "text"},
+//		ContextWindow: 8192,
+//	})
+//	for _, w := range warnings {
+//		fmt.Println(w)
+//	}
+package promptlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// Severity ranks how likely a warning is to be a real problem.
+type Severity string
+
+const (
+	// SeverityError is a problem that breaks the prompt, such as a template
+	// that cannot render or a prompt that exceeds the context window
+	SeverityError Severity = "error"
+
+	// SeverityWarning is a likely problem worth reviewing
+	SeverityWarning Severity = "warning"
+)
+
+// Rule names identify the check that produced a warning.
+const (
+	// RuleEmpty reports a blank prompt
+	RuleEmpty = "empty"
+
+	// RuleUnbalanced reports an unclosed or unopened bracket, quote, code
+	// fence or XML-style tag
+	RuleUnbalanced = "unbalanced-delimiter"
+
+	// RuleConflict reports instructions that contradict each other
+	RuleConflict = "conflicting-instructions"
+
+	// RuleTemplateSyntax reports a template that cannot be parsed
+	RuleTemplateSyntax = "template-syntax"
+
+	// RuleMissingVariable reports a template variable not in Options.Variables
+	RuleMissingVariable = "missing-variable"
+
+	// RuleUnusedVariable reports a variable in Options.Variables the template
+	// never uses
+	RuleUnusedVariable = "unused-variable"
+
+	// RuleContextWindow reports a prompt longer than Options.ContextWindow
+	RuleContextWindow = "context-window"
+)
+
+// Warning is a problem found in a prompt.
+type Warning struct {
+	// Rule is the name of the check that found the problem
+	Rule string `json:"rule"`
+
+	// Severity ranks the problem
+	Severity Severity `json:"severity"`
+
+	// Message describes the problem
+	Message string `json:"message"`
+
+	// Line is the 1-based line of the problem, or 0 if it concerns the whole prompt
+	Line int `json:"line,omitempty"`
+
+	// Column is the 1-based byte column of the problem within Line
+	Column int `json:"column,omitempty"`
+}
+
+// String formats the warning as "line:column: severity: message (rule)"
+func (w Warning) String() string {
+	position := ""
+	if w.Line > 0 {
+		position = fmt.Sprintf("%d:%d: ", w.Line, w.Column)
+	}
+	return fmt.Sprintf("%s%s: %s (%s)", position, w.Severity, w.Message, w.Rule)
+}
+
+// Options configures Lint.
+type Options struct {
+	// Variables lists the variables the caller renders the prompt with; when
+	// set, the prompt is parsed as a prompt.Template and variables it uses but
+	// are not listed are reported, as are listed variables it never uses
+	Variables []string
+
+	// ContextWindow is the model's context window in tokens; prompts
+	// exceeding it are reported (optional, 0 disables the check)
+	ContextWindow int
+
+	// ReservedTokens is subtracted from ContextWindow to leave room for the
+	// response (optional)
+	ReservedTokens int
+
+	// Tokenizer counts tokens for the context window check (default: tokenizer.Default)
+	Tokenizer tokenizer.Tokenizer
+
+	// Disable lists rules to skip (optional)
+	Disable []string
+}
+
+// HasErrors reports whether any of warnings has SeverityError
+func HasErrors(warnings []Warning) bool {
+	for _, w := range warnings {
+		if w.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks text for common prompt problems and returns the warnings in
+// order of position, prompt-wide warnings last
+func Lint(text string, opts Options) []Warning {
+	l := &linter{text: text, disabled: make(map[string]bool, len(opts.Disable))}
+	for _, rule := range opts.Disable {
+		l.disabled[rule] = true
+	}
+
+	if strings.TrimSpace(text) == "" {
+		l.add(RuleEmpty, SeverityError, -1, "prompt is empty")
+		return l.warnings
+	}
+
+	l.checkDelimiters()
+	l.checkConflicts()
+	if opts.Variables != nil {
+		l.checkVariables(opts.Variables)
+	}
+	if opts.ContextWindow > 0 {
+		l.checkContextWindow(opts)
+	}
+
+	sortWarnings(l.warnings)
+	return l.warnings
+}
+
+// linter accumulates the warnings for one prompt
+type linter struct {
+	text     string
+	disabled map[string]bool
+	warnings []Warning
+}
+
+// add records a warning at byte offset, or for the whole prompt if offset is negative
+func (l *linter) add(rule string, severity Severity, offset int, format string, args ...interface{}) {
+	if l.disabled[rule] {
+		return
+	}
+	w := Warning{Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)}
+	if offset >= 0 {
+		w.Line = strings.Count(l.text[:offset], "\n") + 1
+		w.Column = offset - strings.LastIndex(l.text[:offset], "\n")
+	}
+	l.warnings = append(l.warnings, w)
+}
+
+// checkContextWindow reports a prompt longer than the usable context window
+func (l *linter) checkContextWindow(opts Options) {
+	t := opts.Tokenizer
+	if t == nil {
+		t = tokenizer.Default
+	}
+	limit := opts.ContextWindow - opts.ReservedTokens
+	if tokens := t.CountTokens(l.text); tokens > limit {
+		l.add(RuleContextWindow, SeverityError, -1,
+			"prompt has about %d tokens, more than the %d available in the context window", tokens, limit)
+	}
+}
+
+// checkVariables reports template variables the caller does not provide,
+// and provided variables the template does not use
+func (l *linter) checkVariables(variables []string) {
+	tmpl, err := template.New("prompt").Parse(l.text)
+	if err != nil {
+		l.add(RuleTemplateSyntax, SeverityError, -1, "template does not parse: %v", err)
+		return
+	}
+
+	provided := make(map[string]bool, len(variables))
+	for _, name := range variables {
+		provided[name] = true
+	}
+
+	used := make(map[string]bool)
+	if tmpl.Tree != nil {
+		walkFields(tmpl.Tree.Root, func(name string, pos parse.Pos) {
+			if !provided[name] && !used[name] {
+				l.add(RuleMissingVariable, SeverityError, int(pos), "variable %q is not provided", name)
+			}
+			used[name] = true
+		})
+	}
+	for _, name := range variables {
+		if !used[name] {
+			l.add(RuleUnusedVariable, SeverityWarning, -1, "variable %q is provided but never used", name)
+		}
+	}
+}
+
+// walkFields calls fn with the top-level fields of the template data used
+// in node. Fields inside range and with blocks refer to other data and are
+// skipped.
+func walkFields(node parse.Node, fn func(name string, pos parse.Pos)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkFields(child, fn)
+		}
+	case *parse.ActionNode:
+		walkFields(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkFields(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkFields(arg, fn)
+		}
+	case *parse.FieldNode:
+		fn(n.Ident[0], n.Position())
+	case *parse.IfNode:
+		walkFields(n.Pipe, fn)
+		walkFields(n.List, fn)
+		walkFields(n.ElseList, fn)
+	case *parse.RangeNode:
+		walkFields(n.Pipe, fn)
+		walkFields(n.ElseList, fn)
+	case *parse.WithNode:
+		walkFields(n.Pipe, fn)
+		walkFields(n.ElseList, fn)
+	case *parse.TemplateNode:
+		walkFields(n.Pipe, fn)
+	}
+}
+
+// brackets maps closing brackets to their opening ones
+var brackets = map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+// delimiter is an opened bracket or tag awaiting its close
+type delimiter struct {
+	name   string
+	offset int
+}
+
+// tagPattern matches XML-style opening, closing and self-closing tags
+var tagPattern = regexp.MustCompile(`<(/?)([A-Za-z][\w.-]*)(?:\s[^<>]*)?(/?)>`)
+
+// actionPattern matches template actions such as {{.Name}}
+var actionPattern = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// checkDelimiters reports unbalanced brackets, code fences, double quotes
+// and XML-style tags. Code blocks are skipped, as their content follows
+// other rules.
+func (l *linter) checkDelimiters() {
+	var stack []delimiter
+	fenceOffset := -1
+	quoteOffset := -1
+	offset := 0
+
+	for _, line := range strings.SplitAfter(l.text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if fenceOffset < 0 {
+				fenceOffset = offset + strings.Index(line, "```")
+			} else {
+				fenceOffset = -1
+			}
+			offset += len(line)
+			continue
+		}
+		if fenceOffset >= 0 {
+			offset += len(line)
+			continue
+		}
+
+		// Tags and template actions are matched as a whole, in order with the
+		// brackets around them
+		tags := tagPattern.FindAllStringSubmatchIndex(line, -1)
+		actions := actionPattern.FindAllStringIndex(line, -1)
+		for i := 0; i < len(line); i++ {
+			if len(actions) > 0 && i == actions[0][0] {
+				i = actions[0][1] - 1
+				actions = actions[1:]
+				continue
+			}
+			if len(tags) > 0 && i == tags[0][0] {
+				match := tags[0]
+				tags = tags[1:]
+				name := "<" + line[match[4]:match[5]] + ">"
+				switch {
+				case match[7] > match[6]:
+					// Self-closing
+				case match[3] > match[2]:
+					stack = l.closeDelimiter(stack, name, offset+i)
+				default:
+					stack = append(stack, delimiter{name: name, offset: offset + i})
+				}
+				i = match[1] - 1
+				continue
+			}
+
+			switch c := rune(line[i]); c {
+			case '(', '[', '{':
+				stack = append(stack, delimiter{name: string(c), offset: offset + i})
+			case ')', ']', '}':
+				stack = l.closeDelimiter(stack, string(brackets[c]), offset+i)
+			case '"':
+				if quoteOffset < 0 {
+					quoteOffset = offset + i
+				} else {
+					quoteOffset = -1
+				}
+			}
+		}
+		offset += len(line)
+	}
+
+	for _, open := range stack {
+		l.add(RuleUnbalanced, SeverityWarning, open.offset, "%s is never closed", open.name)
+	}
+	if fenceOffset >= 0 {
+		l.add(RuleUnbalanced, SeverityWarning, fenceOffset, "code fence is never closed")
+	}
+	if quoteOffset >= 0 {
+		l.add(RuleUnbalanced, SeverityWarning, quoteOffset, "quote is never closed")
+	}
+}
+
+// closeDelimiter pops the delimiter named open from stack, reporting a close
+// at offset without a matching open. Delimiters opened after the match are
+// reported as unclosed.
+func (l *linter) closeDelimiter(stack []delimiter, open string, offset int) []delimiter {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name != open {
+			continue
+		}
+		for _, unclosed := range stack[i+1:] {
+			l.add(RuleUnbalanced, SeverityWarning, unclosed.offset, "%s is never closed", unclosed.name)
+		}
+		return stack[:i]
+	}
+
+	closing := map[string]string{"(": ")", "[": "]", "{": "}"}[open]
+	if closing == "" {
+		closing = "</" + open[1:]
+	}
+	l.add(RuleUnbalanced, SeverityWarning, offset, "%s has no matching %s", closing, open)
+	return stack
+}
+
+// conflicts are pairs of instructions that cannot both be followed
+var conflicts = []struct {
+	a, b *regexp.Regexp
+}{
+	{
+		regexp.MustCompile(`(?i)\b(be (brief|concise|short)|keep (it|your answer|the answer|responses?) (brief|short|concise)|one sentence)\b`),
+		regexp.MustCompile(`(?i)\b(be (detailed|thorough|comprehensive)|in (great )?detail|elaborate on|step[- ]by[- ]step explanation)\b`),
+	},
+	{
+		regexp.MustCompile(`(?i)\b(respond|reply|answer|output)( only)? (in|with|as) json\b`),
+		regexp.MustCompile(`(?i)\b(respond|reply|answer|output)( only)? (in|with|as) (plain text|markdown|prose)\b`),
+	},
+	{
+		regexp.MustCompile(`(?i)\b(be formal|formal tone|professional tone)\b`),
+		regexp.MustCompile(`(?i)\b(be casual|casual tone|informal tone)\b`),
+	},
+}
+
+// directivePattern matches "always <verb> <object>" and "never/don't <verb> <object>"
+var directivePattern = regexp.MustCompile(`(?i)\b(always|never|do not|don't)\s+([a-z]+\s+[a-z]+)`)
+
+// checkConflicts reports instructions that contradict each other
+func (l *linter) checkConflicts() {
+	for _, pair := range conflicts {
+		a := pair.a.FindStringIndex(l.text)
+		b := pair.b.FindStringIndex(l.text)
+		if a == nil || b == nil {
+			continue
+		}
+		if b[0] < a[0] {
+			a, b = b, a
+		}
+		l.add(RuleConflict, SeverityWarning, b[0], "%q conflicts with %q", l.text[b[0]:b[1]], l.text[a[0]:a[1]])
+	}
+
+	always := make(map[string]int)
+	never := make(map[string]int)
+	for _, match := range directivePattern.FindAllStringSubmatchIndex(l.text, -1) {
+		action := strings.ToLower(l.text[match[4]:match[5]])
+		if strings.EqualFold(l.text[match[2]:match[3]], "always") {
+			always[action] = match[0]
+		} else {
+			never[action] = match[0]
+		}
+		if a, ok := always[action]; ok {
+			if n, ok := never[action]; ok {
+				later := a
+				if n > a {
+					later = n
+				}
+				l.add(RuleConflict, SeverityWarning, later, "instructions both require and forbid %q", action)
+				delete(always, action)
+				delete(never, action)
+			}
+		}
+	}
+}
+
+// sortWarnings orders warnings by position, prompt-wide warnings last
+func sortWarnings(warnings []Warning) {
+	less := func(a, b Warning) bool {
+		if (a.Line == 0) != (b.Line == 0) {
+			return b.Line == 0
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	}
+	// Insertion sort keeps warnings at the same position in rule order
+	for i := 1; i < len(warnings); i++ {
+		for j := i; j > 0 && less(warnings[j], warnings[j-1]); j-- {
+			warnings[j], warnings[j-1] = warnings[j-1], warnings[j]
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package promptlint
+
+import (
+	"strings"
+	"testing"
+)
+
+// rules returns the rule of each warning
+func rules(warnings []Warning) []string {
+	names := make([]string, len(warnings))
+	for i, w := range warnings {
+		names[i] = w.Rule
+	}
+	return names
+}
+
+func TestLint_Clean(t *testing.T) {
+	text := "You are a helpful assistant (be polite).\n\n<context>\n{{.Context}}\n</context>\n\nAnswer the question: {{.Question}}"
+	warnings := Lint(text, Options{Variables: []string{"Context", "Question"}, ContextWindow: 1000})
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+func TestLint_Empty(t *testing.T) {
+	warnings := Lint("  \n", Options{})
+	if len(warnings) != 1 || warnings[0].Rule != RuleEmpty || warnings[0].Severity != SeverityError {
+		t.Errorf("Expected one empty error, got %v", warnings)
+	}
+}
+
+func TestLint_Unbalanced(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		message string
+		line    int
+		column  int
+	}{
+		{"unclosed paren", "Summarize (briefly the text.", "( is never closed", 1, 11},
+		{"unopened bracket", "List items]", "] has no matching [", 1, 11},
+		{"unclosed tag", "Read this:\n<document>\ntext", "<document> is never closed", 2, 1},
+		{"unopened tag", "text\n</document>", "</document> has no matching <document>", 2, 1},
+		{"unclosed fence", "Example:\n```go\nfunc main() {", "code fence is never closed", 2, 1},
+		{"unclosed quote", `Say "hello to the user.`, "quote is never closed", 1, 5},
+		{"mismatched", "Use <a>(x</a>).", "( is never closed", 1, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := Lint(tt.text, Options{})
+			for _, w := range warnings {
+				if w.Rule == RuleUnbalanced && w.Message == tt.message {
+					if w.Line != tt.line || w.Column != tt.column {
+						t.Errorf("Expected position %d:%d, got %d:%d", tt.line, tt.column, w.Line, w.Column)
+					}
+					return
+				}
+			}
+			t.Errorf("Expected warning %q, got %v", tt.message, warnings)
+		})
+	}
+}
+
+func TestLint_BalancedIgnoresCodeAndSelfClosingTags(t *testing.T) {
+	text := "Fix this code:\n```\nif (x {\n```\nInsert <br/> between lines."
+	if warnings := Lint(text, Options{}); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+func TestLint_Conflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"length", "Be concise.\nExplain each step in great detail."},
+		{"format", "Respond in JSON. Later, reply in plain text."},
+		{"tone", "Use a formal tone. Be casual with the user."},
+		{"always never", "Always include sources. Never include sources."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := Lint(tt.text, Options{})
+			if len(warnings) != 1 || warnings[0].Rule != RuleConflict {
+				t.Errorf("Expected one conflict, got %v", warnings)
+			}
+		})
+	}
+
+	if warnings := Lint("Always cite sources. Never invent facts.", Options{}); len(warnings) != 0 {
+		t.Errorf("Expected no conflict for different actions, got %v", warnings)
+	}
+}
+
+func TestLint_Variables(t *testing.T) {
+	text := "Translate to {{.Language}}:\n{{.Text}}\n{{range .Examples}}{{.Input}}{{end}}"
+	warnings := Lint(text, Options{Variables: []string{"Text", "Examples", "Tone"}})
+
+	got := rules(warnings)
+	want := []string{RuleMissingVariable, RuleUnusedVariable}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected rules %v, got %v", want, warnings)
+	}
+	if warnings[0].Line != 1 || !strings.Contains(warnings[0].Message, `"Language"`) {
+		t.Errorf("Expected missing Language on line 1, got %v", warnings[0])
+	}
+	if !strings.Contains(warnings[1].Message, `"Tone"`) || warnings[1].Line != 0 {
+		t.Errorf("Expected unused Tone for the whole prompt, got %v", warnings[1])
+	}
+	if warnings[0].Severity != SeverityError || warnings[1].Severity != SeverityWarning {
+		t.Errorf("Unexpected severities: %v", warnings)
+	}
+
+	// Variables are not checked unless listed
+	if warnings := Lint(text, Options{}); len(warnings) != 0 {
+		t.Errorf("Expected no warnings without Variables, got %v", warnings)
+	}
+}
+
+func TestLint_TemplateSyntax(t *testing.T) {
+	warnings := Lint("Hello {{.Name", Options{Variables: []string{"Name"}})
+	if got := rules(warnings); got[len(got)-1] != RuleTemplateSyntax || !HasErrors(warnings) {
+		t.Errorf("Expected a template syntax error, got %v", warnings)
+	}
+}
+
+func TestLint_ContextWindow(t *testing.T) {
+	text := strings.Repeat("word ", 400)
+	warnings := Lint(text, Options{ContextWindow: 100})
+	if len(warnings) != 1 || warnings[0].Rule != RuleContextWindow || warnings[0].Severity != SeverityError {
+		t.Fatalf("Expected a context window error, got %v", warnings)
+	}
+
+	if warnings := Lint(text, Options{ContextWindow: 10000}); len(warnings) != 0 {
+		t.Errorf("Expected no warnings within the window, got %v", warnings)
+	}
+	if warnings := Lint(text, Options{ContextWindow: 10000, ReservedTokens: 9950}); len(warnings) != 1 {
+		t.Errorf("Expected reserved tokens to shrink the window, got %v", warnings)
+	}
+}
+
+func TestLint_Disable(t *testing.T) {
+	warnings := Lint("Be concise (and detailed. Be thorough.", Options{Disable: []string{RuleConflict}})
+	if got := rules(warnings); len(got) != 1 || got[0] != RuleUnbalanced {
+		t.Errorf("Expected only the unbalanced warning, got %v", warnings)
+	}
+}
+
+func TestLint_Order(t *testing.T) {
+	text := "{{.Missing}}\nBe concise. Be thorough.\n(unclosed"
+	warnings := Lint(text, Options{Variables: []string{"Unused"}})
+	lines := make([]int, len(warnings))
+	for i, w := range warnings {
+		lines[i] = w.Line
+	}
+	want := []int{1, 2, 3, 0}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d warnings, got %v", len(want), warnings)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Expected lines %v, got %v", want, lines)
+			break
+		}
+	}
+}
+
+func TestWarningString(t *testing.T) {
+	w := Warning{Rule: RuleUnbalanced, Severity: SeverityWarning, Message: "( is never closed", Line: 2, Column: 4}
+	if got, want := w.String(), "2:4: warning: ( is never closed (unbalanced-delimiter)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	w.Line = 0
+	if got, want := w.String(), "warning: ( is never closed (unbalanced-delimiter)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,86 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ajeet-kumar1087/ai-providers/prompt"
+)
+
+// applyCompletionPrompt prepends the request's versioned prompt to the
+// prompt, returning the version used or nil if the request selects none
+func (c *client) applyCompletionPrompt(ctx context.Context, req CompletionRequest) (CompletionRequest, *PromptRef, error) {
+	if req.PromptName == "" {
+		return req, nil, nil
+	}
+	text, ref, err := c.renderPrompt(ctx, req.PromptName, req.PromptVersion, req.PromptVars)
+	if err != nil {
+		return req, nil, err
+	}
+
+	req.Prompt = text + "\n\n" + req.Prompt
+	req.PromptName, req.PromptVersion, req.PromptVars = "", "", nil
+	return req, ref, nil
+}
+
+// applyChatPrompt sends the request's versioned prompt as the system
+// message, returning the version used or nil if the request selects none
+func (c *client) applyChatPrompt(ctx context.Context, req ChatRequest) (ChatRequest, *PromptRef, error) {
+	if req.PromptName == "" {
+		return req, nil, nil
+	}
+	text, ref, err := c.renderPrompt(ctx, req.PromptName, req.PromptVersion, req.PromptVars)
+	if err != nil {
+		return req, nil, err
+	}
+
+	req.Messages = withSystemPrompt(req.Messages, text)
+	req.PromptName, req.PromptVersion, req.PromptVars = "", "", nil
+	return req, ref, nil
+}
+
+// renderPrompt looks up a versioned prompt and renders it with vars. The
+// version defaults to the client's pin, then to the latest version.
+func (c *client) renderPrompt(ctx context.Context, name, version string, vars map[string]interface{}) (string, *PromptRef, error) {
+	if c.config.Prompts == nil {
+		return "", nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("prompt %q requested but no prompt store is configured", name),
+			Provider: string(c.provider),
+		}
+	}
+	if version == "" {
+		version = c.config.PromptVersions[name]
+	}
+
+	found, err := c.config.Prompts.GetPrompt(ctx, name, version)
+	if err != nil {
+		return "", nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("failed to load prompt %q: %v", name, err),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	}
+	tmpl, err := prompt.NewTemplate(found.Content)
+	if err != nil {
+		return "", nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("prompt %q version %s: %v", name, found.Version, err),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	}
+	text, err := tmpl.Render(vars)
+	if err != nil {
+		return "", nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("prompt %q version %s: %v", name, found.Version, err),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	}
+
+	ref := found.Ref()
+	return text, &ref, nil
+}
@@ -0,0 +1,118 @@
+package aiprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/prompt"
+)
+
+func newTestPrompts(t *testing.T) *prompt.Registry {
+	t.Helper()
+	prompts := prompt.NewRegistry()
+	for version, content := range map[string]string{
+		"1.0.0": "You support {{.product}}.",
+		"1.1.0": "You cheerfully support {{.product}}.",
+	} {
+		if _, err := prompts.Register("support", version, content); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	return prompts
+}
+
+func TestChatComplete_VersionedPrompt(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Hi"}}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	recorder := &recordingUsageRecorder{}
+	c.config.UsageRecorder = recorder
+	c.config.Prompts = newTestPrompts(t)
+	c.config.PromptVersions = map[string]string{"support": "1.0.0"}
+
+	req := ChatRequest{
+		PromptName: "support",
+		PromptVars: map[string]interface{}{"product": "Widgets"},
+		Messages:   []Message{{Role: "user", Content: "Hello"}},
+	}
+	resp, err := c.ChatComplete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sent := adapter.chatRequests[0]
+	if sent.Messages[0].Role != "system" || sent.Messages[0].Content != "You support Widgets." {
+		t.Errorf("Expected the pinned version as the system message, got %+v", sent.Messages)
+	}
+	if sent.PromptName != "" || sent.PromptVars != nil {
+		t.Errorf("Expected the prompt selection to be resolved before sending, got %+v", sent)
+	}
+	ref := resp.Metadata.Prompt
+	if ref == nil || ref.Name != "support" || ref.Version != "1.0.0" || ref.Hash != prompt.Hash("You support {{.product}}.") {
+		t.Errorf("Expected the pinned version in the metadata, got %+v", ref)
+	}
+	if got := recorder.records[0].Prompt; got == nil || *got != *ref {
+		t.Errorf("Expected the prompt version in the usage record, got %+v", got)
+	}
+
+	// The request overrides the pin
+	req.PromptVersion = "1.1.0"
+	resp, err = c.ChatComplete(context.Background(), req)
+	if err != nil || resp.Metadata.Prompt.Version != "1.1.0" {
+		t.Errorf("Expected the requested version, got %+v, %v", resp, err)
+	}
+
+	// Unpinned prompts use the latest version
+	c.config.PromptVersions = nil
+	adapter.completeResp = &CompletionResponse{Text: "Hi"}
+	completion, err := c.Complete(context.Background(), CompletionRequest{
+		Prompt:     "Hello",
+		PromptName: "support",
+		PromptVars: map[string]interface{}{"product": "Gadgets"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := adapter.completeRequests[len(adapter.completeRequests)-1].Prompt; got != "You cheerfully support Gadgets.\n\nHello" {
+		t.Errorf("Expected the latest version before the prompt, got %q", got)
+	}
+	if completion.Metadata.Prompt.Version != "1.1.0" {
+		t.Errorf("Expected the latest version in the metadata, got %+v", completion.Metadata.Prompt)
+	}
+
+	for name, bad := range map[string]ChatRequest{
+		"unknown prompt":   {PromptName: "billing", Messages: req.Messages},
+		"unknown version":  {PromptName: "support", PromptVersion: "9.0.0", Messages: req.Messages},
+		"missing variable": {PromptName: "support", Messages: req.Messages},
+	} {
+		if _, err := c.ChatComplete(context.Background(), bad); err == nil {
+			t.Errorf("Expected an error for the %s", name)
+		}
+	}
+	c.config.Prompts = nil
+	if _, err := c.ChatComplete(context.Background(), req); err == nil {
+		t.Error("Expected an error without a prompt store")
+	}
+}
+
+func TestChatComplete_PromptVersionRollout(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Hi"}}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.Prompts = newTestPrompts(t)
+	c.config.PromptVersions = map[string]string{"support": "1.0.0"}
+	c.config.Experiments = []Experiment{{
+		Name:     "support-prompt",
+		Variants: []ExperimentVariant{{Name: "v1.1", Percent: 100, PromptVersion: "1.1.0"}},
+	}}
+
+	resp, err := c.ChatComplete(context.Background(), ChatRequest{
+		UserID:     "user-1",
+		PromptName: "support",
+		PromptVars: map[string]interface{}{"product": "Widgets"},
+		Messages:   []Message{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Metadata.Prompt.Version != "1.1.0" || resp.Metadata.Experiments["support-prompt"] != "v1.1" {
+		t.Errorf("Expected the variant's prompt version, got %+v", resp.Metadata)
+	}
+}
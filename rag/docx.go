@@ -0,0 +1,173 @@
+package rag
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DOCX loads Word documents. Paragraphs become lines separated by blank
+// lines, table rows tab-separated lines and headings Markdown headings, so
+// textsplit.Markdown can split at them; the document title becomes the
+// "title" metadata.
+type DOCX struct {
+	// MaxDecodedSize limits the decompressed size of each part of the file
+	// read (default: DefaultMaxDecodedSize)
+	MaxDecodedSize int64
+}
+
+// Load implements Loader
+func (d DOCX) Load(r io.Reader) (Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Document{}, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Document{}, fmt.Errorf("not a DOCX file: %w", err)
+	}
+
+	limit := maxDecodedSize(d.MaxDecodedSize)
+	body, err := readZipFile(archive, "word/document.xml", limit)
+	if err != nil {
+		return Document{}, err
+	}
+	text, err := docxText(body)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	var title string
+	if core, err := readZipFile(archive, "docProps/core.xml", limit); err == nil {
+		var props struct {
+			Title string `xml:"title"`
+		}
+		if xml.Unmarshal(core, &props) == nil {
+			title = strings.TrimSpace(props.Title)
+		}
+	}
+	return newDocument(text, title, nil), nil
+}
+
+// readZipFile returns the content of the named file in an archive, failing
+// if it decompresses to more than limit bytes
+func readZipFile(archive *zip.Reader, name string, limit int64) ([]byte, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("not a DOCX file: %w", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s: %w", name, decodedSizeError(limit))
+	}
+	return data, nil
+}
+
+// docxText extracts the text of a WordprocessingML document body
+func docxText(body []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	var out, paragraph strings.Builder
+	heading := 0
+	inText := false
+	// Paragraphs in table cells are joined by spaces, cells by tabs
+	inCell, cells, cell := 0, 0, ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				paragraph.Reset()
+				heading = 0
+			case "pStyle":
+				heading = headingLevel(xmlAttr(t, "val"))
+			case "t":
+				inText = true
+			case "tab":
+				paragraph.WriteString("\t")
+			case "br", "cr":
+				paragraph.WriteString("\n")
+			case "tr":
+				cells = 0
+			case "tc":
+				inCell++
+				cell = ""
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := strings.TrimSpace(paragraph.String())
+				switch {
+				case text == "":
+				case inCell > 0:
+					if cell != "" {
+						cell += " "
+					}
+					cell += strings.Join(strings.Fields(text), " ")
+				case heading > 0:
+					out.WriteString(strings.Repeat("#", heading) + " " + text + "\n\n")
+				default:
+					out.WriteString(text + "\n\n")
+				}
+			case "tc":
+				inCell--
+				if cells > 0 {
+					out.WriteString("\t")
+				}
+				out.WriteString(cell)
+				cells++
+			case "tr", "tbl":
+				out.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(t)
+			}
+		}
+	}
+	return cleanText(out.String()), nil
+}
+
+// headingLevel returns the level of a heading paragraph style, such as 2
+// for "Heading2", 1 for "Title" and 0 for other styles
+func headingLevel(style string) int {
+	switch {
+	case style == "Title":
+		return 1
+	case strings.HasPrefix(style, "Heading") && len(style) == len("Heading")+1:
+		if level := style[len("Heading")]; level >= '1' && level <= '6' {
+			return int(level - '0')
+		}
+	}
+	return 0
+}
+
+// xmlAttr returns the value of an attribute by local name
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
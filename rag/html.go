@@ -0,0 +1,188 @@
+package rag
+
+import (
+	"html"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// HTML loads HTML documents. Markup, scripts and styles are removed,
+// block elements become line breaks and list items "- " lines; the
+// <title> becomes the "title" metadata.
+type HTML struct{}
+
+// skippedElements are elements whose content is not text
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "canvas": true, "iframe": true, "object": true,
+}
+
+// blockElements are elements that start a new paragraph
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "ul": true,
+}
+
+// Load implements Loader
+func (HTML) Load(r io.Reader) (Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Document{}, err
+	}
+	src := string(data)
+
+	var out strings.Builder
+	var title string
+	pre := 0
+	for i := 0; i < len(src); {
+		if src[i] != '<' {
+			end := strings.IndexByte(src[i:], '<')
+			if end < 0 {
+				end = len(src) - i
+			}
+			writeHTMLText(&out, src[i:i+end], pre > 0)
+			i += end
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(src[i:], "<!--"):
+			i = skipPast(src, i, "-->")
+			continue
+		case strings.HasPrefix(src[i:], "<!"), strings.HasPrefix(src[i:], "<?"):
+			i = skipPast(src, i, ">")
+			continue
+		}
+
+		name, closing, end := parseTag(src, i)
+		if name == "" {
+			// A lone "<" is text
+			writeHTMLText(&out, "<", pre > 0)
+			i++
+			continue
+		}
+		i = end
+
+		switch {
+		case closing:
+			if name == "pre" && pre > 0 {
+				pre--
+			}
+			if blockElements[name] || name == "tr" {
+				out.WriteString("\n")
+			}
+		case name == "title":
+			endTag := indexFold(src[i:], "</title")
+			if endTag < 0 {
+				endTag = len(src) - i
+			}
+			title = strings.Join(strings.Fields(html.UnescapeString(src[i:i+endTag])), " ")
+			i = skipPast(src, i+endTag, ">")
+		case skippedElements[name]:
+			if endTag := indexFold(src[i:], "</"+name); endTag >= 0 {
+				i = skipPast(src, i+endTag, ">")
+			} else {
+				i = len(src)
+			}
+		case name == "br":
+			out.WriteString("\n")
+		case name == "li":
+			out.WriteString("\n- ")
+		case name == "td" || name == "th":
+			out.WriteString("\t")
+		case blockElements[name]:
+			out.WriteString("\n\n")
+			if name == "pre" {
+				pre++
+			}
+		}
+	}
+	return newDocument(cleanText(out.String()), title, nil), nil
+}
+
+// writeHTMLText writes decoded text, collapsing whitespace outside <pre>
+func writeHTMLText(out *strings.Builder, text string, pre bool) {
+	text = html.UnescapeString(text)
+	if pre {
+		out.WriteString(text)
+		return
+	}
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		if text != "" {
+			out.WriteString(" ")
+		}
+		return
+	}
+	if strings.TrimLeftFunc(text, unicode.IsSpace) != text {
+		out.WriteString(" ")
+	}
+	out.WriteString(strings.Join(fields, " "))
+	if strings.TrimRightFunc(text, unicode.IsSpace) != text {
+		out.WriteString(" ")
+	}
+}
+
+// parseTag parses the tag at src[i], returning its lowercase name, whether
+// it is a closing tag and the index after it. The name is empty if src[i]
+// does not start a tag.
+func parseTag(src string, i int) (name string, closing bool, end int) {
+	j := i + 1
+	if j < len(src) && src[j] == '/' {
+		closing = true
+		j++
+	}
+	start := j
+	for j < len(src) && (isASCIILetter(src[j]) || (j > start && src[j] >= '0' && src[j] <= '9')) {
+		j++
+	}
+	if j == start {
+		return "", false, i
+	}
+	name = strings.ToLower(src[start:j])
+
+	// Skip attributes, whose quoted values may contain ">"
+	var quote byte
+	for ; j < len(src); j++ {
+		switch c := src[j]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return name, closing, j + 1
+		}
+	}
+	return name, closing, len(src)
+}
+
+// skipPast returns the index after the first occurrence of marker at or
+// after i, or the end of src
+func skipPast(src string, i int, marker string) int {
+	if end := strings.Index(src[i:], marker); end >= 0 {
+		return i + end + len(marker)
+	}
+	return len(src)
+}
+
+// indexFold returns the index of the first case-insensitive occurrence of
+// an ASCII substr in s, or -1
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,195 @@
+package rag
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxDecodedSize is the default limit on the decompressed data the
+// PDF and DOCX loaders read from one file, so a small compressed file
+// cannot expand without bound
+const DefaultMaxDecodedSize = 64 << 20
+
+// Loader extracts the text of documents in one format.
+type Loader interface {
+	// Load returns the text of the document read from r, with any metadata
+	// the format carries, such as a title. The ID is left to the caller.
+	Load(r io.Reader) (Document, error)
+}
+
+// LoaderFunc adapts a function to the Loader interface
+type LoaderFunc func(r io.Reader) (Document, error)
+
+// Load implements Loader
+func (f LoaderFunc) Load(r io.Reader) (Document, error) {
+	return f(r)
+}
+
+// DefaultLoaders returns the built-in loaders by lowercase file extension.
+// They are written in pure Go and extract plain text only; tables become
+// tab-separated lines, and images and scanned pages yield no text.
+func DefaultLoaders() map[string]Loader {
+	return map[string]Loader{
+		".txt":      Text{},
+		".md":       Markdown{},
+		".markdown": Markdown{},
+		".html":     HTML{},
+		".htm":      HTML{},
+		".docx":     DOCX{},
+		".pdf":      PDF{},
+	}
+}
+
+// LoadFile loads the document at path with the loader for its extension
+// in loaders, or in DefaultLoaders if loaders is nil. The document ID is
+// the file name and its "source" metadata the path.
+func LoadFile(path string, loaders map[string]Loader) (Document, error) {
+	if loaders == nil {
+		loaders = DefaultLoaders()
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	loader, ok := loaders[ext]
+	if !ok {
+		return Document{}, fmt.Errorf("no loader for %q files: %s", ext, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return Document{}, err
+	}
+	defer file.Close()
+
+	doc, err := loader.Load(file)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	if doc.ID == "" {
+		doc.ID = filepath.Base(path)
+	}
+	if doc.Metadata == nil {
+		doc.Metadata = make(map[string]string)
+	}
+	doc.Metadata["source"] = path
+	return doc, nil
+}
+
+// AddFiles loads, chunks, embeds and indexes files in one call, choosing a
+// loader by file extension from IndexOptions.Loaders. On error no chunks
+// are added.
+//
+// Example:
+//
+//	err := index.AddFiles(ctx, "handbook.pdf", "faq.md", "policies.docx")
+func (ix *Index) AddFiles(ctx context.Context, paths ...string) error {
+	docs := make([]Document, 0, len(paths))
+	for _, path := range paths {
+		doc, err := LoadFile(path, ix.options.Loaders)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+	return ix.Add(ctx, docs...)
+}
+
+// Text loads plain text documents.
+type Text struct{}
+
+// Load implements Loader
+func (Text) Load(r io.Reader) (Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Document{}, err
+	}
+	return Document{Text: string(data)}, nil
+}
+
+// Markdown loads Markdown documents, keeping their syntax for
+// textsplit.Markdown to split at headings. YAML front matter is removed,
+// and its title, or else the first heading, becomes the "title" metadata.
+type Markdown struct{}
+
+// Load implements Loader
+func (Markdown) Load(r io.Reader) (Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Document{}, err
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	var title string
+
+	if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		if end := strings.Index(rest, "\n---\n"); end >= 0 {
+			for _, line := range strings.Split(rest[:end], "\n") {
+				if value, ok := strings.CutPrefix(line, "title:"); ok {
+					title = strings.Trim(strings.TrimSpace(value), `"'`)
+				}
+			}
+			text = rest[end+len("\n---\n"):]
+		}
+	}
+	if title == "" {
+		for _, line := range strings.Split(text, "\n") {
+			if heading, ok := strings.CutPrefix(line, "# "); ok {
+				title = strings.TrimSpace(heading)
+				break
+			}
+		}
+	}
+	return newDocument(text, title, nil), nil
+}
+
+// newDocument returns a document with the title metadata if there is one,
+// and any other metadata
+func newDocument(text, title string, metadata map[string]string) Document {
+	if title != "" {
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata["title"] = title
+	}
+	return Document{Text: text, Metadata: metadata}
+}
+
+// cleanText trims the lines of extracted text and collapses runs of blank
+// lines, so paragraphs are separated by exactly one
+func cleanText(text string) string {
+	lines := strings.Split(text, "\n")
+	var out bytes.Buffer
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			blank++
+			continue
+		}
+		if out.Len() > 0 {
+			if blank > 0 {
+				out.WriteString("\n\n")
+			} else {
+				out.WriteByte('\n')
+			}
+		}
+		blank = 0
+		out.WriteString(line)
+	}
+	return out.String()
+}
+
+// maxDecodedSize returns limit, or DefaultMaxDecodedSize if it is not positive
+func maxDecodedSize(limit int64) int64 {
+	if limit <= 0 {
+		return DefaultMaxDecodedSize
+	}
+	return limit
+}
+
+// decodedSizeError reports decompressed data beyond limit
+func decodedSizeError(limit int64) error {
+	return fmt.Errorf("decompressed data exceeds the limit of %d bytes", limit)
+}
@@ -0,0 +1,273 @@
+package rag
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildPDF returns a PDF file of objects numbered from 1, with the given
+// trailer entries
+func buildPDF(trailer string, objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, xref)
+	return buf.Bytes()
+}
+
+// pdfStreamObject returns a stream object, Flate-compressed if flate is set
+func pdfStreamObject(dict, data string, flate bool) string {
+	if flate {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write([]byte(data))
+		w.Close()
+		data = buf.String()
+		dict += " /Filter /FlateDecode"
+	}
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func TestPDF_Load(t *testing.T) {
+	cmap := `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CMapName /Test def
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+1 beginbfchar <0001> <00E9> endbfchar
+1 beginbfrange <0002> <0004> <0061> endbfrange
+endcmap
+end end`
+	data := buildPDF("/Root 1 0 R /Info 9 0 R",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 6 0 R /F2 7 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [10 0 R] /Count 1 >>",
+		pdfStreamObject("", `BT /F1 12 Tf 72 720 Td (Hello) Tj ( World) Tj 0 -14 Td [(Sec) 20 (ond) -300 (line)] TJ ET
+BT 1 0 0 1 72 680 Tm (\(escaped\)) Tj (\223quoted\224) ' ET
+q 10 0 0 10 0 0 cm BI /W 2 /H 1 /BPC 8 /CS /G ID `+"\x00EI"+` EI Q`, false),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /Encoding /Identity-H /ToUnicode 8 0 R >>",
+		pdfStreamObject("", cmap, true),
+		"<< /Title <FEFF00480061006E00640062006F006F006B> >>",
+		"<< /Type /Page /Parent 4 0 R /Contents [11 0 R] >>",
+		pdfStreamObject("", "BT /F2 11 Tf 72 720 Td <0001000200030004> Tj ET", true),
+	)
+
+	doc, err := PDF{}.Load(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "Hello World\nSecond line\n(escaped)\n“quoted”\n\néabc"
+	if doc.Text != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Text)
+	}
+	if doc.Metadata["title"] != "Handbook" || doc.Metadata["pages"] != "2" {
+		t.Errorf("Expected the title and page count, got %v", doc.Metadata)
+	}
+}
+
+func TestPDF_Errors(t *testing.T) {
+	tests := map[string][]byte{
+		"not a PDF": []byte("plain text"),
+		"encrypted": buildPDF("/Root 1 0 R /Encrypt 2 0 R",
+			"<< /Type /Catalog /Pages 3 0 R >>",
+			"<< /Filter /Standard /V 2 >>",
+			"<< /Type /Pages /Kids [] /Count 0 >>",
+		),
+		"no pages": buildPDF("/Root 1 0 R", "<< /Type /Catalog >>"),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := (PDF{}).Load(bytes.NewReader(data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestPDF_MaxDecodedSize(t *testing.T) {
+	text := "BT /F1 12 Tf 72 720 Td (" + strings.Repeat("a", 5000) + ") Tj ET"
+	data := buildPDF("/Root 1 0 R",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		pdfStreamObject("", text, true),
+	)
+
+	if _, err := (PDF{MaxDecodedSize: 1000}).Load(bytes.NewReader(data)); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Errorf("Expected a decompressed size error, got %v", err)
+	}
+	if _, err := (PDF{}).Load(bytes.NewReader(data)); err != nil {
+		t.Errorf("Expected the default limit to allow the file, got %v", err)
+	}
+}
+
+func TestHTML_Load(t *testing.T) {
+	src := `<!DOCTYPE html>
+<html><head><title>Leave &amp; Holidays</title>
+<style>p { color: red; }</style>
+<script>if (a < b) { document.write("<p>no</p>"); }</script></head>
+<body>
+<!-- navigation -->
+<h1>Vacation</h1>
+<p>Employees get <b>25&nbsp;days</b>
+   per year.</p>
+<ul><li>Full time</li><li data-note="a > b">Part time</li></ul>
+<table><tr><th>Type</th><th>Days</th></tr><tr><td>Sick</td><td>10</td></tr></table>
+<pre>line 1
+  line 2</pre>
+</body></html>`
+
+	doc, err := HTML{}.Load(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "Vacation\n\nEmployees get 25 days per year.\n\n- Full time\n- Part time\n\n\tType\tDays\n\tSick\t10\n\nline 1\n  line 2"
+	if doc.Text != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Text)
+	}
+	if doc.Metadata["title"] != "Leave & Holidays" {
+		t.Errorf("Expected the title, got %v", doc.Metadata)
+	}
+}
+
+func TestDOCX_Load(t *testing.T) {
+	const document = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Vacation</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Employees get </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>25 days</w:t></w:r><w:r><w:tab/><w:t>a year.</w:t></w:r></w:p>
+<w:p/>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Sick</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>10</w:t></w:r></w:p><w:p><w:r><w:t>days</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+<w:p><w:r><w:t>Last</w:t><w:br/><w:t>line</w:t></w:r></w:p>
+</w:body></w:document>`
+	const core = `<?xml version="1.0" encoding="UTF-8"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Handbook</dc:title></cp:coreProperties>`
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{"word/document.xml": document, "docProps/core.xml": core} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	archive.Close()
+
+	doc, err := DOCX{}.Load(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "# Vacation\n\nEmployees get 25 days\ta year.\n\nSick\t10 days\n\nLast\nline"
+	if doc.Text != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Text)
+	}
+	if doc.Metadata["title"] != "Handbook" {
+		t.Errorf("Expected the title, got %v", doc.Metadata)
+	}
+
+	if _, err := (DOCX{}).Load(strings.NewReader("not a zip")); err == nil {
+		t.Error("Expected an error for a file that is not a DOCX file")
+	}
+}
+
+func TestDOCX_MaxDecodedSize(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, err := archive.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>` +
+		strings.Repeat("a", 5000) + `</w:t></w:r></w:p></w:body></w:document>`))
+	archive.Close()
+
+	if _, err := (DOCX{MaxDecodedSize: 1000}).Load(bytes.NewReader(buf.Bytes())); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Errorf("Expected a decompressed size error, got %v", err)
+	}
+	if _, err := (DOCX{}).Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Errorf("Expected the default limit to allow the file, got %v", err)
+	}
+}
+
+func TestMarkdown_Load(t *testing.T) {
+	doc, err := Markdown{}.Load(strings.NewReader("---\ntitle: \"Handbook\"\ndraft: true\n---\n# Vacation\n\n25 days.\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if doc.Text != "# Vacation\n\n25 days.\n" || doc.Metadata["title"] != "Handbook" {
+		t.Errorf("Expected the front matter removed and its title, got %+v", doc)
+	}
+
+	doc, _ = Markdown{}.Load(strings.NewReader("Intro\n# Vacation\n"))
+	if doc.Metadata["title"] != "Vacation" {
+		t.Errorf("Expected the first heading as the title, got %v", doc.Metadata)
+	}
+}
+
+func TestIndex_AddFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"faq.md":     "# FAQ\n\nVacation requests go to HR.",
+		"page.HTML":  "<title>Office</title><p>The office opens at 8.</p>",
+		"notes.json": "{}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	embedder := &keywordEmbedder{keywords: []string{"vacation", "office"}}
+	index := NewIndex(embedder, IndexOptions{})
+	err := index.AddFiles(context.Background(), filepath.Join(dir, "faq.md"), filepath.Join(dir, "page.HTML"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results, err := index.Search(context.Background(), "office hours", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	chunk := results[0].Chunk
+	if chunk.DocumentID != "page.HTML" || chunk.Text != "The office opens at 8." {
+		t.Errorf("Expected the HTML text, got %+v", chunk)
+	}
+	if chunk.Metadata["title"] != "Office" || chunk.Metadata["source"] != filepath.Join(dir, "page.HTML") {
+		t.Errorf("Expected the title and source metadata, got %v", chunk.Metadata)
+	}
+
+	// Files without a loader fail the call, unless a custom loader is given
+	if err := index.AddFiles(context.Background(), filepath.Join(dir, "notes.json")); err == nil {
+		t.Error("Expected an error for a file without a loader")
+	}
+	loaders := DefaultLoaders()
+	loaders[".json"] = LoaderFunc(func(r io.Reader) (Document, error) {
+		return Document{ID: "notes", Text: "office notes"}, nil
+	})
+	index = NewIndex(embedder, IndexOptions{Loaders: loaders})
+	if err := index.AddFiles(context.Background(), filepath.Join(dir, "notes.json")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results, _ := index.Search(context.Background(), "office", 1); results[0].Chunk.DocumentID != "notes" {
+		t.Errorf("Expected the custom loader to be used, got %+v", results[0].Chunk)
+	}
+}
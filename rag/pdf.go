@@ -0,0 +1,532 @@
+package rag
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// PDF loads PDF documents, extracting the text of their pages in order,
+// separated by blank lines. The document title becomes the "title"
+// metadata and the page count "pages".
+//
+// Text is decoded through the ToUnicode maps of fonts, or their encoding
+// for simple fonts; text in embedded fonts without either, in images and
+// in encrypted files cannot be extracted.
+type PDF struct {
+	// MaxDecodedSize limits the total decompressed size of the streams
+	// read (default: DefaultMaxDecodedSize)
+	MaxDecodedSize int64
+}
+
+// Load implements Loader
+func (p PDF) Load(r io.Reader) (Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Document{}, err
+	}
+	file, err := parsePDF(data, maxDecodedSize(p.MaxDecodedSize))
+	if err != nil {
+		return Document{}, err
+	}
+	pages := file.pages()
+	if len(pages) == 0 {
+		return Document{}, errors.New("PDF file has no pages")
+	}
+
+	var out strings.Builder
+	for _, page := range pages {
+		text := &pdfText{file: file}
+		text.content(file.pageContent(page.dict), page.resources, 0)
+		out.WriteString(text.out.String())
+		out.WriteString("\n\n")
+	}
+	if file.decodeErr != nil {
+		return Document{}, file.decodeErr
+	}
+
+	var title string
+	if info := file.dict(file.trailer["Info"]); info != nil {
+		if s, ok := file.resolve(info["Title"]).([]byte); ok {
+			title = strings.TrimSpace(pdfTextString(s))
+		}
+	}
+	metadata := map[string]string{"pages": strconv.Itoa(len(pages))}
+	return newDocument(cleanText(out.String()), title, metadata), nil
+}
+
+// pdfPage is a page with the resources it uses, which may be inherited
+// from the page tree
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages returns the pages of the document in order
+func (f *pdfFile) pages() []pdfPage {
+	root := f.dict(f.trailer["Root"])
+	if root == nil {
+		for _, num := range f.objectNumbers() {
+			if dict := f.dict(f.objects[num]); dict["Type"] == pdfName("Catalog") {
+				root = dict
+				break
+			}
+		}
+	}
+
+	var pages []pdfPage
+	if root != nil {
+		f.walkPages(root["Pages"], nil, make(map[int]bool), &pages, 0)
+	}
+	if len(pages) == 0 {
+		// Without a page tree, take the page objects in object order
+		for _, num := range f.objectNumbers() {
+			if dict, ok := f.objects[num].(pdfDict); ok && dict["Type"] == pdfName("Page") {
+				pages = append(pages, pdfPage{dict: dict, resources: f.dict(dict["Resources"])})
+			}
+		}
+	}
+	return pages
+}
+
+// walkPages appends the pages under a node of the page tree
+func (f *pdfFile) walkPages(node interface{}, resources pdfDict, seen map[int]bool, pages *[]pdfPage, depth int) {
+	if ref, ok := node.(pdfRef); ok {
+		if seen[ref.num] {
+			return
+		}
+		seen[ref.num] = true
+	}
+	dict := f.dict(node)
+	if dict == nil || depth > pdfMaxDepth {
+		return
+	}
+	if r := f.dict(dict["Resources"]); r != nil {
+		resources = r
+	}
+
+	kids, ok := f.resolve(dict["Kids"]).([]interface{})
+	if !ok || dict["Type"] == pdfName("Page") {
+		*pages = append(*pages, pdfPage{dict: dict, resources: resources})
+		return
+	}
+	for _, kid := range kids {
+		f.walkPages(kid, resources, seen, pages, depth+1)
+	}
+}
+
+// pageContent returns the decoded content streams of a page
+func (f *pdfFile) pageContent(page pdfDict) []byte {
+	var streams []interface{}
+	switch contents := f.resolve(page["Contents"]).(type) {
+	case []interface{}:
+		streams = contents
+	case *pdfStream:
+		streams = []interface{}{contents}
+	}
+
+	var content []byte
+	for _, s := range streams {
+		if stream := f.stream(s); stream != nil {
+			if data, err := f.decode(stream); err == nil {
+				content = append(append(content, data...), '\n')
+			}
+		}
+	}
+	return content
+}
+
+// pdfText extracts the text shown by content streams
+type pdfText struct {
+	file  *pdfFile
+	out   strings.Builder
+	font  *pdfFont
+	y     float64 // Vertical position in the current text object
+	lineY float64 // Vertical position of the last line written
+}
+
+// pdfWordSpace is the TJ adjustment, in thousandths of an em, taken to
+// separate words
+const pdfWordSpace = 200
+
+// content extracts the text of a content stream
+func (t *pdfText) content(data []byte, resources pdfDict, depth int) {
+	fonts := make(map[pdfName]*pdfFont)
+	l := &pdfLexer{data: data}
+	var operands []interface{}
+	for {
+		token, err := l.next()
+		if err != nil {
+			return
+		}
+		v, err := l.value(token, 0)
+		if err != nil {
+			return
+		}
+		op, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+
+		switch op {
+		case "BT":
+			t.y = 0
+		case "Tf":
+			if len(operands) >= 1 {
+				if name, ok := operands[0].(pdfName); ok {
+					t.font = t.file.font(resources, name, fonts)
+				}
+			}
+		case "Tj":
+			t.show(lastOperand(operands))
+		case "'", "\"":
+			t.newline()
+			t.show(lastOperand(operands))
+		case "TJ":
+			array, _ := lastOperand(operands).([]interface{})
+			for _, item := range array {
+				if n, ok := item.(float64); ok && n < -pdfWordSpace {
+					t.space()
+				}
+				t.show(item)
+			}
+		case "T*":
+			t.newline()
+		case "Td", "TD":
+			if len(operands) == 2 {
+				dy, _ := operands[1].(float64)
+				t.y += dy
+				t.moveTo(t.y)
+			}
+		case "Tm":
+			if len(operands) == 6 {
+				t.y, _ = operands[5].(float64)
+				t.moveTo(t.y)
+			}
+		case "ID":
+			// Skip the data of inline images, which ends at EI
+			for l.pos < len(l.data) {
+				end := bytes.Index(l.data[l.pos:], []byte("EI"))
+				if end < 0 {
+					l.pos = len(l.data)
+					break
+				}
+				l.pos += end + 2
+				if (end == 0 || isPDFSpace(l.data[l.pos-3])) && (l.pos == len(l.data) || isPDFSpace(l.data[l.pos])) {
+					break
+				}
+			}
+		case "Do":
+			if len(operands) == 1 && depth < 8 {
+				if name, ok := operands[0].(pdfName); ok {
+					t.form(resources, name, depth)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// form extracts the text of a form XObject
+func (t *pdfText) form(resources pdfDict, name pdfName, depth int) {
+	form := t.file.stream(t.file.dict(resources["XObject"])[name])
+	if form == nil || form.dict["Subtype"] != pdfName("Form") {
+		return
+	}
+	data, err := t.file.decode(form)
+	if err != nil {
+		return
+	}
+	if r := t.file.dict(form.dict["Resources"]); r != nil {
+		resources = r
+	}
+	font := t.font
+	t.content(data, resources, depth+1)
+	t.font = font
+}
+
+// lastOperand returns the last operand of an operator, or nil
+func lastOperand(operands []interface{}) interface{} {
+	if len(operands) == 0 {
+		return nil
+	}
+	return operands[len(operands)-1]
+}
+
+// show writes a string shown in the current font
+func (t *pdfText) show(v interface{}) {
+	if s, ok := v.([]byte); ok {
+		t.out.WriteString(t.font.decode(s))
+	}
+}
+
+// moveTo starts a new line if the text moves vertically
+func (t *pdfText) moveTo(y float64) {
+	if math.Abs(y-t.lineY) > 0.5 {
+		t.newline()
+	} else {
+		t.space()
+	}
+	t.lineY = y
+}
+
+func (t *pdfText) newline() {
+	if s := t.out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		t.out.WriteString("\n")
+	}
+}
+
+func (t *pdfText) space() {
+	if s := t.out.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		t.out.WriteString(" ")
+	}
+}
+
+// pdfFont decodes the strings shown in a font
+type pdfFont struct {
+	codeBytes   int               // Bytes per character code
+	toUnicode   map[uint32]string // From the font's ToUnicode CMap
+	differences map[byte]string   // From the Differences of simple fonts
+}
+
+// font returns the named font of resources, caching it in fonts
+func (f *pdfFile) font(resources pdfDict, name pdfName, fonts map[pdfName]*pdfFont) *pdfFont {
+	if font, ok := fonts[name]; ok {
+		return font
+	}
+	dict := f.dict(f.dict(resources["Font"])[name])
+	font := &pdfFont{codeBytes: 1}
+	if dict["Subtype"] == pdfName("Type0") {
+		font.codeBytes = 2
+	}
+	if stream := f.stream(dict["ToUnicode"]); stream != nil {
+		if data, err := f.decode(stream); err == nil {
+			var codeBytes int
+			font.toUnicode, codeBytes = parseCMap(data)
+			if codeBytes > 0 {
+				font.codeBytes = codeBytes
+			}
+		}
+	}
+	if encoding := f.dict(dict["Encoding"]); encoding != nil {
+		differences, _ := f.resolve(encoding["Differences"]).([]interface{})
+		code := 0
+		for _, item := range differences {
+			switch v := f.resolve(item).(type) {
+			case float64:
+				code = int(v)
+			case pdfName:
+				if text := glyphText(string(v)); text != "" && code >= 0 && code < 256 {
+					if font.differences == nil {
+						font.differences = make(map[byte]string)
+					}
+					font.differences[byte(code)] = text
+				}
+				code++
+			}
+		}
+	}
+	fonts[name] = font
+	return font
+}
+
+// decode returns the text of a string shown in the font. A nil font, as
+// when the content sets none, decodes as WinAnsi.
+func (font *pdfFont) decode(s []byte) string {
+	if font == nil {
+		font = &pdfFont{codeBytes: 1}
+	}
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		n := font.codeBytes
+		if i+n > len(s) {
+			n = len(s) - i
+		}
+		var code uint32
+		for _, b := range s[i : i+n] {
+			code = code<<8 | uint32(b)
+		}
+		i += n
+
+		if text, ok := font.toUnicode[code]; ok {
+			out.WriteString(text)
+		} else if font.codeBytes > 1 {
+			// Character IDs mean nothing without a ToUnicode map
+			continue
+		} else if text, ok := font.differences[byte(code)]; ok {
+			out.WriteString(text)
+		} else if r := winAnsiRune(byte(code)); r != 0 {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// parseCMap returns the mappings of a ToUnicode CMap and the length of its
+// character codes in bytes
+func parseCMap(data []byte) (map[uint32]string, int) {
+	mappings := make(map[uint32]string)
+	codeBytes := 0
+	l := &pdfLexer{data: data}
+	var operands []interface{}
+	for {
+		token, err := l.next()
+		if err != nil {
+			break
+		}
+		v, err := l.value(token, 0)
+		if err != nil {
+			break
+		}
+		keyword, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+
+		switch keyword {
+		case "endcodespacerange":
+			if low, ok := lastOperand(operands).([]byte); ok && len(operands) >= 2 {
+				codeBytes = len(low)
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					mappings[cmapCode(src)] = utf16Text(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, ok1 := operands[i].([]byte)
+				high, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 || cmapCode(high) < cmapCode(low) || cmapCode(high)-cmapCode(low) > 0xFFFF {
+					continue
+				}
+				start, end := cmapCode(low), cmapCode(high)
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					// Codes map to consecutive characters from dst
+					base := []rune(utf16Text(dst))
+					if len(base) == 0 {
+						continue
+					}
+					for code := start; code <= end; code++ {
+						runes := append([]rune{}, base...)
+						runes[len(runes)-1] += rune(code - start)
+						mappings[code] = string(runes)
+					}
+				case []interface{}:
+					for j, item := range dst {
+						if text, ok := item.([]byte); ok && start+uint32(j) <= end {
+							mappings[start+uint32(j)] = utf16Text(text)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return mappings, codeBytes
+}
+
+// cmapCode returns the character code of a CMap string
+func cmapCode(s []byte) uint32 {
+	var code uint32
+	for _, b := range s {
+		code = code<<8 | uint32(b)
+	}
+	return code
+}
+
+// utf16Text decodes UTF-16BE text
+func utf16Text(s []byte) string {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfTextString decodes a text string, such as a document title, which is
+// UTF-16BE with a byte order mark, UTF-8 with one, or PDFDocEncoding
+func pdfTextString(s []byte) string {
+	switch {
+	case bytes.HasPrefix(s, []byte{0xFE, 0xFF}):
+		return utf16Text(s[2:])
+	case bytes.HasPrefix(s, []byte{0xEF, 0xBB, 0xBF}) && utf8.Valid(s[3:]):
+		return string(s[3:])
+	}
+	var out strings.Builder
+	for _, b := range s {
+		out.WriteRune(rune(b))
+	}
+	return out.String()
+}
+
+// winAnsiHigh maps the codes 0x80 to 0x9F of WinAnsiEncoding, where it
+// differs from Latin-1
+var winAnsiHigh = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// winAnsiRune returns the character of a WinAnsiEncoding code, or 0 for
+// control codes
+func winAnsiRune(code byte) rune {
+	switch {
+	case code == '\t' || code == '\n':
+		return rune(code)
+	case code < 0x20 || code == 0x7F:
+		return 0
+	case code >= 0x80 && code < 0xA0:
+		return winAnsiHigh[code-0x80]
+	}
+	return rune(code)
+}
+
+// glyphNames maps common glyph names of the Adobe Glyph List to their text
+var glyphNames = map[string]string{
+	"space": " ", "exclam": "!", "quotedbl": "\"", "numbersign": "#",
+	"dollar": "$", "percent": "%", "ampersand": "&", "quotesingle": "'",
+	"parenleft": "(", "parenright": ")", "asterisk": "*", "plus": "+",
+	"comma": ",", "hyphen": "-", "period": ".", "slash": "/",
+	"zero": "0", "one": "1", "two": "2", "three": "3", "four": "4",
+	"five": "5", "six": "6", "seven": "7", "eight": "8", "nine": "9",
+	"colon": ":", "semicolon": ";", "less": "<", "equal": "=",
+	"greater": ">", "question": "?", "at": "@", "bracketleft": "[",
+	"backslash": "\\", "bracketright": "]", "underscore": "_",
+	"quoteleft": "‘", "quoteright": "’", "quotedblleft": "“",
+	"quotedblright": "”", "endash": "–", "emdash": "—", "bullet": "•",
+	"ellipsis": "…", "fi": "fi", "fl": "fl", "ff": "ff", "ffi": "ffi",
+	"ffl": "ffl", "minus": "−", "degree": "°", "copyright": "©",
+	"registered": "®", "trademark": "™", "eacute": "é", "egrave": "è",
+	"agrave": "à", "ccedilla": "ç", "udieresis": "ü", "odieresis": "ö",
+	"adieresis": "ä", "germandbls": "ß",
+}
+
+// glyphText returns the text of a glyph name, such as "A", "uni00E9" or
+// "quoteright", or "" if it is unknown
+func glyphText(name string) string {
+	if text, ok := glyphNames[name]; ok {
+		return text
+	}
+	if len(name) == 1 {
+		return name
+	}
+	for _, prefix := range []string{"uni", "u"} {
+		if hexCode, ok := strings.CutPrefix(name, prefix); ok && len(hexCode) >= 4 && len(hexCode) <= 6 {
+			if code, err := strconv.ParseUint(hexCode, 16, 32); err == nil && utf8.ValidRune(rune(code)) {
+				return string(rune(code))
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,566 @@
+package rag
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// PDF objects are parsed into nil, bool, float64, []byte (strings),
+// pdfName, []interface{} (arrays), pdfDict, pdfRef and *pdfStream values.
+
+// pdfName is a name object, without its slash
+type pdfName string
+
+// pdfKeyword is a bare token: an operator, a delimiter such as "[" or "<<",
+// or a keyword such as "obj"
+type pdfKeyword string
+
+// pdfRef is a reference to an indirect object
+type pdfRef struct {
+	num, gen int
+}
+
+// pdfDict is a dictionary object
+type pdfDict map[pdfName]interface{}
+
+// pdfStream is a stream object
+type pdfStream struct {
+	dict pdfDict
+	data []byte // Encoded data
+}
+
+// pdfMaxDepth bounds the nesting of objects and of the page tree, so
+// malformed files cannot recurse without end
+const pdfMaxDepth = 64
+
+var errPDFSyntax = errors.New("malformed PDF object")
+
+// pdfLexer reads the tokens and objects of PDF syntax
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skipSpace skips whitespace and comments
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// regular returns the run of regular characters at the current position
+func (l *pdfLexer) regular() []byte {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return l.data[start:l.pos]
+}
+
+// next returns the next token, or io.EOF at the end of the data
+func (l *pdfLexer) next() (interface{}, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+
+	switch c := l.data[l.pos]; c {
+	case '/':
+		l.pos++
+		return pdfName(decodeName(l.regular())), nil
+	case '(':
+		l.pos++
+		return l.literalString()
+	case '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return pdfKeyword("<<"), nil
+		}
+		l.pos++
+		return l.hexString()
+	case '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return pdfKeyword(">>"), nil
+		}
+		l.pos++
+		return nil, errPDFSyntax
+	case '[', ']', '{', '}', ')':
+		l.pos++
+		return pdfKeyword(c), nil
+	case '+', '-', '.', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		token := l.regular()
+		n, err := strconv.ParseFloat(string(token), 64)
+		if err != nil {
+			// Tolerate malformed numbers such as "--5" as writers do
+			return 0.0, nil
+		}
+		return n, nil
+	}
+	return pdfKeyword(l.regular()), nil
+}
+
+// decodeName decodes the #xx escapes of a name
+func decodeName(name []byte) string {
+	if bytes.IndexByte(name, '#') < 0 {
+		return string(name)
+	}
+	var out []byte
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if b, err := strconv.ParseUint(string(name[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(b))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, name[i])
+	}
+	return string(out)
+}
+
+// literalString reads a (string) after its opening parenthesis
+func (l *pdfLexer) literalString() ([]byte, error) {
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return out, nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				continue
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// A backslash at the end of a line continues the string
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				code := int(c - '0')
+				for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+					code = code*8 + int(l.data[l.pos]-'0')
+					l.pos++
+				}
+				c = byte(code)
+			}
+		}
+		out = append(out, c)
+	}
+	return nil, errPDFSyntax
+}
+
+// hexString reads a <hex string> after its opening bracket
+func (l *pdfLexer) hexString() ([]byte, error) {
+	end := bytes.IndexByte(l.data[l.pos:], '>')
+	if end < 0 {
+		return nil, errPDFSyntax
+	}
+	digits := make([]byte, 0, end)
+	for _, c := range l.data[l.pos : l.pos+end] {
+		if !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	l.pos += end + 1
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	if _, err := hex.Decode(out, digits); err != nil {
+		return nil, errPDFSyntax
+	}
+	return out, nil
+}
+
+// object reads the next object
+func (l *pdfLexer) object() (interface{}, error) {
+	token, err := l.next()
+	if err != nil {
+		return nil, err
+	}
+	return l.value(token, 0)
+}
+
+// value completes the object starting with token: arrays, dictionaries and
+// references span several tokens. Operators are returned as keywords.
+func (l *pdfLexer) value(token interface{}, depth int) (interface{}, error) {
+	if depth > pdfMaxDepth {
+		return nil, errPDFSyntax
+	}
+	switch t := token.(type) {
+	case pdfKeyword:
+		switch t {
+		case "[":
+			var array []interface{}
+			for {
+				token, err := l.next()
+				if err != nil {
+					return nil, err
+				}
+				if token == pdfKeyword("]") {
+					return array, nil
+				}
+				v, err := l.value(token, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, v)
+			}
+		case "<<":
+			dict := make(pdfDict)
+			for {
+				token, err := l.next()
+				if err != nil {
+					return nil, err
+				}
+				if token == pdfKeyword(">>") {
+					return dict, nil
+				}
+				key, ok := token.(pdfName)
+				if !ok {
+					return nil, errPDFSyntax
+				}
+				if token, err = l.next(); err != nil {
+					return nil, err
+				}
+				v, err := l.value(token, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = v
+			}
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	case float64:
+		// Two integers followed by R are a reference
+		if t >= 0 && t == math.Trunc(t) {
+			start := l.pos
+			if gen, err := l.next(); err == nil {
+				if g, ok := gen.(float64); ok && g >= 0 && g == math.Trunc(g) {
+					if r, err := l.next(); err == nil && r == pdfKeyword("R") {
+						return pdfRef{num: int(t), gen: int(g)}, nil
+					}
+				}
+			}
+			l.pos = start
+		}
+	}
+	return token, nil
+}
+
+// indirectObject reads "num gen obj ... endobj" at the current position,
+// returning the object number and the object
+func (l *pdfLexer) indirectObject() (int, interface{}, error) {
+	num, err := l.next()
+	if err != nil {
+		return 0, nil, err
+	}
+	n, ok := num.(float64)
+	if !ok {
+		return 0, nil, errPDFSyntax
+	}
+	if _, err := l.next(); err != nil {
+		return 0, nil, err
+	}
+	if keyword, err := l.next(); err != nil || keyword != pdfKeyword("obj") {
+		return 0, nil, errPDFSyntax
+	}
+	obj, err := l.object()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	dict, ok := obj.(pdfDict)
+	if !ok {
+		return int(n), obj, nil
+	}
+	start := l.pos
+	if keyword, err := l.next(); err != nil || keyword != pdfKeyword("stream") {
+		l.pos = start
+		return int(n), obj, nil
+	}
+
+	// Data starts after the end of line following "stream"
+	if l.pos < len(l.data) && l.data[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+		l.pos++
+	}
+	start = l.pos
+	if length, ok := dict["Length"].(float64); ok && length >= 0 && start+int(length) <= len(l.data) {
+		end := start + int(length)
+		after := &pdfLexer{data: l.data, pos: end}
+		if keyword, err := after.next(); err == nil && keyword == pdfKeyword("endstream") {
+			l.pos = after.pos
+			return int(n), &pdfStream{dict: dict, data: l.data[start:end]}, nil
+		}
+	}
+
+	// The length is indirect or wrong, so look for the end of the data
+	end := bytes.Index(l.data[start:], []byte("endstream"))
+	if end < 0 {
+		return 0, nil, errPDFSyntax
+	}
+	l.pos = start + end + len("endstream")
+	data := bytes.TrimSuffix(l.data[start:start+end], []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	return int(n), &pdfStream{dict: dict, data: data}, nil
+}
+
+// pdfFile holds the objects of a parsed PDF file
+type pdfFile struct {
+	objects map[int]interface{}
+	trailer pdfDict
+
+	// decoded is the decompressed size of the streams decoded so far, at
+	// most maxDecoded; decodeErr is set once a stream would exceed it
+	decoded    int64
+	maxDecoded int64
+	decodeErr  error
+}
+
+// objectHeader matches the start of an indirect object
+var objectHeader = regexp.MustCompile(`(\d+)[ \t\r\n\f\x00]+\d+[ \t\r\n\f\x00]+obj\b`)
+
+// parsePDF reads the objects of a PDF file.
+//
+// Objects are found by scanning the file rather than through its
+// cross-reference table, which tolerates the broken tables common in the
+// wild; later definitions replace earlier ones, as incremental updates do.
+func parsePDF(data []byte, maxDecoded int64) (*pdfFile, error) {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if !bytes.Contains(head, []byte("%PDF-")) {
+		return nil, errors.New("not a PDF file")
+	}
+
+	file := &pdfFile{objects: make(map[int]interface{}), maxDecoded: maxDecoded}
+	var objectStreams []*pdfStream
+	end := 0
+	for _, match := range objectHeader.FindAllIndex(data, -1) {
+		// Skip matches in the data of streams and in longer numbers
+		if match[0] < end || (match[0] > 0 && !isPDFSpace(data[match[0]-1]) && !isPDFDelimiter(data[match[0]-1])) {
+			continue
+		}
+		l := &pdfLexer{data: data, pos: match[0]}
+		num, obj, err := l.indirectObject()
+		if err != nil {
+			continue
+		}
+		end = l.pos
+		file.objects[num] = obj
+
+		if stream, ok := obj.(*pdfStream); ok {
+			switch stream.dict["Type"] {
+			case pdfName("ObjStm"):
+				objectStreams = append(objectStreams, stream)
+			case pdfName("XRef"):
+				// Cross-reference streams double as the trailer
+				file.trailer = stream.dict
+			}
+		}
+	}
+	if i := bytes.LastIndex(data, []byte("trailer")); i >= 0 {
+		l := &pdfLexer{data: data, pos: i + len("trailer")}
+		if trailer, err := l.object(); err == nil {
+			if dict, ok := trailer.(pdfDict); ok && dict["Root"] != nil {
+				file.trailer = dict
+			}
+		}
+	}
+	if file.trailer["Encrypt"] != nil {
+		return nil, errors.New("encrypted PDF files are not supported")
+	}
+
+	for _, stream := range objectStreams {
+		file.readObjectStream(stream)
+	}
+	return file, nil
+}
+
+// readObjectStream adds the objects compressed in an object stream, unless
+// they are defined directly
+func (f *pdfFile) readObjectStream(stream *pdfStream) {
+	data, err := f.decode(stream)
+	if err != nil {
+		return
+	}
+	count, _ := f.resolve(stream.dict["N"]).(float64)
+	first, _ := f.resolve(stream.dict["First"]).(float64)
+	header := &pdfLexer{data: data}
+	for i := 0; i < int(count); i++ {
+		num, err1 := header.next()
+		offset, err2 := header.next()
+		n, ok1 := num.(float64)
+		o, ok2 := offset.(float64)
+		if err1 != nil || err2 != nil || !ok1 || !ok2 {
+			return
+		}
+		if _, ok := f.objects[int(n)]; ok || int(first+o) >= len(data) {
+			continue
+		}
+		l := &pdfLexer{data: data, pos: int(first + o)}
+		if obj, err := l.object(); err == nil {
+			f.objects[int(n)] = obj
+		}
+	}
+}
+
+// resolve follows references to the object they point at
+func (f *pdfFile) resolve(v interface{}) interface{} {
+	for i := 0; i < pdfMaxDepth; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+// dict resolves v to a dictionary, or the dictionary of a stream
+func (f *pdfFile) dict(v interface{}) pdfDict {
+	switch t := f.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case *pdfStream:
+		return t.dict
+	}
+	return nil
+}
+
+// stream resolves v to a stream
+func (f *pdfFile) stream(v interface{}) *pdfStream {
+	stream, _ := f.resolve(v).(*pdfStream)
+	return stream
+}
+
+// objectNumbers returns the numbers of all objects in order
+func (f *pdfFile) objectNumbers() []int {
+	nums := make([]int, 0, len(f.objects))
+	for num := range f.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// decode returns the decoded data of a stream
+func (f *pdfFile) decode(stream *pdfStream) ([]byte, error) {
+	var filters, params []interface{}
+	switch filter := f.resolve(stream.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{filter}
+		params = []interface{}{stream.dict["DecodeParms"]}
+	case []interface{}:
+		filters = filter
+		params, _ = f.resolve(stream.dict["DecodeParms"]).([]interface{})
+	}
+
+	data := stream.data
+	for i, filter := range filters {
+		if i < len(params) {
+			if predictor, _ := f.resolve(f.dict(params[i])["Predictor"]).(float64); predictor > 1 {
+				return nil, fmt.Errorf("unsupported PDF predictor %v", predictor)
+			}
+		}
+
+		var r io.Reader
+		switch f.resolve(filter) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			r = zr
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+			if end := bytes.Index(data, []byte("~>")); end >= 0 {
+				data = data[:end]
+			}
+			r = ascii85.NewDecoder(bytes.NewReader(data))
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			l := &pdfLexer{data: append(append([]byte{}, data...), '>')}
+			decoded, err := l.hexString()
+			if err != nil {
+				return nil, err
+			}
+			data = decoded
+			continue
+		default:
+			return nil, fmt.Errorf("unsupported PDF filter %v", filter)
+		}
+
+		remaining := f.maxDecoded - f.decoded
+		decoded, err := io.ReadAll(io.LimitReader(r, remaining+1))
+		if int64(len(decoded)) > remaining {
+			f.decodeErr = decodedSizeError(f.maxDecoded)
+			return nil, f.decodeErr
+		}
+		// Keep what was decoded of truncated streams
+		if err != nil && len(decoded) == 0 {
+			return nil, err
+		}
+		f.decoded += int64(len(decoded))
+		data = decoded
+	}
+	return data, nil
+}
@@ -0,0 +1,234 @@
+// Package rag provides embedding-based retrieval for retrieval-augmented generation.
+//
+// An Index splits documents into token-bounded chunks, embeds them with a
+// pluggable Embedder and answers similarity queries from memory. It is meant
+// for document sets that fit in memory, such as the handful of files passed
+// to a question-answering request; larger corpora belong in a vector database.
+//
+// Loaders extract the text of PDF, HTML, Markdown and DOCX files, so
+// AddFiles indexes files in one call.
+//
+// Example:
+//
+//	index := rag.NewIndex(embedder, rag.IndexOptions{ChunkTokens: 300})
+//	err := index.Add(ctx,
+//		rag.Document{ID: "handbook", Text: handbook},
+//		rag.Document{ID: "faq", Text: faq},
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	results, err := index.Search(ctx, "How many vacation days do I get?", 3)
+//	for _, r := range results {
+//		fmt.Printf("%s (%.2f): %s\n", r.Chunk.Ref(), r.Score, r.Chunk.Text)
+//	}
+package rag
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ajeet-kumar1087/ai-providers/prompt"
+	"github.com/ajeet-kumar1087/ai-providers/textsplit"
+)
+
+const (
+	// DefaultChunkTokens is the default maximum size of an indexed chunk
+	DefaultChunkTokens = 400
+
+	// DefaultBatchSize is the default number of chunks embedded per Embed call
+	DefaultBatchSize = 64
+)
+
+// Embedder converts texts into embedding vectors.
+//
+// It has the same method set as prompt.Embedder, so one implementation can
+// serve both packages.
+type Embedder interface {
+	// Embed returns one vector per input text, in input order
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// Document is a source text to index.
+type Document struct {
+	// ID identifies the document in citations (default: "doc-N" in insertion order)
+	ID string `json:"id"`
+
+	// Text is the document content
+	Text string `json:"text"`
+
+	// Metadata holds arbitrary attributes such as a title or URL (optional)
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Chunk is an indexed piece of a document.
+type Chunk struct {
+	// ID is a hash of Text (see textsplit.ChunkID), stable across runs, for
+	// caching embeddings
+	ID string `json:"id"`
+
+	// DocumentID is the ID of the document the chunk belongs to
+	DocumentID string `json:"document_id"`
+
+	// Index is the position of the chunk within its document
+	Index int `json:"index"`
+
+	// Text is the chunk content
+	Text string `json:"text"`
+
+	// Offset is the byte offset of Text in the document text, so citations
+	// can point into the source
+	Offset int `json:"offset"`
+
+	// Metadata is the metadata of the document the chunk belongs to
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Ref returns a stable reference to the chunk, e.g. "handbook#2"
+func (c Chunk) Ref() string {
+	return fmt.Sprintf("%s#%d", c.DocumentID, c.Index)
+}
+
+// Result is a chunk matched by a search.
+type Result struct {
+	// Chunk is the matched chunk
+	Chunk Chunk `json:"chunk"`
+
+	// Score is the cosine similarity between the query and the chunk
+	Score float64 `json:"score"`
+}
+
+// IndexOptions configures an Index.
+type IndexOptions struct {
+	// ChunkTokens is the maximum estimated size of each chunk (default: 400)
+	ChunkTokens int
+
+	// Splitter splits documents into chunks (default: textsplit.Recursive
+	// with ChunkTokens); use textsplit.Markdown for Markdown documents or
+	// textsplit.Fixed for overlapping chunks
+	Splitter textsplit.Splitter
+
+	// BatchSize is the number of chunks embedded per Embed call (default: 64)
+	BatchSize int
+
+	// Loaders maps lowercase file extensions, such as ".pdf", to the loaders
+	// AddFiles reads them with (default: DefaultLoaders)
+	Loaders map[string]Loader
+}
+
+// Index is an in-memory vector index of document chunks.
+//
+// Index is safe for concurrent use.
+type Index struct {
+	embedder  Embedder
+	options   IndexOptions
+	mu        sync.RWMutex
+	documents int
+	chunks    []Chunk
+	vectors   [][]float64
+}
+
+// NewIndex creates an empty index that embeds text with embedder
+func NewIndex(embedder Embedder, opts IndexOptions) *Index {
+	if opts.ChunkTokens <= 0 {
+		opts.ChunkTokens = DefaultChunkTokens
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.Splitter == nil {
+		opts.Splitter = textsplit.Recursive{MaxTokens: opts.ChunkTokens}
+	}
+	return &Index{embedder: embedder, options: opts}
+}
+
+// Add chunks, embeds and indexes documents. On error no chunks are added.
+func (ix *Index) Add(ctx context.Context, docs ...Document) error {
+	// Reserve default document numbers so concurrent calls don't share them
+	ix.mu.Lock()
+	next := ix.documents
+	ix.documents += len(docs)
+	ix.mu.Unlock()
+
+	var chunks []Chunk
+	for _, doc := range docs {
+		next++
+		id := doc.ID
+		if id == "" {
+			id = fmt.Sprintf("doc-%d", next)
+		}
+		for _, chunk := range ix.options.Splitter.Split(doc.Text) {
+			text := strings.TrimSpace(chunk.Text)
+			if text == "" {
+				continue
+			}
+			offset := chunk.Offset + strings.Index(chunk.Text, text)
+			chunks = append(chunks, Chunk{ID: textsplit.ChunkID(text), DocumentID: id, Index: chunk.Index, Text: text, Offset: offset, Metadata: doc.Metadata})
+		}
+	}
+
+	vectors := make([][]float64, 0, len(chunks))
+	for start := 0; start < len(chunks); start += ix.options.BatchSize {
+		end := start + ix.options.BatchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+
+		texts := make([]string, end-start)
+		for i, chunk := range chunks[start:end] {
+			texts[i] = chunk.Text
+		}
+		batch, err := ix.embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed chunks: %w", err)
+		}
+		if len(batch) != len(texts) {
+			return fmt.Errorf("embedder returned %d vectors for %d chunks", len(batch), len(texts))
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.chunks = append(ix.chunks, chunks...)
+	ix.vectors = append(ix.vectors, vectors...)
+	return nil
+}
+
+// Search returns the k chunks most similar to query, best match first
+func (ix *Index) Search(ctx context.Context, query string, k int) ([]Result, error) {
+	vectors, err := ix.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for 1 query", len(vectors))
+	}
+
+	ix.mu.RLock()
+	results := make([]Result, len(ix.chunks))
+	for i, chunk := range ix.chunks {
+		results[i] = Result{Chunk: chunk, Score: prompt.CosineSimilarity(vectors[0], ix.vectors[i])}
+	}
+	ix.mu.RUnlock()
+
+	// Stable sort keeps insertion order among equal scores
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if k > 0 && k < len(results) {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// Len returns the number of indexed chunks
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.chunks)
+}
@@ -0,0 +1,125 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/textsplit"
+)
+
+// keywordEmbedder embeds text as counts of fixed keywords
+type keywordEmbedder struct {
+	keywords []string
+	calls    int
+	err      error
+}
+
+func (e *keywordEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, len(e.keywords))
+		for j, keyword := range e.keywords {
+			vectors[i][j] = float64(strings.Count(strings.ToLower(text), keyword))
+		}
+	}
+	return vectors, nil
+}
+
+func TestIndex_Search(t *testing.T) {
+	embedder := &keywordEmbedder{keywords: []string{"vacation", "salary", "office"}}
+	index := NewIndex(embedder, IndexOptions{})
+
+	err := index.Add(context.Background(),
+		Document{ID: "handbook", Text: "Employees get 25 vacation days.", Metadata: map[string]string{"title": "Handbook"}},
+		Document{Text: "Salary is paid monthly."},
+		Document{Text: "The office opens at 8."},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if index.Len() != 3 {
+		t.Errorf("Expected 3 chunks, got %d", index.Len())
+	}
+
+	results, err := index.Search(context.Background(), "How many vacation days?", 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Chunk.Ref() != "handbook#0" || results[0].Score != 1 {
+		t.Errorf("Expected handbook#0 with score 1 first, got %s (%v)", results[0].Chunk.Ref(), results[0].Score)
+	}
+	if results[0].Chunk.Metadata["title"] != "Handbook" {
+		t.Errorf("Expected document metadata on chunk")
+	}
+	if results[1].Chunk.DocumentID != "doc-2" {
+		t.Errorf("Expected default document ID doc-2, got %q", results[1].Chunk.DocumentID)
+	}
+}
+
+func TestIndex_Chunking(t *testing.T) {
+	embedder := &keywordEmbedder{keywords: []string{"a"}}
+	index := NewIndex(embedder, IndexOptions{ChunkTokens: 20, BatchSize: 2})
+
+	text := strings.Repeat("alpha beta gamma. ", 20)
+	if err := index.Add(context.Background(), Document{ID: "long", Text: text}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	chunks := index.Len()
+	if chunks < 2 {
+		t.Fatalf("Expected multiple chunks, got %d", chunks)
+	}
+	if want := (chunks + 1) / 2; embedder.calls != want {
+		t.Errorf("Expected %d embedding batches, got %d", want, embedder.calls)
+	}
+}
+
+func TestIndex_Splitter(t *testing.T) {
+	embedder := &keywordEmbedder{keywords: []string{"install", "usage"}}
+	index := NewIndex(embedder, IndexOptions{Splitter: textsplit.Markdown{MaxTokens: 10}})
+
+	text := "# Install\n\nRun the install script.\n\n# Usage\n\nSee the usage guide."
+	if err := index.Add(context.Background(), Document{ID: "readme", Text: text}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if index.Len() != 2 {
+		t.Fatalf("Expected a chunk per section, got %d", index.Len())
+	}
+
+	results, err := index.Search(context.Background(), "usage", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	chunk := results[0].Chunk
+	if chunk.Ref() != "readme#1" || !strings.HasPrefix(chunk.Text, "# Usage") {
+		t.Errorf("Expected the usage section, got %s: %q", chunk.Ref(), chunk.Text)
+	}
+	if chunk.ID != textsplit.ChunkID(chunk.Text) {
+		t.Errorf("Expected the chunk ID to hash its text, got %q", chunk.ID)
+	}
+	if end := chunk.Offset + len(chunk.Text); end > len(text) || text[chunk.Offset:end] != chunk.Text {
+		t.Errorf("Expected the chunk offset to locate its text, got %d", chunk.Offset)
+	}
+}
+
+func TestIndex_AddError(t *testing.T) {
+	embedder := &keywordEmbedder{err: errors.New("quota exceeded")}
+	index := NewIndex(embedder, IndexOptions{})
+
+	err := index.Add(context.Background(), Document{Text: "text"})
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("Expected embedder error, got %v", err)
+	}
+	if index.Len() != 0 {
+		t.Errorf("Expected no chunks after failed add, got %d", index.Len())
+	}
+}
@@ -0,0 +1,350 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRedisDialTimeout is how long DialRedis waits for a connection
+	// when RedisOptions.DialTimeout is zero
+	DefaultRedisDialTimeout = 5 * time.Second
+
+	// DefaultRedisReadTimeout is how long a command waits for its reply when
+	// RedisOptions.ReadTimeout is zero
+	DefaultRedisReadTimeout = 3 * time.Second
+
+	// DefaultRedisPoolSize is the number of connections commands are sent
+	// over concurrently when RedisOptions.PoolSize is zero
+	DefaultRedisPoolSize = 4
+)
+
+// RedisOptions configures DialRedis.
+type RedisOptions struct {
+	// Username authenticates with a Redis 6 ACL user (optional)
+	Username string
+
+	// Password authenticates the connection (optional)
+	Password string
+
+	// DB selects the database (optional)
+	DB int
+
+	// DialTimeout limits connecting (default: DefaultRedisDialTimeout)
+	DialTimeout time.Duration
+
+	// ReadTimeout limits sending a command and reading its reply, unless the
+	// context's deadline is earlier, so a stalled server cannot block
+	// callers without a deadline (default: DefaultRedisReadTimeout)
+	ReadTimeout time.Duration
+
+	// PoolSize is the number of connections commands are sent over
+	// concurrently; further commands wait for a free one (default:
+	// DefaultRedisPoolSize)
+	PoolSize int
+
+	// TLSConfig enables TLS when set (optional)
+	TLSConfig *tls.Config
+}
+
+// RedisError is an error reply from the Redis server.
+type RedisError string
+
+// Error implements the error interface
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// errRedisClosed is returned by commands sent after Close
+var errRedisClosed = errors.New("redis: connection closed")
+
+// RedisConn is a minimal Redis client, enough for RedisStore without
+// depending on a Redis client library.
+//
+// Commands are sent over a small pool of connections, one command at a time
+// on each; connections are dialled as needed and discarded after a network
+// error. RedisConn is safe for concurrent use.
+type RedisConn struct {
+	addr  string
+	opts  RedisOptions
+	slots chan struct{} // Holds a token per command in flight, up to PoolSize
+
+	mu     sync.Mutex
+	idle   []*redisConn
+	closed bool
+}
+
+// redisConn is a pooled connection
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// DialRedis connects to a Redis server.
+//
+// Parameters:
+//   - ctx: Context for the connection attempt
+//   - addr: The server address, e.g. "localhost:6379"
+//   - opts: Authentication, database, timeout, pool and TLS settings
+//
+// Returns:
+//   - *RedisConn: The open connection
+//   - error: An error if connecting or authenticating fails
+func DialRedis(ctx context.Context, addr string, opts RedisOptions) (*RedisConn, error) {
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultRedisDialTimeout
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = DefaultRedisReadTimeout
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = DefaultRedisPoolSize
+	}
+	c := &RedisConn{addr: addr, opts: opts, slots: make(chan struct{}, opts.PoolSize)}
+
+	// Dial the first connection now, so a wrong address or password fails
+	// here rather than on the first command
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.idle = append(c.idle, conn)
+	return c, nil
+}
+
+// Eval runs a script with EVAL, implementing RedisScripter
+func (c *RedisConn) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	command := make([]interface{}, 0, 3+len(keys)+len(args))
+	command = append(command, "EVAL", script, len(keys))
+	for _, key := range keys {
+		command = append(command, key)
+	}
+	return c.Do(ctx, append(command, args...)...)
+}
+
+// Do sends a command and returns its reply: a string, an int64, nil, or a
+// []interface{} of those. Error replies are returned as RedisError.
+func (c *RedisConn) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-c.slots }()
+
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.roundTrip(ctx, conn, args)
+	var redisErr RedisError
+	if err != nil && !errors.As(err, &redisErr) {
+		// The connection may hold half a reply, so it cannot be reused
+		conn.conn.Close()
+		return reply, err
+	}
+	c.put(conn)
+	return reply, err
+}
+
+// Close closes the connections; commands sent afterwards fail
+func (c *RedisConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	var first error
+	for _, conn := range c.idle {
+		if err := conn.conn.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	c.idle = nil
+	return first
+}
+
+// get returns an idle connection, or dials a new one
+func (c *RedisConn) get(ctx context.Context) (*redisConn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errRedisClosed
+	}
+	if n := len(c.idle); n > 0 {
+		conn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return conn, nil
+	}
+	c.mu.Unlock()
+	return c.dial(ctx)
+}
+
+// put returns a connection to the pool, closing it if the pool is closed
+func (c *RedisConn) put(conn *redisConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		conn.conn.Close()
+		return
+	}
+	c.idle = append(c.idle, conn)
+}
+
+// dial opens a connection and authenticates it
+func (c *RedisConn) dial(ctx context.Context) (*redisConn, error) {
+	dialer := &net.Dialer{Timeout: c.opts.DialTimeout}
+
+	var netConn net.Conn
+	var err error
+	if c.opts.TLSConfig != nil {
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: c.opts.TLSConfig}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var setup [][]interface{}
+	if c.opts.Password != "" {
+		if c.opts.Username != "" {
+			setup = append(setup, []interface{}{"AUTH", c.opts.Username, c.opts.Password})
+		} else {
+			setup = append(setup, []interface{}{"AUTH", c.opts.Password})
+		}
+	}
+	if c.opts.DB != 0 {
+		setup = append(setup, []interface{}{"SELECT", c.opts.DB})
+	}
+	for _, command := range setup {
+		if _, err := c.roundTrip(ctx, conn, command); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("failed to set up redis connection: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// roundTrip writes a command and reads its reply within ReadTimeout. The
+// connection is closed if ctx is done first, which unblocks the read.
+func (c *RedisConn) roundTrip(ctx context.Context, conn *redisConn, args []interface{}) (interface{}, error) {
+	deadline := time.Now().Add(c.opts.ReadTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.conn.Close() })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		value := formatArg(arg)
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(value), value)
+	}
+	var reply interface{}
+	_, err := io.WriteString(conn.conn, b.String())
+	if err != nil {
+		err = fmt.Errorf("failed to send redis command: %w", err)
+	} else {
+		reply, err = readReply(conn.reader)
+	}
+	if !stop() {
+		// The connection was closed for ctx, possibly after the reply
+		return nil, ctx.Err()
+	}
+	return reply, err
+}
+
+// formatArg converts a command argument to its bulk string form
+func formatArg(arg interface{}) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// readReply reads one RESP reply
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis reply: %w", err)
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("malformed redis reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, RedisError(payload)
+	case ':':
+		n, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed redis integer %q", payload)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("malformed redis bulk length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("failed to read redis reply: %w", err)
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("malformed redis array length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := readReply(r)
+			var redisErr RedisError
+			if errors.As(err, &redisErr) {
+				// An error element does not fail the whole reply
+				items[i] = redisErr
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unsupported redis reply type %q", kind)
+	}
+}
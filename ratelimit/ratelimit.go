@@ -0,0 +1,108 @@
+// Package ratelimit provides token bucket stores for Config.RequestsPerMinute.
+//
+// MemoryStore keeps the buckets in process and is what clients use by
+// default. A service running several replicas overshoots the provider's
+// quota that way, as each replica allows the full rate, so RedisStore keeps
+// the buckets in Redis instead, where every replica draws from the same one.
+//
+// Example:
+//
+//	conn, err := ratelimit.DialRedis(ctx, "redis:6379", ratelimit.RedisOptions{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer conn.Close()
+//
+//	config := aiprovider.DefaultConfig().WithAPIKey(key)
+//	config.RequestsPerMinute = 500
+//	config.RateLimiterStore = ratelimit.NewRedisStore(conn)
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// Store holds token buckets. It is an alias of types.RateLimiterStore.
+type Store = types.RateLimiterStore
+
+// MemoryStore keeps token buckets in process memory.
+//
+// MemoryStore is safe for concurrent use.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// bucket is the state of one token bucket. Tokens go negative while
+// reservations wait for their turn.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Reserve takes a token from the bucket with the given key, implementing
+// types.RateLimiterStore. A new bucket starts full.
+func (s *MemoryStore) Reserve(ctx context.Context, key string, rate float64, burst int) (time.Duration, error) {
+	if err := validate(rate, burst); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed.Seconds()*rate)
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0, nil
+	}
+	return time.Duration(math.Ceil(-b.tokens / rate * float64(time.Second))), nil
+}
+
+// Release returns a token taken with Reserve to the bucket with the given
+// key, implementing types.RateLimitReleaser. The bucket never holds more
+// than burst tokens.
+func (s *MemoryStore) Release(ctx context.Context, key string, rate float64, burst int) error {
+	if err := validate(rate, burst); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.buckets[key]; ok {
+		b.tokens = math.Min(float64(burst), b.tokens+1)
+	}
+	return nil
+}
+
+// validate checks the bucket parameters of a reservation
+func validate(rate float64, burst int) error {
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return fmt.Errorf("rate must be positive, got: %g", rate)
+	}
+	if burst < 1 {
+		return fmt.Errorf("burst must be at least 1, got: %d", burst)
+	}
+	return nil
+}
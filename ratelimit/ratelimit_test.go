@@ -0,0 +1,259 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// A new bucket allows a burst, then spaces reservations at the rate
+	for i := 0; i < 3; i++ {
+		if wait, err := s.Reserve(ctx, "openai:key", 2, 3); err != nil || wait != 0 {
+			t.Fatalf("Expected reservation %d within the burst, got %v, %v", i, wait, err)
+		}
+	}
+	for i, want := range []time.Duration{500 * time.Millisecond, time.Second} {
+		if wait, _ := s.Reserve(ctx, "openai:key", 2, 3); wait != want {
+			t.Errorf("Expected reservation %d to wait %v, got %v", i, want, wait)
+		}
+	}
+
+	// Buckets refill over time, up to the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if wait, _ := s.Reserve(ctx, "openai:key", 2, 3); wait != 0 {
+			t.Errorf("Expected a refilled bucket, got a wait of %v", wait)
+		}
+	}
+	if wait, _ := s.Reserve(ctx, "openai:key", 2, 3); wait == 0 {
+		t.Error("Expected the refill to stop at the burst")
+	}
+
+	// Keys have separate buckets
+	if wait, _ := s.Reserve(ctx, "anthropic:key", 2, 3); wait != 0 {
+		t.Errorf("Expected a separate bucket per key, got a wait of %v", wait)
+	}
+
+	// Released tokens shorten the wait of later reservations
+	s.Reserve(ctx, "release", 1, 1)
+	if wait, _ := s.Reserve(ctx, "release", 1, 1); wait != time.Second {
+		t.Fatalf("Expected a wait of 1s, got %v", wait)
+	}
+	if err := s.Release(ctx, "release", 1, 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if wait, _ := s.Reserve(ctx, "release", 1, 1); wait != time.Second {
+		t.Errorf("Expected the released token to be reused, got a wait of %v", wait)
+	}
+	s.Release(ctx, "release", 1, 1)
+	s.Release(ctx, "release", 1, 1)
+	s.Release(ctx, "release", 1, 1)
+	if wait, _ := s.Reserve(ctx, "release", 1, 1); wait != 0 {
+		t.Errorf("Expected a full bucket, got a wait of %v", wait)
+	}
+	if wait, _ := s.Reserve(ctx, "release", 1, 1); wait == 0 {
+		t.Error("Expected releases not to exceed the burst")
+	}
+
+	if _, err := s.Reserve(ctx, "k", 0, 1); err == nil {
+		t.Error("Expected an error for a zero rate")
+	}
+	if _, err := s.Reserve(ctx, "k", 1, 0); err == nil {
+		t.Error("Expected an error for a zero burst")
+	}
+}
+
+// fakeRedis is a RESP server answering commands with reply
+type fakeRedis struct {
+	listener net.Listener
+	commands chan []string
+	reply    func(command []string) string
+}
+
+func newFakeRedis(t *testing.T, reply func(command []string) string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on loopback: %v", err)
+	}
+	f := &fakeRedis{listener: listener, commands: make(chan []string, 16), reply: reply}
+	t.Cleanup(func() { listener.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				command, err := readCommand(r)
+				if err != nil {
+					return
+				}
+				f.commands <- command
+				reply := f.reply(command)
+				if reply == "" {
+					return // Drop the connection
+				}
+				io.WriteString(conn, reply)
+			}
+		}(conn)
+	}
+}
+
+// readCommand reads a RESP array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	command := make([]string, n)
+	for i := range command {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		length, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		value := make([]byte, length+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+		command[i] = string(value[:length])
+	}
+	return command, nil
+}
+
+func TestRedisStore(t *testing.T) {
+	f := newFakeRedis(t, func(command []string) string {
+		switch command[0] {
+		case "AUTH":
+			return "+OK\r\n"
+		case "EVAL":
+			return ":250\r\n"
+		}
+		return "-ERR unknown command\r\n"
+	})
+	ctx := context.Background()
+	conn, err := DialRedis(ctx, f.listener.Addr().String(), RedisOptions{Password: "secret"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer conn.Close()
+	if auth := <-f.commands; strings.Join(auth, " ") != "AUTH secret" {
+		t.Errorf("Expected the connection to authenticate, got %v", auth)
+	}
+
+	store := NewRedisStore(conn)
+	wait, err := store.Reserve(ctx, "openai:abc", 8.5, 10)
+	if err != nil || wait != 250*time.Millisecond {
+		t.Fatalf("Expected the script's wait, got %v, %v", wait, err)
+	}
+	eval := <-f.commands
+	if len(eval) != 6 || eval[1] != reserveScript || eval[2] != "1" || eval[3] != DefaultRedisPrefix+"openai:abc" || eval[4] != "8.5" || eval[5] != "10" {
+		t.Errorf("Expected EVAL of the reserve script, got %q", eval[2:])
+	}
+
+	if err := store.Release(ctx, "openai:abc", 8.5, 10); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	eval = <-f.commands
+	if len(eval) != 5 || eval[1] != releaseScript || eval[3] != DefaultRedisPrefix+"openai:abc" || eval[4] != "10" {
+		t.Errorf("Expected EVAL of the release script, got %q", eval[2:])
+	}
+
+	if _, err := conn.Do(ctx, "FLUSHALL"); !errors.As(err, new(RedisError)) {
+		t.Errorf("Expected a RedisError for an error reply, got %v", err)
+	}
+}
+
+func TestRedisConn_Reconnect(t *testing.T) {
+	var calls int32
+	f := newFakeRedis(t, func(command []string) string {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return ""
+		}
+		return fmt.Sprintf("*2\r\n$%d\r\n%s\r\n:1\r\n", len(command[1]), command[1])
+	})
+	ctx := context.Background()
+	conn, err := DialRedis(ctx, f.listener.Addr().String(), RedisOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Do(ctx, "ECHO", "a"); err == nil {
+		t.Fatal("Expected an error when the server drops the connection")
+	}
+	reply, err := conn.Do(ctx, "ECHO", "b")
+	if err != nil {
+		t.Fatalf("Expected the connection to be dialled again, got %v", err)
+	}
+	if items, ok := reply.([]interface{}); !ok || len(items) != 2 || items[0] != "b" || items[1] != int64(1) {
+		t.Errorf("Expected an array reply, got %#v", reply)
+	}
+}
+
+func TestRedisConn_Timeouts(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	f := newFakeRedis(t, func(command []string) string {
+		if command[0] == "STALL" {
+			<-release
+		}
+		return "+OK\r\n"
+	})
+	conn, err := DialRedis(context.Background(), f.listener.Addr().String(), RedisOptions{ReadTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	// A stalled server fails commands without a deadline after ReadTimeout
+	start := time.Now()
+	if _, err := conn.Do(context.Background(), "STALL"); err == nil || time.Since(start) > time.Second {
+		t.Errorf("Expected the read timeout to fail the command, got %v after %v", err, time.Since(start))
+	}
+
+	// Cancelling the context unblocks the command, while others use the
+	// remaining connections of the pool
+	conn.opts.ReadTimeout = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Do(ctx, "STALL")
+		done <- err
+	}()
+	if reply, err := conn.Do(context.Background(), "PING"); err != nil || reply != "OK" {
+		t.Errorf("Expected a command to succeed while another stalls, got %v, %v", reply, err)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the cancellation error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected cancelling the context to unblock the command")
+	}
+}
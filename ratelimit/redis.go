@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DefaultRedisPrefix is prepended to the bucket keys RedisStore writes
+const DefaultRedisPrefix = "aiprovider:ratelimit:"
+
+// reserveScript takes a token from a bucket atomically. Redis's clock is
+// used so replicas with skewed clocks agree on the refill. The bucket
+// expires once idle long enough to have refilled completely.
+//
+// KEYS[1] is the bucket, ARGV[1] the rate in tokens per second and ARGV[2]
+// the burst. It returns the wait in milliseconds.
+const reserveScript = `
+redis.replicate_commands()
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+	ts = now
+end
+tokens = tokens - 1
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', ts)
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * 1000 / rate) + 1000)
+if tokens >= 0 then
+	return 0
+end
+return math.ceil(-tokens * 1000 / rate)
+`
+
+// releaseScript returns a token to a bucket, if it still exists, without
+// exceeding the burst.
+//
+// KEYS[1] is the bucket and ARGV[1] the burst.
+const releaseScript = `
+local tokens = tonumber(redis.call('HGET', KEYS[1], 'tokens'))
+if tokens == nil then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(math.min(tonumber(ARGV[1]), tokens + 1)))
+return 0
+`
+
+// RedisScripter runs Lua scripts on a Redis server. RedisConn implements it;
+// so does a thin wrapper around a go-redis client:
+//
+//	type goRedis struct{ *redis.Client }
+//
+//	func (r goRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+//		return r.Client.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisScripter interface {
+	// Eval runs a script with EVAL and returns its reply
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RedisStore keeps token buckets in Redis, so every client using the same
+// server and prefix shares them.
+//
+// Reservations are atomic Lua scripts, so concurrent replicas never take the
+// same token. RedisStore is safe for concurrent use if its RedisScripter is.
+type RedisStore struct {
+	// Prefix is prepended to bucket keys (default: DefaultRedisPrefix)
+	Prefix string
+
+	redis RedisScripter
+}
+
+// NewRedisStore creates a store keeping its buckets in Redis
+func NewRedisStore(redis RedisScripter) *RedisStore {
+	return &RedisStore{Prefix: DefaultRedisPrefix, redis: redis}
+}
+
+// Reserve takes a token from the bucket with the given key, implementing
+// types.RateLimiterStore. A new bucket starts full.
+func (s *RedisStore) Reserve(ctx context.Context, key string, rate float64, burst int) (time.Duration, error) {
+	if err := validate(rate, burst); err != nil {
+		return 0, err
+	}
+
+	reply, err := s.redis.Eval(ctx, reserveScript, []string{s.Prefix + key},
+		strconv.FormatFloat(rate, 'g', -1, 64), strconv.Itoa(burst))
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve rate limit token: %w", err)
+	}
+	wait, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected reply to rate limit script: %v", reply)
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// Release returns a token taken with Reserve to the bucket with the given
+// key, implementing types.RateLimitReleaser. The bucket never holds more
+// than burst tokens.
+func (s *RedisStore) Release(ctx context.Context, key string, rate float64, burst int) error {
+	if err := validate(rate, burst); err != nil {
+		return err
+	}
+
+	if _, err := s.redis.Eval(ctx, releaseScript, []string{s.Prefix + key}, strconv.Itoa(burst)); err != nil {
+		return fmt.Errorf("failed to release rate limit token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package aiprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+)
+
+// waitRateLimit reserves a token from the bucket of the provider and API key
+// of a request under Config.RequestsPerMinute and waits for its turn. Store
+// failures are reported to OnRateLimiterError and never fail the request.
+// A request cancelled while waiting returns its token if the store is a
+// RateLimitReleaser, so it does not delay the requests queued behind it.
+func (c *client) waitRateLimit(ctx context.Context) error {
+	if c.config.RequestsPerMinute <= 0 || c.config.RateLimiterStore == nil {
+		return nil
+	}
+
+	rate := c.config.RequestsPerMinute / 60
+	burst := c.config.RateLimitBurst
+	if burst == 0 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	key := c.rateLimitKey(ctx)
+	wait, err := c.config.RateLimiterStore.Reserve(ctx, key, rate, burst)
+	if err != nil {
+		if c.config.OnRateLimiterError != nil {
+			c.config.OnRateLimiterError(err)
+		}
+		return nil
+	}
+	if wait <= 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, wait); err != nil {
+		if releaser, ok := c.config.RateLimiterStore.(RateLimitReleaser); ok {
+			if releaseErr := releaser.Release(context.WithoutCancel(ctx), key, rate, burst); releaseErr != nil && c.config.OnRateLimiterError != nil {
+				c.config.OnRateLimiterError(releaseErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// rateLimitKey identifies the bucket of a request: the provider and a hash
+// of the API key it is sent with, so stores never see the key itself
+func (c *client) rateLimitKey(ctx context.Context) string {
+	key := c.config.APIKey
+	if projectKey, ok := c.config.ProjectKeys[ProjectFromContext(ctx)]; ok {
+		key = projectKey
+	}
+	sum := sha256.Sum256([]byte(key))
+	return string(c.provider) + ":" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,76 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRateLimiterStore records reservations and answers them with wait or err
+type fakeRateLimiterStore struct {
+	keys  []string
+	rate  float64
+	burst int
+	wait  time.Duration
+	err   error
+}
+
+func (f *fakeRateLimiterStore) Reserve(ctx context.Context, key string, rate float64, burst int) (time.Duration, error) {
+	f.keys = append(f.keys, key)
+	f.rate, f.burst = rate, burst
+	return f.wait, f.err
+}
+
+func TestRateLimit(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Hi"}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	store := &fakeRateLimiterStore{wait: 20 * time.Millisecond}
+	c.config.APIKey = "sk-secret"
+	c.config.RequestsPerMinute = 600
+	c.config.RateLimiterStore = store
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+	start := time.Now()
+	if _, err := c.ChatComplete(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected the request to wait for its turn, took %v", elapsed)
+	}
+	if store.rate != 10 || store.burst != 10 {
+		t.Errorf("Expected 10 requests per second with a one second burst, got %g and %d", store.rate, store.burst)
+	}
+	if key := store.keys[0]; !strings.HasPrefix(key, "openai:") || strings.Contains(key, "secret") {
+		t.Errorf("Expected a provider key without the API key, got %q", key)
+	}
+
+	// Requests of other API keys use other buckets
+	c.config.ProjectKeys = map[string]string{"proj_search": "sk-search"}
+	if _, err := c.ChatComplete(WithProject(context.Background(), "proj_search"), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if store.keys[1] == store.keys[0] {
+		t.Error("Expected a separate bucket for the project's API key")
+	}
+
+	// A cancelled wait fails the request without sending it
+	store.wait = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.ChatComplete(ctx, req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+	if len(adapter.chatRequests) != 2 {
+		t.Errorf("Expected the cancelled request not to be sent, got %d requests", len(adapter.chatRequests))
+	}
+
+	// Store failures are reported and the request is sent
+	store.wait, store.err = 0, errors.New("redis unavailable")
+	var reported error
+	c.config.OnRateLimiterError = func(err error) { reported = err }
+	if _, err := c.ChatComplete(context.Background(), req); err != nil || reported == nil {
+		t.Errorf("Expected the request to proceed and the error to be reported, got %v, %v", err, reported)
+	}
+}
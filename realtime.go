@@ -0,0 +1,191 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Realtime opens an experimental realtime speech-to-speech session.
+//
+// Audio and text are sent with the session's methods while events are read
+// with Recv, typically from separate goroutines. The session counts as in
+// flight until it is closed, so Close on the client waits for it, and the
+// usage of each response is passed to the configured UsageRecorder. A
+// BudgetGuard is checked when the session opens and before each
+// CreateResponse; responses the provider starts itself on detecting the end
+// of speech are not checked.
+// Providers that cannot open realtime sessions fail with a validation error
+// before any connection is made.
+//
+// Example:
+//
+//	session, err := client.Realtime(ctx, RealtimeRequest{Voice: "verse", Instructions: "Be brief."})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer session.Close()
+//	go streamMicrophone(session) // calls session.SendAudio
+//	for {
+//		event, err := session.Recv()
+//		if err != nil {
+//			break
+//		}
+//		switch event.Type {
+//		case RealtimeEventAudio:
+//			speaker.Write(event.Audio)
+//		case RealtimeEventSpeechStarted:
+//			speaker.Stop() // the caller interrupted the response
+//		}
+//	}
+//
+// Parameters:
+//   - ctx: Context for opening the session
+//   - req: The session's model, instructions, voice, audio formats and tools
+//
+// Returns:
+//   - RealtimeSession: The open session
+//   - error: A validation error for invalid tools or if realtime sessions are unsupported, or a provider error
+func (c *client) Realtime(ctx context.Context, req RealtimeRequest) (RealtimeSession, error) {
+	// The session stays in flight until it is closed
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opened := false
+	defer func() {
+		if !opened {
+			c.end()
+		}
+	}()
+
+	if err := c.requireFeatures(FeatureRealtime); err != nil {
+		return nil, err
+	}
+	realtime, ok := c.adapter.(RealtimeAdapter)
+	if !ok {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("feature %q not supported by provider %s", FeatureRealtime, c.provider),
+			Provider: string(c.provider),
+		}
+	}
+	for _, tool := range req.Tools {
+		if strings.TrimSpace(tool.Name) == "" {
+			return nil, &Error{
+				Type:     ErrorTypeValidation,
+				Message:  "realtime tool name cannot be empty",
+				Provider: string(c.provider),
+			}
+		}
+	}
+
+	ctx, err = c.withProject(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkEndpoint(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.checkBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	session, err := realtime.Realtime(ctx, req)
+	if err != nil {
+		return nil, c.sanitizeError(err, []string{req.Instructions})
+	}
+	opened = true
+	// The budget is checked during the session, after ctx may have ended
+	return &realtimeSession{RealtimeSession: session, client: c, ctx: context.WithoutCancel(ctx), model: req.Model, lastInput: time.Now()}, nil
+}
+
+// realtimeSession records the usage of a provider's realtime session and
+// releases the client when it ends
+type realtimeSession struct {
+	RealtimeSession
+	client *client
+	ctx    context.Context // Values of the opening context, for budget checks
+	model  string
+
+	mu        sync.Mutex
+	lastInput time.Time // When the caller last sent something, for response latency
+	once      sync.Once
+}
+
+// SendAudio implements RealtimeSession
+func (s *realtimeSession) SendAudio(audio []byte) error {
+	s.touch()
+	return s.RealtimeSession.SendAudio(audio)
+}
+
+// CommitAudio implements RealtimeSession
+func (s *realtimeSession) CommitAudio() error {
+	s.touch()
+	return s.RealtimeSession.CommitAudio()
+}
+
+// SendText implements RealtimeSession
+func (s *realtimeSession) SendText(text string) error {
+	s.touch()
+	return s.RealtimeSession.SendText(text)
+}
+
+// SendToolResult implements RealtimeSession
+func (s *realtimeSession) SendToolResult(callID, output string) error {
+	s.touch()
+	return s.RealtimeSession.SendToolResult(callID, output)
+}
+
+// CreateResponse implements RealtimeSession, failing without asking for a
+// response if a BudgetGuard rejects it
+func (s *realtimeSession) CreateResponse() error {
+	if err := s.client.checkBudget(s.ctx); err != nil {
+		return err
+	}
+	s.touch()
+	return s.RealtimeSession.CreateResponse()
+}
+
+// Recv implements RealtimeSession, recording the usage of each completed
+// response with the time since the caller's last input as its latency
+func (s *realtimeSession) Recv() (RealtimeEvent, error) {
+	event, err := s.RealtimeSession.Recv()
+	if err != nil {
+		s.release()
+		return event, err
+	}
+	if event.Type == RealtimeEventResponseDone && event.Usage != nil {
+		s.mu.Lock()
+		latency := time.Since(s.lastInput)
+		s.mu.Unlock()
+		// Adapters report the model they resolved, such as their default
+		model := event.Model
+		if model == "" {
+			model = s.model
+		}
+		s.client.observeResponse(ResponseMetadata{Model: model, ResponseID: event.ResponseID}, *event.Usage, latency)
+	}
+	return event, nil
+}
+
+// Close implements RealtimeSession
+func (s *realtimeSession) Close() error {
+	err := s.RealtimeSession.Close()
+	s.release()
+	return err
+}
+
+// touch records caller input
+func (s *realtimeSession) touch() {
+	s.mu.Lock()
+	s.lastInput = time.Now()
+	s.mu.Unlock()
+}
+
+// release marks the session as no longer in flight
+func (s *realtimeSession) release() {
+	s.once.Do(s.client.end)
+}
@@ -0,0 +1,159 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// fakeRealtimeSession replays events and records the audio sent to it
+type fakeRealtimeSession struct {
+	events []RealtimeEvent
+	audio  [][]byte
+	closed bool
+}
+
+func (s *fakeRealtimeSession) SendAudio(audio []byte) error {
+	s.audio = append(s.audio, audio)
+	return nil
+}
+func (s *fakeRealtimeSession) CommitAudio() error                         { return nil }
+func (s *fakeRealtimeSession) SendText(text string) error                 { return nil }
+func (s *fakeRealtimeSession) SendToolResult(callID, output string) error { return nil }
+func (s *fakeRealtimeSession) CreateResponse() error                      { return nil }
+func (s *fakeRealtimeSession) Interrupt() error                           { return nil }
+
+func (s *fakeRealtimeSession) Recv() (RealtimeEvent, error) {
+	if len(s.events) == 0 {
+		return RealtimeEvent{}, io.EOF
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+func (s *fakeRealtimeSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+// realtimeAdapter opens fake realtime sessions
+type realtimeAdapter struct {
+	mockAdapter
+	session  *fakeRealtimeSession
+	requests []RealtimeRequest
+}
+
+func (r *realtimeAdapter) Realtime(ctx context.Context, req RealtimeRequest) (RealtimeSession, error) {
+	r.requests = append(r.requests, req)
+	return r.session, nil
+}
+
+func (r *realtimeAdapter) SupportedFeatures() []string {
+	return append(r.mockAdapter.SupportedFeatures(), FeatureRealtime)
+}
+
+func TestRealtime(t *testing.T) {
+	adapter := &realtimeAdapter{session: &fakeRealtimeSession{events: []RealtimeEvent{
+		{Type: RealtimeEventAudio, Audio: []byte{1, 2}},
+		{Type: RealtimeEventResponseDone, ResponseID: "resp_1", Usage: &Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+		{Type: RealtimeEventResponseDone, ResponseID: "resp_2", Model: "gpt-4o-realtime-default", Usage: &Usage{TotalTokens: 7}},
+	}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	recorder := &recordingUsageRecorder{}
+	c.config.UsageRecorder = recorder
+
+	session, err := c.Realtime(context.Background(), RealtimeRequest{Model: "gpt-4o-realtime-preview", Voice: "verse"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(adapter.requests) != 1 || adapter.requests[0].Voice != "verse" {
+		t.Errorf("Expected the request to reach the adapter, got %+v", adapter.requests)
+	}
+	session.SendAudio([]byte{0})
+
+	var types []string
+	for {
+		event, err := session.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		types = append(types, event.Type)
+	}
+	if len(types) != 3 || len(adapter.session.audio) != 1 {
+		t.Errorf("Expected the events and audio to pass through, got %v", types)
+	}
+	if len(recorder.records) != 2 || recorder.records[0].Usage.TotalTokens != 15 || recorder.records[0].Model != "gpt-4o-realtime-preview" {
+		t.Fatalf("Expected the response usage to be recorded, got %+v", recorder.records)
+	}
+	if recorder.records[1].Model != "gpt-4o-realtime-default" {
+		t.Errorf("Expected the model reported by the adapter to be recorded, got %q", recorder.records[1].Model)
+	}
+
+	// The session ended, so the client closes without waiting for it
+	session.Close()
+	c.config.ShutdownTimeout = 10 * time.Millisecond
+	if err := c.Close(); err != nil {
+		t.Errorf("Expected the client to close, got %v", err)
+	}
+	if !adapter.session.closed {
+		t.Error("Expected the provider session to be closed")
+	}
+}
+
+func TestRealtime_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter ProviderAdapter
+		req     RealtimeRequest
+	}{
+		{"unsupported provider", &mockAdapter{}, RealtimeRequest{}},
+		{"unnamed tool", &realtimeAdapter{session: &fakeRealtimeSession{}}, RealtimeRequest{Tools: []RealtimeTool{{Description: "Looks up the weather"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newMockClient(ProviderOpenAI, tt.adapter)
+			_, err := c.Realtime(context.Background(), tt.req)
+			if apiErr, ok := err.(*Error); !ok || apiErr.Type != ErrorTypeValidation {
+				t.Errorf("Expected a validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRealtime_Budget(t *testing.T) {
+	adapter := &realtimeAdapter{session: &fakeRealtimeSession{}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	guard := &fixedBudget{err: &BudgetExceededError{Window: "daily", Limit: 100, Spent: 100}}
+	c.config.UsageRecorder = guard
+
+	var exceeded *BudgetExceededError
+	if _, err := c.Realtime(context.Background(), RealtimeRequest{}); !errors.As(err, &exceeded) {
+		t.Fatalf("Expected a budget exceeded error, got %v", err)
+	}
+	if len(adapter.requests) != 0 {
+		t.Errorf("Expected no session to be opened, got %d", len(adapter.requests))
+	}
+
+	guard.err = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	session, err := c.Realtime(ctx, RealtimeRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer session.Close()
+	cancel()
+	if err := session.CreateResponse(); err != nil {
+		t.Errorf("Expected a response within the budget after the opening context ended, got %v", err)
+	}
+	guard.err = &BudgetExceededError{Window: "daily", Limit: 100, Spent: 100}
+	if err := session.CreateResponse(); !errors.As(err, &exceeded) {
+		t.Errorf("Expected the budget to be checked before each response, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package aiprovider
+
+// generatedText returns everything the model generated for a message,
+// including reasoning, which providers bill as output tokens
+func generatedText(content, reasoning string) string {
+	if reasoning == "" {
+		return content
+	}
+	return reasoning + "\n" + content
+}
+
+// redactReasoning strips reasoning from a response when
+// Config.RedactReasoning is set. Usage must be verified first so it still
+// accounts for the reasoning tokens.
+func (c *client) redactReasoning(resp *ChatResponse) {
+	if !c.config.RedactReasoning || resp.Message.Reasoning == "" {
+		return
+	}
+	resp.Message.Reasoning = ""
+	resp.Metadata.ReasoningRedacted = true
+}
+
+// storedMessages returns the messages to store with an interaction, without
+// reasoning when Config.RedactReasoning is set
+func (c *client) storedMessages(messages []Message) []Message {
+	if !c.config.RedactReasoning {
+		return messages
+	}
+	var redacted []Message
+	for i, msg := range messages {
+		if msg.Reasoning == "" {
+			continue
+		}
+		if redacted == nil {
+			redacted = append([]Message(nil), messages...)
+		}
+		redacted[i].Reasoning = ""
+	}
+	if redacted == nil {
+		return messages
+	}
+	return redacted
+}
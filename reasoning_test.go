@@ -0,0 +1,115 @@
+package aiprovider
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// reasoningAdapter is a streamingAdapter that supports extended reasoning
+type reasoningAdapter struct {
+	streamingAdapter
+}
+
+func (r *reasoningAdapter) SupportedFeatures() []string {
+	return append(r.streamingAdapter.SupportedFeatures(), FeatureReasoning)
+}
+
+func TestChatComplete_Reasoning(t *testing.T) {
+	for _, redact := range []bool{false, true} {
+		adapter := &reasoningAdapter{streamingAdapter{mockAdapter: mockAdapter{chatResp: &ChatResponse{
+			Message: Message{Role: "assistant", Content: "51", Reasoning: "17 * 3 = 51."},
+			Usage:   Usage{PromptTokens: 10, CompletionTokens: 8, TotalTokens: 18},
+		}}}}
+		c := newMockClient(ProviderAnthropic, adapter)
+		store := &recordingStore{}
+		c.config.Store = store
+		c.config.RedactReasoning = redact
+
+		history := []Message{
+			{Role: "user", Content: "What is 6 * 7?"},
+			{Role: "assistant", Content: "42", Reasoning: "6 * 7 = 42."},
+			{Role: "user", Content: "What is 17 * 3?"},
+		}
+		resp, err := c.ChatComplete(context.Background(), ChatRequest{Messages: history, ReasoningBudget: intPtr(2048)})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		stored := store.records[0].Messages[1].Reasoning
+		if redact {
+			if resp.Message.Reasoning != "" || !resp.Metadata.ReasoningRedacted || stored != "" {
+				t.Errorf("Expected reasoning to be redacted, got %+v with %q stored", resp, stored)
+			}
+			if history[1].Reasoning == "" {
+				t.Error("Expected the caller's messages to be left unchanged")
+			}
+		} else if resp.Message.Reasoning != "17 * 3 = 51." || resp.Metadata.ReasoningRedacted || stored == "" {
+			t.Errorf("Expected reasoning to be returned, got %+v with %q stored", resp, stored)
+		}
+		if resp.Usage.CompletionTokens != 8 || len(resp.Metadata.UsageMismatches) != 0 {
+			t.Errorf("Expected usage to count the reasoning, got %+v", resp)
+		}
+	}
+
+	// Anthropic rejects budgets below its minimum before sending
+	small := &mockAdapter{chatResp: &ChatResponse{}}
+	anthropic := newMockClient(ProviderAnthropic, small)
+	_, err := anthropic.ChatComplete(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}, ReasoningBudget: intPtr(512)})
+	if err == nil || !strings.Contains(err.Error(), "at least 1024") || len(small.chatRequests) != 0 {
+		t.Errorf("Expected a validation error for a small budget, got %v", err)
+	}
+
+	// Providers without reasoning follow the unsupported parameter policy
+	plain := &mockAdapter{chatResp: &ChatResponse{}}
+	c := newMockClient(ProviderOpenAI, plain)
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}, ReasoningBudget: intPtr(2048)}
+	if _, err := c.ChatComplete(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if plain.chatRequests[0].ReasoningBudget != nil {
+		t.Error("Expected the reasoning budget to be dropped")
+	}
+	c.config.UnsupportedParameterPolicy = UnsupportedParameterError
+	if _, err := c.ChatComplete(context.Background(), req); err == nil {
+		t.Error("Expected an error for a provider without reasoning")
+	}
+}
+
+func TestStreamChat_RedactReasoning(t *testing.T) {
+	adapter := &reasoningAdapter{streamingAdapter{streams: []*sliceStream{{chunks: []StreamChunk{
+		{ReasoningDelta: "Greet ", Metadata: &ResponseMetadata{Model: "claude-3-7-sonnet-latest"}},
+		{ReasoningDelta: "back."},
+		{Delta: "Hello"},
+		{FinishReason: "end_turn", Usage: &Usage{PromptTokens: 5, CompletionTokens: 4, TotalTokens: 9}},
+	}}}}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.RedactReasoning = true
+
+	stream, err := c.StreamChat(context.Background(), ChatRequest{
+		Messages:        []Message{{Role: "user", Content: "Hi"}},
+		ReasoningBudget: intPtr(1024),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var chunks []StreamChunk
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		if chunk.ReasoningDelta != "" {
+			t.Errorf("Expected no reasoning in chunks, got %q", chunk.ReasoningDelta)
+		}
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 3 || chunks[0].Metadata == nil {
+		t.Errorf("Expected the empty reasoning chunk to be skipped and the metadata kept, got %+v", chunks)
+	}
+
+	resp := stream.Response()
+	if resp.Message.Content != "Hello" || resp.Message.Reasoning != "" || !resp.Metadata.ReasoningRedacted {
+		t.Errorf("Expected a redacted response, got %+v", resp)
+	}
+}
@@ -0,0 +1,346 @@
+package aiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/ajeet-kumar1087/ai-providers/rag"
+)
+
+// ReloadableClient is a Client whose configuration can be replaced while it
+// serves requests, so API keys can be rotated and models upgraded without a
+// restart.
+//
+// Reload builds a new client from the new configuration and swaps it in
+// atomically: requests started afterwards use the new client, while requests
+// and streams already in flight finish on the old one, which is then closed.
+// ReloadableClient is safe for concurrent use.
+type ReloadableClient struct {
+	provider ProviderType
+
+	mu       sync.RWMutex // Guards the fields below
+	current  Client
+	profiles map[string]RequestProfile  // Profiles registered with RegisterProfile
+	draining map[Client]struct{}        // Old clients still draining
+	calls    map[Client]*sync.WaitGroup // Calls in flight on each client
+	closed   bool
+
+	retiring  sync.WaitGroup // Old clients still draining
+	errMu     sync.Mutex     // Guards retireErr
+	retireErr error          // First error closing an old client
+}
+
+// NewReloadableClient creates a client for provider whose configuration can
+// be replaced with Reload.
+//
+// Example:
+//
+//	client, err := NewReloadableClient(ProviderOpenAI, config)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer client.Close()
+//
+//	// Later, e.g. on SIGHUP after a key rotation
+//	config.APIKey = os.Getenv("OPENAI_API_KEY")
+//	if err := client.Reload(config); err != nil {
+//		log.Printf("keeping previous config: %v", err)
+//	}
+//
+// Parameters:
+//   - provider: The AI provider to use
+//   - config: The initial configuration
+//
+// Returns:
+//   - *ReloadableClient: The client, usable anywhere a Client is
+//   - error: An error if the provider or configuration is invalid
+func NewReloadableClient(provider ProviderType, config Config) (*ReloadableClient, error) {
+	current, err := NewClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	return &ReloadableClient{
+		provider: provider,
+		current:  current,
+		profiles: make(map[string]RequestProfile),
+		draining: make(map[Client]struct{}),
+		calls:    map[Client]*sync.WaitGroup{current: {}},
+	}, nil
+}
+
+// Reload replaces the configuration of the client.
+//
+// The new configuration is validated first; if it is invalid the client
+// keeps its current configuration. Profiles registered with RegisterProfile
+// are carried over. The old client is closed in the background once the
+// calls made through this client on it return, including multi-request
+// helpers such as Summarize, and then its streams finish within its
+// Config.ShutdownTimeout.
+//
+// Parameters:
+//   - config: The new configuration
+//
+// Returns:
+//   - error: An error if the configuration is invalid or the client is closed
+func (r *ReloadableClient) Reload(config Config) error {
+	next, err := NewClient(r.provider, config)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		next.Close()
+		return ErrClientClosed
+	}
+	for name, profile := range r.profiles {
+		if err := next.RegisterProfile(name, profile); err != nil {
+			r.mu.Unlock()
+			next.Close()
+			return err
+		}
+	}
+	old := r.current
+	calls := r.calls[old]
+	r.current = next
+	r.calls[next] = &sync.WaitGroup{}
+	r.draining[old] = struct{}{}
+	r.retiring.Add(1)
+	r.mu.Unlock()
+
+	go func() {
+		defer r.retiring.Done()
+		// No call can start on old any more, so this wait ends
+		calls.Wait()
+		err := old.Close()
+		r.mu.Lock()
+		delete(r.draining, old)
+		delete(r.calls, old)
+		r.mu.Unlock()
+		if err != nil {
+			r.errMu.Lock()
+			if r.retireErr == nil {
+				r.retireErr = err
+			}
+			r.errMu.Unlock()
+		}
+	}()
+	return nil
+}
+
+// client returns the client serving new requests
+func (r *ReloadableClient) client() Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// acquire returns the client serving new requests, counting the call as in
+// flight on it until release is called, so a Reload does not close it
+// between the requests of the call
+func (r *ReloadableClient) acquire() (c Client, release func()) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	calls := r.calls[r.current]
+	calls.Add(1)
+	return r.current, calls.Done
+}
+
+// Complete implements Client
+func (r *ReloadableClient) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Complete(ctx, req)
+}
+
+// ChatComplete implements Client
+func (r *ReloadableClient) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.ChatComplete(ctx, req)
+}
+
+// RateLimitStatus implements Client, reporting the status observed by the
+// current client only
+func (r *ReloadableClient) RateLimitStatus() *RateLimitStatus {
+	return r.client().RateLimitStatus()
+}
+
+// Limits implements Client
+func (r *ReloadableClient) Limits(ctx context.Context) (*Limits, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Limits(ctx)
+}
+
+// Summarize implements Client
+func (r *ReloadableClient) Summarize(ctx context.Context, text string, opts SummarizeOptions) (*SummaryResult, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Summarize(ctx, text, opts)
+}
+
+// Classify implements Client
+func (r *ReloadableClient) Classify(ctx context.Context, text string, labels []string, opts ClassifyOptions) (*ClassificationResult, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Classify(ctx, text, labels, opts)
+}
+
+// CompleteWithVoting implements Client
+func (r *ReloadableClient) CompleteWithVoting(ctx context.Context, req CompletionRequest, k int, opts VotingOptions) (*VotingResult, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.CompleteWithVoting(ctx, req, k, opts)
+}
+
+// CompleteBestOfN implements Client
+func (r *ReloadableClient) CompleteBestOfN(ctx context.Context, req CompletionRequest, n int, opts BestOfNOptions) (*BestOfNResult, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.CompleteBestOfN(ctx, req, n, opts)
+}
+
+// Extract implements Client
+func (r *ReloadableClient) Extract(ctx context.Context, text string, schema json.RawMessage, opts ExtractOptions) (*ExtractionResult, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Extract(ctx, text, schema, opts)
+}
+
+// Translate implements Client
+func (r *ReloadableClient) Translate(ctx context.Context, text, targetLang string, opts TranslateOptions) (*TranslationResult, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Translate(ctx, text, targetLang, opts)
+}
+
+// Answer implements Client
+func (r *ReloadableClient) Answer(ctx context.Context, question string, docs []rag.Document, opts AnswerOptions) (*AnswerResult, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Answer(ctx, question, docs, opts)
+}
+
+// CountTokensRemote implements Client
+func (r *ReloadableClient) CountTokensRemote(ctx context.Context, req ChatRequest) (*TokenCount, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.CountTokensRemote(ctx, req)
+}
+
+// Speech implements Client
+func (r *ReloadableClient) Speech(ctx context.Context, req SpeechRequest) (*BinaryResponse, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Speech(ctx, req)
+}
+
+// Transcribe implements Client
+func (r *ReloadableClient) Transcribe(ctx context.Context, req TranscriptionRequest) (*TranscriptionResponse, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Transcribe(ctx, req)
+}
+
+// Realtime implements Client. Open sessions stay on the client they were
+// opened with after a reload.
+func (r *ReloadableClient) Realtime(ctx context.Context, req RealtimeRequest) (RealtimeSession, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.Realtime(ctx, req)
+}
+
+// SubmitBatch implements Client
+func (r *ReloadableClient) SubmitBatch(ctx context.Context, items []BatchItem) (*Batch, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.SubmitBatch(ctx, items)
+}
+
+// GetBatch implements Client
+func (r *ReloadableClient) GetBatch(ctx context.Context, id string) (*Batch, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.GetBatch(ctx, id)
+}
+
+// ListBatchResults implements Client
+func (r *ReloadableClient) ListBatchResults(ctx context.Context, id string) ([]BatchResult, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.ListBatchResults(ctx, id)
+}
+
+// RegisterProfile implements Client. The profile is registered again on the
+// clients created by later reloads.
+func (r *ReloadableClient) RegisterProfile(name string, profile RequestProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.current.RegisterProfile(name, profile); err != nil {
+		return err
+	}
+	r.profiles[name] = profile
+	return nil
+}
+
+// StreamChat implements Client
+func (r *ReloadableClient) StreamChat(ctx context.Context, req ChatRequest) (*ChatStream, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.StreamChat(ctx, req)
+}
+
+// StreamSpeculative implements Client
+func (r *ReloadableClient) StreamSpeculative(ctx context.Context, req ChatRequest, opts SpeculativeOptions) (*SpeculativeStream, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.StreamSpeculative(ctx, req, opts)
+}
+
+// ChatCompleteWithResume implements Client
+func (r *ReloadableClient) ChatCompleteWithResume(ctx context.Context, req ChatRequest, opts ResumeOptions) (*ChatResponse, error) {
+	c, release := r.acquire()
+	defer release()
+	return c.ChatCompleteWithResume(ctx, req, opts)
+}
+
+// CancelGroup implements Client, cancelling the group's requests on the
+// current client and on those replaced by Reload that are still draining
+func (r *ReloadableClient) CancelGroup(group string) int {
+	r.mu.RLock()
+	clients := []Client{r.current}
+	for old := range r.draining {
+		clients = append(clients, old)
+	}
+	r.mu.RUnlock()
+
+	cancelled := 0
+	for _, c := range clients {
+		cancelled += c.CancelGroup(group)
+	}
+	return cancelled
+}
+
+// SupportsFeature implements Client
+func (r *ReloadableClient) SupportsFeature(feature string) bool {
+	return r.client().SupportsFeature(feature)
+}
+
+// Close closes the current client and waits for the clients replaced by
+// Reload to finish closing, returning the errors of both
+func (r *ReloadableClient) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	current := r.current
+	r.mu.Unlock()
+
+	err := current.Close()
+	r.retiring.Wait()
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	return errors.Join(err, r.retireErr)
+}
@@ -0,0 +1,129 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestReloadableClient(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-Api-Key")
+		mu.Lock()
+		keys = append(keys, key)
+		mu.Unlock()
+		if key == "sk-ant-old-key-1234567890" {
+			started <- struct{}{}
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":2}}`)
+	}))
+	defer server.Close()
+
+	config := Config{APIKey: "sk-ant-old-key-1234567890", BaseURL: server.URL}
+	client, err := NewReloadableClient(ProviderAnthropic, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.RegisterProfile("terse", RequestProfile{SystemPrompt: "Be terse."}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+	inFlight := make(chan error)
+	go func() {
+		_, err := client.ChatComplete(context.Background(), req)
+		inFlight <- err
+	}()
+	<-started
+
+	// Invalid configurations are rejected and the current one kept
+	if err := client.Reload(Config{BaseURL: server.URL}); err == nil {
+		t.Error("Expected an error for a config without an API key")
+	}
+
+	config.APIKey = "sk-ant-new-key-1234567890"
+	if err := client.Reload(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	req.Profile = "terse"
+	if _, err := client.ChatComplete(context.Background(), req); err != nil {
+		t.Fatalf("Expected the profile to survive the reload, got %v", err)
+	}
+
+	close(release)
+	if err := <-inFlight; err != nil {
+		t.Errorf("Expected the in-flight request to finish on the old client, got %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("Unexpected error closing: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(keys) != 2 || keys[0] != "sk-ant-old-key-1234567890" || keys[1] != "sk-ant-new-key-1234567890" {
+		t.Errorf("Expected one request per key, got %v", keys)
+	}
+	if err := client.Reload(config); err != ErrClientClosed {
+		t.Errorf("Expected ErrClientClosed after Close, got %v", err)
+	}
+}
+
+func TestReloadableClient_ReloadDuringSummarize(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		first := requests == 1
+		mu.Unlock()
+		if first {
+			started <- struct{}{}
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Summary."}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":2}}`)
+	}))
+	defer server.Close()
+
+	config := Config{APIKey: "sk-ant-old-key-1234567890", BaseURL: server.URL}
+	client, err := NewReloadableClient(ProviderAnthropic, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	// Two chunks and a reduce step make three requests on the old client
+	text := "The first paragraph is about apples and pears.\n\nThe second paragraph is about boats and trains."
+	done := make(chan error)
+	go func() {
+		_, err := client.Summarize(context.Background(), text, SummarizeOptions{MaxChunkTokens: 12, Concurrency: 1})
+		done <- err
+	}()
+	<-started
+
+	config.APIKey = "sk-ant-new-key-1234567890"
+	if err := client.Reload(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Expected Summarize to finish on the old client, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
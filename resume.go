@@ -0,0 +1,229 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+)
+
+const (
+	// DefaultMaxResumes is the number of continuation requests made by
+	// ChatCompleteWithResume when ResumeOptions.MaxResumes is zero
+	DefaultMaxResumes = 2
+
+	// DefaultContinuePrompt asks the model to continue a cut-off answer on
+	// providers without assistant prefill
+	DefaultContinuePrompt = "Your previous answer was cut off. Continue exactly where it stopped, without repeating any text."
+
+	// maxStitchOverlap bounds the repeated text removed when stitching pieces
+	maxStitchOverlap = 200
+
+	// minStitchOverlap is the shortest repetition treated as an overlap
+	minStitchOverlap = 8
+)
+
+// ResumeOptions configures ChatCompleteWithResume.
+type ResumeOptions struct {
+	// MaxResumes is the maximum number of continuation requests
+	// (default: DefaultMaxResumes, negative disables resuming)
+	MaxResumes int
+
+	// ContinuePrompt is the user message asking the model to continue
+	// (default: DefaultContinuePrompt). Anthropic continues a prefilled
+	// assistant message instead, unless ContinuePrompt is set.
+	ContinuePrompt string
+
+	// OnResume is called before each continuation request with the number of
+	// the resume, the output so far and the reason the output was cut off (optional)
+	OnResume func(resume int, partial string, cause error)
+}
+
+// ErrTokenLimitReached is passed to ResumeOptions.OnResume when the output
+// stopped at the token limit
+var ErrTokenLimitReached = errors.New("output reached the token limit")
+
+// ChatCompleteWithResume sends a chat request and resumes the generation if
+// the output is cut off.
+//
+// The response is streamed when the provider supports it. If the stream
+// fails with a retryable error (a stall, dropped connection, rate limit or
+// network error) or the output stops at the token limit, the request is
+// re-issued with the partial output appended so the model continues where it
+// stopped. The pieces are stitched into a single response, with any text the
+// model repeated at the seam removed, and Metadata.Resumed set. Usage is the
+// total across all requests.
+//
+// Output cut off by MaxWords or MaxChars is never resumed.
+//
+// Example:
+//
+//	resp, err := client.ChatCompleteWithResume(ctx, ChatRequest{
+//		Messages:  messages,
+//		MaxTokens: &[]int{4096}[0],
+//	}, ResumeOptions{MaxResumes: 3})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if resp.Metadata.Resumed {
+//		log.Printf("stitched from %d generations", resp.Metadata.Resumes+1)
+//	}
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - req: The chat request with messages and optional parameters
+//   - opts: Resume limits and continuation prompt
+//
+// Returns:
+//   - *ChatResponse: The stitched response
+//   - error: A non-retryable request error, or the last error once resumes are exhausted
+func (c *client) ChatCompleteWithResume(ctx context.Context, req ChatRequest, opts ResumeOptions) (*ChatResponse, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	maxResumes := opts.MaxResumes
+	if maxResumes == 0 {
+		maxResumes = DefaultMaxResumes
+	}
+	lengthLimited := req.MaxWords != nil || req.MaxChars != nil
+
+	var text string
+	var usage Usage
+	var last *ChatResponse
+	resumes := 0
+
+	for {
+		pieceReq := req
+		if text != "" {
+			pieceReq.Messages = c.continuation(req.Messages, text, opts.ContinuePrompt)
+		}
+
+		piece, err := c.generatePiece(ctx, pieceReq)
+		if piece != nil {
+			text = stitch(text, piece.Message.Content)
+			usage = addUsage(usage, piece.Usage)
+			last = piece
+		}
+
+		cause := err
+		if err == nil {
+			if lengthLimited || !isLengthFinish(piece.FinishReason) {
+				break
+			}
+			cause = ErrTokenLimitReached
+		} else if !isResumable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		if resumes >= maxResumes {
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		resumes++
+		if opts.OnResume != nil {
+			opts.OnResume(resumes, text, cause)
+		}
+	}
+
+	last.Message.Content = text
+	last.Usage = usage
+	last.Metadata.Resumed = resumes > 0
+	last.Metadata.Resumes = resumes
+	return last, nil
+}
+
+// generatePiece sends one request, streaming when supported. On a stream
+// error it returns the partial response along with the error.
+func (c *client) generatePiece(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if !c.SupportsFeature(FeatureStreaming) {
+		return c.ChatComplete(ctx, req)
+	}
+
+	stream, err := c.StreamChat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if err == io.EOF {
+				return stream.Response(), nil
+			}
+			return stream.Response(), err
+		}
+	}
+}
+
+// continuation returns the messages asking the model to continue partial
+func (c *client) continuation(messages []Message, partial, continuePrompt string) []Message {
+	return continuationMessages(c.provider, messages, partial, continuePrompt)
+}
+
+// continuationMessages returns messages asking a provider's model to continue partial
+func continuationMessages(provider ProviderType, messages []Message, partial, continuePrompt string) []Message {
+	continued := make([]Message, len(messages), len(messages)+2)
+	copy(continued, messages)
+
+	// Anthropic continues a trailing assistant message, which must not end in whitespace
+	if provider == ProviderAnthropic && continuePrompt == "" {
+		return append(continued, Message{Role: "assistant", Content: strings.TrimRight(partial, " \t\r\n")})
+	}
+
+	if continuePrompt == "" {
+		continuePrompt = DefaultContinuePrompt
+	}
+	return append(continued,
+		Message{Role: "assistant", Content: partial},
+		Message{Role: "user", Content: continuePrompt},
+	)
+}
+
+// isLengthFinish reports whether a finish reason means the token limit was reached
+func isLengthFinish(reason string) bool {
+	return reason == "length" || reason == "max_tokens"
+}
+
+// isResumable reports whether a generation error may be recovered by resuming
+func isResumable(err error) bool {
+	if errors.Is(err, ErrStreamStalled) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var aiErr *Error
+	if errors.As(err, &aiErr) {
+		return aiErr.IsRetryable()
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// stitch appends continuation to text, dropping text the model repeated at the seam
+func stitch(text, continuation string) string {
+	if text == "" {
+		return continuation
+	}
+
+	// Whitespace trimmed from a prefill is often repeated by the model
+	if strings.TrimRight(text, " \t\r\n") != text {
+		continuation = strings.TrimLeft(continuation, " \t\r\n")
+	}
+
+	limit := len(continuation)
+	if limit > maxStitchOverlap {
+		limit = maxStitchOverlap
+	}
+	for n := limit; n >= minStitchOverlap; n-- {
+		if strings.HasSuffix(text, continuation[:n]) {
+			return text + continuation[n:]
+		}
+	}
+	return text + continuation
+}
@@ -0,0 +1,135 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// sequenceAdapter answers chat requests with the given responses in order
+type sequenceAdapter struct {
+	mockAdapter
+	responses []*ChatResponse
+}
+
+func (s *sequenceAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	s.chatRequests = append(s.chatRequests, req)
+	resp := *s.responses[0]
+	s.responses = s.responses[1:]
+	return &resp, nil
+}
+
+func TestChatCompleteWithResume_Stream(t *testing.T) {
+	adapter := &streamingAdapter{streams: []*sliceStream{
+		{chunks: []StreamChunk{{Delta: "The quick brown "}, {Delta: "fox jumps"}}, err: ErrStreamStalled},
+		{chunks: []StreamChunk{
+			{Delta: " over the lazy dog."},
+			{FinishReason: "end_turn", Usage: &Usage{PromptTokens: 20, CompletionTokens: 5, TotalTokens: 25}},
+		}},
+	}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	var causes []error
+	resp, err := c.ChatCompleteWithResume(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Write a pangram"}},
+	}, ResumeOptions{
+		OnResume: func(resume int, partial string, cause error) { causes = append(causes, cause) },
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Message.Content != "The quick brown fox jumps over the lazy dog." {
+		t.Errorf("Unexpected stitched content: %q", resp.Message.Content)
+	}
+	if !resp.Metadata.Resumed || resp.Metadata.Resumes != 1 {
+		t.Errorf("Expected resumed metadata, got %+v", resp.Metadata)
+	}
+	if len(causes) != 1 || !errors.Is(causes[0], ErrStreamStalled) {
+		t.Errorf("Expected one stall resume, got %v", causes)
+	}
+
+	// Anthropic continues a prefilled assistant message
+	continued := adapter.streamRequests[1].Messages
+	if len(continued) != 2 || continued[1].Role != "assistant" || continued[1].Content != "The quick brown fox jumps" {
+		t.Errorf("Expected assistant prefill, got %+v", continued)
+	}
+}
+
+func TestChatCompleteWithResume_LengthCap(t *testing.T) {
+	adapter := &sequenceAdapter{responses: []*ChatResponse{
+		{Message: Message{Content: "Step one. Step two. "}, FinishReason: "length", Usage: Usage{TotalTokens: 10}},
+		{Message: Message{Content: "Step two. Step three."}, FinishReason: "stop", Usage: Usage{TotalTokens: 12}},
+	}}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	resp, err := c.ChatCompleteWithResume(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "List the steps"}},
+	}, ResumeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Message.Content != "Step one. Step two. Step three." {
+		t.Errorf("Expected repeated text at the seam to be removed, got %q", resp.Message.Content)
+	}
+	if resp.Usage.TotalTokens != 22 {
+		t.Errorf("Expected total usage across requests, got %d", resp.Usage.TotalTokens)
+	}
+
+	continued := adapter.chatRequests[1].Messages
+	if len(continued) != 3 || continued[1].Role != "assistant" || continued[2].Content != DefaultContinuePrompt {
+		t.Errorf("Expected continue prompt, got %+v", continued)
+	}
+}
+
+func TestChatCompleteWithResume_Limits(t *testing.T) {
+	t.Run("stops after max resumes", func(t *testing.T) {
+		adapter := &sequenceAdapter{responses: []*ChatResponse{
+			{Message: Message{Content: "a"}, FinishReason: "length"},
+			{Message: Message{Content: "b"}, FinishReason: "length"},
+		}}
+		c := newMockClient(ProviderOpenAI, adapter)
+
+		resp, err := c.ChatCompleteWithResume(context.Background(), ChatRequest{
+			Messages: []Message{{Role: "user", Content: "Go"}},
+		}, ResumeOptions{MaxResumes: 1})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Message.Content != "ab" || resp.FinishReason != "length" || resp.Metadata.Resumes != 1 {
+			t.Errorf("Unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("does not resume word limits", func(t *testing.T) {
+		adapter := &sequenceAdapter{responses: []*ChatResponse{
+			{Message: Message{Content: "one two three"}, FinishReason: "stop"},
+		}}
+		c := newMockClient(ProviderOpenAI, adapter)
+
+		resp, err := c.ChatCompleteWithResume(context.Background(), ChatRequest{
+			Messages: []Message{{Role: "user", Content: "Go"}},
+			MaxWords: intPtr(2),
+		}, ResumeOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Message.Content != "one two" || resp.Metadata.Resumed {
+			t.Errorf("Unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("returns non-retryable errors", func(t *testing.T) {
+		authErr := &Error{Type: ErrorTypeAuth, Provider: "anthropic", Message: "bad key"}
+		adapter := &streamingAdapter{streams: []*sliceStream{{err: authErr}}}
+		c := newMockClient(ProviderAnthropic, adapter)
+
+		_, err := c.ChatCompleteWithResume(context.Background(), ChatRequest{
+			Messages: []Message{{Role: "user", Content: "Go"}},
+		}, ResumeOptions{})
+		if err != authErr {
+			t.Errorf("Expected auth error, got %v", err)
+		}
+	})
+}
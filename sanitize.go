@@ -0,0 +1,138 @@
+package aiprovider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ajeet-kumar1087/ai-providers/adapters/anthropic"
+	"github.com/ajeet-kumar1087/ai-providers/adapters/openai"
+)
+
+const (
+	// minEchoLength is the shortest run of prompt text in an error message
+	// treated as an echo; shorter matches are mostly common words
+	minEchoLength = 16
+
+	// redactedEcho replaces stripped prompt echoes
+	redactedEcho = "[redacted]"
+)
+
+// sanitizeError removes prompt text echoed in a provider error message
+// according to the configured policy. The sanitized error is an *Error
+// wrapping the original, which keeps the full message; errors without echoes
+// are returned unchanged.
+func (c *client) sanitizeError(err error, texts []string) error {
+	policy := c.config.ErrorSanitization
+	if err == nil || policy == "" || policy == ErrorSanitizationOff {
+		return err
+	}
+
+	var sanitized *Error
+	if e, ok := err.(*Error); ok {
+		copied := *e
+		sanitized = &copied
+	} else {
+		sanitized = c.toError(err)
+	}
+
+	message := redactEchoes(sanitized.Message, texts, policy)
+	if message == sanitized.Message {
+		return err
+	}
+	sanitized.Message = message
+	sanitized.Wrapped = err
+	return sanitized
+}
+
+// toError converts an adapter error to an *Error with the same type,
+// message and code, without wrapping it
+func (c *client) toError(err error) *Error {
+	switch e := err.(type) {
+	case *anthropic.Error:
+		return &Error{Type: ErrorType(e.Type), Message: e.Message, Code: e.Code, Provider: e.Provider, RetryAfter: e.RetryAfter}
+	case *openai.Error:
+		return &Error{Type: ErrorType(e.Type), Message: e.Message, Code: e.Code, Provider: e.Provider, RetryAfter: e.RetryAfter}
+	default:
+		return &Error{Type: ErrorTypeProvider, Message: err.Error(), Provider: string(c.provider)}
+	}
+}
+
+// completionTexts returns the prompt text of a completion request
+func completionTexts(req CompletionRequest) []string {
+	return []string{req.Prompt}
+}
+
+// chatTexts returns the message contents of a chat request
+func chatTexts(req ChatRequest) []string {
+	texts := make([]string, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		texts = append(texts, msg.Content)
+	}
+	return texts
+}
+
+// redactEchoes replaces runs of message that occur in any of texts
+func redactEchoes(message string, texts []string, policy ErrorSanitizationPolicy) string {
+	var b strings.Builder
+	changed := false
+	for i := 0; i < len(message); {
+		n := 0
+		if (i == 0 || isEchoBoundary(message[i-1])) && !isEchoBoundary(message[i]) {
+			n = echoLength(message[i:], texts)
+		}
+		if n == 0 {
+			b.WriteByte(message[i])
+			i++
+			continue
+		}
+
+		changed = true
+		if policy == ErrorSanitizationHash {
+			sum := sha256.Sum256([]byte(message[i : i+n]))
+			b.WriteString("[sha256:" + hex.EncodeToString(sum[:4]) + "]")
+		} else {
+			b.WriteString(redactedEcho)
+		}
+		i += n
+	}
+	if !changed {
+		return message
+	}
+	return b.String()
+}
+
+// isEchoBoundary reports whether c is an ASCII byte separating words
+func isEchoBoundary(c byte) bool {
+	return c < utf8.RuneSelf && !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9')
+}
+
+// echoLength returns the length of the longest prefix of s, at least
+// minEchoLength bytes, contained in any of texts, or 0 if there is none
+func echoLength(s string, texts []string) int {
+	if len(s) < minEchoLength {
+		return 0
+	}
+
+	longest := 0
+	for _, text := range texts {
+		if !strings.Contains(text, s[:minEchoLength]) {
+			continue
+		}
+		// Prefix containment is monotonic, so search for the longest one
+		n := minEchoLength + sort.Search(len(s)-minEchoLength, func(extra int) bool {
+			return !strings.Contains(text, s[:minEchoLength+extra+1])
+		})
+		if n > longest {
+			longest = n
+		}
+	}
+
+	// Never split a multi-byte character or redact trailing whitespace
+	for longest > 0 && longest < len(s) && !utf8.RuneStart(s[longest]) {
+		longest--
+	}
+	return len(strings.TrimRight(s[:longest], " \t\r\n"))
+}
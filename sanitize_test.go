@@ -0,0 +1,150 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/adapters/anthropic"
+)
+
+func TestRedactEchoes(t *testing.T) {
+	texts := []string{"Summarize the account history of John Smith, SSN 123-45-6789, for review."}
+
+	tests := []struct {
+		name     string
+		message  string
+		policy   ErrorSanitizationPolicy
+		expected string
+	}{
+		{
+			name:     "strip echo",
+			message:  `invalid request: "account history of John Smith, SSN 123-45-6789" exceeds limits`,
+			policy:   ErrorSanitizationStrip,
+			expected: `invalid request: "[redacted]" exceeds limits`,
+		},
+		{
+			name:     "hash echo",
+			message:  "rejected: John Smith, SSN 123-45-6789",
+			policy:   ErrorSanitizationHash,
+			expected: "rejected: [sha256:",
+		},
+		{
+			name:     "short overlap kept",
+			message:  "the account is invalid",
+			policy:   ErrorSanitizationStrip,
+			expected: "the account is invalid",
+		},
+		{
+			name:     "no echo",
+			message:  "max_tokens: must be less than 4096",
+			policy:   ErrorSanitizationStrip,
+			expected: "max_tokens: must be less than 4096",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactEchoes(tt.message, texts, tt.policy)
+			if !strings.HasPrefix(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+			if strings.Contains(got, "123-45-6789") {
+				t.Errorf("Expected the SSN to be removed, got %q", got)
+			}
+		})
+	}
+}
+
+func TestSanitizeError(t *testing.T) {
+	providerErr := &anthropic.Error{
+		Type:     "validation",
+		Message:  "messages.0.content: unexpected text 'my password is hunter2-hunter2' in request",
+		Code:     "invalid_request_error",
+		Provider: "anthropic",
+	}
+	adapter := &mockAdapter{err: providerErr}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.ErrorSanitization = ErrorSanitizationStrip
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Remember that my password is hunter2-hunter2."}}}
+	_, err := c.ChatComplete(context.Background(), req)
+
+	var aiErr *Error
+	if !errors.As(err, &aiErr) {
+		t.Fatalf("Expected *Error, got %T: %v", err, err)
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected the echo stripped, got %q", err.Error())
+	}
+	if aiErr.Type != ErrorTypeValidation || aiErr.Code != "invalid_request_error" {
+		t.Errorf("Expected type and code preserved, got %+v", aiErr)
+	}
+
+	var original *anthropic.Error
+	if !errors.As(err, &original) || !strings.Contains(original.Message, "hunter2") {
+		t.Errorf("Expected the full provider error to stay wrapped, got %v", original)
+	}
+
+	// Disabled by default
+	c.config.ErrorSanitization = ""
+	if _, err := c.ChatComplete(context.Background(), req); err != providerErr {
+		t.Errorf("Expected the provider error unchanged, got %v", err)
+	}
+}
+
+// reopenErrorAdapter serves its streams in order and then fails to open
+type reopenErrorAdapter struct {
+	streamingAdapter
+}
+
+func (a *reopenErrorAdapter) StreamChat(ctx context.Context, req ChatRequest) (StreamReader, error) {
+	if len(a.streams) == 0 {
+		return nil, a.err
+	}
+	return a.streamingAdapter.StreamChat(ctx, req)
+}
+
+func TestSanitizeError_Stream(t *testing.T) {
+	providerErr := &anthropic.Error{
+		Type:     "server",
+		Message:  "failed while processing 'my password is hunter2-hunter2'",
+		Provider: "anthropic",
+	}
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Remember that my password is hunter2-hunter2."}}}
+
+	t.Run("reopening a stalled stream", func(t *testing.T) {
+		adapter := &reopenErrorAdapter{streamingAdapter{
+			mockAdapter: mockAdapter{err: providerErr},
+			streams:     []*sliceStream{{err: ErrStreamStalled}},
+		}}
+		c := newMockClient(ProviderAnthropic, adapter)
+		c.config.ErrorSanitization = ErrorSanitizationStrip
+		c.config.StreamStallRetries = 1
+
+		stream, err := c.StreamChat(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_, err = drain(stream)
+		if !errors.Is(err, providerErr) || strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("Expected the reopen error with the echo stripped, got %v", err)
+		}
+	})
+
+	t.Run("reading mid-stream", func(t *testing.T) {
+		adapter := &streamingAdapter{streams: []*sliceStream{{chunks: []StreamChunk{{Delta: "Hel"}}, err: providerErr}}}
+		c := newMockClient(ProviderAnthropic, adapter)
+		c.config.ErrorSanitization = ErrorSanitizationStrip
+
+		stream, err := c.StreamChat(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_, err = drain(stream)
+		if !errors.Is(err, providerErr) || strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("Expected the read error with the echo stripped, got %v", err)
+		}
+	})
+}
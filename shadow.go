@@ -0,0 +1,341 @@
+package aiprovider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultShadowTimeout is the timeout of shadow requests used when
+	// ShadowOptions.Timeout is zero
+	DefaultShadowTimeout = 60 * time.Second
+
+	// DefaultShadowMaxInFlight is the number of concurrent shadow requests
+	// used when ShadowOptions.MaxInFlight is zero
+	DefaultShadowMaxInFlight = 16
+)
+
+// ShadowOptions configures a ShadowClient.
+type ShadowOptions struct {
+	// Model overrides the request model for shadow requests (optional)
+	Model string
+
+	// Timeout limits each shadow request, which is detached from the
+	// caller's context (default: DefaultShadowTimeout)
+	Timeout time.Duration
+
+	// MaxInFlight is the maximum number of concurrent shadow requests;
+	// requests beyond it are not shadowed (default: DefaultShadowMaxInFlight)
+	MaxInFlight int
+
+	// OnResult is called from a background goroutine with the primary and
+	// shadow outcomes of every shadowed request (optional)
+	OnResult func(ShadowResult)
+}
+
+// ShadowResponse is one side of a shadowed request.
+type ShadowResponse struct {
+	// Text is the generated text or assistant message content
+	Text string `json:"text"`
+
+	// FinishReason is why generation stopped
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// Usage is the token usage of the request
+	Usage Usage `json:"usage"`
+
+	// Metadata describes how the request was served
+	Metadata ResponseMetadata `json:"metadata"`
+
+	// Latency is the response time
+	Latency time.Duration `json:"latency"`
+}
+
+// ShadowResult compares the primary and shadow outcomes of a request.
+type ShadowResult struct {
+	// Request is the CompletionRequest or ChatRequest sent to the primary client
+	Request interface{} `json:"request"`
+
+	// Primary is the primary response, nil if it failed or was streamed
+	Primary *ShadowResponse `json:"primary,omitempty"`
+
+	// PrimaryErr is the primary error, if any
+	PrimaryErr error `json:"-"`
+
+	// Shadow is the shadow response, nil if it failed
+	Shadow *ShadowResponse `json:"shadow,omitempty"`
+
+	// ShadowErr is the shadow error, if any
+	ShadowErr error `json:"-"`
+}
+
+// ShadowStats counts shadow requests.
+type ShadowStats struct {
+	// Sent is the number of shadow requests started
+	Sent int64 `json:"sent"`
+
+	// Succeeded is the number of shadow requests that returned a response
+	Succeeded int64 `json:"succeeded"`
+
+	// Failed is the number of shadow requests that returned an error
+	Failed int64 `json:"failed"`
+
+	// Skipped is the number of requests not shadowed because MaxInFlight
+	// shadow requests were already running
+	Skipped int64 `json:"skipped"`
+}
+
+// ShadowClient serves every request from a primary client and also sends it,
+// in the background, to a shadow client whose responses are never returned.
+//
+// Shadow traffic evaluates a migration target, such as another provider or
+// model, under real traffic without user impact: shadow requests do not
+// delay or fail primary requests, and when too many are pending new requests
+// are simply not shadowed. Compare both sides with ShadowOptions.OnResult, or
+// configure a UsageRecorder or Store on the shadow client.
+//
+// Complete, ChatComplete and StreamChat are shadowed; streamed requests are
+// shadowed with a non-streaming ChatComplete. Other Client methods are served
+// by the primary client only. ShadowClient is safe for concurrent use if its
+// clients are.
+type ShadowClient struct {
+	Client
+
+	shadow Client
+	opts   ShadowOptions
+	slots  chan struct{}
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex // Guards stats
+	stats ShadowStats
+}
+
+// NewShadowClient creates a client that mirrors requests to a shadow client.
+//
+// Example:
+//
+//	current, _ := NewClient(ProviderOpenAI, openaiConfig)
+//	candidate, _ := NewClient(ProviderAnthropic, anthropicConfig)
+//	client, err := NewShadowClient(current, candidate, ShadowOptions{
+//		Model: "claude-3-5-sonnet-20241022",
+//		OnResult: func(r ShadowResult) {
+//			if r.Primary != nil && r.Shadow != nil {
+//				log.Printf("primary %v, shadow %v", r.Primary.Latency, r.Shadow.Latency)
+//			}
+//		},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer client.Close()
+//
+// Parameters:
+//   - primary: The client whose responses are returned
+//   - shadow: The client evaluated in the background
+//   - opts: Shadow model, limits and result hook
+//
+// Returns:
+//   - *ShadowClient: The shadowing client, usable anywhere a Client is
+//   - error: A validation error if a client is missing or an option is negative
+func NewShadowClient(primary, shadow Client, opts ShadowOptions) (*ShadowClient, error) {
+	if primary == nil || shadow == nil {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "shadow client requires a primary and a shadow client",
+			Provider: "shadow",
+		}
+	}
+	if opts.Timeout < 0 || opts.MaxInFlight < 0 {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "shadow timeout and max in-flight must be non-negative",
+			Provider: "shadow",
+		}
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultShadowTimeout
+	}
+	if opts.MaxInFlight == 0 {
+		opts.MaxInFlight = DefaultShadowMaxInFlight
+	}
+
+	return &ShadowClient{
+		Client: primary,
+		shadow: shadow,
+		opts:   opts,
+		slots:  make(chan struct{}, opts.MaxInFlight),
+	}, nil
+}
+
+// Complete sends the request to the primary client and returns its response,
+// mirroring the request to the shadow client in the background
+func (s *ShadowClient) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	primary := s.mirror(ctx, req, s.shadowComplete(req))
+
+	start := time.Now()
+	resp, err := s.Client.Complete(ctx, req)
+	if primary != nil {
+		if err != nil {
+			primary <- shadowOutcome{err: err}
+		} else {
+			primary <- shadowOutcome{resp: completionShadowResponse(resp, time.Since(start))}
+		}
+	}
+	return resp, err
+}
+
+// ChatComplete sends the request to the primary client and returns its
+// response, mirroring the request to the shadow client in the background
+func (s *ShadowClient) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	primary := s.mirror(ctx, req, s.shadowChat(req))
+
+	start := time.Now()
+	resp, err := s.Client.ChatComplete(ctx, req)
+	if primary != nil {
+		if err != nil {
+			primary <- shadowOutcome{err: err}
+		} else {
+			primary <- shadowOutcome{resp: chatShadowResponse(resp, time.Since(start))}
+		}
+	}
+	return resp, err
+}
+
+// StreamChat opens a stream on the primary client, mirroring the request to
+// the shadow client with a non-streaming ChatComplete in the background
+func (s *ShadowClient) StreamChat(ctx context.Context, req ChatRequest) (*ChatStream, error) {
+	primary := s.mirror(ctx, req, s.shadowChat(req))
+
+	stream, err := s.Client.StreamChat(ctx, req)
+	if primary != nil {
+		primary <- shadowOutcome{err: err}
+	}
+	return stream, err
+}
+
+// Stats returns the shadow request counters
+func (s *ShadowClient) Stats() ShadowStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Wait blocks until all pending shadow requests and OnResult calls are done
+func (s *ShadowClient) Wait() {
+	s.wg.Wait()
+}
+
+// CancelGroup cancels the group's requests on both clients, returning the
+// number cancelled on the primary
+func (s *ShadowClient) CancelGroup(group string) int {
+	s.shadow.CancelGroup(group)
+	return s.Client.CancelGroup(group)
+}
+
+// Close waits for pending shadow requests and closes both clients,
+// returning the first error
+func (s *ShadowClient) Close() error {
+	s.Wait()
+	err := s.Client.Close()
+	if shadowErr := s.shadow.Close(); err == nil {
+		err = shadowErr
+	}
+	return err
+}
+
+// shadowOutcome is the result of one side of a shadowed request
+type shadowOutcome struct {
+	resp *ShadowResponse
+	err  error
+}
+
+// shadowComplete returns the shadow request function for a completion request
+func (s *ShadowClient) shadowComplete(req CompletionRequest) func(context.Context) (*ShadowResponse, error) {
+	if s.opts.Model != "" {
+		req.Model = s.opts.Model
+	}
+	return func(ctx context.Context) (*ShadowResponse, error) {
+		start := time.Now()
+		resp, err := s.shadow.Complete(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return completionShadowResponse(resp, time.Since(start)), nil
+	}
+}
+
+// shadowChat returns the shadow request function for a chat request
+func (s *ShadowClient) shadowChat(req ChatRequest) func(context.Context) (*ShadowResponse, error) {
+	if s.opts.Model != "" {
+		req.Model = s.opts.Model
+	}
+	return func(ctx context.Context) (*ShadowResponse, error) {
+		start := time.Now()
+		resp, err := s.shadow.ChatComplete(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return chatShadowResponse(resp, time.Since(start)), nil
+	}
+}
+
+// completionShadowResponse summarizes a completion response
+func completionShadowResponse(resp *CompletionResponse, latency time.Duration) *ShadowResponse {
+	return &ShadowResponse{Text: resp.Text, FinishReason: resp.FinishReason, Usage: resp.Usage, Metadata: resp.Metadata, Latency: latency}
+}
+
+// chatShadowResponse summarizes a chat response
+func chatShadowResponse(resp *ChatResponse, latency time.Duration) *ShadowResponse {
+	return &ShadowResponse{Text: resp.Message.Content, FinishReason: resp.FinishReason, Usage: resp.Usage, Metadata: resp.Metadata, Latency: latency}
+}
+
+// mirror starts a shadow request in the background and returns the channel
+// receiving the primary outcome, or nil if the request is not shadowed
+func (s *ShadowClient) mirror(ctx context.Context, req interface{}, send func(context.Context) (*ShadowResponse, error)) chan<- shadowOutcome {
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		s.count(func(stats *ShadowStats) { stats.Skipped++ })
+		return nil
+	}
+	s.count(func(stats *ShadowStats) { stats.Sent++ })
+
+	primary := make(chan shadowOutcome, 1)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.opts.Timeout)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.slots }()
+		defer cancel()
+
+		resp, err := send(ctx)
+		s.count(func(stats *ShadowStats) {
+			if err != nil {
+				stats.Failed++
+			} else {
+				stats.Succeeded++
+			}
+		})
+		if s.opts.OnResult == nil {
+			return
+		}
+
+		outcome := <-primary
+		s.opts.OnResult(ShadowResult{
+			Request:    req,
+			Primary:    outcome.resp,
+			PrimaryErr: outcome.err,
+			Shadow:     resp,
+			ShadowErr:  err,
+		})
+	}()
+	return primary
+}
+
+// count updates the shadow request counters
+func (s *ShadowClient) count(update func(*ShadowStats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(&s.stats)
+}
@@ -0,0 +1,114 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// blockingClient is a Client whose ChatComplete waits for release
+type blockingClient struct {
+	Client
+	release chan struct{}
+	ctxErr  error
+}
+
+func (b *blockingClient) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	<-b.release
+	b.ctxErr = ctx.Err()
+	return &ChatResponse{Message: Message{Role: "assistant", Content: "late"}}, nil
+}
+
+func TestShadowClient(t *testing.T) {
+	primaryAdapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "primary"}}}
+	shadowAdapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "shadow"}}}
+
+	var mu sync.Mutex
+	var results []ShadowResult
+	client, err := NewShadowClient(newMockClient(ProviderOpenAI, primaryAdapter), newMockClient(ProviderAnthropic, shadowAdapter), ShadowOptions{
+		Model: "claude-3-5-sonnet-20241022",
+		OnResult: func(r ShadowResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, r)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	req := ChatRequest{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	resp, err := client.ChatComplete(context.Background(), req)
+	if err != nil || resp.Message.Content != "primary" {
+		t.Fatalf("Expected the primary response, got %+v, %v", resp, err)
+	}
+	client.Wait()
+
+	if len(shadowAdapter.chatRequests) != 1 || shadowAdapter.chatRequests[0].Model != "claude-3-5-sonnet-20241022" {
+		t.Errorf("Expected the request mirrored with the shadow model, got %+v", shadowAdapter.chatRequests)
+	}
+	if primaryAdapter.chatRequests[0].Model != "gpt-4o" {
+		t.Errorf("Expected the primary model unchanged, got %q", primaryAdapter.chatRequests[0].Model)
+	}
+	if len(results) != 1 || results[0].Primary.Text != "primary" || results[0].Shadow.Text != "shadow" {
+		t.Fatalf("Expected both responses in the result, got %+v", results)
+	}
+	if stats := client.Stats(); stats.Sent != 1 || stats.Succeeded != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// Shadow failures never reach the caller
+	shadowAdapter.err = errors.New("shadow down")
+	if _, err := client.ChatComplete(context.Background(), req); err != nil {
+		t.Fatalf("Expected the shadow error to be hidden, got %v", err)
+	}
+	client.Wait()
+	if results[1].ShadowErr == nil || results[1].Primary == nil {
+		t.Errorf("Expected the shadow error in the result, got %+v", results[1])
+	}
+	if stats := client.Stats(); stats.Failed != 1 {
+		t.Errorf("Expected a failed shadow request, got %+v", stats)
+	}
+}
+
+func TestShadowClient_Detached(t *testing.T) {
+	shadow := &blockingClient{release: make(chan struct{})}
+	client, err := NewShadowClient(newMockClient(ProviderOpenAI, &mockAdapter{
+		chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "primary"}},
+	}), shadow, ShadowOptions{MaxInFlight: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}
+	if _, err := client.ChatComplete(ctx, req); err != nil {
+		t.Fatalf("Expected the primary not to wait for the shadow, got %v", err)
+	}
+	cancel()
+
+	// The single in-flight slot is taken, so the next request is not shadowed
+	if _, err := client.ChatComplete(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stats := client.Stats(); stats.Sent != 1 || stats.Skipped != 1 {
+		t.Errorf("Expected one sent and one skipped shadow request, got %+v", stats)
+	}
+
+	close(shadow.release)
+	client.Wait()
+	if shadow.ctxErr != nil {
+		t.Errorf("Expected the shadow request to outlive the caller's context, got %v", shadow.ctxErr)
+	}
+}
+
+func TestNewShadowClient_Validation(t *testing.T) {
+	primary := newMockClient(ProviderOpenAI, &mockAdapter{})
+	if _, err := NewShadowClient(primary, nil, ShadowOptions{}); err == nil {
+		t.Error("Expected an error without a shadow client")
+	}
+	if _, err := NewShadowClient(primary, primary, ShadowOptions{MaxInFlight: -1}); err == nil {
+		t.Error("Expected an error for negative max in-flight")
+	}
+}
@@ -0,0 +1,85 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long Close waits for in-flight requests, and
+// then for buffers to flush, when Config.ShutdownTimeout is zero
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ErrClientClosed is returned by requests made after Close was called
+var ErrClientClosed = errors.New("client is closed")
+
+// inflightKey is the context key marking requests registered with begin, so
+// the requests a composite helper makes on their behalf are still accepted
+// while Close drains it
+type inflightKey struct{}
+
+// begin registers a request or stream as in flight, failing with
+// ErrClientClosed once Close has been called unless ctx belongs to a request
+// already in flight. Every successful begin must be matched by a call to end;
+// the returned context marks requests made on behalf of this one.
+func (c *client) begin(ctx context.Context) (context.Context, error) {
+	c.lifecycle.Lock()
+	defer c.lifecycle.Unlock()
+	if c.closed && ctx.Value(inflightKey{}) != c {
+		return ctx, ErrClientClosed
+	}
+	c.inflight.Add(1)
+	return context.WithValue(ctx, inflightKey{}, c), nil
+}
+
+// end marks a request or stream registered with begin as finished
+func (c *client) end() {
+	c.inflight.Done()
+}
+
+// shutdown implements Close: it stops accepting requests, waits for those in
+// flight, flushes the usage recorder and store, and closes idle connections
+func (c *client) shutdown() error {
+	c.lifecycle.Lock()
+	c.closed = true
+	c.lifecycle.Unlock()
+
+	timeout := c.config.ShutdownTimeout
+	if timeout == 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	var errs []error
+	drained := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(drained)
+	}()
+	timer := time.NewTimer(timeout)
+	select {
+	case <-drained:
+		timer.Stop()
+	case <-timer.C:
+		errs = append(errs, fmt.Errorf("in-flight requests did not finish within %v", timeout))
+	}
+
+	// Flush even after a timeout, so records of finished requests are kept
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if flusher, ok := c.config.UsageRecorder.(Flusher); ok {
+		if err := flusher.Flush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush usage recorder: %w", err))
+		}
+	}
+	if flusher, ok := c.config.Store.(Flusher); ok {
+		if err := flusher.Flush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush store: %w", err))
+		}
+	}
+
+	if closer, ok := c.adapter.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,169 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingAdapter is a mockAdapter whose chat requests wait for release
+type blockingAdapter struct {
+	mockAdapter
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return &ChatResponse{Message: Message{Role: "assistant", Content: "done"}}, nil
+}
+
+// flushRecorder is a UsageRecorder counting flushes
+type flushRecorder struct {
+	flushes int
+}
+
+func (f *flushRecorder) RecordUsage(record UsageRecord) {}
+
+func (f *flushRecorder) Flush(ctx context.Context) error {
+	f.flushes++
+	return nil
+}
+
+func TestClose_DrainsInFlightRequests(t *testing.T) {
+	adapter := &blockingAdapter{
+		mockAdapter: mockAdapter{completeResp: &CompletionResponse{Text: "Hi"}},
+		started:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	c := newMockClient(ProviderOpenAI, adapter)
+	recorder := &flushRecorder{}
+	c.config.UsageRecorder = recorder
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+	respErr := make(chan error)
+	go func() {
+		_, err := c.ChatComplete(context.Background(), req)
+		respErr <- err
+	}()
+	<-adapter.started
+
+	closed := make(chan error)
+	go func() { closed <- c.Close() }()
+
+	// New requests are rejected while the in-flight one finishes
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, err := c.Complete(context.Background(), CompletionRequest{Prompt: "Hi"})
+		if errors.Is(err, ErrClientClosed) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected ErrClientClosed after Close, got %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-closed:
+		t.Fatalf("Expected Close to wait for the in-flight request, returned %v", err)
+	default:
+	}
+
+	close(adapter.release)
+	if err := <-respErr; err != nil {
+		t.Errorf("Expected the in-flight request to succeed, got %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Errorf("Expected no error from Close, got %v", err)
+	}
+	if recorder.flushes != 1 {
+		t.Errorf("Expected the usage recorder to be flushed once, got %d", recorder.flushes)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Expected closing again to succeed, got %v", err)
+	}
+}
+
+func TestClose_DrainsHelpers(t *testing.T) {
+	// Translate detects the language, then translates: two chat requests
+	adapter := &blockingAdapter{started: make(chan struct{}, 2), release: make(chan struct{})}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	result := make(chan error)
+	go func() {
+		_, err := c.Translate(context.Background(), "Hola", "English", TranslateOptions{})
+		result <- err
+	}()
+	<-adapter.started
+
+	closed := make(chan error)
+	go func() { closed <- c.Close() }()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := c.Limits(context.Background()); errors.Is(err, ErrClientClosed) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected ErrClientClosed from Limits after Close")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-closed:
+		t.Fatalf("Expected Close to wait for the helper, returned %v", err)
+	default:
+	}
+
+	// The helper's second request is accepted while Close drains it
+	close(adapter.release)
+	if err := <-result; err != nil {
+		t.Errorf("Expected the in-flight helper to succeed, got %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Errorf("Expected no error from Close, got %v", err)
+	}
+	if _, err := c.CountTokensRemote(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Expected ErrClientClosed from CountTokensRemote, got %v", err)
+	}
+}
+
+func TestClose_Timeout(t *testing.T) {
+	adapter := &blockingAdapter{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(adapter.release)
+	c := newMockClient(ProviderOpenAI, adapter)
+	c.config.ShutdownTimeout = 10 * time.Millisecond
+
+	go c.ChatComplete(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}})
+	<-adapter.started
+
+	if err := c.Close(); err == nil || !contains(err.Error(), "did not finish") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestClose_WaitsForStreams(t *testing.T) {
+	adapter := &streamingAdapter{streams: []*sliceStream{completeStream(), completeStream()}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.ShutdownTimeout = 10 * time.Millisecond
+
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+	finished, err := c.StreamChat(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	drain(finished)
+	open, err := c.StreamChat(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := c.Close(); err == nil {
+		t.Error("Expected Close to time out on the open stream")
+	}
+	open.Close()
+	if _, err := c.StreamChat(context.Background(), req); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Expected ErrClientClosed, got %v", err)
+	}
+}
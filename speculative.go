@@ -0,0 +1,241 @@
+package aiprovider
+
+import (
+	"context"
+	"io"
+)
+
+const (
+	// PhaseDraft is the StreamChunk.Phase of chunks of the draft answer
+	PhaseDraft = "draft"
+
+	// PhaseFinal is the StreamChunk.Phase of the final answer
+	PhaseFinal = "final"
+)
+
+// SpeculativeOptions configures StreamSpeculative.
+type SpeculativeOptions struct {
+	// DraftModel is the small, fast model that streams the draft (required
+	// unless DraftClient is set)
+	DraftModel string
+
+	// DraftClient streams the draft, e.g. a client of a faster provider
+	// (default: the client itself)
+	DraftClient Client
+
+	// OnDraftError is called when the draft fails; the stream still waits
+	// for the final answer (optional)
+	OnDraftError func(err error)
+}
+
+// draftEvent is a chunk or error read from the draft stream
+type draftEvent struct {
+	chunk StreamChunk
+	err   error
+}
+
+// finalOutcome is the result of the final request
+type finalOutcome struct {
+	resp *ChatResponse
+	err  error
+}
+
+// SpeculativeStream is a chat response streamed by StreamSpeculative: a
+// draft followed by the final answer that replaces it.
+//
+// Chunks of the draft have Phase set to PhaseDraft. The final answer arrives
+// as a single chunk with Phase set to PhaseFinal, carrying the finish reason,
+// usage and metadata; if draft text was delivered, it also has Restart set
+// and the consumer must replace the draft with it.
+//
+// A SpeculativeStream must not be used by multiple goroutines at once,
+// except for Close, which may be called concurrently to abort a pending Recv.
+type SpeculativeStream struct {
+	cancel       context.CancelFunc
+	draft        *ChatStream // Nil if the draft failed to open
+	drafts       chan draftEvent
+	final        chan finalOutcome
+	onDraftError func(err error)
+
+	draftDone bool
+	drafted   bool // Draft text was delivered
+	resp      *ChatResponse
+	err       error
+}
+
+// StreamSpeculative streams a draft answer from a small, fast model while
+// the client generates the final answer, then swaps the final answer in.
+//
+// Both requests start at once. Draft chunks are delivered as they arrive
+// until the final answer is ready, at which point the draft is stopped and
+// the final answer is delivered in one chunk. This lets latency-sensitive
+// interfaces show text immediately while still presenting the large model's
+// answer. A failing draft is reported to opts.OnDraftError and otherwise
+// ignored; only a failing final request fails the stream.
+//
+// Example:
+//
+//	stream, err := client.StreamSpeculative(ctx, ChatRequest{Messages: messages}, SpeculativeOptions{
+//		DraftModel: "claude-3-haiku-20240307",
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer stream.Close()
+//
+//	for {
+//		chunk, err := stream.Recv()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		if chunk.Restart {
+//			ui.Clear()
+//		}
+//		ui.Append(chunk.Delta, chunk.Phase == PhaseDraft)
+//	}
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout, covering both requests
+//   - req: The chat request for the final answer
+//   - opts: The draft model or client
+//
+// Returns:
+//   - *SpeculativeStream: The open stream; read it with Recv until io.EOF
+//   - error: A validation error if no draft model or client is given
+func (c *client) StreamSpeculative(ctx context.Context, req ChatRequest, opts SpeculativeOptions) (*SpeculativeStream, error) {
+	if opts.DraftModel == "" && opts.DraftClient == nil {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "speculative streaming requires a draft model or client",
+			Provider: string(c.provider),
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &SpeculativeStream{
+		cancel:       cancel,
+		drafts:       make(chan draftEvent),
+		final:        make(chan finalOutcome, 1),
+		onDraftError: opts.OnDraftError,
+	}
+
+	finalReq := req
+	finalReq.Stream = false
+	go func() {
+		resp, err := c.ChatComplete(ctx, finalReq)
+		s.final <- finalOutcome{resp: resp, err: err}
+	}()
+
+	draftReq := req
+	if opts.DraftModel != "" {
+		draftReq.Model = opts.DraftModel
+	}
+	var draftClient Client = c
+	if opts.DraftClient != nil {
+		draftClient = opts.DraftClient
+	}
+	draft, err := draftClient.StreamChat(ctx, draftReq)
+	if err != nil {
+		s.draftDone = true
+		s.reportDraftError(err)
+		return s, nil
+	}
+	s.draft = draft
+	go s.readDraft(ctx)
+	return s, nil
+}
+
+// Recv returns the next chunk, or io.EOF after the final answer.
+//
+// After any error, further calls return the same error.
+func (s *SpeculativeStream) Recv() (StreamChunk, error) {
+	if s.err != nil {
+		return StreamChunk{}, s.err
+	}
+
+	for {
+		// A nil channel disables the draft case once the draft has ended
+		var drafts chan draftEvent
+		if !s.draftDone {
+			drafts = s.drafts
+		}
+
+		select {
+		case outcome := <-s.final:
+			s.Close()
+			if outcome.err != nil {
+				s.err = outcome.err
+				return StreamChunk{}, s.err
+			}
+			s.resp = outcome.resp
+			s.err = io.EOF
+			usage, metadata := outcome.resp.Usage, outcome.resp.Metadata
+			return StreamChunk{
+				Delta:               outcome.resp.Message.Content,
+				FinishReason:        outcome.resp.FinishReason,
+				MatchedStopSequence: outcome.resp.MatchedStopSequence,
+				Usage:               &usage,
+				Metadata:            &metadata,
+				Restart:             s.drafted,
+				Phase:               PhaseFinal,
+			}, nil
+
+		case event := <-drafts:
+			if event.err != nil {
+				s.draftDone = true
+				if event.err != io.EOF {
+					s.reportDraftError(event.err)
+				}
+				continue
+			}
+			// The draft's finish reason, usage and metadata describe the
+			// draft model, not the answer, so only its text is delivered
+			if event.chunk.Delta == "" && !event.chunk.Restart {
+				continue
+			}
+			s.drafted = true
+			return StreamChunk{Delta: event.chunk.Delta, Restart: event.chunk.Restart, Phase: PhaseDraft}, nil
+		}
+	}
+}
+
+// Response returns the final response, or nil until Recv has delivered it
+func (s *SpeculativeStream) Response() *ChatResponse {
+	return s.resp
+}
+
+// Close stops both requests. It is safe to call more than once and from
+// another goroutine to abort a pending Recv.
+func (s *SpeculativeStream) Close() error {
+	s.cancel()
+	if s.draft != nil {
+		return s.draft.Close()
+	}
+	return nil
+}
+
+// readDraft forwards the draft's chunks to Recv until the draft ends or the
+// stream is closed
+func (s *SpeculativeStream) readDraft(ctx context.Context) {
+	for {
+		chunk, err := s.draft.Recv()
+		select {
+		case s.drafts <- draftEvent{chunk: chunk, err: err}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// reportDraftError passes a draft failure to the OnDraftError hook
+func (s *SpeculativeStream) reportDraftError(err error) {
+	if s.onDraftError != nil {
+		s.onDraftError(err)
+	}
+}
@@ -0,0 +1,122 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// slowFinalAdapter is a streamingAdapter whose ChatComplete waits for release
+type slowFinalAdapter struct {
+	streamingAdapter
+	release chan struct{}
+}
+
+func (s *slowFinalAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return s.streamingAdapter.ChatComplete(ctx, req)
+}
+
+func TestStreamSpeculative(t *testing.T) {
+	adapter := &slowFinalAdapter{
+		streamingAdapter: streamingAdapter{
+			mockAdapter: mockAdapter{chatResp: &ChatResponse{
+				Message:      Message{Role: "assistant", Content: "Hello, Ada."},
+				Usage:        Usage{PromptTokens: 5, CompletionTokens: 4, TotalTokens: 9},
+				FinishReason: "end_turn",
+			}},
+			streams: []*sliceStream{completeStream()},
+		},
+		release: make(chan struct{}),
+	}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	stream, err := c.StreamSpeculative(context.Background(), ChatRequest{
+		Model:    "claude-3-opus-20240229",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	}, SpeculativeOptions{DraftModel: "claude-3-haiku-20240307"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var draft string
+	for draft != "Hello world" {
+		chunk, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if chunk.Phase != PhaseDraft || chunk.Usage != nil {
+			t.Errorf("Expected a draft chunk with text only, got %+v", chunk)
+		}
+		draft += chunk.Delta
+	}
+	if model := adapter.streamRequests[0].Model; model != "claude-3-haiku-20240307" {
+		t.Errorf("Expected the draft from the draft model, got %q", model)
+	}
+
+	close(adapter.release)
+	final, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if final.Phase != PhaseFinal || !final.Restart || final.Delta != "Hello, Ada." || final.Usage.TotalTokens != 9 {
+		t.Errorf("Expected the final answer to replace the draft, got %+v", final)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Expected io.EOF after the final answer, got %v", err)
+	}
+	if resp := stream.Response(); resp == nil || resp.Message.Content != "Hello, Ada." {
+		t.Errorf("Expected the final response, got %+v", resp)
+	}
+	if model := adapter.chatRequests[0].Model; model != "claude-3-opus-20240229" {
+		t.Errorf("Expected the final answer from the request model, got %q", model)
+	}
+}
+
+func TestStreamSpeculative_Failures(t *testing.T) {
+	// A failing draft is reported, which releases the final answer
+	slow := &slowFinalAdapter{
+		streamingAdapter: streamingAdapter{
+			mockAdapter: mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Final"}}},
+			streams:     []*sliceStream{{err: errors.New("draft overloaded")}},
+		},
+		release: make(chan struct{}),
+	}
+	c := newMockClient(ProviderAnthropic, slow)
+	stream, err := c.StreamSpeculative(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}, SpeculativeOptions{
+		DraftModel:   "claude-3-haiku-20240307",
+		OnDraftError: func(err error) { close(slow.release) },
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	chunk, err := stream.Recv()
+	if err != nil || chunk.Delta != "Final" || chunk.Restart {
+		t.Errorf("Expected the final answer without a restart, got %+v, %v", chunk, err)
+	}
+	stream.Close()
+
+	// A failing final request fails the stream
+	adapter := &streamingAdapter{mockAdapter: mockAdapter{err: errors.New("overloaded")}, streams: []*sliceStream{completeStream()}}
+	c = newMockClient(ProviderAnthropic, adapter)
+	stream, _ = c.StreamSpeculative(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}}, SpeculativeOptions{DraftModel: "claude-3-haiku-20240307"})
+	defer stream.Close()
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		t.Error("Expected the final request's error")
+	}
+
+	if _, err := c.StreamSpeculative(context.Background(), ChatRequest{}, SpeculativeOptions{}); err == nil {
+		t.Error("Expected a validation error without a draft model")
+	}
+}
@@ -0,0 +1,85 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Speech converts text to audio.
+//
+// The audio is streamed from the provider as it is read, so long outputs are
+// not held in memory. The caller must close the returned BinaryResponse to
+// release the connection. Providers that cannot generate speech fail with a
+// validation error before any request is sent.
+//
+// Example:
+//
+//	audio, err := client.Speech(ctx, SpeechRequest{Input: "Hello!", Format: "mp3"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer audio.Close()
+//	io.Copy(file, audio)
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - req: The text and optional model, voice, format and speed
+//
+// Returns:
+//   - *BinaryResponse: The audio stream with its content type and length
+//   - error: A validation error if the input is empty or speech is unsupported, or a provider error
+func (c *client) Speech(ctx context.Context, req SpeechRequest) (*BinaryResponse, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	if err := c.requireFeatures(FeatureSpeech); err != nil {
+		return nil, err
+	}
+	speaker, ok := c.adapter.(SpeechAdapter)
+	if !ok {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("feature %q not supported by provider %s", FeatureSpeech, c.provider),
+			Provider: string(c.provider),
+		}
+	}
+
+	if strings.TrimSpace(req.Input) == "" {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "speech input cannot be empty",
+			Provider: string(c.provider),
+		}
+	}
+	if req.Speed != nil && *req.Speed <= 0 {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("speech speed must be positive, got: %g", *req.Speed),
+			Provider: string(c.provider),
+		}
+	}
+
+	ctx, err = c.withProject(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkEndpoint(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.checkBudget(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.waitRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	audio, err := speaker.Speech(ctx, req)
+	if err != nil {
+		return nil, c.sanitizeError(err, []string{req.Input})
+	}
+	return audio, nil
+}
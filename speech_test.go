@@ -0,0 +1,69 @@
+package aiprovider
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// speechAdapter returns the input text as audio
+type speechAdapter struct {
+	mockAdapter
+	requests []SpeechRequest
+}
+
+func (s *speechAdapter) Speech(ctx context.Context, req SpeechRequest) (*BinaryResponse, error) {
+	s.requests = append(s.requests, req)
+	return &BinaryResponse{
+		Body:          io.NopCloser(strings.NewReader(req.Input)),
+		ContentType:   "audio/mpeg",
+		ContentLength: int64(len(req.Input)),
+	}, nil
+}
+
+func (s *speechAdapter) SupportedFeatures() []string {
+	return append(s.mockAdapter.SupportedFeatures(), FeatureSpeech)
+}
+
+func TestSpeech(t *testing.T) {
+	adapter := &speechAdapter{}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	audio, err := c.Speech(context.Background(), SpeechRequest{Input: "Hello", Voice: "nova"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer audio.Close()
+
+	data, _ := io.ReadAll(audio)
+	if string(data) != "Hello" || audio.ContentType != "audio/mpeg" || audio.ContentLength != 5 {
+		t.Errorf("Unexpected audio %q (%s, %d bytes)", data, audio.ContentType, audio.ContentLength)
+	}
+	if len(adapter.requests) != 1 || adapter.requests[0].Voice != "nova" {
+		t.Errorf("Expected the request to reach the adapter, got %+v", adapter.requests)
+	}
+}
+
+func TestSpeech_Validation(t *testing.T) {
+	negative := -1.0
+	tests := []struct {
+		name    string
+		adapter ProviderAdapter
+		req     SpeechRequest
+	}{
+		{"unsupported provider", &mockAdapter{}, SpeechRequest{Input: "Hello"}},
+		{"empty input", &speechAdapter{}, SpeechRequest{Input: "  "}},
+		{"negative speed", &speechAdapter{}, SpeechRequest{Input: "Hello", Speed: &negative}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newMockClient(ProviderOpenAI, tt.adapter)
+			_, err := c.Speech(context.Background(), tt.req)
+			if aiErr, ok := err.(*Error); !ok || aiErr.Type != ErrorTypeValidation {
+				t.Errorf("Expected a validation error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package store
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// encryptedPrefix marks a field value encrypted by EncryptingStore. The
+// version allows the format to change without breaking stored records.
+const encryptedPrefix = "enc:v2:"
+
+// legacyPrefix marks fields encrypted before the field name was bound to
+// the ciphertext, which Decrypt still reads
+const legacyPrefix = "enc:v1:"
+
+// dataKeySize is the size of the AES-256 data keys encrypting record fields
+const dataKeySize = 32
+
+// KeyProvider issues and unwraps the data keys of envelope encryption.
+//
+// Each record is encrypted with a fresh data key, which is stored alongside
+// it wrapped by a key encryption key that never leaves the provider. A KMS
+// maps onto it directly: GenerateDataKey and Decrypt in AWS KMS, or Encrypt
+// and Decrypt in Google Cloud KMS and Vault's transit engine. StaticKey uses
+// a key held by the application. Implementations must be safe for concurrent
+// use.
+type KeyProvider interface {
+	// GenerateDataKey returns a new 32-byte data key, in plaintext and
+	// wrapped for storage
+	GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error)
+
+	// DecryptDataKey unwraps a data key returned by GenerateDataKey
+	DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// StaticKey is a KeyProvider wrapping data keys with an application-supplied
+// AES-256 key, for deployments without a KMS.
+type StaticKey struct {
+	aead cipher.AEAD
+}
+
+// NewStaticKey creates a KeyProvider from a 32-byte key encryption key
+func NewStaticKey(key []byte) (*StaticKey, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("key encryption key must be %d bytes, got %d", dataKeySize, len(key))
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticKey{aead: aead}, nil
+}
+
+// GenerateDataKey returns a random data key wrapped with the static key,
+// implementing KeyProvider
+func (s *StaticKey) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := seal(s.aead, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, wrapped, nil
+}
+
+// DecryptDataKey unwraps a data key with the static key, implementing
+// KeyProvider
+func (s *StaticKey) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	key, err := open(s.aead, wrapped, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return key, nil
+}
+
+// EncryptingStore encrypts the prompt and response bodies of records before
+// passing them to another Store.
+//
+// Encryption is field-level: the prompt, the response, the content and
+// reasoning of each message and the excerpts of injection findings are
+// encrypted separately, while the provider, model, roles, usage, cost,
+// latency, timestamp and other metadata stay in the clear, so stored records
+// remain queryable by everything but their text. Encrypted fields are
+// strings of the form "enc:v2:<field>:<wrapped key>:<ciphertext>", with the
+// field's name, such as "messages.2.content", authenticated as additional
+// data so ciphertexts cannot be moved between fields unnoticed; read them
+// back with Decrypt or DecryptRecord.
+//
+// EncryptingStore is safe for concurrent use if its Store and KeyProvider are.
+type EncryptingStore struct {
+	next Store
+	keys KeyProvider
+}
+
+// NewEncryptingStore creates a store encrypting records with data keys from
+// keys before saving them to next.
+//
+// Example:
+//
+//	keys, err := store.NewStaticKey(masterKey)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	config.Store = store.NewEncryptingStore(interactions, keys)
+func NewEncryptingStore(next Store, keys KeyProvider) *EncryptingStore {
+	return &EncryptingStore{next: next, keys: keys}
+}
+
+// SaveInteraction encrypts a record's bodies and saves it, implementing
+// types.InteractionStore
+func (s *EncryptingStore) SaveInteraction(ctx context.Context, record Record) error {
+	key, wrapped, err := s.keys.GenerateDataKey(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	wrapped64 := base64.StdEncoding.EncodeToString(wrapped)
+
+	encrypt := func(field, text string) (string, error) {
+		if text == "" {
+			return "", nil
+		}
+		sealed, err := seal(aead, []byte(text), []byte(field))
+		if err != nil {
+			return "", err
+		}
+		return encryptedPrefix + field + ":" + wrapped64 + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+	}
+
+	if record.Prompt, err = encrypt("prompt", record.Prompt); err != nil {
+		return err
+	}
+	if record.Response, err = encrypt("response", record.Response); err != nil {
+		return err
+	}
+	if record.Messages != nil {
+		messages := make([]types.Message, len(record.Messages))
+		for i, msg := range record.Messages {
+			if msg.Content, err = encrypt(messageField(i, "content"), msg.Content); err != nil {
+				return err
+			}
+			if msg.Reasoning, err = encrypt(messageField(i, "reasoning"), msg.Reasoning); err != nil {
+				return err
+			}
+			messages[i] = msg
+		}
+		record.Messages = messages
+	}
+	if record.Metadata.InjectionFindings != nil {
+		findings := make([]types.InjectionFinding, len(record.Metadata.InjectionFindings))
+		for i, finding := range record.Metadata.InjectionFindings {
+			if finding.Excerpt, err = encrypt(findingField(i), finding.Excerpt); err != nil {
+				return err
+			}
+			findings[i] = finding
+		}
+		record.Metadata.InjectionFindings = findings
+	}
+	return s.next.SaveInteraction(ctx, record)
+}
+
+// messageField names the field of a record's message for encryption
+func messageField(i int, name string) string {
+	return fmt.Sprintf("messages.%d.%s", i, name)
+}
+
+// findingField names the excerpt of a record's injection finding for
+// encryption
+func findingField(i int) string {
+	return fmt.Sprintf("injection_findings.%d.excerpt", i)
+}
+
+// Decrypt returns the plaintext of a field encrypted by EncryptingStore.
+// Values that are not encrypted, such as those stored before encryption was
+// enabled, are returned unchanged. Use DecryptRecord to also check that each
+// field was encrypted for the place it is stored in.
+func Decrypt(ctx context.Context, keys KeyProvider, value string) (string, error) {
+	return decryptField(ctx, keys, "", value)
+}
+
+// decryptField decrypts value, which must have been encrypted as field
+// unless field is empty
+func decryptField(ctx context.Context, keys KeyProvider, field, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, encryptedPrefix):
+		name, rest, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+		if !ok {
+			return "", errors.New("malformed encrypted field")
+		}
+		if field != "" && name != field {
+			return "", fmt.Errorf("encrypted field %s stored as %s", name, field)
+		}
+		return decryptSealed(ctx, keys, rest, []byte(name))
+	case strings.HasPrefix(value, legacyPrefix):
+		return decryptSealed(ctx, keys, strings.TrimPrefix(value, legacyPrefix), nil)
+	}
+	return value, nil
+}
+
+// decryptSealed decrypts "<wrapped key>:<ciphertext>" with additional data
+func decryptSealed(ctx context.Context, keys KeyProvider, value string, additionalData []byte) (string, error) {
+	wrapped64, sealed64, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", errors.New("malformed encrypted field")
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(wrapped64)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted field: %w", err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(sealed64)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted field: %w", err)
+	}
+
+	key, err := keys.DecryptDataKey(ctx, wrapped)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, sealed, additionalData)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt field: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// DecryptRecord returns a copy of a record with the fields encrypted by
+// EncryptingStore decrypted
+func DecryptRecord(ctx context.Context, keys KeyProvider, record Record) (Record, error) {
+	var err error
+	if record.Prompt, err = decryptField(ctx, keys, "prompt", record.Prompt); err != nil {
+		return Record{}, err
+	}
+	if record.Response, err = decryptField(ctx, keys, "response", record.Response); err != nil {
+		return Record{}, err
+	}
+	if record.Messages != nil {
+		messages := make([]types.Message, len(record.Messages))
+		for i, msg := range record.Messages {
+			if msg.Content, err = decryptField(ctx, keys, messageField(i, "content"), msg.Content); err != nil {
+				return Record{}, err
+			}
+			if msg.Reasoning, err = decryptField(ctx, keys, messageField(i, "reasoning"), msg.Reasoning); err != nil {
+				return Record{}, err
+			}
+			messages[i] = msg
+		}
+		record.Messages = messages
+	}
+	if record.Metadata.InjectionFindings != nil {
+		findings := make([]types.InjectionFinding, len(record.Metadata.InjectionFindings))
+		for i, finding := range record.Metadata.InjectionFindings {
+			if finding.Excerpt, err = decryptField(ctx, keys, findingField(i), finding.Excerpt); err != nil {
+				return Record{}, err
+			}
+			findings[i] = finding
+		}
+		record.Metadata.InjectionFindings = findings
+	}
+	return record, nil
+}
+
+// newAEAD creates an AES-GCM cipher from a 32-byte key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("data key must be %d bytes, got %d", dataKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext and authenticates additionalData under a random
+// nonce, which it prepends
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts the output of seal
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultTable is the table SQLiteStore writes to
+const DefaultTable = "interactions"
+
+// createTableSQL creates the interactions table; %s is the table name
+const createTableSQL = `CREATE TABLE IF NOT EXISTS %s (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	created_at TEXT NOT NULL,
+	provider TEXT NOT NULL,
+	model TEXT,
+	prompt TEXT,
+	messages TEXT,
+	response TEXT,
+	finish_reason TEXT,
+	prompt_tokens INTEGER,
+	completion_tokens INTEGER,
+	total_tokens INTEGER,
+	cost_usd REAL,
+	latency_ms INTEGER,
+	metadata TEXT
+)`
+
+// insertSQL inserts one interaction; %s is the table name
+const insertSQL = `INSERT INTO %s (
+	created_at, provider, model, prompt, messages, response, finish_reason,
+	prompt_tokens, completion_tokens, total_tokens, cost_usd, latency_ms, metadata
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// SQLiteStore saves interactions to a SQLite database.
+//
+// Chat messages and response metadata are stored as JSON text columns so the
+// table can be queried with SQLite's JSON functions. SQLiteStore is safe for
+// concurrent use.
+type SQLiteStore struct {
+	db     *sql.DB
+	insert string
+}
+
+// NewSQLiteStore creates the interactions table if needed and returns a store
+// writing to it. The caller owns db and must register a SQLite driver.
+func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(createTableSQL, DefaultTable)); err != nil {
+		return nil, fmt.Errorf("failed to create %s table: %w", DefaultTable, err)
+	}
+
+	return &SQLiteStore{
+		db:     db,
+		insert: fmt.Sprintf(insertSQL, DefaultTable),
+	}, nil
+}
+
+// SaveInteraction inserts a record into the interactions table
+func (s *SQLiteStore) SaveInteraction(ctx context.Context, record Record) error {
+	var messages interface{}
+	if len(record.Messages) > 0 {
+		encoded, err := json.Marshal(record.Messages)
+		if err != nil {
+			return fmt.Errorf("failed to encode messages: %w", err)
+		}
+		messages = string(encoded)
+	}
+
+	metadata, err := json.Marshal(record.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	timestamp := record.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	_, err = s.db.ExecContext(ctx, s.insert,
+		timestamp.UTC().Format(time.RFC3339Nano),
+		string(record.Provider),
+		record.Model,
+		record.Prompt,
+		messages,
+		record.Response,
+		record.FinishReason,
+		record.Usage.PromptTokens,
+		record.Usage.CompletionTokens,
+		record.Usage.TotalTokens,
+		record.Cost,
+		record.Latency.Milliseconds(),
+		string(metadata),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save interaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Package store persists prompts, responses, usage and latency.
+//
+// Set Config.Store to an InteractionStore and the client saves every
+// successful request. SQLiteStore is a reference implementation on top of
+// database/sql; it works with any SQLite driver registered by the
+// application, so this module does not depend on one. EncryptingStore wraps
+// any Store to encrypt prompt and response bodies at rest.
+//
+// Example:
+//
+//	import _ "modernc.org/sqlite"
+//
+//	db, err := sql.Open("sqlite", "interactions.db")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	interactions, err := store.NewSQLiteStore(ctx, db)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	config := aiprovider.DefaultConfig().WithAPIKey(key)
+//	config.Store = interactions
+//	config.OnStoreError = func(err error) { log.Printf("store: %v", err) }
+package store
+
+import "github.com/ajeet-kumar1087/ai-providers/types"
+
+// Store persists interactions. It is an alias of types.InteractionStore.
+type Store = types.InteractionStore
+
+// Record is a persisted prompt/response pair. It is an alias of types.InteractionRecord.
+type Record = types.InteractionRecord
@@ -0,0 +1,248 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// recordingDriver is a minimal database/sql driver that records executed
+// statements, standing in for a real SQLite driver
+type recordingDriver struct {
+	mu    sync.Mutex
+	execs []recordedExec
+	err   error
+}
+
+type recordedExec struct {
+	query string
+	args  []driver.Value
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return nil, d.err
+	}
+	d.execs = append(d.execs, recordedExec{query: s.query, args: args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, io.EOF
+}
+
+var testDriver = &recordingDriver{}
+
+func init() {
+	sql.Register("store-recording", testDriver)
+}
+
+func TestSQLiteStore(t *testing.T) {
+	db, err := sql.Open("store-recording", "")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	store, err := NewSQLiteStore(ctx, db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	err = store.SaveInteraction(ctx, Record{
+		Provider:     types.ProviderAnthropic,
+		Model:        "claude-3-haiku-20240307",
+		Messages:     []types.Message{{Role: "user", Content: "Hi"}},
+		Response:     "Hello!",
+		FinishReason: "end_turn",
+		Usage:        types.Usage{PromptTokens: 8, CompletionTokens: 3, TotalTokens: 11},
+		Cost:         0.00001,
+		Latency:      250 * time.Millisecond,
+		Timestamp:    timestamp,
+		Metadata:     types.ResponseMetadata{ResponseID: "msg_1"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(testDriver.execs) != 2 {
+		t.Fatalf("Expected create and insert statements, got %d", len(testDriver.execs))
+	}
+	if !strings.HasPrefix(testDriver.execs[0].query, "CREATE TABLE IF NOT EXISTS interactions") {
+		t.Errorf("Expected create table statement, got %q", testDriver.execs[0].query)
+	}
+
+	args := testDriver.execs[1].args
+	if len(args) != 13 {
+		t.Fatalf("Expected 13 insert arguments, got %d", len(args))
+	}
+	if args[0] != "2024-05-01T12:00:00Z" || args[1] != "anthropic" || args[2] != "claude-3-haiku-20240307" {
+		t.Errorf("Unexpected timestamp, provider or model: %v", args[:3])
+	}
+	if args[3] != "" {
+		t.Errorf("Expected empty prompt for chat record, got %v", args[3])
+	}
+
+	var messages []types.Message
+	if err := json.Unmarshal([]byte(args[4].(string)), &messages); err != nil || len(messages) != 1 {
+		t.Errorf("Expected JSON encoded messages, got %v", args[4])
+	}
+	if args[5] != "Hello!" || args[9] != int64(11) || args[11] != int64(250) {
+		t.Errorf("Unexpected response, total tokens or latency: %v", args)
+	}
+	if !strings.Contains(args[12].(string), `"response_id":"msg_1"`) {
+		t.Errorf("Expected JSON encoded metadata, got %v", args[12])
+	}
+
+	testDriver.err = errors.New("disk full")
+	defer func() { testDriver.err = nil }()
+	if err := store.SaveInteraction(ctx, Record{Provider: types.ProviderOpenAI}); err == nil {
+		t.Error("Expected database error to be returned")
+	}
+}
+
+func TestNewSQLiteStoreRequiresDB(t *testing.T) {
+	if _, err := NewSQLiteStore(context.Background(), nil); err == nil {
+		t.Error("Expected error for nil database")
+	}
+}
+
+// memoryStore keeps saved records in a slice
+type memoryStore struct {
+	records []Record
+}
+
+func (m *memoryStore) SaveInteraction(ctx context.Context, record Record) error {
+	m.records = append(m.records, record)
+	return nil
+}
+
+func TestEncryptingStore(t *testing.T) {
+	keys, err := NewStaticKey([]byte(strings.Repeat("k", 32)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	next := &memoryStore{}
+	s := NewEncryptingStore(next, keys)
+	ctx := context.Background()
+
+	record := Record{
+		Provider: types.ProviderOpenAI,
+		Model:    "gpt-4o",
+		Messages: []types.Message{{Role: "user", Content: "My SSN is 078-05-1120"}, {Role: "assistant", Content: "Noted", Reasoning: "The user shared an SSN"}},
+		Response: "I will not repeat it",
+		Usage:    types.Usage{TotalTokens: 20},
+		Metadata: types.ResponseMetadata{ResponseID: "resp_1", InjectionFindings: []types.InjectionFinding{{MessageIndex: 0, Reasons: []string{"test"}, Excerpt: "My SSN is"}}},
+	}
+	if err := s.SaveInteraction(ctx, record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if record.Messages[0].Content != "My SSN is 078-05-1120" {
+		t.Error("Expected the caller's record not to be modified")
+	}
+
+	saved := next.records[0]
+	if record.Metadata.InjectionFindings[0].Excerpt != "My SSN is" {
+		t.Error("Expected the caller's findings not to be modified")
+	}
+	for _, field := range []string{saved.Messages[0].Content, saved.Messages[1].Content, saved.Messages[1].Reasoning, saved.Response, saved.Metadata.InjectionFindings[0].Excerpt} {
+		if !strings.HasPrefix(field, encryptedPrefix) || strings.Contains(field, "SSN") {
+			t.Errorf("Expected an encrypted field, got %q", field)
+		}
+	}
+	if saved.Prompt != "" {
+		t.Errorf("Expected an empty prompt to stay empty, got %q", saved.Prompt)
+	}
+	if saved.Model != "gpt-4o" || saved.Messages[0].Role != "user" || saved.Usage.TotalTokens != 20 || saved.Metadata.ResponseID != "resp_1" {
+		t.Errorf("Expected metadata to stay in the clear, got %+v", saved)
+	}
+
+	decrypted, err := DecryptRecord(ctx, keys, saved)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decrypted.Messages[0].Content != record.Messages[0].Content || decrypted.Messages[1].Reasoning != record.Messages[1].Reasoning || decrypted.Response != record.Response {
+		t.Errorf("Expected the original bodies, got %+v", decrypted)
+	}
+	if decrypted.Metadata.InjectionFindings[0].Excerpt != "My SSN is" {
+		t.Errorf("Expected the original excerpt, got %+v", decrypted.Metadata.InjectionFindings)
+	}
+
+	// Fields moved to another place fail to decrypt with the record, even
+	// if the name they were encrypted under is rewritten
+	swapped := saved
+	swapped.Messages = []types.Message{{Role: "user", Content: saved.Messages[1].Content}, saved.Messages[1]}
+	if _, err := DecryptRecord(ctx, keys, swapped); err == nil {
+		t.Error("Expected an error for a field moved between messages")
+	}
+	swapped.Messages[0].Content = strings.Replace(saved.Messages[1].Content, "messages.1.", "messages.0.", 1)
+	if _, err := DecryptRecord(ctx, keys, swapped); err == nil {
+		t.Error("Expected an error for a field renamed to its new place")
+	}
+
+	// Fields encrypted before field names were bound still decrypt
+	key, wrapped, _ := keys.GenerateDataKey(ctx)
+	aead, _ := newAEAD(key)
+	sealed, _ := seal(aead, []byte("legacy"), nil)
+	legacy := legacyPrefix + base64.StdEncoding.EncodeToString(wrapped) + ":" + base64.StdEncoding.EncodeToString(sealed)
+	if text, err := Decrypt(ctx, keys, legacy); err != nil || text != "legacy" {
+		t.Errorf("Expected the legacy field decrypted, got %q, %v", text, err)
+	}
+
+	// Plaintext passes through; other keys and tampering fail
+	if text, err := Decrypt(ctx, keys, "stored before encryption"); err != nil || text != "stored before encryption" {
+		t.Errorf("Expected plaintext unchanged, got %q, %v", text, err)
+	}
+	other, _ := NewStaticKey([]byte(strings.Repeat("o", 32)))
+	if _, err := Decrypt(ctx, other, saved.Response); err == nil {
+		t.Error("Expected an error decrypting with another key")
+	}
+	tampered := saved.Response[:len(saved.Response)-4] + "AAAA"
+	if _, err := Decrypt(ctx, keys, tampered); err == nil {
+		t.Error("Expected an error for a tampered field")
+	}
+	if _, err := NewStaticKey([]byte("short")); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+}
@@ -0,0 +1,471 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	httputil "github.com/ajeet-kumar1087/ai-providers/internal/http"
+)
+
+// ErrStreamStalled is wrapped by the network error returned from
+// ChatStream.Recv when no data arrives within Config.StreamIdleTimeout.
+var ErrStreamStalled = httputil.ErrStreamStalled
+
+// ChatStream is a streamed chat response returned by Client.StreamChat.
+//
+// Call Recv until it returns io.EOF, then Response for the aggregated
+// response. Streams that stall are aborted with a retryable ErrorTypeNetwork
+// error wrapping ErrStreamStalled; if no content had been received yet, the
+// stream is first reopened up to Config.StreamStallRetries times.
+//
+// A ChatStream must not be used by multiple goroutines at once, except for
+// Close, which may be called concurrently to abort a pending Recv, and Stats.
+//
+// With Config.StreamBufferSize set, chunks are read ahead of Recv into a
+// bounded buffer. A consumer slower than the provider fills the buffer, after
+// which reading from the provider pauses until Recv catches up, so memory
+// stays bounded; chunks are never dropped unless the stream is closed early.
+type ChatStream struct {
+	client      *client
+	ctx         context.Context
+	req         ChatRequest
+	findings    []InjectionFinding
+	experiments map[string]string
+	prompt      *PromptRef
+	warnings    []Warning
+	open        func() (StreamReader, error)
+	start       time.Time
+	retriesLeft int
+	leave       func()      // Removes the stream from its cancellation group
+	stopClose   func() bool // Stops closing the stream on group cancellation, or nil
+
+	mu     sync.Mutex // Guards reader and closed for Close
+	reader StreamReader
+	closed bool
+
+	released sync.Once // Ends the stream's in-flight registration once
+
+	counters streamCounters
+
+	received     bool
+	limited      bool      // Whether the content reached MaxWords or MaxChars
+	firstToken   time.Time // When the first content arrived
+	lastToken    time.Time // When the latest content arrived
+	content      strings.Builder
+	reasoning    strings.Builder
+	finishReason string
+	stopSequence *string
+	usage        Usage
+	metadata     ResponseMetadata
+	mismatches   []UsageMismatch
+	err          error
+}
+
+// StreamChat sends a chat completion request and streams the response.
+//
+// The request is validated and normalized like ChatComplete. Chunks are
+// returned as the provider generates them; Response aggregates them once the
+// stream is complete, at which point usage is recorded and the interaction is
+// stored like a ChatComplete call. Content past the request's MaxWords or
+// MaxChars is not returned: the chunk reaching the limit is cut, and later
+// chunks carry only usage and a "length" finish reason.
+//
+// Example:
+//
+//	stream, err := client.StreamChat(ctx, ChatRequest{Messages: messages})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer stream.Close()
+//
+//	for {
+//		chunk, err := stream.Recv()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		fmt.Print(chunk.Delta)
+//	}
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout, covering the whole stream
+//   - req: The chat request with messages and optional parameters
+//
+// Returns:
+//   - *ChatStream: The open stream
+//   - error: A validation error if streaming is unsupported or the request is invalid, or the provider error
+func (c *client) StreamChat(ctx context.Context, req ChatRequest) (*ChatStream, error) {
+	// The stream stays in flight until it ends or is closed
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grouped := CancelGroupFromContext(ctx) != ""
+	ctx, leave := c.joinGroup(ctx)
+	opened := false
+	defer func() {
+		if !opened {
+			leave()
+			c.end()
+		}
+	}()
+
+	req, experiments := c.applyChatExperiments(ctx, req)
+	req, promptRef, err := c.applyChatPrompt(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	normalizedReq, warnings, err := c.validateAndNormalizeChatRequest(req)
+	if err != nil {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("request validation failed: %v", err),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	}
+	if err := c.requireFeatures(append(chatFeatures(normalizedReq), FeatureStreaming)...); err != nil {
+		return nil, err
+	}
+	streamer, ok := c.adapter.(StreamingAdapter)
+	if !ok {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("feature %q not supported by provider %s", FeatureStreaming, c.provider),
+			Provider: string(c.provider),
+		}
+	}
+	c.reportWarnings(warnings)
+	ctx, err = c.withProject(ctx, normalizedReq.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkEndpoint(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.checkBudget(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.waitRateLimit(ctx); err != nil {
+		return nil, groupError(ctx, err)
+	}
+	normalizedReq, findings := c.applyInjectionGuard(normalizedReq)
+
+	stream := &ChatStream{
+		client:      c,
+		ctx:         ctx,
+		req:         normalizedReq,
+		findings:    findings,
+		experiments: experiments,
+		prompt:      promptRef,
+		warnings:    warnings,
+		start:       time.Now(),
+		retriesLeft: c.config.StreamStallRetries,
+		leave:       leave,
+	}
+	stream.open = func() (StreamReader, error) {
+		reader, err := streamer.StreamChat(ctx, normalizedReq)
+		if err != nil || c.config.StreamBufferSize == 0 {
+			return reader, err
+		}
+		return newBufferedReader(reader, c.config.StreamBufferSize, &stream.counters), nil
+	}
+
+	reader, err := stream.open()
+	if err != nil {
+		c.noteQuotaError(normalizedReq.Model, err)
+		return nil, groupError(ctx, c.sanitizeError(err, chatTexts(normalizedReq)))
+	}
+	stream.reader = reader
+	opened = true
+
+	// Readers need not watch the context while blocked, so streams ended by
+	// CancelGroup are closed to abort a pending Recv. The lock makes Close,
+	// and so release, see stopClose if the group is cancelled at once.
+	if grouped {
+		stream.mu.Lock()
+		stream.stopClose = context.AfterFunc(ctx, func() {
+			if errors.Is(context.Cause(ctx), ErrGroupCancelled) {
+				stream.Close()
+			}
+		})
+		stream.mu.Unlock()
+	}
+	return stream, nil
+}
+
+// Recv returns the next chunk of the response.
+//
+// It returns io.EOF once the stream is complete. After any error, further
+// calls return the same error.
+func (s *ChatStream) Recv() (StreamChunk, error) {
+	if s.err != nil {
+		return StreamChunk{}, s.err
+	}
+
+	for {
+		chunk, err := s.reader.Recv()
+		if err == nil {
+			if s.limited {
+				// Output past MaxWords or MaxChars is dropped, but the
+				// stream is read to the end for its usage
+				chunk.Delta, chunk.ReasoningDelta, chunk.Restart = "", "", false
+				if chunk.FinishReason != "" {
+					chunk.FinishReason, chunk.MatchedStopSequence = "length", nil
+				}
+			}
+			s.accumulate(chunk)
+			s.limitLength(&chunk)
+			if s.limited && chunk == (StreamChunk{}) {
+				continue
+			}
+			if s.client.config.RedactReasoning && chunk.ReasoningDelta != "" {
+				// Reasoning is counted in usage but never returned
+				chunk.ReasoningDelta = ""
+				if chunk == (StreamChunk{}) {
+					continue
+				}
+			}
+			s.counters.update(func(stats *StreamStats) { stats.Received++ })
+			return chunk, nil
+		}
+
+		if err == io.EOF {
+			s.err = io.EOF
+			s.finish()
+			s.release()
+			return StreamChunk{}, io.EOF
+		}
+
+		if errors.Is(err, ErrStreamStalled) && !s.received && s.retriesLeft > 0 && s.ctx.Err() == nil {
+			s.retriesLeft--
+			if reopenErr := s.reopen(); reopenErr != nil {
+				s.err = reopenErr
+				s.release()
+				return StreamChunk{}, reopenErr
+			}
+			continue
+		}
+
+		s.err = s.wrapError(err)
+		s.release()
+		return StreamChunk{}, s.err
+	}
+}
+
+// Response returns the response aggregated from the chunks received so far.
+// It is complete once Recv has returned io.EOF.
+func (s *ChatStream) Response() *ChatResponse {
+	metadata := s.metadata
+	metadata.InjectionFindings = s.findings
+	metadata.Experiments = s.experiments
+	metadata.Prompt = s.prompt
+	metadata.Tags = s.req.Tags
+	metadata.Warnings = s.warnings
+	metadata.UsageMismatches = s.mismatches
+	metadata.TimeToFirstToken, metadata.TokensPerSecond = s.tokenRate()
+	reasoning := s.reasoning.String()
+	if s.client.config.RedactReasoning && reasoning != "" {
+		reasoning = ""
+		metadata.ReasoningRedacted = true
+	}
+	return &ChatResponse{
+		Message: Message{
+			Role:      "assistant",
+			Content:   s.content.String(),
+			Reasoning: reasoning,
+		},
+		Usage:               s.usage,
+		FinishReason:        s.finishReason,
+		MatchedStopSequence: s.stopSequence,
+		Metadata:            metadata,
+	}
+}
+
+// tokenRate returns the time to the first token and the rate of completion
+// tokens after it, zero until the first token and for rates that cannot be
+// measured
+func (s *ChatStream) tokenRate() (time.Duration, float64) {
+	if s.firstToken.IsZero() {
+		return 0, 0
+	}
+	ttft := s.firstToken.Sub(s.start)
+	generation := s.lastToken.Sub(s.firstToken)
+	if s.usage.CompletionTokens == 0 || generation <= 0 {
+		return ttft, 0
+	}
+	return ttft, float64(s.usage.CompletionTokens) / generation.Seconds()
+}
+
+// Stats returns how the stream's chunks have been received and buffered so
+// far. Unlike Recv, it may be called from any goroutine.
+func (s *ChatStream) Stats() StreamStats {
+	stats := s.counters.snapshot()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if buffered, ok := s.reader.(*bufferedReader); ok && !s.closed {
+		stats.Buffered = buffered.buffered()
+	}
+	return stats
+}
+
+// Close releases the underlying connection. It is safe to call more than
+// once and from another goroutine to abort a pending Recv.
+func (s *ChatStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	defer s.release()
+	return s.reader.Close()
+}
+
+// release tells the client the stream is no longer in flight, and stops
+// watching its context so the context does not keep it reachable
+func (s *ChatStream) release() {
+	s.released.Do(func() {
+		if s.stopClose != nil {
+			s.stopClose()
+		}
+		s.leave()
+		s.client.end()
+	})
+}
+
+// accumulate adds a chunk to the aggregated response
+func (s *ChatStream) accumulate(chunk StreamChunk) {
+	if chunk.Restart {
+		s.content.Reset()
+		s.reasoning.Reset()
+	}
+	if chunk.ReasoningDelta != "" || chunk.Delta != "" {
+		s.lastToken = time.Now()
+		if s.firstToken.IsZero() {
+			s.firstToken = s.lastToken
+		}
+	}
+	if chunk.ReasoningDelta != "" {
+		s.received = true
+		s.reasoning.WriteString(chunk.ReasoningDelta)
+	}
+	if chunk.Delta != "" {
+		s.received = true
+		s.content.WriteString(chunk.Delta)
+	}
+	if chunk.FinishReason != "" {
+		s.finishReason = chunk.FinishReason
+		s.stopSequence = chunk.MatchedStopSequence
+	}
+	if chunk.Usage != nil {
+		s.usage = *chunk.Usage
+	}
+	if chunk.Metadata != nil {
+		s.metadata = *chunk.Metadata
+	}
+}
+
+// limitLength cuts a chunk whose delta takes the content past the request's
+// MaxWords or MaxChars, so the content ends like a trimmed ChatComplete
+// response. Content already returned cannot be taken back, so the cut is
+// never before the start of the delta.
+func (s *ChatStream) limitLength(chunk *StreamChunk) {
+	if s.limited || chunk.Delta == "" || (s.req.MaxWords == nil && s.req.MaxChars == nil) {
+		return
+	}
+	content := s.content.String()
+	text, trimmed := trimToLength(content, s.req.MaxWords, s.req.MaxChars)
+	if !trimmed {
+		return
+	}
+
+	previous := content[:len(content)-len(chunk.Delta)]
+	chunk.Delta = ""
+	if len(text) > len(previous) {
+		chunk.Delta = text[len(previous):]
+	}
+	s.content.Reset()
+	s.content.WriteString(previous + chunk.Delta)
+	s.limited = true
+	s.finishReason, s.stopSequence = "length", nil
+	if chunk.FinishReason != "" {
+		chunk.FinishReason, chunk.MatchedStopSequence = "length", nil
+	}
+}
+
+// reopen replaces a stalled reader with a new stream for the same request.
+// The stream is opened without holding the lock, so Close and Stats do not
+// wait for the provider.
+func (s *ChatStream) reopen() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return s.wrapError(ErrStreamStalled)
+	}
+	s.reader.Close()
+	s.mu.Unlock()
+
+	reader, err := s.open()
+	if err != nil {
+		s.client.noteQuotaError(s.req.Model, err)
+		return s.wrapError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		// Closed while opening, so nobody will read this stream
+		reader.Close()
+		return s.wrapError(ErrStreamStalled)
+	}
+	s.reader = reader
+	return nil
+}
+
+// finish records usage and stores the interaction once the stream completes
+func (s *ChatStream) finish() {
+	latency := time.Since(s.start)
+	resp := s.Response()
+	s.client.verifyUsage(&resp.Metadata, resp.Usage, "", s.req.Messages, generatedText(resp.Message.Content, s.reasoning.String()))
+	s.mismatches = resp.Metadata.UsageMismatches
+	s.client.observeResponse(resp.Metadata, resp.Usage, latency)
+	s.client.saveInteraction(s.ctx, InteractionRecord{
+		Messages:     s.client.storedMessages(s.req.Messages),
+		Response:     resp.Message.Content,
+		FinishReason: resp.FinishReason,
+		Usage:        resp.Usage,
+		Latency:      latency,
+		Metadata:     resp.Metadata,
+	})
+}
+
+// wrapError converts stalls into retryable network errors, and sanitizes
+// other errors the way opening the stream does and marks those of streams
+// ended by CancelGroup
+func (s *ChatStream) wrapError(err error) error {
+	if !errors.Is(err, ErrStreamStalled) {
+		return groupError(s.ctx, s.client.sanitizeError(err, chatTexts(s.req)))
+	}
+
+	idleTimeout := s.client.config.StreamIdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = httputil.DefaultStreamIdleTimeout
+	}
+	return &Error{
+		Type:     ErrorTypeNetwork,
+		Message:  fmt.Sprintf("stream stalled: no data received for %v", idleTimeout),
+		Code:     "stream_stalled",
+		Provider: string(s.client.provider),
+		Wrapped:  err,
+	}
+}
@@ -0,0 +1,335 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// sliceStream is a StreamReader returning chunks and then err (io.EOF if nil)
+type sliceStream struct {
+	chunks []StreamChunk
+	err    error
+	closed bool
+}
+
+func (s *sliceStream) Recv() (StreamChunk, error) {
+	if len(s.chunks) > 0 {
+		chunk := s.chunks[0]
+		s.chunks = s.chunks[1:]
+		return chunk, nil
+	}
+	if s.err != nil {
+		return StreamChunk{}, s.err
+	}
+	return StreamChunk{}, io.EOF
+}
+
+func (s *sliceStream) Close() error {
+	s.closed = true
+	return nil
+}
+
+// streamingAdapter is a mockAdapter that serves the given streams in order
+type streamingAdapter struct {
+	mockAdapter
+	streams        []*sliceStream
+	streamRequests []ChatRequest
+}
+
+func (s *streamingAdapter) StreamChat(ctx context.Context, req ChatRequest) (StreamReader, error) {
+	s.streamRequests = append(s.streamRequests, req)
+	stream := s.streams[0]
+	s.streams = s.streams[1:]
+	return stream, nil
+}
+
+func (s *streamingAdapter) SupportedFeatures() []string {
+	return append(s.mockAdapter.SupportedFeatures(), FeatureStreaming)
+}
+
+// completeStream returns a stream producing text in two chunks
+func completeStream() *sliceStream {
+	return &sliceStream{chunks: []StreamChunk{
+		{Delta: "Hello", Metadata: &ResponseMetadata{Model: "claude-3-haiku-20240307", ResponseID: "msg_01"}},
+		{Delta: " world"},
+		{FinishReason: "end_turn", Usage: &Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}},
+	}}
+}
+
+// drain reads a stream to the end, returning the text and the final error
+func drain(stream *ChatStream) (string, error) {
+	var text string
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return text, err
+		}
+		text += chunk.Delta
+	}
+}
+
+func TestStreamChat(t *testing.T) {
+	adapter := &streamingAdapter{streams: []*sliceStream{completeStream()}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	recorder := &recordingUsageRecorder{}
+	c.config.UsageRecorder = recorder
+	c.config.Model = "claude-3-haiku-20240307"
+
+	stream, err := c.StreamChat(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	text, err := drain(stream)
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+	if text != "Hello world" {
+		t.Errorf("Expected 'Hello world', got %q", text)
+	}
+	if adapter.streamRequests[0].Model != "claude-3-haiku-20240307" {
+		t.Errorf("Expected config model to be applied, got %q", adapter.streamRequests[0].Model)
+	}
+
+	resp := stream.Response()
+	if resp.Message.Content != "Hello world" || resp.FinishReason != "end_turn" || resp.Usage.TotalTokens != 7 {
+		t.Errorf("Unexpected aggregated response: %+v", resp)
+	}
+	if resp.Metadata.ResponseID != "msg_01" {
+		t.Errorf("Expected metadata from the first chunk, got %+v", resp.Metadata)
+	}
+	if len(recorder.records) != 1 || recorder.records[0].Usage.TotalTokens != 7 {
+		t.Errorf("Expected usage to be recorded once, got %+v", recorder.records)
+	}
+
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Expected io.EOF after completion, got %v", err)
+	}
+}
+
+func TestStreamChat_TokenRate(t *testing.T) {
+	adapter := &streamingAdapter{streams: []*sliceStream{completeStream()}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	recorder := &recordingUsageRecorder{}
+	c.config.UsageRecorder = recorder
+
+	stream, err := c.StreamChat(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	// The first token arrives after 30ms and the second 50ms later
+	time.Sleep(30 * time.Millisecond)
+	stream.Recv()
+	time.Sleep(50 * time.Millisecond)
+	drain(stream)
+
+	metadata := stream.Response().Metadata
+	if metadata.TimeToFirstToken < 30*time.Millisecond || metadata.TimeToFirstToken > time.Second {
+		t.Errorf("Expected a time to first token of about 30ms, got %v", metadata.TimeToFirstToken)
+	}
+	// 2 completion tokens in at least 50ms
+	if metadata.TokensPerSecond <= 0 || metadata.TokensPerSecond > 40 {
+		t.Errorf("Expected at most 40 tokens/s, got %g", metadata.TokensPerSecond)
+	}
+	if len(recorder.records) != 1 || recorder.records[0].TimeToFirstToken != metadata.TimeToFirstToken || recorder.records[0].TokensPerSecond != metadata.TokensPerSecond {
+		t.Errorf("Expected the stream metrics in the usage record, got %+v", recorder.records)
+	}
+}
+
+func TestStreamChat_MatchedStopSequence(t *testing.T) {
+	stop := "\n3."
+	adapter := &streamingAdapter{streams: []*sliceStream{{chunks: []StreamChunk{
+		{Delta: "1. Apples\n2. Pears"},
+		{FinishReason: "stop_sequence", MatchedStopSequence: &stop},
+	}}}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	stream, err := c.StreamChat(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "List two fruits"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+	drain(stream)
+
+	if resp := stream.Response(); resp.MatchedStopSequence == nil || *resp.MatchedStopSequence != stop {
+		t.Errorf("Expected the matched stop sequence on the response, got %v", resp.MatchedStopSequence)
+	}
+}
+
+func TestStreamChat_MaxWords(t *testing.T) {
+	adapter := &streamingAdapter{streams: []*sliceStream{{chunks: []StreamChunk{
+		{Delta: "One two"},
+		{Delta: " three four"},
+		{Delta: " five"},
+		{FinishReason: "end_turn", Usage: &Usage{PromptTokens: 5, CompletionTokens: 5, TotalTokens: 10}},
+	}}}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	stream, err := c.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Count"}}, MaxWords: intPtr(3)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var chunks []StreamChunk
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) != 3 || chunks[1].Delta != " three" || chunks[2].FinishReason != "length" {
+		t.Errorf("Expected the output cut at three words, got %+v", chunks)
+	}
+	resp := stream.Response()
+	if resp.Message.Content != "One two three" || resp.FinishReason != "length" || resp.Usage.TotalTokens != 10 {
+		t.Errorf("Expected the trimmed response with its usage, got %+v", resp)
+	}
+}
+
+func TestStreamChat_StallRetries(t *testing.T) {
+	t.Run("reopens stream stalled before content", func(t *testing.T) {
+		stalled := &sliceStream{err: ErrStreamStalled}
+		adapter := &streamingAdapter{streams: []*sliceStream{stalled, completeStream()}}
+		c := newMockClient(ProviderAnthropic, adapter)
+		c.config.StreamStallRetries = 1
+
+		stream, err := c.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		text, err := drain(stream)
+		if err != io.EOF || text != "Hello world" {
+			t.Errorf("Expected complete text after reopening, got %q, %v", text, err)
+		}
+		if !stalled.closed || len(adapter.streamRequests) != 2 {
+			t.Errorf("Expected the stalled stream to be closed and reopened")
+		}
+	})
+
+	t.Run("fails when stalled after content", func(t *testing.T) {
+		stalled := &sliceStream{chunks: []StreamChunk{{Delta: "Hel"}}, err: ErrStreamStalled}
+		adapter := &streamingAdapter{streams: []*sliceStream{stalled, completeStream()}}
+		c := newMockClient(ProviderAnthropic, adapter)
+		c.config.StreamStallRetries = 1
+
+		stream, err := c.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		text, err := drain(stream)
+		if text != "Hel" {
+			t.Errorf("Expected partial text, got %q", text)
+		}
+
+		var aiErr *Error
+		if !errors.As(err, &aiErr) || aiErr.Type != ErrorTypeNetwork || !aiErr.IsRetryable() {
+			t.Fatalf("Expected retryable network error, got %v", err)
+		}
+		if !errors.Is(err, ErrStreamStalled) {
+			t.Errorf("Expected error to wrap ErrStreamStalled")
+		}
+		if len(adapter.streamRequests) != 1 {
+			t.Errorf("Expected no reopen after content was received")
+		}
+		if stream.Response().Message.Content != "Hel" {
+			t.Errorf("Expected partial response to be available")
+		}
+	})
+
+	t.Run("fails when retries are exhausted", func(t *testing.T) {
+		adapter := &streamingAdapter{streams: []*sliceStream{{err: ErrStreamStalled}}}
+		c := newMockClient(ProviderAnthropic, adapter)
+
+		stream, err := c.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := drain(stream); !errors.Is(err, ErrStreamStalled) {
+			t.Errorf("Expected stall error, got %v", err)
+		}
+	})
+}
+
+// blockingReopenAdapter serves its first stream at once and blocks opening
+// the others until released
+type blockingReopenAdapter struct {
+	streamingAdapter
+	opening chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingReopenAdapter) StreamChat(ctx context.Context, req ChatRequest) (StreamReader, error) {
+	if len(s.streamRequests) > 0 {
+		s.opening <- struct{}{}
+		<-s.release
+	}
+	return s.streamingAdapter.StreamChat(ctx, req)
+}
+
+func TestStreamChat_CloseWhileReopening(t *testing.T) {
+	reopened := completeStream()
+	adapter := &blockingReopenAdapter{
+		streamingAdapter: streamingAdapter{streams: []*sliceStream{{err: ErrStreamStalled}, reopened}},
+		opening:          make(chan struct{}),
+		release:          make(chan struct{}),
+	}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.StreamStallRetries = 1
+
+	stream, err := c.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := stream.Recv()
+		done <- err
+	}()
+	<-adapter.opening
+
+	// Close must not wait for the stream being opened
+	closed := make(chan struct{})
+	go func() {
+		stream.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close not to block while the stream is reopened")
+	}
+
+	close(adapter.release)
+	if err := <-done; !errors.Is(err, ErrStreamStalled) {
+		t.Errorf("Expected stall error, got %v", err)
+	}
+	if !reopened.closed {
+		t.Error("Expected the stream opened after Close to be closed")
+	}
+}
+
+func TestStreamChat_Unsupported(t *testing.T) {
+	c := newMockClient(ProviderOpenAI, &mockAdapter{})
+
+	_, err := c.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	var aiErr *Error
+	if !errors.As(err, &aiErr) || aiErr.Type != ErrorTypeValidation {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package aiprovider
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// errStreamClosed is returned by Recv on a buffered stream after Close
+var errStreamClosed = errors.New("stream closed")
+
+// StreamStats reports how a ChatStream's chunks were buffered.
+//
+// Chunks are only read ahead of Recv when Config.StreamBufferSize is set;
+// otherwise Buffered, MaxBuffered, Waits, WaitTime and Dropped stay zero.
+type StreamStats struct {
+	// Received is the number of chunks returned by Recv
+	Received int
+
+	// Buffered is the number of chunks read from the provider and not yet
+	// returned by Recv
+	Buffered int
+
+	// MaxBuffered is the highest number of chunks buffered at once
+	MaxBuffered int
+
+	// Waits is how many times reading from the provider paused because the
+	// buffer was full
+	Waits int
+
+	// WaitTime is the total time reading from the provider was paused
+	WaitTime time.Duration
+
+	// Dropped is the number of chunks read from the provider but discarded
+	// because the stream was closed before Recv returned them
+	Dropped int
+}
+
+// streamCounters accumulates StreamStats across the readers of a stream,
+// which may be replaced when a stalled stream is reopened
+type streamCounters struct {
+	mu    sync.Mutex
+	stats StreamStats
+}
+
+// snapshot returns a copy of the counters
+func (c *streamCounters) snapshot() StreamStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// update applies fn to the counters
+func (c *streamCounters) update(fn func(stats *StreamStats)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(&c.stats)
+}
+
+// streamResult is a chunk or error read from the provider
+type streamResult struct {
+	chunk StreamChunk
+	err   error
+}
+
+// bufferedReader reads chunks from a StreamReader into a bounded buffer
+// ahead of Recv. While the buffer is full, reading pauses until Recv catches
+// up, which stops reading from the connection and so pushes back on the
+// provider rather than growing memory.
+type bufferedReader struct {
+	reader   StreamReader
+	results  chan streamResult
+	done     chan struct{}
+	counters *streamCounters
+	once     sync.Once
+}
+
+// newBufferedReader starts reading ahead from reader into a buffer of size chunks
+func newBufferedReader(reader StreamReader, size int, counters *streamCounters) *bufferedReader {
+	b := &bufferedReader{
+		reader:   reader,
+		results:  make(chan streamResult, size),
+		done:     make(chan struct{}),
+		counters: counters,
+	}
+	go b.readAhead()
+	return b
+}
+
+// readAhead moves chunks from the reader into the buffer until the stream
+// ends, fails or is closed
+func (b *bufferedReader) readAhead() {
+	defer close(b.results)
+
+	for {
+		chunk, err := b.reader.Recv()
+		if !b.push(streamResult{chunk: chunk, err: err}) {
+			if err == nil {
+				b.counters.update(func(stats *StreamStats) { stats.Dropped++ })
+			}
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// push adds a result to the buffer, waiting while it is full. It reports
+// false if the stream was closed first.
+func (b *bufferedReader) push(result streamResult) bool {
+	select {
+	case <-b.done:
+		return false
+	case b.results <- result:
+	default:
+		b.counters.update(func(stats *StreamStats) { stats.Waits++ })
+		start := time.Now()
+		select {
+		case <-b.done:
+			return false
+		case b.results <- result:
+		}
+		waited := time.Since(start)
+		b.counters.update(func(stats *StreamStats) { stats.WaitTime += waited })
+	}
+
+	buffered := len(b.results)
+	b.counters.update(func(stats *StreamStats) {
+		if buffered > stats.MaxBuffered {
+			stats.MaxBuffered = buffered
+		}
+	})
+	return true
+}
+
+// Recv returns the next buffered chunk, waiting for one if the buffer is empty
+func (b *bufferedReader) Recv() (StreamChunk, error) {
+	result, ok := <-b.results
+	if !ok {
+		return StreamChunk{}, errStreamClosed
+	}
+	return result.chunk, result.err
+}
+
+// Close stops reading ahead, closes the reader and discards buffered chunks
+func (b *bufferedReader) Close() error {
+	var err error
+	b.once.Do(func() {
+		close(b.done)
+		err = b.reader.Close()
+
+		dropped := 0
+	drain:
+		for {
+			select {
+			case result, ok := <-b.results:
+				if !ok {
+					break drain
+				}
+				if result.err == nil {
+					dropped++
+				}
+			default:
+				break drain
+			}
+		}
+		b.counters.update(func(stats *StreamStats) { stats.Dropped += dropped })
+	})
+	return err
+}
+
+// buffered returns the number of chunks waiting to be returned by Recv
+func (b *bufferedReader) buffered() int {
+	return len(b.results)
+}
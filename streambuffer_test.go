@@ -0,0 +1,135 @@
+package aiprovider
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// endlessStream is a StreamReader producing chunks until closed, counting reads
+type endlessStream struct {
+	mu     sync.Mutex
+	reads  int
+	closed bool
+}
+
+func (e *endlessStream) Recv() (StreamChunk, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return StreamChunk{}, io.ErrClosedPipe
+	}
+	e.reads++
+	return StreamChunk{Delta: "x"}, nil
+}
+
+func (e *endlessStream) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	return nil
+}
+
+func (e *endlessStream) readCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.reads
+}
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStreamChat_Buffered(t *testing.T) {
+	chunks := make([]StreamChunk, 10)
+	for i := range chunks {
+		chunks[i] = StreamChunk{Delta: "ab"}
+	}
+	adapter := &streamingAdapter{streams: []*sliceStream{{chunks: chunks}}}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.StreamBufferSize = 3
+
+	stream, err := c.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	// A consumer that has not called Recv yet fills the buffer and no more
+	waitFor(t, func() bool { return stream.Stats().Waits == 1 })
+	if stats := stream.Stats(); stats.Buffered != 3 || stats.MaxBuffered != 3 {
+		t.Errorf("Expected a full buffer of 3 chunks, got %+v", stats)
+	}
+
+	text, err := drain(stream)
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+	if text != strings.Repeat("ab", 10) {
+		t.Errorf("Expected every chunk in order, got %q", text)
+	}
+
+	stats := stream.Stats()
+	if stats.Received != 10 || stats.Buffered != 0 || stats.Dropped != 0 || stats.MaxBuffered != 3 {
+		t.Errorf("Unexpected stats after draining: %+v", stats)
+	}
+	if stats.WaitTime <= 0 {
+		t.Errorf("Expected the paused time to be recorded, got %v", stats.WaitTime)
+	}
+}
+
+func TestStreamChat_BufferedBackpressure(t *testing.T) {
+	provider := &endlessStream{}
+	adapter := &endlessAdapter{stream: provider}
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.StreamBufferSize = 4
+
+	stream, err := c.StreamChat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := stream.Recv(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Reading stops once the buffer is full: 4 buffered plus 1 waiting
+	waitFor(t, func() bool { return stream.Stats().Buffered == 4 })
+	time.Sleep(10 * time.Millisecond)
+	if reads := provider.readCount(); reads != 2+4+1 {
+		t.Errorf("Expected reading to pause at 7 chunks, got %d", reads)
+	}
+
+	stream.Close()
+	waitFor(t, func() bool { return stream.Stats().Dropped == 5 })
+	if stats := stream.Stats(); stats.Received != 2 || stats.Buffered != 0 {
+		t.Errorf("Unexpected stats after close: %+v", stats)
+	}
+}
+
+// endlessAdapter serves an endlessStream
+type endlessAdapter struct {
+	mockAdapter
+	stream *endlessStream
+}
+
+func (e *endlessAdapter) StreamChat(ctx context.Context, req ChatRequest) (StreamReader, error) {
+	return e.stream, nil
+}
+
+func (e *endlessAdapter) SupportedFeatures() []string {
+	return append(e.mockAdapter.SupportedFeatures(), FeatureStreaming)
+}
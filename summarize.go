@@ -0,0 +1,278 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/textsplit"
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+const (
+	// DefaultSummaryChunkTokens is the default maximum size of a chunk summarized in one request
+	DefaultSummaryChunkTokens = 3000
+
+	// DefaultSummaryConcurrency is the default number of chunks summarized in parallel
+	DefaultSummaryConcurrency = 4
+)
+
+// SummarizeOptions configures Summarize.
+type SummarizeOptions struct {
+	// MaxChunkTokens is the maximum estimated size of each chunk (default: 3000)
+	// Inputs larger than this are split and summarized with map-reduce
+	MaxChunkTokens int
+
+	// MaxWords is the approximate length of the final summary (optional)
+	MaxWords int
+
+	// Instructions adds guidance such as "focus on action items" (optional)
+	Instructions string
+
+	// Model is used to summarize chunks (optional, default: the provider default)
+	Model string
+
+	// ReduceModel is used to combine chunk summaries (optional, default: Model)
+	// A stronger model here often improves coherence at little extra cost
+	ReduceModel string
+
+	// Temperature is applied to every request (optional)
+	Temperature *float64
+
+	// MaxTokens limits each generated summary (optional)
+	MaxTokens *int
+
+	// Concurrency is the number of chunks summarized in parallel (default: 4)
+	Concurrency int
+
+	// Splitter splits the input into chunks (optional, default: paragraph and
+	// sentence boundaries with MaxChunkTokens), e.g. textsplit.Markdown to
+	// summarize a document section by section
+	Splitter textsplit.Splitter
+}
+
+// ChunkSummary is the intermediate summary of one chunk of the input.
+type ChunkSummary struct {
+	// Index is the position of the chunk in the input
+	Index int `json:"index"`
+
+	// Text is the chunk of the input that was summarized
+	Text string `json:"text"`
+
+	// Summary is the chunk's summary
+	Summary string `json:"summary"`
+
+	// Usage is the token usage of the chunk's request
+	Usage Usage `json:"usage"`
+}
+
+// SummaryResult is the result of Summarize.
+type SummaryResult struct {
+	// Summary is the final summary of the whole input
+	Summary string `json:"summary"`
+
+	// Chunks contains the per-chunk intermediate summaries, in input order
+	// It holds a single entry when the input fit in one chunk
+	Chunks []ChunkSummary `json:"chunks"`
+
+	// Usage is the total token usage across all requests
+	Usage Usage `json:"usage"`
+}
+
+// Summarize summarizes text of any length.
+//
+// Short inputs are summarized in a single request. Longer inputs are split into
+// chunks at paragraph and sentence boundaries, each chunk is summarized (map),
+// and the chunk summaries are combined into one summary (reduce), repeating the
+// reduce step until everything fits in a single request.
+//
+// Example:
+//
+//	result, err := client.Summarize(ctx, report, SummarizeOptions{
+//		MaxWords:     150,
+//		Instructions: "Focus on decisions and action items.",
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(result.Summary)
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - text: The text to summarize
+//   - opts: Chunking, length and model options
+//
+// Returns:
+//   - *SummaryResult: The final summary with per-chunk intermediate results
+//   - error: A validation error for empty input, or the first request error
+func (c *client) Summarize(ctx context.Context, text string, opts SummarizeOptions) (*SummaryResult, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	if strings.TrimSpace(text) == "" {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "text to summarize cannot be empty",
+			Provider: string(c.provider),
+		}
+	}
+
+	maxChunkTokens := opts.MaxChunkTokens
+	if maxChunkTokens <= 0 {
+		maxChunkTokens = DefaultSummaryChunkTokens
+	}
+
+	// Map: summarize each chunk
+	var chunks []string
+	if opts.Splitter != nil {
+		for _, chunk := range opts.Splitter.Split(text) {
+			chunks = append(chunks, chunk.Text)
+		}
+	} else {
+		chunks = textsplit.Split(text, maxChunkTokens)
+	}
+	if len(chunks) == 0 {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "splitter returned no chunks to summarize",
+			Provider: string(c.provider),
+		}
+	}
+	summaries, err := c.summarizeChunks(ctx, chunks, opts, opts.Model, summarizeInstruction(opts, len(chunks) == 1))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SummaryResult{Chunks: summaries}
+	parts := make([]string, len(summaries))
+	for i, s := range summaries {
+		parts[i] = s.Summary
+		result.Usage = addUsage(result.Usage, s.Usage)
+	}
+
+	// Reduce: combine summaries until a single one remains
+	reduceModel := opts.ReduceModel
+	if reduceModel == "" {
+		reduceModel = opts.Model
+	}
+	for len(parts) > 1 {
+		groups := groupTexts(parts, maxChunkTokens)
+		if len(groups) == len(parts) {
+			// Summaries too large to group; combine pairwise to guarantee progress
+			groups = groupPairs(parts)
+		}
+
+		combined, err := c.summarizeChunks(ctx, groups, opts, reduceModel, combineInstruction(opts, len(groups) == 1))
+		if err != nil {
+			return nil, err
+		}
+
+		parts = parts[:0]
+		for _, s := range combined {
+			parts = append(parts, s.Summary)
+			result.Usage = addUsage(result.Usage, s.Usage)
+		}
+	}
+
+	result.Summary = parts[0]
+	return result, nil
+}
+
+// summarizeChunks summarizes texts in parallel, preserving order
+func (c *client) summarizeChunks(ctx context.Context, texts []string, opts SummarizeOptions, model, instruction string) ([]ChunkSummary, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultSummaryConcurrency
+	}
+
+	// Trim a copy, leaving the caller's texts as they were
+	trimmed := make([]string, len(texts))
+	reqs := make([]helperRequest, len(texts))
+	for i, text := range texts {
+		trimmed[i] = strings.TrimSpace(text)
+		reqs[i] = helperRequest{
+			system:      "You write concise, accurate summaries that preserve key facts, names and numbers.",
+			user:        instruction + "\n\n<text>\n" + trimmed[i] + "\n</text>",
+			model:       model,
+			temperature: opts.Temperature,
+			maxTokens:   opts.MaxTokens,
+		}
+	}
+
+	generations, err := c.generateAll(ctx, reqs, concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize: %w", err)
+	}
+
+	summaries := make([]ChunkSummary, len(texts))
+	for i, gen := range generations {
+		summaries[i] = ChunkSummary{Index: i, Text: trimmed[i], Summary: strings.TrimSpace(gen.text), Usage: gen.usage}
+	}
+	return summaries, nil
+}
+
+// summarizeInstruction builds the instruction for summarizing input chunks
+func summarizeInstruction(opts SummarizeOptions, final bool) string {
+	instruction := "Summarize the text below."
+	if !final {
+		instruction = "Summarize the text below, which is one part of a longer document."
+	}
+	return instruction + summaryConstraints(opts, final)
+}
+
+// combineInstruction builds the instruction for combining chunk summaries
+func combineInstruction(opts SummarizeOptions, final bool) string {
+	return "The text below contains summaries of consecutive parts of one document. " +
+		"Combine them into a single coherent summary without repeating information." +
+		summaryConstraints(opts, final)
+}
+
+// summaryConstraints renders length and custom instructions; the length limit
+// applies only to the final summary so intermediate steps keep detail
+func summaryConstraints(opts SummarizeOptions, final bool) string {
+	var b strings.Builder
+	if final && opts.MaxWords > 0 {
+		fmt.Fprintf(&b, " Use at most %d words.", opts.MaxWords)
+	}
+	if opts.Instructions != "" {
+		b.WriteString(" " + opts.Instructions)
+	}
+	b.WriteString(" Respond with the summary only.")
+	return b.String()
+}
+
+// groupTexts concatenates consecutive texts into groups of at most maxTokens estimated tokens
+func groupTexts(texts []string, maxTokens int) []string {
+	var groups []string
+	var current strings.Builder
+	for _, text := range texts {
+		if current.Len() > 0 && tokenizer.Estimate(current.String())+tokenizer.Estimate(text) > maxTokens {
+			groups = append(groups, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(text)
+	}
+	if current.Len() > 0 {
+		groups = append(groups, current.String())
+	}
+	return groups
+}
+
+// groupPairs concatenates texts two at a time
+func groupPairs(texts []string) []string {
+	var groups []string
+	for i := 0; i < len(texts); i += 2 {
+		if i+1 < len(texts) {
+			groups = append(groups, texts[i]+"\n\n"+texts[i+1])
+		} else {
+			groups = append(groups, texts[i])
+		}
+	}
+	return groups
+}
@@ -0,0 +1,215 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/textsplit"
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// scriptedAdapter answers chat requests with reply, which receives the last
+// user message. It is safe for concurrent use.
+type scriptedAdapter struct {
+	mockAdapter
+	mu       sync.Mutex
+	requests []ChatRequest
+	reply    func(user string) (string, error)
+}
+
+func (s *scriptedAdapter) ChatComplete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	user := req.Messages[len(req.Messages)-1].Content
+	content, err := s.reply(user)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatResponse{
+		Message:      Message{Role: "assistant", Content: content},
+		Usage:        Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		FinishReason: "stop",
+	}, nil
+}
+
+func TestSummarize_SingleChunk(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(string) (string, error) { return " Short summary. ", nil }}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	result, err := c.Summarize(context.Background(), "A short document.", SummarizeOptions{MaxWords: 20, Model: "claude-3-haiku"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Summary != "Short summary." {
+		t.Errorf("Expected trimmed summary, got %q", result.Summary)
+	}
+	if len(result.Chunks) != 1 || result.Chunks[0].Text != "A short document." {
+		t.Errorf("Expected one chunk, got %+v", result.Chunks)
+	}
+	if len(adapter.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(adapter.requests))
+	}
+
+	req := adapter.requests[0]
+	if req.Model != "claude-3-haiku" {
+		t.Errorf("Expected model claude-3-haiku, got %q", req.Model)
+	}
+	if req.Messages[0].Role != "system" {
+		t.Errorf("Expected system message first, got %q", req.Messages[0].Role)
+	}
+	if !strings.Contains(req.Messages[1].Content, "at most 20 words") {
+		t.Errorf("Expected word limit in prompt, got %q", req.Messages[1].Content)
+	}
+}
+
+func TestSummarize_MapReduce(t *testing.T) {
+	var paragraphs []string
+	for i := 0; i < 6; i++ {
+		paragraphs = append(paragraphs, strings.Repeat(fmt.Sprintf("paragraph %d content. ", i), 20))
+	}
+	text := strings.Join(paragraphs, "\n\n")
+
+	adapter := &scriptedAdapter{reply: func(user string) (string, error) {
+		if strings.Contains(user, "Combine them") {
+			return "combined", nil
+		}
+		return "part", nil
+	}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	result, err := c.Summarize(context.Background(), text, SummarizeOptions{
+		MaxChunkTokens: 150,
+		MaxWords:       50,
+		Model:          "small",
+		ReduceModel:    "large",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Summary != "combined" {
+		t.Errorf("Expected combined summary, got %q", result.Summary)
+	}
+	if len(result.Chunks) < 2 {
+		t.Fatalf("Expected multiple chunks, got %d", len(result.Chunks))
+	}
+	for i, chunk := range result.Chunks {
+		if chunk.Index != i || chunk.Summary != "part" {
+			t.Errorf("Unexpected chunk %d: %+v", i, chunk)
+		}
+		if tokenizer.Estimate(chunk.Text) > 150 {
+			t.Errorf("Chunk %d exceeds token budget", i)
+		}
+	}
+
+	mapRequests, reduceRequests := 0, 0
+	for _, req := range adapter.requests {
+		user := req.Messages[len(req.Messages)-1].Content
+		switch req.Model {
+		case "small":
+			mapRequests++
+			if strings.Contains(user, "at most 50 words") {
+				t.Errorf("Word limit should only apply to the final summary")
+			}
+		case "large":
+			reduceRequests++
+		}
+	}
+	if mapRequests != len(result.Chunks) || reduceRequests != 1 {
+		t.Errorf("Expected %d map and 1 reduce requests, got %d and %d", len(result.Chunks), mapRequests, reduceRequests)
+	}
+	if want := 15 * (len(result.Chunks) + 1); result.Usage.TotalTokens != want {
+		t.Errorf("Expected %d total tokens, got %d", want, result.Usage.TotalTokens)
+	}
+}
+
+func TestSummarize_Splitter(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(string) (string, error) { return "Section summary.", nil }}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	text := "# Setup\n\n" + strings.Repeat("Install it. ", 10) + "\n\n# Usage\n\n" + strings.Repeat("Call it. ", 10)
+	result, err := c.Summarize(context.Background(), text, SummarizeOptions{Splitter: textsplit.Markdown{MaxTokens: 40}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Chunks) != 2 || !strings.HasPrefix(result.Chunks[1].Text, "# Usage") {
+		t.Errorf("Expected a chunk per section, got %+v", result.Chunks)
+	}
+}
+
+func TestSummarizeChunks_KeepsInput(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(string) (string, error) { return "Summary.", nil }}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	texts := []string{"  first part \n", "\tsecond part"}
+	summaries, err := c.summarizeChunks(context.Background(), texts, SummarizeOptions{}, "", "Summarize the text below.")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if summaries[0].Text != "first part" || summaries[1].Text != "second part" {
+		t.Errorf("Expected trimmed chunk texts, got %+v", summaries)
+	}
+	if texts[0] != "  first part \n" || texts[1] != "\tsecond part" {
+		t.Errorf("Expected the input texts to be left unchanged, got %q", texts)
+	}
+}
+
+// nilSplitter is a splitter returning no chunks
+type nilSplitter struct{}
+
+func (nilSplitter) Split(text string) []textsplit.Chunk { return nil }
+
+func TestSummarize_NoChunks(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(string) (string, error) { return "Summary.", nil }}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	_, err := c.Summarize(context.Background(), "Some text.", SummarizeOptions{Splitter: nilSplitter{}})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Type != ErrorTypeValidation {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	if len(adapter.requests) != 0 {
+		t.Errorf("Expected no requests, got %d", len(adapter.requests))
+	}
+}
+
+func TestSummarize_Errors(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(string) (string, error) {
+		return "", NewError(ErrorTypeProvider, "anthropic", "overloaded")
+	}}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	_, err := c.Summarize(context.Background(), "  ", SummarizeOptions{})
+	if e, ok := err.(*Error); !ok || e.Type != ErrorTypeValidation {
+		t.Errorf("Expected validation error for empty text, got %v", err)
+	}
+
+	_, err = c.Summarize(context.Background(), "Some text.", SummarizeOptions{})
+	if err == nil || !strings.Contains(err.Error(), "overloaded") {
+		t.Errorf("Expected provider error, got %v", err)
+	}
+}
+
+func TestSummarize_CompletionFallback(t *testing.T) {
+	adapter := &limitedAdapter{mockAdapter{completeResp: &CompletionResponse{Text: "summary"}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	result, err := c.Summarize(context.Background(), "Some text.", SummarizeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Summary != "summary" {
+		t.Errorf("Expected summary, got %q", result.Summary)
+	}
+	if len(adapter.completeRequests) != 1 || !strings.Contains(adapter.completeRequests[0].Prompt, "Some text.") {
+		t.Errorf("Expected one completion request containing the text, got %+v", adapter.completeRequests)
+	}
+}
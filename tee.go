@@ -0,0 +1,227 @@
+package aiprovider
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTeeBufferSize is the number of chunks buffered for each consumer of
+// a tee when TeeOptions.BufferSize is zero
+const DefaultTeeBufferSize = 64
+
+// TeePolicy controls what a tee does with a chunk for a consumer whose
+// buffer is full.
+type TeePolicy string
+
+const (
+	// TeeBlock pauses reading from the source until the consumer catches
+	// up, so the consumer sees every chunk but can slow the others
+	TeeBlock TeePolicy = "block"
+
+	// TeeDrop discards the chunk for the consumer, counted in
+	// StreamStats.Dropped, so a slow consumer never holds up the others.
+	// The end of the stream and errors are always delivered.
+	TeeDrop TeePolicy = "drop"
+)
+
+// TeeOptions configures one consumer of a tee.
+type TeeOptions struct {
+	// BufferSize is the number of chunks read ahead of the consumer's Recv
+	// Default: 64 if not specified
+	BufferSize int
+
+	// Policy is applied when the buffer is full (default: TeeBlock)
+	Policy TeePolicy
+}
+
+// TeeReader is one consumer's view of a teed stream. It returns the same
+// chunks in the same order as the source, then the source's final error.
+type TeeReader struct {
+	tee      *tee
+	chunks   chan StreamChunk
+	done     chan struct{}
+	policy   TeePolicy
+	err      error // The source's final error, set before chunks is closed
+	counters streamCounters
+	once     sync.Once
+}
+
+// tee reads a source stream once on behalf of its consumers
+type tee struct {
+	source    StreamReader
+	mu        sync.Mutex
+	open      int // Consumers not yet closed
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Tee splits a stream between consumers, one for each options value, so the
+// same response can be returned to a caller and recorded by an audit logger
+// without either reading the other's chunks. The source is read once, ahead
+// of the consumers, into a buffer for each; each consumer's policy decides
+// what happens when its buffer fills. The source is closed once it ends or
+// every consumer has been closed.
+//
+// Reading a *ChatStream through a tee still accumulates its Response.
+//
+// Example:
+//
+//	readers := aiprovider.Tee(stream, aiprovider.TeeOptions{}, aiprovider.TeeOptions{Policy: aiprovider.TeeDrop})
+//	go auditLog(readers[1])
+//	forward(w, readers[0])
+func Tee(source StreamReader, consumers ...TeeOptions) []*TeeReader {
+	if len(consumers) == 0 {
+		source.Close()
+		return nil
+	}
+
+	t := &tee{source: source, open: len(consumers)}
+	readers := make([]*TeeReader, len(consumers))
+	for i, opts := range consumers {
+		size := opts.BufferSize
+		if size <= 0 {
+			size = DefaultTeeBufferSize
+		}
+		policy := opts.Policy
+		if policy == "" {
+			policy = TeeBlock
+		}
+		readers[i] = &TeeReader{
+			tee:    t,
+			chunks: make(chan StreamChunk, size),
+			done:   make(chan struct{}),
+			policy: policy,
+		}
+	}
+	go t.pump(readers)
+	return readers
+}
+
+// pump copies chunks from the source to the readers until the source ends
+// or fails, or every reader is closed
+func (t *tee) pump(readers []*TeeReader) {
+	var final error
+	for {
+		chunk, err := t.source.Recv()
+		if err != nil {
+			final = err
+			break
+		}
+
+		open := 0
+		for _, r := range readers {
+			if r.push(chunk) {
+				open++
+			}
+		}
+		if open == 0 {
+			final = errStreamClosed
+			break
+		}
+	}
+
+	t.closeSource()
+	for _, r := range readers {
+		r.err = final
+		close(r.chunks)
+	}
+}
+
+// closeSource closes the source stream once
+func (t *tee) closeSource() error {
+	t.closeOnce.Do(func() {
+		t.closeErr = t.source.Close()
+	})
+	return t.closeErr
+}
+
+// push delivers a chunk to the reader according to its policy. It reports
+// false if the reader was closed.
+func (r *TeeReader) push(chunk StreamChunk) bool {
+	select {
+	case <-r.done:
+		return false
+	case r.chunks <- chunk:
+	default:
+		if r.policy == TeeDrop {
+			r.counters.update(func(stats *StreamStats) { stats.Dropped++ })
+			return true
+		}
+
+		r.counters.update(func(stats *StreamStats) { stats.Waits++ })
+		start := time.Now()
+		select {
+		case <-r.done:
+			return false
+		case r.chunks <- chunk:
+		}
+		waited := time.Since(start)
+		r.counters.update(func(stats *StreamStats) { stats.WaitTime += waited })
+	}
+
+	buffered := len(r.chunks)
+	r.counters.update(func(stats *StreamStats) {
+		if buffered > stats.MaxBuffered {
+			stats.MaxBuffered = buffered
+		}
+	})
+	return true
+}
+
+// Recv returns the next chunk, waiting for one if none is buffered. It
+// returns io.EOF once the source is complete, or the source's error.
+func (r *TeeReader) Recv() (StreamChunk, error) {
+	select {
+	case <-r.done:
+		return StreamChunk{}, errStreamClosed
+	default:
+	}
+
+	chunk, ok := <-r.chunks
+	if !ok {
+		return StreamChunk{}, r.err
+	}
+	r.counters.update(func(stats *StreamStats) { stats.Received++ })
+	return chunk, nil
+}
+
+// Stats returns how the reader's chunks were buffered and, with TeeDrop,
+// how many were dropped
+func (r *TeeReader) Stats() StreamStats {
+	stats := r.counters.snapshot()
+	stats.Buffered = len(r.chunks)
+	return stats
+}
+
+// Close stops delivering chunks to the reader and discards those buffered.
+// Closing the last open reader closes the source.
+func (r *TeeReader) Close() error {
+	var err error
+	r.once.Do(func() {
+		close(r.done)
+
+		dropped := 0
+	drain:
+		for {
+			select {
+			case _, ok := <-r.chunks:
+				if !ok {
+					break drain
+				}
+				dropped++
+			default:
+				break drain
+			}
+		}
+		r.counters.update(func(stats *StreamStats) { stats.Dropped += dropped })
+
+		r.tee.mu.Lock()
+		r.tee.open--
+		last := r.tee.open == 0
+		r.tee.mu.Unlock()
+		if last {
+			err = r.tee.closeSource()
+		}
+	})
+	return err
+}
@@ -0,0 +1,109 @@
+package aiprovider
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// readAll reads a stream to the end, returning the text and the final error
+func readAll(stream StreamReader) (string, error) {
+	var text string
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return text, err
+		}
+		text += chunk.Delta
+	}
+}
+
+func TestTee(t *testing.T) {
+	source := completeStream()
+	readers := Tee(source, TeeOptions{}, TeeOptions{})
+
+	var wg sync.WaitGroup
+	texts := make([]string, len(readers))
+	errs := make([]error, len(readers))
+	for i, reader := range readers {
+		wg.Add(1)
+		go func(i int, reader *TeeReader) {
+			defer wg.Done()
+			texts[i], errs[i] = readAll(reader)
+		}(i, reader)
+	}
+	wg.Wait()
+
+	for i := range readers {
+		if texts[i] != "Hello world" || errs[i] != io.EOF {
+			t.Errorf("Reader %d: expected the full text and io.EOF, got %q, %v", i, texts[i], errs[i])
+		}
+		if stats := readers[i].Stats(); stats.Received != 3 || stats.Dropped != 0 {
+			t.Errorf("Reader %d: unexpected stats %+v", i, stats)
+		}
+	}
+	if !source.closed {
+		t.Error("Expected the source to be closed once it ended")
+	}
+}
+
+func TestTee_DropPolicy(t *testing.T) {
+	readers := Tee(completeStream(), TeeOptions{BufferSize: 1}, TeeOptions{BufferSize: 1, Policy: TeeDrop})
+
+	// The blocking reader sees everything; the slow dropping reader keeps
+	// only what fit in its buffer
+	if text, err := readAll(readers[0]); text != "Hello world" || err != io.EOF {
+		t.Fatalf("Expected the full text and io.EOF, got %q, %v", text, err)
+	}
+	if text, err := readAll(readers[1]); text != "Hello" || err != io.EOF {
+		t.Errorf("Expected the buffered chunk and io.EOF, got %q, %v", text, err)
+	}
+	if stats := readers[1].Stats(); stats.Received != 1 || stats.Dropped != 2 {
+		t.Errorf("Expected 2 dropped chunks, got %+v", stats)
+	}
+	if stats := readers[0].Stats(); stats.Waits == 0 {
+		t.Errorf("Expected the blocking reader to hold up the source, got %+v", stats)
+	}
+}
+
+func TestTee_Error(t *testing.T) {
+	streamErr := errors.New("connection reset")
+	readers := Tee(&sliceStream{chunks: []StreamChunk{{Delta: "Hello"}}, err: streamErr}, TeeOptions{}, TeeOptions{})
+
+	for i, reader := range readers {
+		if text, err := readAll(reader); text != "Hello" || err != streamErr {
+			t.Errorf("Reader %d: expected the chunk and the source error, got %q, %v", i, text, err)
+		}
+	}
+}
+
+func TestTee_Close(t *testing.T) {
+	source := completeStream()
+	readers := Tee(source, TeeOptions{BufferSize: 1}, TeeOptions{BufferSize: 1})
+
+	// Closing a consumer releases the source for the others
+	if err := readers[1].Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := readers[1].Recv(); err != errStreamClosed {
+		t.Errorf("Expected errStreamClosed after Close, got %v", err)
+	}
+	if text, err := readAll(readers[0]); text != "Hello world" || err != io.EOF {
+		t.Errorf("Expected the full text and io.EOF, got %q, %v", text, err)
+	}
+	readers[0].Close()
+	if !source.closed {
+		t.Error("Expected the source to be closed")
+	}
+}
+
+func TestTee_CloseAll(t *testing.T) {
+	source := completeStream()
+	readers := Tee(source, TeeOptions{BufferSize: 1})
+	readers[0].Close()
+
+	if !source.closed {
+		t.Error("Expected closing the last reader to close the source")
+	}
+}
@@ -0,0 +1,115 @@
+package testutil
+
+import "testing"
+
+// ResponseSeed is a provider HTTP response used to seed fuzz tests of
+// response parsing.
+type ResponseSeed struct {
+	// Status is the HTTP status code
+	Status int
+
+	// Body is the response body
+	Body string
+}
+
+// AnthropicResponseSeeds are well-formed, malformed and unexpected Anthropic
+// Messages API responses. The adapter fuzz tests start from them; forks
+// adding parsing code can seed their own fuzz tests with AddResponseSeeds.
+var AnthropicResponseSeeds = []ResponseSeed{
+	{200, `{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":2}}`},
+	{200, `{"id":"msg_01","type":"message","role":"assistant","content":[],"usage":{}}`},
+	{200, `{"content":[{"type":"tool_use","id":"toolu_01","name":"lookup","input":{}}]}`},
+	{200, `{"content":"Hi"}`},
+	{200, `{"content":[{"type":"text","text":null}],"usage":{"input_tokens":-1,"output_tokens":1e30}}`},
+	{200, `{}`},
+	{200, `[]`},
+	{200, `null`},
+	{200, ``},
+	{200, `{"id":"msg_01","content":[{"type":"text","text":"Hi"`},
+	{400, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: too large"}}`},
+	{400, `{"type":"invalid_request_error","message":"messages: roles must alternate"}`},
+	{401, `{"type":"authentication_error","message":"invalid x-api-key"}`},
+	{429, `{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}`},
+	{429, `Too Many Requests`},
+	{500, ``},
+	{502, `<html><body><h1>502 Bad Gateway</h1></body></html>`},
+	{529, `{"type":"overloaded_error","message":"Overloaded"}`},
+}
+
+// AnthropicStreamSeeds are well-formed, truncated and malformed Anthropic
+// server-sent event streams.
+var AnthropicStreamSeeds = []string{
+	"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"model\":\"claude-3-haiku-20240307\",\"usage\":{\"input_tokens\":5,\"output_tokens\":1}}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n" +
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":2}}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+	"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\"}}\n\nevent: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n",
+	"event: content_block_delta\r\ndata: {\"type\":\"content_block_delta\",\r\ndata: \"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\r\n\r\ndata: {\"type\":\"message_stop\"}",
+	": keep-alive\n\nevent: ping\ndata: {\"type\":\"ping\"}\n\nevent: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+	"event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n",
+	"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"text\":\"Hi\"\n\n",
+	"data: not json\n\n",
+	"data: null\n\ndata: []\n\ndata: \"message_stop\"\n\n",
+	"event\ndata\n:\n\n\n",
+	"",
+}
+
+// OpenAIResponseSeeds are well-formed, malformed and unexpected OpenAI
+// completion responses.
+var OpenAIResponseSeeds = []ResponseSeed{
+	{200, `{"id":"cmpl-1","object":"text_completion","model":"gpt-3.5-turbo-instruct","choices":[{"text":"Hi","index":0,"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`},
+	{200, `{"choices":[]}`},
+	{200, `{"choices":null,"usage":null}`},
+	{200, `{"choices":[{}]}`},
+	{200, `{"choices":[{"text":7}]}`},
+	{200, `{"choices":[{"text":"Hi","finish_reason":null}],"usage":{"total_tokens":-3}}`},
+	{200, `{}`},
+	{200, `[]`},
+	{200, `null`},
+	{200, ``},
+	{200, `{"choices":[{"text":"Hi"`},
+	{400, `{"error":{"message":"Invalid value for 'temperature'","type":"invalid_request_error","param":"temperature","code":null}}`},
+	{400, `{"error":"bad request"}`},
+	{401, `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`},
+	{429, `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`},
+	{429, `Too Many Requests`},
+	{500, ``},
+	{503, `<html><body>Service Unavailable</body></html>`},
+}
+
+// AddResponseSeeds adds seeds to the corpus of a fuzz test taking a status
+// code and a body.
+//
+// Example:
+//
+//	func FuzzParseResponse(f *testing.F) {
+//		testutil.AddResponseSeeds(f, testutil.AnthropicResponseSeeds)
+//		f.Fuzz(func(t *testing.T, status int, body string) {
+//			status = testutil.FuzzStatus(status)
+//			...
+//		})
+//	}
+func AddResponseSeeds(f *testing.F, seeds []ResponseSeed) {
+	for _, seed := range seeds {
+		f.Add(seed.Status, seed.Body)
+	}
+}
+
+// AddStreamSeeds adds stream bodies to the corpus of a fuzz test taking a body
+func AddStreamSeeds(f *testing.F, seeds []string) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+}
+
+// FuzzStatus maps a fuzzed integer to an HTTP status code from 200 to 599,
+// keeping the seeds' status codes unchanged
+func FuzzStatus(status int) int {
+	if status >= 200 && status <= 599 {
+		return status
+	}
+	if status < 0 {
+		status = -(status + 1)
+	}
+	return 200 + status%400
+}
@@ -0,0 +1,285 @@
+// Package testutil provides helpers for testing applications built on ai-providers.
+//
+// Golden records a prompt, model, seed and response the first time a test
+// runs and asserts that later responses still match, exactly, after
+// normalization or by embedding similarity. This makes prompt-regression
+// suites easy to build: a changed prompt template or model upgrade that
+// changes answers beyond the threshold fails the test.
+//
+// Example:
+//
+//	func TestSummaryPrompt(t *testing.T) {
+//		golden := testutil.NewGolden(testutil.GoldenOptions{
+//			Mode:      testutil.MatchNormalized,
+//			Threshold: 0.8,
+//		})
+//
+//		resp, err := client.ChatComplete(ctx, req)
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		golden.Assert(t, "summary", testutil.Sample{
+//			Prompt:   req.Messages[len(req.Messages)-1].Content,
+//			Model:    resp.Metadata.Model,
+//			Response: resp.Message.Content,
+//		})
+//	}
+//
+// Run the tests with AI_GOLDEN_UPDATE=1 to re-record all samples.
+//
+// The fuzz seed corpora, such as AnthropicResponseSeeds, collect the
+// well-formed and malformed provider responses the adapters are fuzzed
+// with, for reuse in fuzz tests of custom parsing code.
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/ajeet-kumar1087/ai-providers/prompt"
+)
+
+const (
+	// DefaultGoldenDir is the directory golden files are stored in
+	DefaultGoldenDir = "testdata/golden"
+
+	// DefaultEmbeddingThreshold is the minimum cosine similarity for MatchEmbedding
+	DefaultEmbeddingThreshold = 0.9
+
+	// UpdateEnvVar re-records all golden samples when set to a non-empty value
+	UpdateEnvVar = "AI_GOLDEN_UPDATE"
+)
+
+// MatchMode selects how a response is compared with its golden sample.
+type MatchMode string
+
+const (
+	// MatchExact requires byte-identical responses
+	MatchExact MatchMode = "exact"
+
+	// MatchNormalized compares responses after lowercasing, removing
+	// punctuation and collapsing whitespace. With a Threshold below 1, the
+	// word-level similarity must reach the threshold instead.
+	MatchNormalized MatchMode = "normalized"
+
+	// MatchEmbedding requires the cosine similarity of the response
+	// embeddings to reach the threshold
+	MatchEmbedding MatchMode = "embedding"
+)
+
+// Sample is a recorded prompt, model, seed and response.
+type Sample struct {
+	// Prompt is the prompt or rendered conversation that produced the response
+	Prompt string `json:"prompt"`
+
+	// Model is the model that produced the response
+	Model string `json:"model"`
+
+	// Seed is the sampling seed, if the request used one (optional)
+	Seed *int64 `json:"seed,omitempty"`
+
+	// Response is the response text
+	Response string `json:"response"`
+
+	// RecordedAt is when the sample was recorded, set by Golden
+	RecordedAt time.Time `json:"recorded_at,omitempty"`
+}
+
+// GoldenOptions configures a Golden.
+type GoldenOptions struct {
+	// Dir is the directory golden files are stored in (default: DefaultGoldenDir)
+	Dir string
+
+	// Mode is the comparison mode (default: MatchExact)
+	Mode MatchMode
+
+	// Threshold is the minimum similarity between 0 and 1 (default: 1 for
+	// MatchNormalized, DefaultEmbeddingThreshold for MatchEmbedding)
+	Threshold float64
+
+	// Embedder embeds responses for MatchEmbedding (required for that mode)
+	Embedder prompt.Embedder
+
+	// Update re-records samples instead of comparing them
+	// (default: whether the UpdateEnvVar environment variable is set)
+	Update bool
+}
+
+// Golden asserts responses against recorded samples.
+type Golden struct {
+	opts GoldenOptions
+}
+
+// NewGolden creates a Golden with opts, applying defaults
+func NewGolden(opts GoldenOptions) *Golden {
+	if opts.Dir == "" {
+		opts.Dir = DefaultGoldenDir
+	}
+	if opts.Mode == "" {
+		opts.Mode = MatchExact
+	}
+	if opts.Threshold == 0 {
+		opts.Threshold = 1
+		if opts.Mode == MatchEmbedding {
+			opts.Threshold = DefaultEmbeddingThreshold
+		}
+	}
+	if os.Getenv(UpdateEnvVar) != "" {
+		opts.Update = true
+	}
+	return &Golden{opts: opts}
+}
+
+// Assert compares got with the sample recorded under name, failing t if the
+// prompt, model or seed changed or the response is not similar enough. A
+// missing sample is recorded and the assertion passes.
+func (g *Golden) Assert(t testing.TB, name string, got Sample) {
+	t.Helper()
+
+	path := g.Path(name)
+	want, err := readSample(path)
+	if os.IsNotExist(err) || g.opts.Update {
+		got.RecordedAt = time.Now().UTC()
+		if err := writeSample(path, got); err != nil {
+			t.Fatalf("golden %s: %v", name, err)
+		}
+		t.Logf("golden %s: recorded %s", name, path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("golden %s: %v", name, err)
+	}
+
+	if got.Prompt != want.Prompt || got.Model != want.Model || !equalSeeds(got.Seed, want.Seed) {
+		t.Fatalf("golden %s: prompt, model or seed changed since recording; rerun with %s=1 to re-record", name, UpdateEnvVar)
+	}
+
+	similarity, err := g.Compare(context.Background(), want.Response, got.Response)
+	if err != nil {
+		t.Fatalf("golden %s: %v", name, err)
+	}
+	if similarity < g.opts.Threshold {
+		t.Errorf("golden %s: %s similarity %.3f is below %.3f\nwant: %s\ngot:  %s",
+			name, g.opts.Mode, similarity, g.opts.Threshold, want.Response, got.Response)
+	}
+}
+
+// Compare returns the similarity between 0 and 1 of two responses under the configured mode
+func (g *Golden) Compare(ctx context.Context, want, got string) (float64, error) {
+	switch g.opts.Mode {
+	case MatchExact:
+		if want == got {
+			return 1, nil
+		}
+		return 0, nil
+	case MatchNormalized:
+		return wordSimilarity(normalizeWords(want), normalizeWords(got)), nil
+	case MatchEmbedding:
+		if g.opts.Embedder == nil {
+			return 0, fmt.Errorf("embedding match requires an embedder")
+		}
+		vectors, err := g.opts.Embedder.Embed(ctx, []string{want, got})
+		if err != nil {
+			return 0, fmt.Errorf("failed to embed responses: %w", err)
+		}
+		if len(vectors) != 2 {
+			return 0, fmt.Errorf("embedder returned %d vectors for 2 texts", len(vectors))
+		}
+		return prompt.CosineSimilarity(vectors[0], vectors[1]), nil
+	default:
+		return 0, fmt.Errorf("unknown match mode %q", g.opts.Mode)
+	}
+}
+
+// Path returns the golden file path of a sample name
+func (g *Golden) Path(name string) string {
+	return filepath.Join(g.opts.Dir, unsafeFileChars.ReplaceAllString(name, "_")+".json")
+}
+
+// unsafeFileChars matches characters replaced in golden file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// readSample loads a golden file
+func readSample(path string) (Sample, error) {
+	var sample Sample
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sample, err
+	}
+	if err := json.Unmarshal(data, &sample); err != nil {
+		return sample, fmt.Errorf("invalid golden file %s: %w", path, err)
+	}
+	return sample, nil
+}
+
+// writeSample stores a golden file, creating its directory
+func writeSample(path string, sample Sample) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create golden directory: %w", err)
+	}
+	data, err := json.MarshalIndent(sample, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode golden sample: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// equalSeeds reports whether two optional seeds are equal
+func equalSeeds(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// normalizeWords lowercases text and splits it into words, dropping punctuation
+func normalizeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// wordSimilarity returns 1 minus the word-level edit distance divided by the longer length
+func wordSimilarity(a, b []string) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(b)])/float64(longest)
+}
+
+// minInt returns the smaller of two ints
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,135 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fakeTB records failures instead of failing the test
+type fakeTB struct {
+	testing.TB
+	failures []string
+}
+
+// fatal stops the assertion like testing.TB.Fatalf stops the test
+type fatal struct{}
+
+func (f *fakeTB) Helper()                                 {}
+func (f *fakeTB) Logf(format string, args ...interface{}) {}
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+func (f *fakeTB) Fatalf(format string, args ...interface{}) {
+	f.Errorf(format, args...)
+	panic(fatal{})
+}
+
+// assert runs Golden.Assert and returns the reported failures
+func assert(g *Golden, name string, sample Sample) (failures []string) {
+	tb := &fakeTB{}
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(fatal); !ok {
+				panic(r)
+			}
+		}
+		failures = tb.failures
+	}()
+	g.Assert(tb, name, sample)
+	return tb.failures
+}
+
+// letterEmbedder embeds texts as letter frequency vectors
+type letterEmbedder struct{}
+
+func (letterEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, 26)
+		for _, r := range strings.ToLower(text) {
+			if r >= 'a' && r <= 'z' {
+				vectors[i][r-'a']++
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func TestGolden(t *testing.T) {
+	t.Setenv(UpdateEnvVar, "")
+	seed := int64(42)
+	recorded := Sample{Prompt: "Capital of France?", Model: "m1", Seed: &seed, Response: "The capital of France is Paris."}
+
+	tests := []struct {
+		name   string
+		opts   GoldenOptions
+		sample Sample
+		pass   bool
+	}{
+		{name: "exact match", sample: recorded, pass: true},
+		{name: "exact mismatch", sample: Sample{Prompt: recorded.Prompt, Model: "m1", Seed: &seed, Response: "Paris."}},
+		{
+			name:   "normalized ignores case and punctuation",
+			opts:   GoldenOptions{Mode: MatchNormalized},
+			sample: Sample{Prompt: recorded.Prompt, Model: "m1", Seed: &seed, Response: "the capital of france is  PARIS"},
+			pass:   true,
+		},
+		{
+			name:   "normalized threshold",
+			opts:   GoldenOptions{Mode: MatchNormalized, Threshold: 0.8},
+			sample: Sample{Prompt: recorded.Prompt, Model: "m1", Seed: &seed, Response: "The capital city of France is Paris."},
+			pass:   true,
+		},
+		{
+			name:   "normalized below threshold",
+			opts:   GoldenOptions{Mode: MatchNormalized, Threshold: 0.8},
+			sample: Sample{Prompt: recorded.Prompt, Model: "m1", Seed: &seed, Response: "It is Lyon."},
+		},
+		{
+			name:   "embedding similarity",
+			opts:   GoldenOptions{Mode: MatchEmbedding, Embedder: letterEmbedder{}},
+			sample: Sample{Prompt: recorded.Prompt, Model: "m1", Seed: &seed, Response: "Paris is the capital of France."},
+			pass:   true,
+		},
+		{name: "model changed", sample: Sample{Prompt: recorded.Prompt, Model: "m2", Seed: &seed, Response: recorded.Response}},
+		{name: "seed removed", sample: Sample{Prompt: recorded.Prompt, Model: "m1", Response: recorded.Response}},
+		{name: "embedding without embedder", opts: GoldenOptions{Mode: MatchEmbedding}, sample: recorded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Dir = t.TempDir()
+			golden := NewGolden(tt.opts)
+			if failures := assert(golden, "capital/france", recorded); len(failures) != 0 {
+				t.Fatalf("Expected the first run to record, got %v", failures)
+			}
+			if _, err := os.Stat(golden.Path("capital/france")); err != nil {
+				t.Fatalf("Expected a golden file: %v", err)
+			}
+
+			failures := assert(golden, "capital/france", tt.sample)
+			if tt.pass && len(failures) != 0 {
+				t.Errorf("Expected a match, got %v", failures)
+			}
+			if !tt.pass && len(failures) == 0 {
+				t.Errorf("Expected a mismatch")
+			}
+		})
+	}
+}
+
+func TestGolden_Update(t *testing.T) {
+	dir := t.TempDir()
+	NewGolden(GoldenOptions{Dir: dir}).Assert(t, "greeting", Sample{Prompt: "Hi", Response: "Hello"})
+
+	t.Setenv(UpdateEnvVar, "1")
+	NewGolden(GoldenOptions{Dir: dir}).Assert(t, "greeting", Sample{Prompt: "Hi", Response: "Hey there"})
+
+	sample, err := readSample(NewGolden(GoldenOptions{Dir: dir}).Path("greeting"))
+	if err != nil || sample.Response != "Hey there" || sample.RecordedAt.IsZero() {
+		t.Errorf("Expected the update to re-record, got %+v, %v", sample, err)
+	}
+}
@@ -0,0 +1,89 @@
+package textsplit
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// Fixed splits text into windows of MaxTokens tokens, each starting Overlap
+// tokens before the previous one ended so that context cut at a boundary
+// appears in both chunks. Windows end at word boundaries where possible.
+type Fixed struct {
+	// MaxTokens is the maximum size of each chunk; 0 disables splitting
+	MaxTokens int
+
+	// Overlap is the number of tokens repeated from the end of each chunk at
+	// the start of the next; at most half of MaxTokens is used
+	Overlap int
+
+	// Tokenizer counts tokens (default: tokenizer.Default)
+	Tokenizer tokenizer.Tokenizer
+}
+
+// Split implements Splitter
+func (f Fixed) Split(text string) []Chunk {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	t := tokenizerOrDefault(f.Tokenizer)
+	if f.MaxTokens <= 0 {
+		return []Chunk{newChunk(t, 0, 0, text)}
+	}
+	overlap := f.Overlap
+	if overlap > f.MaxTokens/2 {
+		overlap = f.MaxTokens / 2
+	}
+
+	var chunks []Chunk
+	for start := 0; start < len(text); {
+		end := start + len(truncateTokens(t, text[start:], f.MaxTokens))
+		if end == start {
+			// A single character over the limit still makes progress
+			end += len(tokenizer.TruncateRunes(text[start:], 1))
+		}
+		if end < len(text) {
+			if space := strings.LastIndexFunc(text[start:end], unicode.IsSpace); space > 0 {
+				_, size := utf8.DecodeRuneInString(text[start+space:])
+				end = start + space + size
+			}
+		}
+
+		chunks = append(chunks, newChunk(t, len(chunks), start, text[start:end]))
+		if end >= len(text) {
+			break
+		}
+		start = overlapStart(t, text, start, end, overlap)
+	}
+	return chunks
+}
+
+// overlapStart returns where the chunk after text[start:end] begins: far
+// enough before end to repeat at least overlap tokens, at a word start if
+// possible, and always after start so splitting makes progress
+func overlapStart(t tokenizer.Tokenizer, text string, start, end, overlap int) int {
+	if overlap <= 0 {
+		return end
+	}
+
+	next := end
+	for next > start && t.CountTokens(text[next:end]) < overlap {
+		_, size := utf8.DecodeLastRuneInString(text[:next])
+		next -= size
+	}
+
+	// Move back to the start of the word rather than begin mid-word
+	if prev, _ := utf8.DecodeLastRuneInString(text[:next]); next > start && !unicode.IsSpace(prev) {
+		if space := strings.LastIndexFunc(text[start:next], unicode.IsSpace); space >= 0 {
+			_, size := utf8.DecodeRuneInString(text[start+space:])
+			next = start + space + size
+		}
+	}
+
+	if next <= start {
+		return end
+	}
+	return next
+}
@@ -0,0 +1,203 @@
+package textsplit
+
+import (
+	"strings"
+
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// Markdown splits Markdown documents longer than MaxTokens at headings, so
+// each chunk covers one section where it fits, and keeps fenced code blocks
+// whole unless a block alone exceeds MaxTokens, in which case it is split
+// between lines. Sections too long for a chunk are split like Recursive.
+// Concatenating the chunks yields the original text.
+type Markdown struct {
+	// MaxTokens is the maximum size of each chunk; 0 disables splitting
+	MaxTokens int
+
+	// Tokenizer counts tokens (default: tokenizer.Default)
+	Tokenizer tokenizer.Tokenizer
+}
+
+// markdownBlock is a heading, fenced code block or paragraph of a document
+type markdownBlock struct {
+	text    string
+	heading string // Heading path the block is under
+	title   bool   // The block is a heading line
+	code    bool   // The block is a fenced code block
+}
+
+// Split implements Splitter
+func (m Markdown) Split(text string) []Chunk {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	t := tokenizerOrDefault(m.Tokenizer)
+	if m.MaxTokens <= 0 || t.CountTokens(text) <= m.MaxTokens {
+		chunks := contiguousChunks(t, []string{text})
+		chunks[0].Heading = markdownBlocks(text)[0].heading
+		return chunks
+	}
+
+	var texts, headings []string
+	var current strings.Builder
+	currentHeading := ""
+	onlyTitles := true
+	flush := func() {
+		if current.Len() > 0 {
+			texts = append(texts, current.String())
+			headings = append(headings, currentHeading)
+			current.Reset()
+		}
+		onlyTitles = true
+	}
+
+	for _, block := range markdownBlocks(text) {
+		// Start each section in a new chunk, keeping headings with their content
+		if block.title && !onlyTitles {
+			flush()
+		}
+		if current.Len() > 0 && t.CountTokens(current.String()+block.text) > m.MaxTokens {
+			flush()
+		}
+		if current.Len() == 0 {
+			currentHeading = block.heading
+		}
+
+		if t.CountTokens(block.text) <= m.MaxTokens {
+			current.WriteString(block.text)
+			onlyTitles = onlyTitles && block.title
+			continue
+		}
+
+		// The block alone is too large; code is split between lines only
+		flush()
+		var parts []string
+		if block.code {
+			parts = splitLines(t, block.text, m.MaxTokens)
+		} else {
+			parts = splitChunks(t, block.text, m.MaxTokens)
+		}
+		for _, part := range parts {
+			texts = append(texts, part)
+			headings = append(headings, block.heading)
+		}
+	}
+	flush()
+
+	chunks := contiguousChunks(t, texts)
+	for i := range chunks {
+		chunks[i].Heading = headings[i]
+	}
+	return chunks
+}
+
+// markdownBlocks divides text into heading lines, fenced code blocks and
+// paragraphs, each with its trailing blank lines, recording the heading path
+// of each. The blocks concatenate to text.
+func markdownBlocks(text string) []markdownBlock {
+	var blocks []markdownBlock
+	var path []string // Heading titles by level
+	heading := ""
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		start := i
+		block := markdownBlock{heading: heading}
+
+		switch {
+		case isFence(trimmed):
+			// A fence runs to the closing fence, or the end of the document
+			fence := trimmed[:3]
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					i++
+					break
+				}
+			}
+			block.code = true
+		case headingLevel(trimmed) > 0:
+			level := headingLevel(trimmed)
+			for len(path) < level {
+				path = append(path, "")
+			}
+			path = append(path[:level-1], strings.TrimSpace(trimmed[level:]))
+			heading = joinHeadings(path)
+			block.heading = heading
+			block.title = true
+			i++
+		default:
+			// A paragraph runs to a blank line, heading or fence
+			for i++; i < len(lines); i++ {
+				next := strings.TrimSpace(lines[i])
+				if next == "" || isFence(next) || headingLevel(next) > 0 {
+					break
+				}
+			}
+		}
+
+		// Keep trailing blank lines with the block
+		for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			i++
+		}
+		block.text = strings.Join(lines[start:i], "")
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// isFence reports whether a trimmed line opens or closes a code block
+func isFence(line string) bool {
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+// headingLevel returns the level of an ATX heading line, or 0 if the trimmed
+// line is not a heading
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ' && line[level] != '\t') {
+		return 0
+	}
+	return level
+}
+
+// joinHeadings joins the non-empty titles of a heading path
+func joinHeadings(path []string) string {
+	titles := make([]string, 0, len(path))
+	for _, title := range path {
+		if title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return strings.Join(titles, " > ")
+}
+
+// splitLines splits text between lines into chunks of at most maxTokens,
+// splitting a single line only if it alone is too large
+func splitLines(t tokenizer.Tokenizer, text string, maxTokens int) []string {
+	var chunks []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if current.Len() > 0 && t.CountTokens(current.String()+line) > maxTokens {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if t.CountTokens(line) > maxTokens {
+			chunks = append(chunks, splitChunks(t, line, maxTokens)...)
+			continue
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
@@ -0,0 +1,197 @@
+// Package textsplit splits long documents into token-bounded chunks.
+//
+// Documents too long for a single request or embedding are split before
+// summarization, translation or indexing. Split returns plain text chunks;
+// the Splitter implementations return Chunks carrying their position, size
+// and a deterministic ID:
+//
+//   - Recursive splits at paragraph, then line, sentence and word boundaries
+//   - Fixed splits into fixed-size windows that overlap by a number of tokens
+//   - Markdown splits at headings and keeps fenced code blocks whole
+//
+// Example:
+//
+//	splitter := textsplit.Fixed{MaxTokens: 300, Overlap: 30}
+//	for _, chunk := range splitter.Split(document) {
+//		fmt.Println(chunk.ID, chunk.Tokens)
+//	}
+package textsplit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// Chunk is a piece of a split document.
+type Chunk struct {
+	// ID is a hash of Text, so identical chunks share an ID across documents
+	// and runs; use it as a cache key for embeddings or summaries
+	ID string `json:"id"`
+
+	// Index is the position of the chunk in the document
+	Index int `json:"index"`
+
+	// Text is the chunk content
+	Text string `json:"text"`
+
+	// Offset is the byte offset of Text in the document
+	Offset int `json:"offset"`
+
+	// Tokens is the token count of Text
+	Tokens int `json:"tokens"`
+
+	// Heading is the path of Markdown headings the chunk is under, e.g.
+	// "Install > Linux" (Markdown only)
+	Heading string `json:"heading,omitempty"`
+}
+
+// Splitter splits a document into chunks.
+//
+// Implementations must be deterministic: splitting the same text always
+// returns the same chunks.
+type Splitter interface {
+	// Split returns the chunks of text in order, or nil if text is blank
+	Split(text string) []Chunk
+}
+
+// ChunkID returns the ID of a chunk with the given text: the first 16 hex
+// digits of its SHA-256 hash
+func ChunkID(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Split splits text into chunks of at most maxTokens estimated tokens,
+// preferring paragraph, then line, then sentence, then word boundaries.
+// Concatenating the chunks yields the original text.
+func Split(text string, maxTokens int) []string {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	return splitChunks(tokenizer.Default, text, maxTokens)
+}
+
+// Recursive splits text at paragraph, then line, then sentence, then word
+// boundaries, like Split. Concatenating the chunks yields the original text.
+type Recursive struct {
+	// MaxTokens is the maximum size of each chunk; 0 disables splitting
+	MaxTokens int
+
+	// Tokenizer counts tokens (default: tokenizer.Default)
+	Tokenizer tokenizer.Tokenizer
+}
+
+// Split implements Splitter
+func (r Recursive) Split(text string) []Chunk {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	t := tokenizerOrDefault(r.Tokenizer)
+	return contiguousChunks(t, splitChunks(t, text, r.MaxTokens))
+}
+
+// splitChunks implements Split
+func splitChunks(t tokenizer.Tokenizer, text string, maxTokens int) []string {
+	if maxTokens <= 0 || t.CountTokens(text) <= maxTokens {
+		return []string{text}
+	}
+
+	for _, sep := range []string{"\n\n", "\n", ". ", " "} {
+		parts := strings.Split(text, sep)
+		if len(parts) < 2 {
+			continue
+		}
+
+		var chunks []string
+		var current strings.Builder
+		for i, part := range parts {
+			if i < len(parts)-1 {
+				part += sep
+			}
+			if current.Len() > 0 && t.CountTokens(current.String()+part) > maxTokens {
+				chunks = append(chunks, current.String())
+				current.Reset()
+			}
+			current.WriteString(part)
+		}
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+		}
+		if len(chunks) < 2 {
+			// Only a trailing separator matched, so try a finer one
+			continue
+		}
+
+		// Split any chunk still too large at a finer boundary
+		var result []string
+		for _, chunk := range chunks {
+			result = append(result, splitChunks(t, chunk, maxTokens)...)
+		}
+		return result
+	}
+
+	// No separators left: split at character boundaries
+	var result []string
+	for text != "" {
+		chunk := truncateTokens(t, text, maxTokens)
+		if chunk == "" {
+			// A single character over the limit still makes progress
+			chunk = tokenizer.TruncateRunes(text, 1)
+		}
+		result = append(result, chunk)
+		text = text[len(chunk):]
+	}
+	return result
+}
+
+// maxBytesPerToken bounds the bytes of text a chunk of n tokens can span.
+// Tokens average a few bytes, so the bound is generous.
+const maxBytesPerToken = 32
+
+// truncateTokens returns the longest prefix of text within maxTokens,
+// searching only the first maxTokens × maxBytesPerToken bytes so that
+// chunking a long text stays linear in its length
+func truncateTokens(t tokenizer.Tokenizer, text string, maxTokens int) string {
+	if maxTokens < len(text)/maxBytesPerToken {
+		window := maxTokens * maxBytesPerToken
+		for window > 0 && !utf8.RuneStart(text[window]) {
+			window--
+		}
+		text = text[:window]
+	}
+	return tokenizer.TruncateTokens(t, text, maxTokens)
+}
+
+// contiguousChunks builds Chunks from texts that concatenate to the document
+func contiguousChunks(t tokenizer.Tokenizer, texts []string) []Chunk {
+	chunks := make([]Chunk, len(texts))
+	offset := 0
+	for i, text := range texts {
+		chunks[i] = newChunk(t, i, offset, text)
+		offset += len(text)
+	}
+	return chunks
+}
+
+// newChunk returns the chunk at index with text found at offset
+func newChunk(t tokenizer.Tokenizer, index, offset int, text string) Chunk {
+	return Chunk{
+		ID:     ChunkID(text),
+		Index:  index,
+		Text:   text,
+		Offset: offset,
+		Tokens: t.CountTokens(text),
+	}
+}
+
+// tokenizerOrDefault returns t, or tokenizer.Default if t is nil
+func tokenizerOrDefault(t tokenizer.Tokenizer) tokenizer.Tokenizer {
+	if t == nil {
+		return tokenizer.Default
+	}
+	return t
+}
@@ -0,0 +1,215 @@
+package textsplit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxTokens int
+		want      int
+	}{
+		{"empty", "   ", 10, 0},
+		{"fits", "Hello world.", 10, 1},
+		{"paragraphs", strings.Repeat("word ", 30) + "\n\n" + strings.Repeat("word ", 30), 40, 2},
+		{"no separators", strings.Repeat("x", 200), 10, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Split(tt.text, tt.maxTokens)
+			if len(chunks) != tt.want {
+				t.Errorf("Expected %d chunks, got %d", tt.want, len(chunks))
+			}
+			if tt.want > 0 && strings.Join(chunks, "") != tt.text {
+				t.Errorf("Expected chunks to concatenate to the original text")
+			}
+			for _, chunk := range chunks {
+				if tokenizer.Estimate(chunk) > tt.maxTokens {
+					t.Errorf("Expected chunk within %d tokens, got %d", tt.maxTokens, tokenizer.Estimate(chunk))
+				}
+			}
+		})
+	}
+}
+
+func TestRecursive(t *testing.T) {
+	text := strings.Repeat("First sentence here. ", 20) + "\n\n" + strings.Repeat("Second part. ", 20)
+	chunks := Recursive{MaxTokens: 30}.Split(text)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %d", len(chunks))
+	}
+
+	var joined strings.Builder
+	for i, chunk := range chunks {
+		if chunk.Index != i || chunk.Offset != joined.Len() || text[chunk.Offset:chunk.Offset+len(chunk.Text)] != chunk.Text {
+			t.Errorf("Chunk %d has the wrong position: %+v", i, chunk)
+		}
+		if chunk.Tokens > 30 || chunk.Tokens != tokenizer.Estimate(chunk.Text) {
+			t.Errorf("Chunk %d has %d tokens", i, chunk.Tokens)
+		}
+		joined.WriteString(chunk.Text)
+	}
+	if joined.String() != text {
+		t.Errorf("Expected chunks to concatenate to the original text")
+	}
+
+	if (Recursive{MaxTokens: 30}).Split("  \n ") != nil {
+		t.Errorf("Expected no chunks for blank text")
+	}
+}
+
+func TestChunkID(t *testing.T) {
+	first := Recursive{MaxTokens: 10}.Split(strings.Repeat("same words ", 10))
+	second := Recursive{MaxTokens: 10}.Split("prefix\n\n" + strings.Repeat("same words ", 10))
+
+	// Identical chunk text has the same ID regardless of position
+	if first[0].ID != ChunkID(first[0].Text) || len(first[0].ID) != 16 {
+		t.Errorf("Expected a 16 digit content hash, got %q", first[0].ID)
+	}
+	if first[0].ID != second[1].ID || first[0].Index == second[1].Index {
+		t.Errorf("Expected the same ID for the same text, got %q and %q", first[0].ID, second[1].ID)
+	}
+	if first[0].ID == ChunkID(first[0].Text+" ") {
+		t.Errorf("Expected different text to have a different ID")
+	}
+}
+
+func TestFixed(t *testing.T) {
+	words := make([]string, 200)
+	for i := range words {
+		words[i] = "word" + strings.Repeat("x", i%5)
+	}
+	text := strings.Join(words, " ")
+
+	chunks := Fixed{MaxTokens: 40, Overlap: 10}.Split(text)
+	if len(chunks) < 5 {
+		t.Fatalf("Expected several chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.Tokens > 40 {
+			t.Errorf("Chunk %d has %d tokens", i, chunk.Tokens)
+		}
+		if text[chunk.Offset:chunk.Offset+len(chunk.Text)] != chunk.Text {
+			t.Errorf("Chunk %d is not at its offset", i)
+		}
+		if i == 0 {
+			continue
+		}
+
+		// Each chunk starts at a word inside the previous one
+		prev := chunks[i-1]
+		if chunk.Offset <= prev.Offset || chunk.Offset >= prev.Offset+len(prev.Text) {
+			t.Errorf("Chunk %d does not overlap the previous one", i)
+		}
+		if text[chunk.Offset-1] != ' ' {
+			t.Errorf("Chunk %d starts mid-word: %q", i, chunk.Text[:10])
+		}
+		if overlap := tokenizer.Estimate(text[chunk.Offset : prev.Offset+len(prev.Text)]); overlap < 10 {
+			t.Errorf("Chunk %d overlaps by %d tokens, want at least 10", i, overlap)
+		}
+	}
+	last := chunks[len(chunks)-1]
+	if last.Offset+len(last.Text) != len(text) {
+		t.Errorf("Expected the last chunk to reach the end of the text")
+	}
+
+	// Without overlap the chunks concatenate to the text
+	var joined strings.Builder
+	for _, chunk := range (Fixed{MaxTokens: 40}).Split(text) {
+		joined.WriteString(chunk.Text)
+	}
+	if joined.String() != text {
+		t.Errorf("Expected chunks without overlap to concatenate to the original text")
+	}
+}
+
+// countingTokenizer estimates tokens, counting the bytes it is asked about
+type countingTokenizer struct {
+	counted int
+}
+
+func (c *countingTokenizer) CountTokens(text string) int {
+	c.counted += len(text)
+	return tokenizer.Estimate(text)
+}
+
+func TestSplit_LongText(t *testing.T) {
+	tests := map[string]struct {
+		splitter func(tokenizer.Tokenizer) Splitter
+		text     string
+	}{
+		"fixed": {
+			splitter: func(t tokenizer.Tokenizer) Splitter { return Fixed{MaxTokens: 100, Overlap: 10, Tokenizer: t} },
+			text:     strings.Repeat("lorem ipsum dolor sit amet ", 40000),
+		},
+		"recursive without separators": {
+			splitter: func(t tokenizer.Tokenizer) Splitter { return Recursive{MaxTokens: 100, Tokenizer: t} },
+			text:     strings.Repeat("abcdefgh", 1<<17),
+		},
+	}
+	for name, tt := range tests {
+		counter := &countingTokenizer{}
+		chunks := tt.splitter(counter).Split(tt.text)
+		if len(chunks) < 100 {
+			t.Fatalf("%s: expected many chunks, got %d", name, len(chunks))
+		}
+		// Searching the whole remaining text for every chunk would count
+		// thousands of times the text
+		if counter.counted > 100*len(tt.text) {
+			t.Errorf("%s: expected token counting linear in the text, counted %d bytes for %d", name, counter.counted, len(tt.text))
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	code := "```go\n" + strings.Repeat("fmt.Println(\"hello\")\n", 5) + "```\n"
+	text := "# Guide\n\nIntro text.\n\n## Install\n\n" + strings.Repeat("Run the installer. ", 10) + "\n\n" +
+		code + "\n## Usage\n\nCall the API.\n"
+
+	chunks := Markdown{MaxTokens: 60}.Split(text)
+
+	var joined strings.Builder
+	for _, chunk := range chunks {
+		joined.WriteString(chunk.Text)
+		if chunk.Tokens > 60 {
+			t.Errorf("Chunk has %d tokens: %q", chunk.Tokens, chunk.Text)
+		}
+		// Code blocks are never split when they fit
+		if strings.Contains(chunk.Text, "```go") && !strings.Contains(chunk.Text, "```\n") {
+			t.Errorf("Expected the code block kept whole, got %q", chunk.Text)
+		}
+	}
+	if joined.String() != text {
+		t.Errorf("Expected chunks to concatenate to the original text")
+	}
+
+	// Sections start new chunks and carry their heading path
+	headings := map[string]string{}
+	for _, chunk := range chunks {
+		if strings.HasPrefix(chunk.Text, "#") {
+			headings[strings.SplitN(chunk.Text, "\n", 2)[0]] = chunk.Heading
+		}
+	}
+	if headings["## Install"] != "Guide > Install" || headings["## Usage"] != "Guide > Usage" || headings["# Guide"] != "Guide" {
+		t.Errorf("Unexpected section chunks: %v", headings)
+	}
+}
+
+func TestMarkdown_LargeCodeBlock(t *testing.T) {
+	code := "```\n" + strings.Repeat("line of code here\n", 40) + "```\n"
+	chunks := Markdown{MaxTokens: 30}.Split("# Code\n\n" + code)
+	for _, chunk := range chunks[1:] {
+		if !strings.HasSuffix(chunk.Text, "\n") {
+			t.Errorf("Expected code split between lines, got %q", chunk.Text)
+		}
+		if chunk.Heading != "Code" {
+			t.Errorf("Expected the Code heading, got %q", chunk.Heading)
+		}
+	}
+}
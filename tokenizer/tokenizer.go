@@ -0,0 +1,127 @@
+// Package tokenizer provides local token count estimation.
+//
+// Providers bill and limit requests by tokens, but exact tokenization depends
+// on the model's vocabulary. This package offers a fast, dependency-free
+// heuristic estimator that is accurate enough for budgeting, cost estimation
+// and context window checks. Exact tokenizers can be plugged in through the
+// Tokenizer interface. The Truncate functions shorten text to a token, rune,
+// byte or UTF-16 limit without splitting characters.
+//
+// Example:
+//
+//	tokens := tokenizer.Estimate("Write a haiku about programming")
+//	chatTokens := tokenizer.EstimateMessages(messages)
+//	prompt = tokenizer.TruncateTokens(tokenizer.Default, prompt, 2000)
+package tokenizer
+
+import (
+	"math"
+	"unicode/utf8"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+const (
+	// DefaultCharsPerToken is the average number of ASCII characters per token
+	// for English text with GPT and Claude style BPE vocabularies
+	DefaultCharsPerToken = 4.0
+
+	// MessageOverhead is the number of tokens added per chat message for role
+	// and formatting markers
+	MessageOverhead = 4
+
+	// ReplyPriming is the number of tokens added once per chat request to prime
+	// the assistant reply
+	ReplyPriming = 3
+
+	// DefaultTokensPerWord is the average number of tokens per English word
+	DefaultTokensPerWord = 4.0 / 3.0
+
+	// lengthHeadroom enlarges token limits derived from word or character
+	// counts so output is usually trimmed rather than cut off mid-word
+	lengthHeadroom = 1.2
+)
+
+// Tokenizer counts the tokens in a piece of text.
+//
+// Implementations must be safe for concurrent use.
+type Tokenizer interface {
+	// CountTokens returns the number of tokens in text
+	CountTokens(text string) int
+}
+
+// Estimator is a heuristic Tokenizer based on character counts.
+//
+// ASCII text is counted at CharsPerToken characters per token, while
+// non-ASCII runes (CJK, emoji, accented letters) are counted as one token
+// each, since BPE vocabularies rarely merge them.
+type Estimator struct {
+	// CharsPerToken is the average number of ASCII characters per token
+	// (default: DefaultCharsPerToken)
+	CharsPerToken float64
+}
+
+// CountTokens estimates the number of tokens in text
+func (e Estimator) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+
+	charsPerToken := e.CharsPerToken
+	if charsPerToken <= 0 {
+		charsPerToken = DefaultCharsPerToken
+	}
+
+	ascii := 0
+	other := 0
+	for i := 0; i < len(text); {
+		if text[i] < utf8.RuneSelf {
+			ascii++
+			i++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		other++
+		i += size
+	}
+
+	return int(math.Ceil(float64(ascii)/charsPerToken)) + other
+}
+
+// Default is the tokenizer used by package-level helpers
+var Default Tokenizer = Estimator{CharsPerToken: DefaultCharsPerToken}
+
+// Estimate returns the estimated number of tokens in text using the Default tokenizer
+func Estimate(text string) int {
+	return Default.CountTokens(text)
+}
+
+// EstimateMessages returns the estimated number of prompt tokens for a chat
+// conversation using the Default tokenizer
+func EstimateMessages(messages []types.Message) int {
+	return CountMessages(Default, messages)
+}
+
+// CountMessages returns the number of prompt tokens for a chat conversation,
+// including per-message formatting overhead
+func CountMessages(t Tokenizer, messages []types.Message) int {
+	if len(messages) == 0 {
+		return 0
+	}
+
+	total := ReplyPriming
+	for _, msg := range messages {
+		total += MessageOverhead + t.CountTokens(msg.Role) + t.CountTokens(msg.Content)
+	}
+	return total
+}
+
+// TokensForWords returns an approximate token limit for generating n words
+func TokensForWords(n int) int {
+	return int(math.Ceil(float64(n) * DefaultTokensPerWord * lengthHeadroom))
+}
+
+// TokensForChars returns an approximate token limit for generating n characters
+func TokensForChars(n int) int {
+	return int(math.Ceil(float64(n) / DefaultCharsPerToken * lengthHeadroom))
+}
@@ -0,0 +1,44 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+func TestEstimatorCountTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"empty", "", 0},
+		{"short ascii", "Hi", 1},
+		{"ascii", "Hello, world!", 4},
+		{"non-ascii runes count individually", "日本語", 3},
+		{"mixed", "abcd é", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Estimator{}).CountTokens(tt.text); got != tt.expected {
+				t.Errorf("CountTokens(%q) = %d, want %d", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCountMessages(t *testing.T) {
+	if got := EstimateMessages(nil); got != 0 {
+		t.Errorf("Expected 0 tokens for empty conversation, got %d", got)
+	}
+
+	messages := []types.Message{
+		{Role: "user", Content: "Hello, world!"},
+	}
+	// priming + overhead + role + content
+	expected := ReplyPriming + MessageOverhead + Estimate("user") + Estimate("Hello, world!")
+	if got := EstimateMessages(messages); got != expected {
+		t.Errorf("EstimateMessages() = %d, want %d", got, expected)
+	}
+}
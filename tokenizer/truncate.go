@@ -0,0 +1,116 @@
+package tokenizer
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// zeroWidthJoiner joins emoji into a single displayed character
+const zeroWidthJoiner = '\u200d'
+
+// TruncateRunes returns the longest prefix of text with at most n runes.
+//
+// Like every Truncate function, it never splits a multi-byte character and
+// does not separate a character from the combining marks, variation
+// selectors or zero width joiners that follow it; such a cluster is dropped
+// whole if it does not fit. Text that already fits is returned unchanged.
+func TruncateRunes(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	count := 0
+	for i := range text {
+		if count == n {
+			return text[:clusterStart(text, i)]
+		}
+		count++
+	}
+	return text
+}
+
+// TruncateBytes returns the longest prefix of text of at most n bytes, for
+// limits measured in UTF-8 bytes.
+func TruncateBytes(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(text) <= n {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:clusterStart(text, n)]
+}
+
+// TruncateUTF16 returns the longest prefix of text of at most n UTF-16 code
+// units, for limits counted the way JavaScript and Java count string length.
+// Characters outside the Basic Multilingual Plane, such as most emoji, take
+// two units and are never split into half a surrogate pair.
+func TruncateUTF16(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	units := 0
+	for i, r := range text {
+		size := 1
+		if r > 0xffff {
+			size = 2
+		}
+		if units+size > n {
+			return text[:clusterStart(text, i)]
+		}
+		units += size
+	}
+	return text
+}
+
+// TruncateTokens returns the longest prefix of text counted by t as at most
+// maxTokens tokens, cut at a character boundary.
+//
+// Prefixes are searched with t.CountTokens, which assumes a longer prefix
+// never has fewer tokens; this holds for Estimator and closely enough for
+// BPE tokenizers.
+func TruncateTokens(t Tokenizer, text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	if t.CountTokens(text) <= maxTokens {
+		return text
+	}
+
+	// Search rune boundaries only, so a cut can never split a character
+	boundaries := make([]int, 0, len(text))
+	for i := range text {
+		boundaries = append(boundaries, i)
+	}
+	n := sort.Search(len(boundaries), func(i int) bool {
+		return t.CountTokens(text[:boundaries[i]]) > maxTokens
+	})
+	if n == 0 {
+		return ""
+	}
+	return text[:clusterStart(text, boundaries[n-1])]
+}
+
+// clusterStart moves the cut at byte offset i of text back to the start of
+// the character cluster the following rune belongs to, so a base character
+// is not kept without its combining marks or joined characters
+func clusterStart(text string, i int) int {
+	for i > 0 && i < len(text) {
+		next, _ := utf8.DecodeRuneInString(text[i:])
+		prev, size := utf8.DecodeLastRuneInString(text[:i])
+		if !extendsCluster(next) && prev != zeroWidthJoiner {
+			break
+		}
+		i -= size
+	}
+	return i
+}
+
+// extendsCluster reports whether r attaches to the character before it
+func extendsCluster(r rune) bool {
+	return r == zeroWidthJoiner || unicode.In(r, unicode.Mn, unicode.Me, unicode.Variation_Selector) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) // Emoji skin tone modifiers
+}
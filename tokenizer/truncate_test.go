@@ -0,0 +1,95 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		n        int
+		expected string
+	}{
+		{"fits", "héllo", 5, "héllo"},
+		{"ascii", "hello world", 5, "hello"},
+		{"multi-byte", "日本語のテキスト", 3, "日本語"},
+		{"combining mark kept with base", "cafe\u0301s", 4, "caf"},
+		{"combining mark fits", "cafe\u0301s", 5, "cafe\u0301"},
+		{"zwj sequence dropped whole", "hi\U0001F469\u200d\U0001F4BB", 4, "hi"},
+		{"skin tone kept with emoji", "ok\U0001F44D\U0001F3FD", 3, "ok"},
+		{"zero", "hello", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TruncateRunes(tt.text, tt.n); got != tt.expected {
+				t.Errorf("TruncateRunes(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		text     string
+		n        int
+		expected string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"日本語", 4, "日"},
+		{"日本語", 6, "日本"},
+		{"añb", 2, "a"},
+	}
+
+	for _, tt := range tests {
+		got := TruncateBytes(tt.text, tt.n)
+		if got != tt.expected || !utf8.ValidString(got) {
+			t.Errorf("TruncateBytes(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestTruncateUTF16(t *testing.T) {
+	tests := []struct {
+		text     string
+		n        int
+		expected string
+	}{
+		{"hello", 5, "hello"},
+		{"ab😀c", 3, "ab"},
+		{"ab😀c", 4, "ab😀"},
+		{"日本語", 2, "日本"},
+	}
+
+	for _, tt := range tests {
+		if got := TruncateUTF16(tt.text, tt.n); got != tt.expected {
+			t.Errorf("TruncateUTF16(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestTruncateTokens(t *testing.T) {
+	text := strings.Repeat("word ", 100)
+	got := TruncateTokens(Default, text, 10)
+	if tokens := Estimate(got); tokens > 10 {
+		t.Errorf("Expected at most 10 tokens, got %d", tokens)
+	}
+	if Estimate(text[:len(got)+1]) <= 10 {
+		t.Errorf("Expected the longest prefix within 10 tokens, got %q", got)
+	}
+
+	// Non-ASCII runes count as a token each and are never split
+	if got := TruncateTokens(Default, "日本語のテキスト", 4); got != "日本語の" {
+		t.Errorf("Expected 4 characters, got %q", got)
+	}
+	if got := TruncateTokens(Default, "short", 10); got != "short" {
+		t.Errorf("Expected text within the limit unchanged, got %q", got)
+	}
+	if got := TruncateTokens(Default, "short", 0); got != "" {
+		t.Errorf("Expected an empty string for no tokens, got %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ajeet-kumar1087/ai-providers/internal/utils"
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// TokenCountMethod identifies how a token count was obtained.
+type TokenCountMethod string
+
+const (
+	// TokenCountProvider is an exact count from the provider's counting endpoint
+	TokenCountProvider TokenCountMethod = "provider"
+
+	// TokenCountEstimate is an estimate from the local tokenizer
+	TokenCountEstimate TokenCountMethod = "estimate"
+)
+
+// TokenCount is the result of CountTokensRemote.
+type TokenCount struct {
+	// InputTokens is the number of input tokens in the request
+	InputTokens int `json:"input_tokens"`
+
+	// Method reports whether the count is exact or estimated
+	Method TokenCountMethod `json:"method"`
+
+	// FallbackError explains why an available provider endpoint was not used (optional)
+	FallbackError string `json:"fallback_error,omitempty"`
+}
+
+// CountTokensRemote counts the input tokens of a chat request.
+//
+// When the provider offers a token counting endpoint (Anthropic's
+// /messages/count_tokens), it is used for an exact count. Otherwise the count
+// is estimated with the local tokenizer, which is also the fallback if the
+// endpoint fails for any reason other than cancellation. Method reports which
+// was used.
+//
+// Example:
+//
+//	count, err := client.CountTokensRemote(ctx, ChatRequest{Messages: messages})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("%d tokens (%s)\n", count.InputTokens, count.Method)
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - req: The chat request to count
+//
+// Returns:
+//   - *TokenCount: The token count and the method used to obtain it
+//   - error: A validation error for invalid requests, or a context error
+func (c *client) CountTokensRemote(ctx context.Context, req ChatRequest) (*TokenCount, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	if err := utils.ValidateChatRequest(req); err != nil {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("request validation failed: %v", err),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	}
+	if req.Model == "" {
+		req.Model = c.config.Model
+	}
+
+	count := &TokenCount{}
+	if counter, ok := c.adapter.(TokenCounter); ok && c.SupportsFeature(FeatureTokenCounting) {
+		tokens, err := counter.CountTokens(ctx, req)
+		if err == nil {
+			count.InputTokens = tokens
+			count.Method = TokenCountProvider
+			return count, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		count.FallbackError = err.Error()
+	}
+
+	count.InputTokens = tokenizer.EstimateMessages(req.Messages)
+	count.Method = TokenCountEstimate
+	return count, nil
+}
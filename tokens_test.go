@@ -0,0 +1,79 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// countingAdapter reports exact token counts
+type countingAdapter struct {
+	mockAdapter
+	tokens int
+	err    error
+	models []string
+}
+
+func (m *countingAdapter) CountTokens(ctx context.Context, req ChatRequest) (int, error) {
+	m.models = append(m.models, req.Model)
+	return m.tokens, m.err
+}
+
+func (m *countingAdapter) SupportedFeatures() []string {
+	return append(m.mockAdapter.SupportedFeatures(), FeatureTokenCounting)
+}
+
+func TestCountTokensRemote(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "How many tokens is this?"}}
+	estimate := tokenizer.EstimateMessages(messages)
+
+	tests := []struct {
+		name         string
+		adapter      ProviderAdapter
+		wantTokens   int
+		wantMethod   TokenCountMethod
+		wantFallback bool
+	}{
+		{"provider count", &countingAdapter{tokens: 11}, 11, TokenCountProvider, false},
+		{"endpoint failure", &countingAdapter{err: errors.New("overloaded")}, estimate, TokenCountEstimate, true},
+		{"no endpoint", &mockAdapter{}, estimate, TokenCountEstimate, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newMockClient(ProviderAnthropic, tt.adapter)
+			c.config.Model = "claude-3-haiku-20240307"
+
+			count, err := c.CountTokensRemote(context.Background(), ChatRequest{Messages: messages})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if count.InputTokens != tt.wantTokens || count.Method != tt.wantMethod {
+				t.Errorf("Expected %d tokens via %s, got %d via %s", tt.wantTokens, tt.wantMethod, count.InputTokens, count.Method)
+			}
+			if (count.FallbackError != "") != tt.wantFallback {
+				t.Errorf("Unexpected fallback error %q", count.FallbackError)
+			}
+			if counter, ok := tt.adapter.(*countingAdapter); ok && counter.models[0] != "claude-3-haiku-20240307" {
+				t.Errorf("Expected config model to be counted against, got %q", counter.models[0])
+			}
+		})
+	}
+}
+
+func TestCountTokensRemote_Errors(t *testing.T) {
+	c := newMockClient(ProviderAnthropic, &countingAdapter{err: errors.New("cancelled")})
+
+	if _, err := c.CountTokensRemote(context.Background(), ChatRequest{}); err == nil {
+		t.Errorf("Expected validation error for empty request")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.CountTokensRemote(ctx, ChatRequest{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context error, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package aiprovider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ajeet-kumar1087/ai-providers/audiosplit"
+)
+
+// transcriptionPromptLength is the number of bytes of the previous
+// chunk's transcript given as the prompt of the next, so words and style
+// carry over the cut
+const transcriptionPromptLength = 200
+
+// Transcribe converts speech to text.
+//
+// Recordings larger than the provider's upload limit, or longer than
+// req.ChunkDuration, are split at quiet moments with the audiosplit package
+// and the chunks transcribed in turn, each prompted with req.Prompt and the
+// end of the previous transcript. The transcripts are joined and the
+// timestamps of their segments shifted by the offset of their chunk, so the
+// response reads as one transcript of the whole recording. The transcription
+// is reported to the UsageRecorder as one request without tokens, and joins
+// the cancellation group of ctx.
+//
+// Example:
+//
+//	file, err := os.Open("meeting.wav")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer file.Close()
+//	transcript, err := client.Transcribe(ctx, TranscriptionRequest{Audio: file, FileName: "meeting.wav"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, segment := range transcript.Segments {
+//		fmt.Printf("[%v] %s\n", segment.Start, segment.Text)
+//	}
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - req: The recording and optional model, language, prompt and chunk duration
+//
+// Returns:
+//   - *TranscriptionResponse: The transcript with its timed segments
+//   - error: A validation error if the request is invalid, transcription is unsupported or the recording cannot be split, or a provider error
+func (c *client) Transcribe(ctx context.Context, req TranscriptionRequest) (*TranscriptionResponse, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+	ctx, leave := c.joinGroup(ctx)
+	defer leave()
+
+	if err := c.requireFeatures(FeatureTranscription); err != nil {
+		return nil, err
+	}
+	transcriber, ok := c.adapter.(TranscriptionAdapter)
+	if !ok {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("feature %q not supported by provider %s", FeatureTranscription, c.provider),
+			Provider: string(c.provider),
+		}
+	}
+
+	if req.Audio == nil || strings.TrimSpace(req.FileName) == "" {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "transcription requires audio and a file name",
+			Provider: string(c.provider),
+		}
+	}
+	if req.ChunkDuration < 0 {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("chunk duration must not be negative, got: %v", req.ChunkDuration),
+			Provider: string(c.provider),
+		}
+	}
+
+	ctx, err = c.withProject(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkEndpoint(ctx); err != nil {
+		return nil, err
+	}
+
+	chunks, err := c.transcriptionChunks(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *TranscriptionResponse
+	start := time.Now()
+	for _, chunk := range chunks {
+		if err := c.checkBudget(ctx); err != nil {
+			return nil, err
+		}
+		if err := c.waitRateLimit(ctx); err != nil {
+			return nil, groupError(ctx, err)
+		}
+
+		chunkReq := req
+		chunkReq.Audio = chunk.Audio
+		if result != nil {
+			chunkReq.Prompt = strings.TrimSpace(req.Prompt + " " + transcriptTail(result.Text, transcriptionPromptLength))
+		}
+		resp, err := transcriber.Transcribe(ctx, chunkReq)
+		if err != nil {
+			return nil, groupError(ctx, c.sanitizeError(err, []string{req.Prompt}))
+		}
+		result = stitchTranscript(result, resp, chunk)
+	}
+	result.Chunks = len(chunks)
+	if result.Metadata.Model == "" {
+		result.Metadata.Model = req.Model
+	}
+
+	// Transcriptions report no tokens, but count as requests with their
+	// latency and retries
+	c.observeResponse(result.Metadata, Usage{}, time.Since(start))
+	return result, nil
+}
+
+// transcriptionChunks returns the chunks a recording is transcribed in: the
+// whole recording if it is within the upload limit and not to be split by
+// duration, otherwise the chunks of audiosplit.Split
+func (c *client) transcriptionChunks(req TranscriptionRequest) ([]audiosplit.Chunk, error) {
+	audio, size, err := readerAt(req.Audio)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+	whole := []audiosplit.Chunk{{Audio: io.NewSectionReader(audio, 0, size)}}
+	if size <= audiosplit.DefaultMaxBytes && req.ChunkDuration == 0 {
+		return whole, nil
+	}
+
+	chunks, err := audiosplit.Split(audio, size, audiosplit.Options{MaxDuration: req.ChunkDuration})
+	switch {
+	case errors.Is(err, audiosplit.ErrUnsupportedFormat) && size <= audiosplit.DefaultMaxBytes:
+		// Only the duration asked for a split, so send it whole
+		return whole, nil
+	case errors.Is(err, audiosplit.ErrUnsupportedFormat):
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("recording of %d bytes exceeds the %d byte upload limit and is not a PCM WAV file that can be split", size, audiosplit.DefaultMaxBytes),
+			Provider: string(c.provider),
+			Wrapped:  err,
+		}
+	case err != nil:
+		return nil, err
+	case len(chunks) == 0:
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "recording has no audio",
+			Provider: string(c.provider),
+		}
+	}
+	return chunks, nil
+}
+
+// readerAt returns random access to the rest of r and its size, reading it
+// into memory unless it can seek
+func readerAt(r io.Reader) (io.ReaderAt, int64, error) {
+	if file, ok := r.(interface {
+		io.ReaderAt
+		io.Seeker
+	}); ok {
+		start, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, 0, err
+		}
+		end, err := file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, err := file.Seek(start, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+		return io.NewSectionReader(file, start, end-start), end - start, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+// stitchTranscript appends the transcript of a chunk to those of the chunks
+// before it, shifting its segments by the chunk's offset
+func stitchTranscript(result, resp *TranscriptionResponse, chunk audiosplit.Chunk) *TranscriptionResponse {
+	for i := range resp.Segments {
+		resp.Segments[i].Start += chunk.Offset
+		resp.Segments[i].End += chunk.Offset
+	}
+	if result == nil {
+		resp.Text = strings.TrimSpace(resp.Text)
+		if resp.Duration == 0 {
+			resp.Duration = chunk.Duration
+		}
+		return resp
+	}
+
+	text := strings.TrimSpace(resp.Text)
+	if result.Text != "" && text != "" {
+		result.Text += " " + text
+	} else if text != "" {
+		result.Text = text
+	}
+	if result.Language == "" {
+		result.Language = resp.Language
+	}
+	result.Duration = chunk.Offset + chunk.Duration
+	result.Segments = append(result.Segments, resp.Segments...)
+	result.Metadata.Attempts += resp.Metadata.Attempts
+	result.Metadata.RetryWait += resp.Metadata.RetryWait
+	return result
+}
+
+// transcriptTail returns the last n bytes of a transcript, widened to start
+// at a character boundary and then cut to start at a word boundary
+func transcriptTail(text string, n int) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= n {
+		return text
+	}
+	start := len(text) - n
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	tail := text[start:]
+	if i := strings.IndexByte(tail, ' '); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return tail
+}
@@ -0,0 +1,175 @@
+package aiprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// transcriptionAdapter transcribes every recording as "part N", with one
+// segment a second long
+type transcriptionAdapter struct {
+	mockAdapter
+	requests []TranscriptionRequest
+	sizes    []int
+}
+
+func (a *transcriptionAdapter) Transcribe(ctx context.Context, req TranscriptionRequest) (*TranscriptionResponse, error) {
+	audio, err := io.ReadAll(req.Audio)
+	if err != nil {
+		return nil, err
+	}
+	a.requests = append(a.requests, req)
+	a.sizes = append(a.sizes, len(audio))
+	text := fmt.Sprintf("part %d", len(a.requests))
+	return &TranscriptionResponse{
+		Text:     " " + text,
+		Language: "english",
+		Segments: []TranscriptionSegment{{Start: 0, End: time.Second, Text: text}},
+	}, nil
+}
+
+func (a *transcriptionAdapter) SupportedFeatures() []string {
+	return append(a.mockAdapter.SupportedFeatures(), FeatureTranscription)
+}
+
+// silentWAV returns a 16-bit mono 8 kHz WAV file of silence lasting d
+func silentWAV(d time.Duration) []byte {
+	size := int(d.Seconds() * 8000 * 2)
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+size))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, []uint32{16})
+	binary.Write(&buf, binary.LittleEndian, []uint16{1, 1})
+	binary.Write(&buf, binary.LittleEndian, []uint32{8000, 16000})
+	binary.Write(&buf, binary.LittleEndian, []uint16{2, 16})
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(size))
+	buf.Write(make([]byte, size))
+	return buf.Bytes()
+}
+
+func TestTranscribe_Chunks(t *testing.T) {
+	adapter := &transcriptionAdapter{}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	result, err := c.Transcribe(context.Background(), TranscriptionRequest{
+		Audio:         bytes.NewReader(silentWAV(10 * time.Second)),
+		FileName:      "meeting.wav",
+		Prompt:        "Kenobi",
+		ChunkDuration: 4 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Chunks != 3 || len(adapter.requests) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d with %d requests", result.Chunks, len(adapter.requests))
+	}
+	if result.Text != "part 1 part 2 part 3" || result.Language != "english" || result.Duration != 10*time.Second {
+		t.Errorf("Expected the stitched transcript, got %+v", result)
+	}
+
+	// Segments are shifted by the duration of the chunks before them
+	var offset time.Duration
+	for i, segment := range result.Segments {
+		if segment.Start != offset || segment.End != offset+time.Second {
+			t.Errorf("Segment %d: expected to start at %v, got %+v", i, offset, segment)
+		}
+		offset += time.Duration(adapter.sizes[i]-44) * time.Second / 16000
+	}
+
+	if adapter.requests[0].Prompt != "Kenobi" || adapter.requests[1].Prompt != "Kenobi part 1" || adapter.requests[2].Prompt != "Kenobi part 1 part 2" {
+		t.Errorf("Expected chunks to be prompted with the previous transcript, got %q, %q and %q",
+			adapter.requests[0].Prompt, adapter.requests[1].Prompt, adapter.requests[2].Prompt)
+	}
+}
+
+func TestTranscribe_Whole(t *testing.T) {
+	adapter := &transcriptionAdapter{}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	// Recordings that cannot be split are sent whole while within the limit
+	result, err := c.Transcribe(context.Background(), TranscriptionRequest{
+		Audio:         strings.NewReader("ID3 mp3 audio"),
+		FileName:      "memo.mp3",
+		ChunkDuration: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Chunks != 1 || result.Text != "part 1" || adapter.sizes[0] != len("ID3 mp3 audio") {
+		t.Errorf("Expected the recording to be sent whole, got %+v", result)
+	}
+
+	if _, err := c.Transcribe(context.Background(), TranscriptionRequest{Audio: strings.NewReader("audio")}); err == nil {
+		t.Error("Expected an error for a recording without a file name")
+	}
+	c = newMockClient(ProviderAnthropic, &mockAdapter{})
+	if _, err := c.Transcribe(context.Background(), TranscriptionRequest{Audio: strings.NewReader("audio"), FileName: "memo.mp3"}); err == nil {
+		t.Error("Expected an error for a provider without transcription")
+	}
+}
+
+func TestTranscriptTail(t *testing.T) {
+	// Multi-byte characters are never cut, even without a space to start at
+	text := strings.Repeat("日本語", 10)
+	tail := transcriptTail(text, 10)
+	if !utf8.ValidString(tail) || !strings.HasSuffix(text, tail) || len(tail) < 10 {
+		t.Errorf("Expected a valid tail of at least 10 bytes, got %q", tail)
+	}
+	if got := transcriptTail("the quick brown fox", 8); got != "fox" {
+		t.Errorf("Expected the tail to start at a word, got %q", got)
+	}
+}
+
+// blockingTranscriber blocks transcriptions until their context is done
+type blockingTranscriber struct {
+	transcriptionAdapter
+	started chan struct{}
+}
+
+func (b *blockingTranscriber) Transcribe(ctx context.Context, req TranscriptionRequest) (*TranscriptionResponse, error) {
+	b.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestTranscribe_UsageAndGroups(t *testing.T) {
+	adapter := &transcriptionAdapter{}
+	c := newMockClient(ProviderOpenAI, adapter)
+	recorder := &recordingUsageRecorder{}
+	c.config.UsageRecorder = recorder
+
+	req := TranscriptionRequest{Audio: strings.NewReader("ID3 mp3 audio"), FileName: "memo.mp3", Model: "whisper-1"}
+	if _, err := c.Transcribe(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recorder.records) != 1 || recorder.records[0].Model != "whisper-1" || recorder.records[0].Provider != ProviderOpenAI {
+		t.Errorf("Expected one usage record for the transcription, got %+v", recorder.records)
+	}
+
+	// Transcriptions join the cancellation group of their context
+	blocking := &blockingTranscriber{started: make(chan struct{})}
+	c = newMockClient(ProviderOpenAI, blocking)
+	errs := make(chan error, 1)
+	go func() {
+		req.Audio = strings.NewReader("ID3 mp3 audio")
+		_, err := c.Transcribe(WithCancelGroup(context.Background(), "user-a"), req)
+		errs <- err
+	}()
+	<-blocking.started
+	if n := c.CancelGroup("user-a"); n != 1 {
+		t.Errorf("Expected 1 cancelled request, got %d", n)
+	}
+	if err := <-errs; !errors.Is(err, ErrGroupCancelled) {
+		t.Errorf("Expected a group cancellation error, got %v", err)
+	}
+}
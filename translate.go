@@ -0,0 +1,258 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ajeet-kumar1087/ai-providers/textsplit"
+)
+
+const (
+	// DefaultTranslateChunkTokens is the default maximum size of a chunk translated in one request
+	DefaultTranslateChunkTokens = 1500
+
+	// DefaultTranslateConcurrency is the default number of chunks translated in parallel
+	DefaultTranslateConcurrency = 4
+
+	// languageSampleChars is the amount of text used for language detection
+	languageSampleChars = 1000
+)
+
+// TranslateOptions configures Translate.
+type TranslateOptions struct {
+	// SourceLanguage is the language of the input (default: detected automatically)
+	SourceLanguage string
+
+	// Instructions adds guidance such as "Use formal register" (optional)
+	Instructions string
+
+	// Model overrides the provider default model (optional)
+	Model string
+
+	// MaxChunkTokens is the maximum estimated size of each chunk (default: 1500)
+	MaxChunkTokens int
+
+	// Concurrency is the number of chunks translated in parallel (default: 4)
+	Concurrency int
+}
+
+// TranslationResult is the result of Translate.
+type TranslationResult struct {
+	// Text is the translated text
+	Text string `json:"text"`
+
+	// SourceLanguage is the given or detected source language
+	SourceLanguage string `json:"source_language"`
+
+	// TargetLanguage is the language translated to
+	TargetLanguage string `json:"target_language"`
+
+	// Detected reports whether SourceLanguage was detected automatically
+	Detected bool `json:"detected"`
+
+	// Chunks is the number of chunks the text was translated in
+	// It is zero when the text was already in the target language
+	Chunks int `json:"chunks"`
+
+	// Usage is the total token usage, including language detection
+	Usage Usage `json:"usage"`
+}
+
+// Translate translates text into targetLang.
+//
+// If opts.SourceLanguage is empty the source language is detected first, and
+// text already in the target language is returned unchanged. Long documents
+// are translated in chunks split at paragraph boundaries, preserving the
+// original whitespace between chunks. Fenced code blocks, inline code and
+// HTML/XML tags are replaced with placeholders before translation and restored
+// afterwards, so code and markup come back byte for byte.
+//
+// Example:
+//
+//	result, err := client.Translate(ctx, readme, "German", TranslateOptions{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("Translated from %s:\n%s\n", result.SourceLanguage, result.Text)
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - text: The text to translate
+//   - targetLang: The language to translate into, e.g. "French"
+//   - opts: Source language, chunking and model options
+//
+// Returns:
+//   - *TranslationResult: The translated text and detected source language
+//   - error: A validation error for invalid input, or the first request error
+func (c *client) Translate(ctx context.Context, text, targetLang string, opts TranslateOptions) (*TranslationResult, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	if strings.TrimSpace(text) == "" || strings.TrimSpace(targetLang) == "" {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  "text and target language cannot be empty",
+			Provider: string(c.provider),
+		}
+	}
+
+	result := &TranslationResult{
+		SourceLanguage: opts.SourceLanguage,
+		TargetLanguage: targetLang,
+	}
+
+	if result.SourceLanguage == "" {
+		language, usage, err := c.detectLanguage(ctx, text, opts.Model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect language: %w", err)
+		}
+		result.SourceLanguage = language
+		result.Detected = true
+		result.Usage = usage
+
+		if normalizeLabel(language) == normalizeLabel(targetLang) {
+			result.Text = text
+			return result, nil
+		}
+	}
+
+	protected, blocks := protectMarkup(text)
+
+	maxChunkTokens := opts.MaxChunkTokens
+	if maxChunkTokens <= 0 {
+		maxChunkTokens = DefaultTranslateChunkTokens
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultTranslateConcurrency
+	}
+
+	// Translate only the content of each chunk and keep its surrounding whitespace
+	chunks := textsplit.Split(protected, maxChunkTokens)
+	instruction := translateInstruction(result.SourceLanguage, targetLang, opts.Instructions)
+	var reqs []helperRequest
+	for _, chunk := range chunks {
+		if strings.TrimSpace(chunk) == "" {
+			continue
+		}
+		reqs = append(reqs, helperRequest{
+			system: "You are a professional translator. Translate faithfully, keep the original formatting, " +
+				"and copy placeholders such as ⟦0⟧ unchanged.",
+			user:  instruction + "\n\n<text>\n" + strings.TrimSpace(chunk) + "\n</text>",
+			model: opts.Model,
+		})
+	}
+
+	generations, err := c.generateAll(ctx, reqs, concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate: %w", err)
+	}
+
+	var b strings.Builder
+	next := 0
+	for _, chunk := range chunks {
+		if strings.TrimSpace(chunk) == "" {
+			b.WriteString(chunk)
+			continue
+		}
+		gen := generations[next]
+		next++
+
+		leading := chunk[:len(chunk)-len(strings.TrimLeftFunc(chunk, unicode.IsSpace))]
+		trailing := chunk[len(strings.TrimRightFunc(chunk, unicode.IsSpace)):]
+		b.WriteString(leading + strings.TrimSpace(gen.text) + trailing)
+		result.Usage = addUsage(result.Usage, gen.usage)
+	}
+
+	translated, err := restoreMarkup(b.String(), blocks)
+	if err != nil {
+		return nil, &Error{
+			Type:     ErrorTypeProvider,
+			Message:  err.Error(),
+			Provider: string(c.provider),
+		}
+	}
+
+	result.Text = translated
+	result.Chunks = len(reqs)
+	return result, nil
+}
+
+// detectLanguage asks the model for the language of a sample of text
+func (c *client) detectLanguage(ctx context.Context, text, model string) (string, Usage, error) {
+	sample := text
+	if runes := []rune(text); len(runes) > languageSampleChars {
+		sample = string(runes[:languageSampleChars])
+	}
+
+	temperature := 0.0
+	maxTokens := 10
+	gen, err := c.generate(ctx, helperRequest{
+		system:      "You identify the language of text. Respond with the English name of the language only, e.g. \"Spanish\".",
+		user:        "<text>\n" + sample + "\n</text>",
+		model:       model,
+		temperature: &temperature,
+		maxTokens:   &maxTokens,
+	})
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	language := strings.TrimSpace(strings.Trim(strings.TrimSpace(gen.text), ".\"'"))
+	if language == "" {
+		return "", gen.usage, fmt.Errorf("model returned no language")
+	}
+	return language, gen.usage, nil
+}
+
+// translateInstruction builds the translation instruction
+func translateInstruction(source, target, instructions string) string {
+	instruction := fmt.Sprintf("Translate the text below from %s to %s.", source, target)
+	if instructions != "" {
+		instruction += " " + instructions
+	}
+	return instruction + " Keep Markdown formatting and placeholders such as ⟦0⟧ unchanged. Respond with the translation only."
+}
+
+// markupPattern matches fenced code blocks, inline code and HTML/XML tags
+var markupPattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]+`|</?[A-Za-z][^<>]*>")
+
+// placeholderPattern matches placeholders inserted by protectMarkup
+var placeholderPattern = regexp.MustCompile(`⟦(\d+)⟧`)
+
+// protectMarkup replaces code and markup with numbered placeholders
+func protectMarkup(text string) (string, []string) {
+	var blocks []string
+	protected := markupPattern.ReplaceAllStringFunc(text, func(match string) string {
+		blocks = append(blocks, match)
+		return "⟦" + strconv.Itoa(len(blocks)-1) + "⟧"
+	})
+	return protected, blocks
+}
+
+// restoreMarkup puts protected blocks back, failing if the model dropped any
+func restoreMarkup(text string, blocks []string) (string, error) {
+	found := make([]bool, len(blocks))
+	restored := placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		index, err := strconv.Atoi(placeholderPattern.FindStringSubmatch(match)[1])
+		if err != nil || index >= len(blocks) {
+			return match
+		}
+		found[index] = true
+		return blocks[index]
+	})
+
+	for i, ok := range found {
+		if !ok {
+			return "", fmt.Errorf("translation dropped protected block %d: %q", i, blocks[i])
+		}
+	}
+	return restored, nil
+}
@@ -0,0 +1,93 @@
+package aiprovider
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// upperTranslator detects "English" and "translates" text inside <text> tags
+// by upper-casing it, leaving placeholders intact
+func upperTranslator(user string) (string, error) {
+	if !strings.Contains(user, "Translate") {
+		return "English", nil
+	}
+	start := strings.Index(user, "<text>\n") + len("<text>\n")
+	end := strings.LastIndex(user, "\n</text>")
+	return strings.ToUpper(user[start:end]), nil
+}
+
+func TestTranslate(t *testing.T) {
+	adapter := &scriptedAdapter{reply: upperTranslator}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	text := "Run `go test` first.\n\n```go\nfmt.Println(\"hi\")\n```\n\nSee <a href=\"/docs\">the docs</a>."
+	result, err := c.Translate(context.Background(), text, "French", TranslateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "RUN `go test` FIRST.\n\n```go\nfmt.Println(\"hi\")\n```\n\nSEE <a href=\"/docs\">THE DOCS</a>."
+	if result.Text != want {
+		t.Errorf("Expected %q, got %q", want, result.Text)
+	}
+	if result.SourceLanguage != "English" || !result.Detected {
+		t.Errorf("Expected detected English, got %q (detected %v)", result.SourceLanguage, result.Detected)
+	}
+	if len(adapter.requests) != 2 || result.Chunks != 1 {
+		t.Errorf("Expected detection and 1 translation request, got %d requests and %d chunks", len(adapter.requests), result.Chunks)
+	}
+	if result.Usage.TotalTokens != 30 {
+		t.Errorf("Expected usage to include detection, got %d tokens", result.Usage.TotalTokens)
+	}
+}
+
+func TestTranslate_Chunking(t *testing.T) {
+	adapter := &scriptedAdapter{reply: upperTranslator}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	text := strings.Repeat("one ", 40) + "\n\n" + strings.Repeat("two ", 40) + "\n\n" + strings.Repeat("three ", 40)
+
+	result, err := c.Translate(context.Background(), text, "German", TranslateOptions{SourceLanguage: "English", MaxChunkTokens: 60})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Detected || result.Chunks != 3 {
+		t.Errorf("Expected 3 chunks without detection, got %d (detected %v)", result.Chunks, result.Detected)
+	}
+	if result.Text != strings.ToUpper(text) {
+		t.Errorf("Expected paragraph structure to be preserved, got %q", result.Text)
+	}
+}
+
+func TestTranslate_SameLanguage(t *testing.T) {
+	adapter := &scriptedAdapter{reply: upperTranslator}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	result, err := c.Translate(context.Background(), "Hello there", "english", TranslateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Text != "Hello there" || result.Chunks != 0 || len(adapter.requests) != 1 {
+		t.Errorf("Expected text returned unchanged after detection, got %+v", result)
+	}
+}
+
+func TestTranslate_DroppedPlaceholder(t *testing.T) {
+	adapter := &scriptedAdapter{reply: func(string) (string, error) { return "Texte traduit", nil }}
+	c := newMockClient(ProviderAnthropic, adapter)
+
+	_, err := c.Translate(context.Background(), "Use `make`", "French", TranslateOptions{SourceLanguage: "English"})
+	if err == nil || !strings.Contains(err.Error(), "dropped protected block") {
+		t.Errorf("Expected dropped placeholder error, got %v", err)
+	}
+}
+
+func TestTranslate_Validation(t *testing.T) {
+	c := newMockClient(ProviderAnthropic, &scriptedAdapter{})
+
+	if _, err := c.Translate(context.Background(), "Hello", " ", TranslateOptions{}); err == nil {
+		t.Errorf("Expected error for empty target language")
+	}
+}
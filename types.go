@@ -32,6 +32,242 @@ type Message = types.Message
 // See types.Usage for detailed documentation.
 type Usage = types.Usage
 
+// ResponseMetadata contains provider-reported details about a response.
+// See types.ResponseMetadata for detailed documentation.
+type ResponseMetadata = types.ResponseMetadata
+
+// RateLimitStatus represents the rate limit state reported by a provider.
+// See types.RateLimitStatus for detailed documentation.
+type RateLimitStatus = types.RateLimitStatus
+
+// Grammar constrains generated text to a GBNF grammar or JSON schema.
+// See types.Grammar for detailed documentation.
+type Grammar = types.Grammar
+
+// UsageMismatch describes a reported token count diverging from the local count.
+// See types.UsageMismatch for detailed documentation.
+type UsageMismatch = types.UsageMismatch
+
+// Limits describes the known rate limits and quotas of an account.
+// See types.Limits for detailed documentation.
+type Limits = types.Limits
+
+// Quota is a provider account quota.
+// See types.Quota for detailed documentation.
+type Quota = types.Quota
+
+// UsageQuery selects the provider-side usage read by a UsageClient.
+// See types.UsageQuery for detailed documentation.
+type UsageQuery = types.UsageQuery
+
+// ProviderUsage is usage or cost reported by a provider's usage API.
+// See types.ProviderUsage for detailed documentation.
+type ProviderUsage = types.ProviderUsage
+
+// UsageRecord describes the token consumption of a single successful request.
+// See types.UsageRecord for detailed documentation.
+type UsageRecord = types.UsageRecord
+
+// UsageRecorder receives usage records for completed requests.
+// See types.UsageRecorder for detailed documentation.
+type UsageRecorder = types.UsageRecorder
+
+// InteractionRecord is a persisted prompt/response pair.
+// See types.InteractionRecord for detailed documentation.
+type InteractionRecord = types.InteractionRecord
+
+// InteractionStore persists prompts and responses.
+// See types.InteractionStore for detailed documentation.
+type InteractionStore = types.InteractionStore
+
+// ResponseCache stores serialized responses for Config.Cache.
+// See types.ResponseCache for detailed documentation.
+type ResponseCache = types.ResponseCache
+
+// CacheLocker prevents cache stampedes across replicas sharing a ResponseCache.
+// See types.CacheLocker for detailed documentation.
+type CacheLocker = types.CacheLocker
+
+// RateLimiterStore holds the token buckets that enforce Config.RequestsPerMinute.
+// See types.RateLimiterStore for detailed documentation.
+type RateLimiterStore = types.RateLimiterStore
+
+// PromptVersion is one version of a named prompt template.
+// See types.PromptVersion for detailed documentation.
+type PromptVersion = types.PromptVersion
+
+// PromptRef identifies the prompt version a request was sent with.
+// See types.PromptRef for detailed documentation.
+type PromptRef = types.PromptRef
+
+// PromptStore looks up versioned prompts.
+// See types.PromptStore for detailed documentation.
+type PromptStore = types.PromptStore
+
+// UnsupportedParameterPolicy controls how unsupported request parameters are handled.
+// See types.UnsupportedParameterPolicy for detailed documentation.
+type UnsupportedParameterPolicy = types.UnsupportedParameterPolicy
+
+// ErrorSanitizationPolicy controls how prompt echoes in provider errors are handled.
+// See types.ErrorSanitizationPolicy for detailed documentation.
+type ErrorSanitizationPolicy = types.ErrorSanitizationPolicy
+
+// UnsupportedParameter describes a request parameter the provider does not support.
+// See types.UnsupportedParameter for detailed documentation.
+type UnsupportedParameter = types.UnsupportedParameter
+
+// ValidationMode controls how out-of-range request parameters are handled.
+// See types.ValidationMode for detailed documentation.
+type ValidationMode = types.ValidationMode
+
+// OutOfRangeParameter describes a request parameter outside the provider's range.
+// See types.OutOfRangeParameter for detailed documentation.
+type OutOfRangeParameter = types.OutOfRangeParameter
+
+// Warning describes an adjustment made to a request before it was sent.
+// See types.Warning for detailed documentation.
+type Warning = types.Warning
+
+// Endpoint overrides the base URL and headers of requests made with a context.
+// See types.Endpoint for detailed documentation.
+type Endpoint = types.Endpoint
+
+// Flusher is a UsageRecorder or InteractionStore flushed by Close.
+// See types.Flusher for detailed documentation.
+type Flusher = types.Flusher
+
+// ModelDeprecation describes a request for a deprecated model.
+// See types.ModelDeprecation for detailed documentation.
+type ModelDeprecation = types.ModelDeprecation
+
+// DowngradeEvent reports a quota downgrade starting or ending.
+// See types.DowngradeEvent for detailed documentation.
+type DowngradeEvent = types.DowngradeEvent
+
+// RequestMapping is the provider-specific request built for a generic request.
+// See types.RequestMapping for detailed documentation.
+type RequestMapping = types.RequestMapping
+
+// MappingChange describes how a request parameter changed during mapping.
+// See types.MappingChange for detailed documentation.
+type MappingChange = types.MappingChange
+
+// RequestProfile is a named bundle of request defaults for a use case.
+// See types.RequestProfile for detailed documentation.
+type RequestProfile = types.RequestProfile
+
+// DebugEntry is one HTTP exchange with a provider logged with Config.DebugPayloads.
+// See types.DebugEntry for detailed documentation.
+type DebugEntry = types.DebugEntry
+
+// Experiment routes a percentage of traffic to alternative models or prompts.
+// See types.Experiment for detailed documentation.
+type Experiment = types.Experiment
+
+// ExperimentVariant is one treatment arm of an Experiment.
+// See types.ExperimentVariant for detailed documentation.
+type ExperimentVariant = types.ExperimentVariant
+
+// FlagProvider evaluates feature flags for experiments.
+// See types.FlagProvider for detailed documentation.
+type FlagProvider = types.FlagProvider
+
+// FlagFunc adapts a function to a FlagProvider.
+// See types.FlagFunc for detailed documentation.
+type FlagFunc = types.FlagFunc
+
+// RateLimitReleaser is implemented by rate limiter stores that can return unused tokens.
+// See types.RateLimitReleaser for detailed documentation.
+type RateLimitReleaser = types.RateLimitReleaser
+
+// BudgetGuard is implemented by usage recorders that cap spend.
+// See types.BudgetGuard for detailed documentation.
+type BudgetGuard = types.BudgetGuard
+
+// BudgetExceededError is returned for requests rejected by a spend limit.
+// See types.BudgetExceededError for detailed documentation.
+type BudgetExceededError = types.BudgetExceededError
+
+// FlagSubject describes the request a feature flag is evaluated for.
+// See types.FlagSubject for detailed documentation.
+type FlagSubject = types.FlagSubject
+
+// StreamChunk is an incremental part of a streamed chat response.
+// See types.StreamChunk for detailed documentation.
+type StreamChunk = types.StreamChunk
+
+// StreamReader reads the chunks of a streamed response.
+// See types.StreamReader for detailed documentation.
+type StreamReader = types.StreamReader
+
+// SpeechRequest represents a text-to-speech request.
+// See types.SpeechRequest for detailed documentation.
+type SpeechRequest = types.SpeechRequest
+
+// BinaryResponse is a binary response body streamed from the provider.
+// See types.BinaryResponse for detailed documentation.
+type BinaryResponse = types.BinaryResponse
+
+// TranscriptionRequest represents a speech-to-text request.
+// See types.TranscriptionRequest for detailed documentation.
+type TranscriptionRequest = types.TranscriptionRequest
+
+// TranscriptionSegment is a timed piece of a transcript.
+// See types.TranscriptionSegment for detailed documentation.
+type TranscriptionSegment = types.TranscriptionSegment
+
+// TranscriptionResponse represents the transcript of a recording.
+// See types.TranscriptionResponse for detailed documentation.
+type TranscriptionResponse = types.TranscriptionResponse
+
+// RealtimeRequest configures a realtime speech-to-speech session.
+// See types.RealtimeRequest for detailed documentation.
+type RealtimeRequest = types.RealtimeRequest
+
+// RealtimeTool is a function a realtime session's model may call.
+// See types.RealtimeTool for detailed documentation.
+type RealtimeTool = types.RealtimeTool
+
+// RealtimeToolCall is a function call made by a realtime session's model.
+// See types.RealtimeToolCall for detailed documentation.
+type RealtimeToolCall = types.RealtimeToolCall
+
+// RealtimeEvent is an event received from a realtime session.
+// See types.RealtimeEvent for detailed documentation.
+type RealtimeEvent = types.RealtimeEvent
+
+// RealtimeSession is a realtime speech-to-speech session.
+// See types.RealtimeSession for detailed documentation.
+type RealtimeSession = types.RealtimeSession
+
+// BatchItem is one request of a batch submitted for asynchronous processing.
+// See types.BatchItem for detailed documentation.
+type BatchItem = types.BatchItem
+
+// BatchStatus is the processing state of a batch.
+// See types.BatchStatus for detailed documentation.
+type BatchStatus = types.BatchStatus
+
+// BatchCounts counts the requests of a batch by state.
+// See types.BatchCounts for detailed documentation.
+type BatchCounts = types.BatchCounts
+
+// Batch is a batch of requests processed asynchronously by the provider.
+// See types.Batch for detailed documentation.
+type Batch = types.Batch
+
+// BatchResult is the outcome of one item of an ended batch.
+// See types.BatchResult for detailed documentation.
+type BatchResult = types.BatchResult
+
+// HedgeInfo describes a request raced against a hedge request.
+// See types.HedgeInfo for detailed documentation.
+type HedgeInfo = types.HedgeInfo
+
+// InjectionFinding describes suspicious instructions found in untrusted content.
+// See types.InjectionFinding for detailed documentation.
+type InjectionFinding = types.InjectionFinding
+
 // ProviderType represents the type of AI provider.
 // See types.ProviderType for detailed documentation.
 type ProviderType = types.ProviderType
@@ -52,3 +288,111 @@ const (
 	// ProviderGoogle represents the Google AI provider (Gemini models).
 	ProviderGoogle = types.ProviderGoogle
 )
+
+// Re-export feature identifiers for convenient access.
+// See types.FeatureCompletion for detailed documentation.
+const (
+	FeatureCompletion      = types.FeatureCompletion
+	FeatureChatCompletion  = types.FeatureChatCompletion
+	FeatureStreaming       = types.FeatureStreaming
+	FeatureTemperature     = types.FeatureTemperature
+	FeatureMaxTokens       = types.FeatureMaxTokens
+	FeatureStopSequences   = types.FeatureStopSequences
+	FeatureSystemMessages  = types.FeatureSystemMessages
+	FeatureFunctionCalling = types.FeatureFunctionCalling
+	FeatureTokenCounting   = types.FeatureTokenCounting
+	FeatureSpeech          = types.FeatureSpeech
+	FeatureTranscription   = types.FeatureTranscription
+	FeatureBatch           = types.FeatureBatch
+	FeatureGrammar         = types.FeatureGrammar
+	FeatureRealtime        = types.FeatureRealtime
+	FeatureReasoning       = types.FeatureReasoning
+	FeatureStreamingInput  = types.FeatureStreamingInput
+)
+
+// Re-export batch statuses for convenient access.
+const (
+	BatchInProgress = types.BatchInProgress
+	BatchCanceling  = types.BatchCanceling
+	BatchEnded      = types.BatchEnded
+)
+
+// Re-export unsupported parameter policies for convenient access.
+const (
+	// UnsupportedParameterDrop silently drops unsupported parameters (default).
+	UnsupportedParameterDrop = types.UnsupportedParameterDrop
+
+	// UnsupportedParameterWarn drops unsupported parameters and reports them via callback.
+	UnsupportedParameterWarn = types.UnsupportedParameterWarn
+
+	// UnsupportedParameterError rejects requests with unsupported parameters.
+	UnsupportedParameterError = types.UnsupportedParameterError
+)
+
+// Re-export validation modes for convenient access.
+const (
+	// ValidationModeClamp silently clamps out-of-range parameters (default).
+	ValidationModeClamp = types.ValidationModeClamp
+
+	// ValidationModeStrict rejects requests with out-of-range parameters.
+	ValidationModeStrict = types.ValidationModeStrict
+
+	// ValidationModeWarn clamps out-of-range parameters and reports them via callback.
+	ValidationModeWarn = types.ValidationModeWarn
+)
+
+// Re-export warning codes for convenient access.
+const (
+	// WarningClamped reports a parameter clamped to the provider's range.
+	WarningClamped = types.WarningClamped
+
+	// WarningTruncated reports stop sequences beyond the provider's maximum.
+	WarningTruncated = types.WarningTruncated
+
+	// WarningDropped reports a parameter the provider does not support.
+	WarningDropped = types.WarningDropped
+
+	// WarningDeprecated reports a model its provider has deprecated.
+	WarningDeprecated = types.WarningDeprecated
+
+	// WarningDowngraded reports a model replaced after a quota error.
+	WarningDowngraded = types.WarningDowngraded
+)
+
+// Re-export realtime event types for convenient access.
+const (
+	RealtimeEventAudio           = types.RealtimeEventAudio
+	RealtimeEventTranscript      = types.RealtimeEventTranscript
+	RealtimeEventText            = types.RealtimeEventText
+	RealtimeEventInputTranscript = types.RealtimeEventInputTranscript
+	RealtimeEventSpeechStarted   = types.RealtimeEventSpeechStarted
+	RealtimeEventToolCall        = types.RealtimeEventToolCall
+	RealtimeEventResponseDone    = types.RealtimeEventResponseDone
+	RealtimeEventError           = types.RealtimeEventError
+)
+
+// Re-export error sanitization policies for convenient access.
+const (
+	// ErrorSanitizationOff leaves provider error messages unchanged (default).
+	ErrorSanitizationOff = types.ErrorSanitizationOff
+
+	// ErrorSanitizationStrip replaces echoed prompt text with "[redacted]".
+	ErrorSanitizationStrip = types.ErrorSanitizationStrip
+
+	// ErrorSanitizationHash replaces echoed prompt text with a short digest.
+	ErrorSanitizationHash = types.ErrorSanitizationHash
+)
+
+// Re-export mapping change actions for convenient access.
+// See types.MappingChange for detailed documentation.
+const (
+	MappingClamped   = types.MappingClamped
+	MappingDropped   = types.MappingDropped
+	MappingDefaulted = types.MappingDefaulted
+	MappingRenamed   = types.MappingRenamed
+	MappingMoved     = types.MappingMoved
+	MappingConverted = types.MappingConverted
+)
+
+// ExperimentControl is the variant served to users outside every experiment variant.
+const ExperimentControl = types.ExperimentControl
@@ -6,7 +6,11 @@
 package types
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -22,6 +26,10 @@ type CompletionRequest struct {
 	// Prompt is the input text to generate a completion for (required)
 	Prompt string `json:"prompt" validate:"required"`
 
+	// Model selects the model to use (optional)
+	// If empty, the adapter's default completion model is used
+	Model string `json:"model,omitempty"`
+
 	// Temperature controls randomness in the output (optional, 0.0-2.0)
 	// Lower values make output more focused and deterministic
 	Temperature *float64 `json:"temperature,omitempty" validate:"omitempty,min=0,max=2"`
@@ -30,15 +38,104 @@ type CompletionRequest struct {
 	// If not specified, the provider's default limit will be used
 	MaxTokens *int `json:"max_tokens,omitempty" validate:"omitempty,min=1"`
 
+	// MaxWords limits the generated text to about this many words (optional)
+	// It is converted to an approximate token limit, and longer output is trimmed
+	MaxWords *int `json:"max_words,omitempty" validate:"omitempty,min=1"`
+
+	// MaxChars limits the generated text to this many characters (optional)
+	// It is converted to an approximate token limit, and longer output is trimmed
+	MaxChars *int `json:"max_chars,omitempty" validate:"omitempty,min=1"`
+
+	// Profile selects a named RequestProfile registered on the client (optional)
+	// Fields set on the request take precedence over the profile
+	Profile string `json:"profile,omitempty"`
+
+	// UserID identifies the end user for experiment assignment (optional)
+	// Requests without a user ID are always served the control variant
+	UserID string `json:"user_id,omitempty"`
+
+	// PromptName selects a versioned prompt from Config.Prompts, rendered with
+	// PromptVars and prepended to the prompt (optional)
+	PromptName string `json:"prompt_name,omitempty"`
+
+	// PromptVersion selects the version of PromptName (optional)
+	// Defaults to the client's pin in Config.PromptVersions, then the latest version
+	PromptVersion string `json:"prompt_version,omitempty"`
+
+	// PromptVars are the template variables of the versioned prompt (optional)
+	PromptVars map[string]interface{} `json:"prompt_vars,omitempty"`
+
+	// SkipCache sends the request to the provider even if Config.Cache holds
+	// a response to it, and does not cache the new response (optional)
+	SkipCache bool `json:"skip_cache,omitempty"`
+
 	// Stop contains sequences where the API will stop generating further tokens (optional)
 	// Maximum number of stop sequences varies by provider
 	Stop []string `json:"stop,omitempty"`
 
+	// LogitBias adjusts the likelihood of tokens, mapping token IDs of the
+	// model's vocabulary to a bias from -100 (ban) to 100 (force) (optional)
+	// Only OpenAI supports it; other providers apply the unsupported parameter policy
+	LogitBias map[string]float64 `json:"logit_bias,omitempty"`
+
+	// Grammar constrains the output to a GBNF grammar or JSON schema
+	// (optional). Requires FeatureGrammar, which none of the built-in hosted
+	// providers support; for them the unsupported parameter policy applies,
+	// so set UnsupportedParameterError to reject such requests rather than
+	// send them unconstrained
+	Grammar *Grammar `json:"grammar,omitempty"`
+
+	// Project selects the OpenAI project of Config.ProjectKeys the request is
+	// billed to (optional); it takes precedence over WithProject
+	Project string `json:"project,omitempty"`
+
+	// Tags label the request for cost attribution, e.g. by feature, team or
+	// customer (optional). They are recorded in UsageRecord and the response
+	// metadata, and so in stored interactions; they are not sent to the
+	// provider, as the endpoints the built-in adapters use accept no
+	// free-form metadata
+	Tags map[string]string `json:"tags,omitempty"`
+
 	// Stream indicates whether to stream the response (optional, not yet implemented)
 	// When true, the response will be streamed as it's generated
 	Stream bool `json:"stream,omitempty"`
 }
 
+// Grammar constrains generated text to a formal grammar.
+//
+// Local inference backends such as llama.cpp and Ollama enforce grammars
+// during decoding, so the output is guaranteed to parse. Exactly one of GBNF
+// and JSONSchema must be set.
+type Grammar struct {
+	// GBNF is a grammar in llama.cpp's GBNF notation
+	GBNF string `json:"gbnf,omitempty"`
+
+	// JSONSchema is a JSON schema the output must be a valid instance of
+	JSONSchema json.RawMessage `json:"json_schema,omitempty"`
+}
+
+// Validate checks that exactly one of GBNF and JSONSchema is set, and that
+// JSONSchema is a JSON object.
+//
+// Returns:
+//   - error: A descriptive error if the grammar is invalid
+func (g Grammar) Validate() error {
+	hasGBNF := strings.TrimSpace(g.GBNF) != ""
+	hasSchema := len(g.JSONSchema) > 0
+	switch {
+	case hasGBNF && hasSchema:
+		return fmt.Errorf("grammar must set only one of gbnf and json_schema")
+	case !hasGBNF && !hasSchema:
+		return fmt.Errorf("grammar must set gbnf or json_schema")
+	case hasSchema:
+		var schema map[string]interface{}
+		if err := json.Unmarshal(g.JSONSchema, &schema); err != nil {
+			return fmt.Errorf("grammar json_schema must be a JSON object: %w", err)
+		}
+	}
+	return nil
+}
+
 // CompletionResponse represents a text completion response from an AI provider.
 //
 // This struct contains the generated text along with metadata about the
@@ -53,6 +150,13 @@ type CompletionResponse struct {
 	// FinishReason indicates why the generation stopped
 	// Common values: "stop", "length", "content_filter"
 	FinishReason string `json:"finish_reason"`
+
+	// MatchedStopSequence is the stop sequence that ended the generation, or
+	// nil if none did or the provider does not report it (OpenAI does not)
+	MatchedStopSequence *string `json:"matched_stop_sequence,omitempty"`
+
+	// Metadata carries provider-reported details about how the request was served
+	Metadata ResponseMetadata `json:"metadata"`
 }
 
 // ChatRequest represents a chat completion request with conversation history.
@@ -64,6 +168,10 @@ type ChatRequest struct {
 	// Should include user messages and any previous assistant responses
 	Messages []Message `json:"messages" validate:"required,min=1"`
 
+	// Model selects the model to use (optional)
+	// If empty, the adapter's default chat model is used
+	Model string `json:"model,omitempty"`
+
 	// Temperature controls randomness in the output (optional, 0.0-2.0)
 	// Lower values make output more focused and deterministic
 	Temperature *float64 `json:"temperature,omitempty" validate:"omitempty,min=0,max=2"`
@@ -72,6 +180,66 @@ type ChatRequest struct {
 	// If not specified, the provider's default limit will be used
 	MaxTokens *int `json:"max_tokens,omitempty" validate:"omitempty,min=1"`
 
+	// MaxWords limits the generated text to about this many words (optional)
+	// It is converted to an approximate token limit, and longer output is trimmed
+	MaxWords *int `json:"max_words,omitempty" validate:"omitempty,min=1"`
+
+	// MaxChars limits the generated text to this many characters (optional)
+	// It is converted to an approximate token limit, and longer output is trimmed
+	MaxChars *int `json:"max_chars,omitempty" validate:"omitempty,min=1"`
+
+	// Profile selects a named RequestProfile registered on the client (optional)
+	// Fields set on the request take precedence over the profile
+	Profile string `json:"profile,omitempty"`
+
+	// UserID identifies the end user for experiment assignment (optional)
+	// Requests without a user ID are always served the control variant
+	UserID string `json:"user_id,omitempty"`
+
+	// PromptName selects a versioned prompt from Config.Prompts, rendered with
+	// PromptVars and sent as the system message (optional)
+	PromptName string `json:"prompt_name,omitempty"`
+
+	// PromptVersion selects the version of PromptName (optional)
+	// Defaults to the client's pin in Config.PromptVersions, then the latest version
+	PromptVersion string `json:"prompt_version,omitempty"`
+
+	// PromptVars are the template variables of the versioned prompt (optional)
+	PromptVars map[string]interface{} `json:"prompt_vars,omitempty"`
+
+	// SkipCache sends the request to the provider even if Config.Cache holds
+	// a response to it, and does not cache the new response (optional)
+	SkipCache bool `json:"skip_cache,omitempty"`
+
+	// LogitBias adjusts the likelihood of tokens, mapping token IDs of the
+	// model's vocabulary to a bias from -100 (ban) to 100 (force) (optional)
+	// Only OpenAI supports it; other providers apply the unsupported parameter policy
+	LogitBias map[string]float64 `json:"logit_bias,omitempty"`
+
+	// Grammar constrains the output to a GBNF grammar or JSON schema
+	// (optional). Requires FeatureGrammar, which none of the built-in hosted
+	// providers support; for them the unsupported parameter policy applies,
+	// so set UnsupportedParameterError to reject such requests rather than
+	// send them unconstrained
+	Grammar *Grammar `json:"grammar,omitempty"`
+
+	// ReasoningBudget enables extended reasoning before the answer, with up
+	// to this many tokens (optional). Requires FeatureReasoning (Anthropic,
+	// with a minimum of 1024); the reasoning is returned in
+	// Message.Reasoning and counted in CompletionTokens
+	ReasoningBudget *int `json:"reasoning_budget,omitempty"`
+
+	// Project selects the OpenAI project of Config.ProjectKeys the request is
+	// billed to (optional); it takes precedence over WithProject
+	Project string `json:"project,omitempty"`
+
+	// Tags label the request for cost attribution, e.g. by feature, team or
+	// customer (optional). They are recorded in UsageRecord and the response
+	// metadata, and so in stored interactions; they are not sent to the
+	// provider, as the endpoints the built-in adapters use accept no
+	// free-form metadata
+	Tags map[string]string `json:"tags,omitempty"`
+
 	// Stream indicates whether to stream the response (optional, not yet implemented)
 	// When true, the response will be streamed as it's generated
 	Stream bool `json:"stream,omitempty"`
@@ -85,44 +253,1395 @@ type ChatResponse struct {
 	// Message contains the assistant's response message
 	Message Message `json:"message"`
 
-	// Usage provides token usage statistics for the request
-	Usage Usage `json:"usage"`
+	// Usage provides token usage statistics for the request
+	Usage Usage `json:"usage"`
+
+	// FinishReason indicates why the generation stopped
+	// Common values: "stop", "length", "content_filter"
+	FinishReason string `json:"finish_reason"`
+
+	// MatchedStopSequence is the stop sequence that ended the generation, or
+	// nil if none did or the provider does not report it (OpenAI does not)
+	MatchedStopSequence *string `json:"matched_stop_sequence,omitempty"`
+
+	// Metadata carries provider-reported details about how the request was served
+	Metadata ResponseMetadata `json:"metadata"`
+}
+
+// StreamChunk is an incremental part of a streamed chat response.
+//
+// Content arrives as a sequence of deltas; the final chunk carries the
+// finish reason and usage. Metadata is set on the first chunk only.
+type StreamChunk struct {
+	// Delta is the text generated since the previous chunk (may be empty)
+	Delta string `json:"delta,omitempty"`
+
+	// FinishReason indicates why the generation stopped (final chunk only)
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// MatchedStopSequence is the stop sequence that ended the generation, if
+	// the provider reports it (final chunk only)
+	MatchedStopSequence *string `json:"matched_stop_sequence,omitempty"`
+
+	// Usage provides token usage statistics (final chunk only)
+	Usage *Usage `json:"usage,omitempty"`
+
+	// Metadata carries provider-reported details about the response (first chunk only)
+	Metadata *ResponseMetadata `json:"metadata,omitempty"`
+
+	// Restart reports that the response was regenerated from the beginning,
+	// e.g. by a fallback provider; discard previously received deltas
+	Restart bool `json:"restart,omitempty"`
+
+	// ReasoningDelta is reasoning generated since the previous chunk, with
+	// ChatRequest.ReasoningBudget (may be empty)
+	ReasoningDelta string `json:"reasoning_delta,omitempty"`
+
+	// Phase is "draft" for chunks of a draft model's answer and "final" for
+	// the answer that replaces it, in speculative streams (optional)
+	Phase string `json:"phase,omitempty"`
+}
+
+// StreamReader reads the chunks of a streamed response.
+type StreamReader interface {
+	// Recv returns the next chunk, or io.EOF once the stream is complete
+	Recv() (StreamChunk, error)
+
+	// Close releases the underlying connection; it is safe to call more than once
+	Close() error
+}
+
+// SpeechRequest represents a text-to-speech request.
+type SpeechRequest struct {
+	// Input is the text to speak (required)
+	Input string `json:"input"`
+
+	// Model specifies which model to use (optional, uses provider default)
+	Model string `json:"model,omitempty"`
+
+	// Voice selects the voice, e.g. "alloy" (optional, uses provider default)
+	Voice string `json:"voice,omitempty"`
+
+	// Format is the audio format, e.g. "mp3" or "wav" (optional, uses provider default)
+	Format string `json:"format,omitempty"`
+
+	// Speed scales the speaking rate, where 1.0 is normal speed (optional)
+	Speed *float64 `json:"speed,omitempty"`
+}
+
+// BinaryResponse is a binary response body, such as generated audio or an
+// image, streamed from the provider rather than read into memory.
+//
+// It is an io.ReadCloser over the body; the caller must close it to release
+// the connection.
+type BinaryResponse struct {
+	// Body streams the response content
+	Body io.ReadCloser `json:"-"`
+
+	// ContentType is the MIME type of the content, e.g. "audio/mpeg"
+	ContentType string `json:"content_type"`
+
+	// ContentLength is the size of the content in bytes, or -1 if unknown
+	ContentLength int64 `json:"content_length"`
+}
+
+// TranscriptionRequest represents a speech-to-text request.
+type TranscriptionRequest struct {
+	// Audio is the recording to transcribe (required). If it is also an
+	// io.ReaderAt and io.Seeker, such as an *os.File, it is read in place;
+	// otherwise it is read into memory first.
+	Audio io.Reader `json:"-"`
+
+	// FileName is the name of the recording, whose extension tells the
+	// provider its format, e.g. "meeting.wav" (required)
+	FileName string `json:"file_name"`
+
+	// Model specifies which model to use (optional, uses provider default)
+	Model string `json:"model,omitempty"`
+
+	// Language is the ISO-639-1 code of the spoken language, which improves
+	// accuracy and latency (optional)
+	Language string `json:"language,omitempty"`
+
+	// Prompt guides the style or vocabulary of the transcript (optional)
+	Prompt string `json:"prompt,omitempty"`
+
+	// ChunkDuration splits recordings into chunks of at most this duration
+	// (optional). Recordings over the provider's upload limit are split
+	// regardless; only PCM WAV recordings can be split.
+	ChunkDuration time.Duration `json:"chunk_duration,omitempty"`
+}
+
+// TranscriptionSegment is a timed piece of a transcript.
+type TranscriptionSegment struct {
+	// Start and End are the offsets of the segment in the recording
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+
+	// Text is the transcript of the segment
+	Text string `json:"text"`
+}
+
+// TranscriptionResponse represents the transcript of a recording.
+type TranscriptionResponse struct {
+	// Text is the full transcript
+	Text string `json:"text"`
+
+	// Language is the spoken language, as detected or requested
+	Language string `json:"language,omitempty"`
+
+	// Duration is the length of the recording
+	Duration time.Duration `json:"duration"`
+
+	// Segments are the timed pieces of the transcript, if the provider
+	// reports them
+	Segments []TranscriptionSegment `json:"segments,omitempty"`
+
+	// Chunks is the number of chunks the recording was split into
+	Chunks int `json:"chunks"`
+
+	// Metadata contains provider-reported details about the response
+	Metadata ResponseMetadata `json:"metadata"`
+}
+
+// Read reads from the response body
+func (b *BinaryResponse) Read(p []byte) (int, error) {
+	return b.Body.Read(p)
+}
+
+// Close closes the response body
+func (b *BinaryResponse) Close() error {
+	return b.Body.Close()
+}
+
+// RealtimeRequest configures a realtime speech-to-speech session.
+//
+// Realtime sessions are experimental: their API may change as providers'
+// realtime APIs evolve.
+type RealtimeRequest struct {
+	// Model specifies which model to use (optional, uses provider default)
+	Model string `json:"model,omitempty"`
+
+	// Instructions are the system instructions for the session (optional)
+	Instructions string `json:"instructions,omitempty"`
+
+	// Voice selects the voice of the responses, e.g. "alloy" (optional)
+	Voice string `json:"voice,omitempty"`
+
+	// InputAudioFormat is the format of the audio sent with SendAudio, e.g.
+	// "pcm16" (optional, uses provider default)
+	InputAudioFormat string `json:"input_audio_format,omitempty"`
+
+	// OutputAudioFormat is the format of the response audio (optional,
+	// uses provider default)
+	OutputAudioFormat string `json:"output_audio_format,omitempty"`
+
+	// Temperature controls randomness (optional)
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// TranscribeInput requests transcripts of the caller's audio, delivered
+	// as RealtimeEventInputTranscript events
+	TranscribeInput bool `json:"transcribe_input,omitempty"`
+
+	// ManualTurns turns off the provider's detection of when the caller
+	// stops speaking; call CommitAudio and CreateResponse to end each turn
+	ManualTurns bool `json:"manual_turns,omitempty"`
+
+	// Tools are the functions the model may call (optional)
+	Tools []RealtimeTool `json:"tools,omitempty"`
+}
+
+// RealtimeTool is a function a realtime session's model may call.
+type RealtimeTool struct {
+	// Name identifies the function
+	Name string `json:"name"`
+
+	// Description tells the model when to call the function
+	Description string `json:"description,omitempty"`
+
+	// Parameters is the JSON schema of the function's arguments
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+}
+
+// RealtimeToolCall is a function call made by a realtime session's model.
+// Answer it with SendToolResult, then CreateResponse.
+type RealtimeToolCall struct {
+	// ID identifies the call in SendToolResult
+	ID string `json:"id"`
+
+	// Name is the function called
+	Name string `json:"name"`
+
+	// Arguments are the call's arguments as JSON
+	Arguments string `json:"arguments"`
+}
+
+// Realtime event types reported in RealtimeEvent.Type
+const (
+	// RealtimeEventAudio carries a piece of the response audio in Audio
+	RealtimeEventAudio = "audio"
+
+	// RealtimeEventTranscript carries a piece of the transcript of the
+	// response audio in Text
+	RealtimeEventTranscript = "transcript"
+
+	// RealtimeEventText carries a piece of a text response in Text
+	RealtimeEventText = "text"
+
+	// RealtimeEventInputTranscript carries the transcript of the caller's
+	// last turn in Text, with RealtimeRequest.TranscribeInput
+	RealtimeEventInputTranscript = "input_transcript"
+
+	// RealtimeEventSpeechStarted reports that the caller started speaking.
+	// Stop playing response audio; a response in progress is interrupted.
+	RealtimeEventSpeechStarted = "speech_started"
+
+	// RealtimeEventToolCall carries a function call in ToolCall
+	RealtimeEventToolCall = "tool_call"
+
+	// RealtimeEventResponseDone reports the end of a response, with its
+	// usage in Usage
+	RealtimeEventResponseDone = "response_done"
+
+	// RealtimeEventError reports an error the session recovered from, with
+	// the message in Text and the provider's code in Code
+	RealtimeEventError = "error"
+)
+
+// RealtimeEvent is an event received from a realtime session.
+type RealtimeEvent struct {
+	// Type is one of the RealtimeEvent constants
+	Type string `json:"type"`
+
+	// ResponseID identifies the response the event belongs to (optional)
+	ResponseID string `json:"response_id,omitempty"`
+
+	// Audio is a piece of response audio in the session's output format
+	Audio []byte `json:"audio,omitempty"`
+
+	// Text is a piece of transcript or text, or an error message
+	Text string `json:"text,omitempty"`
+
+	// ToolCall is the function call of a RealtimeEventToolCall event
+	ToolCall *RealtimeToolCall `json:"tool_call,omitempty"`
+
+	// Usage is the token usage of a completed response
+	Usage *Usage `json:"usage,omitempty"`
+
+	// Model is the model that produced a completed response (optional)
+	Model string `json:"model,omitempty"`
+
+	// Code is the provider's error code of a RealtimeEventError event
+	Code string `json:"code,omitempty"`
+
+	// Raw is the provider's event as received
+	Raw json.RawMessage `json:"raw,omitempty"`
+}
+
+// RealtimeSession is a realtime speech-to-speech session.
+//
+// One goroutine may call Recv while others send. Realtime sessions are
+// experimental: their API may change as providers' realtime APIs evolve.
+type RealtimeSession interface {
+	// SendAudio appends audio in the session's input format to the
+	// caller's current turn
+	SendAudio(audio []byte) error
+
+	// CommitAudio ends the caller's turn with ManualTurns
+	CommitAudio() error
+
+	// SendText adds a text message from the caller to the conversation
+	SendText(text string) error
+
+	// SendToolResult answers a tool call with its output
+	SendToolResult(callID, output string) error
+
+	// CreateResponse asks the model to respond, e.g. after SendText,
+	// SendToolResult or CommitAudio
+	CreateResponse() error
+
+	// Interrupt cancels the response in progress, e.g. when the caller
+	// starts speaking over it
+	Interrupt() error
+
+	// Recv returns the next event, or io.EOF once the session is closed
+	Recv() (RealtimeEvent, error)
+
+	// Close ends the session; it is safe to call more than once
+	Close() error
+}
+
+// BatchItem is one request of a batch submitted for asynchronous processing.
+type BatchItem struct {
+	// ID identifies the item in the batch results (required, unique within
+	// the batch); providers may restrict it, e.g. Anthropic allows up to 64
+	// letters, digits, hyphens and underscores
+	ID string `json:"id"`
+
+	// Request is the chat request to process
+	Request ChatRequest `json:"request"`
+}
+
+// BatchStatus is the processing state of a batch.
+type BatchStatus string
+
+const (
+	// BatchInProgress is a batch whose requests are being processed
+	BatchInProgress BatchStatus = "in_progress"
+
+	// BatchCanceling is a batch being canceled
+	BatchCanceling BatchStatus = "canceling"
+
+	// BatchEnded is a batch whose requests have all finished, failed,
+	// been canceled or expired; its results are available
+	BatchEnded BatchStatus = "ended"
+)
+
+// BatchCounts counts the requests of a batch by state.
+type BatchCounts struct {
+	// Processing is the number of requests not yet finished
+	Processing int `json:"processing"`
+
+	// Succeeded is the number of requests that produced a response
+	Succeeded int `json:"succeeded"`
+
+	// Errored is the number of requests that failed
+	Errored int `json:"errored"`
+
+	// Canceled is the number of requests canceled before they were processed
+	Canceled int `json:"canceled"`
+
+	// Expired is the number of requests not processed before the batch expired
+	Expired int `json:"expired"`
+}
+
+// Batch is a batch of requests processed asynchronously by the provider.
+//
+// Batches trade latency for cost: providers typically charge half the usual
+// price but may take up to a day to process them.
+type Batch struct {
+	// ID identifies the batch at the provider
+	ID string `json:"id"`
+
+	// Status is the processing state
+	Status BatchStatus `json:"status"`
+
+	// Counts counts the requests by state
+	Counts BatchCounts `json:"counts"`
+
+	// CreatedAt is when the batch was submitted
+	CreatedAt time.Time `json:"created_at"`
+
+	// EndedAt is when processing ended (zero while in progress)
+	EndedAt time.Time `json:"ended_at,omitempty"`
+
+	// ExpiresAt is when unprocessed requests expire
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Done reports whether the batch has ended and its results are available
+func (b *Batch) Done() bool {
+	return b.Status == BatchEnded
+}
+
+// BatchResult is the outcome of one item of an ended batch.
+type BatchResult struct {
+	// ID is the BatchItem ID
+	ID string `json:"id"`
+
+	// Response is the chat response, nil if the item failed
+	Response *ChatResponse `json:"response,omitempty"`
+
+	// Err is the item error if it failed, was canceled or expired
+	Err error `json:"-"`
+}
+
+// Message represents a single message in a conversation.
+//
+// Messages form the building blocks of chat conversations, with different
+// roles serving different purposes in the conversation flow.
+type Message struct {
+	// Role identifies the speaker of the message (required)
+	// Valid values: "user", "assistant", "system"
+	//   - "user": Messages from the human user
+	//   - "assistant": Messages from the AI assistant
+	//   - "system": System instructions or context (usually at the beginning)
+	Role string `json:"role" validate:"required,oneof=user assistant system"`
+
+	// Content contains the actual message text (required unless
+	// ContentReader is set)
+	Content string `json:"content" validate:"required"`
+
+	// ContentReader streams the message text into the request body instead
+	// of holding it in memory, for very large documents (optional). Content,
+	// if set, is sent before it. Only adapters with FeatureStreamingInput
+	// accept it; the request is retried only if it is an io.Seeker, which is
+	// rewound. Streamed content is not counted, cached, or checked by the
+	// prompt injection guard.
+	ContentReader io.Reader `json:"-"`
+
+	// Untrusted marks content from third parties, such as user uploads or
+	// retrieved documents (optional). With Config.PromptInjectionGuard enabled
+	// the client wraps untrusted content in delimiting tags and instructs the
+	// model to treat it as data; see the guard package.
+	Untrusted bool `json:"untrusted,omitempty"`
+
+	// Reasoning is the reasoning the provider exposed before the answer in
+	// an assistant response (optional). It is never sent back to the
+	// provider, and Config.RedactReasoning removes it from responses.
+	Reasoning string `json:"reasoning,omitempty"`
+}
+
+// Usage represents token usage information for API requests.
+//
+// This struct provides detailed information about token consumption,
+// which is important for cost tracking and rate limit management.
+type Usage struct {
+	// PromptTokens is the number of tokens in the input prompt/messages
+	PromptTokens int `json:"prompt_tokens"`
+
+	// CompletionTokens is the number of tokens in the generated response
+	CompletionTokens int `json:"completion_tokens"`
+
+	// TotalTokens is the sum of prompt and completion tokens
+	// This represents the total billable tokens for the request
+	TotalTokens int `json:"total_tokens"`
+}
+
+// ResponseMetadata contains provider-reported details about a response.
+//
+// These values are not part of the generated content but describe how the
+// request was served, which is useful for scheduling, auditing and debugging.
+//
+// Provider, Model, SystemFingerprint and ResponseID together identify exactly
+// which model version produced an output, which regulated users can persist
+// alongside each generation.
+type ResponseMetadata struct {
+	// Provider is the AI provider that served the request
+	Provider ProviderType `json:"provider,omitempty"`
+
+	// Model is the exact model version that served the request, as reported
+	// by the provider (e.g. "gpt-4o-2024-08-06" rather than the requested alias)
+	Model string `json:"model,omitempty"`
+
+	// SystemFingerprint identifies the backend configuration the model ran
+	// with (OpenAI only). It changes when the provider updates the deployment,
+	// which can affect determinism.
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+
+	// ResponseID is the provider's unique identifier for the response
+	ResponseID string `json:"response_id,omitempty"`
+
+	// Attempts is the number of HTTP attempts made, including retries
+	Attempts int `json:"attempts,omitempty"`
+
+	// RetryWait is the total backoff time spent waiting between attempts
+	RetryWait time.Duration `json:"retry_wait,omitempty"`
+
+	// RateLimit is the rate limit state reported alongside the response (optional)
+	// Nil when the provider did not include rate limit headers
+	RateLimit *RateLimitStatus `json:"rate_limit,omitempty"`
+
+	// InjectionFindings lists suspicious instructions detected in untrusted
+	// request messages (optional). Detection never blocks the request.
+	InjectionFindings []InjectionFinding `json:"injection_findings,omitempty"`
+
+	// Resumed reports that the response was stitched together from several
+	// generations after the output was cut off (see Client.ChatCompleteWithResume)
+	Resumed bool `json:"resumed,omitempty"`
+
+	// Resumes is the number of continuation requests made for the response
+	Resumes int `json:"resumes,omitempty"`
+
+	// Hedge describes the race between the primary and hedge requests when a
+	// FallbackClient latency budget applied (optional)
+	Hedge *HedgeInfo `json:"hedge,omitempty"`
+
+	// Experiments maps each configured experiment name to the variant that
+	// served the request (optional)
+	Experiments map[string]string `json:"experiments,omitempty"`
+
+	// Tags are the cost attribution tags of the request (optional)
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// UsageMismatches lists the usage counts that diverged from local token
+	// counts when Config.UsageVerificationTolerance is set (optional)
+	UsageMismatches []UsageMismatch `json:"usage_mismatches,omitempty"`
+
+	// Warnings lists the adjustments made to the request before it was sent,
+	// such as clamped or dropped parameters, and deprecated models (optional)
+	Warnings []Warning `json:"warnings,omitempty"`
+
+	// ReasoningRedacted reports that the provider's reasoning was removed
+	// from the response by Config.RedactReasoning; Usage still counts it
+	ReasoningRedacted bool `json:"reasoning_redacted,omitempty"`
+
+	// Prompt identifies the versioned prompt the request was sent with
+	// (optional)
+	Prompt *PromptRef `json:"prompt,omitempty"`
+
+	// Cached reports that the response was served from Config.Cache; its
+	// usage is that of the original request, which is not billed again.
+	// Fields describing the original request, such as Attempts and
+	// RateLimit, are left empty.
+	Cached bool `json:"cached,omitempty"`
+
+	// TimeToFirstToken is how long a streamed response took to deliver its
+	// first content, from the request being sent (streams only)
+	TimeToFirstToken time.Duration `json:"time_to_first_token,omitempty"`
+
+	// TokensPerSecond is the rate at which a streamed response generated
+	// completion tokens after the first one arrived (streams only; zero if
+	// the provider reported no usage)
+	TokensPerSecond float64 `json:"tokens_per_second,omitempty"`
+}
+
+// HedgeInfo describes a request raced against a hedge request after the
+// primary exceeded its latency budget.
+type HedgeInfo struct {
+	// Triggered reports whether the latency budget was exceeded and the
+	// hedge request was sent
+	Triggered bool `json:"triggered"`
+
+	// Winner is the path that answered: "primary" or "hedge"
+	Winner string `json:"winner"`
+
+	// PrimaryLatency is how long the primary request ran, until it completed
+	// or was cancelled
+	PrimaryLatency time.Duration `json:"primary_latency"`
+
+	// HedgeLatency is how long the hedge request ran, until it completed or
+	// was cancelled (zero if not triggered)
+	HedgeLatency time.Duration `json:"hedge_latency,omitempty"`
+
+	// LoserCancelled reports that the slower request was cancelled before it
+	// completed, so its latency is a lower bound
+	LoserCancelled bool `json:"loser_cancelled,omitempty"`
+}
+
+// UsageMismatch describes a provider-reported token count that diverges from
+// the local token count of the same text beyond the configured tolerance.
+//
+// Occasional mismatches are expected with the heuristic default tokenizer;
+// a sustained rise points at billing anomalies or a tokenizer change.
+type UsageMismatch struct {
+	// Field is the usage count that diverged: "prompt_tokens" or "completion_tokens"
+	Field string `json:"field"`
+
+	// Reported is the count reported by the provider
+	Reported int `json:"reported"`
+
+	// Counted is the local count
+	Counted int `json:"counted"`
+
+	// Deviation is the difference relative to the local count, e.g. 0.5 when
+	// the provider reported 50% more tokens than counted locally
+	Deviation float64 `json:"deviation"`
+}
+
+// InjectionFinding describes suspicious instructions found in untrusted content.
+type InjectionFinding struct {
+	// MessageIndex is the position of the message in the request
+	MessageIndex int `json:"message_index"`
+
+	// Reasons explains why the content was flagged
+	Reasons []string `json:"reasons"`
+
+	// Excerpt is a short extract of the flagged content
+	Excerpt string `json:"excerpt"`
+}
+
+// RateLimitStatus represents the rate limit state reported by a provider.
+//
+// Providers report their remaining quota in response headers. This struct
+// normalizes those headers so schedulers can pace traffic based on the real
+// remaining quota. Limit fields are zero when the provider did not report them.
+type RateLimitStatus struct {
+	// RequestsLimit is the maximum number of requests allowed in the current window
+	RequestsLimit int `json:"requests_limit,omitempty"`
+
+	// RequestsRemaining is the number of requests left in the current window
+	RequestsRemaining int `json:"requests_remaining"`
+
+	// RequestsReset is when the request window resets
+	RequestsReset time.Time `json:"requests_reset,omitempty"`
+
+	// TokensLimit is the maximum number of tokens allowed in the current window
+	TokensLimit int `json:"tokens_limit,omitempty"`
+
+	// TokensRemaining is the number of tokens left in the current window
+	TokensRemaining int `json:"tokens_remaining"`
+
+	// TokensReset is when the token window resets
+	TokensReset time.Time `json:"tokens_reset,omitempty"`
+
+	// InputTokensLimit is the maximum number of input tokens allowed in the
+	// current window, for providers limiting them separately (Anthropic)
+	InputTokensLimit int `json:"input_tokens_limit,omitempty"`
+
+	// InputTokensRemaining is the number of input tokens left in the current window
+	InputTokensRemaining int `json:"input_tokens_remaining,omitempty"`
+
+	// OutputTokensLimit is the maximum number of output tokens allowed in the
+	// current window, for providers limiting them separately (Anthropic)
+	OutputTokensLimit int `json:"output_tokens_limit,omitempty"`
+
+	// OutputTokensRemaining is the number of output tokens left in the current window
+	OutputTokensRemaining int `json:"output_tokens_remaining,omitempty"`
+
+	// RetryAfter is the provider's suggested wait before the next request (optional)
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+
+	// ObservedAt is when the headers were received
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// Limits describes the known rate limits and quotas of the account a client
+// uses.
+//
+// Rate limits come from the headers of the most recent response, or from a
+// provider endpoint for adapters that can query one. Zero values mean the
+// limit is unknown, not that there is none.
+type Limits struct {
+	// Provider is the AI provider the limits apply to
+	Provider ProviderType `json:"provider"`
+
+	// Rate is the latest known rate limit state, or nil if none is known yet
+	Rate *RateLimitStatus `json:"rate,omitempty"`
+
+	// Quotas are the account quotas reported by the provider (optional)
+	Quotas []Quota `json:"quotas,omitempty"`
+}
+
+// Quota is a provider account quota, such as a monthly spend limit.
+type Quota struct {
+	// Name identifies the quota, e.g. "monthly_spend"
+	Name string `json:"name"`
+
+	// Limit is the quota size in Unit
+	Limit float64 `json:"limit"`
+
+	// Used is the part of the quota consumed so far in Unit (optional)
+	Used float64 `json:"used,omitempty"`
+
+	// Unit is what the quota is measured in, e.g. "usd" or "tokens"
+	Unit string `json:"unit"`
+
+	// Reset is when the quota resets (optional)
+	Reset time.Time `json:"reset,omitempty"`
+}
+
+// UsageQuery selects the provider-side usage and costs read by a UsageClient.
+type UsageQuery struct {
+	// Start is the beginning of the period (required)
+	Start time.Time `json:"start"`
+
+	// End is the end of the period (default: now)
+	End time.Time `json:"end,omitempty"`
+
+	// BucketWidth is the length of the time buckets usage is aggregated
+	// in: time.Minute, time.Hour or 24 hours (default: 24 hours). Costs are
+	// only available in daily buckets.
+	BucketWidth time.Duration `json:"bucket_width,omitempty"`
+
+	// ProjectIDs limits the result to these projects (optional)
+	ProjectIDs []string `json:"project_ids,omitempty"`
+
+	// GroupByProject reports usage per project rather than for the whole
+	// organization
+	GroupByProject bool `json:"group_by_project,omitempty"`
+}
+
+// ProviderUsage is the usage or cost reported by a provider's usage API for
+// one time bucket, model and project.
+type ProviderUsage struct {
+	// Start is the beginning of the bucket
+	Start time.Time `json:"start"`
+
+	// End is the end of the bucket
+	End time.Time `json:"end"`
+
+	// Model is the model the usage was recorded for (empty for costs)
+	Model string `json:"model,omitempty"`
+
+	// ProjectID is the project the usage was recorded for (empty unless
+	// grouped by project)
+	ProjectID string `json:"project_id,omitempty"`
+
+	// Requests is the number of requests
+	Requests int64 `json:"requests,omitempty"`
+
+	// PromptTokens is the number of input tokens
+	PromptTokens int64 `json:"prompt_tokens,omitempty"`
+
+	// CompletionTokens is the number of output tokens
+	CompletionTokens int64 `json:"completion_tokens,omitempty"`
+
+	// Cost is the billed amount in USD (costs only)
+	Cost float64 `json:"cost_usd,omitempty"`
+}
+
+// UsageRecord describes the token consumption of a single successful request.
+//
+// Records are emitted by the client to the configured UsageRecorder after
+// every successful request, enabling cost tracking and usage dashboards.
+type UsageRecord struct {
+	// Provider is the AI provider that served the request
+	Provider ProviderType `json:"provider"`
+
+	// Model is the model that served the request (empty if not reported)
+	Model string `json:"model,omitempty"`
+
+	// SystemFingerprint is the provider's backend fingerprint (empty if not reported)
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+
+	// Usage contains the token counts reported by the provider
+	Usage Usage `json:"usage"`
+
+	// Cost is the cost of the request in USD according to the client's pricing
+	// registry (zero if the model's price is unknown)
+	Cost float64 `json:"cost"`
+
+	// Latency is the wall-clock duration of the request
+	Latency time.Duration `json:"latency"`
+
+	// Attempts is the number of HTTP attempts made, including retries
+	Attempts int `json:"attempts,omitempty"`
+
+	// RetryWait is the part of Latency spent in retry backoff
+	RetryWait time.Duration `json:"retry_wait,omitempty"`
+
+	// Experiments maps each experiment name to the variant that served the
+	// request (empty when no experiments are configured)
+	Experiments map[string]string `json:"experiments,omitempty"`
+
+	// Tags are the cost attribution tags of the request (optional)
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// UsageMismatch reports that the usage diverged from local token counts
+	// when Config.UsageVerificationTolerance is set
+	UsageMismatch bool `json:"usage_mismatch,omitempty"`
+
+	// Prompt identifies the versioned prompt the request was sent with
+	// (nil when the request used none)
+	Prompt *PromptRef `json:"prompt,omitempty"`
+
+	// TimeToFirstToken is how long a streamed response took to deliver its
+	// first content (zero for requests that were not streamed)
+	TimeToFirstToken time.Duration `json:"time_to_first_token,omitempty"`
+
+	// TokensPerSecond is the completion token rate of a streamed response
+	// after its first token (zero for requests that were not streamed)
+	TokensPerSecond float64 `json:"tokens_per_second,omitempty"`
+
+	// Timestamp is when the request completed
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// RequestProfile is a named bundle of request defaults for a use case.
+//
+// Profiles are registered on the client (Config.Profiles or
+// Client.RegisterProfile) and selected per request with the Profile field, so
+// teams can standardize settings such as "creative-writing" or "extraction"
+// across services. Request fields take precedence over profile fields, which
+// take precedence over client-wide config defaults.
+type RequestProfile struct {
+	// Model is the model to use (optional)
+	Model string `json:"model,omitempty"`
+
+	// Temperature is the sampling temperature (optional, 0.0-2.0)
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// MaxTokens limits the generated tokens (optional)
+	MaxTokens *int `json:"max_tokens,omitempty"`
+
+	// SystemPrompt is prepended as a system message to chat requests without
+	// one, and before the prompt of completion requests (optional)
+	SystemPrompt string `json:"system_prompt,omitempty"`
+
+	// Stop contains stop sequences for completion requests (optional)
+	Stop []string `json:"stop,omitempty"`
+}
+
+// Validate checks the profile's parameter ranges
+func (p RequestProfile) Validate() error {
+	if p.Temperature != nil && (*p.Temperature < 0.0 || *p.Temperature > 2.0) {
+		return fmt.Errorf("temperature must be between 0.0 and 2.0, got: %f", *p.Temperature)
+	}
+	if p.MaxTokens != nil && *p.MaxTokens <= 0 {
+		return fmt.Errorf("max tokens must be positive, got: %d", *p.MaxTokens)
+	}
+	return nil
+}
+
+// ExperimentControl is the variant served to users outside every configured
+// variant of an experiment
+const ExperimentControl = "control"
+
+// Experiment routes a percentage of traffic to alternative models or prompts.
+//
+// Users are assigned to variants deterministically by hashing the experiment
+// name with the request UserID, so a user sees the same variant on every
+// request and independent experiments are not correlated. Users outside every
+// variant, and requests without a UserID, get ExperimentControl and are sent
+// unchanged. The served variant is reported in ResponseMetadata.Experiments
+// and UsageRecord.Experiments for downstream analysis.
+//
+// Experiments with a Flag leave assignment to a feature flag service instead,
+// such as LaunchDarkly or OpenFeature, through Config.Flags.
+type Experiment struct {
+	// Name identifies the experiment in metrics (required)
+	Name string `json:"name"`
+
+	// Variants are the treatment arms; their percentages must not sum to
+	// more than 100, and the remainder is the control group (required)
+	Variants []ExperimentVariant `json:"variants"`
+
+	// Flag is the key of a feature flag in Config.Flags whose value names
+	// the variant to serve, replacing the percentage assignment (optional)
+	// Values naming no variant, and evaluation errors, serve the control group
+	Flag string `json:"flag,omitempty"`
+}
+
+// ExperimentVariant is one treatment arm of an Experiment.
+//
+// Variant fields override the request rather than fill it in, since the
+// point of a variant is to change what the user is served.
+type ExperimentVariant struct {
+	// Name labels the variant in metrics (required, must not be "control")
+	Name string `json:"name"`
+
+	// Percent is the share of users assigned to the variant (0-100)
+	Percent float64 `json:"percent"`
+
+	// Model replaces the request model (optional)
+	Model string `json:"model,omitempty"`
+
+	// Profile replaces the request profile (optional)
+	Profile string `json:"profile,omitempty"`
+
+	// SystemPrompt replaces the system message of chat requests, or is
+	// prepended to the prompt of completion requests (optional)
+	SystemPrompt string `json:"system_prompt,omitempty"`
+
+	// PromptVersion replaces the version of the request's versioned prompt,
+	// to roll out a new prompt version gradually (optional)
+	PromptVersion string `json:"prompt_version,omitempty"`
+}
+
+// Validate checks the experiment name, variant names and percentages
+func (e Experiment) Validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return fmt.Errorf("experiment name cannot be empty")
+	}
+	if len(e.Variants) == 0 {
+		return fmt.Errorf("experiment must have at least one variant")
+	}
+
+	seen := make(map[string]bool, len(e.Variants))
+	total := 0.0
+	for _, variant := range e.Variants {
+		if strings.TrimSpace(variant.Name) == "" {
+			return fmt.Errorf("variant name cannot be empty")
+		}
+		if variant.Name == ExperimentControl {
+			return fmt.Errorf("variant name %q is reserved", ExperimentControl)
+		}
+		if seen[variant.Name] {
+			return fmt.Errorf("duplicate variant name %q", variant.Name)
+		}
+		seen[variant.Name] = true
+		if variant.Percent < 0 || variant.Percent > 100 {
+			return fmt.Errorf("variant %q percent must be between 0 and 100, got: %g", variant.Name, variant.Percent)
+		}
+		total += variant.Percent
+	}
+	if total > 100 {
+		return fmt.Errorf("variant percentages must not sum to more than 100, got: %g", total)
+	}
+	return nil
+}
+
+// experimentBuckets is the assignment resolution, allowing percentages with
+// two decimal places
+const experimentBuckets = 10000
+
+// Assign returns the variant for a user, or nil for the control group.
+//
+// The assignment depends only on the experiment name, the user ID and the
+// variant percentages, so it is stable across processes and restarts.
+func (e Experiment) Assign(userID string) *ExperimentVariant {
+	if userID == "" {
+		return nil
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(e.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(userID))
+	bucket := float64(h.Sum32()%experimentBuckets) / (experimentBuckets / 100)
+
+	upper := 0.0
+	for i := range e.Variants {
+		upper += e.Variants[i].Percent
+		if bucket < upper {
+			return &e.Variants[i]
+		}
+	}
+	return nil
+}
+
+// Variant returns the variant with the given name, or nil if there is none
+func (e Experiment) Variant(name string) *ExperimentVariant {
+	for i := range e.Variants {
+		if e.Variants[i].Name == name {
+			return &e.Variants[i]
+		}
+	}
+	return nil
+}
+
+// FlagProvider evaluates feature flags, so a flag service such as
+// LaunchDarkly or OpenFeature can drive model and prompt selection without
+// the core package depending on its SDK.
+//
+// The client calls Evaluate with a context carrying the request's
+// FlagSubject, for targeting by user or tags. Implementations must be safe
+// for concurrent use and should answer quickly, e.g. from the SDK's local
+// cache, as they are called on every request.
+type FlagProvider interface {
+	// Evaluate returns the flag's value for the subject in ctx, or an empty
+	// string for the default
+	Evaluate(ctx context.Context, key string) (string, error)
+}
+
+// FlagFunc adapts a function to a FlagProvider.
+//
+// Example:
+//
+//	config.Flags = FlagFunc(func(ctx context.Context, key string) (string, error) {
+//		subject := FlagSubjectFromContext(ctx)
+//		return ldClient.StringVariation(key, ldcontext.New(subject.UserID), "")
+//	})
+type FlagFunc func(ctx context.Context, key string) (string, error)
+
+// Evaluate calls f(ctx, key)
+func (f FlagFunc) Evaluate(ctx context.Context, key string) (string, error) {
+	return f(ctx, key)
+}
+
+// FlagSubject describes the request a feature flag is evaluated for.
+type FlagSubject struct {
+	// UserID is the request UserID (empty if not set)
+	UserID string `json:"user_id,omitempty"`
+
+	// Tags are the request's cost attribution tags (optional)
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// UsageRecorder receives usage records for completed requests.
+//
+// Implementations must be safe for concurrent use, as a single client may
+// serve many requests in parallel. See the usage package for an aggregating
+// implementation with periodic export.
+type UsageRecorder interface {
+	// RecordUsage is called once for every successful request
+	RecordUsage(record UsageRecord)
+}
+
+// BudgetGuard is implemented by a UsageRecorder that caps spend, such as
+// usage.Budget. Clients check it before sending each request and fail the
+// requests it rejects without contacting the provider.
+type BudgetGuard interface {
+	// CheckBudget returns an error, typically a *BudgetExceededError, if no
+	// further requests may be sent
+	CheckBudget(ctx context.Context) error
+}
+
+// BudgetExceededError is returned for requests rejected because a spend
+// limit was reached. Requests are accepted again from ResetAt, when the
+// limit's window starts over.
+type BudgetExceededError struct {
+	// Window is the period the limit applies to, e.g. "hourly" or "daily"
+	Window string
+
+	// Limit is the spend limit in USD
+	Limit float64
+
+	// Spent is the spend in USD recorded in the current window
+	Spent float64
+
+	// ResetAt is when the current window ends
+	ResetAt time.Time
+}
+
+// Error implements the error interface
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s budget of $%.2f exceeded: spent $%.2f, resets at %s", e.Window, e.Limit, e.Spent, e.ResetAt.Format(time.RFC3339))
+}
+
+// Flusher is implemented by a UsageRecorder or InteractionStore that buffers
+// records, so Close can write them out before the process exits.
+type Flusher interface {
+	// Flush writes buffered records, giving up when ctx is done
+	Flush(ctx context.Context) error
+}
+
+// InteractionRecord is a persisted prompt/response pair.
+//
+// Records are emitted by the client to the configured Store after every
+// successful request. Exactly one of Prompt (completion requests) and
+// Messages (chat requests) is set.
+type InteractionRecord struct {
+	// Provider is the AI provider that served the request
+	Provider ProviderType `json:"provider"`
+
+	// Model is the model that served the request (empty if not reported)
+	Model string `json:"model,omitempty"`
+
+	// Prompt is the prompt of a completion request
+	Prompt string `json:"prompt,omitempty"`
+
+	// Messages is the conversation of a chat request
+	Messages []Message `json:"messages,omitempty"`
+
+	// Response is the generated text
+	Response string `json:"response"`
+
+	// FinishReason indicates why the generation stopped
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// Usage contains the token counts reported by the provider
+	Usage Usage `json:"usage"`
+
+	// Cost is the cost of the request in USD (zero if the model's price is unknown)
+	Cost float64 `json:"cost"`
+
+	// Latency is the wall-clock duration of the request
+	Latency time.Duration `json:"latency"`
+
+	// Timestamp is when the request completed
+	Timestamp time.Time `json:"timestamp"`
+
+	// Metadata carries the provider-reported response metadata
+	Metadata ResponseMetadata `json:"metadata"`
+}
+
+// PromptVersion is one version of a named prompt template.
+//
+// Versions are immutable: a name and version always identify the same
+// content, which Hash fingerprints. See the prompt package for an in-memory
+// PromptStore.
+type PromptVersion struct {
+	// Name identifies the prompt, e.g. "support-agent"
+	Name string `json:"name"`
+
+	// Version is a semantic version, e.g. "1.2.0"
+	Version string `json:"version"`
+
+	// Hash is the hex-encoded SHA-256 of Content
+	Hash string `json:"hash"`
+
+	// Content is the prompt text in text/template syntax
+	Content string `json:"content"`
+}
+
+// Ref returns the reference recorded for requests sent with the version
+func (v PromptVersion) Ref() PromptRef {
+	return PromptRef{Name: v.Name, Version: v.Version, Hash: v.Hash}
+}
+
+// PromptRef identifies the prompt version a request was sent with.
+type PromptRef struct {
+	// Name is the prompt name
+	Name string `json:"name"`
+
+	// Version is the prompt version
+	Version string `json:"version"`
+
+	// Hash is the hex-encoded SHA-256 of the prompt content
+	Hash string `json:"hash"`
+}
+
+// PromptStore looks up versioned prompts.
+//
+// Implementations must be safe for concurrent use.
+type PromptStore interface {
+	// GetPrompt returns a version of the named prompt, or its latest version
+	// when version is empty
+	GetPrompt(ctx context.Context, name, version string) (PromptVersion, error)
+}
+
+// ResponseCache stores serialized responses for Config.Cache.
+//
+// Keys are hex-encoded hashes of normalized requests. Implementations must be
+// safe for concurrent use; see the cache package for in-memory, Redis and
+// memcached implementations.
+type ResponseCache interface {
+	// Get returns the value stored under key, and false if there is none
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value under key until ttl passes
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// CacheLocker is implemented by a ResponseCache shared between replicas to
+// prevent cache stampedes: when a popular entry is missing, the replica
+// holding the lock calls the provider while the others wait for the entry.
+type CacheLocker interface {
+	// Lock claims key until ttl passes, returning false if another claim
+	// holds it
+	Lock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+	// Unlock releases a claim made with Lock
+	Unlock(ctx context.Context, key string) error
+}
+
+// RateLimiterStore holds the token buckets that enforce
+// Config.RequestsPerMinute.
+//
+// The client reserves a token before each request and waits the returned
+// duration before sending it. A store shared by all replicas of a service,
+// such as ratelimit.RedisStore, makes them draw from one bucket per provider
+// and API key, so together they stay within the provider's quota.
+// Implementations must be safe for concurrent use.
+type RateLimiterStore interface {
+	// Reserve takes a token from the bucket with the given key, which holds
+	// up to burst tokens and refills at rate tokens per second, and returns
+	// how long to wait until the token is available
+	Reserve(ctx context.Context, key string, rate float64, burst int) (time.Duration, error)
+}
+
+// RateLimitReleaser is implemented by a RateLimiterStore that can return a
+// reserved token unused, so a request cancelled while waiting for its turn
+// does not hold up the requests queued behind it.
+type RateLimitReleaser interface {
+	// Release returns a token taken with Reserve to the bucket with the
+	// given key
+	Release(ctx context.Context, key string, rate float64, burst int) error
+}
+
+// InteractionStore persists prompts and responses.
+//
+// Implementations must be safe for concurrent use. See the store package
+// for a SQLite implementation.
+type InteractionStore interface {
+	// SaveInteraction is called once for every successful request
+	SaveInteraction(ctx context.Context, record InteractionRecord) error
+}
+
+// UnsupportedParameterPolicy controls how the client handles request
+// parameters the target provider does not support.
+type UnsupportedParameterPolicy string
+
+const (
+	// UnsupportedParameterDrop silently drops unsupported parameters (default)
+	UnsupportedParameterDrop UnsupportedParameterPolicy = "drop"
+
+	// UnsupportedParameterWarn drops unsupported parameters and reports each
+	// one to Config.OnUnsupportedParameter
+	UnsupportedParameterWarn UnsupportedParameterPolicy = "warn"
+
+	// UnsupportedParameterError rejects requests with unsupported parameters
+	// with a validation error
+	UnsupportedParameterError UnsupportedParameterPolicy = "error"
+)
+
+// ValidationMode controls how the client and adapters handle temperature,
+// max tokens and stop sequence values outside the provider's ranges.
+type ValidationMode string
+
+const (
+	// ValidationModeClamp silently clamps out-of-range values (default)
+	ValidationModeClamp ValidationMode = "clamp"
+
+	// ValidationModeStrict rejects requests with out-of-range values with a
+	// validation error
+	ValidationModeStrict ValidationMode = "strict"
+
+	// ValidationModeWarn clamps out-of-range values and reports each one to
+	// Config.OnOutOfRangeParameter
+	ValidationModeWarn ValidationMode = "warn"
+)
+
+// ErrorSanitizationPolicy controls how prompt text echoed back in provider
+// error messages is handled.
+type ErrorSanitizationPolicy string
+
+const (
+	// ErrorSanitizationOff leaves provider error messages unchanged (default)
+	ErrorSanitizationOff ErrorSanitizationPolicy = "off"
+
+	// ErrorSanitizationStrip replaces echoed prompt text with "[redacted]"
+	ErrorSanitizationStrip ErrorSanitizationPolicy = "strip"
+
+	// ErrorSanitizationHash replaces echoed prompt text with a short SHA-256
+	// digest, so identical echoes can still be correlated across logs
+	ErrorSanitizationHash ErrorSanitizationPolicy = "hash"
+)
+
+// UnsupportedParameter describes a request parameter the provider does not support.
+type UnsupportedParameter struct {
+	// Provider is the provider the request targets
+	Provider ProviderType `json:"provider"`
+
+	// Parameter is the request parameter name, e.g. "stop" or "stream"
+	Parameter string `json:"parameter"`
+
+	// Reason explains why the parameter is unsupported
+	Reason string `json:"reason"`
+}
+
+// Error implements the error interface so unsupported parameters can be
+// reported directly as validation errors
+func (p UnsupportedParameter) Error() string {
+	return fmt.Sprintf("parameter %q is not supported by provider %s: %s", p.Parameter, p.Provider, p.Reason)
+}
+
+// OutOfRangeParameter describes a request parameter outside the provider's range.
+type OutOfRangeParameter struct {
+	// Provider is the provider the request targets
+	Provider ProviderType `json:"provider"`
+
+	// Parameter is the request parameter name: "temperature", "max_tokens"
+	// or "stop"
+	Parameter string `json:"parameter"`
+
+	// Value is the requested value; for "stop", the number of sequences
+	Value float64 `json:"value"`
+
+	// Limit is the provider maximum the value is clamped to
+	Limit float64 `json:"limit"`
+}
+
+// Error implements the error interface so out-of-range parameters can be
+// reported directly as validation errors
+func (p OutOfRangeParameter) Error() string {
+	return fmt.Sprintf("parameter %q is out of range for provider %s: %g exceeds the maximum of %g", p.Parameter, p.Provider, p.Value, p.Limit)
+}
+
+// Warning describes an adjustment the client made to a request without
+// failing it, such as clamping a parameter or dropping an unsupported one,
+// a request for a deprecated model, or a model downgraded after the
+// provider reported exhausted quota.
+type Warning struct {
+	// Code is the kind of warning: WarningClamped, WarningTruncated,
+	// WarningDropped, WarningDeprecated or WarningDowngraded
+	Code string `json:"code"`
+
+	// Parameter is the request parameter concerned, e.g. "temperature"
+	Parameter string `json:"parameter"`
+
+	// Message describes the warning
+	Message string `json:"message"`
+}
+
+// Warning codes reported in Warning.Code
+const (
+	WarningClamped    = "clamped"
+	WarningTruncated  = "truncated"
+	WarningDropped    = "dropped"
+	WarningDeprecated = "deprecated"
+	WarningDowngraded = "downgraded"
+)
+
+// ModelDeprecation describes a request for a model its provider has
+// deprecated. See the models package for the known deprecations.
+type ModelDeprecation struct {
+	// Provider is the provider of the model
+	Provider ProviderType `json:"provider"`
+
+	// Model is the requested model
+	Model string `json:"model"`
+
+	// DeprecatedOn is when the model was deprecated
+	DeprecatedOn time.Time `json:"deprecated_on"`
+
+	// RetiresOn is when requests for the model start failing (zero if not
+	// announced)
+	RetiresOn time.Time `json:"retires_on,omitempty"`
+
+	// Replacement is the suggested model to migrate to (optional)
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Retired reports whether the model's retirement date has passed at now
+func (d ModelDeprecation) Retired(now time.Time) bool {
+	return !d.RetiresOn.IsZero() && !now.Before(d.RetiresOn)
+}
+
+// String describes the deprecation and the suggested replacement
+func (d ModelDeprecation) String() string {
+	message := fmt.Sprintf("model %s was deprecated on %s", d.Model, d.DeprecatedOn.Format("2006-01-02"))
+	switch {
+	case d.Retired(time.Now()):
+		message += fmt.Sprintf(" and retired on %s", d.RetiresOn.Format("2006-01-02"))
+	case !d.RetiresOn.IsZero():
+		message += fmt.Sprintf(" and retires on %s", d.RetiresOn.Format("2006-01-02"))
+	}
+	if d.Replacement != "" {
+		message += "; migrate to " + d.Replacement
+	}
+	return message
+}
+
+// DowngradeEvent reports that requests for a model started or stopped being
+// sent to its downgrade model after the provider reported exhausted quota.
+type DowngradeEvent struct {
+	// Model is the model the provider reported out of quota
+	Model string `json:"model"`
+
+	// Replacement is the model requests are sent to instead
+	Replacement string `json:"replacement"`
+
+	// Active is true when the downgrade starts and false when its cool-down
+	// has passed and requests go to Model again
+	Active bool `json:"active"`
+
+	// Until is when the cool-down ends
+	Until time.Time `json:"until"`
 
-	// FinishReason indicates why the generation stopped
-	// Common values: "stop", "length", "content_filter"
-	FinishReason string `json:"finish_reason"`
+	// Reason is the quota error that started the downgrade
+	Reason string `json:"reason,omitempty"`
 }
 
-// Message represents a single message in a conversation.
-//
-// Messages form the building blocks of chat conversations, with different
-// roles serving different purposes in the conversation flow.
-type Message struct {
-	// Role identifies the speaker of the message (required)
-	// Valid values: "user", "assistant", "system"
-	//   - "user": Messages from the human user
-	//   - "assistant": Messages from the AI assistant
-	//   - "system": System instructions or context (usually at the beginning)
-	Role string `json:"role" validate:"required,oneof=user assistant system"`
+// Mapping change actions reported in MappingChange.Action
+const (
+	MappingClamped   = "clamped"
+	MappingDropped   = "dropped"
+	MappingDefaulted = "defaulted"
+	MappingRenamed   = "renamed"
+	MappingMoved     = "moved"
+	MappingConverted = "converted"
+)
 
-	// Content contains the actual message text (required)
-	Content string `json:"content" validate:"required"`
+// MappingChange describes how a generic request parameter was changed on its
+// way to the provider payload.
+type MappingChange struct {
+	// Parameter is the generic request parameter, e.g. "temperature" or "messages[2]"
+	Parameter string `json:"parameter"`
+
+	// Action is what happened to the parameter, one of the Mapping* constants
+	Action string `json:"action"`
+
+	// Detail explains the change, including old and new values where useful
+	Detail string `json:"detail"`
 }
 
-// Usage represents token usage information for API requests.
-//
-// This struct provides detailed information about token consumption,
-// which is important for cost tracking and rate limit management.
-type Usage struct {
-	// PromptTokens is the number of tokens in the input prompt/messages
-	PromptTokens int `json:"prompt_tokens"`
+// RequestMapping is the provider-specific request an adapter builds for a
+// generic request.
+type RequestMapping struct {
+	// Provider is the provider the payload targets
+	Provider ProviderType `json:"provider"`
 
-	// CompletionTokens is the number of tokens in the generated response
-	CompletionTokens int `json:"completion_tokens"`
+	// Endpoint is the API path the payload is sent to, e.g. "/messages"
+	Endpoint string `json:"endpoint"`
 
-	// TotalTokens is the sum of prompt and completion tokens
-	// This represents the total billable tokens for the request
-	TotalTokens int `json:"total_tokens"`
+	// Payload is the request body in the provider's format
+	Payload interface{} `json:"payload"`
+
+	// Changes lists the parameters that were clamped, dropped, filled in or renamed
+	Changes []MappingChange `json:"changes,omitempty"`
 }
 
 // ProviderType represents the type of AI provider.
@@ -149,6 +1668,62 @@ const (
 	ProviderGoogle ProviderType = "google"
 )
 
+// Feature identifiers returned by ProviderAdapter.SupportedFeatures.
+//
+// The client checks these before sending a request, so using a method or
+// parameter the adapter does not support fails with a consistent validation
+// error instead of a provider-specific API error.
+const (
+	// FeatureCompletion is support for text completion requests
+	FeatureCompletion = "completion"
+
+	// FeatureChatCompletion is support for chat completion requests
+	FeatureChatCompletion = "chat_completion"
+
+	// FeatureStreaming is support for streamed responses
+	FeatureStreaming = "streaming"
+
+	// FeatureTemperature is support for the temperature parameter
+	FeatureTemperature = "temperature"
+
+	// FeatureMaxTokens is support for the max tokens parameter
+	FeatureMaxTokens = "max_tokens"
+
+	// FeatureStopSequences is support for stop sequences
+	FeatureStopSequences = "stop_sequences"
+
+	// FeatureSystemMessages is support for system messages in chat requests
+	FeatureSystemMessages = "system_messages"
+
+	// FeatureFunctionCalling is support for function/tool calling
+	FeatureFunctionCalling = "function_calling"
+
+	// FeatureTokenCounting is support for exact token counting by the provider
+	FeatureTokenCounting = "token_counting"
+
+	// FeatureSpeech is support for text-to-speech requests
+	FeatureSpeech = "speech"
+
+	// FeatureTranscription is support for speech-to-text requests
+	FeatureTranscription = "transcription"
+
+	// FeatureBatch is support for asynchronous batches of chat requests
+	FeatureBatch = "batch"
+
+	// FeatureGrammar is support for grammar-constrained generation
+	FeatureGrammar = "grammar"
+
+	// FeatureRealtime is support for realtime speech-to-speech sessions
+	FeatureRealtime = "realtime"
+
+	// FeatureReasoning is support for extended reasoning with a token budget
+	FeatureReasoning = "reasoning"
+
+	// FeatureStreamingInput is support for message content streamed from
+	// an io.Reader
+	FeatureStreamingInput = "streaming_input"
+)
+
 // Config represents the configuration for an AI provider client.
 //
 // This struct contains all the settings needed to create and configure
@@ -163,6 +1738,10 @@ type Config struct {
 	// Useful for custom deployments or proxy configurations
 	BaseURL string `json:"base_url,omitempty"`
 
+	// Model sets the default model for requests that do not specify one (optional)
+	// If empty, each adapter's default completion and chat models are used
+	Model string `json:"model,omitempty"`
+
 	// Timeout sets the maximum duration for API requests (optional)
 	// Default: 30 seconds if not specified
 	Timeout time.Duration `json:"timeout,omitempty"`
@@ -171,6 +1750,16 @@ type Config struct {
 	// Default: 3 retries if not specified
 	MaxRetries int `json:"max_retries,omitempty"`
 
+	// MaxRetryWait caps the cumulative backoff time across retries (optional)
+	// Applies independently of Timeout, which limits each attempt; 0 means no cap
+	MaxRetryWait time.Duration `json:"max_retry_wait,omitempty"`
+
+	// RequestCompressionThreshold gzip-compresses request bodies of at least
+	// this many bytes (optional, 0 disables)
+	// Only enable for providers and proxies that accept compressed requests;
+	// responses are decompressed regardless of this setting
+	RequestCompressionThreshold int `json:"request_compression_threshold,omitempty"`
+
 	// Temperature sets the default temperature for requests (optional, 0.0-2.0)
 	// Can be overridden on individual requests
 	Temperature *float64 `json:"temperature,omitempty" validate:"omitempty,min=0,max=2"`
@@ -178,6 +1767,489 @@ type Config struct {
 	// MaxTokens sets the default maximum tokens for requests (optional)
 	// Can be overridden on individual requests
 	MaxTokens *int `json:"max_tokens,omitempty" validate:"omitempty,min=1"`
+
+	// UsageRecorder receives a usage record after every successful request (optional)
+	// See the usage package for an aggregating recorder with periodic export,
+	// and usage.Budget for spend limits; a UsageRecorder implementing
+	// BudgetGuard is checked before every request
+	UsageRecorder UsageRecorder `json:"-"`
+
+	// Store persists every successful request and response (optional)
+	// See the store package for a SQLite implementation
+	Store InteractionStore `json:"-"`
+
+	// ShutdownTimeout is how long Close waits for in-flight requests and
+	// streams to finish, and then for UsageRecorder and Store to flush
+	// (optional)
+	// Default: 30 seconds if not specified
+	ShutdownTimeout time.Duration `json:"shutdown_timeout,omitempty"`
+
+	// OnStoreError is called when Store fails to save an interaction (optional)
+	// Store failures never fail the request itself
+	OnStoreError func(error) `json:"-"`
+
+	// PricingFile is a JSON pricing table overriding default model prices (optional)
+	// Used to compute UsageRecord.Cost; see the pricing package for the format
+	PricingFile string `json:"pricing_file,omitempty"`
+
+	// UnsupportedParameterPolicy controls how parameters the provider does not
+	// support are handled: "drop" (default), "warn" or "error"
+	UnsupportedParameterPolicy UnsupportedParameterPolicy `json:"unsupported_parameter_policy,omitempty"`
+
+	// OnUnsupportedParameter is called for each dropped parameter when the
+	// policy is "warn" (optional)
+	OnUnsupportedParameter func(UnsupportedParameter) `json:"-"`
+
+	// ValidationMode controls how temperature, max tokens and stop sequence
+	// values beyond the provider's limits are handled: "clamp" (default),
+	// "strict" or "warn"
+	// Negative temperatures and non-positive max tokens are always rejected
+	ValidationMode ValidationMode `json:"validation_mode,omitempty"`
+
+	// OnOutOfRangeParameter is called for each clamped parameter when the
+	// validation mode is "warn" (optional)
+	OnOutOfRangeParameter func(OutOfRangeParameter) `json:"-"`
+
+	// OnDeprecatedModel is called for each request for a model with a known
+	// deprecation in the models package registry, e.g. to log it or count it
+	// in a metric; requests are still sent (optional)
+	OnDeprecatedModel func(ModelDeprecation) `json:"-"`
+
+	// DowngradeModels maps models to cheaper or backup models used in their
+	// place for DowngradeCooldown after the provider reports exhausted quota
+	// or a billing problem for them, e.g. {"gpt-4o": "gpt-4o-mini"} (optional)
+	DowngradeModels map[string]string `json:"downgrade_models,omitempty"`
+
+	// DowngradeCooldown is how long requests are downgraded after a quota
+	// error (optional)
+	// Default: 15 minutes if not specified
+	DowngradeCooldown time.Duration `json:"downgrade_cooldown,omitempty"`
+
+	// OnDowngrade is called when a downgrade starts and when its cool-down
+	// ends, so operators know degraded service is active (optional)
+	OnDowngrade func(DowngradeEvent) `json:"-"`
+
+	// Cache serves repeated completion and chat requests from stored
+	// responses instead of calling the provider again (optional)
+	// Requests match when they are equal after normalization, ignoring
+	// whitespace differences, tags and user IDs. Requests with a
+	// Temperature above 0, or with none from the request or Config.Temperature
+	// so the provider's default applies, are sampled and not cached unless
+	// CacheSampled is set
+	Cache ResponseCache `json:"-"`
+
+	// CacheSampled caches sampled requests too, so repeated sampled
+	// requests return the first sample (optional)
+	CacheSampled bool `json:"cache_sampled,omitempty"`
+
+	// CacheTTL is how long responses stay in Cache (optional)
+	// Default: 1 hour if not specified
+	CacheTTL time.Duration `json:"cache_ttl,omitempty"`
+
+	// OnCacheError is called when Cache fails (optional)
+	// Cache failures never fail the request, which is then sent uncached
+	OnCacheError func(error) `json:"-"`
+
+	// RequestsPerMinute limits the requests sent per provider and API key;
+	// requests over the limit wait for their turn (optional)
+	// Default: unlimited if not specified
+	RequestsPerMinute float64 `json:"requests_per_minute,omitempty"`
+
+	// RateLimitBurst is how many requests may be sent at once above the
+	// steady rate of RequestsPerMinute (optional)
+	// Default: one second's worth of requests, at least 1
+	RateLimitBurst int `json:"rate_limit_burst,omitempty"`
+
+	// RateLimiterStore holds the token buckets of RequestsPerMinute (optional)
+	// Defaults to an in-process store; set a shared store such as
+	// ratelimit.RedisStore so all replicas of a service share one bucket
+	RateLimiterStore RateLimiterStore `json:"-"`
+
+	// OnRateLimiterError is called when RateLimiterStore fails (optional)
+	// The request is then sent without waiting
+	OnRateLimiterError func(error) `json:"-"`
+
+	// OnWarning is called for each adjustment made to a request before it is
+	// sent, whatever the unsupported parameter policy and validation mode,
+	// and for requests for deprecated models; the same warnings are listed
+	// in ResponseMetadata.Warnings (optional)
+	OnWarning func(Warning) `json:"-"`
+
+	// ErrorSanitization removes prompt text echoed back in provider error
+	// messages before they reach logs: "off" (default), "strip" or "hash"
+	// The unsanitized provider error stays available through errors.Unwrap
+	ErrorSanitization ErrorSanitizationPolicy `json:"error_sanitization,omitempty"`
+
+	// PromptInjectionGuard wraps chat messages marked Untrusted in delimiting
+	// tags and adds a system instruction to treat them as data (optional)
+	PromptInjectionGuard bool `json:"prompt_injection_guard,omitempty"`
+
+	// InjectionDetector flags suspicious instructions in untrusted messages,
+	// returning the reasons or nil (optional)
+	// Defaults to guard.Detect when PromptInjectionGuard is enabled
+	InjectionDetector func(content string) []string `json:"-"`
+
+	// OnInjectionDetected is called for each flagged untrusted message (optional)
+	// Findings are also reported in ResponseMetadata.InjectionFindings
+	OnInjectionDetected func(InjectionFinding) `json:"-"`
+
+	// RedactReasoning removes the provider's reasoning from responses and
+	// streams before they are returned, stored or recorded, and from the
+	// bodies logged with DebugPayloads or kept in debug bundles, for
+	// compliance regimes that forbid keeping intermediate reasoning
+	// (optional). Token usage still includes the reasoning tokens.
+	RedactReasoning bool `json:"redact_reasoning,omitempty"`
+
+	// Profiles registers named request defaults selectable with the request
+	// Profile field (optional); more can be added with Client.RegisterProfile
+	Profiles map[string]RequestProfile `json:"profiles,omitempty"`
+
+	// Experiments route a percentage of users, identified by the request
+	// UserID, to alternative models or prompts (optional)
+	Experiments []Experiment `json:"experiments,omitempty"`
+
+	// Flags evaluates the feature flags of experiments with a Flag, e.g.
+	// through LaunchDarkly or OpenFeature (optional)
+	Flags FlagProvider `json:"-"`
+
+	// OnFlagError is called when a feature flag fails to evaluate; the
+	// request is then served the experiment's control group (optional)
+	OnFlagError func(key string, err error) `json:"-"`
+
+	// Prompts holds the versioned prompts selectable with the request
+	// PromptName field (optional)
+	Prompts PromptStore `json:"-"`
+
+	// PromptVersions pins prompt names to the version this client uses,
+	// e.g. {"support-agent": "1.2.0"} (optional)
+	// Unpinned prompts use their latest version; requests may override the pin
+	PromptVersions map[string]string `json:"prompt_versions,omitempty"`
+
+	// StreamIdleTimeout aborts a streamed response when no data, including
+	// keep-alives, arrives for this long (optional)
+	// Default: 60 seconds if not specified
+	StreamIdleTimeout time.Duration `json:"stream_idle_timeout,omitempty"`
+
+	// StreamStallRetries is how many times a stalled stream is reopened before
+	// any content was received (optional)
+	// Streams that stall after content was received fail with a retryable error
+	StreamStallRetries int `json:"stream_stall_retries,omitempty"`
+
+	// StreamBufferSize is the number of chunks read from the provider ahead
+	// of ChatStream.Recv (optional)
+	// Zero reads each chunk when Recv is called. Reading pauses while the
+	// buffer is full, so slow consumers hold back the provider instead of
+	// growing memory
+	StreamBufferSize int `json:"stream_buffer_size,omitempty"`
+
+	// DebugPayloads logs every HTTP exchange with the provider: the outbound
+	// JSON with credentials and DebugRedactFields redacted, the response status
+	// and the truncated response body (optional, off by default)
+	// Intended for debugging rejected requests; prompts are logged unless redacted
+	DebugPayloads bool `json:"debug_payloads,omitempty"`
+
+	// DebugLogger receives the exchanges logged when DebugPayloads is enabled
+	// (optional); it is never called otherwise
+	// Default: the standard library logger
+	DebugLogger func(DebugEntry) `json:"-"`
+
+	// DebugRedactFields lists JSON field names, at any depth, whose values are
+	// redacted from logged request bodies, e.g. "content" to hide prompts (optional)
+	DebugRedactFields []string `json:"debug_redact_fields,omitempty"`
+
+	// DebugBodyLimit truncates logged response bodies to this many bytes (optional)
+	// Default: 2048 if not specified
+	DebugBodyLimit int `json:"debug_body_limit,omitempty"`
+
+	// DebugBundles attaches a DebugBundle to the errors of failed Complete
+	// and ChatComplete calls (optional, off by default)
+	// Bundles hold the request and the full HTTP exchanges with credentials
+	// redacted, so they contain prompts; they can be replayed to reproduce
+	// an issue
+	DebugBundles bool `json:"debug_bundles,omitempty"`
+
+	// RequestTransformer rewrites the JSON payload of every request just
+	// before it is sent, e.g. to set provider fields the wrapper does not
+	// model yet (optional)
+	// An error aborts the request. Multipart uploads are not transformed
+	RequestTransformer func(provider ProviderType, payload []byte) ([]byte, error) `json:"-"`
+
+	// ResponseTransformer rewrites every raw response body before it is
+	// parsed, e.g. to unwrap a gateway envelope (optional)
+	// Streamed responses are transformed one event's data at a time, and
+	// successful binary downloads not at all. An error fails the request
+	ResponseTransformer func(provider ProviderType, body []byte) ([]byte, error) `json:"-"`
+
+	// UsageVerificationTolerance enables checking provider-reported token
+	// usage against local token counts of the prompt and output (optional)
+	// Counts deviating by more than this fraction of the local count, e.g.
+	// 0.5 for 50%, are reported in ResponseMetadata.UsageMismatches, usage
+	// records and to OnUsageMismatch. 0 disables verification
+	UsageVerificationTolerance float64 `json:"usage_verification_tolerance,omitempty"`
+
+	// UsageTokenCounter counts tokens for usage verification (optional)
+	// Defaults to the heuristic tokenizer.Default; plug in an exact tokenizer
+	// to lower the tolerance
+	UsageTokenCounter func(text string) int `json:"-"`
+
+	// OnUsageMismatch is called for each usage count that diverged from the
+	// local count (optional)
+	OnUsageMismatch func(UsageMismatch) `json:"-"`
+
+	// Organization is the OpenAI organization requests are made under
+	// (optional), for API keys that belong to several organizations
+	Organization string `json:"organization,omitempty"`
+
+	// ProjectKeys maps OpenAI project IDs to API keys of those projects
+	// (optional), so one client can bill requests to different projects
+	// Requests select a project with their Project field or WithProject;
+	// requests without one use APIKey
+	ProjectKeys map[string]string `json:"project_keys,omitempty"`
+
+	// AllowedBaseURLs lists the base URLs requests may be sent to instead of
+	// BaseURL with WithEndpoint (optional); overrides are rejected if empty
+	AllowedBaseURLs []string `json:"allowed_base_urls,omitempty"`
+
+	// AllowedHeaders lists the names of the headers requests may add with
+	// WithEndpoint, matched case-insensitively (optional); header overrides
+	// are rejected if empty
+	AllowedHeaders []string `json:"allowed_headers,omitempty"`
+}
+
+// flagSubjectKey is the context key of the subject set with WithFlagSubject
+type flagSubjectKey struct{}
+
+// WithFlagSubject returns a context carrying the subject feature flags are
+// evaluated for. The client sets it before calling Config.Flags; it is
+// exported so FlagProvider implementations can be tested.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - subject: The request's user and tags
+//
+// Returns:
+//   - context.Context: A context carrying the subject
+func WithFlagSubject(ctx context.Context, subject FlagSubject) context.Context {
+	return context.WithValue(ctx, flagSubjectKey{}, subject)
+}
+
+// FlagSubjectFromContext returns the subject set with WithFlagSubject, or a
+// zero FlagSubject if none was.
+func FlagSubjectFromContext(ctx context.Context) FlagSubject {
+	subject, _ := ctx.Value(flagSubjectKey{}).(FlagSubject)
+	return subject
+}
+
+// cancelGroupKey is the context key of the group set with WithCancelGroup
+type cancelGroupKey struct{}
+
+// WithCancelGroup returns a context adding the requests made with it to a
+// cancellation group, which Client.CancelGroup cancels as a whole.
+//
+// Groups are typically per user or page view, so all generations of a user
+// who navigated away can be stopped at once. Requests of the group that are
+// in flight, including open streams and requests waiting for the rate
+// limiter, fail with an error wrapping ErrGroupCancelled; requests made with
+// the context afterwards join the group anew.
+//
+// Example:
+//
+//	ctx = WithCancelGroup(ctx, "user-42")
+//	go client.ChatComplete(ctx, summaryReq)
+//	stream, err := client.StreamChat(ctx, chatReq)
+//
+//	// When the user's session ends
+//	client.CancelGroup("user-42")
+//
+// Parameters:
+//   - ctx: The parent context
+//   - group: The group ID
+//
+// Returns:
+//   - context.Context: A context carrying the group
+func WithCancelGroup(ctx context.Context, group string) context.Context {
+	return context.WithValue(ctx, cancelGroupKey{}, group)
+}
+
+// CancelGroupFromContext returns the group set with WithCancelGroup, or an
+// empty string if none was.
+func CancelGroupFromContext(ctx context.Context) string {
+	group, _ := ctx.Value(cancelGroupKey{}).(string)
+	return group
+}
+
+// projectKey is the context key of the project selected with WithProject
+type projectKey struct{}
+
+// WithProject returns a context selecting the OpenAI project of
+// Config.ProjectKeys that requests made with it are billed to.
+//
+// The project applies to every request made with the context, including
+// those without a Project field such as speech requests, so it can be set
+// once per incoming request, e.g. by the middleware identifying the team.
+// A request's own Project field takes precedence.
+//
+// Example:
+//
+//	ctx = WithProject(ctx, "proj_search")
+//	resp, err := client.ChatComplete(ctx, req)
+//
+// Parameters:
+//   - ctx: The parent context
+//   - project: A project ID from Config.ProjectKeys
+//
+// Returns:
+//   - context.Context: A context carrying the project
+func WithProject(ctx context.Context, project string) context.Context {
+	return context.WithValue(ctx, projectKey{}, project)
+}
+
+// ProjectFromContext returns the project selected with WithProject, or an
+// empty string if none was.
+func ProjectFromContext(ctx context.Context) string {
+	project, _ := ctx.Value(projectKey{}).(string)
+	return project
+}
+
+// sessionKey is the context key of the session selected with WithSession
+type sessionKey struct{}
+
+// WithSession returns a context marking the requests made with it as turns
+// of one session, such as a conversation. Clients that balance or fall back
+// between providers can use it to keep a session on the same provider and
+// model; conversation.Conversation sets it automatically.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - session: An ID unique to the session
+//
+// Returns:
+//   - context.Context: A context carrying the session
+func WithSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFromContext returns the session selected with WithSession, or an
+// empty string if none was.
+func SessionFromContext(ctx context.Context) string {
+	session, _ := ctx.Value(sessionKey{}).(string)
+	return session
+}
+
+// Endpoint overrides where requests are sent, for gateways that route each
+// tenant to its own upstream deployment through a single client.
+type Endpoint struct {
+	// BaseURL replaces Config.BaseURL; it must be listed in
+	// Config.AllowedBaseURLs (optional)
+	BaseURL string `json:"base_url,omitempty"`
+
+	// Headers are added to the request headers, replacing any of the same
+	// name; each must be listed in Config.AllowedHeaders (optional)
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// endpointKey is the context key of the endpoint selected with WithEndpoint
+type endpointKey struct{}
+
+// WithEndpoint returns a context sending the requests made with it to another
+// base URL, with extra headers. The client and adapters reject endpoints not
+// allowed by Config.AllowedBaseURLs and Config.AllowedHeaders, so tenant
+// input cannot redirect requests or credentials elsewhere.
+//
+// Example:
+//
+//	ctx = WithEndpoint(ctx, Endpoint{
+//		BaseURL: "https://eu.gateway.example.com/v1",
+//		Headers: map[string]string{"X-Tenant": tenant},
+//	})
+//	resp, err := client.ChatComplete(ctx, req)
+//
+// Parameters:
+//   - ctx: The parent context
+//   - endpoint: The base URL and headers of the requests
+//
+// Returns:
+//   - context.Context: A context carrying the endpoint
+func WithEndpoint(ctx context.Context, endpoint Endpoint) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
+
+// EndpointFromContext returns the endpoint selected with WithEndpoint, and
+// whether one was.
+func EndpointFromContext(ctx context.Context) (Endpoint, bool) {
+	endpoint, ok := ctx.Value(endpointKey{}).(Endpoint)
+	return endpoint, ok
+}
+
+// CheckEndpoint returns an error if the endpoint's base URL is not in
+// AllowedBaseURLs or one of its headers is not in AllowedHeaders. Trailing
+// slashes are ignored when comparing base URLs.
+func (c Config) CheckEndpoint(endpoint Endpoint) error {
+	if endpoint.BaseURL != "" {
+		allowed := false
+		for _, baseURL := range c.AllowedBaseURLs {
+			if strings.TrimSuffix(baseURL, "/") == strings.TrimSuffix(endpoint.BaseURL, "/") {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("base URL %q is not in the allowed base URLs", endpoint.BaseURL)
+		}
+	}
+	for name := range endpoint.Headers {
+		allowed := false
+		for _, header := range c.AllowedHeaders {
+			if strings.EqualFold(header, name) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("header %q is not in the allowed headers", name)
+		}
+	}
+	return nil
+}
+
+// DebugEntry is one HTTP exchange with a provider logged with Config.DebugPayloads.
+//
+// Credentials are always redacted: the values of authorization and API key
+// headers and of key or token query parameters are replaced with
+// "[REDACTED]". The request body is logged byte for byte unless
+// Config.DebugRedactFields is set, in which case it is re-encoded with the
+// listed fields redacted.
+type DebugEntry struct {
+	// Method is the HTTP method
+	Method string `json:"method"`
+
+	// URL is the request URL with credentials redacted
+	URL string `json:"url"`
+
+	// Headers are the request headers with credentials redacted
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// RequestBody is the outbound JSON payload
+	RequestBody string `json:"request_body,omitempty"`
+
+	// Attempt is the attempt number, starting at 1, when the request is retried
+	Attempt int `json:"attempt"`
+
+	// Status is the response status code, or zero if the request failed
+	Status int `json:"status,omitempty"`
+
+	// ResponseBody is the start of the response body; it is omitted for
+	// successful streamed responses, which are read by the caller
+	ResponseBody string `json:"response_body,omitempty"`
+
+	// Truncated reports whether ResponseBody was cut at Config.DebugBodyLimit
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Duration is the time until the response headers arrived or the request failed
+	Duration time.Duration `json:"duration"`
+
+	// Error is the transport error message, if the request failed
+	Error string `json:"error,omitempty"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
@@ -215,15 +2287,39 @@ func DefaultConfig() Config {
 // clients in containerized or cloud environments.
 //
 // Environment Variables by Provider:
-//   - OpenAI: OPENAI_API_KEY, OPENAI_BASE_URL
-//   - Anthropic: ANTHROPIC_API_KEY, ANTHROPIC_BASE_URL
-//   - Google: GOOGLE_API_KEY, GOOGLE_BASE_URL
+//   - OpenAI: OPENAI_API_KEY, OPENAI_BASE_URL, OPENAI_MODEL
+//   - Anthropic: ANTHROPIC_API_KEY, ANTHROPIC_BASE_URL, ANTHROPIC_MODEL
+//   - Google: GOOGLE_API_KEY, GOOGLE_BASE_URL, GOOGLE_MODEL
 //
 // Common Environment Variables:
+//   - AI_MODEL: Default model when the provider-specific variable is not set
 //   - AI_TIMEOUT: Request timeout (e.g., "30s", "1m")
 //   - AI_MAX_RETRIES: Maximum retry attempts (integer)
+//   - AI_MAX_RETRY_WAIT: Cumulative retry backoff budget (e.g., "20s")
+//   - AI_REQUEST_COMPRESSION_THRESHOLD: Minimum request body size in bytes to gzip (integer)
 //   - AI_TEMPERATURE: Default temperature (float, 0.0-2.0)
 //   - AI_MAX_TOKENS: Default max tokens (integer)
+//   - AI_PRICING_FILE: Path to a JSON pricing table overriding default prices
+//   - AI_UNSUPPORTED_PARAMETER_POLICY: Handling of unsupported parameters (drop, warn, error)
+//   - AI_VALIDATION_MODE: Handling of out-of-range temperature, max tokens and stop sequences (clamp, strict, warn)
+//   - AI_ERROR_SANITIZATION: Handling of prompt echoes in provider errors (off, strip, hash)
+//   - AI_PROMPT_INJECTION_GUARD: Wrap untrusted chat messages (boolean)
+//   - AI_REDACT_REASONING: Remove provider reasoning from responses (boolean)
+//   - AI_STREAM_IDLE_TIMEOUT: Stream inactivity timeout (e.g., "45s")
+//   - AI_SHUTDOWN_TIMEOUT: How long Close waits for in-flight requests (e.g., "20s")
+//   - AI_STREAM_STALL_RETRIES: Reopen attempts for streams stalled before any content (integer)
+//   - AI_STREAM_BUFFER_SIZE: Chunks read ahead of a stream's consumer (integer)
+//   - AI_DEBUG_PAYLOADS: Log redacted provider requests and responses (boolean)
+//   - AI_DEBUG_REDACT_FIELDS: Comma-separated JSON fields redacted from logged requests
+//   - AI_DEBUG_BUNDLES: Attach replayable debug bundles to request errors (boolean)
+//   - AI_ALLOWED_BASE_URLS: Comma-separated base URLs requests may be routed to with WithEndpoint
+//   - AI_ALLOWED_HEADERS: Comma-separated header names requests may add with WithEndpoint
+//   - AI_DOWNGRADE_MODELS: Comma-separated model=replacement pairs used after quota errors
+//   - AI_DOWNGRADE_COOLDOWN: How long requests are downgraded after a quota error (e.g., "10m")
+//   - AI_PROMPT_VERSIONS: Comma-separated name=version pairs pinning versioned prompts
+//   - AI_CACHE_TTL: How long responses stay in Config.Cache (e.g., "24h")
+//   - AI_REQUESTS_PER_MINUTE: Requests per minute allowed per provider and API key (float)
+//   - AI_RATE_LIMIT_BURST: Requests allowed at once above the steady rate (integer)
 //
 // Example:
 //
@@ -239,53 +2335,218 @@ func DefaultConfig() Config {
 func LoadConfigFromEnv(provider ProviderType) Config {
 	config := DefaultConfig()
 
-	// Load API key based on provider
-	switch provider {
-	case ProviderOpenAI:
-		if key := os.Getenv("OPENAI_API_KEY"); key != "" {
-			config.APIKey = key
+	// Load API key based on provider
+	switch provider {
+	case ProviderOpenAI:
+		if key := os.Getenv("OPENAI_API_KEY"); key != "" {
+			config.APIKey = key
+		}
+		if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+			config.BaseURL = baseURL
+		}
+		if model := os.Getenv("OPENAI_MODEL"); model != "" {
+			config.Model = model
+		}
+	case ProviderAnthropic:
+		if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+			config.APIKey = key
+		}
+		if baseURL := os.Getenv("ANTHROPIC_BASE_URL"); baseURL != "" {
+			config.BaseURL = baseURL
+		}
+		if model := os.Getenv("ANTHROPIC_MODEL"); model != "" {
+			config.Model = model
+		}
+	case ProviderGoogle:
+		if key := os.Getenv("GOOGLE_API_KEY"); key != "" {
+			config.APIKey = key
+		}
+		if baseURL := os.Getenv("GOOGLE_BASE_URL"); baseURL != "" {
+			config.BaseURL = baseURL
+		}
+		if model := os.Getenv("GOOGLE_MODEL"); model != "" {
+			config.Model = model
+		}
+	}
+
+	// Load common configuration from environment
+	if config.Model == "" {
+		config.Model = os.Getenv("AI_MODEL")
+	}
+
+	if timeout := os.Getenv("AI_TIMEOUT"); timeout != "" {
+		if duration, err := time.ParseDuration(timeout); err == nil {
+			config.Timeout = duration
+		}
+	}
+
+	if retries := os.Getenv("AI_MAX_RETRIES"); retries != "" {
+		if maxRetries, err := strconv.Atoi(retries); err == nil && maxRetries >= 0 {
+			config.MaxRetries = maxRetries
+		}
+	}
+
+	if wait := os.Getenv("AI_MAX_RETRY_WAIT"); wait != "" {
+		if duration, err := time.ParseDuration(wait); err == nil && duration >= 0 {
+			config.MaxRetryWait = duration
+		}
+	}
+
+	if threshold := os.Getenv("AI_REQUEST_COMPRESSION_THRESHOLD"); threshold != "" {
+		if size, err := strconv.Atoi(threshold); err == nil && size >= 0 {
+			config.RequestCompressionThreshold = size
+		}
+	}
+
+	if temp := os.Getenv("AI_TEMPERATURE"); temp != "" {
+		if temperature, err := strconv.ParseFloat(temp, 64); err == nil {
+			config.Temperature = &temperature
+		}
+	}
+
+	if tokens := os.Getenv("AI_MAX_TOKENS"); tokens != "" {
+		if maxTokens, err := strconv.Atoi(tokens); err == nil && maxTokens > 0 {
+			config.MaxTokens = &maxTokens
+		}
+	}
+
+	if pricingFile := os.Getenv("AI_PRICING_FILE"); pricingFile != "" {
+		config.PricingFile = pricingFile
+	}
+
+	if policy := os.Getenv("AI_UNSUPPORTED_PARAMETER_POLICY"); policy != "" {
+		config.UnsupportedParameterPolicy = UnsupportedParameterPolicy(strings.ToLower(policy))
+	}
+
+	if mode := os.Getenv("AI_VALIDATION_MODE"); mode != "" {
+		config.ValidationMode = ValidationMode(strings.ToLower(mode))
+	}
+
+	if sanitization := os.Getenv("AI_ERROR_SANITIZATION"); sanitization != "" {
+		config.ErrorSanitization = ErrorSanitizationPolicy(strings.ToLower(sanitization))
+	}
+
+	if guard := os.Getenv("AI_PROMPT_INJECTION_GUARD"); guard != "" {
+		if enabled, err := strconv.ParseBool(guard); err == nil {
+			config.PromptInjectionGuard = enabled
 		}
-		if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
-			config.BaseURL = baseURL
+	}
+
+	if redact := os.Getenv("AI_REDACT_REASONING"); redact != "" {
+		if enabled, err := strconv.ParseBool(redact); err == nil {
+			config.RedactReasoning = enabled
 		}
-	case ProviderAnthropic:
-		if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
-			config.APIKey = key
+	}
+
+	if timeout := os.Getenv("AI_SHUTDOWN_TIMEOUT"); timeout != "" {
+		if duration, err := time.ParseDuration(timeout); err == nil && duration >= 0 {
+			config.ShutdownTimeout = duration
 		}
-		if baseURL := os.Getenv("ANTHROPIC_BASE_URL"); baseURL != "" {
-			config.BaseURL = baseURL
+	}
+
+	if timeout := os.Getenv("AI_STREAM_IDLE_TIMEOUT"); timeout != "" {
+		if duration, err := time.ParseDuration(timeout); err == nil && duration >= 0 {
+			config.StreamIdleTimeout = duration
 		}
-	case ProviderGoogle:
-		if key := os.Getenv("GOOGLE_API_KEY"); key != "" {
-			config.APIKey = key
+	}
+
+	if retries := os.Getenv("AI_STREAM_STALL_RETRIES"); retries != "" {
+		if stallRetries, err := strconv.Atoi(retries); err == nil && stallRetries >= 0 {
+			config.StreamStallRetries = stallRetries
 		}
-		if baseURL := os.Getenv("GOOGLE_BASE_URL"); baseURL != "" {
-			config.BaseURL = baseURL
+	}
+
+	if size := os.Getenv("AI_STREAM_BUFFER_SIZE"); size != "" {
+		if bufferSize, err := strconv.Atoi(size); err == nil && bufferSize >= 0 {
+			config.StreamBufferSize = bufferSize
 		}
 	}
 
-	// Load common configuration from environment
-	if timeout := os.Getenv("AI_TIMEOUT"); timeout != "" {
-		if duration, err := time.ParseDuration(timeout); err == nil {
-			config.Timeout = duration
+	if debug := os.Getenv("AI_DEBUG_PAYLOADS"); debug != "" {
+		if enabled, err := strconv.ParseBool(debug); err == nil {
+			config.DebugPayloads = enabled
 		}
 	}
 
-	if retries := os.Getenv("AI_MAX_RETRIES"); retries != "" {
-		if maxRetries, err := strconv.Atoi(retries); err == nil && maxRetries >= 0 {
-			config.MaxRetries = maxRetries
+	if bundles := os.Getenv("AI_DEBUG_BUNDLES"); bundles != "" {
+		if enabled, err := strconv.ParseBool(bundles); err == nil {
+			config.DebugBundles = enabled
 		}
 	}
 
-	if temp := os.Getenv("AI_TEMPERATURE"); temp != "" {
-		if temperature, err := strconv.ParseFloat(temp, 64); err == nil {
-			config.Temperature = &temperature
+	if fields := os.Getenv("AI_DEBUG_REDACT_FIELDS"); fields != "" {
+		for _, field := range strings.Split(fields, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				config.DebugRedactFields = append(config.DebugRedactFields, field)
+			}
 		}
 	}
 
-	if tokens := os.Getenv("AI_MAX_TOKENS"); tokens != "" {
-		if maxTokens, err := strconv.Atoi(tokens); err == nil && maxTokens > 0 {
-			config.MaxTokens = &maxTokens
+	if baseURLs := os.Getenv("AI_ALLOWED_BASE_URLS"); baseURLs != "" {
+		for _, baseURL := range strings.Split(baseURLs, ",") {
+			if baseURL = strings.TrimSpace(baseURL); baseURL != "" {
+				config.AllowedBaseURLs = append(config.AllowedBaseURLs, baseURL)
+			}
+		}
+	}
+
+	if headers := os.Getenv("AI_ALLOWED_HEADERS"); headers != "" {
+		for _, header := range strings.Split(headers, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				config.AllowedHeaders = append(config.AllowedHeaders, header)
+			}
+		}
+	}
+
+	if downgrades := os.Getenv("AI_DOWNGRADE_MODELS"); downgrades != "" {
+		for _, pair := range strings.Split(downgrades, ",") {
+			model, replacement, ok := strings.Cut(pair, "=")
+			model, replacement = strings.TrimSpace(model), strings.TrimSpace(replacement)
+			if !ok || model == "" || replacement == "" {
+				continue
+			}
+			if config.DowngradeModels == nil {
+				config.DowngradeModels = make(map[string]string)
+			}
+			config.DowngradeModels[model] = replacement
+		}
+	}
+
+	if cooldown := os.Getenv("AI_DOWNGRADE_COOLDOWN"); cooldown != "" {
+		if duration, err := time.ParseDuration(cooldown); err == nil && duration >= 0 {
+			config.DowngradeCooldown = duration
+		}
+	}
+
+	if versions := os.Getenv("AI_PROMPT_VERSIONS"); versions != "" {
+		for _, pair := range strings.Split(versions, ",") {
+			name, version, ok := strings.Cut(pair, "=")
+			name, version = strings.TrimSpace(name), strings.TrimSpace(version)
+			if !ok || name == "" || version == "" {
+				continue
+			}
+			if config.PromptVersions == nil {
+				config.PromptVersions = make(map[string]string)
+			}
+			config.PromptVersions[name] = version
+		}
+	}
+
+	if ttl := os.Getenv("AI_CACHE_TTL"); ttl != "" {
+		if duration, err := time.ParseDuration(ttl); err == nil && duration >= 0 {
+			config.CacheTTL = duration
+		}
+	}
+
+	if rpm := os.Getenv("AI_REQUESTS_PER_MINUTE"); rpm != "" {
+		if requestsPerMinute, err := strconv.ParseFloat(rpm, 64); err == nil && requestsPerMinute >= 0 {
+			config.RequestsPerMinute = requestsPerMinute
+		}
+	}
+
+	if burst := os.Getenv("AI_RATE_LIMIT_BURST"); burst != "" {
+		if rateLimitBurst, err := strconv.Atoi(burst); err == nil && rateLimitBurst >= 0 {
+			config.RateLimitBurst = rateLimitBurst
 		}
 	}
 
@@ -348,6 +2609,37 @@ func (c Config) Validate(provider ProviderType) error {
 		return fmt.Errorf("max retries must be non-negative, got: %d", c.MaxRetries)
 	}
 
+	// Validate retry budget
+	if c.MaxRetryWait < 0 {
+		return fmt.Errorf("max retry wait must be non-negative, got: %v", c.MaxRetryWait)
+	}
+	if c.RequestCompressionThreshold < 0 {
+		return fmt.Errorf("request compression threshold must be non-negative, got: %d", c.RequestCompressionThreshold)
+	}
+
+	if c.ShutdownTimeout < 0 {
+		return fmt.Errorf("shutdown timeout must be non-negative, got: %v", c.ShutdownTimeout)
+	}
+
+	// Validate stream settings
+	if c.StreamIdleTimeout < 0 {
+		return fmt.Errorf("stream idle timeout must be non-negative, got: %v", c.StreamIdleTimeout)
+	}
+	if c.StreamStallRetries < 0 {
+		return fmt.Errorf("stream stall retries must be non-negative, got: %d", c.StreamStallRetries)
+	}
+	if c.StreamBufferSize < 0 {
+		return fmt.Errorf("stream buffer size must be non-negative, got: %d", c.StreamBufferSize)
+	}
+
+	if c.UsageVerificationTolerance < 0 {
+		return fmt.Errorf("usage verification tolerance must be non-negative, got: %g", c.UsageVerificationTolerance)
+	}
+
+	if c.DebugBodyLimit < 0 {
+		return fmt.Errorf("debug body limit must be non-negative, got: %d", c.DebugBodyLimit)
+	}
+
 	// Validate temperature
 	if c.Temperature != nil {
 		temp := *c.Temperature
@@ -370,6 +2662,108 @@ func (c Config) Validate(provider ProviderType) error {
 		}
 	}
 
+	// Validate unsupported parameter policy
+	switch c.UnsupportedParameterPolicy {
+	case "", UnsupportedParameterDrop, UnsupportedParameterWarn, UnsupportedParameterError:
+	default:
+		return fmt.Errorf("unsupported parameter policy must be one of: drop, warn, error, got: %q", c.UnsupportedParameterPolicy)
+	}
+
+	switch c.ValidationMode {
+	case "", ValidationModeClamp, ValidationModeStrict, ValidationModeWarn:
+	default:
+		return fmt.Errorf("validation mode must be one of: clamp, strict, warn, got: %q", c.ValidationMode)
+	}
+
+	// Validate error sanitization policy
+	switch c.ErrorSanitization {
+	case "", ErrorSanitizationOff, ErrorSanitizationStrip, ErrorSanitizationHash:
+	default:
+		return fmt.Errorf("error sanitization must be one of: off, strip, hash, got: %q", c.ErrorSanitization)
+	}
+
+	// Validate project keys
+	if len(c.ProjectKeys) > 0 && provider != ProviderOpenAI {
+		return fmt.Errorf("project keys are only supported by %s", ProviderOpenAI)
+	}
+	for project, key := range c.ProjectKeys {
+		if strings.TrimSpace(project) == "" {
+			return fmt.Errorf("project ID cannot be empty")
+		}
+		if err := (Config{APIKey: key}).validateAPIKeyFormat(provider); err != nil {
+			return fmt.Errorf("invalid API key format for project %q: %w", project, err)
+		}
+	}
+
+	// Validate endpoint allowlists
+	for _, baseURL := range c.AllowedBaseURLs {
+		if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+			return fmt.Errorf("allowed base URL must start with http:// or https://, got: %q", baseURL)
+		}
+	}
+	for _, header := range c.AllowedHeaders {
+		if strings.TrimSpace(header) == "" {
+			return fmt.Errorf("allowed header name cannot be empty")
+		}
+	}
+
+	// Validate quota downgrades
+	for model, replacement := range c.DowngradeModels {
+		if strings.TrimSpace(model) == "" || strings.TrimSpace(replacement) == "" {
+			return fmt.Errorf("downgrade model names cannot be empty")
+		}
+		if model == replacement {
+			return fmt.Errorf("model %q cannot be downgraded to itself", model)
+		}
+	}
+	if c.DowngradeCooldown < 0 {
+		return fmt.Errorf("downgrade cooldown must be non-negative, got: %v", c.DowngradeCooldown)
+	}
+
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("cache TTL must be non-negative, got: %v", c.CacheTTL)
+	}
+
+	// Validate rate limits
+	if c.RequestsPerMinute < 0 {
+		return fmt.Errorf("requests per minute must be non-negative, got: %g", c.RequestsPerMinute)
+	}
+	if c.RateLimitBurst < 0 {
+		return fmt.Errorf("rate limit burst must be non-negative, got: %d", c.RateLimitBurst)
+	}
+
+	// Validate request profiles
+	for name, profile := range c.Profiles {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("request profile name cannot be empty")
+		}
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("request profile %q: %w", name, err)
+		}
+	}
+
+	// Validate prompt pins
+	for name, version := range c.PromptVersions {
+		if strings.TrimSpace(name) == "" || strings.TrimSpace(version) == "" {
+			return fmt.Errorf("pinned prompt names and versions cannot be empty")
+		}
+	}
+
+	// Validate experiments
+	experiments := make(map[string]bool, len(c.Experiments))
+	for _, experiment := range c.Experiments {
+		if err := experiment.Validate(); err != nil {
+			return fmt.Errorf("experiment %q: %w", experiment.Name, err)
+		}
+		if experiment.Flag != "" && c.Flags == nil {
+			return fmt.Errorf("experiment %q uses flag %q but no flag provider is configured", experiment.Name, experiment.Flag)
+		}
+		if experiments[experiment.Name] {
+			return fmt.Errorf("duplicate experiment name %q", experiment.Name)
+		}
+		experiments[experiment.Name] = true
+	}
+
 	return nil
 }
 
@@ -459,6 +2853,27 @@ func (c Config) WithBaseURL(baseURL string) Config {
 	return c
 }
 
+// WithModel returns a new config with the specified default model.
+//
+// The model is used by requests that do not set their own Model, allowing
+// deployments to switch models without code changes.
+//
+// Example:
+//
+//	config := DefaultConfig().
+//		WithAPIKey("sk-ant-your-key").
+//		WithModel("claude-3-5-sonnet-20241022")
+//
+// Parameters:
+//   - model: The provider model name to use by default
+//
+// Returns:
+//   - Config: A new configuration with the specified default model
+func (c Config) WithModel(model string) Config {
+	c.Model = model
+	return c
+}
+
 // WithTimeout returns a new config with the specified timeout.
 //
 // This method sets the maximum duration for API requests. Requests that
@@ -501,6 +2916,28 @@ func (c Config) WithMaxRetries(maxRetries int) Config {
 	return c
 }
 
+// WithMaxRetryWait returns a new config with the specified retry budget.
+//
+// Retries use exponential backoff with full jitter. The budget caps the total
+// time spent waiting between attempts, so a request never spends more than
+// maxRetryWait backing off regardless of the per-attempt timeout.
+//
+// Example:
+//
+//	config := DefaultConfig().
+//		WithAPIKey("sk-your-key").
+//		WithMaxRetryWait(10 * time.Second)
+//
+// Parameters:
+//   - maxRetryWait: The cumulative backoff budget (0 disables the cap)
+//
+// Returns:
+//   - Config: A new configuration with the specified retry budget
+func (c Config) WithMaxRetryWait(maxRetryWait time.Duration) Config {
+	c.MaxRetryWait = maxRetryWait
+	return c
+}
+
 // WithTemperature returns a new config with the specified temperature.
 //
 // This method sets the default temperature for all requests made with this
@@ -545,6 +2982,200 @@ func (c Config) WithMaxTokens(maxTokens int) Config {
 	return c
 }
 
+// WithUnsupportedParameterPolicy returns a new config with the specified policy.
+//
+// The policy controls what happens when a request sets a parameter the
+// provider does not support, such as more stop sequences than the provider
+// accepts. With the "warn" policy, each dropped parameter is passed to
+// onUnsupported.
+//
+// Example:
+//
+//	config := DefaultConfig().
+//		WithAPIKey("sk-your-key").
+//		WithUnsupportedParameterPolicy(UnsupportedParameterWarn, func(p UnsupportedParameter) {
+//			log.Printf("dropped parameter: %v", p)
+//		})
+//
+// Parameters:
+//   - policy: One of UnsupportedParameterDrop, UnsupportedParameterWarn or UnsupportedParameterError
+//   - onUnsupported: Callback for dropped parameters (optional, used by the warn policy)
+//
+// Returns:
+//   - Config: A new configuration with the specified policy
+func (c Config) WithUnsupportedParameterPolicy(policy UnsupportedParameterPolicy, onUnsupported func(UnsupportedParameter)) Config {
+	c.UnsupportedParameterPolicy = policy
+	c.OnUnsupportedParameter = onUnsupported
+	return c
+}
+
+// WithValidationMode returns a new config with the specified validation mode.
+//
+// The mode controls what happens when a request's temperature, max tokens
+// or number of stop sequences exceeds the provider's limits. With the "warn"
+// mode, each clamped parameter is passed to onOutOfRange.
+//
+// Example:
+//
+//	config := DefaultConfig().
+//		WithAPIKey("sk-your-key").
+//		WithValidationMode(ValidationModeStrict, nil)
+//
+// Parameters:
+//   - mode: One of ValidationModeClamp, ValidationModeStrict or ValidationModeWarn
+//   - onOutOfRange: Callback for clamped parameters (optional, used by the warn mode)
+//
+// Returns:
+//   - Config: A new configuration with the specified validation mode
+func (c Config) WithValidationMode(mode ValidationMode, onOutOfRange func(OutOfRangeParameter)) Config {
+	c.ValidationMode = mode
+	c.OnOutOfRangeParameter = onOutOfRange
+	return c
+}
+
+// WithPromptInjectionGuard returns a copy of the config with the prompt injection guard configured.
+//
+// When enabled, chat messages marked Untrusted are wrapped in delimiting tags
+// and the model is instructed to treat them as data. Untrusted messages are
+// also checked by detector (guard.Detect if nil), and each finding is passed
+// to onDetected and reported in the response metadata.
+//
+// Example:
+//
+//	config := DefaultConfig().
+//		WithAPIKey("sk-your-key").
+//		WithPromptInjectionGuard(true, nil, func(f InjectionFinding) {
+//			log.Printf("suspicious content in message %d: %v", f.MessageIndex, f.Reasons)
+//		})
+//
+// Parameters:
+//   - enabled: Whether untrusted messages are wrapped
+//   - detector: Custom detection heuristic (optional)
+//   - onDetected: Callback for flagged messages (optional)
+//
+// Returns:
+//   - Config: A new configuration with the guard configured
+func (c Config) WithPromptInjectionGuard(enabled bool, detector func(content string) []string, onDetected func(InjectionFinding)) Config {
+	c.PromptInjectionGuard = enabled
+	c.InjectionDetector = detector
+	c.OnInjectionDetected = onDetected
+	return c
+}
+
+// WithStreamStallDetection returns a copy of the config with stream stall detection configured.
+//
+// A streamed response that receives no data, including keep-alives, for
+// idleTimeout is aborted with a retryable network error instead of hanging.
+// Streams that stall before any content was received are reopened up to
+// retries times.
+//
+// Example:
+//
+//	config := DefaultConfig().
+//		WithAPIKey("sk-ant-your-key").
+//		WithStreamStallDetection(20*time.Second, 2)
+//
+// Parameters:
+//   - idleTimeout: Maximum time between received data (0 for the 60 second default)
+//   - retries: Reopen attempts for streams stalled before any content
+//
+// Returns:
+//   - Config: A new configuration with the specified stall detection
+func (c Config) WithStreamStallDetection(idleTimeout time.Duration, retries int) Config {
+	c.StreamIdleTimeout = idleTimeout
+	c.StreamStallRetries = retries
+	return c
+}
+
+// WithRequestTransformer returns a copy of the config with a request payload transformer.
+//
+// The transformer receives the provider and the JSON payload the adapter
+// built, and returns the payload to send instead. It runs once per JSON
+// request, before any retries, after validation and parameter mapping, so
+// patched fields bypass both.
+//
+// Example:
+//
+//	config := DefaultConfig().
+//		WithAPIKey("sk-your-key").
+//		WithRequestTransformer(func(provider ProviderType, payload []byte) ([]byte, error) {
+//			var body map[string]interface{}
+//			if err := json.Unmarshal(payload, &body); err != nil {
+//				return nil, err
+//			}
+//			body["service_tier"] = "flex"
+//			return json.Marshal(body)
+//		})
+//
+// Parameters:
+//   - transformer: Function returning the payload to send, or an error to abort the request
+//
+// Returns:
+//   - Config: A new configuration with the transformer set
+func (c Config) WithRequestTransformer(transformer func(provider ProviderType, payload []byte) ([]byte, error)) Config {
+	c.RequestTransformer = transformer
+	return c
+}
+
+// WithResponseTransformer returns a copy of the config with a response body transformer.
+//
+// The transformer receives the provider and each raw, decompressed response
+// body, including error responses, and returns the body to parse instead.
+// For streamed responses it receives the data of each server-sent event.
+// Successful binary responses such as speech audio are not transformed.
+//
+// Example:
+//
+//	// Unwrap responses a gateway returns as {"data": <provider response>}
+//	config := DefaultConfig().
+//		WithAPIKey("sk-your-key").
+//		WithBaseURL("https://gateway.example.com/v1").
+//		WithResponseTransformer(func(provider ProviderType, body []byte) ([]byte, error) {
+//			var envelope struct {
+//				Data json.RawMessage `json:"data"`
+//			}
+//			if err := json.Unmarshal(body, &envelope); err != nil || envelope.Data == nil {
+//				return body, nil
+//			}
+//			return envelope.Data, nil
+//		})
+//
+// Parameters:
+//   - transformer: Function returning the body to parse, or an error to fail the request
+//
+// Returns:
+//   - Config: A new configuration with the transformer set
+func (c Config) WithResponseTransformer(transformer func(provider ProviderType, body []byte) ([]byte, error)) Config {
+	c.ResponseTransformer = transformer
+	return c
+}
+
+// WithProjectKey returns a copy of the config that can bill requests to an
+// OpenAI project with the project's API key.
+//
+// Example:
+//
+//	config := DefaultConfig().
+//		WithAPIKey("sk-your-default-key").
+//		WithProjectKey("proj_search", "sk-proj-search-key").
+//		WithProjectKey("proj_support", "sk-proj-support-key")
+//
+// Parameters:
+//   - project: The OpenAI project ID requests select
+//   - apiKey: An API key of the project
+//
+// Returns:
+//   - Config: A new configuration with the project key added
+func (c Config) WithProjectKey(project, apiKey string) Config {
+	keys := make(map[string]string, len(c.ProjectKeys)+1)
+	for id, key := range c.ProjectKeys {
+		keys[id] = key
+	}
+	keys[project] = apiKey
+	c.ProjectKeys = keys
+	return c
+}
+
 // ValidateProviderType validates that the provider type is supported.
 //
 // This function checks if the given provider type is one of the supported
@@ -575,3 +3206,96 @@ func ValidateProviderType(provider ProviderType) error {
 		return fmt.Errorf("unsupported provider '%s', supported providers: %v", provider, []ProviderType{ProviderOpenAI, ProviderAnthropic, ProviderGoogle})
 	}
 }
+
+// PageFunc fetches the page of a list API starting at cursor, the empty
+// string for the first page. It returns the page's items and the cursor of
+// the next page, empty after the last page.
+type PageFunc[T any] func(ctx context.Context, cursor string) (items []T, next string, err error)
+
+// Pager iterates over the pages of a list API, hiding whether the provider
+// pages with cursors, page tokens or offsets.
+//
+// Example:
+//
+//	pager := types.NewPager(listModels)
+//	for {
+//		models, err := pager.Next(ctx)
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		for _, model := range models {
+//			fmt.Println(model.ID)
+//		}
+//	}
+//
+// A Pager must not be used by multiple goroutines at once.
+type Pager[T any] struct {
+	fetch  PageFunc[T]
+	cursor string
+	done   bool
+}
+
+// NewPager returns a Pager over the pages fetch returns
+func NewPager[T any](fetch PageFunc[T]) *Pager[T] {
+	return &Pager[T]{fetch: fetch}
+}
+
+// NewOffsetPager returns a Pager over an offset-based list API, requesting
+// pageSize items at a time. A page shorter than pageSize is the last one.
+// The pager fails without calling fetch if pageSize is not positive.
+func NewOffsetPager[T any](pageSize int, fetch func(ctx context.Context, offset, limit int) ([]T, error)) *Pager[T] {
+	return NewPager(func(ctx context.Context, cursor string) ([]T, string, error) {
+		// Every page would be full, so paging would never end
+		if pageSize <= 0 {
+			return nil, "", fmt.Errorf("page size must be positive, got: %d", pageSize)
+		}
+		offset := 0
+		if cursor != "" {
+			var err error
+			if offset, err = strconv.Atoi(cursor); err != nil {
+				return nil, "", fmt.Errorf("invalid offset cursor %q", cursor)
+			}
+		}
+		items, err := fetch(ctx, offset, pageSize)
+		if err != nil || len(items) < pageSize {
+			return items, "", err
+		}
+		return items, strconv.Itoa(offset + len(items)), nil
+	})
+}
+
+// Next returns the items of the next page, skipping empty pages. It returns
+// io.EOF once every page has been read. After an error other than io.EOF,
+// Next may be called again to retry the same page.
+func (p *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	for !p.done {
+		items, next, err := p.fetch(ctx, p.cursor)
+		if err != nil {
+			return nil, err
+		}
+		p.cursor = next
+		p.done = next == ""
+		if len(items) > 0 {
+			return items, nil
+		}
+	}
+	return nil, io.EOF
+}
+
+// All returns the items of every remaining page
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for {
+		items, err := p.Next(ctx)
+		if err == io.EOF {
+			return all, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+	}
+}
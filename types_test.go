@@ -1,7 +1,11 @@
 package aiprovider
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
 	"testing"
 
 	"github.com/ajeet-kumar1087/ai-providers/types"
@@ -389,3 +393,67 @@ func TestProviderType(t *testing.T) {
 }
 
 // Helper functions are in test_utils.go
+
+func TestPager(t *testing.T) {
+	ctx := context.Background()
+	pages := map[string]struct {
+		items []string
+		next  string
+	}{
+		"":   {[]string{"a", "b"}, "p2"},
+		"p2": {nil, "p3"}, // Empty pages are skipped
+		"p3": {[]string{"c"}, ""},
+	}
+	var cursors []string
+	fail := true
+	pager := types.NewPager(func(ctx context.Context, cursor string) ([]string, string, error) {
+		cursors = append(cursors, cursor)
+		if cursor == "p3" && fail {
+			fail = false
+			return nil, "", errors.New("temporary failure")
+		}
+		return pages[cursor].items, pages[cursor].next, nil
+	})
+
+	if items, err := pager.Next(ctx); err != nil || !reflect.DeepEqual(items, []string{"a", "b"}) {
+		t.Fatalf("Expected the first page, got %v, %v", items, err)
+	}
+	if _, err := pager.Next(ctx); err == nil {
+		t.Fatal("Expected the fetch error")
+	}
+	if items, err := pager.Next(ctx); err != nil || !reflect.DeepEqual(items, []string{"c"}) {
+		t.Fatalf("Expected the failed page to be retried, got %v, %v", items, err)
+	}
+	if _, err := pager.Next(ctx); err != io.EOF {
+		t.Errorf("Expected io.EOF after the last page, got %v", err)
+	}
+	if !reflect.DeepEqual(cursors, []string{"", "p2", "p3", "p3"}) {
+		t.Errorf("Unexpected cursors %q", cursors)
+	}
+
+	// Offset pagers stop at a short page
+	numbers := []int{1, 2, 3, 4, 5}
+	var offsets []int
+	offsetPager := types.NewOffsetPager(2, func(ctx context.Context, offset, limit int) ([]int, error) {
+		offsets = append(offsets, offset)
+		end := offset + limit
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+		return numbers[offset:end], nil
+	})
+	all, err := offsetPager.All(ctx)
+	if err != nil || !reflect.DeepEqual(all, numbers) || !reflect.DeepEqual(offsets, []int{0, 2, 4}) {
+		t.Errorf("Expected every item in three pages, got %v at offsets %v, %v", all, offsets, err)
+	}
+
+	// Non-positive page sizes are rejected instead of paging forever
+	calls := 0
+	emptyPager := types.NewOffsetPager(0, func(ctx context.Context, offset, limit int) ([]int, error) {
+		calls++
+		return nil, nil
+	})
+	if _, err := emptyPager.All(ctx); err == nil || calls != 0 {
+		t.Errorf("Expected an error without fetching for page size 0, got %v after %d calls", err, calls)
+	}
+}
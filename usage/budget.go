@@ -0,0 +1,220 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// Window is the period a spend Threshold applies to. Windows follow the
+// clock: hourly windows start on the hour and daily windows at midnight.
+type Window string
+
+const (
+	// WindowHourly resets spend at the start of every hour
+	WindowHourly Window = "hourly"
+
+	// WindowDaily resets spend at midnight
+	WindowDaily Window = "daily"
+)
+
+// Threshold is a spend level of a Budget.
+type Threshold struct {
+	// Window is the period the spend is summed over
+	Window Window
+
+	// Amount is the spend in USD at which the threshold is reached
+	Amount float64
+
+	// HardStop rejects further requests with a *types.BudgetExceededError
+	// once the threshold is reached, until the window resets; without it the
+	// threshold only triggers OnThreshold
+	HardStop bool
+}
+
+// Alert describes a Threshold that was reached.
+type Alert struct {
+	// Threshold is the threshold reached
+	Threshold Threshold
+
+	// Spent is the spend in USD of the window when it was reached
+	Spent float64
+
+	// ResetAt is when the window ends
+	ResetAt time.Time
+}
+
+// BudgetOptions configures a Budget.
+type BudgetOptions struct {
+	// Thresholds are the spend levels to alert on or stop at, e.g. an alert
+	// at $50 and a hard stop at $100 a day
+	Thresholds []Threshold
+
+	// OnThreshold is called once per window for every threshold reached
+	// (optional). It is called synchronously by the request that crossed it,
+	// so it should not block.
+	OnThreshold func(Alert)
+
+	// Location is the time zone windows follow (default: UTC)
+	Location *time.Location
+
+	// Next receives every usage record after the budget, e.g. a Tracker
+	// (optional)
+	Next types.UsageRecorder
+
+	// CostFunc prices recorded usage, overriding the cost computed by the
+	// client (optional)
+	CostFunc CostFunc
+}
+
+// Budget sums the spend of usage records over hourly and daily windows,
+// calling OnThreshold as thresholds are reached and, for hard-stop
+// thresholds, rejecting further requests until the window resets.
+//
+// Budget implements types.UsageRecorder and types.BudgetGuard, so a client
+// configured with it as UsageRecorder checks it before every request:
+//
+//	budget, err := usage.NewBudget(usage.BudgetOptions{
+//		Thresholds: []usage.Threshold{
+//			{Window: usage.WindowDaily, Amount: 50},
+//			{Window: usage.WindowDaily, Amount: 100, HardStop: true},
+//		},
+//		OnThreshold: func(alert usage.Alert) {
+//			log.Printf("spent $%.2f of the %s budget", alert.Spent, alert.Threshold.Window)
+//		},
+//		Next: tracker,
+//	})
+//	config.UsageRecorder = budget
+//
+// Spend is only known once requests complete, so requests in flight when a
+// hard stop is reached still finish and the limit may be overshot by them.
+// Budget is safe for concurrent use.
+type Budget struct {
+	opts BudgetOptions
+	now  func() time.Time
+
+	mu      sync.Mutex
+	windows map[Window]*windowSpend
+	alerted []time.Time // Start of the window each threshold last alerted in
+}
+
+// windowSpend is the spend of the current window of one Window
+type windowSpend struct {
+	start time.Time
+	end   time.Time
+	spent float64
+}
+
+// NewBudget creates a budget with no spend recorded
+func NewBudget(opts BudgetOptions) (*Budget, error) {
+	for _, threshold := range opts.Thresholds {
+		if threshold.Window != WindowHourly && threshold.Window != WindowDaily {
+			return nil, fmt.Errorf("unknown budget window %q", threshold.Window)
+		}
+		if threshold.Amount <= 0 {
+			return nil, fmt.Errorf("budget threshold must be positive, got: %g", threshold.Amount)
+		}
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	return &Budget{
+		opts:    opts,
+		now:     time.Now,
+		windows: make(map[Window]*windowSpend),
+		alerted: make([]time.Time, len(opts.Thresholds)),
+	}, nil
+}
+
+// RecordUsage adds the cost of a request to the current windows, implementing
+// types.UsageRecorder, and forwards the record to Next
+func (b *Budget) RecordUsage(record types.UsageRecord) {
+	cost := record.Cost
+	if b.opts.CostFunc != nil {
+		cost = b.opts.CostFunc(record.Provider, record.Model, record.Usage)
+	}
+
+	b.mu.Lock()
+	now := b.now()
+	for _, window := range []Window{WindowHourly, WindowDaily} {
+		b.windowLocked(window, now).spent += cost
+	}
+	var alerts []Alert
+	for i, threshold := range b.opts.Thresholds {
+		w := b.windowLocked(threshold.Window, now)
+		if w.spent >= threshold.Amount && !b.alerted[i].Equal(w.start) {
+			b.alerted[i] = w.start
+			alerts = append(alerts, Alert{Threshold: threshold, Spent: w.spent, ResetAt: w.end})
+		}
+	}
+	b.mu.Unlock()
+
+	if b.opts.OnThreshold != nil {
+		for _, alert := range alerts {
+			b.opts.OnThreshold(alert)
+		}
+	}
+	if b.opts.Next != nil {
+		b.opts.Next.RecordUsage(record)
+	}
+}
+
+// CheckBudget returns a *types.BudgetExceededError if a hard-stop threshold
+// has been reached in its current window, implementing types.BudgetGuard
+func (b *Budget) CheckBudget(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	for _, threshold := range b.opts.Thresholds {
+		w := b.windowLocked(threshold.Window, now)
+		if threshold.HardStop && w.spent >= threshold.Amount {
+			return &types.BudgetExceededError{
+				Window:  string(threshold.Window),
+				Limit:   threshold.Amount,
+				Spent:   w.spent,
+				ResetAt: w.end,
+			}
+		}
+	}
+	return nil
+}
+
+// Spent returns the spend in USD recorded in the current window
+func (b *Budget) Spent(window Window) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.windowLocked(window, b.now()).spent
+}
+
+// Flush flushes Next if it buffers records, implementing types.Flusher
+func (b *Budget) Flush(ctx context.Context) error {
+	if flusher, ok := b.opts.Next.(types.Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// windowLocked returns the current window of a Window, starting a new one
+// once the previous has ended; b.mu must be held
+func (b *Budget) windowLocked(window Window, now time.Time) *windowSpend {
+	w, ok := b.windows[window]
+	if ok && now.Before(w.end) {
+		return w
+	}
+
+	local := now.In(b.opts.Location)
+	w = &windowSpend{}
+	if window == WindowHourly {
+		w.start = time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, b.opts.Location)
+		w.end = w.start.Add(time.Hour)
+	} else {
+		w.start = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, b.opts.Location)
+		w.end = w.start.AddDate(0, 0, 1)
+	}
+	b.windows[window] = w
+	return w
+}
@@ -0,0 +1,128 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultExportInterval is the flush interval used when none is configured
+const DefaultExportInterval = time.Minute
+
+// Sink receives aggregated usage reports from an Exporter.
+//
+// Implementations are called from a single goroutine at a time by an
+// Exporter, but should tolerate being shared between exporters.
+type Sink interface {
+	// Export writes a usage report to the sink
+	Export(ctx context.Context, report Report) error
+}
+
+// ExporterOptions configures an Exporter.
+type ExporterOptions struct {
+	// Interval is how often usage is flushed to the sink (default: 1 minute)
+	Interval time.Duration
+
+	// OnError is called when an export fails (optional)
+	// Failed reports are merged back into the tracker and retried on the next flush
+	OnError func(err error)
+}
+
+// Exporter periodically flushes aggregated usage from a Tracker to a Sink.
+type Exporter struct {
+	tracker *Tracker
+	sink    Sink
+	opts    ExporterOptions
+
+	mu      sync.Mutex // Serializes flushes and guards the fields below
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+}
+
+// NewExporter creates an exporter that flushes tracker usage to sink.
+// Call Start to begin periodic export.
+func NewExporter(tracker *Tracker, sink Sink, opts ExporterOptions) *Exporter {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultExportInterval
+	}
+
+	return &Exporter{
+		tracker: tracker,
+		sink:    sink,
+		opts:    opts,
+	}
+}
+
+// Start begins periodic export in a background goroutine.
+// Calling Start on a running exporter has no effect.
+func (e *Exporter) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return
+	}
+
+	e.running = true
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	go e.loop(e.stop, e.done)
+}
+
+// Stop halts periodic export and performs a final flush so no usage is lost.
+func (e *Exporter) Stop(ctx context.Context) error {
+	e.mu.Lock()
+	if e.running {
+		e.running = false
+		close(e.stop)
+		done := e.done
+		e.mu.Unlock()
+		<-done
+	} else {
+		e.mu.Unlock()
+	}
+
+	return e.Flush(ctx)
+}
+
+// Flush immediately exports the usage aggregated since the last flush.
+// Empty windows are skipped. On failure the usage is kept for the next flush.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	report := e.tracker.Flush()
+	if len(report.Stats) == 0 {
+		return nil
+	}
+
+	if err := e.sink.Export(ctx, report); err != nil {
+		e.tracker.restore(report)
+		return fmt.Errorf("failed to export usage: %w", err)
+	}
+
+	return nil
+}
+
+// loop runs the periodic export until stop is closed
+func (e *Exporter) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), e.opts.Interval)
+			if err := e.Flush(ctx); err != nil && e.opts.OnError != nil {
+				e.opts.OnError(err)
+			}
+			cancel()
+		}
+	}
+}
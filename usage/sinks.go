@@ -0,0 +1,281 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// csvHeader is the header row written to new CSV files
+var csvHeader = []string{
+	"window_start",
+	"window_end",
+	"provider",
+	"model",
+	"requests",
+	"prompt_tokens",
+	"completion_tokens",
+	"total_tokens",
+	"cost_usd",
+	"avg_latency_ms",
+	"retries",
+	"retry_wait_ms",
+	"variants",
+	"tags",
+	"usage_mismatches",
+	"avg_time_to_first_token_ms",
+	"tokens_per_second",
+}
+
+// CSVSink appends usage reports to a CSV file, one row per provider, model,
+// experiment variants and request tags.
+// A header row is written when the file is empty. A file with a different
+// header, such as one written by an older version with fewer columns, is
+// renamed aside, e.g. usage.csv to usage-20240102T150405Z.csv, and a new
+// file is started, so columns never shift under an existing header.
+type CSVSink struct {
+	// Path is the file to append to (created if missing)
+	Path string
+
+	mu sync.Mutex
+}
+
+// Export appends the report rows to the CSV file
+func (s *CSVSink) Export(ctx context.Context, report Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.rotateStale(); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open usage file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat usage file: %w", err)
+	}
+
+	w := csv.NewWriter(file)
+	if info.Size() == 0 {
+		if err := w.Write(csvHeader); err != nil {
+			return fmt.Errorf("failed to write usage header: %w", err)
+		}
+	}
+
+	start := report.Start.UTC().Format(time.RFC3339)
+	end := report.End.UTC().Format(time.RFC3339)
+	for _, st := range report.Stats {
+		row := []string{
+			start,
+			end,
+			string(st.Provider),
+			st.Model,
+			strconv.FormatInt(st.Requests, 10),
+			strconv.FormatInt(st.PromptTokens, 10),
+			strconv.FormatInt(st.CompletionTokens, 10),
+			strconv.FormatInt(st.TotalTokens, 10),
+			strconv.FormatFloat(st.Cost, 'f', 6, 64),
+			strconv.FormatInt(st.AverageLatency().Milliseconds(), 10),
+			strconv.FormatInt(st.Retries, 10),
+			strconv.FormatInt(st.RetryWait.Milliseconds(), 10),
+			st.Variants,
+			st.Tags,
+			strconv.FormatInt(st.UsageMismatches, 10),
+			strconv.FormatInt(st.AverageTimeToFirstToken().Milliseconds(), 10),
+			strconv.FormatFloat(st.TokensPerSecond(), 'f', 1, 64),
+		}
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("failed to write usage row: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to flush usage file: %w", err)
+	}
+	return nil
+}
+
+// rotateStale renames the file aside if it is not empty and does not start
+// with csvHeader
+func (s *CSVSink) rotateStale() error {
+	file, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open usage file: %w", err)
+	}
+	header, err := csv.NewReader(file).Read()
+	file.Close()
+	if err == io.EOF || (err == nil && equalFields(header, csvHeader)) {
+		return nil
+	}
+
+	ext := filepath.Ext(s.Path)
+	rotated := strings.TrimSuffix(s.Path, ext) + "-" + time.Now().UTC().Format("20060102T150405Z") + ext
+	if err := os.Rename(s.Path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate usage file with a different header: %w", err)
+	}
+	return nil
+}
+
+// equalFields reports whether two CSV records have the same fields
+func equalFields(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WebhookSink posts usage reports as JSON to an HTTP endpoint.
+type WebhookSink struct {
+	// URL is the endpoint that receives the report via POST
+	URL string
+
+	// Headers are added to every request, e.g. for authentication (optional)
+	Headers map[string]string
+
+	// Client is the HTTP client to use (default: client with 10s timeout)
+	Client *http.Client
+}
+
+// Export posts the report to the webhook URL
+func (s *WebhookSink) Export(ctx context.Context, report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// StatsdSink emits usage reports as statsd metrics over UDP.
+//
+// For every provider and model the following metrics are emitted, where the
+// model name has dots replaced by underscores:
+//
+//	<prefix>.<provider>.<model>.requests           (counter)
+//	<prefix>.<provider>.<model>.tokens.prompt      (counter)
+//	<prefix>.<provider>.<model>.tokens.completion  (counter)
+//	<prefix>.<provider>.<model>.tokens.total       (counter)
+//	<prefix>.<provider>.<model>.cost_microusd      (counter)
+//	<prefix>.<provider>.<model>.usage_mismatches   (counter)
+//	<prefix>.<provider>.<model>.latency_avg        (timer, ms)
+//
+// Usage served by experiment variants is reported under
+// <prefix>.<provider>.<model>.<variants> instead, and tagged usage under a
+// further .<tags> suffix.
+type StatsdSink struct {
+	prefix string
+	conn   net.Conn
+}
+
+// NewStatsdSink creates a sink sending metrics to the statsd server at addr.
+// The prefix is prepended to every metric name (default: "ai").
+func NewStatsdSink(addr, prefix string) (*StatsdSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd: %w", err)
+	}
+
+	if prefix == "" {
+		prefix = "ai"
+	}
+
+	return &StatsdSink{prefix: prefix, conn: conn}, nil
+}
+
+// Export sends the report metrics, one UDP packet per stats entry
+func (s *StatsdSink) Export(ctx context.Context, report Report) error {
+	for _, st := range report.Stats {
+		model := st.Model
+		if model == "" {
+			model = "unknown"
+		}
+		base := fmt.Sprintf("%s.%s.%s", s.prefix, statsdSanitize(string(st.Provider)), statsdSanitize(model))
+		if st.Variants != "" {
+			base += "." + statsdSanitize(st.Variants)
+		}
+		if st.Tags != "" {
+			base += "." + statsdSanitize(st.Tags)
+		}
+
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "%s.requests:%d|c\n", base, st.Requests)
+		fmt.Fprintf(&buf, "%s.tokens.prompt:%d|c\n", base, st.PromptTokens)
+		fmt.Fprintf(&buf, "%s.tokens.completion:%d|c\n", base, st.CompletionTokens)
+		fmt.Fprintf(&buf, "%s.tokens.total:%d|c\n", base, st.TotalTokens)
+		fmt.Fprintf(&buf, "%s.cost_microusd:%d|c\n", base, int64(st.Cost*1e6))
+		fmt.Fprintf(&buf, "%s.retries:%d|c\n", base, st.Retries)
+		fmt.Fprintf(&buf, "%s.retry_wait:%d|ms\n", base, st.RetryWait.Milliseconds())
+		fmt.Fprintf(&buf, "%s.usage_mismatches:%d|c\n", base, st.UsageMismatches)
+		fmt.Fprintf(&buf, "%s.latency_avg:%d|ms", base, st.AverageLatency().Milliseconds())
+		if st.Streams > 0 {
+			fmt.Fprintf(&buf, "\n%s.time_to_first_token_avg:%d|ms", base, st.AverageTimeToFirstToken().Milliseconds())
+		}
+		if st.StreamGenerationTime > 0 {
+			fmt.Fprintf(&buf, "\n%s.tokens_per_second:%d|g", base, int64(st.TokensPerSecond()))
+		}
+
+		if _, err := s.conn.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to send statsd metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying UDP connection
+func (s *StatsdSink) Close() error {
+	return s.conn.Close()
+}
+
+// statsdSanitize replaces characters with special meaning in statsd names
+func statsdSanitize(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_", "|", "_", "@", "_", " ", "_").Replace(name)
+}
@@ -0,0 +1,325 @@
+// Package usage provides usage aggregation and export for AI provider clients.
+//
+// A Tracker implements types.UsageRecorder and aggregates per-request usage
+// records by provider, model, experiment variants and request tags. An Exporter periodically flushes the
+// aggregated statistics to a pluggable Sink (CSV file, HTTP webhook, statsd),
+// enabling simple cost dashboards without wiring a full metrics stack.
+// A Budget caps spend with hourly and daily thresholds that alert and, if
+// configured, stop further requests until the window resets.
+//
+// Example:
+//
+//	tracker := usage.NewTracker()
+//	config := aiprovider.DefaultConfig().WithAPIKey(key)
+//	config.UsageRecorder = tracker
+//
+//	exporter := usage.NewExporter(tracker, &usage.CSVSink{Path: "usage.csv"}, usage.ExporterOptions{
+//		Interval: time.Minute,
+//	})
+//	exporter.Start()
+//	defer exporter.Stop(context.Background())
+package usage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+// CostFunc computes the cost in USD of a request's token usage.
+// It returns zero when the price of the model is unknown.
+type CostFunc func(provider types.ProviderType, model string, usage types.Usage) float64
+
+// Stats contains aggregated usage for a single provider, model, set of
+// experiment variants and set of request tags.
+type Stats struct {
+	// Provider is the AI provider the usage was recorded for
+	Provider types.ProviderType `json:"provider"`
+
+	// Model is the model the usage was recorded for (empty if not reported)
+	Model string `json:"model,omitempty"`
+
+	// Variants labels the experiment variants that served the requests as
+	// comma-separated experiment=variant pairs sorted by experiment, with
+	// backslashes, commas and equals signs escaped by a backslash (empty
+	// when no experiments are configured)
+	Variants string `json:"variants,omitempty"`
+
+	// Tags labels the cost attribution tags of the requests as
+	// comma-separated key=value pairs sorted by key, escaped like Variants
+	// (empty for untagged requests)
+	Tags string `json:"tags,omitempty"`
+
+	// Requests is the number of successful requests
+	Requests int64 `json:"requests"`
+
+	// PromptTokens is the total number of prompt tokens
+	PromptTokens int64 `json:"prompt_tokens"`
+
+	// CompletionTokens is the total number of completion tokens
+	CompletionTokens int64 `json:"completion_tokens"`
+
+	// TotalTokens is the total number of billable tokens
+	TotalTokens int64 `json:"total_tokens"`
+
+	// Cost is the estimated cost in USD (zero for models with unknown prices)
+	Cost float64 `json:"cost_usd"`
+
+	// TotalLatency is the sum of all request latencies
+	TotalLatency time.Duration `json:"total_latency"`
+
+	// Retries is the total number of retried HTTP attempts
+	Retries int64 `json:"retries"`
+
+	// RetryWait is the total time spent in retry backoff
+	RetryWait time.Duration `json:"retry_wait"`
+
+	// UsageMismatches is the number of requests whose usage diverged from
+	// local token counts (only counted with usage verification enabled)
+	UsageMismatches int64 `json:"usage_mismatches,omitempty"`
+
+	// Streams is the number of streamed requests that delivered content
+	Streams int64 `json:"streams,omitempty"`
+
+	// TotalTimeToFirstToken is the sum of the streams' times to first token
+	TotalTimeToFirstToken time.Duration `json:"total_time_to_first_token,omitempty"`
+
+	// StreamTokens is the number of completion tokens of streams with a
+	// measured token rate
+	StreamTokens int64 `json:"stream_tokens,omitempty"`
+
+	// StreamGenerationTime is the time those streams spent generating
+	// StreamTokens after their first token
+	StreamGenerationTime time.Duration `json:"stream_generation_time,omitempty"`
+}
+
+// AverageLatency returns the mean request latency
+func (s Stats) AverageLatency() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Requests)
+}
+
+// AverageTimeToFirstToken returns the mean time to first token of streams
+func (s Stats) AverageTimeToFirstToken() time.Duration {
+	if s.Streams == 0 {
+		return 0
+	}
+	return s.TotalTimeToFirstToken / time.Duration(s.Streams)
+}
+
+// TokensPerSecond returns the completion token rate of streams, weighted by
+// their tokens
+func (s Stats) TokensPerSecond() float64 {
+	if s.StreamGenerationTime <= 0 {
+		return 0
+	}
+	return float64(s.StreamTokens) / s.StreamGenerationTime.Seconds()
+}
+
+// add merges other into s
+func (s *Stats) add(other Stats) {
+	s.Requests += other.Requests
+	s.PromptTokens += other.PromptTokens
+	s.CompletionTokens += other.CompletionTokens
+	s.TotalTokens += other.TotalTokens
+	s.Cost += other.Cost
+	s.TotalLatency += other.TotalLatency
+	s.Retries += other.Retries
+	s.RetryWait += other.RetryWait
+	s.UsageMismatches += other.UsageMismatches
+	s.Streams += other.Streams
+	s.TotalTimeToFirstToken += other.TotalTimeToFirstToken
+	s.StreamTokens += other.StreamTokens
+	s.StreamGenerationTime += other.StreamGenerationTime
+}
+
+// Report is a snapshot of aggregated usage over a time window.
+type Report struct {
+	// Start is the beginning of the aggregation window
+	Start time.Time `json:"start"`
+
+	// End is the end of the aggregation window
+	End time.Time `json:"end"`
+
+	// Stats contains one entry per provider, model, variants and tags, sorted in that order
+	Stats []Stats `json:"stats"`
+}
+
+// Total returns the usage summed across all providers and models
+func (r Report) Total() Stats {
+	var total Stats
+	for _, s := range r.Stats {
+		total.add(s)
+	}
+	return total
+}
+
+// statsKey identifies an aggregation bucket
+type statsKey struct {
+	provider types.ProviderType
+	model    string
+	variants string
+	tags     string
+}
+
+// labelEscaper escapes the separators of a label inside its keys and values
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `=`, `\=`)
+
+// pairsLabel formats experiment assignments or tags as comma-separated
+// key=value pairs sorted by key. Backslashes, commas and equals signs in
+// keys and values are escaped with a backslash, so distinct sets never
+// share a label.
+func pairsLabel(values map[string]string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(values))
+	for key, value := range values {
+		pairs = append(pairs, labelEscaper.Replace(key)+"="+labelEscaper.Replace(value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Tracker aggregates usage records by provider, model, experiment variants
+// and request tags.
+//
+// Tracker implements types.UsageRecorder and is safe for concurrent use.
+type Tracker struct {
+	mu          sync.Mutex
+	costFunc    CostFunc
+	windowStart time.Time
+	stats       map[statsKey]*Stats
+}
+
+// NewTracker creates an empty usage tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		windowStart: time.Now(),
+		stats:       make(map[statsKey]*Stats),
+	}
+}
+
+// SetCostFunc sets the function used to price recorded usage, overriding the
+// cost computed by the client. Only records received after the call are priced.
+func (t *Tracker) SetCostFunc(fn CostFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.costFunc = fn
+}
+
+// RecordUsage adds a usage record to the current aggregation window
+func (t *Tracker) RecordUsage(record types.UsageRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := Stats{
+		Provider:         record.Provider,
+		Model:            record.Model,
+		Variants:         pairsLabel(record.Experiments),
+		Tags:             pairsLabel(record.Tags),
+		Requests:         1,
+		PromptTokens:     int64(record.Usage.PromptTokens),
+		CompletionTokens: int64(record.Usage.CompletionTokens),
+		TotalTokens:      int64(record.Usage.TotalTokens),
+		TotalLatency:     record.Latency,
+		RetryWait:        record.RetryWait,
+	}
+	if record.Attempts > 1 {
+		entry.Retries = int64(record.Attempts - 1)
+	}
+	if record.UsageMismatch {
+		entry.UsageMismatches = 1
+	}
+	if record.TimeToFirstToken > 0 {
+		entry.Streams = 1
+		entry.TotalTimeToFirstToken = record.TimeToFirstToken
+	}
+	if record.TokensPerSecond > 0 {
+		entry.StreamTokens = int64(record.Usage.CompletionTokens)
+		entry.StreamGenerationTime = time.Duration(float64(record.Usage.CompletionTokens) / record.TokensPerSecond * float64(time.Second))
+	}
+	if t.costFunc != nil {
+		entry.Cost = t.costFunc(record.Provider, record.Model, record.Usage)
+	} else {
+		entry.Cost = record.Cost
+	}
+
+	t.addLocked(entry)
+}
+
+// Snapshot returns the usage aggregated so far without resetting the window
+func (t *Tracker) Snapshot() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.reportLocked(time.Now())
+}
+
+// Flush returns the usage aggregated so far and starts a new window
+func (t *Tracker) Flush() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	report := t.reportLocked(now)
+	t.stats = make(map[statsKey]*Stats)
+	t.windowStart = now
+	return report
+}
+
+// restore merges a previously flushed report back into the tracker, used when
+// an export fails so the usage is not lost
+func (t *Tracker) restore(report Report) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range report.Stats {
+		t.addLocked(s)
+	}
+	if report.Start.Before(t.windowStart) {
+		t.windowStart = report.Start
+	}
+}
+
+// addLocked merges stats into the matching bucket; t.mu must be held
+func (t *Tracker) addLocked(s Stats) {
+	key := statsKey{provider: s.Provider, model: s.Model, variants: s.Variants, tags: s.Tags}
+	bucket, ok := t.stats[key]
+	if !ok {
+		bucket = &Stats{Provider: s.Provider, Model: s.Model, Variants: s.Variants, Tags: s.Tags}
+		t.stats[key] = bucket
+	}
+	bucket.add(s)
+}
+
+// reportLocked builds a sorted report of the current window; t.mu must be held
+func (t *Tracker) reportLocked(end time.Time) Report {
+	report := Report{
+		Start: t.windowStart,
+		End:   end,
+		Stats: make([]Stats, 0, len(t.stats)),
+	}
+	for _, s := range t.stats {
+		report.Stats = append(report.Stats, *s)
+	}
+
+	sort.Slice(report.Stats, func(i, j int) bool {
+		if report.Stats[i].Provider != report.Stats[j].Provider {
+			return report.Stats[i].Provider < report.Stats[j].Provider
+		}
+		if report.Stats[i].Model != report.Stats[j].Model {
+			return report.Stats[i].Model < report.Stats[j].Model
+		}
+		if report.Stats[i].Variants != report.Stats[j].Variants {
+			return report.Stats[i].Variants < report.Stats[j].Variants
+		}
+		return report.Stats[i].Tags < report.Stats[j].Tags
+	})
+
+	return report
+}
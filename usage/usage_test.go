@@ -0,0 +1,460 @@
+package usage
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/types"
+)
+
+func record(provider types.ProviderType, model string, prompt, completion int) types.UsageRecord {
+	return types.UsageRecord{
+		Provider: provider,
+		Model:    model,
+		Usage: types.Usage{
+			PromptTokens:     prompt,
+			CompletionTokens: completion,
+			TotalTokens:      prompt + completion,
+		},
+		Latency:   100 * time.Millisecond,
+		Timestamp: time.Now(),
+	}
+}
+
+// Test aggregation by provider and model
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	tracker.SetCostFunc(func(provider types.ProviderType, model string, usage types.Usage) float64 {
+		return float64(usage.TotalTokens) / 1000
+	})
+
+	tracker.RecordUsage(record(types.ProviderOpenAI, "gpt-4", 100, 50))
+	tracker.RecordUsage(record(types.ProviderOpenAI, "gpt-4", 200, 50))
+	tracker.RecordUsage(record(types.ProviderAnthropic, "claude-3-haiku", 10, 10))
+
+	report := tracker.Snapshot()
+	if len(report.Stats) != 2 {
+		t.Fatalf("Expected 2 stats entries, got %d", len(report.Stats))
+	}
+
+	// Sorted by provider: anthropic before openai
+	if report.Stats[0].Provider != types.ProviderAnthropic {
+		t.Errorf("Expected anthropic first, got %s", report.Stats[0].Provider)
+	}
+
+	openai := report.Stats[1]
+	if openai.Requests != 2 || openai.PromptTokens != 300 || openai.TotalTokens != 400 {
+		t.Errorf("Unexpected openai stats: %+v", openai)
+	}
+	if openai.Cost != 0.4 {
+		t.Errorf("Expected cost 0.4, got %f", openai.Cost)
+	}
+	if openai.AverageLatency() != 100*time.Millisecond {
+		t.Errorf("Expected average latency 100ms, got %v", openai.AverageLatency())
+	}
+
+	if total := report.Total(); total.Requests != 3 || total.TotalTokens != 420 {
+		t.Errorf("Unexpected total: %+v", total)
+	}
+
+	// Flush resets the window
+	if flushed := tracker.Flush(); len(flushed.Stats) != 2 {
+		t.Errorf("Expected flush to return 2 entries, got %d", len(flushed.Stats))
+	}
+	if after := tracker.Snapshot(); len(after.Stats) != 0 {
+		t.Errorf("Expected empty tracker after flush, got %d entries", len(after.Stats))
+	}
+}
+
+// Test aggregation by experiment variant
+func TestTracker_Variants(t *testing.T) {
+	tracker := NewTracker()
+
+	control := record(types.ProviderOpenAI, "gpt-4", 100, 50)
+	control.Experiments = map[string]string{"tone": "control", "model": "control"}
+	treated := record(types.ProviderOpenAI, "gpt-4", 100, 50)
+	treated.Experiments = map[string]string{"tone": "friendly", "model": "control"}
+
+	tracker.RecordUsage(control)
+	tracker.RecordUsage(treated)
+	tracker.RecordUsage(treated)
+	tracker.RecordUsage(record(types.ProviderOpenAI, "gpt-4", 10, 10))
+
+	report := tracker.Snapshot()
+	if len(report.Stats) != 3 {
+		t.Fatalf("Expected 3 stats entries, got %+v", report.Stats)
+	}
+	want := []struct {
+		variants string
+		requests int64
+	}{
+		{"", 1},
+		{"model=control,tone=control", 1},
+		{"model=control,tone=friendly", 2},
+	}
+	for i, w := range want {
+		if report.Stats[i].Variants != w.variants || report.Stats[i].Requests != w.requests {
+			t.Errorf("Expected stats %d to be %q with %d requests, got %+v", i, w.variants, w.requests, report.Stats[i])
+		}
+	}
+}
+
+// Test aggregation by request tags
+func TestTracker_Tags(t *testing.T) {
+	tracker := NewTracker()
+
+	search := record(types.ProviderOpenAI, "gpt-4", 100, 50)
+	search.Tags = map[string]string{"team": "search", "feature": "autocomplete"}
+	support := record(types.ProviderOpenAI, "gpt-4", 10, 5)
+	support.Tags = map[string]string{"team": "support"}
+
+	tracker.RecordUsage(search)
+	tracker.RecordUsage(search)
+	tracker.RecordUsage(support)
+
+	report := tracker.Snapshot()
+	if len(report.Stats) != 2 {
+		t.Fatalf("Expected 2 stats entries, got %+v", report.Stats)
+	}
+	if report.Stats[0].Tags != "feature=autocomplete,team=search" || report.Stats[0].Requests != 2 {
+		t.Errorf("Unexpected search stats: %+v", report.Stats[0])
+	}
+	if report.Stats[1].Tags != "team=support" || report.Stats[1].TotalTokens != 15 {
+		t.Errorf("Unexpected support stats: %+v", report.Stats[1])
+	}
+}
+
+// Test that separators in tags cannot merge distinct tag sets
+func TestTracker_TagEscaping(t *testing.T) {
+	tracker := NewTracker()
+
+	joined := record(types.ProviderOpenAI, "gpt-4", 10, 5)
+	joined.Tags = map[string]string{"team": "a,feature=b"}
+	split := record(types.ProviderOpenAI, "gpt-4", 10, 5)
+	split.Tags = map[string]string{"team": "a", "feature": "b"}
+
+	tracker.RecordUsage(joined)
+	tracker.RecordUsage(split)
+
+	report := tracker.Snapshot()
+	if len(report.Stats) != 2 {
+		t.Fatalf("Expected 2 stats entries, got %+v", report.Stats)
+	}
+	if report.Stats[1].Tags != `team=a\,feature\=b` {
+		t.Errorf("Expected escaped separators, got %q", report.Stats[1].Tags)
+	}
+}
+
+type recordingSink struct {
+	reports []Report
+	err     error
+}
+
+func (s *recordingSink) Export(ctx context.Context, report Report) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.reports = append(s.reports, report)
+	return nil
+}
+
+// Test that failed exports keep usage for the next flush
+func TestExporterFlush(t *testing.T) {
+	tracker := NewTracker()
+	sink := &recordingSink{err: errors.New("sink down")}
+	exporter := NewExporter(tracker, sink, ExporterOptions{})
+
+	// Empty windows are not exported
+	if err := exporter.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected error flushing empty tracker: %v", err)
+	}
+
+	tracker.RecordUsage(record(types.ProviderOpenAI, "gpt-4", 10, 5))
+	if err := exporter.Flush(context.Background()); err == nil {
+		t.Fatalf("Expected export error")
+	}
+
+	tracker.RecordUsage(record(types.ProviderOpenAI, "gpt-4", 10, 5))
+	sink.err = nil
+	if err := exporter.Stop(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(sink.reports) != 1 {
+		t.Fatalf("Expected 1 exported report, got %d", len(sink.reports))
+	}
+	if got := sink.reports[0].Total().Requests; got != 2 {
+		t.Errorf("Expected restored usage to be exported (2 requests), got %d", got)
+	}
+}
+
+// Test periodic export in the background
+func TestExporterStart(t *testing.T) {
+	tracker := NewTracker()
+	sink := &lockedSink{exported: make(chan Report, 1)}
+	exporter := NewExporter(tracker, sink, ExporterOptions{Interval: 10 * time.Millisecond})
+
+	tracker.RecordUsage(record(types.ProviderOpenAI, "gpt-4", 1, 1))
+	exporter.Start()
+	defer exporter.Stop(context.Background())
+
+	select {
+	case report := <-sink.exported:
+		if report.Total().Requests != 1 {
+			t.Errorf("Expected 1 request, got %d", report.Total().Requests)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for periodic export")
+	}
+}
+
+type lockedSink struct {
+	exported chan Report
+}
+
+func (s *lockedSink) Export(ctx context.Context, report Report) error {
+	select {
+	case s.exported <- report:
+	default:
+	}
+	return nil
+}
+
+func TestCSVSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.csv")
+	sink := &CSVSink{Path: path}
+
+	tracker := NewTracker()
+	tracker.RecordUsage(record(types.ProviderOpenAI, "gpt-4", 10, 5))
+	report := tracker.Flush()
+
+	for i := 0; i < 2; i++ {
+		if err := sink.Export(context.Background(), report); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open CSV: %v", err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	// One header row plus one row per export
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+	if rows[0][0] != "window_start" {
+		t.Errorf("Expected header row, got %v", rows[0])
+	}
+	if rows[1][2] != "openai" || rows[1][3] != "gpt-4" || rows[1][7] != "15" {
+		t.Errorf("Unexpected data row: %v", rows[1])
+	}
+}
+
+// Test that a file with an older header is moved aside rather than appended to
+func TestCSVSink_HeaderChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "usage.csv")
+	old := "window_start,window_end,provider,model\n2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,openai,gpt-4\n"
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := NewTracker()
+	tracker.RecordUsage(record(types.ProviderOpenAI, "gpt-4", 10, 5))
+	if err := (&CSVSink{Path: path}).Export(context.Background(), tracker.Flush()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(rows) != 2 || strings.Join(rows[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("Expected a new file with the current header, got %v", rows)
+	}
+
+	rotated, _ := filepath.Glob(filepath.Join(dir, "usage-*.csv"))
+	if len(rotated) != 1 {
+		t.Fatalf("Expected the old file to be moved aside, got %v", rotated)
+	}
+	if data, _ := os.ReadFile(rotated[0]); string(data) != old {
+		t.Errorf("Expected the old file unchanged, got %q", data)
+	}
+}
+
+func TestTracker_StreamMetrics(t *testing.T) {
+	tracker := NewTracker()
+	fast := record(types.ProviderAnthropic, "claude-3-haiku", 10, 100)
+	fast.TimeToFirstToken, fast.TokensPerSecond = 200*time.Millisecond, 100
+	slow := record(types.ProviderAnthropic, "claude-3-haiku", 10, 300)
+	slow.TimeToFirstToken, slow.TokensPerSecond = 400*time.Millisecond, 50
+	tracker.RecordUsage(fast)
+	tracker.RecordUsage(slow)
+	tracker.RecordUsage(record(types.ProviderAnthropic, "claude-3-haiku", 10, 5))
+
+	stats := tracker.Flush().Stats[0]
+	if stats.Requests != 3 || stats.Streams != 2 {
+		t.Fatalf("Expected 3 requests of which 2 streamed, got %+v", stats)
+	}
+	if ttft := stats.AverageTimeToFirstToken(); ttft != 300*time.Millisecond {
+		t.Errorf("Expected an average time to first token of 300ms, got %v", ttft)
+	}
+	// 400 tokens in 1s + 6s
+	if tps := stats.TokensPerSecond(); tps < 57.1 || tps > 57.2 {
+		t.Errorf("Expected a token-weighted rate of 57.1 tokens/s, got %g", tps)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	tracker := NewTracker()
+	tracker.RecordUsage(record(types.ProviderAnthropic, "claude-3-haiku", 10, 5))
+	report := tracker.Flush()
+
+	sink := &WebhookSink{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}
+	if err := sink.Export(context.Background(), report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if received.Total().TotalTokens != 15 {
+		t.Errorf("Expected 15 tokens received, got %d", received.Total().TotalTokens)
+	}
+
+	unauthorized := &WebhookSink{URL: server.URL}
+	if err := unauthorized.Export(context.Background(), report); err == nil {
+		t.Errorf("Expected error for non-2xx status")
+	}
+}
+
+func TestStatsdSink(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer listener.Close()
+
+	sink, err := NewStatsdSink(listener.LocalAddr().String(), "")
+	if err != nil {
+		t.Fatalf("Failed to create statsd sink: %v", err)
+	}
+	defer sink.Close()
+
+	tracker := NewTracker()
+	streamed := record(types.ProviderOpenAI, "gpt-3.5-turbo", 10, 5)
+	streamed.TimeToFirstToken, streamed.TokensPerSecond = 250*time.Millisecond, 40
+	tracker.RecordUsage(streamed)
+	if err := sink.Export(context.Background(), tracker.Flush()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := listener.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Failed to read statsd packet: %v", err)
+	}
+
+	packet := string(buf[:n])
+	if !strings.Contains(packet, "ai.openai.gpt-3_5-turbo.tokens.total:15|c") {
+		t.Errorf("Unexpected statsd packet: %q", packet)
+	}
+	if !strings.Contains(packet, "ai.openai.gpt-3_5-turbo.time_to_first_token_avg:250|ms") || !strings.Contains(packet, "ai.openai.gpt-3_5-turbo.tokens_per_second:40|g") {
+		t.Errorf("Expected stream metrics in statsd packet: %q", packet)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	var alerts []Alert
+	tracker := NewTracker()
+	budget, err := NewBudget(BudgetOptions{
+		Thresholds: []Threshold{
+			{Window: WindowHourly, Amount: 1},
+			{Window: WindowDaily, Amount: 2, HardStop: true},
+		},
+		OnThreshold: func(alert Alert) { alerts = append(alerts, alert) },
+		Next:        tracker,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	budget.now = func() time.Time { return now }
+	ctx := context.Background()
+	spend := func(cost float64) {
+		r := record(types.ProviderOpenAI, "gpt-4o", 10, 5)
+		r.Cost = cost
+		budget.RecordUsage(r)
+	}
+
+	spend(0.6)
+	spend(0.6)
+	if len(alerts) != 1 || alerts[0].Threshold.Window != WindowHourly || alerts[0].Spent != 1.2 || !alerts[0].ResetAt.Equal(time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected one hourly alert, got %+v", alerts)
+	}
+	spend(0.1)
+	if len(alerts) != 1 {
+		t.Errorf("Expected a threshold to alert once per window, got %d alerts", len(alerts))
+	}
+	if err := budget.CheckBudget(ctx); err != nil {
+		t.Errorf("Expected an alert-only threshold not to stop requests, got %v", err)
+	}
+	if tracker.Snapshot().Total().Requests != 3 {
+		t.Error("Expected records to be forwarded to Next")
+	}
+
+	// The next hour starts a new hourly window but keeps the daily spend
+	now = now.Add(time.Hour)
+	if spent := budget.Spent(WindowHourly); spent != 0 {
+		t.Errorf("Expected a new hourly window, got $%g", spent)
+	}
+	spend(1)
+	if len(alerts) != 3 || alerts[1].Threshold.Window != WindowHourly || alerts[2].Threshold.Window != WindowDaily {
+		t.Fatalf("Expected hourly and daily alerts, got %+v", alerts)
+	}
+	var exceeded *types.BudgetExceededError
+	if err := budget.CheckBudget(ctx); !errors.As(err, &exceeded) || exceeded.Window != "daily" || exceeded.Limit != 2 || !exceeded.ResetAt.Equal(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected the daily budget to be exceeded, got %v", err)
+	}
+
+	// Requests are accepted again once the window resets
+	now = time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	if err := budget.CheckBudget(ctx); err != nil {
+		t.Errorf("Expected the budget to reset at midnight, got %v", err)
+	}
+
+	if _, err := NewBudget(BudgetOptions{Thresholds: []Threshold{{Window: "weekly", Amount: 1}}}); err == nil {
+		t.Error("Expected an error for an unknown window")
+	}
+	if _, err := NewBudget(BudgetOptions{Thresholds: []Threshold{{Window: WindowDaily}}}); err == nil {
+		t.Error("Expected an error for a zero amount")
+	}
+}
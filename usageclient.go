@@ -0,0 +1,163 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ajeet-kumar1087/ai-providers/usage"
+)
+
+// UsageClient reads the usage and billed costs providers report for an
+// account, normalized into usage reports.
+//
+// Unlike the usage recorded by a client, which only covers its own requests,
+// provider-side data covers every request of the organization, so finance
+// tooling can reconcile spend without provider-specific SDKs. Providers
+// without a usage API, currently all but OpenAI, are rejected by
+// NewUsageClient. UsageClient is safe for concurrent use.
+type UsageClient struct {
+	provider ProviderType
+	adapter  UsageAdapter
+}
+
+// NewUsageClient creates a read-only client for a provider's usage API.
+//
+// OpenAI's usage API requires an admin API key, which can read usage but
+// not send requests, so it is typically configured separately from the key
+// of the request client.
+//
+// Example:
+//
+//	usageClient, err := NewUsageClient(ProviderOpenAI, Config{APIKey: os.Getenv("OPENAI_ADMIN_KEY")})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	reports, err := usageClient.Usage(ctx, UsageQuery{Start: time.Now().AddDate(0, 0, -7)})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, report := range reports {
+//		fmt.Printf("%s: %d tokens\n", report.Start.Format("2006-01-02"), report.Total().TotalTokens)
+//	}
+//
+// Parameters:
+//   - provider: The AI provider whose usage to read
+//   - config: Configuration with the provider's API key
+//
+// Returns:
+//   - *UsageClient: The usage client
+//   - error: A validation error if the configuration is invalid or the provider has no usage API
+func NewUsageClient(provider ProviderType, config Config) (*UsageClient, error) {
+	if err := config.Validate(provider); err != nil {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("invalid configuration: %v", err),
+			Provider: string(provider),
+			Wrapped:  err,
+		}
+	}
+	adapter, err := CreateAdapter(provider, config)
+	if err != nil {
+		return nil, err
+	}
+	reader, ok := adapter.(UsageAdapter)
+	if !ok {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("provider %s has no usage API", provider),
+			Provider: string(provider),
+		}
+	}
+	return &UsageClient{provider: provider, adapter: reader}, nil
+}
+
+// Usage returns the token usage and request counts of the account, with one
+// report per time bucket and one entry per model, and per project with
+// query.GroupByProject (labeled "project=<id>" in Stats.Tags). Costs are not
+// included; see Costs.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - query: The period, bucket width and projects
+//
+// Returns:
+//   - []usage.Report: The usage per bucket, in time order
+//   - error: A validation error for an invalid query, or a provider error
+func (u *UsageClient) Usage(ctx context.Context, query UsageQuery) ([]usage.Report, error) {
+	if err := u.validateQuery(query); err != nil {
+		return nil, err
+	}
+	entries, err := u.adapter.Usage(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return u.reports(entries), nil
+}
+
+// Costs returns the billed costs of the account in daily buckets, with one
+// report per day and, with query.GroupByProject, one entry per project.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - query: The period and projects
+//
+// Returns:
+//   - []usage.Report: The costs per bucket in Stats.Cost, in time order
+//   - error: A validation error for an invalid query, or a provider error
+func (u *UsageClient) Costs(ctx context.Context, query UsageQuery) ([]usage.Report, error) {
+	if err := u.validateQuery(query); err != nil {
+		return nil, err
+	}
+	entries, err := u.adapter.Costs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return u.reports(entries), nil
+}
+
+// validateQuery rejects queries without a valid period
+func (u *UsageClient) validateQuery(query UsageQuery) error {
+	message := ""
+	switch {
+	case query.Start.IsZero():
+		message = "usage query requires a start time"
+	case !query.End.IsZero() && !query.End.After(query.Start):
+		message = "usage query end must be after its start"
+	case query.Start.After(time.Now()):
+		message = "usage query start must not be in the future"
+	}
+	if message == "" {
+		return nil
+	}
+	return &Error{
+		Type:     ErrorTypeValidation,
+		Message:  message,
+		Provider: string(u.provider),
+	}
+}
+
+// reports groups provider usage entries into one report per bucket
+func (u *UsageClient) reports(entries []ProviderUsage) []usage.Report {
+	var reports []usage.Report
+	for _, entry := range entries {
+		if n := len(reports); n == 0 || !reports[n-1].Start.Equal(entry.Start) {
+			reports = append(reports, usage.Report{Start: entry.Start, End: entry.End})
+		}
+		stats := usage.Stats{
+			Provider:         u.provider,
+			Model:            entry.Model,
+			Requests:         entry.Requests,
+			PromptTokens:     entry.PromptTokens,
+			CompletionTokens: entry.CompletionTokens,
+			TotalTokens:      entry.PromptTokens + entry.CompletionTokens,
+			Cost:             entry.Cost,
+		}
+		if entry.ProjectID != "" {
+			stats.Tags = "project=" + entry.ProjectID
+		}
+		report := &reports[len(reports)-1]
+		report.Stats = append(report.Stats, stats)
+	}
+	return reports
+}
@@ -0,0 +1,103 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeUsageAdapter returns canned provider usage and records queries
+type fakeUsageAdapter struct {
+	entries []ProviderUsage
+	queries []UsageQuery
+}
+
+func (f *fakeUsageAdapter) Usage(ctx context.Context, query UsageQuery) ([]ProviderUsage, error) {
+	f.queries = append(f.queries, query)
+	return f.entries, nil
+}
+
+func (f *fakeUsageAdapter) Costs(ctx context.Context, query UsageQuery) ([]ProviderUsage, error) {
+	f.queries = append(f.queries, query)
+	return f.entries, nil
+}
+
+func TestUsageClient(t *testing.T) {
+	day := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	adapter := &fakeUsageAdapter{entries: []ProviderUsage{
+		{Start: day, End: day.Add(24 * time.Hour), Model: "gpt-4o", ProjectID: "proj_a", Requests: 2, PromptTokens: 100, CompletionTokens: 20},
+		{Start: day, End: day.Add(24 * time.Hour), Model: "gpt-4o-mini", Requests: 1, PromptTokens: 10, CompletionTokens: 5},
+		{Start: day.Add(24 * time.Hour), End: day.Add(48 * time.Hour), Model: "gpt-4o", Requests: 1, PromptTokens: 1, CompletionTokens: 1},
+	}}
+	client := &UsageClient{provider: ProviderOpenAI, adapter: adapter}
+
+	reports, err := client.Usage(context.Background(), UsageQuery{Start: day, GroupByProject: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(reports) != 2 || len(reports[0].Stats) != 2 || !reports[1].Start.Equal(day.Add(24*time.Hour)) {
+		t.Fatalf("Expected one report per bucket, got %+v", reports)
+	}
+	stats := reports[0].Stats[0]
+	if stats.Provider != ProviderOpenAI || stats.Tags != "project=proj_a" || stats.TotalTokens != 120 || stats.Requests != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if total := reports[0].Total(); total.TotalTokens != 135 {
+		t.Errorf("Expected the bucket total to be 135 tokens, got %+v", total)
+	}
+
+	invalid := []UsageQuery{
+		{},
+		{Start: day, End: day.Add(-time.Hour)},
+		{Start: time.Now().Add(time.Hour)},
+	}
+	for _, query := range invalid {
+		if _, err := client.Costs(context.Background(), query); err == nil {
+			t.Errorf("Expected a validation error for %+v", query)
+		}
+	}
+	if len(adapter.queries) != 1 {
+		t.Errorf("Expected invalid queries not to be sent, got %d", len(adapter.queries))
+	}
+}
+
+func TestNewUsageClient(t *testing.T) {
+	if _, err := NewUsageClient(ProviderOpenAI, Config{APIKey: "sk-admin-1234567890abcdef1234567890"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := NewUsageClient(ProviderAnthropic, Config{APIKey: "sk-ant-REDACTED"}); err == nil {
+		t.Error("Expected an error for a provider without a usage API")
+	}
+}
+
+// fixedBudget is a UsageRecorder rejecting requests with err
+type fixedBudget struct {
+	err error
+}
+
+func (f *fixedBudget) RecordUsage(record UsageRecord) {}
+
+func (f *fixedBudget) CheckBudget(ctx context.Context) error { return f.err }
+
+func TestBudgetGuard(t *testing.T) {
+	adapter := &mockAdapter{chatResp: &ChatResponse{Message: Message{Role: "assistant", Content: "Hi"}}}
+	c := newMockClient(ProviderOpenAI, adapter)
+	guard := &fixedBudget{}
+	c.config.UsageRecorder = guard
+	req := ChatRequest{Messages: []Message{{Role: "user", Content: "Hello"}}}
+
+	if _, err := c.ChatComplete(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	guard.err = &BudgetExceededError{Window: "daily", Limit: 100, Spent: 100.5, ResetAt: time.Now().Add(time.Hour)}
+	_, err := c.ChatComplete(context.Background(), req)
+	var exceeded *BudgetExceededError
+	if !errors.As(err, &exceeded) || exceeded.Limit != 100 {
+		t.Fatalf("Expected a budget exceeded error, got %v", err)
+	}
+	if len(adapter.chatRequests) != 1 {
+		t.Errorf("Expected the rejected request not to be sent, got %d requests", len(adapter.chatRequests))
+	}
+}
@@ -0,0 +1,84 @@
+package aiprovider
+
+import (
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+// minVerifiedTokens is the smallest local count usage is verified against, as
+// fixed formatting overhead dominates the relative deviation of shorter texts
+const minVerifiedTokens = 20
+
+// verifyUsage compares the reported usage of a response with local token
+// counts of the prompt, or the messages of chat requests, and the output. It
+// records counts diverging beyond Config.UsageVerificationTolerance in
+// metadata and reports them to Config.OnUsageMismatch.
+func (c *client) verifyUsage(metadata *ResponseMetadata, usage Usage, prompt string, messages []Message, output string) {
+	tolerance := c.config.UsageVerificationTolerance
+	if tolerance <= 0 {
+		return
+	}
+
+	var promptTokens int
+	switch {
+	case hasContentReaders(messages):
+		// Streamed content cannot be counted; a zero count skips the check
+	case messages != nil:
+		promptTokens = c.countMessageTokens(messages)
+	default:
+		promptTokens = c.countTokens(prompt)
+	}
+
+	checks := []struct {
+		field    string
+		reported int
+		counted  int
+	}{
+		{"prompt_tokens", usage.PromptTokens, promptTokens},
+		{"completion_tokens", usage.CompletionTokens, c.countTokens(output)},
+	}
+	for _, check := range checks {
+		// Providers that do not report a count leave it at zero
+		if check.reported == 0 || check.counted < minVerifiedTokens {
+			continue
+		}
+		deviation := float64(check.reported-check.counted) / float64(check.counted)
+		if deviation <= tolerance && deviation >= -tolerance {
+			continue
+		}
+
+		mismatch := UsageMismatch{
+			Field:     check.field,
+			Reported:  check.reported,
+			Counted:   check.counted,
+			Deviation: deviation,
+		}
+		metadata.UsageMismatches = append(metadata.UsageMismatches, mismatch)
+		if c.config.OnUsageMismatch != nil {
+			c.config.OnUsageMismatch(mismatch)
+		}
+	}
+}
+
+// countTokens counts the tokens of text for usage verification
+func (c *client) countTokens(text string) int {
+	if c.config.UsageTokenCounter != nil {
+		return c.config.UsageTokenCounter(text)
+	}
+	return tokenizer.Estimate(text)
+}
+
+// countMessageTokens counts the prompt tokens of a conversation for usage verification
+func (c *client) countMessageTokens(messages []Message) int {
+	if c.config.UsageTokenCounter != nil {
+		return tokenizer.CountMessages(tokenCounter(c.config.UsageTokenCounter), messages)
+	}
+	return tokenizer.EstimateMessages(messages)
+}
+
+// tokenCounter adapts a counting function to tokenizer.Tokenizer
+type tokenCounter func(text string) int
+
+// CountTokens implements tokenizer.Tokenizer
+func (t tokenCounter) CountTokens(text string) int {
+	return t(text)
+}
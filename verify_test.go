@@ -0,0 +1,66 @@
+package aiprovider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ajeet-kumar1087/ai-providers/tokenizer"
+)
+
+func TestChatComplete_UsageVerification(t *testing.T) {
+	prompt := strings.Repeat("word ", 80)
+	output := strings.Repeat("token ", 40)
+	counted := tokenizer.EstimateMessages([]Message{{Role: "user", Content: prompt}})
+
+	adapter := &mockAdapter{
+		chatResp: &ChatResponse{
+			Message: Message{Role: "assistant", Content: output},
+			// The prompt count matches; the completion count is doubled
+			Usage: Usage{PromptTokens: counted, CompletionTokens: 120, TotalTokens: counted + 120},
+		},
+	}
+	recorder := &recordingUsageRecorder{}
+	var reported []UsageMismatch
+	c := newMockClient(ProviderAnthropic, adapter)
+	c.config.UsageRecorder = recorder
+	c.config.UsageVerificationTolerance = 0.5
+	c.config.OnUsageMismatch = func(m UsageMismatch) { reported = append(reported, m) }
+
+	resp, err := c.ChatComplete(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: prompt}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mismatches := resp.Metadata.UsageMismatches
+	if len(mismatches) != 1 || mismatches[0].Field != "completion_tokens" || mismatches[0].Reported != 120 || mismatches[0].Deviation <= 0.5 {
+		t.Errorf("Expected a completion token mismatch, got %+v", mismatches)
+	}
+	if len(reported) != 1 {
+		t.Errorf("Expected OnUsageMismatch to be called once, got %d", len(reported))
+	}
+	if len(recorder.records) != 1 || !recorder.records[0].UsageMismatch {
+		t.Errorf("Expected the usage record to flag the mismatch, got %+v", recorder.records)
+	}
+
+	// Disabled verification reports nothing
+	c.config.UsageVerificationTolerance = 0
+	resp, err = c.ChatComplete(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: prompt}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.Metadata.UsageMismatches) != 0 || len(reported) != 1 {
+		t.Errorf("Expected no verification when disabled, got %+v", resp.Metadata.UsageMismatches)
+	}
+}
+
+func TestVerifyUsage_CustomCounter(t *testing.T) {
+	c := newMockClient(ProviderOpenAI, &mockAdapter{})
+	c.config.UsageVerificationTolerance = 0.1
+	c.config.UsageTokenCounter = func(text string) int { return len(strings.Fields(text)) }
+
+	var metadata ResponseMetadata
+	c.verifyUsage(&metadata, Usage{PromptTokens: 50, CompletionTokens: 5}, strings.Repeat("a ", 30), nil, "short")
+	if len(metadata.UsageMismatches) != 1 || metadata.UsageMismatches[0].Counted != 30 {
+		t.Errorf("Expected a prompt token mismatch against the custom count, got %+v", metadata.UsageMismatches)
+	}
+}
@@ -0,0 +1,323 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ajeet-kumar1087/ai-providers/rag"
+)
+
+const (
+	// DefaultVotingTemperature is the sampling temperature of
+	// CompleteWithVoting when neither the request nor the options set one
+	DefaultVotingTemperature = 0.8
+
+	// DefaultVotingSimilarity is the cosine similarity above which
+	// embedded answers are counted as the same answer
+	DefaultVotingSimilarity = 0.9
+)
+
+// VotingOptions configures CompleteWithVoting.
+type VotingOptions struct {
+	// Temperature overrides the sampling temperature (default: the
+	// request's temperature, or 0.8 if it has none)
+	Temperature *float64
+
+	// Concurrency is the number of samples requested at once (default: k)
+	Concurrency int
+
+	// Answer extracts the answer to vote on from a completion, e.g. the
+	// last line of a chain of thought (default: the whole text)
+	Answer func(text string) string
+
+	// Embedder groups answers by embedding similarity instead of exact
+	// match after normalizing case, whitespace and punctuation (optional)
+	Embedder rag.Embedder
+
+	// Similarity is the minimum cosine similarity of answers grouped by
+	// the Embedder
+	// Default: 0.9 if not specified
+	Similarity float64
+}
+
+// VotingCandidate is one sampled completion.
+type VotingCandidate struct {
+	// Text is the completion
+	Text string `json:"text"`
+
+	// Answer is the part of the completion that was voted on
+	Answer string `json:"answer"`
+
+	// Group is the index of the candidate's answer group in
+	// VotingResult.Groups
+	Group int `json:"group"`
+
+	// FinishReason indicates why the generation stopped
+	FinishReason string `json:"finish_reason"`
+}
+
+// VotingGroup is a set of candidates counted as the same answer.
+type VotingGroup struct {
+	// Answer is the answer of the group's first candidate
+	Answer string `json:"answer"`
+
+	// Votes is the number of candidates in the group
+	Votes int `json:"votes"`
+
+	// Candidates are the indexes of the group's candidates
+	Candidates []int `json:"candidates"`
+}
+
+// VotingResult is the result of CompleteWithVoting.
+type VotingResult struct {
+	// Text is the completion of the first candidate of the winning group
+	Text string `json:"text"`
+
+	// Answer is the consensus answer
+	Answer string `json:"answer"`
+
+	// Votes is the number of candidates agreeing on the answer
+	Votes int `json:"votes"`
+
+	// Agreement is the share of candidates agreeing on the answer, between
+	// 0 and 1
+	Agreement float64 `json:"agreement"`
+
+	// Candidates are all sampled completions, in request order
+	Candidates []VotingCandidate `json:"candidates"`
+
+	// Groups are the distinct answers, by descending votes
+	Groups []VotingGroup `json:"groups"`
+
+	// Usage is the total token usage across all samples
+	Usage Usage `json:"usage"`
+}
+
+// CompleteWithVoting samples k completions of req and returns the answer
+// most of them agree on (self-consistency).
+//
+// The samples are requested in parallel at a raised temperature so they
+// explore different reasoning paths. Their answers are grouped by exact
+// match after normalization, or by embedding similarity with an Embedder,
+// and the largest group wins; ties go to the group sampled first. This
+// improves accuracy on reasoning tasks at k times the cost.
+//
+// Example:
+//
+//	result, err := client.CompleteWithVoting(ctx, CompletionRequest{
+//		Prompt: "Q: A bat and a ball cost $1.10 ... Think step by step, then give the answer on the last line.",
+//	}, 5, VotingOptions{Answer: lastLine})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("%s (%d of 5 agree)\n", result.Answer, result.Votes)
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - req: The completion request to sample
+//   - k: The number of samples (at least 1)
+//   - opts: Sampling and grouping options
+//
+// Returns:
+//   - *VotingResult: The consensus answer, every candidate and the answer groups
+//   - error: A validation error for invalid input, or the first request error
+func (c *client) CompleteWithVoting(ctx context.Context, req CompletionRequest, k int, opts VotingOptions) (*VotingResult, error) {
+	ctx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end()
+
+	if k < 1 {
+		return nil, &Error{
+			Type:     ErrorTypeValidation,
+			Message:  fmt.Sprintf("number of samples must be at least 1, got: %d", k),
+			Provider: string(c.provider),
+		}
+	}
+
+	req.Temperature = samplingTemperature(opts.Temperature, req.Temperature, DefaultVotingTemperature)
+	responses, err := c.sampleCompletions(ctx, req, k, opts.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VotingResult{Candidates: make([]VotingCandidate, k)}
+	answers := make([]string, k)
+	for i, resp := range responses {
+		answer := resp.Text
+		if opts.Answer != nil {
+			answer = opts.Answer(answer)
+		}
+		answers[i] = strings.TrimSpace(answer)
+		result.Candidates[i] = VotingCandidate{Text: resp.Text, Answer: answers[i], FinishReason: resp.FinishReason}
+		result.Usage = addUsage(result.Usage, resp.Usage)
+	}
+
+	groups, err := groupAnswers(ctx, answers, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order groups by votes, keeping sampling order for ties
+	for i := 1; i < len(groups); i++ {
+		for j := i; j > 0 && groups[j].Votes > groups[j-1].Votes; j-- {
+			groups[j], groups[j-1] = groups[j-1], groups[j]
+		}
+	}
+	for g, group := range groups {
+		for _, i := range group.Candidates {
+			result.Candidates[i].Group = g
+		}
+	}
+
+	winner := groups[0]
+	result.Groups = groups
+	result.Text = result.Candidates[winner.Candidates[0]].Text
+	result.Answer = winner.Answer
+	result.Votes = winner.Votes
+	result.Agreement = float64(winner.Votes) / float64(k)
+	return result, nil
+}
+
+// samplingTemperature returns the temperature of sampled requests: the
+// option, else the request's, else fallback
+func samplingTemperature(option, request *float64, fallback float64) *float64 {
+	if option != nil {
+		return option
+	}
+	if request != nil {
+		return request
+	}
+	return &fallback
+}
+
+// sampleCompletions sends req k times with at most concurrency requests in
+// flight. The first error cancels outstanding requests.
+func (c *client) sampleCompletions(ctx context.Context, req CompletionRequest, k, concurrency int) ([]*CompletionResponse, error) {
+	if concurrency <= 0 || concurrency > k {
+		concurrency = k
+	}
+
+	// Cached responses would repeat one sample k times
+	req.SkipCache = true
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make([]*CompletionResponse, k)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var first error
+
+	for i := 0; i < k; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			resp, err := c.Complete(ctx, req)
+			if err != nil {
+				// Report the error that cancelled the others, not those it caused
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+				return
+			}
+			responses[i] = resp
+		}(i)
+	}
+	wg.Wait()
+
+	if first != nil {
+		return nil, first
+	}
+	return responses, nil
+}
+
+// groupAnswers groups equivalent answers in sampling order
+func groupAnswers(ctx context.Context, answers []string, opts VotingOptions) ([]VotingGroup, error) {
+	var groups []VotingGroup
+	if opts.Embedder == nil {
+		index := map[string]int{}
+		for i, answer := range answers {
+			key := normalizeAnswer(answer)
+			g, ok := index[key]
+			if !ok {
+				g = len(groups)
+				index[key] = g
+				groups = append(groups, VotingGroup{Answer: answer})
+			}
+			groups[g].Votes++
+			groups[g].Candidates = append(groups[g].Candidates, i)
+		}
+		return groups, nil
+	}
+
+	vectors, err := opts.Embedder.Embed(ctx, answers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed answers: %w", err)
+	}
+	if len(vectors) != len(answers) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d answers", len(vectors), len(answers))
+	}
+	threshold := opts.Similarity
+	if threshold <= 0 {
+		threshold = DefaultVotingSimilarity
+	}
+
+	// Each answer joins the first group whose first answer is similar enough
+	for i, answer := range answers {
+		g := -1
+		for j := range groups {
+			if cosineSimilarity(vectors[groups[j].Candidates[0]], vectors[i]) >= threshold {
+				g = j
+				break
+			}
+		}
+		if g < 0 {
+			g = len(groups)
+			groups = append(groups, VotingGroup{Answer: answer})
+		}
+		groups[g].Votes++
+		groups[g].Candidates = append(groups[g].Candidates, i)
+	}
+	return groups, nil
+}
+
+// normalizeAnswer lower-cases an answer and reduces it to its words and
+// numbers, so "42." and " 42" count as the same answer
+func normalizeAnswer(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-'
+	})
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, ".-")
+	}
+	return strings.Join(fields, " ")
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors, or
+// 0 if either is zero or their lengths differ
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,105 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// votingAdapter answers completion requests with answers in turn. It is safe
+// for concurrent use.
+type votingAdapter struct {
+	mockAdapter
+	mu       sync.Mutex
+	answers  []string
+	requests []CompletionRequest
+	err      error
+}
+
+func (v *votingAdapter) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.requests = append(v.requests, req)
+	if v.err != nil {
+		return nil, v.err
+	}
+	answer := v.answers[(len(v.requests)-1)%len(v.answers)]
+	return &CompletionResponse{
+		Text:         answer,
+		Usage:        Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		FinishReason: "stop",
+	}, nil
+}
+
+// lastLine returns the last line of a completion
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return lines[len(lines)-1]
+}
+
+func TestCompleteWithVoting(t *testing.T) {
+	adapter := &votingAdapter{answers: []string{
+		"10 + 5 = 15\nThe answer is 10 cents.",
+		"The ball costs 5 cents.\nthe answer is 5 cents",
+		"Let x be the ball...\nThe answer is 5 cents.",
+		"Hmm\nThe answer is 10 cents",
+		"So the ball is 0.05\nThe answer is 5 cents!",
+	}}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	result, err := c.CompleteWithVoting(context.Background(), CompletionRequest{Prompt: "Bat and ball?"}, 5, VotingOptions{Answer: lastLine})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Votes != 3 || result.Agreement != 0.6 || !strings.Contains(result.Answer, "5 cents") {
+		t.Errorf("Expected 3 of 5 votes for 5 cents, got %+v", result)
+	}
+	if len(result.Groups) != 2 || result.Groups[1].Votes != 2 || len(result.Candidates) != 5 {
+		t.Errorf("Expected 2 answer groups over 5 candidates, got %+v", result.Groups)
+	}
+	if winner := result.Candidates[result.Groups[0].Candidates[0]]; winner.Text != result.Text || winner.Group != 0 {
+		t.Errorf("Expected the text of the winning group's first candidate, got %q", result.Text)
+	}
+	if result.Usage.TotalTokens != 75 {
+		t.Errorf("Expected the usage of 5 samples, got %+v", result.Usage)
+	}
+	for _, req := range adapter.requests {
+		if req.Temperature == nil || *req.Temperature != DefaultVotingTemperature {
+			t.Fatalf("Expected samples at the default voting temperature, got %v", req.Temperature)
+		}
+	}
+}
+
+func TestCompleteWithVoting_Embedder(t *testing.T) {
+	adapter := &votingAdapter{answers: []string{"Paris is the capital.", "It is Lyon.", "The capital is Paris."}}
+	c := newMockClient(ProviderOpenAI, adapter)
+
+	temperature := 0.3
+	result, err := c.CompleteWithVoting(context.Background(), CompletionRequest{Prompt: "Capital of France?", Temperature: &temperature}, 3, VotingOptions{
+		Embedder: keywordEmbedder{keywords: []string{"paris", "lyon"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Votes != 2 || !strings.Contains(result.Answer, "Paris") {
+		t.Errorf("Expected the similar answers to be grouped, got %+v", result)
+	}
+	if *adapter.requests[0].Temperature != 0.3 {
+		t.Errorf("Expected the request's temperature to be kept, got %v", *adapter.requests[0].Temperature)
+	}
+}
+
+func TestCompleteWithVoting_Errors(t *testing.T) {
+	c := newMockClient(ProviderOpenAI, &votingAdapter{answers: []string{"a"}})
+	_, err := c.CompleteWithVoting(context.Background(), CompletionRequest{Prompt: "?"}, 0, VotingOptions{})
+	if apiErr, ok := err.(*Error); !ok || apiErr.Type != ErrorTypeValidation {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+
+	c = newMockClient(ProviderOpenAI, &votingAdapter{err: errors.New("boom")})
+	if _, err := c.CompleteWithVoting(context.Background(), CompletionRequest{Prompt: "?"}, 3, VotingOptions{}); err == nil {
+		t.Error("Expected the request error")
+	}
+}